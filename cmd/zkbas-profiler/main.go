@@ -0,0 +1,129 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/consensys/gnark/logger"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+/*
+	zkbas-profiler: compile the tx or block circuit and print its constraints
+	by component as json, e.g.
+	zkbas-profiler -circuit tx -slot l2Asset
+	zkbas-profiler -circuit block -layout priorityOp,l2Asset,l2Asset,nftMarket -o profile.json
+	It exits with 0 on success, 1 if the profiling failed and 2 on invalid usage.
+*/
+
+const (
+	exitCodeOk = iota
+	exitCodeFailed
+	exitCodeUsage
+)
+
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	// keep stdout for the json output
+	logger.SetOutput(os.Stderr)
+	err := run(os.Args[1:])
+	switch {
+	case err == nil:
+		os.Exit(exitCodeOk)
+	case errors.Is(err, errUsage):
+		if err != errUsage {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(exitCodeUsage)
+	default:
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitCodeFailed)
+	}
+}
+
+/*
+	run: profile the circuit and write the json report, the output file is
+	closed before returning so a failed write is reported
+*/
+func run(args []string) (err error) {
+	flags := flag.NewFlagSet("zkbas-profiler", flag.ContinueOnError)
+	circuit := flags.String("circuit", "tx", "circuit to profile: tx or block")
+	slot := flags.String("slot", "all", "slot type of the tx circuit: all, priorityOp, l2Asset or nftMarket")
+	layout := flags.String("layout", "all", "comma separated slot types of the block circuit")
+	output := flags.String("o", "", "output file, stdout if empty")
+	err = flags.Parse(args)
+	if err == flag.ErrHelp {
+		return nil
+	}
+	if err != nil {
+		return errUsage
+	}
+
+	var profile *std.ConstraintProfile
+	switch *circuit {
+	case "tx":
+		slotType, err := block.ParseTxSlotType(*slot)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errUsage, err)
+		}
+		profile, err = block.ProfileTxConstraints(slotType)
+		if err != nil {
+			return err
+		}
+	case "block":
+		var slotTypes []int
+		for _, name := range strings.Split(*layout, ",") {
+			slotType, err := block.ParseTxSlotType(strings.TrimSpace(name))
+			if err != nil {
+				return fmt.Errorf("%w: %v", errUsage, err)
+			}
+			slotTypes = append(slotTypes, slotType)
+		}
+		profile, err = block.ProfileBlockConstraints(slotTypes)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: invalid circuit %s", errUsage, *circuit)
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		out, err = os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := out.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(profile)
+}
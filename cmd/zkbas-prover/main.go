@@ -0,0 +1,545 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/logger"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/prover"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/witness"
+)
+
+/*
+	zkbas-prover: compile the block circuit, setup its keys, prove and verify
+	blocks and export the verifier contract, e.g.
+	zkbas-prover compile -txs 10 -dir zkbas10
+	zkbas-prover compile -txs 10 -compress -dir zkbas10_compressed
+	zkbas-prover setup -dir zkbas10
+	zkbas-prover setup -backend plonk -txs 10 -dir zkbas10_plonk -srs bn254.srs
+	zkbas-prover prove -dir zkbas10 -block block.json -o block.proof
+	zkbas-prover verify -dir zkbas10 -proof block.proof -block block.json
+	zkbas-prover export -dir zkbas10 -o ZkbasVerifier10.sol
+	zkbas-prover dispatcher -dirs zkbas1,zkbas10 -o ZkbasVerifier.sol
+	zkbas-prover setup -circuit exodus -dir exodus
+	zkbas-prover exodus-prove -dir exodus -snapshot state.json -account 1 -asset 0 -o exodus.proof -public exodus.json
+	zkbas-prover exodus-verify -dir exodus -proof exodus.proof -public exodus.json
+	The groth16 keys of setup are only meant for tests, the phase1-* and
+	phase2-* commands run a setup ceremony instead, see mpc.go.
+	The circuit and its keys are kept in a bundle directory with their manifest,
+	see prover.Bundle.
+	It exits with 0 on success, 1 if the command failed and 2 on invalid usage.
+*/
+
+const (
+	exitCodeOk = iota
+	exitCodeFailed
+	exitCodeUsage
+)
+
+var errUsage = errors.New("invalid usage")
+
+var commands = map[string]func(args []string) error{
+	"compile": compile,
+	"setup":   setup,
+	"prove":   prove,
+	"verify":  verify,
+	"export":  export,
+
+	"dispatcher": dispatcher,
+
+	"exodus-prove":  exodusProve,
+	"exodus-verify": exodusVerify,
+
+	"phase1-new":        phase1New,
+	"phase1-import":     phase1Import,
+	"phase1-contribute": phase1Contribute,
+	"phase1-verify":     phase1Verify,
+	"phase2-init":       phase2Init,
+	"phase2-contribute": phase2Contribute,
+	"phase2-verify":     phase2Verify,
+	"phase2-keys":       phase2Keys,
+}
+
+func main() {
+	// keep stdout for the outputs written to stdout
+	logger.SetOutput(os.Stderr)
+	if len(os.Args) < 2 || commands[os.Args[1]] == nil {
+		usage()
+		os.Exit(exitCodeUsage)
+	}
+	err := commands[os.Args[1]](os.Args[2:])
+	switch {
+	case err == nil:
+		os.Exit(exitCodeOk)
+	case errors.Is(err, errUsage):
+		if err != errUsage {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+		}
+		os.Exit(exitCodeUsage)
+	default:
+		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+		os.Exit(exitCodeFailed)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: zkbas-prover <compile|setup|prove|verify|export|dispatcher> [flags]")
+	fmt.Fprintln(os.Stderr, "       zkbas-prover <exodus-prove|exodus-verify> [flags]")
+	fmt.Fprintln(os.Stderr, "       zkbas-prover <phase1-new|phase1-import|phase1-contribute|phase1-verify> [flags]")
+	fmt.Fprintln(os.Stderr, "       zkbas-prover <phase2-init|phase2-contribute|phase2-verify|phase2-keys> [flags]")
+	fmt.Fprintln(os.Stderr, "run zkbas-prover <command> -h for the flags of a command")
+}
+
+/*
+	parseFlags: parse the flags of the command and check the required ones are set
+*/
+func parseFlags(flags *flag.FlagSet, args []string, required ...string) error {
+	err := flags.Parse(args)
+	if err == flag.ErrHelp {
+		os.Exit(exitCodeOk)
+	}
+	if err != nil {
+		return errUsage
+	}
+	for _, name := range required {
+		if flags.Lookup(name).Value.String() == "" {
+			fmt.Fprintf(os.Stderr, "flag -%s is required\n", name)
+			flags.Usage()
+			return errUsage
+		}
+	}
+	return nil
+}
+
+func isFlagSet(flags *flag.FlagSet, name string) (isSet bool) {
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			isSet = true
+		}
+	})
+	return isSet
+}
+
+/*
+	layoutFlags: circuit, backend, public inputs and slot types of the block circuit,
+	either txs slots accepting all tx types or a comma separated list of slot types.
+	The exodus circuits have no slot types.
+*/
+type layoutFlags struct {
+	circuit  *string
+	txs      *int
+	layout   *string
+	backend  *string
+	compress *bool
+}
+
+func newLayoutFlags(flags *flag.FlagSet) layoutFlags {
+	return layoutFlags{
+		circuit:  flags.String("circuit", prover.CircuitBlock, "circuit: block, exodus or exodusNft"),
+		txs:      flags.Int("txs", 0, "number of tx slots accepting all tx types"),
+		layout:   flags.String("layout", "", "comma separated slot types, overrides -txs"),
+		backend:  flags.String("backend", prover.BackendGroth16, "proving system: groth16 or plonk"),
+		compress: flags.Bool("compress", false, "single public input hashing the roots, commitment, block number, timestamp and priority ops hashes"),
+	}
+}
+
+func (f layoutFlags) isSet() bool {
+	return *f.txs != 0 || *f.layout != "" || *f.circuit != prover.CircuitBlock
+}
+
+/*
+	loadOrCompileBundle: see prover.LoadOrCompileBundle and prover.LoadOrCompileExodusBundle
+*/
+func (f layoutFlags) loadOrCompileBundle(dir string) (bundle *prover.Bundle, ccs frontend.CompiledConstraintSystem, err error) {
+	if *f.circuit == prover.CircuitBlock {
+		slotTypes, err := f.slotTypes()
+		if err != nil {
+			return nil, nil, err
+		}
+		return prover.LoadOrCompileBundle(dir, *f.backend, slotTypes, *f.compress)
+	}
+	if *f.circuit != prover.CircuitExodus && *f.circuit != prover.CircuitExodusNft {
+		return nil, nil, fmt.Errorf("%w: circuit %q", errUsage, *f.circuit)
+	}
+	if *f.txs != 0 || *f.layout != "" || *f.compress {
+		fmt.Fprintln(os.Stderr, "flags -txs, -layout and -compress are only used by the block circuit")
+		return nil, nil, errUsage
+	}
+	return prover.LoadOrCompileExodusBundle(dir, *f.backend, *f.circuit)
+}
+
+func (f layoutFlags) slotTypes() (slotTypes []int, err error) {
+	if *f.layout != "" {
+		for _, name := range strings.Split(*f.layout, ",") {
+			slotType, err := block.ParseTxSlotType(strings.TrimSpace(name))
+			if err != nil {
+				return nil, fmt.Errorf("%w: slot type %q", errUsage, name)
+			}
+			slotTypes = append(slotTypes, slotType)
+		}
+		return slotTypes, nil
+	}
+	if *f.txs <= 0 {
+		fmt.Fprintln(os.Stderr, "flag -txs or -layout is required")
+		return nil, errUsage
+	}
+	slotTypes = make([]int, *f.txs)
+	for i := range slotTypes {
+		slotTypes[i] = block.TxSlotTypeAll
+	}
+	return slotTypes, nil
+}
+
+func compile(args []string) error {
+	flags := flag.NewFlagSet("compile", flag.ContinueOnError)
+	layout := newLayoutFlags(flags)
+	dir := flags.String("dir", "", "bundle directory, the circuit isn't compiled again if it's already in the bundle")
+	if err := parseFlags(flags, args, "dir"); err != nil {
+		return err
+	}
+	bundle, ccs, err := layout.loadOrCompileBundle(*dir)
+	if err != nil {
+		return err
+	}
+	if bundle.Manifest.Circuit != prover.CircuitBlock {
+		log.Printf("%s circuit: %d constraints", bundle.Manifest.Circuit, ccs.GetNbConstraints())
+		return nil
+	}
+	log.Printf("block circuit with %d tx slots: %d constraints", bundle.Manifest.TxsCount, ccs.GetNbConstraints())
+	return nil
+}
+
+func setup(args []string) error {
+	flags := flag.NewFlagSet("setup", flag.ContinueOnError)
+	layout := newLayoutFlags(flags)
+	dir := flags.String("dir", "", "bundle directory, the circuit is compiled first if -txs, -layout or -circuit is set")
+	srsPath := flags.String("srs", "", "KZG SRS of the plonk setup, an insecure test SRS is generated if empty")
+	if err := parseFlags(flags, args, "dir"); err != nil {
+		return err
+	}
+	var (
+		bundle *prover.Bundle
+		ccs    frontend.CompiledConstraintSystem
+		err    error
+	)
+	if layout.isSet() {
+		bundle, ccs, err = layout.loadOrCompileBundle(*dir)
+		if err != nil {
+			return err
+		}
+	} else {
+		bundle, err = prover.ReadBundle(*dir)
+		if err != nil {
+			return err
+		}
+		if isFlagSet(flags, "backend") && *layout.backend != bundle.Manifest.Backend {
+			return fmt.Errorf("bundle is built for %s", bundle.Manifest.Backend)
+		}
+		if isFlagSet(flags, "compress") && *layout.compress != bundle.Manifest.CompressedPublicInputs {
+			return fmt.Errorf("bundle is built with compressed public inputs %v", bundle.Manifest.CompressedPublicInputs)
+		}
+		ccs, err = bundle.ReadCompiledConstraints()
+		if err != nil {
+			return err
+		}
+	}
+	backendName := bundle.Manifest.Backend
+	var srs kzg.SRS
+	switch {
+	case backendName != prover.BackendPlonk && *srsPath != "":
+		fmt.Fprintln(os.Stderr, "flag -srs is only used by plonk")
+		return errUsage
+	case backendName == prover.BackendPlonk && *srsPath != "":
+		srs, err = prover.ReadSRS(*srsPath)
+	case backendName == prover.BackendPlonk:
+		log.Println("generating an insecure test srs, the keys are only meant for tests")
+		srs, err = prover.NewTestSRS(ccs)
+	}
+	if err != nil {
+		return err
+	}
+	pk, vk, err := prover.Setup(backendName, ccs, srs)
+	if err != nil {
+		return err
+	}
+	return bundle.WriteKeys(pk, vk, srs)
+}
+
+func prove(args []string) error {
+	flags := flag.NewFlagSet("prove", flag.ContinueOnError)
+	dir := flags.String("dir", "", "bundle directory")
+	blockPath := flags.String("block", "", "block witness as json")
+	output := flags.String("o", "", "output file of the proof")
+	if err := parseFlags(flags, args, "dir", "block", "o"); err != nil {
+		return err
+	}
+	oBlock, err := prover.ReadBlock(*blockPath)
+	if err != nil {
+		return err
+	}
+	bundle, err := prover.ReadBundle(*dir)
+	if err != nil {
+		return err
+	}
+	if err = bundle.CheckCircuit(prover.CircuitBlock); err != nil {
+		return err
+	}
+	slotTypes, err := bundle.SlotTypes()
+	if err != nil {
+		return err
+	}
+	ccs, err := bundle.ReadCompiledConstraints()
+	if err != nil {
+		return err
+	}
+	pk, err := bundle.ReadProvingKey()
+	if err != nil {
+		return err
+	}
+	proof, err := prover.ProveBlock(
+		bundle.Manifest.Backend, ccs, pk, oBlock, slotTypes, bundle.Manifest.CompressedPublicInputs,
+	)
+	if err != nil {
+		return err
+	}
+	return prover.WriteFile(*output, proof)
+}
+
+func verify(args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ContinueOnError)
+	dir := flags.String("dir", "", "bundle directory")
+	proofPath := flags.String("proof", "", "proof of the block")
+	blockPath := flags.String("block", "", "block as json, only its roots, commitment, number, timestamp and priority ops hashes are read")
+	if err := parseFlags(flags, args, "dir", "proof", "block"); err != nil {
+		return err
+	}
+	bundle, err := prover.ReadBundle(*dir)
+	if err != nil {
+		return err
+	}
+	if err = bundle.CheckCircuit(prover.CircuitBlock); err != nil {
+		return err
+	}
+	vk, err := bundle.ReadVerifyingKey()
+	if err != nil {
+		return err
+	}
+	proof, err := prover.ReadProof(bundle.Manifest.Backend, *proofPath)
+	if err != nil {
+		return err
+	}
+	oBlock, err := prover.ReadBlock(*blockPath)
+	if err != nil {
+		return err
+	}
+	err = prover.VerifyBlock(bundle.Manifest.Backend, vk, proof, oBlock, bundle.Manifest.CompressedPublicInputs)
+	if err != nil {
+		return err
+	}
+	log.Println("valid proof")
+	return nil
+}
+
+func export(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	dir := flags.String("dir", "", "bundle directory")
+	output := flags.String("o", "", "output file of the verifier contract, stdout if empty")
+	if err := parseFlags(flags, args, "dir"); err != nil {
+		return err
+	}
+	bundle, err := prover.ReadBundle(*dir)
+	if err != nil {
+		return err
+	}
+	vk, err := bundle.ReadVerifyingKey()
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		return prover.ExportSolidity(bundle.Manifest.Backend, vk, bundle.Manifest.CompressedPublicInputs, os.Stdout)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	err = prover.ExportSolidity(bundle.Manifest.Backend, vk, bundle.Manifest.CompressedPublicInputs, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func dispatcher(args []string) error {
+	flags := flag.NewFlagSet("dispatcher", flag.ContinueOnError)
+	dirs := flags.String("dirs", "", "comma separated bundle directories, one per block size")
+	output := flags.String("o", "", "output file of the verifier contract, stdout if empty")
+	if err := parseFlags(flags, args, "dirs"); err != nil {
+		return err
+	}
+	registry, err := prover.ReadRegistry(strings.Split(*dirs, ","))
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		return registry.ExportSolidity(os.Stdout)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	err = registry.ExportSolidity(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func exodusProve(args []string) error {
+	flags := flag.NewFlagSet("exodus-prove", flag.ContinueOnError)
+	dir := flags.String("dir", "", "bundle directory of the exodus or exodusNft circuit")
+	snapshotPath := flags.String("snapshot", "", "leaves of the state of the last verified block as json")
+	accountIndex := flags.Int64("account", -1, "account index, exodus circuit only")
+	assetId := flags.Int64("asset", -1, "asset id, exodus circuit only")
+	nftIndex := flags.Int64("nft", -1, "nft index, exodusNft circuit only")
+	output := flags.String("o", "", "output file of the proof")
+	publicPath := flags.String("public", "", "output file of the exodus witness as json, read by exodus-verify")
+	if err := parseFlags(flags, args, "dir", "snapshot", "o"); err != nil {
+		return err
+	}
+	bundle, err := prover.ReadBundle(*dir)
+	if err != nil {
+		return err
+	}
+	snapshot, err := prover.ReadSnapshot(*snapshotPath)
+	if err != nil {
+		return err
+	}
+	s, err := witness.NewStateFromSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+	var (
+		oExodus interface{}
+		prove   func(ccs frontend.CompiledConstraintSystem, pk prover.ProvingKey) (prover.Proof, error)
+	)
+	switch bundle.Manifest.Circuit {
+	case prover.CircuitExodus:
+		if *accountIndex < 0 || *assetId < 0 || *nftIndex >= 0 {
+			fmt.Fprintln(os.Stderr, "flags -account and -asset are required by the exodus circuit")
+			return errUsage
+		}
+		oExodusAsset, err := s.BuildExodus(*accountIndex, *assetId)
+		if err != nil {
+			return err
+		}
+		oExodus = oExodusAsset
+		prove = func(ccs frontend.CompiledConstraintSystem, pk prover.ProvingKey) (prover.Proof, error) {
+			return prover.ProveExodus(bundle.Manifest.Backend, ccs, pk, oExodusAsset)
+		}
+	case prover.CircuitExodusNft:
+		if *nftIndex < 0 || *accountIndex >= 0 || *assetId >= 0 {
+			fmt.Fprintln(os.Stderr, "flag -nft is required by the exodusNft circuit")
+			return errUsage
+		}
+		oExodusNft, err := s.BuildExodusNft(*nftIndex)
+		if err != nil {
+			return err
+		}
+		oExodus = oExodusNft
+		prove = func(ccs frontend.CompiledConstraintSystem, pk prover.ProvingKey) (prover.Proof, error) {
+			return prover.ProveExodusNft(bundle.Manifest.Backend, ccs, pk, oExodusNft)
+		}
+	default:
+		return fmt.Errorf("bundle is built for the %s circuit", bundle.Manifest.Circuit)
+	}
+	ccs, err := bundle.ReadCompiledConstraints()
+	if err != nil {
+		return err
+	}
+	pk, err := bundle.ReadProvingKey()
+	if err != nil {
+		return err
+	}
+	proof, err := prove(ccs, pk)
+	if err != nil {
+		return err
+	}
+	if *publicPath != "" {
+		err = prover.WriteJSON(*publicPath, oExodus)
+		if err != nil {
+			return err
+		}
+	}
+	return prover.WriteFile(*output, proof)
+}
+
+func exodusVerify(args []string) error {
+	flags := flag.NewFlagSet("exodus-verify", flag.ContinueOnError)
+	dir := flags.String("dir", "", "bundle directory of the exodus or exodusNft circuit")
+	proofPath := flags.String("proof", "", "proof of the exodus")
+	publicPath := flags.String("public", "", "exodus witness as json, only its public inputs are read")
+	if err := parseFlags(flags, args, "dir", "proof", "public"); err != nil {
+		return err
+	}
+	bundle, err := prover.ReadBundle(*dir)
+	if err != nil {
+		return err
+	}
+	vk, err := bundle.ReadVerifyingKey()
+	if err != nil {
+		return err
+	}
+	proof, err := prover.ReadProof(bundle.Manifest.Backend, *proofPath)
+	if err != nil {
+		return err
+	}
+	switch bundle.Manifest.Circuit {
+	case prover.CircuitExodus:
+		oExodus, err := prover.ReadExodus(*publicPath)
+		if err != nil {
+			return err
+		}
+		err = prover.VerifyExodus(bundle.Manifest.Backend, vk, proof, oExodus)
+		if err != nil {
+			return err
+		}
+	case prover.CircuitExodusNft:
+		oExodus, err := prover.ReadExodusNft(*publicPath)
+		if err != nil {
+			return err
+		}
+		err = prover.VerifyExodusNft(bundle.Manifest.Backend, vk, proof, oExodus)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("bundle is built for the %s circuit", bundle.Manifest.Circuit)
+	}
+	log.Println("valid proof")
+	return nil
+}
@@ -0,0 +1,302 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/mpcsetup"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/prover"
+)
+
+/*
+	groth16 setup ceremony, every step reads and writes files so participants
+	contribute offline, e.g.
+	zkbas-prover phase1-new -power 24 -o phase1.0
+	zkbas-prover phase1-contribute -in phase1.0 -o phase1.1
+	zkbas-prover phase1-verify -in phase1.1
+	zkbas-prover phase2-init -dir zkbas10 -phase1 phase1.1 -o phase2.0
+	zkbas-prover phase2-contribute -in phase2.0 -o phase2.1
+	zkbas-prover phase2-verify -dir zkbas10 -phase1 phase1.1 -initial phase2.0 -in phase2.1
+	zkbas-prover phase2-keys -dir zkbas10 -phase1 phase1.1 -initial phase2.0 -in phase2.1
+	The phase 1 can instead continue a snarkjs powers of tau ceremony, e.g.
+	zkbas-prover phase1-import -ptau powersOfTau28_hez_final_25.ptau -power 24 -o phase1.0
+	zkbas-prover phase1-contribute -in phase1.0 -o phase1.1
+	zkbas-prover phase1-verify -initial phase1.0 -in phase1.1
+	zkbas-prover phase2-init -dir zkbas10 -initial phase1.0 -phase1 phase1.1 -o phase2.0
+	and the phase 2 is verified with -phase1-initial phase1.0 -phase1 phase1.1
+*/
+
+func phase1New(args []string) error {
+	flags := flag.NewFlagSet("phase1-new", flag.ContinueOnError)
+	power := flags.Int("power", 0, "the phase 1 is usable for circuits with at most 2^power constraints")
+	output := flags.String("o", "", "output file of the phase 1")
+	if err := parseFlags(flags, args, "o"); err != nil {
+		return err
+	}
+	phase1, err := mpcsetup.NewPhase1(*power)
+	if err != nil {
+		return err
+	}
+	return prover.WriteFile(*output, phase1)
+}
+
+func phase1Import(args []string) error {
+	flags := flag.NewFlagSet("phase1-import", flag.ContinueOnError)
+	ptauPath := flags.String("ptau", "", "ptau file of a snarkjs powers of tau ceremony, of power larger than -power")
+	power := flags.Int("power", 0, "the phase 1 is usable for circuits with at most 2^power constraints")
+	output := flags.String("o", "", "output file of the phase 1")
+	if err := parseFlags(flags, args, "ptau", "o"); err != nil {
+		return err
+	}
+	f, err := os.Open(*ptauPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	phase1, err := mpcsetup.ImportPhase1(f, *power)
+	if err != nil {
+		return err
+	}
+	err = prover.WriteFile(*output, phase1)
+	if err != nil {
+		return err
+	}
+	return printContributionHash(phase1.Hash())
+}
+
+func phase1Contribute(args []string) error {
+	flags := flag.NewFlagSet("phase1-contribute", flag.ContinueOnError)
+	input := flags.String("in", "", "phase 1 of the last contribution")
+	output := flags.String("o", "", "output file of the phase 1 with the contribution")
+	if err := parseFlags(flags, args, "in", "o"); err != nil {
+		return err
+	}
+	phase1 := new(mpcsetup.Phase1)
+	err := prover.ReadFile(*input, phase1)
+	if err != nil {
+		return err
+	}
+	err = phase1.Contribute()
+	if err != nil {
+		return err
+	}
+	err = prover.WriteFile(*output, phase1)
+	if err != nil {
+		return err
+	}
+	return printContributionHash(phase1.Hash())
+}
+
+func phase1Verify(args []string) error {
+	flags := flag.NewFlagSet("phase1-verify", flag.ContinueOnError)
+	input := flags.String("in", "", "phase 1 to verify")
+	initialPath := flags.String("initial", "", "phase 1 of phase1-import if the ceremony continues a snarkjs one")
+	if err := parseFlags(flags, args, "in"); err != nil {
+		return err
+	}
+	phase1, err := readPhase1(*initialPath, *input)
+	if err != nil {
+		return err
+	}
+	log.Printf("valid phase 1 of %d contributions for up to %d constraints\n", len(phase1.Contributions), phase1.Size())
+	return printContributionHash(phase1.Hash())
+}
+
+func phase2Init(args []string) error {
+	flags := flag.NewFlagSet("phase2-init", flag.ContinueOnError)
+	dir := flags.String("dir", "", "groth16 bundle of the circuit")
+	phase1Path := flags.String("phase1", "", "phase 1 of the last contribution")
+	initialPath := flags.String("initial", "", "phase 1 of phase1-import if the ceremony continues a snarkjs one")
+	output := flags.String("o", "", "output file of the phase 2")
+	if err := parseFlags(flags, args, "dir", "phase1", "o"); err != nil {
+		return err
+	}
+	_, phase2, _, err := initPhase2(*dir, *initialPath, *phase1Path)
+	if err != nil {
+		return err
+	}
+	err = prover.WriteFile(*output, phase2)
+	if err != nil {
+		return err
+	}
+	return printContributionHash(phase2.Hash())
+}
+
+func phase2Contribute(args []string) error {
+	flags := flag.NewFlagSet("phase2-contribute", flag.ContinueOnError)
+	input := flags.String("in", "", "phase 2 of the last contribution")
+	output := flags.String("o", "", "output file of the phase 2 with the contribution")
+	if err := parseFlags(flags, args, "in", "o"); err != nil {
+		return err
+	}
+	phase2 := new(mpcsetup.Phase2)
+	err := prover.ReadFile(*input, phase2)
+	if err != nil {
+		return err
+	}
+	err = phase2.Contribute()
+	if err != nil {
+		return err
+	}
+	err = prover.WriteFile(*output, phase2)
+	if err != nil {
+		return err
+	}
+	return printContributionHash(phase2.Hash())
+}
+
+func phase2Verify(args []string) error {
+	flags := flag.NewFlagSet("phase2-verify", flag.ContinueOnError)
+	dir := flags.String("dir", "", "groth16 bundle of the circuit")
+	phase1Path := flags.String("phase1", "", "phase 1 the phase 2 was initialized from")
+	phase1InitialPath := flags.String("phase1-initial", "", "phase 1 of phase1-import if the ceremony continues a snarkjs one")
+	initialPath := flags.String("initial", "", "phase 2 the ceremony started from, see phase2-init")
+	input := flags.String("in", "", "phase 2 to verify")
+	if err := parseFlags(flags, args, "dir", "phase1", "initial", "in"); err != nil {
+		return err
+	}
+	_, phase2, _, err := readPhase2(*dir, *phase1InitialPath, *phase1Path, *initialPath, *input)
+	if err != nil {
+		return err
+	}
+	log.Printf("valid phase 2 of %d contributions\n", len(phase2.Contributions))
+	return printContributionHash(phase2.Hash())
+}
+
+func phase2Keys(args []string) error {
+	flags := flag.NewFlagSet("phase2-keys", flag.ContinueOnError)
+	dir := flags.String("dir", "", "groth16 bundle of the circuit, the keys are added to it")
+	phase1Path := flags.String("phase1", "", "phase 1 the phase 2 was initialized from")
+	phase1InitialPath := flags.String("phase1-initial", "", "phase 1 of phase1-import if the ceremony continues a snarkjs one")
+	initialPath := flags.String("initial", "", "phase 2 the ceremony started from")
+	input := flags.String("in", "", "phase 2 of the last contribution")
+	if err := parseFlags(flags, args, "dir", "phase1", "initial", "in"); err != nil {
+		return err
+	}
+	bundle, phase2, evaluations, err := readPhase2(*dir, *phase1InitialPath, *phase1Path, *initialPath, *input)
+	if err != nil {
+		return err
+	}
+	if len(phase2.Contributions) == 0 {
+		return fmt.Errorf("%w: phase 2 without contribution", errUsage)
+	}
+	pk, vk, err := phase2.ExtractKeys(evaluations)
+	if err != nil {
+		return err
+	}
+	return bundle.WriteKeys(pk, vk, nil)
+}
+
+/*
+	readPhase1: read the phase 1 and verify it against the imported one if
+	initialPath is set, or else against the one of phase1-new
+*/
+func readPhase1(initialPath, path string) (phase1 *mpcsetup.Phase1, err error) {
+	var initial *mpcsetup.Phase1
+	if initialPath != "" {
+		initial = new(mpcsetup.Phase1)
+		err = prover.ReadFile(initialPath, initial)
+		if err != nil {
+			return nil, err
+		}
+	}
+	phase1 = new(mpcsetup.Phase1)
+	err = prover.ReadFile(path, phase1)
+	if err != nil {
+		return nil, err
+	}
+	err = phase1.Verify(initial)
+	if err != nil {
+		return nil, err
+	}
+	return phase1, nil
+}
+
+/*
+	initPhase2: phase 2 of the circuit of the groth16 bundle before any
+	contribution, computed from the phase 1 verified as in readPhase1
+*/
+func initPhase2(dir, phase1InitialPath, phase1Path string) (
+	bundle *prover.Bundle, initial *mpcsetup.Phase2, evaluations *mpcsetup.Phase2Evaluations, err error,
+) {
+	bundle, err = prover.ReadBundle(dir)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if bundle.Manifest.Backend != prover.BackendGroth16 {
+		return nil, nil, nil, fmt.Errorf("bundle is built for %s, the ceremony is only for groth16", bundle.Manifest.Backend)
+	}
+	ccs, err := bundle.ReadCompiledConstraints()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	phase1, err := readPhase1(phase1InitialPath, phase1Path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	initial, evaluations, err = mpcsetup.InitPhase2(ccs, phase1)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return bundle, initial, evaluations, nil
+}
+
+/*
+	readPhase2: read the phase 2 and verify it against the initial one, which
+	must be the one phase2-init computes from the bundle and the phase 1, so
+	that the evaluations of the keys are the ones of the circuit
+*/
+func readPhase2(dir, phase1InitialPath, phase1Path, initialPath, path string) (
+	bundle *prover.Bundle, phase2 *mpcsetup.Phase2, evaluations *mpcsetup.Phase2Evaluations, err error,
+) {
+	bundle, initial, evaluations, err := initPhase2(dir, phase1InitialPath, phase1Path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	published := new(mpcsetup.Phase2)
+	err = prover.ReadFile(initialPath, published)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if published.InitialHash != initial.InitialHash {
+		return nil, nil, nil, fmt.Errorf("%s isn't the phase 2 of the circuit and the phase 1", initialPath)
+	}
+	phase2 = new(mpcsetup.Phase2)
+	err = prover.ReadFile(path, phase2)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	err = phase2.Verify(initial)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return bundle, phase2, evaluations, nil
+}
+
+func printContributionHash(hash []byte, err error) error {
+	if err != nil {
+		return err
+	}
+	fmt.Println(hex.EncodeToString(hash))
+	return nil
+}
@@ -18,6 +18,8 @@
 package block
 
 import (
+	"math/big"
+
 	"github.com/consensys/gnark/std/signature/eddsa"
 	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
 )
@@ -90,6 +92,8 @@ func GetLiquidityDeltaFromCreatePair(
 		FeeRate:              txInfo.FeeRate,
 		TreasuryAccountIndex: txInfo.TreasuryAccountIndex,
 		TreasuryRate:         txInfo.TreasuryRate,
+		PairType:             txInfo.PairType,
+		Amplification:        txInfo.Amplification,
 	}
 	return liquidityDelta
 }
@@ -108,6 +112,8 @@ func GetLiquidityDeltaFromUpdatePairRate(
 		FeeRate:              txInfo.FeeRate,
 		TreasuryAccountIndex: txInfo.TreasuryAccountIndex,
 		TreasuryRate:         txInfo.TreasuryRate,
+		PairType:             liquidityBefore.PairType,
+		Amplification:        liquidityBefore.Amplification,
 	}
 	return liquidityDelta
 }
@@ -304,11 +310,84 @@ func GetAssetDeltasAndLiquidityDeltaFromSwap(
 		FeeRate:              liquidityBefore.FeeRate,
 		TreasuryAccountIndex: liquidityBefore.TreasuryAccountIndex,
 		TreasuryRate:         liquidityBefore.TreasuryRate,
+		PairType:             liquidityBefore.PairType,
+		Amplification:        liquidityBefore.Amplification,
 	}
 	return deltas, liquidityDelta
 }
 
-// TODO treasury lp
+/*
+	GetAssetDeltasAndLiquidityDeltasFromRouteSwap: the intermediate assets only move
+	between the pairs of the route, the deltas of the unused pairs aren't applied
+*/
+func GetAssetDeltasAndLiquidityDeltasFromRouteSwap(
+	api API,
+	flag Variable,
+	txInfo RouteSwapTxConstraints,
+	liquiditiesBefore [NbRoutePairsPerTx]LiquidityConstraints,
+) (deltas [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints, liquidityDeltas [NbRoutePairsPerTx]LiquidityDeltaConstraints) {
+	// from account
+	deltas[0] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		// asset A
+		{
+			BalanceDelta:             api.Neg(txInfo.AssetAAmount),
+			LpDelta:                  std.ZeroInt,
+			OfferCanceledOrFinalized: std.ZeroInt,
+		},
+		// asset B
+		{
+			BalanceDelta:             txInfo.AssetBAmountDelta,
+			LpDelta:                  std.ZeroInt,
+			OfferCanceledOrFinalized: std.ZeroInt,
+		},
+		// asset gas
+		{
+			BalanceDelta:             api.Neg(txInfo.GasFeeAssetAmount),
+			LpDelta:                  std.ZeroInt,
+			OfferCanceledOrFinalized: std.ZeroInt,
+		},
+		EmptyAccountAssetDeltaConstraints(),
+	}
+	// gas account
+	deltas[1] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		// asset gas
+		{
+			BalanceDelta:             txInfo.GasFeeAssetAmount,
+			LpDelta:                  std.ZeroInt,
+			OfferCanceledOrFinalized: std.ZeroInt,
+		},
+		EmptyAccountAssetDeltaConstraints(),
+		EmptyAccountAssetDeltaConstraints(),
+		EmptyAccountAssetDeltaConstraints(),
+	}
+	for i := 2; i < NbAccountsPerTx; i++ {
+		deltas[i] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+			EmptyAccountAssetDeltaConstraints(),
+			EmptyAccountAssetDeltaConstraints(),
+			EmptyAccountAssetDeltaConstraints(),
+			EmptyAccountAssetDeltaConstraints(),
+		}
+	}
+	hops := std.GetRouteSwapHops(api, flag, txInfo, liquiditiesBefore)
+	for i := 0; i < NbRoutePairsPerTx; i++ {
+		negAmountOut := api.Neg(hops[i].AmountOut)
+		liquidityDeltas[i] = LiquidityDeltaConstraints{
+			AssetAId:             liquiditiesBefore[i].AssetAId,
+			AssetBId:             liquiditiesBefore[i].AssetBId,
+			AssetADelta:          api.Select(hops[i].IsAssetAIn, hops[i].AmountIn, negAmountOut),
+			AssetBDelta:          api.Select(hops[i].IsAssetAIn, negAmountOut, hops[i].AmountIn),
+			LpDelta:              std.ZeroInt,
+			KLast:                liquiditiesBefore[i].KLast,
+			FeeRate:              liquiditiesBefore[i].FeeRate,
+			TreasuryAccountIndex: liquiditiesBefore[i].TreasuryAccountIndex,
+			TreasuryRate:         liquiditiesBefore[i].TreasuryRate,
+			PairType:             liquiditiesBefore[i].PairType,
+			Amplification:        liquiditiesBefore[i].Amplification,
+		}
+	}
+	return deltas, liquidityDeltas
+}
+
 func GetAssetDeltasAndLiquidityDeltaFromAddLiquidity(
 	api API,
 	txInfo AddLiquidityTxConstraints,
@@ -378,16 +457,17 @@ func GetAssetDeltasAndLiquidityDeltaFromAddLiquidity(
 		AssetBId:             liquidityBefore.AssetBId,
 		AssetADelta:          txInfo.AssetAAmount,
 		AssetBDelta:          txInfo.AssetBAmount,
-		LpDelta:              txInfo.LpAmount,
+		LpDelta:              api.Add(txInfo.LpAmount, txInfo.TreasuryAmount),
 		KLast:                api.Mul(poolA, poolB),
 		FeeRate:              liquidityBefore.FeeRate,
 		TreasuryAccountIndex: liquidityBefore.TreasuryAccountIndex,
 		TreasuryRate:         liquidityBefore.TreasuryRate,
+		PairType:             liquidityBefore.PairType,
+		Amplification:        liquidityBefore.Amplification,
 	}
 	return deltas, liquidityDelta
 }
 
-// TODO treasury lp
 func GetAssetDeltasAndLiquidityDeltaFromRemoveLiquidity(
 	api API,
 	txInfo RemoveLiquidityTxConstraints,
@@ -457,11 +537,13 @@ func GetAssetDeltasAndLiquidityDeltaFromRemoveLiquidity(
 		AssetBId:             liquidityBefore.AssetBId,
 		AssetADelta:          api.Neg(txInfo.AssetAAmountDelta),
 		AssetBDelta:          api.Neg(txInfo.AssetBAmountDelta),
-		LpDelta:              api.Neg(txInfo.LpAmount),
+		LpDelta:              api.Sub(txInfo.TreasuryAmount, txInfo.LpAmount),
 		KLast:                api.Mul(poolA, poolB),
 		FeeRate:              liquidityBefore.FeeRate,
 		TreasuryAccountIndex: liquidityBefore.TreasuryAccountIndex,
 		TreasuryRate:         liquidityBefore.TreasuryRate,
+		PairType:             liquidityBefore.PairType,
+		Amplification:        liquidityBefore.Amplification,
 	}
 	return deltas, liquidityDelta
 }
@@ -841,6 +923,43 @@ func GetAssetDeltasAndNftDeltaFromWithdrawNft(
 	return deltas, nftDelta
 }
 
+func GetAssetDeltasFromChangePubKey(
+	api API,
+	txInfo ChangePubKeyTxConstraints,
+) (deltas [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints) {
+	// from account
+	deltas[0] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		{
+			BalanceDelta:             api.Neg(txInfo.GasFeeAssetAmount),
+			LpDelta:                  std.ZeroInt,
+			OfferCanceledOrFinalized: std.ZeroInt,
+		},
+		EmptyAccountAssetDeltaConstraints(),
+		EmptyAccountAssetDeltaConstraints(),
+		EmptyAccountAssetDeltaConstraints(),
+	}
+	// gas account
+	deltas[1] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		{
+			BalanceDelta:             txInfo.GasFeeAssetAmount,
+			LpDelta:                  std.ZeroInt,
+			OfferCanceledOrFinalized: std.ZeroInt,
+		},
+		EmptyAccountAssetDeltaConstraints(),
+		EmptyAccountAssetDeltaConstraints(),
+		EmptyAccountAssetDeltaConstraints(),
+	}
+	for i := 2; i < NbAccountsPerTx; i++ {
+		deltas[i] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+			EmptyAccountAssetDeltaConstraints(),
+			EmptyAccountAssetDeltaConstraints(),
+			EmptyAccountAssetDeltaConstraints(),
+			EmptyAccountAssetDeltaConstraints(),
+		}
+	}
+	return deltas
+}
+
 func GetAssetDeltasFromFullExit(
 	api API,
 	txInfo FullExitTxConstraints,
@@ -879,3 +998,140 @@ func GetNftDeltaFromFullExitNft() (nftDelta NftDeltaConstraints) {
 	}
 	return nftDelta
 }
+
+/*
+	GetAssetDeltasFromMatchOrder: the filled amounts of the orders are kept above the
+	offer bits of their order slots, the checks of the tx keep them below the field size
+*/
+func GetAssetDeltasFromMatchOrder(
+	api API,
+	txInfo MatchOrderTxConstraints,
+	accountsBefore [NbAccountsPerTx]std.AccountConstraints,
+) (deltas [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints) {
+	filledAmountDelta := api.Mul(txInfo.AssetAFillAmount, new(big.Int).Lsh(big.NewInt(1), OfferSizePerAsset))
+	// submitter
+	deltas[0] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		{
+			BalanceDelta:             api.Neg(txInfo.GasFeeAssetAmount),
+			LpDelta:                  std.ZeroInt,
+			OfferCanceledOrFinalized: std.ZeroInt,
+		},
+		EmptyAccountAssetDeltaConstraints(),
+		EmptyAccountAssetDeltaConstraints(),
+		EmptyAccountAssetDeltaConstraints(),
+	}
+	// buyer
+	deltas[1] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		// asset B
+		{
+			BalanceDelta:             api.Neg(txInfo.AssetBFillAmount),
+			LpDelta:                  std.ZeroInt,
+			OfferCanceledOrFinalized: std.ZeroInt,
+		},
+		// asset A
+		{
+			BalanceDelta:             txInfo.AssetAFillAmount,
+			LpDelta:                  std.ZeroInt,
+			OfferCanceledOrFinalized: std.ZeroInt,
+		},
+		// order slot
+		{
+			BalanceDelta:             std.ZeroInt,
+			LpDelta:                  std.ZeroInt,
+			OfferCanceledOrFinalized: api.Add(accountsBefore[1].AssetsInfo[2].OfferCanceledOrFinalized, filledAmountDelta),
+		},
+		EmptyAccountAssetDeltaConstraints(),
+	}
+	// seller
+	deltas[2] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		// asset A
+		{
+			BalanceDelta:             api.Neg(txInfo.AssetAFillAmount),
+			LpDelta:                  std.ZeroInt,
+			OfferCanceledOrFinalized: std.ZeroInt,
+		},
+		// asset B
+		{
+			BalanceDelta:             txInfo.AssetBFillAmount,
+			LpDelta:                  std.ZeroInt,
+			OfferCanceledOrFinalized: std.ZeroInt,
+		},
+		// order slot
+		{
+			BalanceDelta:             std.ZeroInt,
+			LpDelta:                  std.ZeroInt,
+			OfferCanceledOrFinalized: api.Add(accountsBefore[2].AssetsInfo[2].OfferCanceledOrFinalized, filledAmountDelta),
+		},
+		EmptyAccountAssetDeltaConstraints(),
+	}
+	// gas account
+	deltas[3] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		{
+			BalanceDelta:             txInfo.GasFeeAssetAmount,
+			LpDelta:                  std.ZeroInt,
+			OfferCanceledOrFinalized: std.ZeroInt,
+		},
+		EmptyAccountAssetDeltaConstraints(),
+		EmptyAccountAssetDeltaConstraints(),
+		EmptyAccountAssetDeltaConstraints(),
+	}
+	for i := 4; i < NbAccountsPerTx; i++ {
+		deltas[i] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+			EmptyAccountAssetDeltaConstraints(),
+			EmptyAccountAssetDeltaConstraints(),
+			EmptyAccountAssetDeltaConstraints(),
+			EmptyAccountAssetDeltaConstraints(),
+		}
+	}
+	return deltas
+}
+
+/*
+	GetAssetDeltasFromCancelOrder: the filled amount of the order slot is replaced by
+	std.CanceledOrderFilledAmount, its offer bits are kept
+*/
+func GetAssetDeltasFromCancelOrder(
+	api API,
+	txInfo CancelOrderTxConstraints,
+	accountsBefore [NbAccountsPerTx]std.AccountConstraints,
+) (deltas [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints) {
+	offerBits, _ := std.SplitOfferCanceledOrFinalized(api, accountsBefore[0].AssetsInfo[1].OfferCanceledOrFinalized)
+	canceledFilledAmount := new(big.Int).Lsh(std.CanceledOrderFilledAmount, OfferSizePerAsset)
+	// from account
+	deltas[0] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		// asset Gas
+		{
+			BalanceDelta:             api.Neg(txInfo.GasFeeAssetAmount),
+			LpDelta:                  std.ZeroInt,
+			OfferCanceledOrFinalized: std.ZeroInt,
+		},
+		// order slot
+		{
+			BalanceDelta:             std.ZeroInt,
+			LpDelta:                  std.ZeroInt,
+			OfferCanceledOrFinalized: api.Add(api.FromBinary(offerBits...), canceledFilledAmount),
+		},
+		EmptyAccountAssetDeltaConstraints(),
+		EmptyAccountAssetDeltaConstraints(),
+	}
+	// gas account
+	deltas[1] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+		{
+			BalanceDelta:             txInfo.GasFeeAssetAmount,
+			LpDelta:                  std.ZeroInt,
+			OfferCanceledOrFinalized: std.ZeroInt,
+		},
+		EmptyAccountAssetDeltaConstraints(),
+		EmptyAccountAssetDeltaConstraints(),
+		EmptyAccountAssetDeltaConstraints(),
+	}
+	for i := 2; i < NbAccountsPerTx; i++ {
+		deltas[i] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
+			EmptyAccountAssetDeltaConstraints(),
+			EmptyAccountAssetDeltaConstraints(),
+			EmptyAccountAssetDeltaConstraints(),
+			EmptyAccountAssetDeltaConstraints(),
+		}
+	}
+	return deltas
+}
@@ -18,10 +18,12 @@
 package block
 
 type Block struct {
-	BlockNumber     int64
-	CreatedAt       int64
-	OldStateRoot    []byte
-	NewStateRoot    []byte
-	BlockCommitment []byte
-	Txs             []*Tx
+	BlockNumber          int64
+	CreatedAt            int64
+	OldStateRoot         []byte
+	NewStateRoot         []byte
+	BlockCommitment      []byte
+	PriorityOpsHashStart []byte
+	PriorityOpsHashEnd   []byte
+	Txs                  []*Tx
 }
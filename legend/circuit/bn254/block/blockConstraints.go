@@ -18,19 +18,23 @@
 package block
 
 import (
+	"errors"
+
 	"github.com/consensys/gnark/std/hash/mimc"
 	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
 	"log"
 )
 
 type BlockConstraints struct {
-	BlockNumber     Variable
-	CreatedAt       Variable
-	OldStateRoot    Variable `gnark:",public"`
-	NewStateRoot    Variable `gnark:",public"`
-	BlockCommitment Variable `gnark:",public"`
-	Txs             []TxConstraints
-	TxsCount        int
+	BlockNumber          Variable
+	CreatedAt            Variable
+	OldStateRoot         Variable `gnark:",public"`
+	NewStateRoot         Variable `gnark:",public"`
+	BlockCommitment      Variable `gnark:",public"`
+	PriorityOpsHashStart Variable `gnark:",public"`
+	PriorityOpsHashEnd   Variable `gnark:",public"`
+	Txs                  []TxConstraints
+	TxsCount             int
 }
 
 func (circuit BlockConstraints) Define(api API) error {
@@ -40,81 +44,184 @@ func (circuit BlockConstraints) Define(api API) error {
 		return err
 	}
 
-	pubdataHashFunc, err := mimc.NewMiMC(api)
-	if err != nil {
-		return err
-	}
-
-	err = VerifyBlock(api, circuit, hFunc, pubdataHashFunc)
+	err = VerifyBlock(api, circuit, hFunc)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+/*
+	VerifyBlock: verify all txs of the block and its commitment.
+	The commitment is keccak256 of
+	BlockNumber | CreatedAt | OldStateRoot | NewStateRoot | PubDataChunks | PubData | OnChainOpsCount | OnChainOps
+	PubData holds the used chunks of all txs packed densely and is padded with 0 to
+	PubDataSizePerTx * TxsCount chunks, so only the used chunks need to be sent to L1.
+	OnChainOps lists the first pubdata chunk and the tx type of every on-chain op, see
+	PackOnChainOps, so L1 only reads the chunks of these txs.
+	The priority ops of the block are folded in order into the rolling hash from
+	PriorityOpsHashStart to PriorityOpsHashEnd, see UpdatePriorityOpsHash, so they
+	match the queue of the L1 contract.
+	Each tx slot is compiled for the tx types of its SlotType, see NewBlockConstraints.
+*/
 func VerifyBlock(
 	api API,
 	block BlockConstraints,
 	hFunc MiMC,
-	pubdataHashFunc MiMC,
 ) (err error) {
+	defer std.ProfileScope(api, "VerifyBlock")()
+	if std.PubDataSizePerTx*block.TxsCount >= 1<<OnChainOpOffsetBitsSize {
+		log.Println("[VerifyBlock] too many txs for the on-chain ops")
+		return errors.New("[VerifyBlock] too many txs for the on-chain ops")
+	}
 	var (
-		onChainOpsCount Variable
-		isOnChainOp     Variable
-		// pendingCommitmentData [std.PubDataSizePerTx*block.TxsCount + 5]Variable
-		count = 4
+		isOnChainOp    Variable
+		pendingPubData [std.PubDataSizePerTx]Variable
+		pubDataChunks  Variable
 	)
-	pendingCommitmentData := make([]Variable, std.PubDataSizePerTx*block.TxsCount+5)
-	// write basic info into hFunc
-	pendingCommitmentData[0] = block.BlockNumber
-	pendingCommitmentData[1] = block.CreatedAt
-	pendingCommitmentData[2] = block.OldStateRoot
-	pendingCommitmentData[3] = block.NewStateRoot
-	api.AssertIsEqual(block.OldStateRoot, block.Txs[0].StateRootBefore)
-	isEmptyTx := api.IsZero(api.Sub(block.Txs[block.TxsCount-1].TxType, std.TxTypeEmptyTx))
-	notEmptyTx := api.IsZero(isEmptyTx)
-	std.IsVariableEqual(api, notEmptyTx, block.NewStateRoot, block.Txs[block.TxsCount-1].StateRootAfter)
-	onChainOpsCount = 0
-	isOnChainOp, pendingPubData, err := VerifyTransaction(api, block.Txs[0], hFunc, block.CreatedAt)
-	if err != nil {
-		log.Println("[VerifyBlock] unable to verify block:", err)
-		return err
-	}
-	for i := 0; i < std.PubDataSizePerTx; i++ {
-		pendingCommitmentData[count] = pendingPubData[i]
-		count++
-	}
-	onChainOpsCount = api.Add(onChainOpsCount, isOnChainOp)
-	for i := 1; i < int(block.TxsCount); i++ {
+	txsPubData := make([][std.PubDataSizePerTx]Variable, block.TxsCount)
+	txsPubDataChunks := make([]Variable, block.TxsCount)
+	txsPubDataOffset := make([]Variable, block.TxsCount)
+	txsType := make([]Variable, block.TxsCount)
+	isOnChainOps := make([]Variable, block.TxsCount)
+	// empty txs keep the state root, so they can fill any slot of the block
+	stateRoot := block.OldStateRoot
+	priorityOpsHash := block.PriorityOpsHashStart
+	pubDataOffset := Variable(0)
+	for i := 0; i < block.TxsCount; i++ {
 		isEmptyTx := api.IsZero(api.Sub(block.Txs[i].TxType, std.TxTypeEmptyTx))
 		notEmptyTx := api.IsZero(isEmptyTx)
-		std.IsVariableEqual(api, notEmptyTx, block.Txs[i-1].StateRootAfter, block.Txs[i].StateRootBefore)
-		hFunc.Reset()
-		isOnChainOp, pendingPubData, err = VerifyTransaction(api, block.Txs[i], hFunc, block.CreatedAt)
+		std.IsVariableEqual(api, notEmptyTx, stateRoot, block.Txs[i].StateRootBefore)
+		stateRoot = api.Select(notEmptyTx, block.Txs[i].StateRootAfter, stateRoot)
+		if i > 0 {
+			hFunc.Reset()
+		}
+		isOnChainOp, pendingPubData, pubDataChunks, err = VerifyTransaction(api, block.Txs[i], hFunc, block.CreatedAt)
 		if err != nil {
 			log.Println("[VerifyBlock] unable to verify block:", err)
 			return err
 		}
-		for j := 0; j < std.PubDataSizePerTx; j++ {
-			pendingCommitmentData[count] = pendingPubData[j]
-			count++
-		}
-		onChainOpsCount = api.Add(onChainOpsCount, isOnChainOp)
+		txsPubData[i] = pendingPubData
+		txsPubDataChunks[i] = pubDataChunks
+		txsPubDataOffset[i] = pubDataOffset
+		txsType[i] = block.Txs[i].TxType
+		isOnChainOps[i] = isOnChainOp
+		pubDataOffset = api.Add(pubDataOffset, pubDataChunks)
+		priorityOpsHash = UpdatePriorityOpsHash(api, block.Txs[i], pendingPubData, priorityOpsHash)
+	}
+	api.AssertIsEqual(block.NewStateRoot, stateRoot)
+	api.AssertIsEqual(block.PriorityOpsHashEnd, priorityOpsHash)
+	endPackPubData := std.ProfileScope(api, "VerifyBlock/PackPubData")
+	pubData, pubDataChunks := PackPubData(api, txsPubData, txsPubDataChunks)
+	endPackPubData()
+	endPackOnChainOps := std.ProfileScope(api, "VerifyBlock/PackOnChainOps")
+	onChainOps, onChainOpsCount := PackOnChainOps(api, txsPubDataOffset, txsType, isOnChainOps)
+	endPackOnChainOps()
+	pendingCommitmentData := make([]Variable, 0, len(pubData)+len(onChainOps)+6)
+	pendingCommitmentData = append(
+		pendingCommitmentData,
+		block.BlockNumber,
+		block.CreatedAt,
+		block.OldStateRoot,
+		block.NewStateRoot,
+		pubDataChunks,
+	)
+	pendingCommitmentData = append(pendingCommitmentData, pubData...)
+	pendingCommitmentData = append(pendingCommitmentData, onChainOpsCount)
+	pendingCommitmentData = append(pendingCommitmentData, onChainOps...)
+	// commitment is computed by the same keccak256 as the L1 contract
+	endCommitment := std.ProfileScope(api, "VerifyBlock/commitment")
+	commitment := std.Keccak256Variables(api, pendingCommitmentData...)
+	endCommitment()
+	api.AssertIsEqual(commitment, block.BlockCommitment)
+	return nil
+}
+
+/*
+	CompressedBlockConstraints: BlockConstraints with a single public input, the
+	truncated keccak256 of OldStateRoot | NewStateRoot | BlockCommitment | BlockNumber | CreatedAt |
+	PriorityOpsHashStart | PriorityOpsHashEnd, so the L1 contract does one scalar multiplication
+	for the public inputs instead of five, and BlockNumber and CreatedAt are bound by the proof
+	instead of only through the commitment
+*/
+type CompressedBlockConstraints struct {
+	BlockNumber          Variable
+	CreatedAt            Variable
+	OldStateRoot         Variable
+	NewStateRoot         Variable
+	BlockCommitment      Variable
+	PriorityOpsHashStart Variable
+	PriorityOpsHashEnd   Variable
+	PublicInputHash      Variable `gnark:",public"`
+	Txs                  []TxConstraints
+	TxsCount             int
+}
+
+func (circuit CompressedBlockConstraints) Define(api API) error {
+	// mimc
+	hFunc, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+
+	err = VerifyBlock(api, circuit.BlockConstraints(), hFunc)
+	if err != nil {
+		return err
 	}
-	pendingCommitmentData[count] = onChainOpsCount
-	//commitment := pubdataHashFunc.Sum()
-	commitments, _ := api.Compiler().NewHint(std.Keccak256, 1, pendingCommitmentData[:]...)
-	api.AssertIsEqual(commitments[0], block.BlockCommitment)
+	VerifyPublicInputHash(api, circuit)
 	return nil
 }
 
+/*
+	BlockConstraints: the block of the circuit, its public inputs are checked
+	against the public input hash instead of being public themselves
+*/
+func (circuit CompressedBlockConstraints) BlockConstraints() BlockConstraints {
+	return BlockConstraints{
+		BlockNumber:          circuit.BlockNumber,
+		CreatedAt:            circuit.CreatedAt,
+		OldStateRoot:         circuit.OldStateRoot,
+		NewStateRoot:         circuit.NewStateRoot,
+		BlockCommitment:      circuit.BlockCommitment,
+		PriorityOpsHashStart: circuit.PriorityOpsHashStart,
+		PriorityOpsHashEnd:   circuit.PriorityOpsHashEnd,
+		Txs:                  circuit.Txs,
+		TxsCount:             circuit.TxsCount,
+	}
+}
+
+/*
+	VerifyPublicInputHash: the public input hash is keccak256 of
+	OldStateRoot | NewStateRoot | BlockCommitment | BlockNumber | CreatedAt |
+	PriorityOpsHashStart | PriorityOpsHashEnd
+	keeping its low PublicInputHashBitsSize bits, see ComputePublicInputHash
+*/
+func VerifyPublicInputHash(api API, block CompressedBlockConstraints) {
+	defer std.ProfileScope(api, "VerifyPublicInputHash")()
+	publicInputHash := std.TruncatedKeccak256Variables(
+		api,
+		PublicInputHashBitsSize,
+		block.OldStateRoot,
+		block.NewStateRoot,
+		block.BlockCommitment,
+		block.BlockNumber,
+		block.CreatedAt,
+		block.PriorityOpsHashStart,
+		block.PriorityOpsHashEnd,
+	)
+	api.AssertIsEqual(publicInputHash, block.PublicInputHash)
+}
+
 func SetBlockWitness(oBlock *Block) (witness BlockConstraints, err error) {
 	witness = BlockConstraints{
-		BlockNumber:     oBlock.BlockNumber,
-		CreatedAt:       oBlock.CreatedAt,
-		OldStateRoot:    oBlock.OldStateRoot,
-		NewStateRoot:    oBlock.NewStateRoot,
-		BlockCommitment: oBlock.BlockCommitment,
+		BlockNumber:          oBlock.BlockNumber,
+		CreatedAt:            oBlock.CreatedAt,
+		OldStateRoot:         oBlock.OldStateRoot,
+		NewStateRoot:         oBlock.NewStateRoot,
+		BlockCommitment:      oBlock.BlockCommitment,
+		PriorityOpsHashStart: oBlock.PriorityOpsHashStart,
+		PriorityOpsHashEnd:   oBlock.PriorityOpsHashEnd,
+		TxsCount:             len(oBlock.Txs),
 	}
 	for i := 0; i < len(oBlock.Txs); i++ {
 		tx, err := SetTxWitness(oBlock.Txs[i])
@@ -127,6 +234,31 @@ func SetBlockWitness(oBlock *Block) (witness BlockConstraints, err error) {
 	return witness, nil
 }
 
+func SetCompressedBlockWitness(oBlock *Block) (witness CompressedBlockConstraints, err error) {
+	blockWitness, err := SetBlockWitness(oBlock)
+	if err != nil {
+		return witness, err
+	}
+	publicInputHash, err := ComputePublicInputHash(oBlock)
+	if err != nil {
+		log.Println("[SetCompressedBlockWitness] unable to compute public input hash: ", err.Error())
+		return witness, err
+	}
+	witness = CompressedBlockConstraints{
+		BlockNumber:          blockWitness.BlockNumber,
+		CreatedAt:            blockWitness.CreatedAt,
+		OldStateRoot:         blockWitness.OldStateRoot,
+		NewStateRoot:         blockWitness.NewStateRoot,
+		BlockCommitment:      blockWitness.BlockCommitment,
+		PriorityOpsHashStart: blockWitness.PriorityOpsHashStart,
+		PriorityOpsHashEnd:   blockWitness.PriorityOpsHashEnd,
+		PublicInputHash:      publicInputHash,
+		Txs:                  blockWitness.Txs,
+		TxsCount:             blockWitness.TxsCount,
+	}
+	return witness, nil
+}
+
 func GetZeroTxConstraint() TxConstraints {
 	var zeroTxConstraint TxConstraints
 	zeroTxConstraint.TxType = 0
@@ -148,7 +280,15 @@ func GetZeroTxConstraint() TxConstraints {
 	zeroTxConstraint.WithdrawNftTxInfo = std.EmptyWithdrawNftTxWitness()
 	zeroTxConstraint.FullExitTxInfo = std.EmptyFullExitTxWitness()
 	zeroTxConstraint.FullExitNftTxInfo = std.EmptyFullExitNftTxWitness()
+	zeroTxConstraint.ChangePubKeyTxInfo = std.EmptyChangePubKeyTxWitness()
+	zeroTxConstraint.FullChangePubKeyTxInfo = std.EmptyFullChangePubKeyTxWitness()
+	zeroTxConstraint.MatchOrderTxInfo = std.EmptyMatchOrderTxWitness()
+	zeroTxConstraint.RouteSwapTxInfo = std.EmptyRouteSwapTxWitness()
+	zeroTxConstraint.CancelOrderTxInfo = std.EmptyCancelOrderTxWitness()
 	zeroTxConstraint.Signature = EmptySignatureWitness()
+	for i := 0; i < NbMultiSigsPerTx; i++ {
+		zeroTxConstraint.MultiSigs[i] = std.EmptyMultiSigWitness()
+	}
 	zeroTxConstraint.Nonce = 0
 	zeroTxConstraint.ExpiredAt = 0
 
@@ -172,6 +312,11 @@ func GetZeroTxConstraint() TxConstraints {
 		FeeRate:              0,
 		TreasuryAccountIndex: 0,
 		TreasuryRate:         0,
+		PairType:             0,
+		Amplification:        0,
+		PriceACumulativeLast: 0,
+		PriceBCumulativeLast: 0,
+		BlockTimestampLast:   0,
 	}
 
 	zeroTxConstraint.NftBefore = NftConstraints{
@@ -194,6 +339,8 @@ func GetZeroTxConstraint() TxConstraints {
 			Nonce:           0,
 			CollectionNonce: 0,
 			AssetRoot:       0,
+			SignerRoot:      0,
+			SignerThreshold: 0,
 		}
 		// set assets witness
 		for i := 0; i < NbAccountAssetsPerAccount; i++ {
@@ -221,6 +368,12 @@ func GetZeroTxConstraint() TxConstraints {
 		// liquidity assets before
 		zeroTxConstraint.MerkleProofsLiquidityBefore[i] = 0
 	}
+	for i := 0; i < NbRoutePairsPerTx-1; i++ {
+		zeroTxConstraint.RouteLiquiditiesBefore[i] = zeroTxConstraint.LiquidityBefore
+		for j := 0; j < LiquidityMerkleLevels; j++ {
+			zeroTxConstraint.MerkleProofsRouteLiquiditiesBefore[i][j] = 0
+		}
+	}
 	for i := 0; i < NftMerkleLevels; i++ {
 		// nft assets before
 		zeroTxConstraint.MerkleProofsNftBefore[i] = 0
@@ -34,15 +34,26 @@ func TestVerifyBlock_AtomicMatch(t *testing.T) {
 	if err != nil {
 		panic(err)
 	}
+	// fixtures were generated with the fixed-size pubdata layout
+	oBlock.BlockCommitment, err = ComputeBlockCommitment(oBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oBlock.PriorityOpsHashEnd, err = ComputePriorityOpsHash(oBlock.PriorityOpsHashStart, oBlock.Txs)
+	if err != nil {
+		t.Fatal(err)
+	}
 	assert := test.NewAssert(t)
 	var circuit, witness BlockConstraints
 	witness, err = SetBlockWitness(oBlock)
 	if err != nil {
 		t.Fatal(err)
 	}
+	circuit.TxsCount = witness.TxsCount
+	circuit.Txs = make([]TxConstraints, circuit.TxsCount)
 	assert.SolvingSucceeded(
 		&circuit, &witness, test.WithBackends(backend.GROTH16),
 		test.WithCurves(ecc.BN254),
-		test.WithProverOpts(backend.WithHints(std.Keccak256, std.ComputeSLp)),
+		test.WithProverOpts(backend.WithHints(std.ComputeSLp, std.ComputeStableSwap, std.ComputePriceCumulative)),
 		test.WithCompileOpts(frontend.IgnoreUnconstrainedInputs()))
 }
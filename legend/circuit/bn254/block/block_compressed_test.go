@@ -0,0 +1,83 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package block
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/test"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+func TestVerifyCompressedBlock(t *testing.T) {
+	var oTx *Tx
+	err := json.Unmarshal([]byte(readTxInfo(t, "depositTx")), &oTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slotTypes := []int{TxSlotTypePriorityOp}
+	oBlock := &Block{
+		BlockNumber:  1,
+		CreatedAt:    1655348736095,
+		OldStateRoot: oTx.StateRootBefore,
+		NewStateRoot: oTx.StateRootAfter,
+		Txs:          []*Tx{oTx},
+		// hash of the priority ops of the previous blocks
+		PriorityOpsHashStart: big.NewInt(7).FillBytes(make([]byte, 32)),
+	}
+	oBlock.BlockCommitment, err = ComputeBlockCommitment(oBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oBlock.PriorityOpsHashEnd, err = ComputePriorityOpsHash(oBlock.PriorityOpsHashStart, oBlock.Txs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicInputHash, err := ComputePublicInputHash(oBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if new(big.Int).SetBytes(publicInputHash).BitLen() > PublicInputHashBitsSize {
+		t.Fatal("public input hash isn't truncated")
+	}
+	witness, err := SetCompressedBlockWitness(oBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	circuit := NewCompressedBlockConstraints(slotTypes)
+	err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16, backend.WithHints(std.ComputeSLp, std.ComputeStableSwap, std.ComputePriceCumulative))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the public input hash of another block number isn't accepted
+	oBlock.BlockNumber++
+	witness.PublicInputHash, err = ComputePublicInputHash(oBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	circuit = NewCompressedBlockConstraints(slotTypes)
+	err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16, backend.WithHints(std.ComputeSLp, std.ComputeStableSwap, std.ComputePriceCumulative))
+	if err == nil {
+		t.Fatal("invalid public input hash accepted")
+	}
+}
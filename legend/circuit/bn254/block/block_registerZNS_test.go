@@ -62,6 +62,8 @@ func TestVerifyBlock_RegisterZNS(t *testing.T) {
 	commitBuf.Write(new(big.Int).SetInt64(createdAt).FillBytes(make([]byte, 32)))
 	commitBuf.Write(new(big.Int).SetBytes(oTx.StateRootBefore).FillBytes(make([]byte, 32)))
 	commitBuf.Write(new(big.Int).SetBytes(oTx.StateRootAfter).FillBytes(make([]byte, 32)))
+	// pub data chunks
+	commitBuf.Write(new(big.Int).SetInt64(std.RegisterZnsPubDataChunks).FillBytes(make([]byte, 32)))
 	// pub data
 	var buf bytes.Buffer
 	buf.WriteByte(oTx.TxType)
@@ -82,6 +84,9 @@ func TestVerifyBlock_RegisterZNS(t *testing.T) {
 	commitBuf.Write(new(big.Int).SetInt64(0).FillBytes(make([]byte, 32)))
 	// ops count
 	commitBuf.Write(new(big.Int).SetInt64(1).FillBytes(make([]byte, 32)))
+	// on-chain ops, the register zns tx starts at chunk 0
+	onChainOp := new(big.Int).SetInt64(int64(oTx.TxType))
+	commitBuf.Write(onChainOp.Lsh(onChainOp, (OnChainOpsPerWord-1)*OnChainOpBitsSize).FillBytes(make([]byte, 32)))
 	commitment := crypto.Keccak256Hash(commitBuf.Bytes())
 	log.Println(new(big.Int).SetBytes(commitment[:]).String())
 	oBlock = &Block{
@@ -92,15 +97,21 @@ func TestVerifyBlock_RegisterZNS(t *testing.T) {
 		BlockCommitment: commitment[:],
 		Txs:             []*Tx{oTx},
 	}
+	oBlock.PriorityOpsHashEnd, err = ComputePriorityOpsHash(oBlock.PriorityOpsHashStart, oBlock.Txs)
+	if err != nil {
+		t.Fatal(err)
+	}
 	assert := test.NewAssert(t)
 	var circuit, witness BlockConstraints
 	witness, err = SetBlockWitness(oBlock)
 	if err != nil {
 		t.Fatal(err)
 	}
+	circuit.TxsCount = witness.TxsCount
+	circuit.Txs = make([]TxConstraints, circuit.TxsCount)
 	assert.SolvingSucceeded(
 		&circuit, &witness, test.WithBackends(backend.GROTH16),
 		test.WithCurves(ecc.BN254),
-		test.WithProverOpts(backend.WithHints(std.Keccak256, std.ComputeSLp)),
+		test.WithProverOpts(backend.WithHints(std.ComputeSLp, std.ComputeStableSwap, std.ComputePriceCumulative)),
 		test.WithCompileOpts(frontend.IgnoreUnconstrainedInputs()))
 }
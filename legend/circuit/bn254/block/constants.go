@@ -51,6 +51,11 @@ type (
 	WithdrawNftTx      = std.WithdrawNftTx
 	FullExitTx         = std.FullExitTx
 	FullExitNftTx      = std.FullExitNftTx
+	ChangePubKeyTx     = std.ChangePubKeyTx
+	FullChangePubKeyTx = std.FullChangePubKeyTx
+	MatchOrderTx       = std.MatchOrderTx
+	RouteSwapTx        = std.RouteSwapTx
+	CancelOrderTx      = std.CancelOrderTx
 
 	RegisterZnsTxConstraints      = std.RegisterZnsTxConstraints
 	CreatePairTxConstraints       = std.CreatePairTxConstraints
@@ -70,6 +75,11 @@ type (
 	WithdrawNftTxConstraints      = std.WithdrawNftTxConstraints
 	FullExitTxConstraints         = std.FullExitTxConstraints
 	FullExitNftTxConstraints      = std.FullExitNftTxConstraints
+	ChangePubKeyTxConstraints     = std.ChangePubKeyTxConstraints
+	FullChangePubKeyTxConstraints = std.FullChangePubKeyTxConstraints
+	MatchOrderTxConstraints       = std.MatchOrderTxConstraints
+	RouteSwapTxConstraints        = std.RouteSwapTxConstraints
+	CancelOrderTxConstraints      = std.CancelOrderTxConstraints
 
 	LiquidityConstraints = std.LiquidityConstraints
 	NftConstraints       = std.NftConstraints
@@ -78,13 +88,30 @@ type (
 const (
 	NbAccountAssetsPerAccount = std.NbAccountAssetsPerAccount
 	NbAccountsPerTx           = std.NbAccountsPerTx
+	NbRoutePairsPerTx         = std.NbRoutePairsPerTx
+	NbMultiSigsPerTx          = std.NbMultiSigsPerTx
 	AssetMerkleLevels         = 16
 	LiquidityMerkleLevels     = 16
 	NftMerkleLevels           = 40
 	AccountMerkleLevels       = 32
 	RateBase                  = std.RateBase
 	OfferSizePerAsset         = 128
+	// below the bit size of the field order, so the truncated hash is a field element
+	PublicInputHashBitsSize = 253
+	// an on-chain op is its first pubdata chunk followed by its tx type
+	OnChainOpOffsetBitsSize = 16
+	OnChainOpBitsSize       = OnChainOpOffsetBitsSize + std.TxTypeBitsSize
+	// 240 bits, the words of the on-chain ops stay below the field order
+	OnChainOpsPerWord = 10
+	// the rolling hash of the priority ops is truncated like the public input hash
+	PriorityOpsHashBitsSize = PublicInputHashBitsSize
 
 	LastAccountIndex   = 4294967295
 	LastAccountAssetId = 65535
+	LastPairIndex      = 65535
+	LastNftIndex       = 1099511627775
+
+	// version of the constraints of the block and exodus circuits, bumped with every
+	// change of the constraints so the bundles compiled before aren't reused
+	CircuitVersion = 1
 )
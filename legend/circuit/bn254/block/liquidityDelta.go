@@ -29,6 +29,8 @@ type LiquidityDeltaConstraints struct {
 	FeeRate              Variable
 	TreasuryAccountIndex Variable
 	TreasuryRate         Variable
+	PairType             Variable
+	Amplification        Variable
 }
 
 func EmptyLiquidityDeltaConstraints() LiquidityDeltaConstraints {
@@ -42,6 +44,8 @@ func EmptyLiquidityDeltaConstraints() LiquidityDeltaConstraints {
 		FeeRate:              std.ZeroInt,
 		TreasuryAccountIndex: std.ZeroInt,
 		TreasuryRate:         std.ZeroInt,
+		PairType:             std.ZeroInt,
+		Amplification:        std.ZeroInt,
 	}
 }
 
@@ -60,5 +64,7 @@ func UpdateLiquidity(
 	liquidityAfter.FeeRate = liquidityDelta.FeeRate
 	liquidityAfter.TreasuryAccountIndex = liquidityDelta.TreasuryAccountIndex
 	liquidityAfter.TreasuryRate = liquidityDelta.TreasuryRate
+	liquidityAfter.PairType = liquidityDelta.PairType
+	liquidityAfter.Amplification = liquidityDelta.Amplification
 	return liquidityAfter
 }
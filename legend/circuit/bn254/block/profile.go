@@ -0,0 +1,66 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package block
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+/*
+	ProfileTxConstraints: compile the tx circuit of the slot type and
+	break its constraints down by component
+*/
+func ProfileTxConstraints(slotType int) (profile *std.ConstraintProfile, err error) {
+	var circuit TxConstraints
+	circuit.SlotType = slotType
+	oR1cs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &circuit, frontend.IgnoreUnconstrainedInputs())
+	if err != nil {
+		log.Println("[ProfileTxConstraints] unable to compile tx circuit:", err)
+		return nil, err
+	}
+	return std.NewConstraintProfile(fmt.Sprintf("tx[%s]", TxSlotTypeNames[slotType]), oR1cs), nil
+}
+
+/*
+	ProfileBlockConstraints: compile the block circuit of the layout and
+	break its constraints down by component
+*/
+func ProfileBlockConstraints(slotTypes []int) (profile *std.ConstraintProfile, err error) {
+	circuit := NewBlockConstraints(slotTypes)
+	oR1cs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &circuit, frontend.IgnoreUnconstrainedInputs())
+	if err != nil {
+		log.Println("[ProfileBlockConstraints] unable to compile block circuit:", err)
+		return nil, err
+	}
+	name := "block["
+	for i, slotType := range slotTypes {
+		if i > 0 {
+			name += ","
+		}
+		name += TxSlotTypeNames[slotType]
+	}
+	name += "]"
+	return std.NewConstraintProfile(name, oR1cs), nil
+}
@@ -0,0 +1,28 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+func TestProfileTxConstraints(t *testing.T) {
+	profile, err := ProfileTxConstraints(TxSlotTypePriorityOp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	components := make(map[string]std.ComponentProfile)
+	for _, component := range profile.Components {
+		components[component.Name] = component
+	}
+	if components["VerifyTransaction"].NbConstraints != profile.NbConstraints {
+		t.Fatal("invalid tx constraints")
+	}
+	if components["VerifyDepositTx"].Count != 1 || components["VerifyMerkleProof/account"].Count != 1 {
+		t.Fatal("missing components")
+	}
+	// priority ops aren't signed
+	if _, ok := components["VerifyEddsaSig"]; ok {
+		t.Fatal("signature verified in priority op slot")
+	}
+}
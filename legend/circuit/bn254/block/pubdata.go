@@ -0,0 +1,716 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package block
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+/*
+	pubDataField: a field of a pubdata chunk, chunks are read from the most significant bit
+*/
+type pubDataField struct {
+	Value    *big.Int
+	BitsSize int
+}
+
+/*
+	CollectPubDataFromTx: native counterpart of CollectPubDataFrom*, returns the used chunks of the tx
+*/
+func CollectPubDataFromTx(oTx *Tx) (pubData []byte, err error) {
+	var w pubDataWriter
+	switch oTx.TxType {
+	case std.TxTypeEmptyTx:
+		return nil, nil
+	case std.TxTypeRegisterZns:
+		txInfo := oTx.RegisterZnsTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeRegisterZns), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.AccountIndex), std.AccountIndexBitsSize},
+		)
+		w.word(txInfo.AccountName)
+		w.word(txInfo.AccountNameHash)
+		w.word(txInfo.PubKey.A.X.ToBigIntRegular(new(big.Int)))
+		w.word(txInfo.PubKey.A.Y.ToBigIntRegular(new(big.Int)))
+	case std.TxTypeCreatePair:
+		txInfo := oTx.CreatePairTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeCreatePair), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.PairIndex), std.PairIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.AssetAId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.AssetBId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.FeeRate), std.PackedFeeBitsSize},
+			pubDataField{big.NewInt(txInfo.TreasuryAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.TreasuryRate), std.PackedFeeBitsSize},
+			pubDataField{big.NewInt(txInfo.PairType), std.PairTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.Amplification), std.AmplificationBitsSize},
+		)
+	case std.TxTypeUpdatePairRate:
+		txInfo := oTx.UpdatePairRateTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeUpdatePairRate), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.PairIndex), std.PairIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.FeeRate), std.PackedFeeBitsSize},
+			pubDataField{big.NewInt(txInfo.TreasuryAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.TreasuryRate), std.PackedFeeBitsSize},
+		)
+	case std.TxTypeDeposit:
+		txInfo := oTx.DepositTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeDeposit), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.AccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.AssetId), std.AssetIdBitsSize},
+			pubDataField{txInfo.AssetAmount, std.StateAmountBitsSize},
+		)
+		w.word(txInfo.AccountNameHash)
+	case std.TxTypeDepositNft:
+		txInfo := oTx.DepositNftTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeDepositNft), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.AccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.NftIndex), std.NftIndexBitsSize},
+			pubDataField{stringToBigInt(txInfo.NftL1Address), std.AddressBitsSize},
+		)
+		w.rightAligned(
+			pubDataField{big.NewInt(txInfo.CreatorAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.CreatorTreasuryRate), std.CreatorTreasuryRateBitsSize},
+			pubDataField{big.NewInt(txInfo.CollectionId), std.CollectionIdBitsSize},
+		)
+		w.word(txInfo.NftContentHash)
+		w.word(txInfo.NftL1TokenId)
+		w.word(txInfo.AccountNameHash)
+	case std.TxTypeTransfer:
+		txInfo := oTx.TransferTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeTransfer), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.FromAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.ToAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.AssetId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.AssetAmount), std.PackedAmountBitsSize},
+			pubDataField{big.NewInt(txInfo.GasAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetAmount), std.PackedFeeBitsSize},
+		)
+		w.word(txInfo.CallDataHash)
+	case std.TxTypeSwap:
+		txInfo := oTx.SwapTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeSwap), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.FromAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.PairIndex), std.PairIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.AssetAId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.AssetAAmount), std.PackedAmountBitsSize},
+			pubDataField{big.NewInt(txInfo.AssetBId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.AssetBAmountDelta), std.PackedAmountBitsSize},
+			pubDataField{big.NewInt(txInfo.GasAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetAmount), std.PackedFeeBitsSize},
+			pubDataField{big.NewInt(txInfo.SwapMode), std.SwapModeBitsSize},
+		)
+	case std.TxTypeRouteSwap:
+		txInfo := oTx.RouteSwapTxInfo
+		fields := []pubDataField{
+			{big.NewInt(std.TxTypeRouteSwap), std.TxTypeBitsSize},
+			{big.NewInt(txInfo.FromAccountIndex), std.AccountIndexBitsSize},
+			{big.NewInt(txInfo.PairsCount), std.RoutePairsCountBitsSize},
+		}
+		for i := 0; i < NbRoutePairsPerTx; i++ {
+			fields = append(fields, pubDataField{big.NewInt(txInfo.PairIndexes[i]), std.PairIndexBitsSize})
+		}
+		w.leftAligned(append(fields,
+			pubDataField{big.NewInt(txInfo.AssetAId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.AssetAAmount), std.PackedAmountBitsSize},
+			pubDataField{big.NewInt(txInfo.AssetBId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.AssetBAmountDelta), std.PackedAmountBitsSize},
+		)...)
+		fields = nil
+		for i := 0; i < NbRoutePairsPerTx-1; i++ {
+			fields = append(fields, pubDataField{big.NewInt(txInfo.HopAmounts[i]), std.PackedAmountBitsSize})
+		}
+		w.rightAligned(append(fields,
+			pubDataField{big.NewInt(txInfo.GasAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetAmount), std.PackedFeeBitsSize},
+		)...)
+	case std.TxTypeAddLiquidity:
+		txInfo := oTx.AddLiquidityTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeAddLiquidity), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.FromAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.PairIndex), std.PairIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.AssetAAmount), std.PackedAmountBitsSize},
+			pubDataField{big.NewInt(txInfo.AssetBAmount), std.PackedAmountBitsSize},
+			pubDataField{big.NewInt(txInfo.LpAmount), std.PackedAmountBitsSize},
+			pubDataField{big.NewInt(txInfo.KLast), std.PackedAmountBitsSize},
+		)
+		w.rightAligned(
+			pubDataField{big.NewInt(txInfo.TreasuryAmount), std.PackedAmountBitsSize},
+			pubDataField{big.NewInt(txInfo.GasAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetAmount), std.PackedFeeBitsSize},
+		)
+	case std.TxTypeRemoveLiquidity:
+		txInfo := oTx.RemoveLiquidityTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeRemoveLiquidity), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.FromAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.PairIndex), std.PairIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.AssetAAmountDelta), std.PackedAmountBitsSize},
+			pubDataField{big.NewInt(txInfo.AssetBAmountDelta), std.PackedAmountBitsSize},
+			pubDataField{big.NewInt(txInfo.LpAmount), std.PackedAmountBitsSize},
+			pubDataField{big.NewInt(txInfo.KLast), std.PackedAmountBitsSize},
+		)
+		w.rightAligned(
+			pubDataField{big.NewInt(txInfo.TreasuryAmount), std.PackedAmountBitsSize},
+			pubDataField{big.NewInt(txInfo.GasAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetAmount), std.PackedFeeBitsSize},
+		)
+	case std.TxTypeWithdraw:
+		txInfo := oTx.WithdrawTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeWithdraw), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.FromAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{txInfo.ToAddress, std.AddressBitsSize},
+			pubDataField{big.NewInt(txInfo.AssetId), std.AssetIdBitsSize},
+		)
+		w.rightAligned(
+			pubDataField{txInfo.AssetAmount, std.StateAmountBitsSize},
+			pubDataField{big.NewInt(txInfo.GasAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetAmount), std.PackedFeeBitsSize},
+		)
+	case std.TxTypeCreateCollection:
+		txInfo := oTx.CreateCollectionTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeCreateCollection), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.AccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.CollectionId), std.CollectionIdBitsSize},
+			pubDataField{big.NewInt(txInfo.GasAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetAmount), std.PackedFeeBitsSize},
+		)
+	case std.TxTypeMintNft:
+		txInfo := oTx.MintNftTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeMintNft), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.CreatorAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.ToAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.NftIndex), std.NftIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.GasAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetAmount), std.PackedFeeBitsSize},
+			pubDataField{big.NewInt(txInfo.CreatorTreasuryRate), std.CreatorTreasuryRateBitsSize},
+			pubDataField{big.NewInt(txInfo.CollectionId), std.CollectionIdBitsSize},
+		)
+		w.word(txInfo.NftContentHash)
+	case std.TxTypeTransferNft:
+		txInfo := oTx.TransferNftTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeTransferNft), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.FromAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.ToAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.NftIndex), std.NftIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.GasAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetAmount), std.PackedFeeBitsSize},
+		)
+		w.word(txInfo.CallDataHash)
+	case std.TxTypeAtomicMatch:
+		txInfo := oTx.AtomicMatchTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeAtomicMatch), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.AccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.BuyOffer.AccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.BuyOffer.OfferId), std.OfferIdBitsSize},
+			pubDataField{big.NewInt(txInfo.SellOffer.AccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.SellOffer.OfferId), std.OfferIdBitsSize},
+			pubDataField{big.NewInt(txInfo.BuyOffer.NftIndex), std.NftIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.SellOffer.AssetId), std.AssetIdBitsSize},
+		)
+		w.rightAligned(
+			pubDataField{big.NewInt(txInfo.SellOffer.AssetAmount), std.PackedAmountBitsSize},
+			pubDataField{big.NewInt(txInfo.CreatorAmount), std.PackedAmountBitsSize},
+			pubDataField{big.NewInt(txInfo.TreasuryAmount), std.PackedAmountBitsSize},
+			pubDataField{big.NewInt(txInfo.GasAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetAmount), std.PackedFeeBitsSize},
+		)
+	case std.TxTypeCancelOffer:
+		txInfo := oTx.CancelOfferTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeCancelOffer), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.AccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.OfferId), std.OfferIdBitsSize},
+			pubDataField{big.NewInt(txInfo.GasAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetAmount), std.PackedFeeBitsSize},
+		)
+	case std.TxTypeWithdrawNft:
+		txInfo := oTx.WithdrawNftTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeWithdrawNft), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.AccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.CreatorAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.CreatorTreasuryRate), std.FeeRateBitsSize},
+			pubDataField{big.NewInt(txInfo.NftIndex), std.NftIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.CollectionId), std.CollectionIdBitsSize},
+		)
+		w.word(stringToBigInt(txInfo.NftL1Address))
+		w.rightAligned(
+			pubDataField{stringToBigInt(txInfo.ToAddress), std.AddressBitsSize},
+			pubDataField{big.NewInt(txInfo.GasAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetAmount), std.PackedFeeBitsSize},
+		)
+		w.word(txInfo.NftContentHash)
+		w.word(txInfo.NftL1TokenId)
+		w.word(txInfo.CreatorAccountNameHash)
+	case std.TxTypeFullExit:
+		txInfo := oTx.FullExitTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeFullExit), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.AccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.AssetId), std.AssetIdBitsSize},
+			pubDataField{txInfo.AssetAmount, std.StateAmountBitsSize},
+		)
+		w.word(txInfo.AccountNameHash)
+	case std.TxTypeFullExitNft:
+		txInfo := oTx.FullExitNftTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeFullExitNft), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.AccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.CreatorAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.CreatorTreasuryRate), std.FeeRateBitsSize},
+			pubDataField{big.NewInt(txInfo.NftIndex), std.NftIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.CollectionId), std.CollectionIdBitsSize},
+		)
+		w.word(stringToBigInt(txInfo.NftL1Address))
+		w.word(txInfo.AccountNameHash)
+		w.word(txInfo.CreatorAccountNameHash)
+		w.word(txInfo.NftContentHash)
+		w.word(txInfo.NftL1TokenId)
+	case std.TxTypeChangePubKey:
+		txInfo := oTx.ChangePubKeyTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeChangePubKey), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.AccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.GasAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetAmount), std.PackedFeeBitsSize},
+			pubDataField{big.NewInt(txInfo.SignerThreshold), std.SignerThresholdBitsSize},
+		)
+		w.word(txInfo.PubKey.A.X.ToBigIntRegular(new(big.Int)))
+		w.word(txInfo.PubKey.A.Y.ToBigIntRegular(new(big.Int)))
+		w.word(new(big.Int).SetBytes(txInfo.SignerRoot))
+	case std.TxTypeFullChangePubKey:
+		txInfo := oTx.FullChangePubKeyTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeFullChangePubKey), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.AccountIndex), std.AccountIndexBitsSize},
+		)
+		w.word(txInfo.AccountNameHash)
+		w.word(txInfo.PubKey.A.X.ToBigIntRegular(new(big.Int)))
+		w.word(txInfo.PubKey.A.Y.ToBigIntRegular(new(big.Int)))
+	case std.TxTypeMatchOrder:
+		txInfo := oTx.MatchOrderTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeMatchOrder), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.AccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.BuyOrder.AccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.BuyOrder.OrderId), std.OrderIdBitsSize},
+			pubDataField{big.NewInt(txInfo.SellOrder.AccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.SellOrder.OrderId), std.OrderIdBitsSize},
+			pubDataField{big.NewInt(txInfo.BuyOrder.AssetAId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.BuyOrder.AssetBId), std.AssetIdBitsSize},
+		)
+		w.rightAligned(
+			pubDataField{big.NewInt(txInfo.AssetAFillAmount), std.PackedAmountBitsSize},
+			pubDataField{big.NewInt(txInfo.AssetBFillAmount), std.PackedAmountBitsSize},
+			pubDataField{big.NewInt(txInfo.GasAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetAmount), std.PackedFeeBitsSize},
+		)
+	case std.TxTypeCancelOrder:
+		txInfo := oTx.CancelOrderTxInfo
+		w.leftAligned(
+			pubDataField{big.NewInt(std.TxTypeCancelOrder), std.TxTypeBitsSize},
+			pubDataField{big.NewInt(txInfo.AccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.OrderId), std.OrderIdBitsSize},
+			pubDataField{big.NewInt(txInfo.GasAccountIndex), std.AccountIndexBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetId), std.AssetIdBitsSize},
+			pubDataField{big.NewInt(txInfo.GasFeeAssetAmount), std.PackedFeeBitsSize},
+		)
+	default:
+		log.Println("[CollectPubDataFromTx] invalid tx type")
+		return nil, errors.New("[CollectPubDataFromTx] invalid tx type")
+	}
+	if w.err != nil {
+		log.Println("[CollectPubDataFromTx] unable to encode pubdata:", w.err)
+		return nil, w.err
+	}
+	return bytes.Join(w.chunks, nil), nil
+}
+
+/*
+	PackTxsPubData: native counterpart of PackPubData, chunks of all txs are put next to each other
+*/
+func PackTxsPubData(oTxs []*Tx) (pubData []byte, pubDataChunks int, err error) {
+	for _, oTx := range oTxs {
+		txPubData, err := CollectPubDataFromTx(oTx)
+		if err != nil {
+			return nil, 0, err
+		}
+		pubData = append(pubData, txPubData...)
+	}
+	return pubData, len(pubData) / std.PubDataChunkSize, nil
+}
+
+/*
+	UnpackPubData: split the packed pubdata of a block into the chunks of each tx,
+	the tx type is the first byte of the first chunk of every tx. The empty tx has
+	no chunk, so a chunk of type 0 starts the zero padding hashed by the commitment
+*/
+func UnpackPubData(pubData []byte) (txsPubData [][]byte, err error) {
+	if len(pubData)%std.PubDataChunkSize != 0 {
+		log.Println("[UnpackPubData] invalid pubdata size")
+		return nil, errors.New("[UnpackPubData] invalid pubdata size")
+	}
+	for offset := 0; offset < len(pubData); {
+		if pubData[offset] == std.TxTypeEmptyTx {
+			if !isZeroPadding(pubData[offset:]) {
+				log.Println("[UnpackPubData] invalid padding")
+				return nil, errors.New("[UnpackPubData] invalid padding")
+			}
+			break
+		}
+		chunks, isValid := std.PubDataChunksPerTxType[int(pubData[offset])]
+		if !isValid || chunks == 0 {
+			log.Println("[UnpackPubData] invalid tx type")
+			return nil, errors.New("[UnpackPubData] invalid tx type")
+		}
+		end := offset + chunks*std.PubDataChunkSize
+		if end > len(pubData) {
+			log.Println("[UnpackPubData] invalid pubdata size")
+			return nil, errors.New("[UnpackPubData] invalid pubdata size")
+		}
+		txsPubData = append(txsPubData, pubData[offset:end])
+		offset = end
+	}
+	return txsPubData, nil
+}
+
+func isZeroPadding(padding []byte) bool {
+	for _, b := range padding {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+	OnChainOp: a tx that needs to be processed by the L1 contract, PubDataOffset is
+	the index of its first chunk in the packed pubdata of the block
+*/
+type OnChainOp struct {
+	PubDataOffset int
+	TxType        uint8
+}
+
+/*
+	CollectOnChainOps: the on-chain ops of the txs in order
+*/
+func CollectOnChainOps(oTxs []*Tx) (onChainOps []OnChainOp, err error) {
+	pubDataOffset := 0
+	for _, oTx := range oTxs {
+		txPubData, err := CollectPubDataFromTx(oTx)
+		if err != nil {
+			return nil, err
+		}
+		if IsOnChainOp(oTx.TxType) {
+			onChainOps = append(onChainOps, OnChainOp{PubDataOffset: pubDataOffset, TxType: oTx.TxType})
+		}
+		pubDataOffset += len(txPubData) / std.PubDataChunkSize
+	}
+	return onChainOps, nil
+}
+
+/*
+	EncodeOnChainOps: native counterpart of PackOnChainOps, the words of the on-chain ops
+	of a block of txsCount txs, OnChainOpsPerWord ops per word
+*/
+func EncodeOnChainOps(onChainOps []OnChainOp, txsCount int) (packedOnChainOps []byte, err error) {
+	if len(onChainOps) > txsCount {
+		log.Println("[EncodeOnChainOps] too many on-chain ops")
+		return nil, errors.New("[EncodeOnChainOps] too many on-chain ops")
+	}
+	words := (txsCount + OnChainOpsPerWord - 1) / OnChainOpsPerWord
+	var w pubDataWriter
+	for i := 0; i < words; i++ {
+		var fields []pubDataField
+		for j := 0; j < OnChainOpsPerWord; j++ {
+			offset, txType := int64(0), int64(0)
+			if k := i*OnChainOpsPerWord + j; k < len(onChainOps) {
+				offset, txType = int64(onChainOps[k].PubDataOffset), int64(onChainOps[k].TxType)
+			}
+			fields = append(fields,
+				pubDataField{big.NewInt(offset), OnChainOpOffsetBitsSize},
+				pubDataField{big.NewInt(txType), std.TxTypeBitsSize},
+			)
+		}
+		w.rightAligned(fields...)
+	}
+	if w.err != nil {
+		log.Println("[EncodeOnChainOps] invalid on-chain op:", w.err)
+		return nil, w.err
+	}
+	return bytes.Join(w.chunks, nil), nil
+}
+
+/*
+	ComputeBlockCommitment: native counterpart of the commitment checked by VerifyBlock,
+	the block should contain all txs of the circuit, including the empty ones
+*/
+func ComputeBlockCommitment(oBlock *Block) (commitment []byte, err error) {
+	pubData, pubDataChunks, err := PackTxsPubData(oBlock.Txs)
+	if err != nil {
+		log.Println("[ComputeBlockCommitment] unable to pack pubdata:", err)
+		return nil, err
+	}
+	onChainOps, err := CollectOnChainOps(oBlock.Txs)
+	if err != nil {
+		log.Println("[ComputeBlockCommitment] unable to collect on-chain ops:", err)
+		return nil, err
+	}
+	packedOnChainOps, err := EncodeOnChainOps(onChainOps, len(oBlock.Txs))
+	if err != nil {
+		log.Println("[ComputeBlockCommitment] unable to encode on-chain ops:", err)
+		return nil, err
+	}
+	var w pubDataWriter
+	w.word(oBlock.BlockNumber)
+	w.word(oBlock.CreatedAt)
+	w.word(oBlock.OldStateRoot)
+	w.word(oBlock.NewStateRoot)
+	w.word(int64(pubDataChunks))
+	w.chunks = append(w.chunks, pubData)
+	w.chunks = append(w.chunks, make([]byte, (std.PubDataSizePerTx*len(oBlock.Txs)-pubDataChunks)*std.PubDataChunkSize))
+	w.word(int64(len(onChainOps)))
+	w.chunks = append(w.chunks, packedOnChainOps)
+	if w.err != nil {
+		log.Println("[ComputeBlockCommitment] invalid block info:", w.err)
+		return nil, w.err
+	}
+	return crypto.Keccak256(bytes.Join(w.chunks, nil)), nil
+}
+
+/*
+	ComputePublicInputHash: native counterpart of the public input of
+	CompressedBlockConstraints, keccak256 of
+	OldStateRoot | NewStateRoot | BlockCommitment | BlockNumber | CreatedAt |
+	PriorityOpsHashStart | PriorityOpsHashEnd
+	as 32-byte words, keeping its low PublicInputHashBitsSize bits
+*/
+func ComputePublicInputHash(oBlock *Block) (publicInputHash []byte, err error) {
+	var w pubDataWriter
+	w.word(oBlock.OldStateRoot)
+	w.word(oBlock.NewStateRoot)
+	w.word(oBlock.BlockCommitment)
+	w.word(oBlock.BlockNumber)
+	w.word(oBlock.CreatedAt)
+	w.word(oBlock.PriorityOpsHashStart)
+	w.word(oBlock.PriorityOpsHashEnd)
+	if w.err != nil {
+		log.Println("[ComputePublicInputHash] invalid block info:", w.err)
+		return nil, w.err
+	}
+	return truncatedKeccak256(bytes.Join(w.chunks, nil), PublicInputHashBitsSize), nil
+}
+
+/*
+	ComputePriorityOpsHash: native counterpart of the rolling hash of VerifyBlock, the
+	priority ops of the txs are folded in order into priorityOpsHashStart, see
+	UpdatePriorityOpsHash, the pubdata of a tx is padded with 0 to PubDataSizePerTx chunks
+*/
+func ComputePriorityOpsHash(priorityOpsHashStart []byte, oTxs []*Tx) (priorityOpsHashEnd []byte, err error) {
+	priorityOpsHash, err := wordChunk(priorityOpsHashStart)
+	if err != nil {
+		log.Println("[ComputePriorityOpsHash] invalid priority ops hash:", err)
+		return nil, err
+	}
+	for _, oTx := range oTxs {
+		if !IsPriorityOp(oTx.TxType) {
+			continue
+		}
+		txPubData, err := CollectPubDataFromTx(oTx)
+		if err != nil {
+			log.Println("[ComputePriorityOpsHash] unable to collect pubdata:", err)
+			return nil, err
+		}
+		pendingHashData := make([]byte, 0, (std.PubDataSizePerTx+1)*std.PubDataChunkSize)
+		pendingHashData = append(pendingHashData, priorityOpsHash...)
+		pendingHashData = append(pendingHashData, txPubData...)
+		pendingHashData = append(pendingHashData, make([]byte, std.PubDataSizePerTx*std.PubDataChunkSize-len(txPubData))...)
+		priorityOpsHash = truncatedKeccak256(pendingHashData, PriorityOpsHashBitsSize)
+	}
+	return priorityOpsHash, nil
+}
+
+/*
+	truncatedKeccak256: keccak256 of data keeping the low bitsSize bits, as a 32-byte word
+*/
+func truncatedKeccak256(data []byte, bitsSize int) []byte {
+	hashVal := new(big.Int).SetBytes(crypto.Keccak256(data))
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bitsSize)), big.NewInt(1))
+	return hashVal.And(hashVal, mask).FillBytes(make([]byte, 32))
+}
+
+/*
+	IsPriorityOp: txs requested on L1, see PriorityOpTxTypes
+*/
+func IsPriorityOp(txType uint8) bool {
+	for _, t := range PriorityOpTxTypes {
+		if int(txType) == t {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+	IsOnChainOp: txs that need to be processed by the L1 contract
+*/
+func IsOnChainOp(txType uint8) bool {
+	switch txType {
+	case std.TxTypeRegisterZns, std.TxTypeDeposit, std.TxTypeDepositNft, std.TxTypeCreatePair,
+		std.TxTypeUpdatePairRate, std.TxTypeWithdraw, std.TxTypeWithdrawNft, std.TxTypeFullExit,
+		std.TxTypeFullExitNft, std.TxTypeFullChangePubKey:
+		return true
+	default:
+		return false
+	}
+}
+
+/*
+	pubDataWriter: appends the chunks of a tx, the first error is kept
+*/
+type pubDataWriter struct {
+	chunks [][]byte
+	err    error
+}
+
+/*
+	leftAligned: fields followed by zero padding, the tx type is always the first byte
+*/
+func (w *pubDataWriter) leftAligned(fields ...pubDataField) {
+	value, bitsSize, err := packPubDataFields(fields)
+	if err != nil {
+		w.fail(err)
+		return
+	}
+	w.word(new(big.Int).Lsh(value, uint(std.PubDataChunkSize*8-bitsSize)))
+}
+
+/*
+	rightAligned: zero padding followed by fields
+*/
+func (w *pubDataWriter) rightAligned(fields ...pubDataField) {
+	value, _, err := packPubDataFields(fields)
+	if err != nil {
+		w.fail(err)
+		return
+	}
+	w.word(value)
+}
+
+/*
+	word: a chunk holding a single field element
+*/
+func (w *pubDataWriter) word(v interface{}) {
+	chunk, err := wordChunk(v)
+	if err != nil {
+		w.fail(err)
+		return
+	}
+	w.chunks = append(w.chunks, chunk)
+}
+
+func (w *pubDataWriter) fail(err error) {
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+func packPubDataFields(fields []pubDataField) (value *big.Int, bitsSize int, err error) {
+	value = new(big.Int)
+	for _, field := range fields {
+		if field.Value == nil || field.Value.Sign() < 0 || field.Value.BitLen() > field.BitsSize {
+			return nil, 0, errors.New("[packPubDataFields] invalid field value")
+		}
+		value.Lsh(value, uint(field.BitsSize))
+		value.Or(value, field.Value)
+		bitsSize += field.BitsSize
+	}
+	if bitsSize > std.PubDataChunkSize*8 {
+		return nil, 0, errors.New("[packPubDataFields] chunk overflow")
+	}
+	return value, bitsSize, nil
+}
+
+/*
+	wordChunk: a field element as 32 bytes big-endian, the value is reduced the same way
+	as a circuit witness
+*/
+func wordChunk(v interface{}) (chunk []byte, err error) {
+	var e fr.Element
+	switch value := v.(type) {
+	case []byte:
+		e.SetBigInt(new(big.Int).SetBytes(value))
+	case *big.Int:
+		if value == nil {
+			return nil, errors.New("[wordChunk] invalid value")
+		}
+		e.SetBigInt(value)
+	case int64:
+		e.SetBigInt(big.NewInt(value))
+	default:
+		return nil, errors.New("[wordChunk] invalid value")
+	}
+	res := e.Bytes()
+	return res[:], nil
+}
+
+/*
+	stringToBigInt: same conversion as a circuit witness, nil if the string is invalid
+*/
+func stringToBigInt(s string) *big.Int {
+	res, isValid := new(big.Int).SetString(s, 0)
+	if !isValid {
+		return nil
+	}
+	return res
+}
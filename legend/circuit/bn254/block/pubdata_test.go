@@ -0,0 +1,475 @@
+package block
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	oEddsa "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+const nbPubDataTestTxs = 22
+
+type PubDataConstraints struct {
+	RegisterZnsTxInfo      RegisterZnsTxConstraints
+	CreatePairTxInfo       CreatePairTxConstraints
+	UpdatePairRateTxInfo   UpdatePairRateTxConstraints
+	DepositTxInfo          DepositTxConstraints
+	DepositNftTxInfo       DepositNftTxConstraints
+	TransferTxInfo         TransferTxConstraints
+	SwapTxInfo             SwapTxConstraints
+	AddLiquidityTxInfo     AddLiquidityTxConstraints
+	RemoveLiquidityTxInfo  RemoveLiquidityTxConstraints
+	WithdrawTxInfo         WithdrawTxConstraints
+	CreateCollectionTxInfo CreateCollectionTxConstraints
+	MintNftTxInfo          MintNftTxConstraints
+	TransferNftTxInfo      TransferNftTxConstraints
+	AtomicMatchTxInfo      AtomicMatchTxConstraints
+	CancelOfferTxInfo      CancelOfferTxConstraints
+	WithdrawNftTxInfo      WithdrawNftTxConstraints
+	FullExitTxInfo         FullExitTxConstraints
+	FullExitNftTxInfo      FullExitNftTxConstraints
+	ChangePubKeyTxInfo     ChangePubKeyTxConstraints
+	FullChangePubKeyTxInfo FullChangePubKeyTxConstraints
+	MatchOrderTxInfo       MatchOrderTxConstraints
+	RouteSwapTxInfo        RouteSwapTxConstraints
+	TxsPubDataChunks       [nbPubDataTestTxs]Variable
+	PubData                [nbPubDataTestTxs * std.PubDataSizePerTx]Variable
+	PubDataChunks          Variable
+}
+
+func (circuit PubDataConstraints) Define(api API) error {
+	txsChunks := [nbPubDataTestTxs][]Variable{
+		std.CollectPubDataFromRegisterZNS(api, circuit.RegisterZnsTxInfo),
+		std.CollectPubDataFromCreatePair(api, circuit.CreatePairTxInfo),
+		std.CollectPubDataFromUpdatePairRate(api, circuit.UpdatePairRateTxInfo),
+		std.CollectPubDataFromDeposit(api, circuit.DepositTxInfo),
+		std.CollectPubDataFromDepositNft(api, circuit.DepositNftTxInfo),
+		std.CollectPubDataFromTransfer(api, circuit.TransferTxInfo),
+		std.CollectPubDataFromSwap(api, circuit.SwapTxInfo),
+		std.CollectPubDataFromAddLiquidity(api, circuit.AddLiquidityTxInfo),
+		std.CollectPubDataFromRemoveLiquidity(api, circuit.RemoveLiquidityTxInfo),
+		std.CollectPubDataFromWithdraw(api, circuit.WithdrawTxInfo),
+		std.CollectPubDataFromCreateCollection(api, circuit.CreateCollectionTxInfo),
+		std.CollectPubDataFromMintNft(api, circuit.MintNftTxInfo),
+		std.CollectPubDataFromTransferNft(api, circuit.TransferNftTxInfo),
+		std.CollectPubDataFromAtomicMatch(api, circuit.AtomicMatchTxInfo),
+		std.CollectPubDataFromCancelOffer(api, circuit.CancelOfferTxInfo),
+		std.CollectPubDataFromWithdrawNft(api, circuit.WithdrawNftTxInfo),
+		std.CollectPubDataFromFullExit(api, circuit.FullExitTxInfo),
+		std.CollectPubDataFromFullExitNft(api, circuit.FullExitNftTxInfo),
+		std.CollectPubDataFromChangePubKey(api, circuit.ChangePubKeyTxInfo),
+		std.CollectPubDataFromFullChangePubKey(api, circuit.FullChangePubKeyTxInfo),
+		std.CollectPubDataFromMatchOrder(api, circuit.MatchOrderTxInfo),
+		std.CollectPubDataFromRouteSwap(api, circuit.RouteSwapTxInfo),
+	}
+	txsPubData := make([][std.PubDataSizePerTx]Variable, nbPubDataTestTxs)
+	for i := 0; i < nbPubDataTestTxs; i++ {
+		var zeros [std.PubDataSizePerTx]Variable
+		for j := 0; j < std.PubDataSizePerTx; j++ {
+			zeros[j] = 0
+		}
+		txsPubData[i] = SelectPubData(api, 1, txsChunks[i], zeros)
+	}
+	pubData, pubDataChunks := PackPubData(api, txsPubData, circuit.TxsPubDataChunks[:])
+	api.AssertIsEqual(pubDataChunks, circuit.PubDataChunks)
+	for i := 0; i < len(pubData); i++ {
+		api.AssertIsEqual(pubData[i], circuit.PubData[i])
+	}
+	return nil
+}
+
+func pubDataTestTxs() []*Tx {
+	hashVal := func(s string) []byte {
+		return crypto.Keccak256([]byte(s))
+	}
+	var pk oEddsa.PublicKey
+	pk.A.X.SetUint64(7)
+	pk.A.Y.SetUint64(11)
+	address := "0xAE45C1DB1C0B5B8B2C0E0F2B0E7A6B5F1D2C3B4A"
+	tokenId, _ := new(big.Int).SetString("340282366920938463463374607431768211455", 10)
+	offer := func(offerId, accountIndex int64) *std.OfferTx {
+		return &std.OfferTx{
+			Type:         1,
+			OfferId:      offerId,
+			AccountIndex: accountIndex,
+			NftIndex:     1099511627775,
+			AssetId:      3,
+			AssetAmount:  1099511627775,
+			Sig:          &oEddsa.Signature{},
+		}
+	}
+	order := func(orderType, orderId, accountIndex int64) *std.OrderTx {
+		return &std.OrderTx{
+			Type:         orderType,
+			OrderId:      orderId,
+			AccountIndex: accountIndex,
+			AssetAId:     65535,
+			AssetBId:     1,
+			AssetAAmount: 1099511627775,
+			AssetBAmount: 1099511627775,
+			Sig:          &oEddsa.Signature{},
+		}
+	}
+	return []*Tx{
+		{TxType: std.TxTypeRegisterZns, RegisterZnsTxInfo: &RegisterZnsTx{
+			AccountIndex: 4294967295, AccountName: []byte("zkbas.legend"), AccountNameHash: hashVal("zkbas.legend"), PubKey: &pk,
+		}},
+		{TxType: std.TxTypeCreatePair, CreatePairTxInfo: &CreatePairTx{
+			PairIndex: 65535, AssetAId: 1, AssetBId: 65535, FeeRate: 30, TreasuryAccountIndex: 2, TreasuryRate: 65535,
+			PairType: std.PairTypeStableSwap, Amplification: 65535,
+		}},
+		{TxType: std.TxTypeUpdatePairRate, UpdatePairRateTxInfo: &UpdatePairRateTx{
+			PairIndex: 1, FeeRate: 65535, TreasuryAccountIndex: 4294967295, TreasuryRate: 5,
+		}},
+		{TxType: std.TxTypeDeposit, DepositTxInfo: &DepositTx{
+			AccountIndex: 3, AccountNameHash: hashVal("deposit"), AssetId: 65535, AssetAmount: tokenId,
+		}},
+		{TxType: std.TxTypeDepositNft, DepositNftTxInfo: &DepositNftTx{
+			AccountIndex: 3, NftIndex: 1099511627775, NftL1Address: address, AccountNameHash: hashVal("depositNft"),
+			NftContentHash: hashVal("content"), NftL1TokenId: tokenId, CreatorAccountIndex: 4294967295,
+			CreatorTreasuryRate: 65535, CollectionId: 7,
+		}},
+		{TxType: std.TxTypeTransfer, TransferTxInfo: &TransferTx{
+			FromAccountIndex: 2, ToAccountIndex: 4294967295, AssetId: 1, AssetAmount: 1099511627775,
+			GasAccountIndex: 1, GasFeeAssetId: 65535, GasFeeAssetAmount: 65535, CallDataHash: hashVal("transfer"),
+		}},
+		{TxType: std.TxTypeSwap, SwapTxInfo: &SwapTx{
+			FromAccountIndex: 2, PairIndex: 65535, AssetAId: 1, AssetAAmount: 1099511627775, AssetBId: 2,
+			AssetBAmountDelta: 12345, GasAccountIndex: 4294967295, GasFeeAssetId: 3, GasFeeAssetAmount: 65535,
+			SwapMode: std.SwapModeExactOutput, AssetAMaxAmount: 1099511627775,
+		}},
+		{TxType: std.TxTypeAddLiquidity, AddLiquidityTxInfo: &AddLiquidityTx{
+			FromAccountIndex: 2, PairIndex: 1, AssetAAmount: 1099511627775, AssetBAmount: 3, LpAmount: 4, KLast: 1099511627775,
+			TreasuryAmount: 1099511627775, GasAccountIndex: 4294967295, GasFeeAssetId: 65535, GasFeeAssetAmount: 65535,
+		}},
+		{TxType: std.TxTypeRemoveLiquidity, RemoveLiquidityTxInfo: &RemoveLiquidityTx{
+			FromAccountIndex: 2, PairIndex: 1, AssetAAmountDelta: 1099511627775, AssetBAmountDelta: 3, LpAmount: 4,
+			KLast: 5, TreasuryAmount: 1099511627775, GasAccountIndex: 1, GasFeeAssetId: 65535, GasFeeAssetAmount: 1,
+		}},
+		{TxType: std.TxTypeWithdraw, WithdrawTxInfo: &WithdrawTx{
+			FromAccountIndex: 4294967295, AssetId: 65535, AssetAmount: tokenId, GasAccountIndex: 1, GasFeeAssetId: 2,
+			GasFeeAssetAmount: 65535, ToAddress: stringToBigInt(address),
+		}},
+		{TxType: std.TxTypeCreateCollection, CreateCollectionTxInfo: &CreateCollectionTx{
+			AccountIndex: 2, CollectionId: 65535, GasAccountIndex: 4294967295, GasFeeAssetId: 1, GasFeeAssetAmount: 65535,
+		}},
+		{TxType: std.TxTypeMintNft, MintNftTxInfo: &MintNftTx{
+			CreatorAccountIndex: 2, ToAccountIndex: 3, NftIndex: 1099511627775, NftContentHash: hashVal("mint"),
+			CreatorTreasuryRate: 65535, GasAccountIndex: 1, GasFeeAssetId: 2, GasFeeAssetAmount: 3, CollectionId: 65535,
+		}},
+		{TxType: std.TxTypeTransferNft, TransferNftTxInfo: &TransferNftTx{
+			FromAccountIndex: 2, ToAccountIndex: 3, NftIndex: 1099511627775, GasAccountIndex: 4294967295,
+			GasFeeAssetId: 1, GasFeeAssetAmount: 65535, CallDataHash: hashVal("transferNft"),
+		}},
+		{TxType: std.TxTypeAtomicMatch, AtomicMatchTxInfo: &AtomicMatchTx{
+			AccountIndex: 1, BuyOffer: offer(16777215, 2), SellOffer: offer(5, 4294967295), CreatorAmount: 1099511627775,
+			TreasuryAmount: 7, GasAccountIndex: 1, GasFeeAssetId: 65535, GasFeeAssetAmount: 65535,
+		}},
+		{TxType: std.TxTypeCancelOffer, CancelOfferTxInfo: &CancelOfferTx{
+			AccountIndex: 2, OfferId: 16777215, GasAccountIndex: 1, GasFeeAssetId: 65535, GasFeeAssetAmount: 65535,
+		}},
+		{TxType: std.TxTypeWithdrawNft, WithdrawNftTxInfo: &WithdrawNftTx{
+			AccountIndex: 2, CreatorAccountIndex: 4294967295, CreatorAccountNameHash: hashVal("creator"),
+			CreatorTreasuryRate: 65535, NftIndex: 1099511627775, NftContentHash: hashVal("content"), NftL1Address: address,
+			NftL1TokenId: tokenId, ToAddress: address, GasAccountIndex: 1, GasFeeAssetId: 2, GasFeeAssetAmount: 65535,
+			CollectionId: 65535,
+		}},
+		{TxType: std.TxTypeFullExit, FullExitTxInfo: &FullExitTx{
+			AccountIndex: 4294967295, AccountNameHash: hashVal("fullExit"), AssetId: 65535, AssetAmount: tokenId,
+		}},
+		{TxType: std.TxTypeFullExitNft, FullExitNftTxInfo: &FullExitNftTx{
+			AccountIndex: 2, AccountNameHash: hashVal("fullExitNft"), CreatorAccountIndex: 4294967295,
+			CreatorAccountNameHash: hashVal("creator"), CreatorTreasuryRate: 65535, NftIndex: 1099511627775,
+			CollectionId: 65535, NftContentHash: hashVal("content"), NftL1Address: address, NftL1TokenId: tokenId,
+		}},
+		{TxType: std.TxTypeChangePubKey, ChangePubKeyTxInfo: &ChangePubKeyTx{
+			AccountIndex: 4294967295, PubKey: &pk, GasAccountIndex: 1, GasFeeAssetId: 65535, GasFeeAssetAmount: 65535,
+			SignerRoot: hashVal("signers"), SignerThreshold: 255,
+		}},
+		{TxType: std.TxTypeFullChangePubKey, FullChangePubKeyTxInfo: &FullChangePubKeyTx{
+			AccountIndex: 4294967295, AccountNameHash: hashVal("fullChangePubKey"), PubKey: &pk,
+		}},
+		{TxType: std.TxTypeMatchOrder, MatchOrderTxInfo: &MatchOrderTx{
+			AccountIndex: 1, BuyOrder: order(0, 65535, 2), SellOrder: order(1, 3, 4294967295),
+			AssetAFillAmount: 1099511627775, AssetBFillAmount: 5, GasAccountIndex: 1, GasFeeAssetId: 65535,
+			GasFeeAssetAmount: 65535,
+		}},
+		{TxType: std.TxTypeRouteSwap, RouteSwapTxInfo: &RouteSwapTx{
+			FromAccountIndex: 4294967295, PairsCount: 3, PairIndexes: [NbRoutePairsPerTx]int64{1, 65535, 2},
+			AssetAId: 1, AssetAAmount: 1099511627775, AssetBId: 65535, AssetBAmountDelta: 2,
+			HopAmounts: [NbRoutePairsPerTx - 1]int64{1099511627775, 3}, GasAccountIndex: 4294967295,
+			GasFeeAssetId: 65535, GasFeeAssetAmount: 65535,
+		}},
+	}
+}
+
+func TestPubData(t *testing.T) {
+	oTxs := pubDataTestTxs()
+	var circuit, witness PubDataConstraints
+	offset := 0
+	for i, oTx := range oTxs {
+		txPubData, err := CollectPubDataFromTx(oTx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks := len(txPubData) / std.PubDataChunkSize
+		if chunks != std.PubDataChunksPerTxType[int(oTx.TxType)] || txPubData[0] != oTx.TxType {
+			t.Fatalf("tx type %d: invalid pubdata", oTx.TxType)
+		}
+		witness.TxsPubDataChunks[i] = chunks
+		offset += chunks
+	}
+	pubData, pubDataChunks, err := PackTxsPubData(oTxs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pubDataChunks != offset {
+		t.Fatal("invalid pubdata chunks")
+	}
+	witness.PubDataChunks = pubDataChunks
+	for i := 0; i < len(witness.PubData); i++ {
+		witness.PubData[i] = 0
+		if i < pubDataChunks {
+			witness.PubData[i] = pubData[i*std.PubDataChunkSize : (i+1)*std.PubDataChunkSize]
+		}
+	}
+	witness.RegisterZnsTxInfo = std.SetRegisterZnsTxWitness(oTxs[0].RegisterZnsTxInfo)
+	witness.CreatePairTxInfo = std.SetCreatePairTxWitness(oTxs[1].CreatePairTxInfo)
+	witness.UpdatePairRateTxInfo = std.SetUpdatePairRateTxWitness(oTxs[2].UpdatePairRateTxInfo)
+	witness.DepositTxInfo = std.SetDepositTxWitness(oTxs[3].DepositTxInfo)
+	witness.DepositNftTxInfo = std.SetDepositNftTxWitness(oTxs[4].DepositNftTxInfo)
+	witness.TransferTxInfo = std.SetTransferTxWitness(oTxs[5].TransferTxInfo)
+	witness.SwapTxInfo = std.SetSwapTxWitness(oTxs[6].SwapTxInfo)
+	witness.AddLiquidityTxInfo = std.SetAddLiquidityTxWitness(oTxs[7].AddLiquidityTxInfo)
+	witness.RemoveLiquidityTxInfo = std.SetRemoveLiquidityTxWitness(oTxs[8].RemoveLiquidityTxInfo)
+	witness.WithdrawTxInfo = std.SetWithdrawTxWitness(oTxs[9].WithdrawTxInfo)
+	witness.CreateCollectionTxInfo = std.SetCreateCollectionTxWitness(oTxs[10].CreateCollectionTxInfo)
+	witness.MintNftTxInfo = std.SetMintNftTxWitness(oTxs[11].MintNftTxInfo)
+	witness.TransferNftTxInfo = std.SetTransferNftTxWitness(oTxs[12].TransferNftTxInfo)
+	witness.AtomicMatchTxInfo = std.SetAtomicMatchTxWitness(oTxs[13].AtomicMatchTxInfo)
+	witness.CancelOfferTxInfo = std.SetCancelOfferTxWitness(oTxs[14].CancelOfferTxInfo)
+	witness.WithdrawNftTxInfo = std.SetWithdrawNftTxWitness(oTxs[15].WithdrawNftTxInfo)
+	witness.FullExitTxInfo = std.SetFullExitTxWitness(oTxs[16].FullExitTxInfo)
+	witness.FullExitNftTxInfo = std.SetFullExitNftTxWitness(oTxs[17].FullExitNftTxInfo)
+	witness.ChangePubKeyTxInfo = std.SetChangePubKeyTxWitness(oTxs[18].ChangePubKeyTxInfo)
+	witness.FullChangePubKeyTxInfo = std.SetFullChangePubKeyTxWitness(oTxs[19].FullChangePubKeyTxInfo)
+	witness.MatchOrderTxInfo = std.SetMatchOrderTxWitness(oTxs[20].MatchOrderTxInfo)
+	witness.RouteSwapTxInfo = std.SetRouteSwapTxWitness(oTxs[21].RouteSwapTxInfo)
+	err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// chunks can't be moved
+	witness.PubData[0], witness.PubData[1] = witness.PubData[1], witness.PubData[0]
+	err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16)
+	if err == nil {
+		t.Fatal("invalid pubdata accepted")
+	}
+}
+
+func TestUnpackPubData(t *testing.T) {
+	oTxs := pubDataTestTxs()
+	pubData, _, err := PackTxsPubData(append(oTxs, &Tx{TxType: std.TxTypeEmptyTx}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	txsPubData, err := UnpackPubData(pubData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txsPubData) != len(oTxs) {
+		t.Fatal("invalid txs count")
+	}
+	for i, oTx := range oTxs {
+		txPubData, _ := CollectPubDataFromTx(oTx)
+		if !bytes.Equal(txsPubData[i], txPubData) {
+			t.Fatalf("tx type %d: invalid pubdata", oTx.TxType)
+		}
+	}
+	_, err = UnpackPubData(pubData[:len(pubData)-std.PubDataChunkSize])
+	if err == nil {
+		t.Fatal("truncated pubdata accepted")
+	}
+	// the padded pubdata of the commitment gives the same txs
+	padded := append(append([]byte(nil), pubData...), make([]byte, 2*std.PubDataChunkSize)...)
+	paddedTxsPubData, err := UnpackPubData(padded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paddedTxsPubData) != len(oTxs) {
+		t.Fatal("invalid txs count of the padded pubdata")
+	}
+	padded[len(padded)-1] = 1
+	if _, err = UnpackPubData(padded); err == nil {
+		t.Fatal("pubdata after the padding accepted")
+	}
+}
+
+const nbOnChainOpsTestWords = (nbPubDataTestTxs + OnChainOpsPerWord - 1) / OnChainOpsPerWord
+
+type OnChainOpsConstraints struct {
+	TxsPubDataOffset [nbPubDataTestTxs]Variable
+	TxsType          [nbPubDataTestTxs]Variable
+	IsOnChainOps     [nbPubDataTestTxs]Variable
+	OnChainOps       [nbOnChainOpsTestWords]Variable
+	OnChainOpsCount  Variable
+}
+
+func (circuit OnChainOpsConstraints) Define(api API) error {
+	onChainOps, onChainOpsCount := PackOnChainOps(
+		api, circuit.TxsPubDataOffset[:], circuit.TxsType[:], circuit.IsOnChainOps[:])
+	api.AssertIsEqual(onChainOpsCount, circuit.OnChainOpsCount)
+	for i := 0; i < len(onChainOps); i++ {
+		api.AssertIsEqual(onChainOps[i], circuit.OnChainOps[i])
+	}
+	return nil
+}
+
+func TestOnChainOps(t *testing.T) {
+	oTxs := pubDataTestTxs()
+	onChainOps, err := CollectOnChainOps(append(oTxs, &Tx{TxType: std.TxTypeEmptyTx}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubData, _, err := PackTxsPubData(oTxs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var circuit, witness OnChainOpsConstraints
+	k := 0
+	offset := 0
+	for i, oTx := range oTxs {
+		witness.TxsPubDataOffset[i] = offset
+		witness.TxsType[i] = oTx.TxType
+		witness.IsOnChainOps[i] = 0
+		if IsOnChainOp(oTx.TxType) {
+			witness.IsOnChainOps[i] = 1
+			if k >= len(onChainOps) || onChainOps[k].PubDataOffset != offset || onChainOps[k].TxType != oTx.TxType {
+				t.Fatalf("tx type %d: invalid on-chain op", oTx.TxType)
+			}
+			// the op points to the first chunk of the tx
+			if pubData[onChainOps[k].PubDataOffset*std.PubDataChunkSize] != oTx.TxType {
+				t.Fatalf("tx type %d: invalid pubdata offset", oTx.TxType)
+			}
+			k++
+		}
+		offset += std.PubDataChunksPerTxType[int(oTx.TxType)]
+	}
+	if k != len(onChainOps) {
+		t.Fatal("invalid on-chain ops count")
+	}
+	packedOnChainOps, err := EncodeOnChainOps(onChainOps, nbPubDataTestTxs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packedOnChainOps) != nbOnChainOpsTestWords*std.PubDataChunkSize {
+		t.Fatal("invalid on-chain ops size")
+	}
+	witness.OnChainOpsCount = len(onChainOps)
+	for i := 0; i < nbOnChainOpsTestWords; i++ {
+		witness.OnChainOps[i] = packedOnChainOps[i*std.PubDataChunkSize : (i+1)*std.PubDataChunkSize]
+	}
+	err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a tx can't be left out of the on-chain ops
+	witness.IsOnChainOps[0] = 0
+	witness.OnChainOpsCount = len(onChainOps) - 1
+	err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16)
+	if err == nil {
+		t.Fatal("invalid on-chain ops accepted")
+	}
+	_, err = EncodeOnChainOps(onChainOps, len(onChainOps)-1)
+	if err == nil {
+		t.Fatal("on-chain ops of too many txs accepted")
+	}
+}
+
+type PriorityOpsHashConstraints struct {
+	TxsType              [nbPubDataTestTxs]Variable
+	TxsPubData           [nbPubDataTestTxs][std.PubDataSizePerTx]Variable
+	PriorityOpsHashStart Variable
+	PriorityOpsHashEnd   Variable
+}
+
+func (circuit PriorityOpsHashConstraints) Define(api API) error {
+	priorityOpsHash := circuit.PriorityOpsHashStart
+	for i := 0; i < nbPubDataTestTxs; i++ {
+		tx := TxConstraints{TxType: circuit.TxsType[i], SlotType: TxSlotTypeAll}
+		priorityOpsHash = UpdatePriorityOpsHash(api, tx, circuit.TxsPubData[i], priorityOpsHash)
+	}
+	api.AssertIsEqual(priorityOpsHash, circuit.PriorityOpsHashEnd)
+	return nil
+}
+
+func TestPriorityOpsHash(t *testing.T) {
+	oTxs := pubDataTestTxs()
+	priorityOpsHashStart := new(big.Int).Lsh(big.NewInt(1), PriorityOpsHashBitsSize-1).FillBytes(make([]byte, 32))
+	priorityOpsHashEnd, err := ComputePriorityOpsHash(priorityOpsHashStart, oTxs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if new(big.Int).SetBytes(priorityOpsHashEnd).BitLen() > PriorityOpsHashBitsSize {
+		t.Fatal("priority ops hash isn't truncated")
+	}
+	// txs requested on L2 don't change the hash
+	var priorityOps []*Tx
+	for _, oTx := range oTxs {
+		if IsPriorityOp(oTx.TxType) {
+			priorityOps = append(priorityOps, oTx)
+		}
+	}
+	if len(priorityOps) != len(PriorityOpTxTypes) {
+		t.Fatal("invalid priority ops")
+	}
+	priorityOpsHash, err := ComputePriorityOpsHash(priorityOpsHashStart, priorityOps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(priorityOpsHash, priorityOpsHashEnd) {
+		t.Fatal("priority ops hash depends on txs requested on L2")
+	}
+	var circuit, witness PriorityOpsHashConstraints
+	for i, oTx := range oTxs {
+		txPubData, err := CollectPubDataFromTx(oTx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		witness.TxsType[i] = oTx.TxType
+		for j := 0; j < std.PubDataSizePerTx; j++ {
+			witness.TxsPubData[i][j] = 0
+			if j < len(txPubData)/std.PubDataChunkSize {
+				witness.TxsPubData[i][j] = txPubData[j*std.PubDataChunkSize : (j+1)*std.PubDataChunkSize]
+			}
+		}
+	}
+	witness.PriorityOpsHashStart = priorityOpsHashStart
+	witness.PriorityOpsHashEnd = priorityOpsHashEnd
+	err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// priority ops can't be reordered
+	witness.TxsType[0], witness.TxsType[1] = witness.TxsType[1], witness.TxsType[0]
+	witness.TxsPubData[0], witness.TxsPubData[1] = witness.TxsPubData[1], witness.TxsPubData[0]
+	err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16)
+	if err == nil {
+		t.Fatal("reordered priority ops accepted")
+	}
+	// nor skipped
+	witness.TxsType[0], witness.TxsType[1] = witness.TxsType[1], witness.TxsType[0]
+	witness.TxsPubData[0], witness.TxsPubData[1] = witness.TxsPubData[1], witness.TxsPubData[0]
+	witness.TxsType[0] = std.TxTypeEmptyTx
+	err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16)
+	if err == nil {
+		t.Fatal("skipped priority op accepted")
+	}
+}
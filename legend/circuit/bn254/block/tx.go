@@ -41,12 +41,19 @@ type Tx struct {
 	WithdrawNftTxInfo      *WithdrawNftTx
 	FullExitTxInfo         *FullExitTx
 	FullExitNftTxInfo      *FullExitNftTx
+	ChangePubKeyTxInfo     *ChangePubKeyTx
+	FullChangePubKeyTxInfo *FullChangePubKeyTx
+	MatchOrderTxInfo       *MatchOrderTx
+	RouteSwapTxInfo        *RouteSwapTx
+	CancelOrderTxInfo      *CancelOrderTx
 	// nonce
 	Nonce int64
 	// expired at
 	ExpiredAt int64
 	// signature
 	Signature *Signature
+	// signatures of the signer set, only set for a tx of a multi-signature account
+	MultiSigs [NbMultiSigsPerTx]*std.MultiSig
 	// account root before
 	AccountRootBefore []byte
 	// account before info, size is 5
@@ -55,6 +62,9 @@ type Tx struct {
 	LiquidityRootBefore []byte
 	// liquidity before
 	LiquidityBefore *std.Liquidity
+	// liquidities before of the pairs of a route swap after the first one,
+	// only set for route swaps
+	RouteLiquiditiesBefore [NbRoutePairsPerTx - 1]*std.Liquidity
 	// nft root before
 	NftRootBefore []byte
 	// nft before
@@ -67,6 +77,9 @@ type Tx struct {
 	MerkleProofsAccountBefore [NbAccountsPerTx][AccountMerkleLevels][]byte
 	// before liquidity merkle proof
 	MerkleProofsLiquidityBefore [LiquidityMerkleLevels][]byte
+	// before route liquidities merkle proofs, each one against the liquidity root
+	// after the pairs before it
+	MerkleProofsRouteLiquiditiesBefore [NbRoutePairsPerTx - 1][LiquidityMerkleLevels][]byte
 	// before nft tree merkle proof
 	MerkleProofsNftBefore [NftMerkleLevels][]byte
 	// state root after
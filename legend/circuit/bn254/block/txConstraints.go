@@ -46,12 +46,19 @@ type TxConstraints struct {
 	WithdrawNftTxInfo      WithdrawNftTxConstraints
 	FullExitTxInfo         FullExitTxConstraints
 	FullExitNftTxInfo      FullExitNftTxConstraints
+	ChangePubKeyTxInfo     ChangePubKeyTxConstraints
+	FullChangePubKeyTxInfo FullChangePubKeyTxConstraints
+	MatchOrderTxInfo       MatchOrderTxConstraints
+	RouteSwapTxInfo        RouteSwapTxConstraints
+	CancelOrderTxInfo      CancelOrderTxConstraints
 	// nonce
 	Nonce Variable
 	// expired at
 	ExpiredAt Variable
 	// signature
 	Signature SignatureConstraints
+	// signatures of the signer set, in place of the signature for a multi-signature account
+	MultiSigs [NbMultiSigsPerTx]std.MultiSigConstraints
 	// account root before
 	AccountRootBefore Variable
 	// account before info, size is 5
@@ -60,6 +67,8 @@ type TxConstraints struct {
 	LiquidityRootBefore Variable
 	// liquidity before
 	LiquidityBefore std.LiquidityConstraints
+	// liquidities before of the pairs of a route swap after the first one
+	RouteLiquiditiesBefore [NbRoutePairsPerTx - 1]std.LiquidityConstraints
 	// nft root before
 	NftRootBefore Variable
 	// nft before
@@ -70,14 +79,21 @@ type TxConstraints struct {
 	MerkleProofsAccountAssetsBefore [NbAccountsPerTx][NbAccountAssetsPerAccount][AssetMerkleLevels]Variable
 	// before liquidity merkle proof
 	MerkleProofsLiquidityBefore [LiquidityMerkleLevels]Variable
+	// before route liquidities merkle proofs
+	MerkleProofsRouteLiquiditiesBefore [NbRoutePairsPerTx - 1][LiquidityMerkleLevels]Variable
 	// before nft tree merkle proof
 	MerkleProofsNftBefore [NftMerkleLevels]Variable
 	// before account merkle proof
 	MerkleProofsAccountBefore [NbAccountsPerTx][AccountMerkleLevels]Variable
 	// state root after
 	StateRootAfter Variable
+	// slot type, decides which tx types are compiled, not part of the witness
+	SlotType int
 }
 
+// block CreatedAt of a tx verified out of a block
+const TxConstraintsBlockCreatedAt = 1633400952228
+
 func (circuit TxConstraints) Define(api API) error {
 	// mimc
 	hFunc, err := mimc.NewMiMC(api)
@@ -85,42 +101,67 @@ func (circuit TxConstraints) Define(api API) error {
 		return err
 	}
 
-	_, _, err = VerifyTransaction(api, circuit, hFunc, 1633400952228)
+	_, _, _, err = VerifyTransaction(api, circuit, hFunc, TxConstraintsBlockCreatedAt)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+/*
+	VerifyTransaction: verify the tx of a slot, only the tx types accepted by
+	tx.SlotType are compiled, the accounts, the liquidity tree and the nft tree
+	are only checked if one of these tx types reads or updates them
+*/
 func VerifyTransaction(
 	api API,
 	tx TxConstraints,
 	hFunc MiMC,
 	blockCreatedAt Variable,
-) (isOnChainOp Variable, pubData [std.PubDataSizePerTx]Variable, err error) {
-	// compute tx type
-	isEmptyTx := api.IsZero(api.Sub(tx.TxType, std.TxTypeEmptyTx))
-	isRegisterZnsTx := api.IsZero(api.Sub(tx.TxType, std.TxTypeRegisterZns))
-	isCreatePairTx := api.IsZero(api.Sub(tx.TxType, std.TxTypeCreatePair))
-	isUpdatePairRateTx := api.IsZero(api.Sub(tx.TxType, std.TxTypeUpdatePairRate))
-	isDepositTx := api.IsZero(api.Sub(tx.TxType, std.TxTypeDeposit))
-	isDepositNftTx := api.IsZero(api.Sub(tx.TxType, std.TxTypeDepositNft))
-	isTransferTx := api.IsZero(api.Sub(tx.TxType, std.TxTypeTransfer))
-	isSwapTx := api.IsZero(api.Sub(tx.TxType, std.TxTypeSwap))
-	isAddLiquidityTx := api.IsZero(api.Sub(tx.TxType, std.TxTypeAddLiquidity))
-	isRemoveLiquidityTx := api.IsZero(api.Sub(tx.TxType, std.TxTypeRemoveLiquidity))
-	isWithdrawTx := api.IsZero(api.Sub(tx.TxType, std.TxTypeWithdraw))
-	isCreateCollectionTx := api.IsZero(api.Sub(tx.TxType, std.TxTypeCreateCollection))
-	isMintNftTx := api.IsZero(api.Sub(tx.TxType, std.TxTypeMintNft))
-	isTransferNftTx := api.IsZero(api.Sub(tx.TxType, std.TxTypeTransferNft))
-	isAtomicMatchTx := api.IsZero(api.Sub(tx.TxType, std.TxTypeAtomicMatch))
-	isCancelOfferTx := api.IsZero(api.Sub(tx.TxType, std.TxTypeCancelOffer))
-	isWithdrawNftTx := api.IsZero(api.Sub(tx.TxType, std.TxTypeWithdrawNft))
-	isFullExitTx := api.IsZero(api.Sub(tx.TxType, std.TxTypeFullExit))
-	isFullExitNftTx := api.IsZero(api.Sub(tx.TxType, std.TxTypeFullExitNft))
+) (isOnChainOp Variable, pubData [std.PubDataSizePerTx]Variable, pubDataChunks Variable, err error) {
+	defer std.ProfileScope(api, "VerifyTransaction")()
+	inSlot := func(txType int) bool {
+		return IsTxTypeInSlot(tx.SlotType, txType)
+	}
+	// compute tx type, the flags of the tx types out of the slot are constant 0
+	var txTypeFlags []Variable
+	txTypeFlag := func(txType int) Variable {
+		if !inSlot(txType) {
+			return 0
+		}
+		flag := api.IsZero(api.Sub(tx.TxType, txType))
+		txTypeFlags = append(txTypeFlags, flag)
+		return flag
+	}
+	isEmptyTx := txTypeFlag(std.TxTypeEmptyTx)
+	isRegisterZnsTx := txTypeFlag(std.TxTypeRegisterZns)
+	isCreatePairTx := txTypeFlag(std.TxTypeCreatePair)
+	isUpdatePairRateTx := txTypeFlag(std.TxTypeUpdatePairRate)
+	isDepositTx := txTypeFlag(std.TxTypeDeposit)
+	isDepositNftTx := txTypeFlag(std.TxTypeDepositNft)
+	isTransferTx := txTypeFlag(std.TxTypeTransfer)
+	isSwapTx := txTypeFlag(std.TxTypeSwap)
+	isAddLiquidityTx := txTypeFlag(std.TxTypeAddLiquidity)
+	isRemoveLiquidityTx := txTypeFlag(std.TxTypeRemoveLiquidity)
+	isWithdrawTx := txTypeFlag(std.TxTypeWithdraw)
+	isCreateCollectionTx := txTypeFlag(std.TxTypeCreateCollection)
+	isMintNftTx := txTypeFlag(std.TxTypeMintNft)
+	isTransferNftTx := txTypeFlag(std.TxTypeTransferNft)
+	isAtomicMatchTx := txTypeFlag(std.TxTypeAtomicMatch)
+	isCancelOfferTx := txTypeFlag(std.TxTypeCancelOffer)
+	isWithdrawNftTx := txTypeFlag(std.TxTypeWithdrawNft)
+	isFullExitTx := txTypeFlag(std.TxTypeFullExit)
+	isFullExitNftTx := txTypeFlag(std.TxTypeFullExitNft)
+	isChangePubKeyTx := txTypeFlag(std.TxTypeChangePubKey)
+	isFullChangePubKeyTx := txTypeFlag(std.TxTypeFullChangePubKey)
+	isMatchOrderTx := txTypeFlag(std.TxTypeMatchOrder)
+	isRouteSwapTx := txTypeFlag(std.TxTypeRouteSwap)
+	isCancelOrderTx := txTypeFlag(std.TxTypeCancelOrder)
+	// the tx type must be accepted by the slot
+	api.AssertIsEqual(sumVariables(api, txTypeFlags), 1)
 
 	// verify nonce
-	isLayer2Tx := api.Add(
+	isLayer2Tx := sumVariables(api, []Variable{
 		isTransferTx,
 		isSwapTx,
 		isAddLiquidityTx,
@@ -132,9 +173,13 @@ func VerifyTransaction(
 		isAtomicMatchTx,
 		isCancelOfferTx,
 		isWithdrawNftTx,
-	)
+		isChangePubKeyTx,
+		isMatchOrderTx,
+		isRouteSwapTx,
+		isCancelOrderTx,
+	})
 
-	isOnChainOp = api.Add(
+	isOnChainOp = sumVariables(api, []Variable{
 		isRegisterZnsTx,
 		isDepositTx,
 		isDepositNftTx,
@@ -144,120 +189,289 @@ func VerifyTransaction(
 		isWithdrawNftTx,
 		isFullExitTx,
 		isFullExitNftTx,
-	)
+		isFullChangePubKeyTx,
+	})
+
+	pubDataChunks = sumVariables(api, []Variable{
+		api.Mul(isRegisterZnsTx, std.RegisterZnsPubDataChunks),
+		api.Mul(isCreatePairTx, std.CreatePairPubDataChunks),
+		api.Mul(isUpdatePairRateTx, std.UpdatePairRatePubDataChunks),
+		api.Mul(isDepositTx, std.DepositPubDataChunks),
+		api.Mul(isDepositNftTx, std.DepositNftPubDataChunks),
+		api.Mul(isTransferTx, std.TransferPubDataChunks),
+		api.Mul(isSwapTx, std.SwapPubDataChunks),
+		api.Mul(isAddLiquidityTx, std.AddLiquidityPubDataChunks),
+		api.Mul(isRemoveLiquidityTx, std.RemoveLiquidityPubDataChunks),
+		api.Mul(isWithdrawTx, std.WithdrawPubDataChunks),
+		api.Mul(isCreateCollectionTx, std.CreateCollectionPubDataChunks),
+		api.Mul(isMintNftTx, std.MintNftPubDataChunks),
+		api.Mul(isTransferNftTx, std.TransferNftPubDataChunks),
+		api.Mul(isAtomicMatchTx, std.AtomicMatchPubDataChunks),
+		api.Mul(isCancelOfferTx, std.CancelOfferPubDataChunks),
+		api.Mul(isWithdrawNftTx, std.WithdrawNftPubDataChunks),
+		api.Mul(isFullExitTx, std.FullExitPubDataChunks),
+		api.Mul(isFullExitNftTx, std.FullExitNftPubDataChunks),
+		api.Mul(isChangePubKeyTx, std.ChangePubKeyPubDataChunks),
+		api.Mul(isFullChangePubKeyTx, std.FullChangePubKeyPubDataChunks),
+		api.Mul(isMatchOrderTx, std.MatchOrderPubDataChunks),
+		api.Mul(isRouteSwapTx, std.RouteSwapPubDataChunks),
+		api.Mul(isCancelOrderTx, std.CancelOrderPubDataChunks),
+	})
 
 	// get hash value from tx based on tx type
+	endTxHash := std.ProfileScope(api, "VerifyTransaction/txHash")
+	var hashVal Variable = 0
 	// transfer tx
-	hashVal := std.ComputeHashFromTransferTx(tx.TransferTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+	if inSlot(std.TxTypeTransfer) {
+		hashValCheck := std.ComputeHashFromTransferTx(tx.TransferTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+		hashVal = api.Select(isTransferTx, hashValCheck, hashVal)
+	}
 	// swap tx
-	hashValCheck := std.ComputeHashFromSwapTx(tx.SwapTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
-	hashVal = api.Select(isSwapTx, hashValCheck, hashVal)
+	if inSlot(std.TxTypeSwap) {
+		hashValCheck := std.ComputeHashFromSwapTx(api, tx.SwapTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+		hashVal = api.Select(isSwapTx, hashValCheck, hashVal)
+	}
 	// add liquidity tx
-	hashValCheck = std.ComputeHashFromAddLiquidityTx(tx.AddLiquidityTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
-	hashVal = api.Select(isAddLiquidityTx, hashValCheck, hashVal)
+	if inSlot(std.TxTypeAddLiquidity) {
+		hashValCheck := std.ComputeHashFromAddLiquidityTx(tx.AddLiquidityTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+		hashVal = api.Select(isAddLiquidityTx, hashValCheck, hashVal)
+	}
 	// remove liquidity tx
-	hashValCheck = std.ComputeHashFromRemoveLiquidityTx(tx.RemoveLiquidityTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
-	hashVal = api.Select(isRemoveLiquidityTx, hashValCheck, hashVal)
+	if inSlot(std.TxTypeRemoveLiquidity) {
+		hashValCheck := std.ComputeHashFromRemoveLiquidityTx(tx.RemoveLiquidityTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+		hashVal = api.Select(isRemoveLiquidityTx, hashValCheck, hashVal)
+	}
 	// withdraw tx
-	hashValCheck = std.ComputeHashFromWithdrawTx(tx.WithdrawTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
-	hashVal = api.Select(isWithdrawTx, hashValCheck, hashVal)
+	if inSlot(std.TxTypeWithdraw) {
+		hashValCheck := std.ComputeHashFromWithdrawTx(tx.WithdrawTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+		hashVal = api.Select(isWithdrawTx, hashValCheck, hashVal)
+	}
 	// createCollection tx
-	hashValCheck = std.ComputeHashFromCreateCollectionTx(tx.CreateCollectionTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
-	hashVal = api.Select(isCreateCollectionTx, hashValCheck, hashVal)
+	if inSlot(std.TxTypeCreateCollection) {
+		hashValCheck := std.ComputeHashFromCreateCollectionTx(tx.CreateCollectionTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+		hashVal = api.Select(isCreateCollectionTx, hashValCheck, hashVal)
+	}
 	// mint nft tx
-	hashValCheck = std.ComputeHashFromMintNftTx(api, tx.MintNftTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
-	hashVal = api.Select(isMintNftTx, hashValCheck, hashVal)
+	if inSlot(std.TxTypeMintNft) {
+		hashValCheck := std.ComputeHashFromMintNftTx(api, tx.MintNftTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+		hashVal = api.Select(isMintNftTx, hashValCheck, hashVal)
+	}
 	// transfer nft tx
-	hashValCheck = std.ComputeHashFromTransferNftTx(tx.TransferNftTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
-	hashVal = api.Select(isTransferNftTx, hashValCheck, hashVal)
-	// set nft price tx
-	hashValCheck = std.ComputeHashFromAtomicMatchTx(tx.AtomicMatchTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
-	hashVal = api.Select(isAtomicMatchTx, hashValCheck, hashVal)
-	// buy nft tx
-	hashValCheck = std.ComputeHashFromCancelOfferTx(tx.CancelOfferTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
-	hashVal = api.Select(isCancelOfferTx, hashValCheck, hashVal)
+	if inSlot(std.TxTypeTransferNft) {
+		hashValCheck := std.ComputeHashFromTransferNftTx(tx.TransferNftTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+		hashVal = api.Select(isTransferNftTx, hashValCheck, hashVal)
+	}
+	// atomic match tx
+	if inSlot(std.TxTypeAtomicMatch) {
+		hashValCheck := std.ComputeHashFromAtomicMatchTx(tx.AtomicMatchTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+		hashVal = api.Select(isAtomicMatchTx, hashValCheck, hashVal)
+	}
+	// cancel offer tx
+	if inSlot(std.TxTypeCancelOffer) {
+		hashValCheck := std.ComputeHashFromCancelOfferTx(tx.CancelOfferTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+		hashVal = api.Select(isCancelOfferTx, hashValCheck, hashVal)
+	}
 	// withdraw nft tx
-	hashValCheck = std.ComputeHashFromWithdrawNftTx(tx.WithdrawNftTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
-	hashVal = api.Select(isWithdrawNftTx, hashValCheck, hashVal)
+	if inSlot(std.TxTypeWithdrawNft) {
+		hashValCheck := std.ComputeHashFromWithdrawNftTx(tx.WithdrawNftTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+		hashVal = api.Select(isWithdrawNftTx, hashValCheck, hashVal)
+	}
+	// change pub key tx
+	if inSlot(std.TxTypeChangePubKey) {
+		hashValCheck := std.ComputeHashFromChangePubKeyTx(api, tx.ChangePubKeyTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+		hashVal = api.Select(isChangePubKeyTx, hashValCheck, hashVal)
+	}
+	// match order tx
+	if inSlot(std.TxTypeMatchOrder) {
+		hashValCheck := std.ComputeHashFromMatchOrderTx(tx.MatchOrderTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+		hashVal = api.Select(isMatchOrderTx, hashValCheck, hashVal)
+	}
+	if inSlot(std.TxTypeRouteSwap) {
+		hashValCheck := std.ComputeHashFromRouteSwapTx(tx.RouteSwapTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+		hashVal = api.Select(isRouteSwapTx, hashValCheck, hashVal)
+	}
+	// cancel order tx
+	if inSlot(std.TxTypeCancelOrder) {
+		hashValCheck := std.ComputeHashFromCancelOrderTx(tx.CancelOrderTxInfo, tx.Nonce, tx.ExpiredAt, hFunc)
+		hashVal = api.Select(isCancelOrderTx, hashValCheck, hashVal)
+	}
 	hFunc.Reset()
+	endTxHash()
 
-	std.IsVariableEqual(api, isLayer2Tx, tx.AccountsInfoBefore[0].Nonce, tx.Nonce)
-	// verify signature
-	err = std.VerifyEddsaSig(
-		isLayer2Tx,
-		api,
-		hFunc,
-		hashVal,
-		tx.AccountsInfoBefore[0].AccountPk,
-		tx.Signature,
-	)
-	if err != nil {
-		log.Println("[VerifyTx] invalid signature:", err)
-		return nil, pubData, err
+	// slots without layer2 txs don't need the signature
+	hasLayer2Tx := IsAnyTxTypeInSlot(tx.SlotType, Layer2TxTypes)
+	if hasLayer2Tx {
+		std.IsVariableEqual(api, isLayer2Tx, tx.AccountsInfoBefore[0].Nonce, tx.Nonce)
+		// a multi-signature account is signed by its signer set
+		isSingleSig := api.IsZero(tx.AccountsInfoBefore[0].SignerThreshold)
+		// verify signature
+		err = std.VerifyEddsaSig(
+			api.And(isLayer2Tx, isSingleSig),
+			api,
+			hFunc,
+			hashVal,
+			tx.AccountsInfoBefore[0].AccountPk,
+			tx.Signature,
+		)
+		if err != nil {
+			log.Println("[VerifyTx] invalid signature:", err)
+			return nil, pubData, nil, err
+		}
+		hFunc.Reset()
+		err = std.VerifyMultiSig(
+			api,
+			api.And(isLayer2Tx, api.Sub(1, isSingleSig)),
+			hFunc,
+			hashVal,
+			tx.AccountsInfoBefore[0],
+			tx.MultiSigs,
+		)
+		if err != nil {
+			log.Println("[VerifyTx] invalid multi signature:", err)
+			return nil, pubData, nil, err
+		}
 	}
 
 	// verify transactions
+	var pubDataCheck []Variable
 	for i := 0; i < std.PubDataSizePerTx; i++ {
 		pubData[i] = 0
 	}
-	pubDataCheck := std.VerifyRegisterZNSTx(api, isRegisterZnsTx, tx.RegisterZnsTxInfo, tx.AccountsInfoBefore)
-	pubData = SelectPubData(api, isRegisterZnsTx, pubDataCheck, pubData)
-	pubDataCheck = std.VerifyCreatePairTx(api, isCreatePairTx, tx.CreatePairTxInfo, tx.LiquidityBefore)
-	pubData = SelectPubData(api, isCreatePairTx, pubDataCheck, pubData)
-	pubDataCheck = std.VerifyUpdatePairRateTx(api, isUpdatePairRateTx, tx.UpdatePairRateTxInfo, tx.LiquidityBefore)
-	pubData = SelectPubData(api, isUpdatePairRateTx, pubDataCheck, pubData)
-	pubDataCheck = std.VerifyDepositTx(api, isDepositTx, tx.DepositTxInfo, tx.AccountsInfoBefore)
-	pubData = SelectPubData(api, isDepositTx, pubDataCheck, pubData)
-	pubDataCheck = std.VerifyDepositNftTx(api, isDepositNftTx, tx.DepositNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
-	pubData = SelectPubData(api, isDepositNftTx, pubDataCheck, pubData)
-	pubDataCheck = std.VerifyTransferTx(api, isTransferTx, &tx.TransferTxInfo, tx.AccountsInfoBefore)
-	pubData = SelectPubData(api, isTransferTx, pubDataCheck, pubData)
-	pubDataCheck = std.VerifySwapTx(api, isSwapTx, &tx.SwapTxInfo, tx.AccountsInfoBefore, tx.LiquidityBefore)
-	pubData = SelectPubData(api, isSwapTx, pubDataCheck, pubData)
-	pubDataCheck, err = std.VerifyAddLiquidityTx(api, isAddLiquidityTx, &tx.AddLiquidityTxInfo, tx.AccountsInfoBefore, tx.LiquidityBefore)
-	if err != nil {
-		return nil, pubData, err
+	if inSlot(std.TxTypeRegisterZns) {
+		pubDataCheck = std.VerifyRegisterZNSTx(api, isRegisterZnsTx, tx.RegisterZnsTxInfo, tx.AccountsInfoBefore)
+		pubData = SelectPubData(api, isRegisterZnsTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeCreatePair) {
+		pubDataCheck = std.VerifyCreatePairTx(api, isCreatePairTx, tx.CreatePairTxInfo, tx.LiquidityBefore)
+		pubData = SelectPubData(api, isCreatePairTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeUpdatePairRate) {
+		pubDataCheck = std.VerifyUpdatePairRateTx(api, isUpdatePairRateTx, tx.UpdatePairRateTxInfo, tx.LiquidityBefore)
+		pubData = SelectPubData(api, isUpdatePairRateTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeDeposit) {
+		pubDataCheck = std.VerifyDepositTx(api, isDepositTx, tx.DepositTxInfo, tx.AccountsInfoBefore)
+		pubData = SelectPubData(api, isDepositTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeDepositNft) {
+		pubDataCheck = std.VerifyDepositNftTx(api, isDepositNftTx, tx.DepositNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
+		pubData = SelectPubData(api, isDepositNftTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeTransfer) {
+		pubDataCheck = std.VerifyTransferTx(api, isTransferTx, &tx.TransferTxInfo, tx.AccountsInfoBefore)
+		pubData = SelectPubData(api, isTransferTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeSwap) {
+		pubDataCheck, err = std.VerifySwapTx(api, isSwapTx, &tx.SwapTxInfo, tx.AccountsInfoBefore, tx.LiquidityBefore)
+		if err != nil {
+			return nil, pubData, nil, err
+		}
+		pubData = SelectPubData(api, isSwapTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeAddLiquidity) {
+		pubDataCheck, err = std.VerifyAddLiquidityTx(api, isAddLiquidityTx, &tx.AddLiquidityTxInfo, tx.AccountsInfoBefore, tx.LiquidityBefore)
+		if err != nil {
+			return nil, pubData, nil, err
+		}
+		pubData = SelectPubData(api, isAddLiquidityTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeRemoveLiquidity) {
+		pubDataCheck, err = std.VerifyRemoveLiquidityTx(api, isRemoveLiquidityTx, &tx.RemoveLiquidityTxInfo, tx.AccountsInfoBefore, tx.LiquidityBefore)
+		if err != nil {
+			return nil, pubData, nil, err
+		}
+		pubData = SelectPubData(api, isRemoveLiquidityTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeCreateCollection) {
+		pubDataCheck = std.VerifyCreateCollectionTx(api, isCreateCollectionTx, &tx.CreateCollectionTxInfo, tx.AccountsInfoBefore)
+		pubData = SelectPubData(api, isCreateCollectionTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeWithdraw) {
+		pubDataCheck = std.VerifyWithdrawTx(api, isWithdrawTx, &tx.WithdrawTxInfo, tx.AccountsInfoBefore)
+		pubData = SelectPubData(api, isWithdrawTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeMintNft) {
+		pubDataCheck = std.VerifyMintNftTx(api, isMintNftTx, &tx.MintNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
+		pubData = SelectPubData(api, isMintNftTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeTransferNft) {
+		pubDataCheck = std.VerifyTransferNftTx(api, isTransferNftTx, &tx.TransferNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
+		pubData = SelectPubData(api, isTransferNftTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeAtomicMatch) {
+		hFunc.Reset()
+		pubDataCheck, err = std.VerifyAtomicMatchTx(
+			api, isAtomicMatchTx, &tx.AtomicMatchTxInfo, tx.AccountsInfoBefore, tx.NftBefore, blockCreatedAt,
+			hFunc,
+		)
+		if err != nil {
+			return nil, pubData, nil, err
+		}
+		pubData = SelectPubData(api, isAtomicMatchTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeCancelOffer) {
+		pubDataCheck = std.VerifyCancelOfferTx(api, isCancelOfferTx, &tx.CancelOfferTxInfo, tx.AccountsInfoBefore)
+		pubData = SelectPubData(api, isCancelOfferTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeWithdrawNft) {
+		pubDataCheck = std.VerifyWithdrawNftTx(api, isWithdrawNftTx, &tx.WithdrawNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
+		pubData = SelectPubData(api, isWithdrawNftTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeFullExit) {
+		pubDataCheck = std.VerifyFullExitTx(api, isFullExitTx, tx.FullExitTxInfo, tx.AccountsInfoBefore)
+		pubData = SelectPubData(api, isFullExitTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeFullExitNft) {
+		pubDataCheck = std.VerifyFullExitNftTx(api, isFullExitNftTx, tx.FullExitNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
+		pubData = SelectPubData(api, isFullExitNftTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeChangePubKey) {
+		pubDataCheck = std.VerifyChangePubKeyTx(api, isChangePubKeyTx, &tx.ChangePubKeyTxInfo, tx.AccountsInfoBefore)
+		pubData = SelectPubData(api, isChangePubKeyTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeFullChangePubKey) {
+		pubDataCheck = std.VerifyFullChangePubKeyTx(api, isFullChangePubKeyTx, tx.FullChangePubKeyTxInfo, tx.AccountsInfoBefore)
+		pubData = SelectPubData(api, isFullChangePubKeyTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeMatchOrder) {
+		hFunc.Reset()
+		pubDataCheck, err = std.VerifyMatchOrderTx(
+			api, isMatchOrderTx, &tx.MatchOrderTxInfo, tx.AccountsInfoBefore, blockCreatedAt,
+			hFunc,
+		)
+		if err != nil {
+			return nil, pubData, nil, err
+		}
+		pubData = SelectPubData(api, isMatchOrderTx, pubDataCheck, pubData)
+	}
+	// the first pair of a route swap is the liquidity of the tx
+	routeLiquiditiesBefore := [NbRoutePairsPerTx]LiquidityConstraints{tx.LiquidityBefore}
+	copy(routeLiquiditiesBefore[1:], tx.RouteLiquiditiesBefore[:])
+	if inSlot(std.TxTypeRouteSwap) {
+		pubDataCheck, err = std.VerifyRouteSwapTx(api, isRouteSwapTx, &tx.RouteSwapTxInfo, tx.AccountsInfoBefore, routeLiquiditiesBefore)
+		if err != nil {
+			return nil, pubData, nil, err
+		}
+		pubData = SelectPubData(api, isRouteSwapTx, pubDataCheck, pubData)
+	}
+	if inSlot(std.TxTypeCancelOrder) {
+		pubDataCheck = std.VerifyCancelOrderTx(api, isCancelOrderTx, &tx.CancelOrderTxInfo, tx.AccountsInfoBefore)
+		pubData = SelectPubData(api, isCancelOrderTx, pubDataCheck, pubData)
 	}
-	pubData = SelectPubData(api, isAddLiquidityTx, pubDataCheck, pubData)
-	pubDataCheck, err = std.VerifyRemoveLiquidityTx(api, isRemoveLiquidityTx, &tx.RemoveLiquidityTxInfo, tx.AccountsInfoBefore, tx.LiquidityBefore)
-	if err != nil {
-		return nil, pubData, err
-	}
-	pubData = SelectPubData(api, isRemoveLiquidityTx, pubDataCheck, pubData)
-	pubDataCheck = std.VerifyCreateCollectionTx(api, isCreateCollectionTx, &tx.CreateCollectionTxInfo, tx.AccountsInfoBefore)
-	pubData = SelectPubData(api, isCreateCollectionTx, pubDataCheck, pubData)
-	pubDataCheck = std.VerifyWithdrawTx(api, isWithdrawTx, &tx.WithdrawTxInfo, tx.AccountsInfoBefore)
-	pubData = SelectPubData(api, isWithdrawTx, pubDataCheck, pubData)
-	pubDataCheck = std.VerifyMintNftTx(api, isMintNftTx, &tx.MintNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
-	pubData = SelectPubData(api, isMintNftTx, pubDataCheck, pubData)
-	pubDataCheck = std.VerifyTransferNftTx(api, isTransferNftTx, &tx.TransferNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
-	pubData = SelectPubData(api, isTransferNftTx, pubDataCheck, pubData)
-	hFunc.Reset()
-	pubDataCheck, err = std.VerifyAtomicMatchTx(
-		api, isAtomicMatchTx, &tx.AtomicMatchTxInfo, tx.AccountsInfoBefore, tx.NftBefore, blockCreatedAt,
-		hFunc,
-	)
-	if err != nil {
-		return nil, pubData, err
-	}
-	pubData = SelectPubData(api, isAtomicMatchTx, pubDataCheck, pubData)
-	pubDataCheck = std.VerifyCancelOfferTx(api, isCancelOfferTx, &tx.CancelOfferTxInfo, tx.AccountsInfoBefore)
-	pubData = SelectPubData(api, isCancelOfferTx, pubDataCheck, pubData)
-	pubDataCheck = std.VerifyWithdrawNftTx(api, isWithdrawNftTx, &tx.WithdrawNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
-	pubData = SelectPubData(api, isWithdrawNftTx, pubDataCheck, pubData)
-	pubDataCheck = std.VerifyFullExitTx(api, isFullExitTx, tx.FullExitTxInfo, tx.AccountsInfoBefore)
-	pubData = SelectPubData(api, isFullExitTx, pubDataCheck, pubData)
-	pubDataCheck = std.VerifyFullExitNftTx(api, isFullExitNftTx, tx.FullExitNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
-	pubData = SelectPubData(api, isFullExitNftTx, pubDataCheck, pubData)
 
 	// verify timestamp
-	std.IsVariableLessOrEqual(api, isLayer2Tx, blockCreatedAt, tx.ExpiredAt)
+	if hasLayer2Tx {
+		std.IsVariableLessOrEqual(api, isLayer2Tx, blockCreatedAt, tx.ExpiredAt)
+	}
 
 	// empty delta
+	endDeltas := std.ProfileScope(api, "VerifyTransaction/deltas")
 	var (
 		assetDeltas    [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints
 		liquidityDelta LiquidityDeltaConstraints
 		nftDelta       NftDeltaConstraints
+		// only route swaps update the route liquidities
+		routeLiquidityDeltas [NbRoutePairsPerTx]LiquidityDeltaConstraints
 	)
 	for i := 0; i < NbAccountsPerTx; i++ {
 		assetDeltas[i] = [NbAccountAssetsPerAccount]AccountAssetDeltaConstraints{
@@ -277,6 +491,8 @@ func VerifyTransaction(
 		FeeRate:              tx.LiquidityBefore.FeeRate,
 		TreasuryAccountIndex: tx.LiquidityBefore.TreasuryAccountIndex,
 		TreasuryRate:         tx.LiquidityBefore.TreasuryRate,
+		PairType:             tx.LiquidityBefore.PairType,
+		Amplification:        tx.LiquidityBefore.Amplification,
 	}
 	nftDelta = NftDeltaConstraints{
 		CreatorAccountIndex: tx.NftBefore.CreatorAccountIndex,
@@ -288,78 +504,153 @@ func VerifyTransaction(
 		CollectionId:        tx.NftBefore.CollectionId,
 	}
 
-	// register
-	accountDelta := GetAccountDeltaFromRegisterZNS(tx.RegisterZnsTxInfo)
+	var (
+		assetDeltasCheck    [NbAccountsPerTx][NbAccountAssetsPerAccount]AccountAssetDeltaConstraints
+		liquidityDeltaCheck LiquidityDeltaConstraints
+		nftDeltaCheck       NftDeltaConstraints
+	)
 	// deposit
-	assetDeltasCheck := GetAssetDeltasFromDeposit(tx.DepositTxInfo)
-	assetDeltas = SelectAssetDeltas(api, isDepositTx, assetDeltasCheck, assetDeltas)
+	if inSlot(std.TxTypeDeposit) {
+		assetDeltasCheck = GetAssetDeltasFromDeposit(tx.DepositTxInfo)
+		assetDeltas = SelectAssetDeltas(api, isDepositTx, assetDeltasCheck, assetDeltas)
+	}
 	// create pair
-	liquidityDeltaCheck := GetLiquidityDeltaFromCreatePair(tx.CreatePairTxInfo)
-	liquidityDelta = SelectLiquidityDelta(api, isCreatePairTx, liquidityDeltaCheck, liquidityDelta)
+	if inSlot(std.TxTypeCreatePair) {
+		liquidityDeltaCheck = GetLiquidityDeltaFromCreatePair(tx.CreatePairTxInfo)
+		liquidityDelta = SelectLiquidityDelta(api, isCreatePairTx, liquidityDeltaCheck, liquidityDelta)
+	}
 	// update pair rate
-	liquidityDeltaCheck = GetLiquidityDeltaFromUpdatePairRate(tx.UpdatePairRateTxInfo, tx.LiquidityBefore)
-	liquidityDelta = SelectLiquidityDelta(api, isUpdatePairRateTx, liquidityDeltaCheck, liquidityDelta)
+	if inSlot(std.TxTypeUpdatePairRate) {
+		liquidityDeltaCheck = GetLiquidityDeltaFromUpdatePairRate(tx.UpdatePairRateTxInfo, tx.LiquidityBefore)
+		liquidityDelta = SelectLiquidityDelta(api, isUpdatePairRateTx, liquidityDeltaCheck, liquidityDelta)
+	}
 	// generic transfer
-	assetDeltasCheck = GetAssetDeltasFromTransfer(api, tx.TransferTxInfo)
-	assetDeltas = SelectAssetDeltas(api, isTransferTx, assetDeltasCheck, assetDeltas)
+	if inSlot(std.TxTypeTransfer) {
+		assetDeltasCheck = GetAssetDeltasFromTransfer(api, tx.TransferTxInfo)
+		assetDeltas = SelectAssetDeltas(api, isTransferTx, assetDeltasCheck, assetDeltas)
+	}
 	// swap
-	assetDeltasCheck, liquidityDeltaCheck = GetAssetDeltasAndLiquidityDeltaFromSwap(api, tx.SwapTxInfo, tx.LiquidityBefore)
-	assetDeltas = SelectAssetDeltas(api, isSwapTx, assetDeltasCheck, assetDeltas)
-	liquidityDelta = SelectLiquidityDelta(api, isSwapTx, liquidityDeltaCheck, liquidityDelta)
+	if inSlot(std.TxTypeSwap) {
+		assetDeltasCheck, liquidityDeltaCheck = GetAssetDeltasAndLiquidityDeltaFromSwap(api, tx.SwapTxInfo, tx.LiquidityBefore)
+		assetDeltas = SelectAssetDeltas(api, isSwapTx, assetDeltasCheck, assetDeltas)
+		liquidityDelta = SelectLiquidityDelta(api, isSwapTx, liquidityDeltaCheck, liquidityDelta)
+	}
 	// add liquidity
-	assetDeltasCheck, liquidityDeltaCheck = GetAssetDeltasAndLiquidityDeltaFromAddLiquidity(api, tx.AddLiquidityTxInfo, tx.LiquidityBefore)
-	assetDeltas = SelectAssetDeltas(api, isAddLiquidityTx, assetDeltasCheck, assetDeltas)
-	liquidityDelta = SelectLiquidityDelta(api, isAddLiquidityTx, liquidityDeltaCheck, liquidityDelta)
+	if inSlot(std.TxTypeAddLiquidity) {
+		assetDeltasCheck, liquidityDeltaCheck = GetAssetDeltasAndLiquidityDeltaFromAddLiquidity(api, tx.AddLiquidityTxInfo, tx.LiquidityBefore)
+		assetDeltas = SelectAssetDeltas(api, isAddLiquidityTx, assetDeltasCheck, assetDeltas)
+		liquidityDelta = SelectLiquidityDelta(api, isAddLiquidityTx, liquidityDeltaCheck, liquidityDelta)
+	}
 	// remove liquidity
-	assetDeltasCheck, liquidityDeltaCheck = GetAssetDeltasAndLiquidityDeltaFromRemoveLiquidity(api, tx.RemoveLiquidityTxInfo, tx.LiquidityBefore)
-	assetDeltas = SelectAssetDeltas(api, isRemoveLiquidityTx, assetDeltasCheck, assetDeltas)
-	liquidityDelta = SelectLiquidityDelta(api, isRemoveLiquidityTx, liquidityDeltaCheck, liquidityDelta)
+	if inSlot(std.TxTypeRemoveLiquidity) {
+		assetDeltasCheck, liquidityDeltaCheck = GetAssetDeltasAndLiquidityDeltaFromRemoveLiquidity(api, tx.RemoveLiquidityTxInfo, tx.LiquidityBefore)
+		assetDeltas = SelectAssetDeltas(api, isRemoveLiquidityTx, assetDeltasCheck, assetDeltas)
+		liquidityDelta = SelectLiquidityDelta(api, isRemoveLiquidityTx, liquidityDeltaCheck, liquidityDelta)
+	}
 	// withdraw
-	assetDeltasCheck = GetAssetDeltasFromWithdraw(api, tx.WithdrawTxInfo)
-	assetDeltas = SelectAssetDeltas(api, isWithdrawTx, assetDeltasCheck, assetDeltas)
+	if inSlot(std.TxTypeWithdraw) {
+		assetDeltasCheck = GetAssetDeltasFromWithdraw(api, tx.WithdrawTxInfo)
+		assetDeltas = SelectAssetDeltas(api, isWithdrawTx, assetDeltasCheck, assetDeltas)
+	}
 	// deposit nft
-	nftDeltaCheck := GetNftDeltaFromDepositNft(tx.DepositNftTxInfo)
-	nftDelta = SelectNftDeltas(api, isDepositNftTx, nftDeltaCheck, nftDelta)
+	if inSlot(std.TxTypeDepositNft) {
+		nftDeltaCheck = GetNftDeltaFromDepositNft(tx.DepositNftTxInfo)
+		nftDelta = SelectNftDeltas(api, isDepositNftTx, nftDeltaCheck, nftDelta)
+	}
 	// create collection
-	assetDeltasCheck = GetAssetDeltasFromCreateCollection(api, tx.CreateCollectionTxInfo)
-	assetDeltas = SelectAssetDeltas(api, isCreateCollectionTx, assetDeltasCheck, assetDeltas)
+	if inSlot(std.TxTypeCreateCollection) {
+		assetDeltasCheck = GetAssetDeltasFromCreateCollection(api, tx.CreateCollectionTxInfo)
+		assetDeltas = SelectAssetDeltas(api, isCreateCollectionTx, assetDeltasCheck, assetDeltas)
+	}
 	// mint nft
-	assetDeltasCheck, nftDeltaCheck = GetAssetDeltasAndNftDeltaFromMintNft(api, tx.MintNftTxInfo)
-	assetDeltas = SelectAssetDeltas(api, isMintNftTx, assetDeltasCheck, assetDeltas)
-	nftDelta = SelectNftDeltas(api, isMintNftTx, nftDeltaCheck, nftDelta)
+	if inSlot(std.TxTypeMintNft) {
+		assetDeltasCheck, nftDeltaCheck = GetAssetDeltasAndNftDeltaFromMintNft(api, tx.MintNftTxInfo)
+		assetDeltas = SelectAssetDeltas(api, isMintNftTx, assetDeltasCheck, assetDeltas)
+		nftDelta = SelectNftDeltas(api, isMintNftTx, nftDeltaCheck, nftDelta)
+	}
 	// transfer nft
-	assetDeltasCheck, nftDeltaCheck = GetAssetDeltasAndNftDeltaFromTransferNft(api, tx.TransferNftTxInfo, tx.NftBefore)
-	assetDeltas = SelectAssetDeltas(api, isTransferNftTx, assetDeltasCheck, assetDeltas)
-	nftDelta = SelectNftDeltas(api, isTransferNftTx, nftDeltaCheck, nftDelta)
-	// set nft price
-	assetDeltasCheck, nftDeltaCheck = GetAssetDeltasAndNftDeltaFromAtomicMatch(api, isAtomicMatchTx, tx.AtomicMatchTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
-	assetDeltas = SelectAssetDeltas(api, isAtomicMatchTx, assetDeltasCheck, assetDeltas)
-	nftDelta = SelectNftDeltas(api, isAtomicMatchTx, nftDeltaCheck, nftDelta)
-	// buy nft
-	assetDeltasCheck = GetAssetDeltasFromCancelOffer(api, isCancelOfferTx, tx.CancelOfferTxInfo, tx.AccountsInfoBefore)
-	assetDeltas = SelectAssetDeltas(api, isCancelOfferTx, assetDeltasCheck, assetDeltas)
+	if inSlot(std.TxTypeTransferNft) {
+		assetDeltasCheck, nftDeltaCheck = GetAssetDeltasAndNftDeltaFromTransferNft(api, tx.TransferNftTxInfo, tx.NftBefore)
+		assetDeltas = SelectAssetDeltas(api, isTransferNftTx, assetDeltasCheck, assetDeltas)
+		nftDelta = SelectNftDeltas(api, isTransferNftTx, nftDeltaCheck, nftDelta)
+	}
+	// atomic match
+	if inSlot(std.TxTypeAtomicMatch) {
+		assetDeltasCheck, nftDeltaCheck = GetAssetDeltasAndNftDeltaFromAtomicMatch(api, isAtomicMatchTx, tx.AtomicMatchTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
+		assetDeltas = SelectAssetDeltas(api, isAtomicMatchTx, assetDeltasCheck, assetDeltas)
+		nftDelta = SelectNftDeltas(api, isAtomicMatchTx, nftDeltaCheck, nftDelta)
+	}
+	// cancel offer
+	if inSlot(std.TxTypeCancelOffer) {
+		assetDeltasCheck = GetAssetDeltasFromCancelOffer(api, isCancelOfferTx, tx.CancelOfferTxInfo, tx.AccountsInfoBefore)
+		assetDeltas = SelectAssetDeltas(api, isCancelOfferTx, assetDeltasCheck, assetDeltas)
+	}
 	// withdraw nft
-	assetDeltasCheck, nftDeltaCheck = GetAssetDeltasAndNftDeltaFromWithdrawNft(api, tx.WithdrawNftTxInfo)
-	assetDeltas = SelectAssetDeltas(api, isWithdrawNftTx, assetDeltasCheck, assetDeltas)
-	nftDelta = SelectNftDeltas(api, isWithdrawNftTx, nftDeltaCheck, nftDelta)
+	if inSlot(std.TxTypeWithdrawNft) {
+		assetDeltasCheck, nftDeltaCheck = GetAssetDeltasAndNftDeltaFromWithdrawNft(api, tx.WithdrawNftTxInfo)
+		assetDeltas = SelectAssetDeltas(api, isWithdrawNftTx, assetDeltasCheck, assetDeltas)
+		nftDelta = SelectNftDeltas(api, isWithdrawNftTx, nftDeltaCheck, nftDelta)
+	}
 	// full exit
-	assetDeltasCheck = GetAssetDeltasFromFullExit(api, tx.FullExitTxInfo)
-	assetDeltas = SelectAssetDeltas(api, isFullExitTx, assetDeltasCheck, assetDeltas)
+	if inSlot(std.TxTypeFullExit) {
+		assetDeltasCheck = GetAssetDeltasFromFullExit(api, tx.FullExitTxInfo)
+		assetDeltas = SelectAssetDeltas(api, isFullExitTx, assetDeltasCheck, assetDeltas)
+	}
 	// full exit nft
-	nftDeltaCheck = GetNftDeltaFromFullExitNft()
-	nftDelta = SelectNftDeltas(api, isFullExitNftTx, nftDeltaCheck, nftDelta)
+	if inSlot(std.TxTypeFullExitNft) {
+		nftDeltaCheck = GetNftDeltaFromFullExitNft()
+		nftDelta = SelectNftDeltas(api, isFullExitNftTx, nftDeltaCheck, nftDelta)
+	}
+	// change pub key
+	if inSlot(std.TxTypeChangePubKey) {
+		assetDeltasCheck = GetAssetDeltasFromChangePubKey(api, tx.ChangePubKeyTxInfo)
+		assetDeltas = SelectAssetDeltas(api, isChangePubKeyTx, assetDeltasCheck, assetDeltas)
+	}
+	// match order
+	if inSlot(std.TxTypeMatchOrder) {
+		assetDeltasCheck = GetAssetDeltasFromMatchOrder(api, tx.MatchOrderTxInfo, tx.AccountsInfoBefore)
+		assetDeltas = SelectAssetDeltas(api, isMatchOrderTx, assetDeltasCheck, assetDeltas)
+	}
+	// route swap
+	if inSlot(std.TxTypeRouteSwap) {
+		assetDeltasCheck, routeLiquidityDeltas = GetAssetDeltasAndLiquidityDeltasFromRouteSwap(api, isRouteSwapTx, tx.RouteSwapTxInfo, routeLiquiditiesBefore)
+		assetDeltas = SelectAssetDeltas(api, isRouteSwapTx, assetDeltasCheck, assetDeltas)
+		liquidityDelta = SelectLiquidityDelta(api, isRouteSwapTx, routeLiquidityDeltas[0], liquidityDelta)
+	}
+	// cancel order
+	if inSlot(std.TxTypeCancelOrder) {
+		assetDeltasCheck = GetAssetDeltasFromCancelOrder(api, tx.CancelOrderTxInfo, tx.AccountsInfoBefore)
+		assetDeltas = SelectAssetDeltas(api, isCancelOrderTx, assetDeltasCheck, assetDeltas)
+	}
 	// update accounts
 	AccountsInfoAfter := UpdateAccounts(api, tx.AccountsInfoBefore, assetDeltas)
-	AccountsInfoAfter[0].AccountNameHash = api.Select(isRegisterZnsTx, accountDelta.AccountNameHash, AccountsInfoAfter[0].AccountNameHash)
-	AccountsInfoAfter[0].AccountPk.A.X = api.Select(isRegisterZnsTx, accountDelta.PubKey.A.X, AccountsInfoAfter[0].AccountPk.A.X)
-	AccountsInfoAfter[0].AccountPk.A.Y = api.Select(isRegisterZnsTx, accountDelta.PubKey.A.Y, AccountsInfoAfter[0].AccountPk.A.Y)
+	// register
+	if inSlot(std.TxTypeRegisterZns) {
+		accountDelta := GetAccountDeltaFromRegisterZNS(tx.RegisterZnsTxInfo)
+		AccountsInfoAfter[0].AccountNameHash = api.Select(isRegisterZnsTx, accountDelta.AccountNameHash, AccountsInfoAfter[0].AccountNameHash)
+		AccountsInfoAfter[0].AccountPk.A.X = api.Select(isRegisterZnsTx, accountDelta.PubKey.A.X, AccountsInfoAfter[0].AccountPk.A.X)
+		AccountsInfoAfter[0].AccountPk.A.Y = api.Select(isRegisterZnsTx, accountDelta.PubKey.A.Y, AccountsInfoAfter[0].AccountPk.A.Y)
+	}
+	// change pub key, signed by the key before
+	if inSlot(std.TxTypeChangePubKey) {
+		pubKey := tx.ChangePubKeyTxInfo.PubKey
+		AccountsInfoAfter[0].AccountPk.A.X = api.Select(isChangePubKeyTx, pubKey.A.X, AccountsInfoAfter[0].AccountPk.A.X)
+		AccountsInfoAfter[0].AccountPk.A.Y = api.Select(isChangePubKeyTx, pubKey.A.Y, AccountsInfoAfter[0].AccountPk.A.Y)
+		AccountsInfoAfter[0].SignerRoot = api.Select(isChangePubKeyTx, tx.ChangePubKeyTxInfo.SignerRoot, AccountsInfoAfter[0].SignerRoot)
+		AccountsInfoAfter[0].SignerThreshold = api.Select(isChangePubKeyTx, tx.ChangePubKeyTxInfo.SignerThreshold, AccountsInfoAfter[0].SignerThreshold)
+	}
+	// change pub key requested on L1, the account gets back a single key
+	if inSlot(std.TxTypeFullChangePubKey) {
+		pubKey := tx.FullChangePubKeyTxInfo.PubKey
+		AccountsInfoAfter[0].AccountPk.A.X = api.Select(isFullChangePubKeyTx, pubKey.A.X, AccountsInfoAfter[0].AccountPk.A.X)
+		AccountsInfoAfter[0].AccountPk.A.Y = api.Select(isFullChangePubKeyTx, pubKey.A.Y, AccountsInfoAfter[0].AccountPk.A.Y)
+		AccountsInfoAfter[0].SignerRoot = api.Select(isFullChangePubKeyTx, 0, AccountsInfoAfter[0].SignerRoot)
+		AccountsInfoAfter[0].SignerThreshold = api.Select(isFullChangePubKeyTx, 0, AccountsInfoAfter[0].SignerThreshold)
+	}
 	// update nonce
 	AccountsInfoAfter[0].Nonce = api.Add(AccountsInfoAfter[0].Nonce, isLayer2Tx)
 	AccountsInfoAfter[0].CollectionNonce = api.Add(AccountsInfoAfter[0].CollectionNonce, isCreateCollectionTx)
-	// update liquidity
-	LiquidityAfter := UpdateLiquidity(api, tx.LiquidityBefore, liquidityDelta)
-	// update nft
-	NftAfter := UpdateNft(tx.NftBefore, nftDelta)
+	endDeltas()
 
 	// check old state root
 	hFunc.Reset()
@@ -373,23 +664,20 @@ func VerifyTransaction(
 	std.IsVariableEqual(api, notEmptyTx, oldStateRoot, tx.StateRootBefore)
 
 	NewAccountRoot := tx.AccountRootBefore
-	for i := 0; i < NbAccountsPerTx; i++ {
+	for i := 0; i < TxSlotNbAccounts[tx.SlotType]; i++ {
 		var (
 			NewAccountAssetsRoot = tx.AccountsInfoBefore[i].AssetRoot
 		)
 		// verify account asset node hash
-		for j := 0; j < NbAccountAssetsPerAccount; j++ {
+		for j := 0; j < TxSlotNbAccountAssets[tx.SlotType]; j++ {
 			api.AssertIsLessOrEqual(tx.AccountsInfoBefore[i].AssetsInfo[j].AssetId, LastAccountAssetId)
 			assetMerkleHelper := AssetIdToMerkleHelper(api, tx.AccountsInfoBefore[i].AssetsInfo[j].AssetId)
 			hFunc.Reset()
-			hFunc.Write(
-				tx.AccountsInfoBefore[i].AssetsInfo[j].Balance,
-				tx.AccountsInfoBefore[i].AssetsInfo[j].LpAmount,
-				tx.AccountsInfoBefore[i].AssetsInfo[j].OfferCanceledOrFinalized,
-			)
+			hFunc.Write(std.CollectHashInputsFromAccountAsset(tx.AccountsInfoBefore[i].AssetsInfo[j])...)
 			assetNodeHash := hFunc.Sum()
 			// verify account asset merkle proof
 			hFunc.Reset()
+			endVerify := std.ProfileScope(api, "VerifyMerkleProof/accountAsset")
 			std.VerifyMerkleProof(
 				api,
 				notEmptyTx,
@@ -399,33 +687,24 @@ func VerifyTransaction(
 				tx.MerkleProofsAccountAssetsBefore[i][j][:],
 				assetMerkleHelper,
 			)
+			endVerify()
 			hFunc.Reset()
-			hFunc.Write(
-				AccountsInfoAfter[i].AssetsInfo[j].Balance,
-				AccountsInfoAfter[i].AssetsInfo[j].LpAmount,
-				AccountsInfoAfter[i].AssetsInfo[j].OfferCanceledOrFinalized,
-			)
+			hFunc.Write(std.CollectHashInputsFromAccountAsset(AccountsInfoAfter[i].AssetsInfo[j])...)
 			assetNodeHash = hFunc.Sum()
 			hFunc.Reset()
 			// update merkle proof
+			endUpdate := std.ProfileScope(api, "UpdateMerkleProof/accountAsset")
 			NewAccountAssetsRoot = std.UpdateMerkleProof(
 				api, hFunc, assetNodeHash, tx.MerkleProofsAccountAssetsBefore[i][j][:], assetMerkleHelper)
+			endUpdate()
 		}
 		// verify account node hash
 		api.AssertIsLessOrEqual(tx.AccountsInfoBefore[i].AccountIndex, LastAccountIndex)
 		accountIndexMerkleHelper := AccountIndexToMerkleHelper(api, tx.AccountsInfoBefore[i].AccountIndex)
-		hFunc.Reset()
-		hFunc.Write(
-			tx.AccountsInfoBefore[i].AccountNameHash,
-			tx.AccountsInfoBefore[i].AccountPk.A.X,
-			tx.AccountsInfoBefore[i].AccountPk.A.Y,
-			tx.AccountsInfoBefore[i].Nonce,
-			tx.AccountsInfoBefore[i].CollectionNonce,
-			tx.AccountsInfoBefore[i].AssetRoot,
-		)
-		accountNodeHash := hFunc.Sum()
+		accountNodeHash := std.ComputeHashFromAccount(api, tx.AccountsInfoBefore[i], tx.AccountsInfoBefore[i].AssetRoot, hFunc)
 		// verify account merkle proof
 		hFunc.Reset()
+		endVerify := std.ProfileScope(api, "VerifyMerkleProof/account")
 		std.VerifyMerkleProof(
 			api,
 			notEmptyTx,
@@ -435,103 +714,110 @@ func VerifyTransaction(
 			tx.MerkleProofsAccountBefore[i][:],
 			accountIndexMerkleHelper,
 		)
-		hFunc.Reset()
-		hFunc.Write(
-			AccountsInfoAfter[i].AccountNameHash,
-			AccountsInfoAfter[i].AccountPk.A.X,
-			AccountsInfoAfter[i].AccountPk.A.Y,
-			AccountsInfoAfter[i].Nonce,
-			AccountsInfoAfter[i].CollectionNonce,
-			NewAccountAssetsRoot,
-		)
-		accountNodeHash = hFunc.Sum()
-		hFunc.Reset()
+		endVerify()
+		accountNodeHash = std.ComputeHashFromAccount(api, AccountsInfoAfter[i], NewAccountAssetsRoot, hFunc)
 		// update merkle proof
+		endUpdate := std.ProfileScope(api, "UpdateMerkleProof/account")
 		NewAccountRoot = std.UpdateMerkleProof(api, hFunc, accountNodeHash, tx.MerkleProofsAccountBefore[i][:], accountIndexMerkleHelper)
+		endUpdate()
 	}
 
 	//// liquidity tree
 	NewLiquidityRoot := tx.LiquidityRootBefore
-	pairIndexMerkleHelper := PairIndexToMerkleHelper(api, tx.LiquidityBefore.PairIndex)
-	hFunc.Write(
-		tx.LiquidityBefore.AssetAId,
-		tx.LiquidityBefore.AssetA,
-		tx.LiquidityBefore.AssetBId,
-		tx.LiquidityBefore.AssetB,
-		tx.LiquidityBefore.LpAmount,
-		tx.LiquidityBefore.KLast,
-		tx.LiquidityBefore.FeeRate,
-		tx.LiquidityBefore.TreasuryAccountIndex,
-		tx.LiquidityBefore.TreasuryRate,
-	)
-	liquidityNodeHash := hFunc.Sum()
-	// verify account merkle proof
-	hFunc.Reset()
-	std.VerifyMerkleProof(
-		api,
-		notEmptyTx,
-		hFunc,
-		NewLiquidityRoot,
-		liquidityNodeHash,
-		tx.MerkleProofsLiquidityBefore[:],
-		pairIndexMerkleHelper,
-	)
-	hFunc.Reset()
-	hFunc.Write(
-		LiquidityAfter.AssetAId,
-		LiquidityAfter.AssetA,
-		LiquidityAfter.AssetBId,
-		LiquidityAfter.AssetB,
-		LiquidityAfter.LpAmount,
-		LiquidityAfter.KLast,
-		LiquidityAfter.FeeRate,
-		LiquidityAfter.TreasuryAccountIndex,
-		LiquidityAfter.TreasuryRate,
-	)
-	liquidityNodeHash = hFunc.Sum()
-	hFunc.Reset()
-	// update merkle proof
-	NewLiquidityRoot = std.UpdateMerkleProof(api, hFunc, liquidityNodeHash, tx.MerkleProofsLiquidityBefore[:], pairIndexMerkleHelper)
+	if IsAnyTxTypeInSlot(tx.SlotType, LiquidityTxTypes) {
+		// update liquidity, the trades update the price accumulators of their pairs
+		isLiquidityPricesTx := api.Add(isSwapTx, isAddLiquidityTx, isRemoveLiquidityTx, isRouteSwapTx)
+		LiquidityAfter := UpdateLiquidity(api, tx.LiquidityBefore, liquidityDelta)
+		LiquidityAfter, err = std.UpdateLiquidityPrices(api, isLiquidityPricesTx, tx.LiquidityBefore, LiquidityAfter, blockCreatedAt)
+		if err != nil {
+			return nil, pubData, nil, err
+		}
+		pairIndexMerkleHelper := PairIndexToMerkleHelper(api, tx.LiquidityBefore.PairIndex)
+		liquidityNodeHash := std.ComputeHashFromLiquidity(api, tx.LiquidityBefore, hFunc)
+		// verify account merkle proof
+		hFunc.Reset()
+		endVerify := std.ProfileScope(api, "VerifyMerkleProof/liquidity")
+		std.VerifyMerkleProof(
+			api,
+			notEmptyTx,
+			hFunc,
+			NewLiquidityRoot,
+			liquidityNodeHash,
+			tx.MerkleProofsLiquidityBefore[:],
+			pairIndexMerkleHelper,
+		)
+		endVerify()
+		hFunc.Reset()
+		liquidityNodeHash = std.ComputeHashFromLiquidity(api, LiquidityAfter, hFunc)
+		// update merkle proof
+		endUpdate := std.ProfileScope(api, "UpdateMerkleProof/liquidity")
+		NewLiquidityRoot = std.UpdateMerkleProof(api, hFunc, liquidityNodeHash, tx.MerkleProofsLiquidityBefore[:], pairIndexMerkleHelper)
+		endUpdate()
+	}
+	// pairs of a route swap after the first one, the unused pairs and the other
+	// tx types leave the liquidity root unchanged
+	if IsAnyTxTypeInSlot(tx.SlotType, RouteLiquidityTxTypes) {
+		isPairUsed := std.GetRouteSwapPairFlags(api, isRouteSwapTx, tx.RouteSwapTxInfo.PairsCount)
+		for i := 0; i < NbRoutePairsPerTx-1; i++ {
+			liquidityBefore := tx.RouteLiquiditiesBefore[i]
+			LiquidityAfter := UpdateLiquidity(api, liquidityBefore, routeLiquidityDeltas[i+1])
+			LiquidityAfter, err = std.UpdateLiquidityPrices(api, isPairUsed[i+1], liquidityBefore, LiquidityAfter, blockCreatedAt)
+			if err != nil {
+				return nil, pubData, nil, err
+			}
+			pairIndexMerkleHelper := PairIndexToMerkleHelper(api, liquidityBefore.PairIndex)
+			liquidityNodeHash := std.ComputeHashFromLiquidity(api, liquidityBefore, hFunc)
+			endVerify := std.ProfileScope(api, "VerifyMerkleProof/routeLiquidity")
+			std.VerifyMerkleProof(
+				api,
+				isPairUsed[i+1],
+				hFunc,
+				NewLiquidityRoot,
+				liquidityNodeHash,
+				tx.MerkleProofsRouteLiquiditiesBefore[i][:],
+				pairIndexMerkleHelper,
+			)
+			endVerify()
+			hFunc.Reset()
+			liquidityNodeHash = std.ComputeHashFromLiquidity(api, LiquidityAfter, hFunc)
+			endUpdate := std.ProfileScope(api, "UpdateMerkleProof/routeLiquidity")
+			routeLiquidityRoot := std.UpdateMerkleProof(api, hFunc, liquidityNodeHash, tx.MerkleProofsRouteLiquiditiesBefore[i][:], pairIndexMerkleHelper)
+			NewLiquidityRoot = api.Select(isPairUsed[i+1], routeLiquidityRoot, NewLiquidityRoot)
+			endUpdate()
+		}
+	}
 
 	//// nft tree
 	NewNftRoot := tx.NftRootBefore
-	nftIndexMerkleHelper := NftIndexToMerkleHelper(api, tx.NftBefore.NftIndex)
-	hFunc.Reset()
-	hFunc.Write(
-		tx.NftBefore.CreatorAccountIndex,
-		tx.NftBefore.OwnerAccountIndex,
-		tx.NftBefore.NftContentHash,
-		tx.NftBefore.NftL1Address,
-		tx.NftBefore.NftL1TokenId,
-		tx.NftBefore.CreatorTreasuryRate,
-		tx.NftBefore.CollectionId,
-	)
-	nftNodeHash := hFunc.Sum()
-	// verify account merkle proof
-	hFunc.Reset()
-	std.VerifyMerkleProof(
-		api,
-		notEmptyTx,
-		hFunc,
-		NewNftRoot,
-		nftNodeHash,
-		tx.MerkleProofsNftBefore[:],
-		nftIndexMerkleHelper,
-	)
-	hFunc.Reset()
-	hFunc.Write(
-		NftAfter.CreatorAccountIndex,
-		NftAfter.OwnerAccountIndex,
-		NftAfter.NftContentHash,
-		NftAfter.NftL1Address,
-		NftAfter.NftL1TokenId,
-		NftAfter.CreatorTreasuryRate,
-		NftAfter.CollectionId,
-	)
-	nftNodeHash = hFunc.Sum()
-	hFunc.Reset()
-	// update merkle proof
-	NewNftRoot = std.UpdateMerkleProof(api, hFunc, nftNodeHash, tx.MerkleProofsNftBefore[:], nftIndexMerkleHelper)
+	if IsAnyTxTypeInSlot(tx.SlotType, NftTxTypes) {
+		// update nft
+		NftAfter := UpdateNft(tx.NftBefore, nftDelta)
+		nftIndexMerkleHelper := NftIndexToMerkleHelper(api, tx.NftBefore.NftIndex)
+		hFunc.Reset()
+		hFunc.Write(std.CollectHashInputsFromNft(tx.NftBefore)...)
+		nftNodeHash := hFunc.Sum()
+		// verify account merkle proof
+		hFunc.Reset()
+		endVerify := std.ProfileScope(api, "VerifyMerkleProof/nft")
+		std.VerifyMerkleProof(
+			api,
+			notEmptyTx,
+			hFunc,
+			NewNftRoot,
+			nftNodeHash,
+			tx.MerkleProofsNftBefore[:],
+			nftIndexMerkleHelper,
+		)
+		endVerify()
+		hFunc.Reset()
+		hFunc.Write(std.CollectHashInputsFromNft(NftAfter)...)
+		nftNodeHash = hFunc.Sum()
+		hFunc.Reset()
+		// update merkle proof
+		endUpdate := std.ProfileScope(api, "UpdateMerkleProof/nft")
+		NewNftRoot = std.UpdateMerkleProof(api, hFunc, nftNodeHash, tx.MerkleProofsNftBefore[:], nftIndexMerkleHelper)
+		endUpdate()
+	}
 
 	// check state root
 	hFunc.Reset()
@@ -542,7 +828,7 @@ func VerifyTransaction(
 	)
 	newStateRoot := hFunc.Sum()
 	std.IsVariableEqual(api, notEmptyTx, newStateRoot, tx.StateRootAfter)
-	return isOnChainOp, pubData, nil
+	return isOnChainOp, pubData, pubDataChunks, nil
 }
 
 func EmptyTx() (oTx *Tx) {
@@ -609,6 +895,11 @@ func SetTxWitness(oTx *Tx) (witness TxConstraints, err error) {
 	witness.WithdrawNftTxInfo = std.EmptyWithdrawNftTxWitness()
 	witness.FullExitTxInfo = std.EmptyFullExitTxWitness()
 	witness.FullExitNftTxInfo = std.EmptyFullExitNftTxWitness()
+	witness.ChangePubKeyTxInfo = std.EmptyChangePubKeyTxWitness()
+	witness.FullChangePubKeyTxInfo = std.EmptyFullChangePubKeyTxWitness()
+	witness.MatchOrderTxInfo = std.EmptyMatchOrderTxWitness()
+	witness.RouteSwapTxInfo = std.EmptyRouteSwapTxWitness()
+	witness.CancelOrderTxInfo = std.EmptyCancelOrderTxWitness()
 	witness.Signature = EmptySignatureWitness()
 	witness.Nonce = oTx.Nonce
 	witness.ExpiredAt = oTx.ExpiredAt
@@ -702,6 +993,33 @@ func SetTxWitness(oTx *Tx) (witness TxConstraints, err error) {
 	case std.TxTypeFullExitNft:
 		witness.FullExitNftTxInfo = std.SetFullExitNftTxWitness(oTx.FullExitNftTxInfo)
 		break
+	case std.TxTypeChangePubKey:
+		witness.ChangePubKeyTxInfo = std.SetChangePubKeyTxWitness(oTx.ChangePubKeyTxInfo)
+		witness.Signature.R.X = oTx.Signature.R.X
+		witness.Signature.R.Y = oTx.Signature.R.Y
+		witness.Signature.S = oTx.Signature.S[:]
+		break
+	case std.TxTypeFullChangePubKey:
+		witness.FullChangePubKeyTxInfo = std.SetFullChangePubKeyTxWitness(oTx.FullChangePubKeyTxInfo)
+		break
+	case std.TxTypeMatchOrder:
+		witness.MatchOrderTxInfo = std.SetMatchOrderTxWitness(oTx.MatchOrderTxInfo)
+		witness.Signature.R.X = oTx.Signature.R.X
+		witness.Signature.R.Y = oTx.Signature.R.Y
+		witness.Signature.S = oTx.Signature.S[:]
+		break
+	case std.TxTypeRouteSwap:
+		witness.RouteSwapTxInfo = std.SetRouteSwapTxWitness(oTx.RouteSwapTxInfo)
+		witness.Signature.R.X = oTx.Signature.R.X
+		witness.Signature.R.Y = oTx.Signature.R.Y
+		witness.Signature.S = oTx.Signature.S[:]
+		break
+	case std.TxTypeCancelOrder:
+		witness.CancelOrderTxInfo = std.SetCancelOrderTxWitness(oTx.CancelOrderTxInfo)
+		witness.Signature.R.X = oTx.Signature.R.X
+		witness.Signature.R.Y = oTx.Signature.R.Y
+		witness.Signature.S = oTx.Signature.S[:]
+		break
 	default:
 		log.Println("[SetTxWitness] invalid oTx type")
 		return witness, errors.New("[SetTxWitness] invalid oTx type")
@@ -725,6 +1043,37 @@ func SetTxWitness(oTx *Tx) (witness TxConstraints, err error) {
 		log.Println("[SetTxWitness] unable to set nft witness:", err.Error())
 		return witness, err
 	}
+	// the route liquidities are only set for route swaps
+	for i := 0; i < NbRoutePairsPerTx-1; i++ {
+		routeLiquidityBefore := oTx.RouteLiquiditiesBefore[i]
+		if routeLiquidityBefore == nil {
+			routeLiquidityBefore = std.EmptyLiquidity(0)
+		}
+		witness.RouteLiquiditiesBefore[i], err = std.SetLiquidityWitness(routeLiquidityBefore)
+		if err != nil {
+			log.Println("[SetTxWitness] unable to set route liquidity witness:", err.Error())
+			return witness, err
+		}
+		for j := 0; j < LiquidityMerkleLevels; j++ {
+			witness.MerkleProofsRouteLiquiditiesBefore[i][j] = 0
+			if oTx.MerkleProofsRouteLiquiditiesBefore[i][j] != nil {
+				witness.MerkleProofsRouteLiquiditiesBefore[i][j] = oTx.MerkleProofsRouteLiquiditiesBefore[i][j]
+			}
+		}
+	}
+
+	// the signatures left of a multi-signature account are empty
+	for i := 0; i < NbMultiSigsPerTx; i++ {
+		witness.MultiSigs[i] = std.EmptyMultiSigWitness()
+		if oTx.MultiSigs[i] == nil {
+			continue
+		}
+		witness.MultiSigs[i], err = std.SetMultiSigWitness(oTx.MultiSigs[i])
+		if err != nil {
+			log.Println("[SetTxWitness] unable to set multi sig witness:", err.Error())
+			return witness, err
+		}
+	}
 
 	// account before info, size is 4
 	for i := 0; i < NbAccountsPerTx; i++ {
@@ -0,0 +1,296 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package block
+
+import (
+	"errors"
+	"log"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+/*
+	slot types: a tx slot of the block circuit is only compiled with the logic
+	of the tx types it accepts, the empty tx is accepted by every slot
+*/
+const (
+	TxSlotTypeAll = iota
+	TxSlotTypePriorityOp
+	TxSlotTypeL2Asset
+	TxSlotTypeNftMarket
+)
+
+var (
+	TxSlotTypeNames = map[int]string{
+		TxSlotTypeAll:        "all",
+		TxSlotTypePriorityOp: "priorityOp",
+		TxSlotTypeL2Asset:    "l2Asset",
+		TxSlotTypeNftMarket:  "nftMarket",
+	}
+	TxSlotTxTypes = map[int][]int{
+		TxSlotTypeAll: {
+			std.TxTypeRegisterZns,
+			std.TxTypeCreatePair,
+			std.TxTypeUpdatePairRate,
+			std.TxTypeDeposit,
+			std.TxTypeDepositNft,
+			std.TxTypeTransfer,
+			std.TxTypeSwap,
+			std.TxTypeAddLiquidity,
+			std.TxTypeRemoveLiquidity,
+			std.TxTypeWithdraw,
+			std.TxTypeCreateCollection,
+			std.TxTypeMintNft,
+			std.TxTypeTransferNft,
+			std.TxTypeAtomicMatch,
+			std.TxTypeCancelOffer,
+			std.TxTypeWithdrawNft,
+			std.TxTypeFullExit,
+			std.TxTypeFullExitNft,
+			std.TxTypeChangePubKey,
+			std.TxTypeFullChangePubKey,
+			std.TxTypeMatchOrder,
+			std.TxTypeRouteSwap,
+			std.TxTypeCancelOrder,
+		},
+		TxSlotTypePriorityOp: {
+			std.TxTypeRegisterZns,
+			std.TxTypeCreatePair,
+			std.TxTypeUpdatePairRate,
+			std.TxTypeDeposit,
+			std.TxTypeDepositNft,
+			std.TxTypeFullExit,
+			std.TxTypeFullExitNft,
+			std.TxTypeFullChangePubKey,
+		},
+		TxSlotTypeL2Asset: {
+			std.TxTypeTransfer,
+			std.TxTypeSwap,
+			std.TxTypeAddLiquidity,
+			std.TxTypeRemoveLiquidity,
+			std.TxTypeWithdraw,
+			std.TxTypeChangePubKey,
+			std.TxTypeRouteSwap,
+		},
+		TxSlotTypeNftMarket: {
+			std.TxTypeCreateCollection,
+			std.TxTypeMintNft,
+			std.TxTypeTransferNft,
+			std.TxTypeAtomicMatch,
+			std.TxTypeCancelOffer,
+			std.TxTypeWithdrawNft,
+			// the order matching reads as many accounts as the offer matching
+			std.TxTypeMatchOrder,
+			std.TxTypeCancelOrder,
+		},
+	}
+	// accounts of the tx read or updated by the tx types of the slot,
+	// the others are left out of the account tree update
+	TxSlotNbAccounts = map[int]int{
+		TxSlotTypeAll:        NbAccountsPerTx,
+		TxSlotTypePriorityOp: 1,
+		TxSlotTypeL2Asset:    3,
+		TxSlotTypeNftMarket:  NbAccountsPerTx,
+	}
+	TxSlotNbAccountAssets = map[int]int{
+		TxSlotTypeAll:        NbAccountAssetsPerAccount,
+		TxSlotTypePriorityOp: 1,
+		TxSlotTypeL2Asset:    NbAccountAssetsPerAccount,
+		TxSlotTypeNftMarket:  NbAccountAssetsPerAccount,
+	}
+	// tx types requested on L1, folded into the priority ops hash
+	PriorityOpTxTypes = []int{
+		std.TxTypeRegisterZns,
+		std.TxTypeCreatePair,
+		std.TxTypeUpdatePairRate,
+		std.TxTypeDeposit,
+		std.TxTypeDepositNft,
+		std.TxTypeFullExit,
+		std.TxTypeFullExitNft,
+		std.TxTypeFullChangePubKey,
+	}
+	// tx types signed by the account owner
+	Layer2TxTypes = []int{
+		std.TxTypeTransfer,
+		std.TxTypeSwap,
+		std.TxTypeAddLiquidity,
+		std.TxTypeRemoveLiquidity,
+		std.TxTypeWithdraw,
+		std.TxTypeCreateCollection,
+		std.TxTypeMintNft,
+		std.TxTypeTransferNft,
+		std.TxTypeAtomicMatch,
+		std.TxTypeCancelOffer,
+		std.TxTypeWithdrawNft,
+		std.TxTypeChangePubKey,
+		std.TxTypeMatchOrder,
+		std.TxTypeRouteSwap,
+		std.TxTypeCancelOrder,
+	}
+	// tx types which read or update the liquidity tree
+	LiquidityTxTypes = []int{
+		std.TxTypeCreatePair,
+		std.TxTypeUpdatePairRate,
+		std.TxTypeSwap,
+		std.TxTypeAddLiquidity,
+		std.TxTypeRemoveLiquidity,
+		std.TxTypeRouteSwap,
+	}
+	// tx types which update the liquidities of the pairs of a route after the first one
+	RouteLiquidityTxTypes = []int{
+		std.TxTypeRouteSwap,
+	}
+	// tx types which read or update the nft tree
+	NftTxTypes = []int{
+		std.TxTypeDepositNft,
+		std.TxTypeMintNft,
+		std.TxTypeTransferNft,
+		std.TxTypeAtomicMatch,
+		std.TxTypeWithdrawNft,
+		std.TxTypeFullExitNft,
+	}
+)
+
+func IsTxTypeInSlot(slotType int, txType int) bool {
+	if txType == std.TxTypeEmptyTx {
+		return true
+	}
+	for _, t := range TxSlotTxTypes[slotType] {
+		if t == txType {
+			return true
+		}
+	}
+	return false
+}
+
+func IsAnyTxTypeInSlot(slotType int, txTypes []int) bool {
+	for _, txType := range txTypes {
+		if IsTxTypeInSlot(slotType, txType) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+	ParseTxSlotType: get the slot type from its name in TxSlotTypeNames
+*/
+func ParseTxSlotType(name string) (slotType int, err error) {
+	for slotType, slotName := range TxSlotTypeNames {
+		if slotName == name {
+			return slotType, nil
+		}
+	}
+	log.Println("[ParseTxSlotType] invalid slot type name")
+	return 0, errors.New("[ParseTxSlotType] invalid slot type name")
+}
+
+/*
+	GetTxSlotType: get the smallest slot type which accepts the tx type
+*/
+func GetTxSlotType(txType int) (slotType int, err error) {
+	for _, slotType = range []int{TxSlotTypePriorityOp, TxSlotTypeL2Asset, TxSlotTypeNftMarket} {
+		if IsTxTypeInSlot(slotType, txType) {
+			return slotType, nil
+		}
+	}
+	log.Println("[GetTxSlotType] invalid tx type")
+	return 0, errors.New("[GetTxSlotType] invalid tx type")
+}
+
+/*
+	GetBlockLayout: slot types of a block with nbPriorityOps priority op slots,
+	followed by nbL2Assets l2 asset slots and nbNftMarkets nft market slots
+*/
+func GetBlockLayout(nbPriorityOps, nbL2Assets, nbNftMarkets int) (slotTypes []int) {
+	slotTypes = make([]int, 0, nbPriorityOps+nbL2Assets+nbNftMarkets)
+	for i := 0; i < nbPriorityOps; i++ {
+		slotTypes = append(slotTypes, TxSlotTypePriorityOp)
+	}
+	for i := 0; i < nbL2Assets; i++ {
+		slotTypes = append(slotTypes, TxSlotTypeL2Asset)
+	}
+	for i := 0; i < nbNftMarkets; i++ {
+		slotTypes = append(slotTypes, TxSlotTypeNftMarket)
+	}
+	return slotTypes
+}
+
+/*
+	NewBlockConstraints: block circuit with one tx slot per slot type
+*/
+func NewBlockConstraints(slotTypes []int) (circuit BlockConstraints) {
+	circuit.TxsCount = len(slotTypes)
+	circuit.Txs = make([]TxConstraints, circuit.TxsCount)
+	for i := 0; i < circuit.TxsCount; i++ {
+		circuit.Txs[i].SlotType = slotTypes[i]
+	}
+	return circuit
+}
+
+/*
+	NewCompressedBlockConstraints: block circuit with one tx slot per slot type
+	and a single public input, see CompressedBlockConstraints
+*/
+func NewCompressedBlockConstraints(slotTypes []int) (circuit CompressedBlockConstraints) {
+	blockCircuit := NewBlockConstraints(slotTypes)
+	circuit.TxsCount = blockCircuit.TxsCount
+	circuit.Txs = blockCircuit.Txs
+	return circuit
+}
+
+/*
+	CheckBlockLayout: check every tx of the block fits its slot,
+	empty txs are used to fill the unused slots
+*/
+func CheckBlockLayout(oBlock *Block, slotTypes []int) (err error) {
+	if len(oBlock.Txs) != len(slotTypes) {
+		log.Println("[CheckBlockLayout] invalid txs count")
+		return errors.New("[CheckBlockLayout] invalid txs count")
+	}
+	for i, oTx := range oBlock.Txs {
+		if !IsTxTypeInSlot(slotTypes[i], int(oTx.TxType)) {
+			log.Println("[CheckBlockLayout] tx type doesn't fit its slot")
+			return errors.New("[CheckBlockLayout] tx type doesn't fit its slot")
+		}
+	}
+	return nil
+}
+
+/*
+	FitBlockLayout: slot of each tx in a block of the slot types, the txs keep
+	their order as the state roots are chained from slot to slot, the slots
+	left are filled with empty txs. Every tx takes the first slot accepting it,
+	so the txs fit if any placement fits.
+*/
+func FitBlockLayout(txTypes []int, slotTypes []int) (slots []int, err error) {
+	slots = make([]int, len(txTypes))
+	slot := 0
+	for i, txType := range txTypes {
+		for slot < len(slotTypes) && !IsTxTypeInSlot(slotTypes[slot], txType) {
+			slot++
+		}
+		if slot == len(slotTypes) {
+			log.Println("[FitBlockLayout] txs don't fit the layout")
+			return nil, errors.New("[FitBlockLayout] txs don't fit the layout")
+		}
+		slots[i] = slot
+		slot++
+	}
+	return slots, nil
+}
@@ -0,0 +1,139 @@
+package block
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+func TestVerifyTransactionInSlot(t *testing.T) {
+	for _, slotType := range []int{TxSlotTypeAll, TxSlotTypePriorityOp, TxSlotTypeL2Asset, TxSlotTypeNftMarket} {
+		var circuit TxConstraints
+		circuit.SlotType = slotType
+		r1cs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &circuit, frontend.IgnoreUnconstrainedInputs())
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Println(TxSlotTypeNames[slotType], "slot constraints:", r1cs.GetNbConstraints())
+	}
+}
+
+func TestVerifyTransactionInTypedSlot(t *testing.T) {
+	txsInfo := []string{
+		readTxInfo(t, "createPairTx"),
+		readTxInfo(t, "updatePairRateTx"),
+		readTxInfo(t, "depositTx"),
+		readTxInfo(t, "depositNftTx"),
+		readTxInfo(t, "fullExitTx"),
+		readTxInfo(t, "fullExitNftTx"),
+	}
+	for _, txInfo := range txsInfo {
+		var oTx *Tx
+		err := json.Unmarshal([]byte(txInfo), &oTx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		witness, err := SetTxWitness(oTx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		slotType, err := GetTxSlotType(int(oTx.TxType))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// same transition in the generic slot and in the typed slot
+		for _, circuitSlotType := range []int{TxSlotTypeAll, slotType} {
+			var circuit TxConstraints
+			circuit.SlotType = circuitSlotType
+			err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16, backend.WithHints(std.ComputeSLp, std.ComputeStableSwap, std.ComputePriceCumulative))
+			if err != nil {
+				t.Fatalf("tx type %d, slot type %d: %v", oTx.TxType, circuitSlotType, err)
+			}
+		}
+		// the tx can't be put in a slot which doesn't accept it
+		var circuit TxConstraints
+		circuit.SlotType = TxSlotTypeL2Asset
+		err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16, backend.WithHints(std.ComputeSLp, std.ComputeStableSwap, std.ComputePriceCumulative))
+		if err == nil {
+			t.Fatalf("tx type %d accepted by l2 asset slot", oTx.TxType)
+		}
+	}
+}
+
+func TestVerifyBlockLayout(t *testing.T) {
+	var oTx *Tx
+	err := json.Unmarshal([]byte(readTxInfo(t, "depositTx")), &oTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// empty slots before and after the deposit
+	slotTypes := []int{TxSlotTypeL2Asset, TxSlotTypePriorityOp, TxSlotTypeNftMarket}
+	oBlock := &Block{
+		BlockNumber:  1,
+		CreatedAt:    1655348736095,
+		OldStateRoot: oTx.StateRootBefore,
+		NewStateRoot: oTx.StateRootAfter,
+		Txs:          []*Tx{EmptyTx(), oTx, EmptyTx()},
+	}
+	err = CheckBlockLayout(oBlock, slotTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if CheckBlockLayout(oBlock, GetBlockLayout(1, 1, 1)) == nil {
+		t.Fatal("deposit accepted by l2 asset slot")
+	}
+	oBlock.BlockCommitment, err = ComputeBlockCommitment(oBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oBlock.PriorityOpsHashEnd, err = ComputePriorityOpsHash(oBlock.PriorityOpsHashStart, oBlock.Txs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	witness, err := SetBlockWitness(oBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	circuit := NewBlockConstraints(slotTypes)
+	err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16, backend.WithHints(std.ComputeSLp, std.ComputeStableSwap, std.ComputePriceCumulative))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the new state root is the one of the last non empty tx
+	witness.NewStateRoot = oBlock.OldStateRoot
+	circuit = NewBlockConstraints(slotTypes)
+	err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16, backend.WithHints(std.ComputeSLp, std.ComputeStableSwap, std.ComputePriceCumulative))
+	if err == nil {
+		t.Fatal("invalid new state root accepted")
+	}
+}
+
+func TestFitBlockLayout(t *testing.T) {
+	slotTypes := []int{TxSlotTypePriorityOp, TxSlotTypeL2Asset, TxSlotTypeL2Asset, TxSlotTypeNftMarket}
+	slots, err := FitBlockLayout([]int{std.TxTypeDeposit, std.TxTypeTransfer, std.TxTypeMintNft}, slotTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(slots) != "[0 1 3]" {
+		t.Fatal("invalid slots:", slots)
+	}
+	slots, err = FitBlockLayout([]int{std.TxTypeSwap, std.TxTypeWithdraw}, slotTypes)
+	if err != nil || fmt.Sprint(slots) != "[1 2]" {
+		t.Fatal("invalid slots:", slots, err)
+	}
+	// the deposit can't be moved before the transfer
+	if _, err = FitBlockLayout([]int{std.TxTypeTransfer, std.TxTypeDeposit}, slotTypes); err == nil {
+		t.Fatal("txs reordered to fit the layout")
+	}
+	if _, err = FitBlockLayout([]int{std.TxTypeTransfer, std.TxTypeTransfer, std.TxTypeTransfer}, slotTypes); err == nil {
+		t.Fatal("more txs than slots accepted")
+	}
+}
@@ -22,9 +22,22 @@ import (
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
+/*
+	readTxInfo: json of a tx in bn254/testdata, which the executor tests share
+*/
+func readTxInfo(t *testing.T, name string) string {
+	txInfo, err := os.ReadFile(filepath.Join("..", "testdata", name+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(txInfo)
+}
+
 func TestVerifyTransaction(t *testing.T) {
 	var circuit TxConstraints
 	r1cs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &circuit, frontend.IgnoreUnconstrainedInputs())
@@ -18,6 +18,8 @@
 package block
 
 import (
+	"math/big"
+
 	"github.com/consensys/gnark/std/signature/eddsa"
 	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
 )
@@ -54,6 +56,8 @@ func SelectLiquidityDelta(
 	deltaRes.FeeRate = api.Select(flag, delta.FeeRate, deltaCheck.FeeRate)
 	deltaRes.TreasuryAccountIndex = api.Select(flag, delta.TreasuryAccountIndex, deltaCheck.TreasuryAccountIndex)
 	deltaRes.TreasuryRate = api.Select(flag, delta.TreasuryRate, deltaCheck.TreasuryRate)
+	deltaRes.PairType = api.Select(flag, delta.PairType, deltaCheck.PairType)
+	deltaRes.Amplification = api.Select(flag, delta.Amplification, deltaCheck.Amplification)
 	return deltaRes
 }
 
@@ -72,20 +76,138 @@ func SelectNftDeltas(
 	return deltaRes
 }
 
+/*
+	SelectPubData: delta only holds the chunks of its tx type, the remaining chunks are set to 0
+*/
 func SelectPubData(
 	api API,
 	flag Variable,
-	delta, deltaCheck [std.PubDataSizePerTx]Variable,
+	delta []Variable,
+	deltaCheck [std.PubDataSizePerTx]Variable,
 ) (deltaRes [std.PubDataSizePerTx]Variable) {
 	for i := 0; i < std.PubDataSizePerTx; i++ {
-		deltaRes[i] = api.Select(flag, delta[i], deltaCheck[i])
+		if i < len(delta) {
+			deltaRes[i] = api.Select(flag, delta[i], deltaCheck[i])
+		} else {
+			deltaRes[i] = api.Select(flag, 0, deltaCheck[i])
+		}
 	}
 	return deltaRes
 }
 
+/*
+	PackPubData: put the chunks of all txs next to each other, tx i starts right after
+	the chunks of tx i-1, which is at most i * PubDataSizePerTx.
+	Chunks of a tx beyond its chunks count are 0, so they don't change the packed data.
+	The offset selection costs O(TxsCount^2 * PubDataSizePerTx) constraints, see the
+	zkbas-profiler figures in the README. The commitment hashes the padded pubdata, so
+	its cost only depends on TxsCount, packing saves L1 calldata.
+*/
+func PackPubData(
+	api API,
+	txsPubData [][std.PubDataSizePerTx]Variable,
+	txsPubDataChunks []Variable,
+) (pubData []Variable, pubDataChunks Variable) {
+	pubData = make([]Variable, len(txsPubData)*std.PubDataSizePerTx)
+	for i := 0; i < len(pubData); i++ {
+		pubData[i] = 0
+	}
+	pubDataChunks = 0
+	for i := 0; i < len(txsPubData); i++ {
+		for offset := 0; offset <= i*std.PubDataSizePerTx; offset++ {
+			isOffset := api.IsZero(api.Sub(pubDataChunks, offset))
+			for j := 0; j < std.PubDataSizePerTx; j++ {
+				pubData[offset+j] = api.Add(pubData[offset+j], api.Mul(isOffset, txsPubData[i][j]))
+			}
+		}
+		pubDataChunks = api.Add(pubDataChunks, txsPubDataChunks[i])
+	}
+	return pubData, pubDataChunks
+}
+
+/*
+	PackOnChainOps: the on-chain ops of the txs in order, op k is
+	PubDataOffset << TxTypeBitsSize | TxType of the k-th tx with isOnChainOp set.
+	OnChainOpsPerWord ops are packed in a word from its most significant op,
+	unused ops are 0.
+*/
+func PackOnChainOps(
+	api API,
+	txsPubDataOffset []Variable,
+	txsType []Variable,
+	isOnChainOps []Variable,
+) (onChainOps []Variable, onChainOpsCount Variable) {
+	ops := make([]Variable, len(isOnChainOps))
+	for i := 0; i < len(ops); i++ {
+		ops[i] = 0
+	}
+	onChainOpsCount = 0
+	for i := 0; i < len(isOnChainOps); i++ {
+		op := api.Add(api.Mul(txsPubDataOffset[i], 1<<std.TxTypeBitsSize), txsType[i])
+		op = api.Mul(isOnChainOps[i], op)
+		for k := 0; k <= i; k++ {
+			isK := api.IsZero(api.Sub(onChainOpsCount, k))
+			ops[k] = api.Add(ops[k], api.Mul(isK, op))
+		}
+		onChainOpsCount = api.Add(onChainOpsCount, isOnChainOps[i])
+	}
+	onChainOps = make([]Variable, (len(ops)+OnChainOpsPerWord-1)/OnChainOpsPerWord)
+	for i := 0; i < len(onChainOps); i++ {
+		onChainOps[i] = 0
+		for j := 0; j < OnChainOpsPerWord && i*OnChainOpsPerWord+j < len(ops); j++ {
+			shift := new(big.Int).Lsh(big.NewInt(1), uint((OnChainOpsPerWord-1-j)*OnChainOpBitsSize))
+			onChainOps[i] = api.Add(onChainOps[i], api.Mul(ops[i*OnChainOpsPerWord+j], shift))
+		}
+	}
+	return onChainOps, onChainOpsCount
+}
+
+/*
+	UpdatePriorityOpsHash: the rolling hash of the priority ops after the tx, a priority op
+	folds keccak256(priorityOpsHash | PubData) keeping its low PriorityOpsHashBitsSize bits,
+	PubData being the PubDataSizePerTx chunks of the tx, the other txs keep the hash.
+	The hash is only compiled in the slots accepting priority ops.
+*/
+func UpdatePriorityOpsHash(
+	api API,
+	tx TxConstraints,
+	pubData [std.PubDataSizePerTx]Variable,
+	priorityOpsHash Variable,
+) Variable {
+	if !IsAnyTxTypeInSlot(tx.SlotType, PriorityOpTxTypes) {
+		return priorityOpsHash
+	}
+	defer std.ProfileScope(api, "UpdatePriorityOpsHash")()
+	isPriorityOp := Variable(0)
+	for _, txType := range PriorityOpTxTypes {
+		if IsTxTypeInSlot(tx.SlotType, txType) {
+			isPriorityOp = api.Add(isPriorityOp, api.IsZero(api.Sub(tx.TxType, txType)))
+		}
+	}
+	pendingHashData := make([]Variable, 0, std.PubDataSizePerTx+1)
+	pendingHashData = append(pendingHashData, priorityOpsHash)
+	pendingHashData = append(pendingHashData, pubData[:]...)
+	nextPriorityOpsHash := std.TruncatedKeccak256Variables(api, PriorityOpsHashBitsSize, pendingHashData...)
+	return api.Select(isPriorityOp, nextPriorityOpsHash, priorityOpsHash)
+}
+
 func EmptySignatureWitness() (sig eddsa.Signature) {
 	sig.R.X = std.ZeroInt
 	sig.R.Y = std.ZeroInt
 	sig.S = std.ZeroInt
 	return sig
 }
+
+/*
+	sumVariables: sum of the variables, 0 if there is none
+*/
+func sumVariables(api API, vs []Variable) Variable {
+	switch len(vs) {
+	case 0:
+		return 0
+	case 1:
+		return vs[0]
+	default:
+		return api.Add(vs[0], vs[1], vs[2:]...)
+	}
+}
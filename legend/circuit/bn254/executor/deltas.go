@@ -0,0 +1,380 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package executor
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+type assetDeltas = [block.NbAccountsPerTx][block.NbAccountAssetsPerAccount]block.AccountAssetDeltaConstraints
+
+/*
+	emptyAssetDeltas: the get*Deltas methods only set the non empty deltas of block.Get*Deltas
+*/
+func emptyAssetDeltas() (deltas assetDeltas) {
+	for i := 0; i < block.NbAccountsPerTx; i++ {
+		for j := 0; j < block.NbAccountAssetsPerAccount; j++ {
+			deltas[i][j] = block.EmptyAccountAssetDeltaConstraints()
+		}
+	}
+	return deltas
+}
+
+func unchangedLiquidityDelta(liquidityBefore std.LiquidityConstraints) block.LiquidityDeltaConstraints {
+	return block.LiquidityDeltaConstraints{
+		AssetAId:             liquidityBefore.AssetAId,
+		AssetBId:             liquidityBefore.AssetBId,
+		AssetADelta:          std.ZeroInt,
+		AssetBDelta:          std.ZeroInt,
+		LpDelta:              std.ZeroInt,
+		KLast:                liquidityBefore.KLast,
+		FeeRate:              liquidityBefore.FeeRate,
+		TreasuryAccountIndex: liquidityBefore.TreasuryAccountIndex,
+		TreasuryRate:         liquidityBefore.TreasuryRate,
+		PairType:             liquidityBefore.PairType,
+		Amplification:        liquidityBefore.Amplification,
+	}
+}
+
+func unchangedNftDelta(nftBefore std.NftConstraints) block.NftDeltaConstraints {
+	return block.NftDeltaConstraints{
+		CreatorAccountIndex: nftBefore.CreatorAccountIndex,
+		OwnerAccountIndex:   nftBefore.OwnerAccountIndex,
+		NftContentHash:      nftBefore.NftContentHash,
+		NftL1Address:        nftBefore.NftL1Address,
+		NftL1TokenId:        nftBefore.NftL1TokenId,
+		CreatorTreasuryRate: nftBefore.CreatorTreasuryRate,
+		CollectionId:        nftBefore.CollectionId,
+	}
+}
+
+/*
+	setOfferBit: canceled or finalized bits with the bit of the offer set, like the
+	FromBinary of the circuit the value is reduced mod r
+*/
+func (e *executor) setOfferBit(check string, offerCanceledOrFinalized Variable, offerId Variable) (res fr.Element) {
+	offerIndex := e.offerIndex(check, offerId)
+	bits := e.bigInt(offerCanceledOrFinalized)
+	res.SetBigInt(new(big.Int).SetBit(bits, int(offerIndex), 1))
+	return res
+}
+
+func (e *executor) getAssetDeltasFromDeposit(tx std.DepositTxConstraints) (deltas assetDeltas) {
+	deltas = emptyAssetDeltas()
+	deltas[0][0].BalanceDelta = tx.AssetAmount
+	return deltas
+}
+
+func (e *executor) getLiquidityDeltaFromCreatePair(tx std.CreatePairTxConstraints) block.LiquidityDeltaConstraints {
+	return block.LiquidityDeltaConstraints{
+		AssetAId:             tx.AssetAId,
+		AssetBId:             tx.AssetBId,
+		AssetADelta:          std.ZeroInt,
+		AssetBDelta:          std.ZeroInt,
+		LpDelta:              std.ZeroInt,
+		KLast:                std.ZeroInt,
+		FeeRate:              tx.FeeRate,
+		TreasuryAccountIndex: tx.TreasuryAccountIndex,
+		TreasuryRate:         tx.TreasuryRate,
+		PairType:             tx.PairType,
+		Amplification:        tx.Amplification,
+	}
+}
+
+func (e *executor) getLiquidityDeltaFromUpdatePairRate(tx std.UpdatePairRateTxConstraints, liquidityBefore std.LiquidityConstraints) block.LiquidityDeltaConstraints {
+	liquidityDelta := unchangedLiquidityDelta(liquidityBefore)
+	liquidityDelta.FeeRate = tx.FeeRate
+	liquidityDelta.TreasuryAccountIndex = tx.TreasuryAccountIndex
+	liquidityDelta.TreasuryRate = tx.TreasuryRate
+	return liquidityDelta
+}
+
+func (e *executor) getNftDeltaFromDepositNft(tx std.DepositNftTxConstraints) block.NftDeltaConstraints {
+	return block.NftDeltaConstraints{
+		CreatorAccountIndex: tx.CreatorAccountIndex,
+		OwnerAccountIndex:   tx.AccountIndex,
+		NftContentHash:      tx.NftContentHash,
+		NftL1Address:        tx.NftL1Address,
+		NftL1TokenId:        tx.NftL1TokenId,
+		CreatorTreasuryRate: tx.CreatorTreasuryRate,
+		CollectionId:        tx.CollectionId,
+	}
+}
+
+func (e *executor) getAssetDeltasFromTransfer(tx std.TransferTxConstraints) (deltas assetDeltas) {
+	deltas = emptyAssetDeltas()
+	deltas[0][0].BalanceDelta = e.neg(tx.AssetAmount)
+	deltas[0][1].BalanceDelta = e.neg(tx.GasFeeAssetAmount)
+	deltas[1][0].BalanceDelta = tx.AssetAmount
+	deltas[2][0].BalanceDelta = tx.GasFeeAssetAmount
+	return deltas
+}
+
+func (e *executor) getAssetDeltasAndLiquidityDeltaFromSwap(tx std.SwapTxConstraints, liquidityBefore std.LiquidityConstraints) (deltas assetDeltas, liquidityDelta block.LiquidityDeltaConstraints) {
+	deltas = emptyAssetDeltas()
+	deltas[0][0].BalanceDelta = e.neg(tx.AssetAAmount)
+	deltas[0][1].BalanceDelta = tx.AssetBAmountDelta
+	deltas[0][2].BalanceDelta = e.neg(tx.GasFeeAssetAmount)
+	deltas[1][0].BalanceDelta = tx.GasFeeAssetAmount
+	liquidityDelta = unchangedLiquidityDelta(liquidityBefore)
+	if e.isEqual(tx.AssetAId, liquidityBefore.AssetAId) {
+		liquidityDelta.AssetADelta = tx.AssetAAmount
+		liquidityDelta.AssetBDelta = e.neg(tx.AssetBAmountDelta)
+	} else {
+		liquidityDelta.AssetADelta = e.neg(tx.AssetBAmountDelta)
+		liquidityDelta.AssetBDelta = tx.AssetAAmount
+	}
+	return deltas, liquidityDelta
+}
+
+func (e *executor) getAssetDeltasAndLiquidityDeltasFromRouteSwap(tx std.RouteSwapTxConstraints, liquiditiesBefore routeLiquidities) (deltas assetDeltas, liquidityDeltas [block.NbRoutePairsPerTx]block.LiquidityDeltaConstraints) {
+	deltas = emptyAssetDeltas()
+	deltas[0][0].BalanceDelta = e.neg(tx.AssetAAmount)
+	deltas[0][1].BalanceDelta = tx.AssetBAmountDelta
+	deltas[0][2].BalanceDelta = e.neg(tx.GasFeeAssetAmount)
+	deltas[1][0].BalanceDelta = tx.GasFeeAssetAmount
+	for i, hop := range e.getRouteSwapHops(tx, liquiditiesBefore) {
+		liquidityDeltas[i] = unchangedLiquidityDelta(liquiditiesBefore[i])
+		if !hop.isUsed {
+			continue
+		}
+		if hop.isAssetAIn {
+			liquidityDeltas[i].AssetADelta = hop.amountIn
+			liquidityDeltas[i].AssetBDelta = e.neg(hop.amountOut)
+		} else {
+			liquidityDeltas[i].AssetADelta = e.neg(hop.amountOut)
+			liquidityDeltas[i].AssetBDelta = hop.amountIn
+		}
+	}
+	return deltas, liquidityDeltas
+}
+
+func (e *executor) getAssetDeltasAndLiquidityDeltaFromAddLiquidity(tx std.AddLiquidityTxConstraints, liquidityBefore std.LiquidityConstraints) (deltas assetDeltas, liquidityDelta block.LiquidityDeltaConstraints) {
+	deltas = emptyAssetDeltas()
+	deltas[0][0].BalanceDelta = e.neg(tx.AssetAAmount)
+	deltas[0][1].BalanceDelta = e.neg(tx.AssetBAmount)
+	deltas[0][2].BalanceDelta = e.neg(tx.GasFeeAssetAmount)
+	deltas[0][3].LpDelta = tx.LpAmount
+	deltas[1][0].LpDelta = tx.TreasuryAmount
+	deltas[2][0].BalanceDelta = tx.GasFeeAssetAmount
+	liquidityDelta = unchangedLiquidityDelta(liquidityBefore)
+	liquidityDelta.AssetADelta = tx.AssetAAmount
+	liquidityDelta.AssetBDelta = tx.AssetBAmount
+	liquidityDelta.LpDelta = e.add(tx.LpAmount, tx.TreasuryAmount)
+	liquidityDelta.KLast = e.mul(e.add(liquidityBefore.AssetA, tx.AssetAAmount), e.add(liquidityBefore.AssetB, tx.AssetBAmount))
+	return deltas, liquidityDelta
+}
+
+func (e *executor) getAssetDeltasAndLiquidityDeltaFromRemoveLiquidity(tx std.RemoveLiquidityTxConstraints, liquidityBefore std.LiquidityConstraints) (deltas assetDeltas, liquidityDelta block.LiquidityDeltaConstraints) {
+	deltas = emptyAssetDeltas()
+	deltas[0][0].BalanceDelta = tx.AssetAAmountDelta
+	deltas[0][1].BalanceDelta = tx.AssetBAmountDelta
+	deltas[0][2].BalanceDelta = e.neg(tx.GasFeeAssetAmount)
+	deltas[0][3].LpDelta = e.neg(tx.LpAmount)
+	deltas[1][0].LpDelta = tx.TreasuryAmount
+	deltas[2][0].BalanceDelta = tx.GasFeeAssetAmount
+	liquidityDelta = unchangedLiquidityDelta(liquidityBefore)
+	liquidityDelta.AssetADelta = e.neg(tx.AssetAAmountDelta)
+	liquidityDelta.AssetBDelta = e.neg(tx.AssetBAmountDelta)
+	liquidityDelta.LpDelta = e.sub(tx.TreasuryAmount, tx.LpAmount)
+	liquidityDelta.KLast = e.mul(e.sub(liquidityBefore.AssetA, tx.AssetAAmountDelta), e.sub(liquidityBefore.AssetB, tx.AssetBAmountDelta))
+	return deltas, liquidityDelta
+}
+
+func (e *executor) getAssetDeltasFromWithdraw(tx std.WithdrawTxConstraints) (deltas assetDeltas) {
+	deltas = emptyAssetDeltas()
+	deltas[0][0].BalanceDelta = e.neg(tx.AssetAmount)
+	deltas[0][1].BalanceDelta = e.neg(tx.GasFeeAssetAmount)
+	deltas[1][0].BalanceDelta = tx.GasFeeAssetAmount
+	return deltas
+}
+
+func (e *executor) getAssetDeltasFromCreateCollection(tx std.CreateCollectionTxConstraints) (deltas assetDeltas) {
+	deltas = emptyAssetDeltas()
+	deltas[0][0].BalanceDelta = e.neg(tx.GasFeeAssetAmount)
+	deltas[1][0].BalanceDelta = tx.GasFeeAssetAmount
+	return deltas
+}
+
+func (e *executor) getAssetDeltasAndNftDeltaFromMintNft(tx std.MintNftTxConstraints) (deltas assetDeltas, nftDelta block.NftDeltaConstraints) {
+	deltas = emptyAssetDeltas()
+	deltas[0][0].BalanceDelta = e.neg(tx.GasFeeAssetAmount)
+	deltas[2][0].BalanceDelta = tx.GasFeeAssetAmount
+	nftDelta = block.NftDeltaConstraints{
+		CreatorAccountIndex: tx.CreatorAccountIndex,
+		OwnerAccountIndex:   tx.ToAccountIndex,
+		NftContentHash:      tx.NftContentHash,
+		NftL1Address:        std.ZeroInt,
+		NftL1TokenId:        std.ZeroInt,
+		CreatorTreasuryRate: tx.CreatorTreasuryRate,
+		CollectionId:        tx.CollectionId,
+	}
+	return deltas, nftDelta
+}
+
+func (e *executor) getAssetDeltasAndNftDeltaFromTransferNft(tx std.TransferNftTxConstraints, nftBefore std.NftConstraints) (deltas assetDeltas, nftDelta block.NftDeltaConstraints) {
+	deltas = emptyAssetDeltas()
+	deltas[0][0].BalanceDelta = e.neg(tx.GasFeeAssetAmount)
+	deltas[2][0].BalanceDelta = tx.GasFeeAssetAmount
+	nftDelta = unchangedNftDelta(nftBefore)
+	nftDelta.OwnerAccountIndex = tx.ToAccountIndex
+	return deltas, nftDelta
+}
+
+/*
+	getAssetDeltasAndNftDeltaFromAtomicMatch: the creator and treasury amounts are
+	field divisions by RateBase, and the offer bits are read from the first asset
+	of the buyer and the seller, like block.GetAssetDeltasAndNftDeltaFromAtomicMatch
+*/
+func (e *executor) getAssetDeltasAndNftDeltaFromAtomicMatch(tx std.AtomicMatchTxConstraints, accountsBefore accounts, nftBefore std.NftConstraints) (deltas assetDeltas, nftDelta block.NftDeltaConstraints) {
+	deltas = emptyAssetDeltas()
+	creatorAmount := e.div(e.mul(tx.BuyOffer.AssetAmount, nftBefore.CreatorTreasuryRate), std.RateBase)
+	treasuryAmount := e.div(e.mul(tx.BuyOffer.AssetAmount, tx.BuyOffer.TreasuryRate), std.RateBase)
+	sellerAmount := e.sub(tx.BuyOffer.AssetAmount, e.add(creatorAmount, treasuryAmount))
+	deltas[0][0].BalanceDelta = e.neg(tx.GasFeeAssetAmount)
+	deltas[1][0].BalanceDelta = e.neg(tx.BuyOffer.AssetAmount)
+	deltas[1][1].OfferCanceledOrFinalized = e.setOfferBit("[GetAssetDeltasAndNftDeltaFromAtomicMatch] invalid buy offer id",
+		accountsBefore[1].AssetsInfo[0].OfferCanceledOrFinalized, tx.BuyOffer.OfferId)
+	deltas[2][0].BalanceDelta = sellerAmount
+	deltas[2][1].OfferCanceledOrFinalized = e.setOfferBit("[GetAssetDeltasAndNftDeltaFromAtomicMatch] invalid sell offer id",
+		accountsBefore[2].AssetsInfo[0].OfferCanceledOrFinalized, tx.SellOffer.OfferId)
+	deltas[3][0].BalanceDelta = creatorAmount
+	deltas[4][0].BalanceDelta = treasuryAmount
+	deltas[4][1].BalanceDelta = tx.GasFeeAssetAmount
+	nftDelta = unchangedNftDelta(nftBefore)
+	nftDelta.OwnerAccountIndex = tx.BuyOffer.AccountIndex
+	return deltas, nftDelta
+}
+
+func (e *executor) getAssetDeltasFromCancelOffer(tx std.CancelOfferTxConstraints, accountsBefore accounts) (deltas assetDeltas) {
+	deltas = emptyAssetDeltas()
+	deltas[0][0].BalanceDelta = e.neg(tx.GasFeeAssetAmount)
+	deltas[0][1].OfferCanceledOrFinalized = e.setOfferBit("[GetAssetDeltasFromCancelOffer] invalid offer id",
+		accountsBefore[0].AssetsInfo[1].OfferCanceledOrFinalized, tx.OfferId)
+	deltas[1][0].BalanceDelta = tx.GasFeeAssetAmount
+	return deltas
+}
+
+/*
+	getAssetDeltasFromMatchOrder: the fill amount of asset A is added above the offer
+	bits of the order slots, like block.GetAssetDeltasFromMatchOrder
+*/
+func (e *executor) getAssetDeltasFromMatchOrder(tx std.MatchOrderTxConstraints, accountsBefore accounts) (deltas assetDeltas) {
+	deltas = emptyAssetDeltas()
+	filledAmountDelta := e.mul(tx.AssetAFillAmount, new(big.Int).Lsh(big.NewInt(1), std.OfferSizePerAsset))
+	deltas[0][0].BalanceDelta = e.neg(tx.GasFeeAssetAmount)
+	deltas[1][0].BalanceDelta = e.neg(tx.AssetBFillAmount)
+	deltas[1][1].BalanceDelta = tx.AssetAFillAmount
+	deltas[1][2].OfferCanceledOrFinalized = e.add(accountsBefore[1].AssetsInfo[2].OfferCanceledOrFinalized, filledAmountDelta)
+	deltas[2][0].BalanceDelta = e.neg(tx.AssetAFillAmount)
+	deltas[2][1].BalanceDelta = tx.AssetBFillAmount
+	deltas[2][2].OfferCanceledOrFinalized = e.add(accountsBefore[2].AssetsInfo[2].OfferCanceledOrFinalized, filledAmountDelta)
+	deltas[3][0].BalanceDelta = tx.GasFeeAssetAmount
+	return deltas
+}
+
+/*
+	getAssetDeltasFromCancelOrder: the filled amount of the order slot is replaced by
+	std.CanceledOrderFilledAmount, like block.GetAssetDeltasFromCancelOrder
+*/
+func (e *executor) getAssetDeltasFromCancelOrder(tx std.CancelOrderTxConstraints, accountsBefore accounts) (deltas assetDeltas) {
+	deltas = emptyAssetDeltas()
+	_, filledAmount := e.splitOfferCanceledOrFinalized(accountsBefore[0].AssetsInfo[1].OfferCanceledOrFinalized)
+	filledAmountDelta := e.mul(e.sub(std.CanceledOrderFilledAmount, filledAmount), new(big.Int).Lsh(big.NewInt(1), std.OfferSizePerAsset))
+	deltas[0][0].BalanceDelta = e.neg(tx.GasFeeAssetAmount)
+	deltas[0][1].OfferCanceledOrFinalized = e.add(accountsBefore[0].AssetsInfo[1].OfferCanceledOrFinalized, filledAmountDelta)
+	deltas[1][0].BalanceDelta = tx.GasFeeAssetAmount
+	return deltas
+}
+
+func (e *executor) getAssetDeltasAndNftDeltaFromWithdrawNft(tx std.WithdrawNftTxConstraints) (deltas assetDeltas, nftDelta block.NftDeltaConstraints) {
+	deltas = emptyAssetDeltas()
+	deltas[0][0].BalanceDelta = e.neg(tx.GasFeeAssetAmount)
+	deltas[2][0].BalanceDelta = tx.GasFeeAssetAmount
+	return deltas, block.EmptyNftDeltaConstraints()
+}
+
+func (e *executor) getAssetDeltasFromChangePubKey(tx std.ChangePubKeyTxConstraints) (deltas assetDeltas) {
+	deltas = emptyAssetDeltas()
+	deltas[0][0].BalanceDelta = e.neg(tx.GasFeeAssetAmount)
+	deltas[1][0].BalanceDelta = tx.GasFeeAssetAmount
+	return deltas
+}
+
+func (e *executor) getAssetDeltasFromFullExit(tx std.FullExitTxConstraints) (deltas assetDeltas) {
+	deltas = emptyAssetDeltas()
+	deltas[0][0].BalanceDelta = e.neg(tx.AssetAmount)
+	return deltas
+}
+
+/*
+	updateAccounts: block.UpdateAccounts, the canceled or finalized bits are only
+	replaced by a non zero delta
+*/
+func (e *executor) updateAccounts(accountsBefore accounts, deltas assetDeltas) (accountsAfter accounts) {
+	accountsAfter = accountsBefore
+	for i := 0; i < block.NbAccountsPerTx; i++ {
+		for j := 0; j < block.NbAccountAssetsPerAccount; j++ {
+			asset := &accountsAfter[i].AssetsInfo[j]
+			asset.Balance = e.add(asset.Balance, deltas[i][j].BalanceDelta)
+			asset.LpAmount = e.add(asset.LpAmount, deltas[i][j].LpDelta)
+			if !e.isZero(deltas[i][j].OfferCanceledOrFinalized) {
+				asset.OfferCanceledOrFinalized = deltas[i][j].OfferCanceledOrFinalized
+			}
+		}
+	}
+	return accountsAfter
+}
+
+func (e *executor) updateLiquidity(liquidity std.LiquidityConstraints, liquidityDelta block.LiquidityDeltaConstraints) (liquidityAfter std.LiquidityConstraints) {
+	liquidityAfter = liquidity
+	liquidityAfter.AssetAId = liquidityDelta.AssetAId
+	liquidityAfter.AssetBId = liquidityDelta.AssetBId
+	liquidityAfter.AssetA = e.add(liquidity.AssetA, liquidityDelta.AssetADelta)
+	liquidityAfter.AssetB = e.add(liquidity.AssetB, liquidityDelta.AssetBDelta)
+	liquidityAfter.LpAmount = e.add(liquidity.LpAmount, liquidityDelta.LpDelta)
+	liquidityAfter.KLast = liquidityDelta.KLast
+	liquidityAfter.FeeRate = liquidityDelta.FeeRate
+	liquidityAfter.TreasuryAccountIndex = liquidityDelta.TreasuryAccountIndex
+	liquidityAfter.TreasuryRate = liquidityDelta.TreasuryRate
+	liquidityAfter.PairType = liquidityDelta.PairType
+	liquidityAfter.Amplification = liquidityDelta.Amplification
+	return liquidityAfter
+}
+
+/*
+	updateLiquidityPrices: std.UpdateLiquidityPrices
+*/
+func (e *executor) updateLiquidityPrices(liquidityBefore, liquidityAfter std.LiquidityConstraints, blockCreatedAt int64) std.LiquidityConstraints {
+	e.toBinary("[UpdateLiquidityPrices] block created before the last update of the pair",
+		e.sub(blockCreatedAt, liquidityBefore.BlockTimestampLast), std.PriceElapsedBitsSize)
+	priceACumulative, priceBCumulative, err := std.ComputeLiquidityPriceCumulatives(e.liquidity(liquidityBefore), blockCreatedAt)
+	if err != nil {
+		e.fail("[UpdateLiquidityPrices] %s", err.Error())
+		return liquidityAfter
+	}
+	liquidityAfter.PriceACumulativeLast = priceACumulative
+	liquidityAfter.PriceBCumulativeLast = priceBCumulative
+	liquidityAfter.BlockTimestampLast = blockCreatedAt
+	return liquidityAfter
+}
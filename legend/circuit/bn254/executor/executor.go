@@ -0,0 +1,692 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package executor
+
+import (
+	"errors"
+	"log"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+/*
+	TxResult: state of the tx leaves after the tx, the accounts out of the slot
+	are returned unchanged
+*/
+type TxResult struct {
+	IsOnChainOp       bool
+	PubData           []byte
+	AccountsInfoAfter [block.NbAccountsPerTx]*std.Account
+	LiquidityAfter    *std.Liquidity
+	// liquidities of the pairs of a route swap after the first one
+	RouteLiquiditiesAfter [block.NbRoutePairsPerTx - 1]*std.Liquidity
+	NftAfter              *std.Nft
+	AccountRootAfter      []byte
+	LiquidityRootAfter    []byte
+	NftRootAfter          []byte
+	StateRootAfter        []byte
+}
+
+type BlockResult struct {
+	NewStateRoot    []byte
+	PubData         []byte
+	PubDataChunks   int
+	OnChainOpsCount int
+	OnChainOps      []block.OnChainOp
+	BlockCommitment []byte
+	// rolling hash of the priority ops from oBlock.PriorityOpsHashStart
+	PriorityOpsHashEnd []byte
+	Txs                []*TxResult
+}
+
+/*
+	ExecuteTransaction: native counterpart of block.VerifyTransaction, the tx is
+	checked like in a tx slot of slotType and the leaves before the tx and their
+	merkle proofs are the state view. oTx.StateRootAfter is checked if it's set.
+	An empty tx doesn't change the state.
+*/
+func ExecuteTransaction(oTx *block.Tx, slotType int, blockCreatedAt int64) (result *TxResult, err error) {
+	if oTx == nil {
+		log.Println("[ExecuteTransaction] invalid params")
+		return nil, errors.New("[ExecuteTransaction] invalid params")
+	}
+	if _, isValid := block.TxSlotTxTypes[slotType]; !isValid {
+		log.Println("[ExecuteTransaction] invalid slot type")
+		return nil, errors.New("[ExecuteTransaction] invalid slot type")
+	}
+	if !block.IsTxTypeInSlot(slotType, int(oTx.TxType)) {
+		log.Println("[ExecuteTransaction] tx type doesn't fit its slot")
+		return nil, errors.New("[ExecuteTransaction] tx type doesn't fit its slot")
+	}
+	err = checkTxInfo(oTx)
+	if err != nil {
+		log.Println("[ExecuteTransaction] invalid tx:", err)
+		return nil, err
+	}
+	tx, err := block.SetTxWitness(oTx)
+	if err != nil {
+		log.Println("[ExecuteTransaction] unable to set tx witness:", err)
+		return nil, err
+	}
+	var e executor
+	result = e.executeTransaction(oTx, tx, slotType, blockCreatedAt)
+	if e.err != nil {
+		log.Println("[ExecuteTransaction] invalid tx:", e.err)
+		return nil, e.err
+	}
+	return result, nil
+}
+
+/*
+	ExecuteBlock: execute the txs of the block in their slots, chain the state
+	roots like block.VerifyBlock and compute the block commitment and the hash
+	of its priority ops
+*/
+func ExecuteBlock(oBlock *block.Block, slotTypes []int) (result *BlockResult, err error) {
+	if oBlock == nil {
+		log.Println("[ExecuteBlock] invalid params")
+		return nil, errors.New("[ExecuteBlock] invalid params")
+	}
+	err = block.CheckBlockLayout(oBlock, slotTypes)
+	if err != nil {
+		return nil, err
+	}
+	var e executor
+	result = &BlockResult{
+		Txs: make([]*TxResult, len(oBlock.Txs)),
+	}
+	stateRoot := oBlock.OldStateRoot
+	for i, oTx := range oBlock.Txs {
+		isEmptyTx := oTx.TxType == std.TxTypeEmptyTx
+		if !isEmptyTx {
+			e.isVariableEqual("[ExecuteBlock] invalid state root before", stateRoot, oTx.StateRootBefore)
+			if e.err != nil {
+				log.Printf("[ExecuteBlock] invalid tx %d: %v\n", i, e.err)
+				return nil, e.err
+			}
+		}
+		result.Txs[i], err = ExecuteTransaction(oTx, slotTypes[i], oBlock.CreatedAt)
+		if err != nil {
+			log.Printf("[ExecuteBlock] invalid tx %d: %v\n", i, err)
+			return nil, err
+		}
+		if !isEmptyTx {
+			stateRoot = result.Txs[i].StateRootAfter
+		}
+		if result.Txs[i].IsOnChainOp {
+			result.OnChainOpsCount++
+			result.OnChainOps = append(result.OnChainOps, block.OnChainOp{
+				PubDataOffset: len(result.PubData) / std.PubDataChunkSize,
+				TxType:        oTx.TxType,
+			})
+		}
+		result.PubData = append(result.PubData, result.Txs[i].PubData...)
+	}
+	result.NewStateRoot = stateRoot
+	result.PubDataChunks = len(result.PubData) / std.PubDataChunkSize
+	executedBlock := *oBlock
+	executedBlock.NewStateRoot = result.NewStateRoot
+	result.BlockCommitment, err = block.ComputeBlockCommitment(&executedBlock)
+	if err != nil {
+		return nil, err
+	}
+	result.PriorityOpsHashEnd, err = block.ComputePriorityOpsHash(oBlock.PriorityOpsHashStart, oBlock.Txs)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+/*
+	UpdateLeaves: leaves after the tx computed from its leaves before, the tx
+	isn't checked and the trees aren't read, so the leaves before of the slots
+	can be filled one at a time. The route liquidities after are only updated
+	by route swaps, and the price accumulators of the pairs with the block CreatedAt.
+*/
+func UpdateLeaves(oTx *block.Tx, blockCreatedAt int64) (
+	accountsAfter [block.NbAccountsPerTx]*std.Account, liquidityAfter *std.Liquidity,
+	routeLiquiditiesAfter [block.NbRoutePairsPerTx - 1]*std.Liquidity, nftAfter *std.Nft, err error,
+) {
+	if oTx == nil {
+		log.Println("[UpdateLeaves] invalid params")
+		return accountsAfter, nil, routeLiquiditiesAfter, nil, errors.New("[UpdateLeaves] invalid params")
+	}
+	err = checkTxInfo(oTx)
+	if err != nil {
+		log.Println("[UpdateLeaves] invalid tx:", err)
+		return accountsAfter, nil, routeLiquiditiesAfter, nil, err
+	}
+	tx, err := block.SetTxWitness(oTx)
+	if err != nil {
+		log.Println("[UpdateLeaves] unable to set tx witness:", err)
+		return accountsAfter, nil, routeLiquiditiesAfter, nil, err
+	}
+	var e executor
+	accounts, liquidity, routeLiquidities, nft := e.applyTransaction(tx, int(oTx.TxType), blockCreatedAt)
+	// failed checks are left to ExecuteTransaction
+	e.err = nil
+	for i := 0; i < block.NbAccountsPerTx; i++ {
+		accountsAfter[i] = e.account(accounts[i])
+	}
+	for i := 0; i < block.NbRoutePairsPerTx-1; i++ {
+		routeLiquiditiesAfter[i] = e.liquidity(routeLiquidities[i])
+	}
+	liquidityAfter, nftAfter = e.liquidity(liquidity), e.nft(nft)
+	if e.err != nil {
+		log.Println("[UpdateLeaves] invalid leaves after:", e.err)
+		return accountsAfter, nil, routeLiquiditiesAfter, nil, e.err
+	}
+	return accountsAfter, liquidityAfter, routeLiquiditiesAfter, nftAfter, nil
+}
+
+/*
+	checkTxInfo: the tx info of the tx type and the signatures are set
+*/
+func checkTxInfo(oTx *block.Tx) (err error) {
+	var isSet bool
+	switch oTx.TxType {
+	case std.TxTypeEmptyTx:
+		isSet = true
+	case std.TxTypeRegisterZns:
+		isSet = oTx.RegisterZnsTxInfo != nil && oTx.RegisterZnsTxInfo.PubKey != nil
+	case std.TxTypeCreatePair:
+		isSet = oTx.CreatePairTxInfo != nil
+	case std.TxTypeUpdatePairRate:
+		isSet = oTx.UpdatePairRateTxInfo != nil
+	case std.TxTypeDeposit:
+		isSet = oTx.DepositTxInfo != nil
+	case std.TxTypeDepositNft:
+		isSet = oTx.DepositNftTxInfo != nil
+	case std.TxTypeTransfer:
+		isSet = oTx.TransferTxInfo != nil
+	case std.TxTypeSwap:
+		isSet = oTx.SwapTxInfo != nil
+	case std.TxTypeAddLiquidity:
+		isSet = oTx.AddLiquidityTxInfo != nil
+	case std.TxTypeRemoveLiquidity:
+		isSet = oTx.RemoveLiquidityTxInfo != nil
+	case std.TxTypeWithdraw:
+		isSet = oTx.WithdrawTxInfo != nil
+	case std.TxTypeCreateCollection:
+		isSet = oTx.CreateCollectionTxInfo != nil
+	case std.TxTypeMintNft:
+		isSet = oTx.MintNftTxInfo != nil
+	case std.TxTypeTransferNft:
+		isSet = oTx.TransferNftTxInfo != nil
+	case std.TxTypeAtomicMatch:
+		isSet = oTx.AtomicMatchTxInfo != nil &&
+			oTx.AtomicMatchTxInfo.BuyOffer != nil && oTx.AtomicMatchTxInfo.BuyOffer.Sig != nil &&
+			oTx.AtomicMatchTxInfo.SellOffer != nil && oTx.AtomicMatchTxInfo.SellOffer.Sig != nil
+	case std.TxTypeCancelOffer:
+		isSet = oTx.CancelOfferTxInfo != nil
+	case std.TxTypeWithdrawNft:
+		isSet = oTx.WithdrawNftTxInfo != nil
+	case std.TxTypeFullExit:
+		isSet = oTx.FullExitTxInfo != nil
+	case std.TxTypeFullExitNft:
+		isSet = oTx.FullExitNftTxInfo != nil
+	case std.TxTypeChangePubKey:
+		isSet = oTx.ChangePubKeyTxInfo != nil && oTx.ChangePubKeyTxInfo.PubKey != nil
+	case std.TxTypeFullChangePubKey:
+		isSet = oTx.FullChangePubKeyTxInfo != nil && oTx.FullChangePubKeyTxInfo.PubKey != nil
+	case std.TxTypeMatchOrder:
+		isSet = oTx.MatchOrderTxInfo != nil &&
+			oTx.MatchOrderTxInfo.BuyOrder != nil && oTx.MatchOrderTxInfo.BuyOrder.Sig != nil &&
+			oTx.MatchOrderTxInfo.SellOrder != nil && oTx.MatchOrderTxInfo.SellOrder.Sig != nil
+	case std.TxTypeRouteSwap:
+		isSet = oTx.RouteSwapTxInfo != nil
+	case std.TxTypeCancelOrder:
+		isSet = oTx.CancelOrderTxInfo != nil
+	default:
+		return errors.New("[checkTxInfo] invalid tx type")
+	}
+	if !isSet {
+		return errors.New("[checkTxInfo] tx info is not set")
+	}
+	if isTxTypeIn(int(oTx.TxType), block.Layer2TxTypes) && oTx.Signature == nil {
+		return errors.New("[checkTxInfo] signature is not set")
+	}
+	for _, multiSig := range oTx.MultiSigs {
+		if multiSig != nil && (multiSig.PubKey == nil || multiSig.Sig == nil) {
+			return errors.New("[checkTxInfo] multi sig is not set")
+		}
+	}
+	return nil
+}
+
+func isTxTypeIn(txType int, txTypes []int) bool {
+	for _, t := range txTypes {
+		if t == txType {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *executor) collectHashInputsFromTx(txType int, tx block.TxConstraints) (inputs []Variable) {
+	switch txType {
+	case std.TxTypeTransfer:
+		return std.CollectHashInputsFromTransferTx(tx.TransferTxInfo, tx.Nonce, tx.ExpiredAt)
+	case std.TxTypeSwap:
+		packedPairIndex, assetAAmount, assetBAmount := e.swapSignedFields(tx.SwapTxInfo)
+		return std.CollectHashInputsFromSwapTx(tx.SwapTxInfo, packedPairIndex, assetAAmount, assetBAmount, tx.Nonce, tx.ExpiredAt)
+	case std.TxTypeAddLiquidity:
+		return std.CollectHashInputsFromAddLiquidityTx(tx.AddLiquidityTxInfo, tx.Nonce, tx.ExpiredAt)
+	case std.TxTypeRemoveLiquidity:
+		return std.CollectHashInputsFromRemoveLiquidityTx(tx.RemoveLiquidityTxInfo, tx.Nonce, tx.ExpiredAt)
+	case std.TxTypeWithdraw:
+		return std.CollectHashInputsFromWithdrawTx(tx.WithdrawTxInfo, tx.Nonce, tx.ExpiredAt)
+	case std.TxTypeCreateCollection:
+		return std.CollectHashInputsFromCreateCollectionTx(tx.CreateCollectionTxInfo, tx.Nonce, tx.ExpiredAt)
+	case std.TxTypeMintNft:
+		return std.CollectHashInputsFromMintNftTx(tx.MintNftTxInfo, tx.Nonce, tx.ExpiredAt)
+	case std.TxTypeTransferNft:
+		return std.CollectHashInputsFromTransferNftTx(tx.TransferNftTxInfo, tx.Nonce, tx.ExpiredAt)
+	case std.TxTypeAtomicMatch:
+		return std.CollectHashInputsFromAtomicMatchTx(tx.AtomicMatchTxInfo, tx.Nonce, tx.ExpiredAt)
+	case std.TxTypeCancelOffer:
+		return std.CollectHashInputsFromCancelOfferTx(tx.CancelOfferTxInfo, tx.Nonce, tx.ExpiredAt)
+	case std.TxTypeWithdrawNft:
+		return std.CollectHashInputsFromWithdrawNftTx(tx.WithdrawNftTxInfo, tx.Nonce, tx.ExpiredAt)
+	case std.TxTypeChangePubKey:
+		inputs = std.CollectHashInputsFromChangePubKeyTx(tx.ChangePubKeyTxInfo, tx.Nonce, tx.ExpiredAt)
+		if e.isZero(tx.ChangePubKeyTxInfo.SignerRoot) && e.isZero(tx.ChangePubKeyTxInfo.SignerThreshold) {
+			return inputs
+		}
+		return std.CollectHashInputsFromChangePubKeySigners(tx.ChangePubKeyTxInfo, e.hash(inputs...))
+	case std.TxTypeMatchOrder:
+		return std.CollectHashInputsFromMatchOrderTx(tx.MatchOrderTxInfo, tx.Nonce, tx.ExpiredAt)
+	case std.TxTypeRouteSwap:
+		return std.CollectHashInputsFromRouteSwapTx(tx.RouteSwapTxInfo, tx.Nonce, tx.ExpiredAt)
+	case std.TxTypeCancelOrder:
+		return std.CollectHashInputsFromCancelOrderTx(tx.CancelOrderTxInfo, tx.Nonce, tx.ExpiredAt)
+	}
+	return nil
+}
+
+/*
+	applyTransaction: checks of the tx and its leaves after, the trees aren't read
+*/
+func (e *executor) applyTransaction(tx block.TxConstraints, txType int, blockCreatedAt int64) (
+	accountsAfter accounts, liquidityAfter std.LiquidityConstraints,
+	routeLiquiditiesAfter [block.NbRoutePairsPerTx - 1]std.LiquidityConstraints, nftAfter std.NftConstraints,
+) {
+	isLayer2Tx := isTxTypeIn(txType, block.Layer2TxTypes)
+
+	// nonce and signature
+	if isLayer2Tx {
+		e.isVariableEqual("[VerifyTransaction] invalid nonce", tx.AccountsInfoBefore[0].Nonce, tx.Nonce)
+		hashVal := e.hash(e.collectHashInputsFromTx(txType, tx)...)
+		if e.isZero(tx.AccountsInfoBefore[0].SignerThreshold) {
+			e.verifyEddsaSig("[VerifyTransaction] invalid signature", hashVal, tx.AccountsInfoBefore[0].AccountPk, tx.Signature)
+		} else {
+			e.verifyMultiSig(hashVal, tx.AccountsInfoBefore[0], tx.MultiSigs)
+		}
+	}
+
+	// verify the tx and get its deltas
+	assetDeltas := emptyAssetDeltas()
+	liquidityDelta := unchangedLiquidityDelta(tx.LiquidityBefore)
+	nftDelta := unchangedNftDelta(tx.NftBefore)
+	var routeLiquidityDeltas [block.NbRoutePairsPerTx - 1]block.LiquidityDeltaConstraints
+	for i := 0; i < block.NbRoutePairsPerTx-1; i++ {
+		routeLiquidityDeltas[i] = unchangedLiquidityDelta(tx.RouteLiquiditiesBefore[i])
+	}
+	switch txType {
+	case std.TxTypeRegisterZns:
+		e.verifyRegisterZnsTx(tx.RegisterZnsTxInfo, tx.AccountsInfoBefore)
+	case std.TxTypeCreatePair:
+		e.verifyCreatePairTx(tx.CreatePairTxInfo, tx.LiquidityBefore)
+		liquidityDelta = e.getLiquidityDeltaFromCreatePair(tx.CreatePairTxInfo)
+	case std.TxTypeUpdatePairRate:
+		e.verifyUpdatePairRateTx(tx.UpdatePairRateTxInfo, tx.LiquidityBefore)
+		liquidityDelta = e.getLiquidityDeltaFromUpdatePairRate(tx.UpdatePairRateTxInfo, tx.LiquidityBefore)
+	case std.TxTypeDeposit:
+		e.verifyDepositTx(tx.DepositTxInfo, tx.AccountsInfoBefore)
+		assetDeltas = e.getAssetDeltasFromDeposit(tx.DepositTxInfo)
+	case std.TxTypeDepositNft:
+		e.verifyDepositNftTx(tx.DepositNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
+		nftDelta = e.getNftDeltaFromDepositNft(tx.DepositNftTxInfo)
+	case std.TxTypeTransfer:
+		e.verifyTransferTx(&tx.TransferTxInfo, tx.AccountsInfoBefore)
+		assetDeltas = e.getAssetDeltasFromTransfer(tx.TransferTxInfo)
+	case std.TxTypeSwap:
+		e.verifySwapTx(&tx.SwapTxInfo, tx.AccountsInfoBefore, tx.LiquidityBefore)
+		assetDeltas, liquidityDelta = e.getAssetDeltasAndLiquidityDeltaFromSwap(tx.SwapTxInfo, tx.LiquidityBefore)
+	case std.TxTypeAddLiquidity:
+		e.verifyAddLiquidityTx(&tx.AddLiquidityTxInfo, tx.AccountsInfoBefore, tx.LiquidityBefore)
+		assetDeltas, liquidityDelta = e.getAssetDeltasAndLiquidityDeltaFromAddLiquidity(tx.AddLiquidityTxInfo, tx.LiquidityBefore)
+	case std.TxTypeRemoveLiquidity:
+		e.verifyRemoveLiquidityTx(&tx.RemoveLiquidityTxInfo, tx.AccountsInfoBefore, tx.LiquidityBefore)
+		assetDeltas, liquidityDelta = e.getAssetDeltasAndLiquidityDeltaFromRemoveLiquidity(tx.RemoveLiquidityTxInfo, tx.LiquidityBefore)
+	case std.TxTypeWithdraw:
+		e.verifyWithdrawTx(&tx.WithdrawTxInfo, tx.AccountsInfoBefore)
+		assetDeltas = e.getAssetDeltasFromWithdraw(tx.WithdrawTxInfo)
+	case std.TxTypeCreateCollection:
+		e.verifyCreateCollectionTx(&tx.CreateCollectionTxInfo, tx.AccountsInfoBefore)
+		assetDeltas = e.getAssetDeltasFromCreateCollection(tx.CreateCollectionTxInfo)
+	case std.TxTypeMintNft:
+		e.verifyMintNftTx(&tx.MintNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
+		assetDeltas, nftDelta = e.getAssetDeltasAndNftDeltaFromMintNft(tx.MintNftTxInfo)
+	case std.TxTypeTransferNft:
+		e.verifyTransferNftTx(&tx.TransferNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
+		assetDeltas, nftDelta = e.getAssetDeltasAndNftDeltaFromTransferNft(tx.TransferNftTxInfo, tx.NftBefore)
+	case std.TxTypeAtomicMatch:
+		e.verifyAtomicMatchTx(&tx.AtomicMatchTxInfo, tx.AccountsInfoBefore, tx.NftBefore, blockCreatedAt)
+		assetDeltas, nftDelta = e.getAssetDeltasAndNftDeltaFromAtomicMatch(tx.AtomicMatchTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
+	case std.TxTypeCancelOffer:
+		e.verifyCancelOfferTx(&tx.CancelOfferTxInfo, tx.AccountsInfoBefore)
+		assetDeltas = e.getAssetDeltasFromCancelOffer(tx.CancelOfferTxInfo, tx.AccountsInfoBefore)
+	case std.TxTypeWithdrawNft:
+		e.verifyWithdrawNftTx(&tx.WithdrawNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
+		assetDeltas, nftDelta = e.getAssetDeltasAndNftDeltaFromWithdrawNft(tx.WithdrawNftTxInfo)
+	case std.TxTypeFullExit:
+		e.verifyFullExitTx(tx.FullExitTxInfo, tx.AccountsInfoBefore)
+		assetDeltas = e.getAssetDeltasFromFullExit(tx.FullExitTxInfo)
+	case std.TxTypeFullExitNft:
+		e.verifyFullExitNftTx(tx.FullExitNftTxInfo, tx.AccountsInfoBefore, tx.NftBefore)
+		nftDelta = block.EmptyNftDeltaConstraints()
+	case std.TxTypeChangePubKey:
+		e.verifyChangePubKeyTx(&tx.ChangePubKeyTxInfo, tx.AccountsInfoBefore)
+		assetDeltas = e.getAssetDeltasFromChangePubKey(tx.ChangePubKeyTxInfo)
+	case std.TxTypeFullChangePubKey:
+		e.verifyFullChangePubKeyTx(tx.FullChangePubKeyTxInfo, tx.AccountsInfoBefore)
+	case std.TxTypeMatchOrder:
+		e.verifyMatchOrderTx(&tx.MatchOrderTxInfo, tx.AccountsInfoBefore, blockCreatedAt)
+		assetDeltas = e.getAssetDeltasFromMatchOrder(tx.MatchOrderTxInfo, tx.AccountsInfoBefore)
+	case std.TxTypeRouteSwap:
+		liquiditiesBefore := routeLiquidities{tx.LiquidityBefore}
+		copy(liquiditiesBefore[1:], tx.RouteLiquiditiesBefore[:])
+		e.verifyRouteSwapTx(&tx.RouteSwapTxInfo, tx.AccountsInfoBefore, liquiditiesBefore)
+		var liquidityDeltas [block.NbRoutePairsPerTx]block.LiquidityDeltaConstraints
+		assetDeltas, liquidityDeltas = e.getAssetDeltasAndLiquidityDeltasFromRouteSwap(tx.RouteSwapTxInfo, liquiditiesBefore)
+		liquidityDelta = liquidityDeltas[0]
+		copy(routeLiquidityDeltas[:], liquidityDeltas[1:])
+	case std.TxTypeCancelOrder:
+		e.verifyCancelOrderTx(&tx.CancelOrderTxInfo, tx.AccountsInfoBefore)
+		assetDeltas = e.getAssetDeltasFromCancelOrder(tx.CancelOrderTxInfo, tx.AccountsInfoBefore)
+	}
+	if isLayer2Tx {
+		e.isVariableLessOrEqual("[VerifyTransaction] tx expired", blockCreatedAt, tx.ExpiredAt)
+	}
+
+	// update leaves
+	accountsAfter = e.updateAccounts(tx.AccountsInfoBefore, assetDeltas)
+	if txType == std.TxTypeRegisterZns {
+		accountsAfter[0].AccountNameHash = tx.RegisterZnsTxInfo.AccountNameHash
+		accountsAfter[0].AccountPk = tx.RegisterZnsTxInfo.PubKey
+	}
+	if txType == std.TxTypeChangePubKey {
+		accountsAfter[0].AccountPk = tx.ChangePubKeyTxInfo.PubKey
+		accountsAfter[0].SignerRoot = tx.ChangePubKeyTxInfo.SignerRoot
+		accountsAfter[0].SignerThreshold = tx.ChangePubKeyTxInfo.SignerThreshold
+	}
+	if txType == std.TxTypeFullChangePubKey {
+		accountsAfter[0].AccountPk = tx.FullChangePubKeyTxInfo.PubKey
+		accountsAfter[0].SignerRoot = 0
+		accountsAfter[0].SignerThreshold = 0
+	}
+	if isLayer2Tx {
+		accountsAfter[0].Nonce = e.add(accountsAfter[0].Nonce, 1)
+	}
+	if txType == std.TxTypeCreateCollection {
+		accountsAfter[0].CollectionNonce = e.add(accountsAfter[0].CollectionNonce, 1)
+	}
+	liquidityAfter = e.updateLiquidity(tx.LiquidityBefore, liquidityDelta)
+	if isTxTypeIn(txType, []int{std.TxTypeSwap, std.TxTypeAddLiquidity, std.TxTypeRemoveLiquidity, std.TxTypeRouteSwap}) {
+		liquidityAfter = e.updateLiquidityPrices(tx.LiquidityBefore, liquidityAfter, blockCreatedAt)
+	}
+	isRoutePairUsed := e.routeSwapPairFlags(txType, tx.RouteSwapTxInfo.PairsCount)
+	for i := 0; i < block.NbRoutePairsPerTx-1; i++ {
+		routeLiquiditiesAfter[i] = e.updateLiquidity(tx.RouteLiquiditiesBefore[i], routeLiquidityDeltas[i])
+		if isRoutePairUsed[i+1] {
+			routeLiquiditiesAfter[i] = e.updateLiquidityPrices(tx.RouteLiquiditiesBefore[i], routeLiquiditiesAfter[i], blockCreatedAt)
+		}
+	}
+	nftAfter = block.UpdateNft(tx.NftBefore, nftDelta)
+	return accountsAfter, liquidityAfter, routeLiquiditiesAfter, nftAfter
+}
+
+func (e *executor) executeTransaction(oTx *block.Tx, tx block.TxConstraints, slotType int, blockCreatedAt int64) (result *TxResult) {
+	txType := int(oTx.TxType)
+	isEmptyTx := txType == std.TxTypeEmptyTx
+	accountsAfter, liquidityAfter, routeLiquiditiesAfter, nftAfter := e.applyTransaction(tx, txType, blockCreatedAt)
+
+	// check old state root, the merkle proofs aren't checked for the empty tx
+	stateRootBefore := e.hash(tx.AccountRootBefore, tx.LiquidityRootBefore, tx.NftRootBefore)
+	if !isEmptyTx {
+		e.isVariableEqual("[VerifyTransaction] invalid state root before", stateRootBefore, tx.StateRootBefore)
+	}
+	verifyMerkleProof := func(check string, merkleRoot Variable, node fr.Element, proofSet []Variable, helper []uint) {
+		if !isEmptyTx {
+			e.verifyMerkleProof(check, merkleRoot, node, proofSet, helper)
+		}
+	}
+
+	// account tree
+	newAccountRoot := e.fe(tx.AccountRootBefore)
+	for i := 0; i < block.TxSlotNbAccounts[slotType]; i++ {
+		newAssetRoot := e.fe(tx.AccountsInfoBefore[i].AssetRoot)
+		for j := 0; j < block.TxSlotNbAccountAssets[slotType]; j++ {
+			e.isVariableLessOrEqual("[VerifyTransaction] invalid asset id", tx.AccountsInfoBefore[i].AssetsInfo[j].AssetId, block.LastAccountAssetId)
+			assetMerkleHelper := e.toBinary("[VerifyTransaction] invalid asset id", tx.AccountsInfoBefore[i].AssetsInfo[j].AssetId, block.AssetMerkleLevels)
+			assetNodeHash := e.hash(std.CollectHashInputsFromAccountAsset(tx.AccountsInfoBefore[i].AssetsInfo[j])...)
+			verifyMerkleProof("[VerifyTransaction] invalid account asset merkle proof",
+				newAssetRoot, assetNodeHash, tx.MerkleProofsAccountAssetsBefore[i][j][:], assetMerkleHelper)
+			assetNodeHash = e.hash(std.CollectHashInputsFromAccountAsset(accountsAfter[i].AssetsInfo[j])...)
+			newAssetRoot = e.updateMerkleProof(assetNodeHash, tx.MerkleProofsAccountAssetsBefore[i][j][:], assetMerkleHelper)
+		}
+		e.isVariableLessOrEqual("[VerifyTransaction] invalid account index", tx.AccountsInfoBefore[i].AccountIndex, block.LastAccountIndex)
+		accountIndexMerkleHelper := e.toBinary("[VerifyTransaction] invalid account index", tx.AccountsInfoBefore[i].AccountIndex, block.AccountMerkleLevels)
+		accountNodeHash := e.accountNodeHash(tx.AccountsInfoBefore[i], tx.AccountsInfoBefore[i].AssetRoot)
+		verifyMerkleProof("[VerifyTransaction] invalid account merkle proof",
+			newAccountRoot, accountNodeHash, tx.MerkleProofsAccountBefore[i][:], accountIndexMerkleHelper)
+		accountNodeHash = e.accountNodeHash(accountsAfter[i], newAssetRoot)
+		newAccountRoot = e.updateMerkleProof(accountNodeHash, tx.MerkleProofsAccountBefore[i][:], accountIndexMerkleHelper)
+		accountsAfter[i].AssetRoot = newAssetRoot
+	}
+
+	// liquidity tree
+	newLiquidityRoot := e.fe(tx.LiquidityRootBefore)
+	if !block.IsAnyTxTypeInSlot(slotType, block.LiquidityTxTypes) {
+		liquidityAfter = tx.LiquidityBefore
+	} else {
+		pairIndexMerkleHelper := e.toBinary("[VerifyTransaction] invalid pair index", tx.LiquidityBefore.PairIndex, block.LiquidityMerkleLevels)
+		liquidityNodeHash := e.liquidityNodeHash(tx.LiquidityBefore)
+		verifyMerkleProof("[VerifyTransaction] invalid liquidity merkle proof",
+			newLiquidityRoot, liquidityNodeHash, tx.MerkleProofsLiquidityBefore[:], pairIndexMerkleHelper)
+		liquidityNodeHash = e.liquidityNodeHash(liquidityAfter)
+		newLiquidityRoot = e.updateMerkleProof(liquidityNodeHash, tx.MerkleProofsLiquidityBefore[:], pairIndexMerkleHelper)
+	}
+	// pairs of a route swap after the first one, each proof is against the root
+	// after the pairs before it
+	isRoutePairUsed := e.routeSwapPairFlags(txType, tx.RouteSwapTxInfo.PairsCount)
+	for i := 0; i < block.NbRoutePairsPerTx-1; i++ {
+		if !block.IsAnyTxTypeInSlot(slotType, block.RouteLiquidityTxTypes) || !isRoutePairUsed[i+1] {
+			routeLiquiditiesAfter[i] = tx.RouteLiquiditiesBefore[i]
+			continue
+		}
+		pairIndexMerkleHelper := e.toBinary("[VerifyTransaction] invalid pair index", tx.RouteLiquiditiesBefore[i].PairIndex, block.LiquidityMerkleLevels)
+		liquidityNodeHash := e.liquidityNodeHash(tx.RouteLiquiditiesBefore[i])
+		verifyMerkleProof("[VerifyTransaction] invalid route liquidity merkle proof",
+			newLiquidityRoot, liquidityNodeHash, tx.MerkleProofsRouteLiquiditiesBefore[i][:], pairIndexMerkleHelper)
+		liquidityNodeHash = e.liquidityNodeHash(routeLiquiditiesAfter[i])
+		newLiquidityRoot = e.updateMerkleProof(liquidityNodeHash, tx.MerkleProofsRouteLiquiditiesBefore[i][:], pairIndexMerkleHelper)
+	}
+
+	// nft tree
+	newNftRoot := e.fe(tx.NftRootBefore)
+	if !block.IsAnyTxTypeInSlot(slotType, block.NftTxTypes) {
+		nftAfter = tx.NftBefore
+	} else {
+		nftIndexMerkleHelper := e.toBinary("[VerifyTransaction] invalid nft index", tx.NftBefore.NftIndex, block.NftMerkleLevels)
+		nftNodeHash := e.hash(std.CollectHashInputsFromNft(tx.NftBefore)...)
+		verifyMerkleProof("[VerifyTransaction] invalid nft merkle proof",
+			newNftRoot, nftNodeHash, tx.MerkleProofsNftBefore[:], nftIndexMerkleHelper)
+		nftNodeHash = e.hash(std.CollectHashInputsFromNft(nftAfter)...)
+		newNftRoot = e.updateMerkleProof(nftNodeHash, tx.MerkleProofsNftBefore[:], nftIndexMerkleHelper)
+	}
+
+	newStateRoot := e.hash(newAccountRoot, newLiquidityRoot, newNftRoot)
+	if isEmptyTx {
+		return e.txResult(oTx, tx.AccountsInfoBefore, tx.LiquidityBefore, tx.RouteLiquiditiesBefore, tx.NftBefore,
+			tx.AccountRootBefore, tx.LiquidityRootBefore, tx.NftRootBefore, tx.StateRootBefore)
+	}
+	if len(oTx.StateRootAfter) != 0 {
+		e.isVariableEqual("[VerifyTransaction] invalid state root after", newStateRoot, tx.StateRootAfter)
+	}
+	return e.txResult(oTx, accountsAfter, liquidityAfter, routeLiquiditiesAfter, nftAfter,
+		newAccountRoot, newLiquidityRoot, newNftRoot, newStateRoot)
+}
+
+func (e *executor) txResult(
+	oTx *block.Tx,
+	accountsAfter accounts, liquidityAfter std.LiquidityConstraints,
+	routeLiquiditiesAfter [block.NbRoutePairsPerTx - 1]std.LiquidityConstraints, nftAfter std.NftConstraints,
+	accountRoot, liquidityRoot, nftRoot, stateRoot Variable,
+) (result *TxResult) {
+	pubData, err := block.CollectPubDataFromTx(oTx)
+	if err != nil {
+		e.fail("[ExecuteTransaction] unable to collect pubdata: %v", err)
+	}
+	result = &TxResult{
+		IsOnChainOp:        block.IsOnChainOp(oTx.TxType),
+		PubData:            pubData,
+		LiquidityAfter:     e.liquidity(liquidityAfter),
+		NftAfter:           e.nft(nftAfter),
+		AccountRootAfter:   e.bytes(accountRoot),
+		LiquidityRootAfter: e.bytes(liquidityRoot),
+		NftRootAfter:       e.bytes(nftRoot),
+		StateRootAfter:     e.bytes(stateRoot),
+	}
+	for i := 0; i < block.NbAccountsPerTx; i++ {
+		result.AccountsInfoAfter[i] = e.account(accountsAfter[i])
+	}
+	for i := 0; i < block.NbRoutePairsPerTx-1; i++ {
+		result.RouteLiquiditiesAfter[i] = e.liquidity(routeLiquiditiesAfter[i])
+	}
+	return result
+}
+
+func (e *executor) account(account std.AccountConstraints) *std.Account {
+	accountPk := new(eddsa.PublicKey)
+	accountPk.A.X = e.fe(account.AccountPk.A.X)
+	accountPk.A.Y = e.fe(account.AccountPk.A.Y)
+	res := &std.Account{
+		AccountIndex:    e.int64Value(account.AccountIndex),
+		AccountNameHash: e.bytes(account.AccountNameHash),
+		AccountPk:       accountPk,
+		Nonce:           e.int64Value(account.Nonce),
+		CollectionNonce: e.int64Value(account.CollectionNonce),
+		AssetRoot:       e.bytes(account.AssetRoot),
+		SignerRoot:      e.bytes(account.SignerRoot),
+		SignerThreshold: e.int64Value(account.SignerThreshold),
+	}
+	for j := 0; j < block.NbAccountAssetsPerAccount; j++ {
+		res.AssetsInfo[j] = &std.AccountAsset{
+			AssetId:                  e.int64Value(account.AssetsInfo[j].AssetId),
+			Balance:                  e.bigInt(account.AssetsInfo[j].Balance),
+			LpAmount:                 e.bigInt(account.AssetsInfo[j].LpAmount),
+			OfferCanceledOrFinalized: e.bigInt(account.AssetsInfo[j].OfferCanceledOrFinalized),
+		}
+	}
+	return res
+}
+
+func (e *executor) liquidity(liquidity std.LiquidityConstraints) *std.Liquidity {
+	return &std.Liquidity{
+		PairIndex:            e.int64Value(liquidity.PairIndex),
+		AssetAId:             e.int64Value(liquidity.AssetAId),
+		AssetA:               e.bigInt(liquidity.AssetA),
+		AssetBId:             e.int64Value(liquidity.AssetBId),
+		AssetB:               e.bigInt(liquidity.AssetB),
+		LpAmount:             e.bigInt(liquidity.LpAmount),
+		KLast:                e.bigInt(liquidity.KLast),
+		FeeRate:              e.int64Value(liquidity.FeeRate),
+		TreasuryAccountIndex: e.int64Value(liquidity.TreasuryAccountIndex),
+		TreasuryRate:         e.int64Value(liquidity.TreasuryRate),
+		PairType:             e.int64Value(liquidity.PairType),
+		Amplification:        e.int64Value(liquidity.Amplification),
+		PriceACumulativeLast: e.bigInt(liquidity.PriceACumulativeLast),
+		PriceBCumulativeLast: e.bigInt(liquidity.PriceBCumulativeLast),
+		BlockTimestampLast:   e.int64Value(liquidity.BlockTimestampLast),
+	}
+}
+
+/*
+	packPairFeeRate: std.PackPairFeeRate
+*/
+func (e *executor) packPairFeeRate(liquidity std.LiquidityConstraints) Variable {
+	return e.add(e.add(liquidity.FeeRate, e.mul(liquidity.PairType, 1<<std.FeeRateBitsSize)),
+		e.mul(liquidity.Amplification, 1<<(std.FeeRateBitsSize+std.PairTypeBitsSize)))
+}
+
+/*
+	accountNodeHash: std.ComputeHashFromAccount
+*/
+func (e *executor) accountNodeHash(account std.AccountConstraints, assetRoot Variable) fr.Element {
+	fieldsHash := e.hash(std.CollectHashInputsFromAccount(account, assetRoot)...)
+	if e.isZero(account.SignerRoot) && e.isZero(account.SignerThreshold) {
+		return fieldsHash
+	}
+	return e.hash(std.CollectHashInputsFromAccountSigners(account, fieldsHash)...)
+}
+
+/*
+	liquidityNodeHash: std.ComputeHashFromLiquidity
+*/
+func (e *executor) liquidityNodeHash(liquidity std.LiquidityConstraints) fr.Element {
+	fieldsHash := e.hash(std.CollectHashInputsFromLiquidity(liquidity, e.packPairFeeRate(liquidity))...)
+	if e.isZero(liquidity.PriceACumulativeLast) && e.isZero(liquidity.PriceBCumulativeLast) && e.isZero(liquidity.BlockTimestampLast) {
+		return fieldsHash
+	}
+	return e.hash(std.CollectHashInputsFromLiquidityPrices(liquidity, fieldsHash)...)
+}
+
+/*
+	swapSignedFields: std.SwapSignedFields, the mode is a boolean of api.Select
+*/
+func (e *executor) swapSignedFields(tx std.SwapTxConstraints) (packedPairIndex, assetAAmount, assetBAmount Variable) {
+	packedPairIndex = e.add(tx.PairIndex, e.mul(tx.SwapMode, 1<<std.PairIndexBitsSize))
+	switch {
+	case e.isEqual(tx.SwapMode, std.SwapModeExactInput):
+		return packedPairIndex, tx.AssetAAmount, tx.AssetBMinAmount
+	case e.isEqual(tx.SwapMode, std.SwapModeExactOutput):
+		return packedPairIndex, tx.AssetAMaxAmount, tx.AssetBAmountDelta
+	default:
+		e.fail("[VerifyTransaction] invalid swap mode")
+		return packedPairIndex, std.ZeroInt, std.ZeroInt
+	}
+}
+
+func (e *executor) nft(nft std.NftConstraints) *std.Nft {
+	return &std.Nft{
+		NftIndex:            e.int64Value(nft.NftIndex),
+		NftContentHash:      e.bytes(nft.NftContentHash),
+		CreatorAccountIndex: e.int64Value(nft.CreatorAccountIndex),
+		OwnerAccountIndex:   e.int64Value(nft.OwnerAccountIndex),
+		NftL1Address:        e.bigInt(nft.NftL1Address),
+		NftL1TokenId:        e.bigInt(nft.NftL1TokenId),
+		CreatorTreasuryRate: e.int64Value(nft.CreatorTreasuryRate),
+		CollectionId:        e.int64Value(nft.CollectionId),
+	}
+}
@@ -0,0 +1,248 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/test"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+/*
+	parseTx: tx of bn254/testdata, the txs of the block circuit tests and the txs
+	built by witness.State in blocks created at block.TxConstraintsBlockCreatedAt
+*/
+func parseTx(t *testing.T, name string) *block.Tx {
+	txInfo, err := os.ReadFile(filepath.Join("..", "testdata", name+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var oTx *block.Tx
+	err = json.Unmarshal(txInfo, &oTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return oTx
+}
+
+/*
+	TestExecuteTransaction: every tx type is executed to the state root after and
+	the pubdata of its fixture, which the circuit of its typed slot accepts, and is
+	rejected by the slots without it
+*/
+func TestExecuteTransaction(t *testing.T) {
+	txsInfo := []string{
+		"registerZnsTx",
+		"createPairTx",
+		"updatePairRateTx",
+		"depositTx",
+		"depositNftTx",
+		"transferTx",
+		"swapTx",
+		"addLiquidityTx",
+		"removeLiquidityTx",
+		"withdrawTx",
+		"createCollectionTx",
+		"mintNftTx",
+		"transferNftTx",
+		"atomicMatchTx",
+		"cancelOfferTx",
+		"withdrawNftTx",
+		"fullExitTx",
+		"fullExitNftTx",
+		"changePubKeyTx",
+		"fullChangePubKeyTx",
+		"matchOrderTx",
+		"routeSwapTx",
+		"cancelOrderTx",
+	}
+	txTypes := make(map[uint8]bool)
+	for _, txInfo := range txsInfo {
+		oTx := parseTx(t, txInfo)
+		txTypes[oTx.TxType] = true
+		slotType, err := block.GetTxSlotType(int(oTx.TxType))
+		if err != nil {
+			t.Fatal(err)
+		}
+		stateRootAfter := oTx.StateRootAfter
+		// the state root after is computed, not read from the tx
+		oTx.StateRootAfter = nil
+		for _, txSlotType := range []int{block.TxSlotTypeAll, slotType} {
+			result, err := ExecuteTransaction(oTx, txSlotType, block.TxConstraintsBlockCreatedAt)
+			if err != nil {
+				t.Fatalf("tx type %d, slot type %d: %v", oTx.TxType, txSlotType, err)
+			}
+			if !bytes.Equal(result.StateRootAfter, stateRootAfter) {
+				t.Fatalf("tx type %d, slot type %d: invalid state root after", oTx.TxType, txSlotType)
+			}
+			pubData, err := block.CollectPubDataFromTx(oTx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(result.PubData, pubData) || result.IsOnChainOp != block.IsOnChainOp(oTx.TxType) {
+				t.Fatalf("tx type %d: invalid pubdata", oTx.TxType)
+			}
+		}
+		// the circuit checks the state root after, its pubdata is checked against
+		// the native pubdata by the block tests
+		oTx.StateRootAfter = stateRootAfter
+		witness, err := block.SetTxWitness(oTx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		circuit := block.TxConstraints{SlotType: slotType}
+		err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16, backend.WithHints(std.ComputeSLp, std.ComputeStableSwap, std.ComputePriceCumulative))
+		if err != nil {
+			t.Fatalf("tx type %d: %v", oTx.TxType, err)
+		}
+		for _, txSlotType := range []int{block.TxSlotTypePriorityOp, block.TxSlotTypeL2Asset, block.TxSlotTypeNftMarket} {
+			if block.IsTxTypeInSlot(txSlotType, int(oTx.TxType)) {
+				continue
+			}
+			if _, err = ExecuteTransaction(oTx, txSlotType, block.TxConstraintsBlockCreatedAt); err == nil {
+				t.Fatalf("tx type %d accepted by slot type %d", oTx.TxType, txSlotType)
+			}
+		}
+	}
+	for _, txType := range block.TxSlotTxTypes[block.TxSlotTypeAll] {
+		if !txTypes[uint8(txType)] {
+			t.Fatalf("tx type %d not executed", txType)
+		}
+	}
+}
+
+func TestExecuteDepositTransaction(t *testing.T) {
+	oTx := parseTx(t, "depositTx")
+	result, err := ExecuteTransaction(oTx, block.TxSlotTypePriorityOp, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountBefore, accountAfter := oTx.AccountsInfoBefore[0], result.AccountsInfoAfter[0]
+	balance := accountBefore.AssetsInfo[0].Balance
+	balance.Add(balance, oTx.DepositTxInfo.AssetAmount)
+	if accountAfter.AssetsInfo[0].Balance.Cmp(balance) != 0 {
+		t.Fatal("invalid balance after deposit")
+	}
+	if accountAfter.Nonce != accountBefore.Nonce {
+		t.Fatal("nonce of a priority op updated")
+	}
+	// the deposit is for the account of the name hash
+	oTx = parseTx(t, "depositTx")
+	oTx.DepositTxInfo.AccountNameHash = oTx.StateRootBefore
+	if _, err = ExecuteTransaction(oTx, block.TxSlotTypePriorityOp, 0); err == nil {
+		t.Fatal("deposit to another account accepted")
+	}
+	// the account is in the account tree
+	oTx = parseTx(t, "depositTx")
+	oTx.MerkleProofsAccountBefore[0][0] = oTx.MerkleProofsAccountBefore[0][1]
+	if _, err = ExecuteTransaction(oTx, block.TxSlotTypePriorityOp, 0); err == nil {
+		t.Fatal("invalid account merkle proof accepted")
+	}
+	// the state root after is checked when it's set
+	oTx = parseTx(t, "depositTx")
+	oTx.StateRootAfter = oTx.StateRootBefore
+	if _, err = ExecuteTransaction(oTx, block.TxSlotTypePriorityOp, 0); err == nil {
+		t.Fatal("invalid state root after accepted")
+	}
+}
+
+func TestExecuteRegisterZnsTransaction(t *testing.T) {
+	oTx := parseTx(t, "registerZnsTx")
+	if _, err := ExecuteTransaction(oTx, block.TxSlotTypePriorityOp, 0); err != nil {
+		t.Fatal(err)
+	}
+	// the registered leaf has no signer set, like in std.CheckEmptyAccountNode
+	oTx = parseTx(t, "registerZnsTx")
+	oTx.AccountsInfoBefore[0].SignerRoot = oTx.StateRootBefore
+	_, err := ExecuteTransaction(oTx, block.TxSlotTypePriorityOp, 0)
+	if err == nil || !strings.Contains(err.Error(), "account is not empty") {
+		t.Fatal("registration over an account with a signer root accepted:", err)
+	}
+}
+
+func TestExecuteCreatePairTransaction(t *testing.T) {
+	oTx := parseTx(t, "createPairTx")
+	if _, err := ExecuteTransaction(oTx, block.TxSlotTypePriorityOp, 0); err != nil {
+		t.Fatal(err)
+	}
+	// the created leaf has no price accumulators, like in std.CheckEmptyLiquidityNode
+	oTx = parseTx(t, "createPairTx")
+	oTx.LiquidityBefore.BlockTimestampLast = block.TxConstraintsBlockCreatedAt
+	_, err := ExecuteTransaction(oTx, block.TxSlotTypePriorityOp, 0)
+	if err == nil || !strings.Contains(err.Error(), "pair is not empty") {
+		t.Fatal("pair created over a liquidity with a timestamp accepted:", err)
+	}
+}
+
+func TestExecuteBlock(t *testing.T) {
+	oTx := parseTx(t, "depositTx")
+	slotTypes := []int{block.TxSlotTypeL2Asset, block.TxSlotTypePriorityOp, block.TxSlotTypeNftMarket}
+	oBlock := &block.Block{
+		BlockNumber:  1,
+		CreatedAt:    1655348736095,
+		OldStateRoot: oTx.StateRootBefore,
+		Txs:          []*block.Tx{block.EmptyTx(), oTx, block.EmptyTx()},
+	}
+	result, err := ExecuteBlock(oBlock, slotTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(result.NewStateRoot, oTx.StateRootAfter) {
+		t.Fatal("invalid new state root")
+	}
+	if result.OnChainOpsCount != 1 || result.PubDataChunks != std.PubDataChunksPerTxType[std.TxTypeDeposit] {
+		t.Fatal("invalid pubdata")
+	}
+	onChainOps, err := block.CollectOnChainOps(oBlock.Txs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.OnChainOps) != 1 || len(onChainOps) != 1 || result.OnChainOps[0] != onChainOps[0] {
+		t.Fatal("invalid on-chain ops")
+	}
+	oBlock.NewStateRoot = oTx.StateRootAfter
+	commitment, err := block.ComputeBlockCommitment(oBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(result.BlockCommitment, commitment) {
+		t.Fatal("invalid block commitment")
+	}
+	priorityOpsHashEnd, err := block.ComputePriorityOpsHash(oBlock.PriorityOpsHashStart, []*block.Tx{oTx})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(result.PriorityOpsHashEnd, priorityOpsHashEnd) {
+		t.Fatal("invalid priority ops hash")
+	}
+	// the txs are chained from the old state root
+	oBlock.OldStateRoot = oTx.StateRootAfter
+	if _, err = ExecuteBlock(oBlock, slotTypes); err == nil {
+		t.Fatal("invalid old state root accepted")
+	}
+}
@@ -0,0 +1,261 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package executor
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+type Variable = std.Variable
+
+/*
+	executor: evaluates the circuit logic on witness values, every value is
+	read as a field element like in the witness assignment and the first failed
+	check is kept, the remaining computation goes on with zero values
+*/
+type executor struct {
+	err error
+}
+
+func (e *executor) fail(format string, a ...interface{}) {
+	if e.err == nil {
+		e.err = fmt.Errorf(format, a...)
+	}
+}
+
+/*
+	fe: field element of a witness value
+*/
+func (e *executor) fe(v Variable) (res fr.Element) {
+	// SetInterface panics on invalid strings
+	if s, isString := v.(string); isString {
+		if _, isValid := new(big.Int).SetString(s, 0); !isValid {
+			e.fail("[executor] invalid witness value %q", s)
+			return res
+		}
+	}
+	if _, err := res.SetInterface(v); err != nil {
+		e.fail("[executor] invalid witness value: %v", err)
+	}
+	return res
+}
+
+func (e *executor) bigInt(v Variable) *big.Int {
+	x := e.fe(v)
+	return x.ToBigIntRegular(new(big.Int))
+}
+
+func (e *executor) int64Value(v Variable) int64 {
+	x := e.bigInt(v)
+	if !x.IsInt64() {
+		e.fail("[executor] value out of int64 range: %s", x.String())
+		return 0
+	}
+	return x.Int64()
+}
+
+func (e *executor) bytes(v Variable) []byte {
+	x := e.fe(v)
+	b := x.Bytes()
+	return b[:]
+}
+
+func (e *executor) isZero(v Variable) bool {
+	x := e.fe(v)
+	return x.IsZero()
+}
+
+func (e *executor) isEqual(i1, i2 Variable) bool {
+	x, y := e.fe(i1), e.fe(i2)
+	return x.Equal(&y)
+}
+
+func (e *executor) add(i1, i2 Variable) (res fr.Element) {
+	x, y := e.fe(i1), e.fe(i2)
+	return *res.Add(&x, &y)
+}
+
+func (e *executor) sub(i1, i2 Variable) (res fr.Element) {
+	x, y := e.fe(i1), e.fe(i2)
+	return *res.Sub(&x, &y)
+}
+
+func (e *executor) mul(i1, i2 Variable) (res fr.Element) {
+	x, y := e.fe(i1), e.fe(i2)
+	return *res.Mul(&x, &y)
+}
+
+func (e *executor) neg(i Variable) (res fr.Element) {
+	x := e.fe(i)
+	return *res.Neg(&x)
+}
+
+/*
+	div: field division like api.Div, not an integer division
+*/
+func (e *executor) div(i1, i2 Variable) (res fr.Element) {
+	x, y := e.fe(i1), e.fe(i2)
+	if y.IsZero() {
+		e.fail("[executor] division by zero")
+		return res
+	}
+	return *res.Div(&x, &y)
+}
+
+/*
+	isVariableEqual, isVariableLessOrEqual, isVariableLess: std.IsVariable* of an
+	enabled check, values are compared as integers in [0, r)
+*/
+func (e *executor) isVariableEqual(check string, i1, i2 Variable) {
+	x, y := e.fe(i1), e.fe(i2)
+	if !x.Equal(&y) {
+		e.fail("%s: %s != %s", check, x.String(), y.String())
+	}
+}
+
+func (e *executor) isVariableLessOrEqual(check string, i1, i2 Variable) {
+	x, y := e.fe(i1), e.fe(i2)
+	if x.Cmp(&y) > 0 {
+		e.fail("%s: %s > %s", check, x.String(), y.String())
+	}
+}
+
+func (e *executor) isVariableLess(check string, i1, i2 Variable) {
+	x, y := e.fe(i1), e.fe(i2)
+	if x.Cmp(&y) >= 0 {
+		e.fail("%s: %s >= %s", check, x.String(), y.String())
+	}
+}
+
+/*
+	toBinary: api.ToBinary(v, nbBits), fails if the value doesn't fit
+*/
+func (e *executor) toBinary(check string, v Variable, nbBits int) (bits []uint) {
+	x := e.bigInt(v)
+	if x.BitLen() > nbBits {
+		e.fail("%s: %s doesn't fit in %d bits", check, x.String(), nbBits)
+	}
+	bits = make([]uint, nbBits)
+	for i := 0; i < nbBits; i++ {
+		bits[i] = x.Bit(i)
+	}
+	return bits
+}
+
+func (e *executor) unpackAmount(packedAmount Variable) fr.Element {
+	return e.unpack("[UnpackAmount] invalid packed amount", packedAmount, std.PackedAmountBitsSize)
+}
+
+func (e *executor) unpackFee(packedFee Variable) fr.Element {
+	return e.unpack("[UnpackFee] invalid packed fee", packedFee, std.PackedFeeBitsSize)
+}
+
+/*
+	unpack: mantissa * 10^exponent, the exponent is held by the 5 low bits
+*/
+func (e *executor) unpack(check string, packed Variable, bitsSize int) (res fr.Element) {
+	e.toBinary(check, packed, bitsSize)
+	x := e.bigInt(packed)
+	exponent := new(big.Int).And(x, big.NewInt(1<<std.PackedExponentBitsSize-1))
+	mantissa := new(big.Int).Rsh(x, std.PackedExponentBitsSize)
+	mantissa.Mul(mantissa, new(big.Int).Exp(big.NewInt(10), exponent, nil))
+	res.SetBigInt(mantissa)
+	return res
+}
+
+/*
+	hash: MiMC of the inputs, one field element per block like the circuit hash
+*/
+func (e *executor) hash(inputs ...Variable) (res fr.Element) {
+	hFunc := mimc.NewMiMC()
+	for _, input := range inputs {
+		hFunc.Write(e.bytes(input))
+	}
+	res.SetBytes(hFunc.Sum(nil))
+	return res
+}
+
+/*
+	updateMerkleProof: root of the tree with the node at the index of the helper bits,
+	the node is the right child where the helper bit is 1
+*/
+func (e *executor) updateMerkleProof(node fr.Element, proofSet []Variable, helper []uint) (root fr.Element) {
+	for i := 0; i < len(proofSet); i++ {
+		if helper[i] == 1 {
+			node = e.hash(proofSet[i], node)
+		} else {
+			node = e.hash(node, proofSet[i])
+		}
+	}
+	return node
+}
+
+func (e *executor) verifyMerkleProof(check string, merkleRoot Variable, node fr.Element, proofSet []Variable, helper []uint) {
+	e.isVariableEqual(check, merkleRoot, e.updateMerkleProof(node, proofSet, helper))
+}
+
+/*
+	verifyEddsaSig: std.VerifyEddsaSig, H(R, A, msg) is computed by the native verifier
+*/
+func (e *executor) verifyEddsaSig(check string, hashVal fr.Element, pk std.PublicKeyConstraints, sig block.SignatureConstraints) {
+	var (
+		pubKey    eddsa.PublicKey
+		signature eddsa.Signature
+	)
+	pubKey.A.X = e.fe(pk.A.X)
+	pubKey.A.Y = e.fe(pk.A.Y)
+	signature.R.X = e.fe(sig.R.X)
+	signature.R.Y = e.fe(sig.R.Y)
+	s := e.fe(sig.S)
+	signature.S = s.Bytes()
+	msg := hashVal.Bytes()
+	isValid, err := pubKey.Verify(signature.Bytes(), msg[:], mimc.NewMiMC())
+	if err != nil || !isValid {
+		e.fail("%s", check)
+	}
+}
+
+/*
+	verifyMultiSig: std.VerifyMultiSig, the first SignerThreshold signatures are
+	checked
+*/
+func (e *executor) verifyMultiSig(hashVal fr.Element, account std.AccountConstraints, multiSigs [std.NbMultiSigsPerTx]std.MultiSigConstraints) {
+	threshold := e.int64Value(account.SignerThreshold)
+	if threshold > std.NbMultiSigsPerTx {
+		e.fail("[VerifyMultiSig] invalid signer threshold")
+		return
+	}
+	for i := 0; i < int(threshold); i++ {
+		if i > 0 {
+			e.isVariableLess("[VerifyMultiSig] invalid signer order", multiSigs[i-1].SignerIndex, multiSigs[i].SignerIndex)
+		}
+		signerIndexMerkleHelper := e.toBinary("[VerifyMultiSig] invalid signer index", multiSigs[i].SignerIndex, std.SignerMerkleLevels)
+		signerNodeHash := e.hash(std.CollectHashInputsFromSigner(multiSigs[i].PubKey)...)
+		e.verifyMerkleProof("[VerifyMultiSig] invalid signer merkle proof",
+			account.SignerRoot, signerNodeHash, multiSigs[i].MerkleProof[:], signerIndexMerkleHelper)
+		e.verifyEddsaSig("[VerifyMultiSig] invalid signature", hashVal, multiSigs[i].PubKey, multiSigs[i].Sig)
+	}
+}
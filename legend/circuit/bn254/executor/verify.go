@@ -0,0 +1,714 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package executor
+
+import (
+	"math/big"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+/*
+	the verify* methods are the native counterparts of std.Verify*Tx for an
+	enabled flag, the packed amounts of the tx are replaced by the unpacked
+	ones like in the circuit so that the deltas read the unpacked values
+*/
+
+type accounts = [block.NbAccountsPerTx]std.AccountConstraints
+
+func (e *executor) checkEmptyAccountNode(check string, account std.AccountConstraints) {
+	e.isVariableEqual(check, account.AccountNameHash, std.ZeroInt)
+	e.isVariableEqual(check, account.AccountPk.A.X, std.ZeroInt)
+	e.isVariableEqual(check, account.AccountPk.A.Y, std.ZeroInt)
+	e.isVariableEqual(check, account.Nonce, std.ZeroInt)
+	e.isVariableEqual(check, account.CollectionNonce, std.ZeroInt)
+	e.isVariableEqual(check, account.AssetRoot, std.EmptyAssetRoot)
+	e.isVariableEqual(check, account.SignerRoot, std.ZeroInt)
+	e.isVariableEqual(check, account.SignerThreshold, std.ZeroInt)
+}
+
+func (e *executor) checkEmptyLiquidityNode(check string, liquidity std.LiquidityConstraints) {
+	e.isVariableEqual(check, liquidity.AssetAId, std.ZeroInt)
+	e.isVariableEqual(check, liquidity.AssetA, std.ZeroInt)
+	e.isVariableEqual(check, liquidity.AssetBId, std.ZeroInt)
+	e.isVariableEqual(check, liquidity.AssetB, std.ZeroInt)
+	e.isVariableEqual(check, liquidity.LpAmount, std.ZeroInt)
+	e.isVariableEqual(check, liquidity.KLast, std.ZeroInt)
+	e.isVariableEqual(check, liquidity.FeeRate, std.ZeroInt)
+	e.isVariableEqual(check, liquidity.TreasuryAccountIndex, std.ZeroInt)
+	e.isVariableEqual(check, liquidity.TreasuryRate, std.ZeroInt)
+	e.isVariableEqual(check, liquidity.PairType, std.ZeroInt)
+	e.isVariableEqual(check, liquidity.Amplification, std.ZeroInt)
+	e.isVariableEqual(check, liquidity.PriceACumulativeLast, std.ZeroInt)
+	e.isVariableEqual(check, liquidity.PriceBCumulativeLast, std.ZeroInt)
+	e.isVariableEqual(check, liquidity.BlockTimestampLast, std.ZeroInt)
+}
+
+func (e *executor) checkEmptyNftNode(check string, nft std.NftConstraints) {
+	e.isVariableEqual(check, nft.NftContentHash, std.ZeroInt)
+	e.isVariableEqual(check, nft.CreatorAccountIndex, std.ZeroInt)
+	e.isVariableEqual(check, nft.OwnerAccountIndex, std.ZeroInt)
+	e.isVariableEqual(check, nft.NftL1Address, std.ZeroInt)
+	e.isVariableEqual(check, nft.NftL1TokenId, std.ZeroInt)
+	e.isVariableEqual(check, nft.CreatorTreasuryRate, std.ZeroInt)
+	e.isVariableEqual(check, nft.CollectionId, std.ZeroInt)
+}
+
+func (e *executor) verifyRegisterZnsTx(tx std.RegisterZnsTxConstraints, accountsBefore accounts) {
+	e.checkEmptyAccountNode("[VerifyRegisterZNSTx] account is not empty", accountsBefore[0])
+}
+
+func (e *executor) verifyCreatePairTx(tx std.CreatePairTxConstraints, liquidityBefore std.LiquidityConstraints) {
+	e.isVariableEqual("[VerifyCreatePairTx] invalid pair index", tx.PairIndex, liquidityBefore.PairIndex)
+	e.checkEmptyLiquidityNode("[VerifyCreatePairTx] pair is not empty", liquidityBefore)
+	switch {
+	case e.isEqual(tx.PairType, std.PairTypeConstantProduct):
+		e.isVariableEqual("[VerifyCreatePairTx] invalid amplification", tx.Amplification, std.ZeroInt)
+	case e.isEqual(tx.PairType, std.PairTypeStableSwap):
+		e.isVariableLessOrEqual("[VerifyCreatePairTx] invalid amplification", 1, tx.Amplification)
+	default:
+		e.fail("[VerifyCreatePairTx] invalid pair type")
+	}
+}
+
+func (e *executor) verifyUpdatePairRateTx(tx std.UpdatePairRateTxConstraints, liquidityBefore std.LiquidityConstraints) {
+	e.isVariableEqual("[VerifyUpdatePairRateTx] invalid pair index", tx.PairIndex, liquidityBefore.PairIndex)
+	e.isVariableLessOrEqual("[VerifyUpdatePairRateTx] treasury rate above fee rate", tx.TreasuryRate, tx.FeeRate)
+}
+
+func (e *executor) verifyDepositTx(tx std.DepositTxConstraints, accountsBefore accounts) {
+	e.isVariableEqual("[VerifyDepositTx] invalid account name hash", tx.AccountNameHash, accountsBefore[0].AccountNameHash)
+	e.isVariableEqual("[VerifyDepositTx] invalid account index", tx.AccountIndex, accountsBefore[0].AccountIndex)
+	e.isVariableEqual("[VerifyDepositTx] invalid asset id", tx.AssetId, accountsBefore[0].AssetsInfo[0].AssetId)
+}
+
+func (e *executor) verifyDepositNftTx(tx std.DepositNftTxConstraints, accountsBefore accounts, nftBefore std.NftConstraints) {
+	e.checkEmptyNftNode("[VerifyDepositNftTx] nft is not empty", nftBefore)
+	e.isVariableEqual("[VerifyDepositNftTx] invalid account index", tx.AccountIndex, accountsBefore[0].AccountIndex)
+	e.isVariableEqual("[VerifyDepositNftTx] invalid account name hash", tx.AccountNameHash, accountsBefore[0].AccountNameHash)
+}
+
+func (e *executor) verifyTransferTx(tx *std.TransferTxConstraints, accountsBefore accounts) {
+	e.isVariableEqual("[VerifyTransferTx] invalid from account index", tx.FromAccountIndex, accountsBefore[0].AccountIndex)
+	e.isVariableEqual("[VerifyTransferTx] invalid to account index", tx.ToAccountIndex, accountsBefore[1].AccountIndex)
+	e.isVariableEqual("[VerifyTransferTx] invalid gas account index", tx.GasAccountIndex, accountsBefore[2].AccountIndex)
+	e.isVariableEqual("[VerifyTransferTx] invalid to account name hash", tx.ToAccountNameHash, accountsBefore[1].AccountNameHash)
+	e.isVariableEqual("[VerifyTransferTx] invalid asset id", tx.AssetId, accountsBefore[0].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyTransferTx] invalid asset id", tx.AssetId, accountsBefore[1].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyTransferTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[0].AssetsInfo[1].AssetId)
+	e.isVariableEqual("[VerifyTransferTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[2].AssetsInfo[0].AssetId)
+	tx.AssetAmount = e.unpackAmount(tx.AssetAmount)
+	tx.GasFeeAssetAmount = e.unpackFee(tx.GasFeeAssetAmount)
+	e.isVariableLessOrEqual("[VerifyTransferTx] not enough balance", tx.AssetAmount, accountsBefore[0].AssetsInfo[0].Balance)
+	e.isVariableLessOrEqual("[VerifyTransferTx] not enough gas fee balance", tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[1].Balance)
+}
+
+func (e *executor) verifySwapTx(tx *std.SwapTxConstraints, accountsBefore accounts, liquidityBefore std.LiquidityConstraints) {
+	e.isVariableEqual("[VerifySwapTx] invalid from account index", tx.FromAccountIndex, accountsBefore[0].AccountIndex)
+	e.isVariableEqual("[VerifySwapTx] invalid gas account index", tx.GasAccountIndex, accountsBefore[1].AccountIndex)
+	e.isVariableEqual("[VerifySwapTx] invalid pair index", tx.PairIndex, liquidityBefore.PairIndex)
+	e.isVariableEqual("[VerifySwapTx] invalid asset a id", tx.AssetAId, accountsBefore[0].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifySwapTx] invalid asset b id", tx.AssetBId, accountsBefore[0].AssetsInfo[1].AssetId)
+	// same flags as the circuit, both are 1 unless the ids match the pair
+	isSameAsset := !(e.isEqual(tx.AssetAId, liquidityBefore.AssetAId) && e.isEqual(tx.AssetBId, liquidityBefore.AssetBId))
+	isDifferentAsset := !(e.isEqual(tx.AssetAId, liquidityBefore.AssetBId) && e.isEqual(tx.AssetBId, liquidityBefore.AssetAId))
+	if !isSameAsset && !isDifferentAsset {
+		e.fail("[VerifySwapTx] invalid pair assets")
+	}
+	e.isVariableEqual("[VerifySwapTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[0].AssetsInfo[2].AssetId)
+	e.isVariableEqual("[VerifySwapTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[1].AssetsInfo[0].AssetId)
+	isExactOutput := e.isEqual(tx.SwapMode, std.SwapModeExactOutput)
+	if !isExactOutput && !e.isEqual(tx.SwapMode, std.SwapModeExactInput) {
+		e.fail("[VerifySwapTx] invalid swap mode")
+	}
+	tx.AssetAAmount = e.unpackAmount(tx.AssetAAmount)
+	tx.AssetBMinAmount = e.unpackAmount(tx.AssetBMinAmount)
+	tx.AssetBAmountDelta = e.unpackAmount(tx.AssetBAmountDelta)
+	tx.AssetAMaxAmount = e.unpackAmount(tx.AssetAMaxAmount)
+	tx.GasFeeAssetAmount = e.unpackFee(tx.GasFeeAssetAmount)
+	if isExactOutput {
+		e.isVariableLessOrEqual("[VerifySwapTx] asset a amount above max amount", tx.AssetAAmount, tx.AssetAMaxAmount)
+	} else {
+		e.isVariableLessOrEqual("[VerifySwapTx] asset b amount below min amount", tx.AssetBMinAmount, tx.AssetBAmountDelta)
+	}
+	e.isVariableLessOrEqual("[VerifySwapTx] not enough asset a balance", tx.AssetAAmount, accountsBefore[0].AssetsInfo[0].Balance)
+	e.isVariableLessOrEqual("[VerifySwapTx] not enough gas fee balance", tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[2].Balance)
+	// the circuit sets isDifferentAsset to isSameAsset
+	isDifferentAsset = isSameAsset
+	e.isVariableLessOrEqual("[VerifySwapTx] invalid fee rate", liquidityBefore.FeeRate, std.RateBase)
+	var assetAAmount, assetBAmount Variable = tx.AssetBAmountDelta, tx.AssetAAmount
+	if isSameAsset {
+		assetAAmount, assetBAmount = tx.AssetAAmount, tx.AssetBAmountDelta
+	}
+	if isDifferentAsset {
+		e.isVariableLessOrEqual("[VerifySwapTx] not enough asset a in the pool", liquidityBefore.AssetA, assetAAmount)
+	}
+	if isSameAsset {
+		e.isVariableLessOrEqual("[VerifySwapTx] not enough asset b in the pool", liquidityBefore.AssetB, assetBAmount)
+	}
+	var assetAAmountAfterAdjusted, assetBAmountAfterAdjusted Variable
+	if isSameAsset {
+		assetAAmountAfter := e.mul(std.RateBase, e.add(liquidityBefore.AssetA, assetAAmount))
+		assetAAmountAfterAdjusted = e.sub(assetAAmountAfter, e.mul(liquidityBefore.FeeRate, assetAAmount))
+		assetBAmountAfterAdjusted = e.mul(std.RateBase, e.sub(liquidityBefore.AssetB, assetBAmount))
+	} else {
+		assetAAmountAfterAdjusted = e.mul(std.RateBase, e.sub(liquidityBefore.AssetA, assetAAmount))
+		assetBAmountAfter := e.mul(std.RateBase, e.add(liquidityBefore.AssetB, assetBAmount))
+		assetBAmountAfterAdjusted = e.sub(assetBAmountAfter, e.mul(liquidityBefore.FeeRate, assetBAmount))
+	}
+	if e.isEqual(liquidityBefore.PairType, std.PairTypeStableSwap) {
+		reserveIn, reserveOut := liquidityBefore.AssetB, liquidityBefore.AssetA
+		if isSameAsset {
+			reserveIn, reserveOut = liquidityBefore.AssetA, liquidityBefore.AssetB
+		}
+		e.verifyStableSwap("[VerifySwapTx] invalid stable swap", liquidityBefore,
+			reserveIn, reserveOut, tx.AssetAAmount, tx.AssetBAmountDelta)
+		return
+	}
+	r := e.mul(e.mul(liquidityBefore.AssetA, liquidityBefore.AssetB), std.RateBase*std.RateBase)
+	l := e.mul(assetAAmountAfterAdjusted, assetBAmountAfterAdjusted)
+	e.isVariableLessOrEqual("[VerifySwapTx] invalid amm product", r, l)
+}
+
+/*
+	verifyStableSwap: std.VerifyStableSwap with the amount in after the fee and the
+	invariant of std.ComputeStableSwap, which are the ones the circuit accepts the
+	most swaps with
+*/
+func (e *executor) verifyStableSwap(check string, liquidity std.LiquidityConstraints, reserveIn, reserveOut, amountIn, amountOut Variable) {
+	// the fee rate is checked by the swap
+	amountInAfterFee := new(big.Int).Quo(
+		e.bigInt(e.mul(amountIn, e.sub(std.RateBase, liquidity.FeeRate))), big.NewInt(std.RateBase),
+	)
+	reserveInAfter := e.add(reserveIn, amountInAfterFee)
+	reserveOutAfter := e.sub(reserveOut, amountOut)
+	e.toBinary(check, reserveIn, std.StableSwapReserveBitsSize)
+	e.toBinary(check, reserveOut, std.StableSwapReserveBitsSize)
+	e.toBinary(check, reserveInAfter, std.StableSwapReserveBitsSize)
+	e.toBinary(check, reserveOutAfter, std.StableSwapReserveBitsSize)
+	if e.err != nil {
+		return
+	}
+	amplification := e.bigInt(liquidity.Amplification)
+	invariant := std.ComputeStableSwapInvariant(amplification, e.bigInt(reserveIn), e.bigInt(reserveOut))
+	l, r := std.StableSwapInvariantSides(amplification, e.bigInt(reserveInAfter), e.bigInt(reserveOutAfter), invariant)
+	e.isVariableLessOrEqual(check, r, l)
+}
+
+type routeLiquidities = [block.NbRoutePairsPerTx]std.LiquidityConstraints
+
+/*
+	routeSwapHop: native counterpart of std.RouteSwapHopConstraints
+*/
+type routeSwapHop struct {
+	isUsed     bool
+	assetInId  Variable
+	isAssetAIn bool
+	amountIn   Variable
+	amountOut  Variable
+}
+
+/*
+	routeSwapPairFlags: same flags as std.GetRouteSwapPairFlags
+*/
+func (e *executor) routeSwapPairFlags(txType int, pairsCount Variable) (isUsed [block.NbRoutePairsPerTx]bool) {
+	for i := 0; i < block.NbRoutePairsPerTx; i++ {
+		isUsed[i] = txType == std.TxTypeRouteSwap
+		if i >= std.MinRoutePairsCount {
+			isUsed[i] = isUsed[i-1] && !e.isEqual(pairsCount, i)
+		}
+	}
+	return isUsed
+}
+
+func (e *executor) getRouteSwapHops(tx std.RouteSwapTxConstraints, liquiditiesBefore routeLiquidities) (hops [block.NbRoutePairsPerTx]routeSwapHop) {
+	isUsed := e.routeSwapPairFlags(std.TxTypeRouteSwap, tx.PairsCount)
+	assetInId := tx.AssetAId
+	for i := 0; i < block.NbRoutePairsPerTx; i++ {
+		hops[i].isUsed = isUsed[i]
+		hops[i].assetInId = assetInId
+		hops[i].isAssetAIn = e.isEqual(assetInId, liquiditiesBefore[i].AssetAId)
+		hops[i].amountIn = tx.AssetAAmount
+		if i > 0 {
+			hops[i].amountIn = tx.HopAmounts[i-1]
+		}
+		hops[i].amountOut = tx.AssetBAmountDelta
+		if i < block.NbRoutePairsPerTx-1 && isUsed[i+1] {
+			hops[i].amountOut = tx.HopAmounts[i]
+		}
+		assetInId = liquiditiesBefore[i].AssetAId
+		if hops[i].isAssetAIn {
+			assetInId = liquiditiesBefore[i].AssetBId
+		}
+	}
+	return hops
+}
+
+func (e *executor) verifyRouteSwapTx(tx *std.RouteSwapTxConstraints, accountsBefore accounts, liquiditiesBefore routeLiquidities) {
+	e.isVariableEqual("[VerifyRouteSwapTx] invalid from account index", tx.FromAccountIndex, accountsBefore[0].AccountIndex)
+	e.isVariableEqual("[VerifyRouteSwapTx] invalid gas account index", tx.GasAccountIndex, accountsBefore[1].AccountIndex)
+	e.isVariableEqual("[VerifyRouteSwapTx] invalid asset a id", tx.AssetAId, accountsBefore[0].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyRouteSwapTx] invalid asset b id", tx.AssetBId, accountsBefore[0].AssetsInfo[1].AssetId)
+	e.isVariableEqual("[VerifyRouteSwapTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[0].AssetsInfo[2].AssetId)
+	e.isVariableEqual("[VerifyRouteSwapTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[1].AssetsInfo[0].AssetId)
+	e.isVariableLessOrEqual("[VerifyRouteSwapTx] invalid pairs count", std.MinRoutePairsCount, tx.PairsCount)
+	e.isVariableLessOrEqual("[VerifyRouteSwapTx] invalid pairs count", tx.PairsCount, block.NbRoutePairsPerTx)
+	tx.AssetAAmount = e.unpackAmount(tx.AssetAAmount)
+	tx.AssetBMinAmount = e.unpackAmount(tx.AssetBMinAmount)
+	tx.AssetBAmountDelta = e.unpackAmount(tx.AssetBAmountDelta)
+	for i := 0; i < block.NbRoutePairsPerTx-1; i++ {
+		tx.HopAmounts[i] = e.unpackAmount(tx.HopAmounts[i])
+	}
+	tx.GasFeeAssetAmount = e.unpackFee(tx.GasFeeAssetAmount)
+	e.isVariableLessOrEqual("[VerifyRouteSwapTx] asset b amount below min amount", tx.AssetBMinAmount, tx.AssetBAmountDelta)
+	e.isVariableLessOrEqual("[VerifyRouteSwapTx] not enough asset a balance", tx.AssetAAmount, accountsBefore[0].AssetsInfo[0].Balance)
+	e.isVariableLessOrEqual("[VerifyRouteSwapTx] not enough gas fee balance", tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[2].Balance)
+	hops := e.getRouteSwapHops(*tx, liquiditiesBefore)
+	var assetBId Variable = std.ZeroInt
+	for i, hop := range hops {
+		liquidity := liquiditiesBefore[i]
+		if !hop.isUsed {
+			e.isVariableEqual("[VerifyRouteSwapTx] invalid unused pair index", tx.PairIndexes[i], 0)
+			if i > 0 {
+				e.isVariableEqual("[VerifyRouteSwapTx] invalid unused hop amount", tx.HopAmounts[i-1], 0)
+			}
+			continue
+		}
+		e.isVariableEqual("[VerifyRouteSwapTx] invalid pair index", tx.PairIndexes[i], liquidity.PairIndex)
+		if !hop.isAssetAIn && !e.isEqual(hop.assetInId, liquidity.AssetBId) {
+			e.fail("[VerifyRouteSwapTx] invalid pair assets")
+		}
+		reserveIn, reserveOut := liquidity.AssetB, liquidity.AssetA
+		assetBId = liquidity.AssetAId
+		if hop.isAssetAIn {
+			reserveIn, reserveOut = liquidity.AssetA, liquidity.AssetB
+			assetBId = liquidity.AssetBId
+		}
+		e.isVariableLessOrEqual("[VerifyRouteSwapTx] invalid fee rate", liquidity.FeeRate, std.RateBase)
+		e.isVariableLessOrEqual("[VerifyRouteSwapTx] not enough asset out in the pool", hop.amountOut, reserveOut)
+		l := e.mul(
+			e.sub(e.mul(std.RateBase, e.add(reserveIn, hop.amountIn)), e.mul(liquidity.FeeRate, hop.amountIn)),
+			e.sub(reserveOut, hop.amountOut),
+		)
+		r := e.mul(e.mul(std.RateBase, reserveIn), reserveOut)
+		if e.isEqual(liquidity.PairType, std.PairTypeStableSwap) {
+			e.verifyStableSwap("[VerifyRouteSwapTx] invalid stable swap", liquidity, reserveIn, reserveOut, hop.amountIn, hop.amountOut)
+			continue
+		}
+		e.isVariableLessOrEqual("[VerifyRouteSwapTx] invalid amm product", r, l)
+	}
+	e.isVariableEqual("[VerifyRouteSwapTx] invalid asset b id", tx.AssetBId, assetBId)
+}
+
+/*
+	verifyTreasuryLpAmount: std.VerifyTreasuryLpAmount, the bounds of the circuit
+	are checked before std.ComputeSLpAmount computes the same amount
+*/
+func (e *executor) verifyTreasuryLpAmount(check string, liquidity std.LiquidityConstraints) (sLp Variable) {
+	e.toBinary(check, liquidity.AssetA, std.PoolReserveBitsSize)
+	e.toBinary(check, liquidity.AssetB, std.PoolReserveBitsSize)
+	kLast := e.bigInt(liquidity.KLast)
+	e.toBinary(check, new(big.Int).Sqrt(kLast), std.SqrtKBitsSize)
+	if e.err != nil {
+		return std.ZeroInt
+	}
+	sLpAmount, err := std.ComputeSLpAmount(
+		e.bigInt(liquidity.AssetA), e.bigInt(liquidity.AssetB), kLast,
+		e.bigInt(liquidity.FeeRate), e.bigInt(liquidity.TreasuryRate),
+	)
+	if err != nil {
+		e.fail("%s: %v", check, err)
+		return std.ZeroInt
+	}
+	return sLpAmount
+}
+
+/*
+	max, min: std.Max and std.Min
+*/
+func (e *executor) max(i1, i2 Variable) Variable {
+	x, y := e.fe(i1), e.fe(i2)
+	if x.Cmp(&y) == 1 {
+		return x
+	}
+	return y
+}
+
+func (e *executor) min(i1, i2 Variable) Variable {
+	x, y := e.fe(i1), e.fe(i2)
+	if x.Cmp(&y) == -1 {
+		return x
+	}
+	return y
+}
+
+func (e *executor) verifyAddLiquidityTx(tx *std.AddLiquidityTxConstraints, accountsBefore accounts, liquidityBefore std.LiquidityConstraints) {
+	e.isVariableEqual("[VerifyAddLiquidityTx] invalid from account index", tx.FromAccountIndex, accountsBefore[0].AccountIndex)
+	e.isVariableEqual("[VerifyAddLiquidityTx] invalid treasury account index", liquidityBefore.TreasuryAccountIndex, accountsBefore[1].AccountIndex)
+	e.isVariableEqual("[VerifyAddLiquidityTx] invalid gas account index", tx.GasAccountIndex, accountsBefore[2].AccountIndex)
+	e.isVariableEqual("[VerifyAddLiquidityTx] invalid asset a id", tx.AssetAId, accountsBefore[0].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyAddLiquidityTx] invalid asset b id", tx.AssetBId, accountsBefore[0].AssetsInfo[1].AssetId)
+	e.isVariableEqual("[VerifyAddLiquidityTx] invalid pair asset a id", tx.AssetAId, liquidityBefore.AssetAId)
+	e.isVariableEqual("[VerifyAddLiquidityTx] invalid pair asset b id", tx.AssetBId, liquidityBefore.AssetBId)
+	e.isVariableEqual("[VerifyAddLiquidityTx] invalid pair index", tx.PairIndex, accountsBefore[1].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyAddLiquidityTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[0].AssetsInfo[2].AssetId)
+	e.isVariableEqual("[VerifyAddLiquidityTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[2].AssetsInfo[0].AssetId)
+	tx.AssetAAmount = e.unpackAmount(tx.AssetAAmount)
+	tx.AssetBAmount = e.unpackAmount(tx.AssetBAmount)
+	tx.LpAmount = e.unpackAmount(tx.LpAmount)
+	tx.TreasuryAmount = e.unpackAmount(tx.TreasuryAmount)
+	tx.GasFeeAssetAmount = e.unpackFee(tx.GasFeeAssetAmount)
+	e.isVariableLessOrEqual("[VerifyAddLiquidityTx] not enough asset a balance", tx.AssetAAmount, accountsBefore[0].AssetsInfo[0].Balance)
+	e.isVariableLessOrEqual("[VerifyAddLiquidityTx] not enough asset b balance", tx.AssetBAmount, accountsBefore[0].AssetsInfo[1].Balance)
+	e.isVariableLessOrEqual("[VerifyAddLiquidityTx] not enough gas fee balance", tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[2].Balance)
+	e.isVariableLessOrEqual("[VerifyAddLiquidityTx] treasury rate above fee rate", liquidityBefore.TreasuryRate, liquidityBefore.FeeRate)
+	// k of a StableSwap pair drops on swaps, its treasury doesn't get lp
+	var sLp Variable = std.ZeroInt
+	if !e.isEqual(liquidityBefore.PairType, std.PairTypeStableSwap) {
+		kCurrent := e.mul(liquidityBefore.AssetA, liquidityBefore.AssetB)
+		e.isVariableLessOrEqual("[VerifyAddLiquidityTx] invalid k last", liquidityBefore.KLast, kCurrent)
+		sLp = e.verifyTreasuryLpAmount("[VerifyAddLiquidityTx] invalid treasury lp amount", liquidityBefore)
+	}
+	e.isVariableEqual("[VerifyAddLiquidityTx] invalid treasury amount", tx.TreasuryAmount, sLp)
+	// ratio
+	var l, r Variable = e.mul(liquidityBefore.AssetA, tx.AssetBAmount), e.mul(liquidityBefore.AssetB, tx.AssetAAmount)
+	maxDelta := e.max(liquidityBefore.AssetA, liquidityBefore.AssetB)
+	l = e.max(l, r)
+	r = e.min(l, r)
+	e.isVariableLessOrEqual("[VerifyAddLiquidityTx] invalid ratio", e.sub(l, r), maxDelta)
+	// lp amount
+	if e.isZero(liquidityBefore.AssetA) {
+		e.isVariableLessOrEqual("[VerifyAddLiquidityTx] invalid lp amount",
+			e.mul(tx.LpAmount, tx.LpAmount), e.mul(tx.AssetAAmount, tx.AssetBAmount))
+	} else {
+		poolLp := e.add(liquidityBefore.LpAmount, sLp)
+		e.isVariableEqual("[VerifyAddLiquidityTx] invalid lp amount",
+			e.mul(tx.LpAmount, liquidityBefore.AssetA), e.mul(tx.AssetAAmount, poolLp))
+	}
+	if e.isEqual(liquidityBefore.PairType, std.PairTypeStableSwap) {
+		e.toBinary("[VerifyAddLiquidityTx] stable pair reserve above the cap", e.add(liquidityBefore.AssetA, tx.AssetAAmount), std.StableSwapReserveBitsSize)
+		e.toBinary("[VerifyAddLiquidityTx] stable pair reserve above the cap", e.add(liquidityBefore.AssetB, tx.AssetBAmount), std.StableSwapReserveBitsSize)
+	}
+}
+
+func (e *executor) verifyRemoveLiquidityTx(tx *std.RemoveLiquidityTxConstraints, accountsBefore accounts, liquidityBefore std.LiquidityConstraints) {
+	e.isVariableEqual("[VerifyRemoveLiquidityTx] invalid from account index", tx.FromAccountIndex, accountsBefore[0].AccountIndex)
+	e.isVariableEqual("[VerifyRemoveLiquidityTx] invalid treasury account index", liquidityBefore.TreasuryAccountIndex, accountsBefore[1].AccountIndex)
+	e.isVariableEqual("[VerifyRemoveLiquidityTx] invalid gas account index", tx.GasAccountIndex, accountsBefore[2].AccountIndex)
+	e.isVariableEqual("[VerifyRemoveLiquidityTx] invalid asset a id", tx.AssetAId, accountsBefore[0].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyRemoveLiquidityTx] invalid asset b id", tx.AssetBId, accountsBefore[0].AssetsInfo[1].AssetId)
+	e.isVariableEqual("[VerifyRemoveLiquidityTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[0].AssetsInfo[2].AssetId)
+	e.isVariableEqual("[VerifyRemoveLiquidityTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[2].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyRemoveLiquidityTx] invalid pair asset a id", tx.AssetAId, liquidityBefore.AssetAId)
+	e.isVariableEqual("[VerifyRemoveLiquidityTx] invalid pair asset b id", tx.AssetBId, liquidityBefore.AssetBId)
+	// checked on the packed lp amount like the circuit
+	e.isVariableLessOrEqual("[VerifyRemoveLiquidityTx] not enough lp", tx.LpAmount, accountsBefore[0].AssetsInfo[3].LpAmount)
+	tx.AssetAMinAmount = e.unpackAmount(tx.AssetAMinAmount)
+	tx.AssetAAmountDelta = e.unpackAmount(tx.AssetAAmountDelta)
+	tx.AssetBMinAmount = e.unpackAmount(tx.AssetBMinAmount)
+	tx.AssetBAmountDelta = e.unpackAmount(tx.AssetBAmountDelta)
+	tx.LpAmount = e.unpackAmount(tx.LpAmount)
+	tx.TreasuryAmount = e.unpackAmount(tx.TreasuryAmount)
+	tx.GasFeeAssetAmount = e.unpackFee(tx.GasFeeAssetAmount)
+	e.isVariableLessOrEqual("[VerifyRemoveLiquidityTx] not enough gas fee balance", tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[2].Balance)
+	e.isVariableLessOrEqual("[VerifyRemoveLiquidityTx] treasury rate above fee rate", liquidityBefore.TreasuryRate, liquidityBefore.FeeRate)
+	var sLp Variable = std.ZeroInt
+	if !e.isEqual(liquidityBefore.PairType, std.PairTypeStableSwap) {
+		kCurrent := e.mul(liquidityBefore.AssetA, liquidityBefore.AssetB)
+		e.isVariableLessOrEqual("[VerifyRemoveLiquidityTx] invalid k last", liquidityBefore.KLast, kCurrent)
+		sLp = e.verifyTreasuryLpAmount("[VerifyRemoveLiquidityTx] invalid treasury lp amount", liquidityBefore)
+	}
+	e.isVariableEqual("[VerifyRemoveLiquidityTx] invalid treasury amount", tx.TreasuryAmount, sLp)
+	poolLp := e.add(liquidityBefore.LpAmount, sLp)
+	e.isVariableLessOrEqual("[VerifyRemoveLiquidityTx] invalid asset a amount delta",
+		e.mul(tx.AssetAAmountDelta, poolLp), e.mul(tx.LpAmount, liquidityBefore.AssetA))
+	e.isVariableLessOrEqual("[VerifyRemoveLiquidityTx] invalid asset b amount delta",
+		e.mul(tx.AssetBAmountDelta, poolLp), e.mul(tx.LpAmount, liquidityBefore.AssetB))
+	e.isVariableLessOrEqual("[VerifyRemoveLiquidityTx] asset a amount below min amount", tx.AssetAMinAmount, tx.AssetAAmountDelta)
+	e.isVariableLessOrEqual("[VerifyRemoveLiquidityTx] asset b amount below min amount", tx.AssetBMinAmount, tx.AssetBAmountDelta)
+}
+
+func (e *executor) verifyWithdrawTx(tx *std.WithdrawTxConstraints, accountsBefore accounts) {
+	e.isVariableEqual("[VerifyWithdrawTx] invalid from account index", tx.FromAccountIndex, accountsBefore[0].AccountIndex)
+	e.isVariableEqual("[VerifyWithdrawTx] invalid gas account index", tx.GasAccountIndex, accountsBefore[1].AccountIndex)
+	e.isVariableEqual("[VerifyWithdrawTx] invalid asset id", tx.AssetId, accountsBefore[0].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyWithdrawTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[0].AssetsInfo[1].AssetId)
+	e.isVariableEqual("[VerifyWithdrawTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[1].AssetsInfo[0].AssetId)
+	tx.GasFeeAssetAmount = e.unpackFee(tx.GasFeeAssetAmount)
+	e.isVariableLessOrEqual("[VerifyWithdrawTx] not enough balance", tx.AssetAmount, accountsBefore[0].AssetsInfo[0].Balance)
+	e.isVariableLessOrEqual("[VerifyWithdrawTx] not enough gas fee balance", tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[1].Balance)
+}
+
+func (e *executor) verifyCreateCollectionTx(tx *std.CreateCollectionTxConstraints, accountsBefore accounts) {
+	e.isVariableLessOrEqual("[VerifyCreateCollectionTx] invalid collection id", tx.CollectionId, 65535)
+	e.isVariableEqual("[VerifyCreateCollectionTx] invalid account index", tx.AccountIndex, accountsBefore[0].AccountIndex)
+	e.isVariableEqual("[VerifyCreateCollectionTx] invalid gas account index", tx.GasAccountIndex, accountsBefore[1].AccountIndex)
+	e.isVariableEqual("[VerifyCreateCollectionTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[0].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyCreateCollectionTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[1].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyCreateCollectionTx] invalid collection id", tx.CollectionId, accountsBefore[0].CollectionNonce)
+	// the circuit unpacks this fee as an amount
+	tx.GasFeeAssetAmount = e.unpackAmount(tx.GasFeeAssetAmount)
+	e.isVariableLessOrEqual("[VerifyCreateCollectionTx] not enough gas fee balance", tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[0].Balance)
+}
+
+func (e *executor) verifyMintNftTx(tx *std.MintNftTxConstraints, accountsBefore accounts, nftBefore std.NftConstraints) {
+	e.checkEmptyNftNode("[VerifyMintNftTx] nft is not empty", nftBefore)
+	e.isVariableEqual("[VerifyMintNftTx] invalid creator account index", tx.CreatorAccountIndex, accountsBefore[0].AccountIndex)
+	e.isVariableEqual("[VerifyMintNftTx] invalid to account index", tx.ToAccountIndex, accountsBefore[1].AccountIndex)
+	e.isVariableEqual("[VerifyMintNftTx] invalid gas account index", tx.GasAccountIndex, accountsBefore[2].AccountIndex)
+	e.isVariableEqual("[VerifyMintNftTx] invalid to account name hash", tx.ToAccountNameHash, accountsBefore[1].AccountNameHash)
+	if e.isZero(tx.NftContentHash) {
+		e.fail("[VerifyMintNftTx] empty nft content hash")
+	}
+	e.isVariableEqual("[VerifyMintNftTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[0].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyMintNftTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[2].AssetsInfo[0].AssetId)
+	tx.GasFeeAssetAmount = e.unpackFee(tx.GasFeeAssetAmount)
+	e.isVariableLessOrEqual("[VerifyMintNftTx] not enough gas fee balance", tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[0].Balance)
+	e.isVariableLess("[VerifyMintNftTx] invalid collection id", tx.CollectionId, accountsBefore[0].CollectionNonce)
+}
+
+func (e *executor) verifyTransferNftTx(tx *std.TransferNftTxConstraints, accountsBefore accounts, nftBefore std.NftConstraints) {
+	e.isVariableEqual("[VerifyTransferNftTx] invalid from account index", tx.FromAccountIndex, accountsBefore[0].AccountIndex)
+	e.isVariableEqual("[VerifyTransferNftTx] invalid to account index", tx.ToAccountIndex, accountsBefore[1].AccountIndex)
+	e.isVariableEqual("[VerifyTransferNftTx] invalid gas account index", tx.GasAccountIndex, accountsBefore[2].AccountIndex)
+	e.isVariableEqual("[VerifyTransferNftTx] invalid to account name hash", tx.ToAccountNameHash, accountsBefore[1].AccountNameHash)
+	e.isVariableEqual("[VerifyTransferNftTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[0].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyTransferNftTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[2].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyTransferNftTx] invalid nft index", tx.NftIndex, nftBefore.NftIndex)
+	e.isVariableEqual("[VerifyTransferNftTx] invalid nft owner", tx.FromAccountIndex, nftBefore.OwnerAccountIndex)
+	tx.GasFeeAssetAmount = e.unpackFee(tx.GasFeeAssetAmount)
+	e.isVariableLessOrEqual("[VerifyTransferNftTx] not enough gas fee balance", tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[0].Balance)
+}
+
+/*
+	offerIndex: index of the offer in the canceled or finalized bits of its asset
+*/
+func (e *executor) offerIndex(check string, offerId Variable) uint {
+	e.toBinary(check, offerId, std.OfferIdBitsSize)
+	return uint(new(big.Int).Mod(e.bigInt(offerId), big.NewInt(std.OfferSizePerAsset)).Uint64())
+}
+
+/*
+	splitOfferCanceledOrFinalized: std.SplitOfferCanceledOrFinalized
+*/
+func (e *executor) splitOfferCanceledOrFinalized(offerCanceledOrFinalized Variable) (offerBits []uint, orderFilledAmount *big.Int) {
+	x := e.bigInt(offerCanceledOrFinalized)
+	offerBits = make([]uint, std.OfferSizePerAsset)
+	for i := 0; i < std.OfferSizePerAsset; i++ {
+		offerBits[i] = x.Bit(i)
+	}
+	return offerBits, new(big.Int).Rsh(x, std.OfferSizePerAsset)
+}
+
+func (e *executor) verifyAtomicMatchTx(tx *std.AtomicMatchTxConstraints, accountsBefore accounts, nftBefore std.NftConstraints, blockCreatedAt int64) {
+	e.isVariableEqual("[VerifyAtomicMatchTx] invalid buy offer type", tx.BuyOffer.Type, 0)
+	e.isVariableEqual("[VerifyAtomicMatchTx] invalid sell offer type", tx.SellOffer.Type, 1)
+	e.isVariableEqual("[VerifyAtomicMatchTx] offer asset ids don't match", tx.BuyOffer.AssetId, tx.SellOffer.AssetId)
+	e.isVariableEqual("[VerifyAtomicMatchTx] offer asset amounts don't match", tx.BuyOffer.AssetAmount, tx.SellOffer.AssetAmount)
+	e.isVariableEqual("[VerifyAtomicMatchTx] offer nft indexes don't match", tx.BuyOffer.NftIndex, tx.SellOffer.NftIndex)
+	e.isVariableEqual("[VerifyAtomicMatchTx] invalid buyer asset id", tx.BuyOffer.AssetId, accountsBefore[1].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyAtomicMatchTx] invalid seller asset id", tx.SellOffer.AssetId, accountsBefore[2].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyAtomicMatchTx] invalid creator asset id", tx.SellOffer.AssetId, accountsBefore[3].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyAtomicMatchTx] invalid gas account index", tx.GasAccountIndex, accountsBefore[4].AccountIndex)
+	e.isVariableEqual("[VerifyAtomicMatchTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[0].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyAtomicMatchTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[4].AssetsInfo[1].AssetId)
+	e.isVariableLessOrEqual("[VerifyAtomicMatchTx] buy offer expired", blockCreatedAt, tx.BuyOffer.ExpiredAt)
+	e.isVariableLessOrEqual("[VerifyAtomicMatchTx] sell offer expired", blockCreatedAt, tx.SellOffer.ExpiredAt)
+	e.isVariableEqual("[VerifyAtomicMatchTx] invalid nft index", nftBefore.NftIndex, tx.SellOffer.NftIndex)
+	e.isVariableEqual("[VerifyAtomicMatchTx] offer treasury rates don't match", tx.BuyOffer.TreasuryRate, tx.SellOffer.TreasuryRate)
+	// the offers of the submitter are signed by the tx signature
+	if !e.isEqual(tx.AccountIndex, tx.BuyOffer.AccountIndex) {
+		e.isVariableEqual("[VerifyAtomicMatchTx] buy offer of a multi-signature account", accountsBefore[1].SignerThreshold, 0)
+		buyOfferHash := e.hash(std.CollectHashInputsFromOfferTx(tx.BuyOffer)...)
+		e.verifyEddsaSig("[VerifyAtomicMatchTx] invalid buy offer signature", buyOfferHash, accountsBefore[1].AccountPk, tx.BuyOffer.Sig)
+	}
+	if !e.isEqual(tx.AccountIndex, tx.SellOffer.AccountIndex) {
+		e.isVariableEqual("[VerifyAtomicMatchTx] sell offer of a multi-signature account", accountsBefore[2].SignerThreshold, 0)
+		sellOfferHash := e.hash(std.CollectHashInputsFromOfferTx(tx.SellOffer)...)
+		e.verifyEddsaSig("[VerifyAtomicMatchTx] invalid sell offer signature", sellOfferHash, accountsBefore[2].AccountPk, tx.SellOffer.Sig)
+	}
+	e.isVariableEqual("[VerifyAtomicMatchTx] invalid submitter account index", tx.AccountIndex, accountsBefore[0].AccountIndex)
+	e.isVariableEqual("[VerifyAtomicMatchTx] invalid buyer account index", tx.BuyOffer.AccountIndex, accountsBefore[1].AccountIndex)
+	e.isVariableEqual("[VerifyAtomicMatchTx] invalid seller account index", tx.SellOffer.AccountIndex, accountsBefore[2].AccountIndex)
+	e.isVariableEqual("[VerifyAtomicMatchTx] invalid creator account index", nftBefore.CreatorAccountIndex, accountsBefore[3].AccountIndex)
+	e.isVariableEqual("[VerifyAtomicMatchTx] invalid gas account index", tx.GasAccountIndex, accountsBefore[4].AccountIndex)
+	buyOfferIndex := e.offerIndex("[VerifyAtomicMatchTx] invalid buy offer id", tx.BuyOffer.OfferId)
+	buyOfferBits, _ := e.splitOfferCanceledOrFinalized(accountsBefore[1].AssetsInfo[1].OfferCanceledOrFinalized)
+	if buyOfferBits[buyOfferIndex] != 0 {
+		e.fail("[VerifyAtomicMatchTx] buy offer canceled or finalized")
+	}
+	sellOfferIndex := e.offerIndex("[VerifyAtomicMatchTx] invalid sell offer id", tx.SellOffer.OfferId)
+	sellOfferBits, _ := e.splitOfferCanceledOrFinalized(accountsBefore[2].AssetsInfo[1].OfferCanceledOrFinalized)
+	if sellOfferBits[sellOfferIndex] != 0 {
+		e.fail("[VerifyAtomicMatchTx] sell offer canceled or finalized")
+	}
+	tx.BuyOffer.AssetAmount = e.unpackAmount(tx.BuyOffer.AssetAmount)
+	e.isVariableLessOrEqual("[VerifyAtomicMatchTx] not enough buyer balance", tx.BuyOffer.AssetAmount, accountsBefore[1].AssetsInfo[0].Balance)
+	tx.GasFeeAssetAmount = e.unpackFee(tx.GasFeeAssetAmount)
+	e.isVariableLessOrEqual("[VerifyAtomicMatchTx] not enough gas fee balance", tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[0].Balance)
+}
+
+func (e *executor) verifyCancelOfferTx(tx *std.CancelOfferTxConstraints, accountsBefore accounts) {
+	e.isVariableEqual("[VerifyCancelOfferTx] invalid account index", tx.AccountIndex, accountsBefore[0].AccountIndex)
+	e.isVariableEqual("[VerifyCancelOfferTx] invalid gas account index", tx.GasAccountIndex, accountsBefore[1].AccountIndex)
+	e.isVariableEqual("[VerifyCancelOfferTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[0].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyCancelOfferTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[1].AssetsInfo[0].AssetId)
+	e.toBinary("[VerifyCancelOfferTx] invalid offer id", tx.OfferId, std.OfferIdBitsSize)
+	assetId := new(big.Int).Rsh(e.bigInt(tx.OfferId), 7)
+	e.isVariableEqual("[VerifyCancelOfferTx] invalid offer asset id", assetId, accountsBefore[0].AssetsInfo[1].AssetId)
+	tx.GasFeeAssetAmount = e.unpackFee(tx.GasFeeAssetAmount)
+	e.isVariableLessOrEqual("[VerifyCancelOfferTx] not enough gas fee balance", tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[0].Balance)
+}
+
+func (e *executor) verifyWithdrawNftTx(tx *std.WithdrawNftTxConstraints, accountsBefore accounts, nftBefore std.NftConstraints) {
+	e.isVariableEqual("[VerifyWithdrawNftTx] invalid account index", tx.AccountIndex, accountsBefore[0].AccountIndex)
+	e.isVariableEqual("[VerifyWithdrawNftTx] invalid creator account index", tx.CreatorAccountIndex, accountsBefore[1].AccountIndex)
+	e.isVariableEqual("[VerifyWithdrawNftTx] invalid gas account index", tx.GasAccountIndex, accountsBefore[2].AccountIndex)
+	e.isVariableEqual("[VerifyWithdrawNftTx] invalid creator account name hash", tx.CreatorAccountNameHash, accountsBefore[1].AccountNameHash)
+	e.isVariableEqual("[VerifyWithdrawNftTx] invalid collection id", tx.CollectionId, nftBefore.CollectionId)
+	e.isVariableEqual("[VerifyWithdrawNftTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[0].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyWithdrawNftTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[2].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyWithdrawNftTx] invalid nft index", tx.NftIndex, nftBefore.NftIndex)
+	e.isVariableEqual("[VerifyWithdrawNftTx] invalid nft creator", tx.CreatorAccountIndex, nftBefore.CreatorAccountIndex)
+	e.isVariableEqual("[VerifyWithdrawNftTx] invalid creator treasury rate", tx.CreatorTreasuryRate, nftBefore.CreatorTreasuryRate)
+	e.isVariableEqual("[VerifyWithdrawNftTx] invalid nft owner", tx.AccountIndex, nftBefore.OwnerAccountIndex)
+	e.isVariableEqual("[VerifyWithdrawNftTx] invalid nft content hash", tx.NftContentHash, nftBefore.NftContentHash)
+	e.isVariableEqual("[VerifyWithdrawNftTx] invalid nft l1 token id", tx.NftL1TokenId, nftBefore.NftL1TokenId)
+	e.isVariableEqual("[VerifyWithdrawNftTx] invalid nft l1 address", tx.NftL1Address, nftBefore.NftL1Address)
+	tx.GasFeeAssetAmount = e.unpackFee(tx.GasFeeAssetAmount)
+	e.isVariableLessOrEqual("[VerifyWithdrawNftTx] not enough gas fee balance", tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[0].Balance)
+}
+
+func (e *executor) verifyFullExitTx(tx std.FullExitTxConstraints, accountsBefore accounts) {
+	e.isVariableEqual("[VerifyFullExitTx] invalid account name hash", tx.AccountNameHash, accountsBefore[0].AccountNameHash)
+	e.isVariableEqual("[VerifyFullExitTx] invalid account index", tx.AccountIndex, accountsBefore[0].AccountIndex)
+	e.isVariableEqual("[VerifyFullExitTx] invalid asset id", tx.AssetId, accountsBefore[0].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyFullExitTx] invalid asset amount", tx.AssetAmount, accountsBefore[0].AssetsInfo[0].Balance)
+}
+
+func (e *executor) verifyFullExitNftTx(tx std.FullExitNftTxConstraints, accountsBefore accounts, nftBefore std.NftConstraints) {
+	e.isVariableEqual("[VerifyFullExitNftTx] invalid account name hash", tx.AccountNameHash, accountsBefore[0].AccountNameHash)
+	e.isVariableEqual("[VerifyFullExitNftTx] invalid account index", tx.AccountIndex, accountsBefore[0].AccountIndex)
+	e.isVariableEqual("[VerifyFullExitNftTx] invalid nft index", tx.NftIndex, nftBefore.NftIndex)
+	e.isVariableEqual("[VerifyFullExitNftTx] invalid creator account index", tx.CreatorAccountIndex, nftBefore.CreatorAccountIndex)
+	e.isVariableEqual("[VerifyFullExitNftTx] invalid creator treasury rate", tx.CreatorTreasuryRate, nftBefore.CreatorTreasuryRate)
+	// the nft info is only checked for its owner
+	if e.isEqual(tx.AccountIndex, nftBefore.OwnerAccountIndex) {
+		e.isVariableEqual("[VerifyFullExitNftTx] invalid nft content hash", tx.NftContentHash, nftBefore.NftContentHash)
+		e.isVariableEqual("[VerifyFullExitNftTx] invalid nft l1 address", tx.NftL1Address, nftBefore.NftL1Address)
+		e.isVariableEqual("[VerifyFullExitNftTx] invalid nft l1 token id", tx.NftL1TokenId, nftBefore.NftL1TokenId)
+	}
+}
+
+func (e *executor) verifyChangePubKeyTx(tx *std.ChangePubKeyTxConstraints, accountsBefore accounts) {
+	e.isVariableEqual("[VerifyChangePubKeyTx] invalid account index", tx.AccountIndex, accountsBefore[0].AccountIndex)
+	e.isVariableEqual("[VerifyChangePubKeyTx] invalid gas account index", tx.GasAccountIndex, accountsBefore[1].AccountIndex)
+	e.isVariableEqual("[VerifyChangePubKeyTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[0].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyChangePubKeyTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[1].AssetsInfo[0].AssetId)
+	tx.GasFeeAssetAmount = e.unpackFee(tx.GasFeeAssetAmount)
+	e.isVariableLessOrEqual("[VerifyChangePubKeyTx] not enough gas fee balance", tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[0].Balance)
+	e.isVariableLessOrEqual("[VerifyChangePubKeyTx] invalid signer threshold", tx.SignerThreshold, std.NbMultiSigsPerTx)
+	if e.isZero(tx.SignerThreshold) {
+		e.isVariableEqual("[VerifyChangePubKeyTx] signer root of a single key", tx.SignerRoot, 0)
+	} else {
+		e.isVariableEqual("[VerifyChangePubKeyTx] public key of a signer set", tx.PubKey.A.X, 0)
+		e.isVariableEqual("[VerifyChangePubKeyTx] public key of a signer set", tx.PubKey.A.Y, 0)
+	}
+}
+
+func (e *executor) verifyFullChangePubKeyTx(tx std.FullChangePubKeyTxConstraints, accountsBefore accounts) {
+	e.isVariableEqual("[VerifyFullChangePubKeyTx] invalid account name hash", tx.AccountNameHash, accountsBefore[0].AccountNameHash)
+	e.isVariableEqual("[VerifyFullChangePubKeyTx] invalid account index", tx.AccountIndex, accountsBefore[0].AccountIndex)
+}
+
+func (e *executor) verifyMatchOrderTx(tx *std.MatchOrderTxConstraints, accountsBefore accounts, blockCreatedAt int64) {
+	e.isVariableEqual("[VerifyMatchOrderTx] invalid buy order type", tx.BuyOrder.Type, 0)
+	e.isVariableEqual("[VerifyMatchOrderTx] invalid sell order type", tx.SellOrder.Type, 1)
+	e.isVariableEqual("[VerifyMatchOrderTx] order asset a ids don't match", tx.BuyOrder.AssetAId, tx.SellOrder.AssetAId)
+	e.isVariableEqual("[VerifyMatchOrderTx] order asset b ids don't match", tx.BuyOrder.AssetBId, tx.SellOrder.AssetBId)
+	if e.isEqual(tx.BuyOrder.AssetAId, tx.BuyOrder.AssetBId) {
+		e.fail("[VerifyMatchOrderTx] same order assets")
+	}
+	e.isVariableLessOrEqual("[VerifyMatchOrderTx] buy order expired", blockCreatedAt, tx.BuyOrder.ExpiredAt)
+	e.isVariableLessOrEqual("[VerifyMatchOrderTx] sell order expired", blockCreatedAt, tx.SellOrder.ExpiredAt)
+	// the orders of the submitter are signed by the tx signature
+	if !e.isEqual(tx.AccountIndex, tx.BuyOrder.AccountIndex) {
+		e.isVariableEqual("[VerifyMatchOrderTx] buy order of a multi-signature account", accountsBefore[1].SignerThreshold, 0)
+		buyOrderHash := e.hash(std.CollectHashInputsFromOrderTx(tx.BuyOrder)...)
+		e.verifyEddsaSig("[VerifyMatchOrderTx] invalid buy order signature", buyOrderHash, accountsBefore[1].AccountPk, tx.BuyOrder.Sig)
+	}
+	if !e.isEqual(tx.AccountIndex, tx.SellOrder.AccountIndex) {
+		e.isVariableEqual("[VerifyMatchOrderTx] sell order of a multi-signature account", accountsBefore[2].SignerThreshold, 0)
+		sellOrderHash := e.hash(std.CollectHashInputsFromOrderTx(tx.SellOrder)...)
+		e.verifyEddsaSig("[VerifyMatchOrderTx] invalid sell order signature", sellOrderHash, accountsBefore[2].AccountPk, tx.SellOrder.Sig)
+	}
+	e.isVariableEqual("[VerifyMatchOrderTx] invalid submitter account index", tx.AccountIndex, accountsBefore[0].AccountIndex)
+	e.isVariableEqual("[VerifyMatchOrderTx] invalid buyer account index", tx.BuyOrder.AccountIndex, accountsBefore[1].AccountIndex)
+	e.isVariableEqual("[VerifyMatchOrderTx] invalid seller account index", tx.SellOrder.AccountIndex, accountsBefore[2].AccountIndex)
+	e.isVariableEqual("[VerifyMatchOrderTx] invalid gas account index", tx.GasAccountIndex, accountsBefore[3].AccountIndex)
+	e.isVariableEqual("[VerifyMatchOrderTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[0].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyMatchOrderTx] invalid buyer asset b id", tx.BuyOrder.AssetBId, accountsBefore[1].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyMatchOrderTx] invalid buyer asset a id", tx.BuyOrder.AssetAId, accountsBefore[1].AssetsInfo[1].AssetId)
+	e.isVariableEqual("[VerifyMatchOrderTx] invalid seller asset a id", tx.SellOrder.AssetAId, accountsBefore[2].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyMatchOrderTx] invalid seller asset b id", tx.SellOrder.AssetBId, accountsBefore[2].AssetsInfo[1].AssetId)
+	e.isVariableEqual("[VerifyMatchOrderTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[3].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyMatchOrderTx] invalid buy order slot", tx.BuyOrder.OrderId, accountsBefore[1].AssetsInfo[2].AssetId)
+	e.isVariableEqual("[VerifyMatchOrderTx] invalid sell order slot", tx.SellOrder.OrderId, accountsBefore[2].AssetsInfo[2].AssetId)
+	if e.isEqual(tx.BuyOrder.OrderId, tx.BuyOrder.AssetAId) || e.isEqual(tx.BuyOrder.OrderId, tx.BuyOrder.AssetBId) ||
+		e.isEqual(tx.SellOrder.OrderId, tx.SellOrder.AssetAId) || e.isEqual(tx.SellOrder.OrderId, tx.SellOrder.AssetBId) {
+		e.fail("[VerifyMatchOrderTx] order slot is a traded asset")
+	}
+	tx.BuyOrder.AssetAAmount = e.unpackAmount(tx.BuyOrder.AssetAAmount)
+	tx.BuyOrder.AssetBAmount = e.unpackAmount(tx.BuyOrder.AssetBAmount)
+	tx.SellOrder.AssetAAmount = e.unpackAmount(tx.SellOrder.AssetAAmount)
+	tx.SellOrder.AssetBAmount = e.unpackAmount(tx.SellOrder.AssetBAmount)
+	tx.AssetAFillAmount = e.unpackAmount(tx.AssetAFillAmount)
+	tx.AssetBFillAmount = e.unpackAmount(tx.AssetBFillAmount)
+	e.toBinary("[VerifyMatchOrderTx] invalid buy order asset a amount", tx.BuyOrder.AssetAAmount, std.OrderFilledAmountBitsSize)
+	e.toBinary("[VerifyMatchOrderTx] invalid buy order asset b amount", tx.BuyOrder.AssetBAmount, std.OrderFilledAmountBitsSize)
+	e.toBinary("[VerifyMatchOrderTx] invalid sell order asset a amount", tx.SellOrder.AssetAAmount, std.OrderFilledAmountBitsSize)
+	e.toBinary("[VerifyMatchOrderTx] invalid sell order asset b amount", tx.SellOrder.AssetBAmount, std.OrderFilledAmountBitsSize)
+	e.toBinary("[VerifyMatchOrderTx] invalid asset b fill amount", tx.AssetBFillAmount, std.OrderFilledAmountBitsSize)
+	e.isVariableLessOrEqual("[VerifyMatchOrderTx] empty asset a fill amount", 1, tx.AssetAFillAmount)
+	e.isVariableLessOrEqual("[VerifyMatchOrderTx] buy order price exceeded",
+		e.mul(tx.AssetBFillAmount, tx.BuyOrder.AssetAAmount), e.mul(tx.AssetAFillAmount, tx.BuyOrder.AssetBAmount))
+	e.isVariableLessOrEqual("[VerifyMatchOrderTx] sell order price not reached",
+		e.mul(tx.AssetAFillAmount, tx.SellOrder.AssetBAmount), e.mul(tx.AssetBFillAmount, tx.SellOrder.AssetAAmount))
+	_, buyOrderFilledAmount := e.splitOfferCanceledOrFinalized(accountsBefore[1].AssetsInfo[2].OfferCanceledOrFinalized)
+	_, sellOrderFilledAmount := e.splitOfferCanceledOrFinalized(accountsBefore[2].AssetsInfo[2].OfferCanceledOrFinalized)
+	e.isVariableLessOrEqual("[VerifyMatchOrderTx] buy order overfilled", e.add(buyOrderFilledAmount, tx.AssetAFillAmount), tx.BuyOrder.AssetAAmount)
+	e.isVariableLessOrEqual("[VerifyMatchOrderTx] sell order overfilled", e.add(sellOrderFilledAmount, tx.AssetAFillAmount), tx.SellOrder.AssetAAmount)
+	tx.GasFeeAssetAmount = e.unpackFee(tx.GasFeeAssetAmount)
+	e.isVariableLessOrEqual("[VerifyMatchOrderTx] not enough buyer balance", tx.AssetBFillAmount, accountsBefore[1].AssetsInfo[0].Balance)
+	e.isVariableLessOrEqual("[VerifyMatchOrderTx] not enough seller balance", tx.AssetAFillAmount, accountsBefore[2].AssetsInfo[0].Balance)
+	e.isVariableLessOrEqual("[VerifyMatchOrderTx] not enough gas fee balance", tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[0].Balance)
+}
+
+func (e *executor) verifyCancelOrderTx(tx *std.CancelOrderTxConstraints, accountsBefore accounts) {
+	e.isVariableEqual("[VerifyCancelOrderTx] invalid account index", tx.AccountIndex, accountsBefore[0].AccountIndex)
+	e.isVariableEqual("[VerifyCancelOrderTx] invalid gas account index", tx.GasAccountIndex, accountsBefore[1].AccountIndex)
+	e.isVariableEqual("[VerifyCancelOrderTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[0].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyCancelOrderTx] invalid gas fee asset id", tx.GasFeeAssetId, accountsBefore[1].AssetsInfo[0].AssetId)
+	e.isVariableEqual("[VerifyCancelOrderTx] invalid order slot", tx.OrderId, accountsBefore[0].AssetsInfo[1].AssetId)
+	if e.isEqual(tx.OrderId, tx.GasFeeAssetId) {
+		e.fail("[VerifyCancelOrderTx] order slot is the gas fee asset")
+	}
+	tx.GasFeeAssetAmount = e.unpackFee(tx.GasFeeAssetAmount)
+	e.isVariableLessOrEqual("[VerifyCancelOrderTx] not enough gas fee balance", tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[0].Balance)
+}
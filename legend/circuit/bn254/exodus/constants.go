@@ -0,0 +1,41 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package exodus
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+type (
+	Variable = frontend.Variable
+	API      = frontend.API
+	MiMC     = mimc.MiMC
+
+	PublicKeyConstraints = std.PublicKeyConstraints
+	NftConstraints       = std.NftConstraints
+)
+
+const (
+	AccountMerkleLevels = block.AccountMerkleLevels
+	AssetMerkleLevels   = block.AssetMerkleLevels
+	NftMerkleLevels     = block.NftMerkleLevels
+)
@@ -0,0 +1,64 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package exodus
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+/*
+	ExodusAccount: account leaf without its assets and its merkle proof in the account tree
+*/
+type ExodusAccount struct {
+	AccountIndex        int64
+	AccountNameHash     []byte
+	AccountPk           *eddsa.PublicKey
+	Nonce               int64
+	CollectionNonce     int64
+	AssetRoot           []byte
+	SignerRoot          []byte
+	SignerThreshold     int64
+	MerkleProofsAccount [AccountMerkleLevels][]byte
+}
+
+/*
+	Exodus: asset of an account and the merkle proof of the asset in the asset
+	tree of the account, under the state root of the last verified block
+*/
+type Exodus struct {
+	StateRoot                []byte
+	Account                  *ExodusAccount
+	Asset                    *std.AccountAsset
+	MerkleProofsAccountAsset [AssetMerkleLevels][]byte
+	LiquidityRoot            []byte
+	NftRoot                  []byte
+}
+
+/*
+	ExodusNft: nft owned by an account and the merkle proof of the nft in the
+	nft tree, under the state root of the last verified block
+*/
+type ExodusNft struct {
+	StateRoot       []byte
+	Account         *ExodusAccount
+	Nft             *std.Nft
+	MerkleProofsNft [NftMerkleLevels][]byte
+	LiquidityRoot   []byte
+}
@@ -0,0 +1,249 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package exodus
+
+import (
+	"errors"
+	"log"
+
+	"github.com/consensys/gnark/std/hash/mimc"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+/*
+	ExodusAccountConstraints: account leaf of the exodus circuits, its index, name
+	hash and public key are public so that L1 can check the owner of the account
+*/
+type ExodusAccountConstraints struct {
+	AccountIndex        Variable             `gnark:",public"`
+	AccountNameHash     Variable             `gnark:",public"`
+	AccountPk           PublicKeyConstraints `gnark:",public"`
+	Nonce               Variable
+	CollectionNonce     Variable
+	AssetRoot           Variable
+	SignerRoot          Variable
+	SignerThreshold     Variable
+	MerkleProofsAccount [AccountMerkleLevels]Variable
+}
+
+/*
+	ExodusConstraints: the balance of an asset of an account under the state root
+	of the last verified block, its public inputs are in order
+	StateRoot | AccountIndex | AccountNameHash | AccountPk.A.X | AccountPk.A.Y | AssetId | Balance
+*/
+type ExodusConstraints struct {
+	StateRoot                Variable `gnark:",public"`
+	Account                  ExodusAccountConstraints
+	AssetId                  Variable `gnark:",public"`
+	Balance                  Variable `gnark:",public"`
+	LpAmount                 Variable
+	OfferCanceledOrFinalized Variable
+	MerkleProofsAccountAsset [AssetMerkleLevels]Variable
+	LiquidityRoot            Variable
+	NftRoot                  Variable
+}
+
+func (circuit ExodusConstraints) Define(api API) error {
+	// mimc
+	hFunc, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	VerifyExodus(api, circuit, hFunc)
+	return nil
+}
+
+/*
+	ExodusNftConstraints: an nft owned by an account under the state root of the
+	last verified block, its public inputs are in order
+	StateRoot | AccountIndex | AccountNameHash | AccountPk.A.X | AccountPk.A.Y |
+	NftIndex | NftContentHash | CreatorAccountIndex | OwnerAccountIndex |
+	NftL1Address | NftL1TokenId | CreatorTreasuryRate | CollectionId
+*/
+type ExodusNftConstraints struct {
+	StateRoot       Variable `gnark:",public"`
+	Account         ExodusAccountConstraints
+	Nft             NftConstraints `gnark:",public"`
+	MerkleProofsNft [NftMerkleLevels]Variable
+	LiquidityRoot   Variable
+}
+
+func (circuit ExodusNftConstraints) Define(api API) error {
+	// mimc
+	hFunc, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	VerifyExodusNft(api, circuit, hFunc)
+	return nil
+}
+
+/*
+	VerifyExodus: the asset leaf is in the asset tree of the account and the
+	account leaf is in the account tree of the state root
+*/
+func VerifyExodus(api API, exodus ExodusConstraints, hFunc MiMC) {
+	api.AssertIsLessOrEqual(exodus.AssetId, block.LastAccountAssetId)
+	assetMerkleHelper := block.AssetIdToMerkleHelper(api, exodus.AssetId)
+	hFunc.Reset()
+	hFunc.Write(std.CollectHashInputsFromAccountAsset(std.AccountAssetConstraints{
+		AssetId:                  exodus.AssetId,
+		Balance:                  exodus.Balance,
+		LpAmount:                 exodus.LpAmount,
+		OfferCanceledOrFinalized: exodus.OfferCanceledOrFinalized,
+	})...)
+	assetNodeHash := hFunc.Sum()
+	hFunc.Reset()
+	assetRoot := std.UpdateMerkleProof(
+		api, hFunc, assetNodeHash, exodus.MerkleProofsAccountAsset[:], assetMerkleHelper)
+	api.AssertIsEqual(assetRoot, exodus.Account.AssetRoot)
+	accountRoot := VerifyExodusAccount(api, exodus.Account, hFunc)
+	VerifyStateRoot(api, hFunc, exodus.StateRoot, accountRoot, exodus.LiquidityRoot, exodus.NftRoot)
+}
+
+/*
+	VerifyExodusNft: the nft leaf is owned by the account and is in the nft
+	tree of the state root, like the account leaf in the account tree
+*/
+func VerifyExodusNft(api API, exodus ExodusNftConstraints, hFunc MiMC) {
+	api.AssertIsLessOrEqual(exodus.Nft.NftIndex, block.LastNftIndex)
+	api.AssertIsEqual(exodus.Nft.OwnerAccountIndex, exodus.Account.AccountIndex)
+	nftMerkleHelper := block.NftIndexToMerkleHelper(api, exodus.Nft.NftIndex)
+	hFunc.Reset()
+	hFunc.Write(std.CollectHashInputsFromNft(exodus.Nft)...)
+	nftNodeHash := hFunc.Sum()
+	hFunc.Reset()
+	nftRoot := std.UpdateMerkleProof(api, hFunc, nftNodeHash, exodus.MerkleProofsNft[:], nftMerkleHelper)
+	accountRoot := VerifyExodusAccount(api, exodus.Account, hFunc)
+	VerifyStateRoot(api, hFunc, exodus.StateRoot, accountRoot, exodus.LiquidityRoot, nftRoot)
+}
+
+/*
+	VerifyExodusAccount: root of the account tree with the account leaf, the
+	account must be registered
+*/
+func VerifyExodusAccount(api API, account ExodusAccountConstraints, hFunc MiMC) (accountRoot Variable) {
+	api.AssertIsLessOrEqual(account.AccountIndex, block.LastAccountIndex)
+	api.AssertIsDifferent(account.AccountNameHash, std.ZeroInt)
+	accountIndexMerkleHelper := block.AccountIndexToMerkleHelper(api, account.AccountIndex)
+	accountNodeHash := std.ComputeHashFromAccount(api, std.AccountConstraints{
+		AccountIndex:    account.AccountIndex,
+		AccountNameHash: account.AccountNameHash,
+		AccountPk:       account.AccountPk,
+		Nonce:           account.Nonce,
+		CollectionNonce: account.CollectionNonce,
+		AssetRoot:       account.AssetRoot,
+		SignerRoot:      account.SignerRoot,
+		SignerThreshold: account.SignerThreshold,
+	}, account.AssetRoot, hFunc)
+	return std.UpdateMerkleProof(
+		api, hFunc, accountNodeHash, account.MerkleProofsAccount[:], accountIndexMerkleHelper)
+}
+
+/*
+	VerifyStateRoot: the state root is the hash of the account, liquidity and nft roots
+*/
+func VerifyStateRoot(api API, hFunc MiMC, stateRoot, accountRoot, liquidityRoot, nftRoot Variable) {
+	hFunc.Reset()
+	hFunc.Write(
+		accountRoot,
+		liquidityRoot,
+		nftRoot,
+	)
+	api.AssertIsEqual(hFunc.Sum(), stateRoot)
+}
+
+func SetExodusAccountWitness(account *ExodusAccount) (witness ExodusAccountConstraints, err error) {
+	if account == nil || account.AccountPk == nil {
+		log.Println("[SetExodusAccountWitness] invalid params")
+		return witness, errors.New("[SetExodusAccountWitness] invalid params")
+	}
+	witness = ExodusAccountConstraints{
+		AccountIndex:    account.AccountIndex,
+		AccountNameHash: account.AccountNameHash,
+		AccountPk:       std.SetPubKeyWitness(account.AccountPk),
+		Nonce:           account.Nonce,
+		CollectionNonce: account.CollectionNonce,
+		AssetRoot:       account.AssetRoot,
+		SignerRoot:      std.ZeroInt,
+		SignerThreshold: account.SignerThreshold,
+	}
+	if account.SignerRoot != nil {
+		witness.SignerRoot = account.SignerRoot
+	}
+	for i := 0; i < AccountMerkleLevels; i++ {
+		witness.MerkleProofsAccount[i] = account.MerkleProofsAccount[i]
+	}
+	return witness, nil
+}
+
+func SetExodusWitness(oExodus *Exodus) (witness ExodusConstraints, err error) {
+	if oExodus == nil || oExodus.Asset == nil {
+		log.Println("[SetExodusWitness] invalid params")
+		return witness, errors.New("[SetExodusWitness] invalid params")
+	}
+	account, err := SetExodusAccountWitness(oExodus.Account)
+	if err != nil {
+		return witness, err
+	}
+	asset, err := std.SetAccountAssetWitness(oExodus.Asset)
+	if err != nil {
+		return witness, err
+	}
+	witness = ExodusConstraints{
+		StateRoot:                oExodus.StateRoot,
+		Account:                  account,
+		AssetId:                  asset.AssetId,
+		Balance:                  asset.Balance,
+		LpAmount:                 asset.LpAmount,
+		OfferCanceledOrFinalized: asset.OfferCanceledOrFinalized,
+		LiquidityRoot:            oExodus.LiquidityRoot,
+		NftRoot:                  oExodus.NftRoot,
+	}
+	for i := 0; i < AssetMerkleLevels; i++ {
+		witness.MerkleProofsAccountAsset[i] = oExodus.MerkleProofsAccountAsset[i]
+	}
+	return witness, nil
+}
+
+func SetExodusNftWitness(oExodus *ExodusNft) (witness ExodusNftConstraints, err error) {
+	if oExodus == nil {
+		log.Println("[SetExodusNftWitness] invalid params")
+		return witness, errors.New("[SetExodusNftWitness] invalid params")
+	}
+	account, err := SetExodusAccountWitness(oExodus.Account)
+	if err != nil {
+		return witness, err
+	}
+	nft, err := std.SetNftWitness(oExodus.Nft)
+	if err != nil {
+		return witness, err
+	}
+	witness = ExodusNftConstraints{
+		StateRoot:     oExodus.StateRoot,
+		Account:       account,
+		Nft:           nft,
+		LiquidityRoot: oExodus.LiquidityRoot,
+	}
+	for i := 0; i < NftMerkleLevels; i++ {
+		witness.MerkleProofsNft[i] = oExodus.MerkleProofsNft[i]
+	}
+	return witness, nil
+}
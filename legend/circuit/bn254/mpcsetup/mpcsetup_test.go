@@ -0,0 +1,396 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package mpcsetup
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+)
+
+type cubicCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *cubicCircuit) Define(api frontend.API) error {
+	x3 := api.Mul(circuit.X, circuit.X, circuit.X)
+	api.AssertIsEqual(circuit.Y, api.Add(x3, circuit.X, 5))
+	return nil
+}
+
+func reencode(t *testing.T, from io.WriterTo, to io.ReaderFrom) {
+	var buf bytes.Buffer
+	if _, err := from.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := to.ReadFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPhase1(t *testing.T) {
+	if _, err := NewPhase1(MaxPower + 1); err == nil {
+		t.Fatal("phase 1 larger than the largest domain")
+	}
+	phase1, err := NewPhase1(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = phase1.Verify(nil); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err = phase1.Contribute(); err != nil {
+			t.Fatal(err)
+		}
+		next := new(Phase1)
+		reencode(t, phase1, next)
+		if err = next.Verify(nil); err != nil {
+			t.Fatal(err)
+		}
+		phase1 = next
+	}
+
+	tampered := new(Phase1)
+	reencode(t, phase1, tampered)
+	tampered.Parameters.G1.Tau[3] = tampered.Parameters.G1.Tau[2]
+	if err = tampered.Verify(nil); err == nil {
+		t.Fatal("invalid powers of τ accepted")
+	}
+	reencode(t, phase1, tampered)
+	tampered.Parameters.G1.AlphaTau[5] = tampered.Parameters.G1.BetaTau[5]
+	if err = tampered.Verify(nil); err == nil {
+		t.Fatal("invalid powers of ατ accepted")
+	}
+	// a contribution can't be replaced by another one
+	reencode(t, phase1, tampered)
+	tampered.Contributions[0].Tau = tampered.Contributions[1].Tau
+	if err = tampered.Verify(nil); err == nil {
+		t.Fatal("invalid contribution accepted")
+	}
+}
+
+/*
+	writePtau: the parameters of phase1 in a snarkjs ptau file of the same
+	power, with the sections of snarkjs after the ones read
+*/
+func writePtau(phase1 *Phase1) []byte {
+	var buf bytes.Buffer
+	put := func(v interface{}) {
+		_ = binary.Write(&buf, binary.LittleEndian, v)
+	}
+	putElements := func(elements ...*fp.Element) {
+		for _, e := range elements {
+			put(e[:])
+		}
+	}
+	section := func(id uint32, size int) {
+		put(id)
+		put(uint64(size))
+	}
+	params := &phase1.Parameters
+	n := phase1.Size()
+	power := 0
+	for 1<<power < n {
+		power++
+	}
+	buf.WriteString("ptau")
+	put(uint32(1))
+	put(uint32(8))
+	section(ptauSectionHeader, 4+fp.Bytes+4+4)
+	put(uint32(fp.Bytes))
+	q := fp.Modulus().Bytes()
+	reverse(q)
+	buf.Write(q)
+	put(uint32(power))
+	put(uint32(MaxPower))
+	for _, points := range []struct {
+		id     uint32
+		points []curve.G1Affine
+	}{
+		{ptauSectionTauG1, params.G1.Tau[:2*n-1]},
+		{ptauSectionTauG2, nil},
+		{ptauSectionAlphaTauG1, params.G1.AlphaTau},
+		{ptauSectionBetaTauG1, params.G1.BetaTau},
+		{ptauSectionBetaG2, nil},
+	} {
+		switch points.id {
+		case ptauSectionTauG2:
+			section(points.id, n*4*fp.Bytes)
+			for i := range params.G2.Tau {
+				p := &params.G2.Tau[i]
+				putElements(&p.X.A0, &p.X.A1, &p.Y.A0, &p.Y.A1)
+			}
+		case ptauSectionBetaG2:
+			section(points.id, 4*fp.Bytes)
+			p := &params.G2.Beta
+			putElements(&p.X.A0, &p.X.A1, &p.Y.A0, &p.Y.A1)
+		default:
+			section(points.id, len(points.points)*2*fp.Bytes)
+			for i := range points.points {
+				putElements(&points.points[i].X, &points.points[i].Y)
+			}
+		}
+	}
+	// no contribution, and a lagrange section of a prepared ptau
+	section(7, 4)
+	put(uint32(0))
+	section(12, 2*fp.Bytes)
+	buf.Write(make([]byte, 2*fp.Bytes))
+	return buf.Bytes()
+}
+
+func TestImportPhase1(t *testing.T) {
+	// the ceremony of the ptau file
+	ceremony, err := NewPhase1(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ceremony.Contribute(); err != nil {
+		t.Fatal(err)
+	}
+	ptau := writePtau(ceremony)
+	if _, err = ImportPhase1(bytes.NewReader(ptau), 4); err == nil {
+		t.Fatal("phase 1 as large as the ptau accepted")
+	}
+	imported, err := ImportPhase1(bytes.NewReader(ptau), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := &imported.Parameters
+	if imported.Size() != 8 || len(params.G1.Tau) != 16 || len(imported.Contributions) != 0 ||
+		!params.G1.Tau[15].Equal(&ceremony.Parameters.G1.Tau[15]) ||
+		!params.G1.BetaTau[7].Equal(&ceremony.Parameters.G1.BetaTau[7]) ||
+		!params.G2.Tau[7].Equal(&ceremony.Parameters.G2.Tau[7]) ||
+		!params.G2.Beta.Equal(&ceremony.Parameters.G2.Beta) {
+		t.Fatal("invalid imported parameters")
+	}
+
+	phase1 := new(Phase1)
+	reencode(t, imported, phase1)
+	if err = phase1.Contribute(); err != nil {
+		t.Fatal(err)
+	}
+	next := new(Phase1)
+	reencode(t, phase1, next)
+	if err = next.Verify(imported); err != nil {
+		t.Fatal(err)
+	}
+	// the τ of the ceremony isn't the one of NewPhase1
+	if err = next.Verify(nil); err == nil {
+		t.Fatal("imported phase 1 accepted without the ceremony")
+	}
+	fresh, err := NewPhase1(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = fresh.Contribute(); err != nil {
+		t.Fatal(err)
+	}
+	if err = fresh.Verify(imported); err == nil {
+		t.Fatal("phase 1 of another ceremony accepted")
+	}
+
+	// the imported phase 1 gives working keys
+	var circuit cubicCircuit
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	phase2, evaluations, err := InitPhase2(ccs, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = phase2.Contribute(); err != nil {
+		t.Fatal(err)
+	}
+	pk, vk, err := phase2.ExtractKeys(evaluations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	witness, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 35}, ecc.BN254)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := groth16.Prove(ccs, pk, witness)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicWitness, err := witness.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = groth16.Verify(proof, vk, publicWitness); err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := new(Phase1)
+	reencode(t, ceremony, tampered)
+	tampered.Parameters.G1.Tau[5] = tampered.Parameters.G1.Tau[4]
+	if _, err = ImportPhase1(bytes.NewReader(writePtau(tampered)), 3); err == nil {
+		t.Fatal("invalid powers of τ accepted")
+	}
+	reencode(t, ceremony, tampered)
+	tampered.Parameters.G1.AlphaTau[2] = tampered.Parameters.G1.BetaTau[2]
+	if _, err = ImportPhase1(bytes.NewReader(writePtau(tampered)), 3); err == nil {
+		t.Fatal("invalid powers of ατ accepted")
+	}
+	invalid := append([]byte(nil), ptau...)
+	invalid[0] = 'z'
+	if _, err = ImportPhase1(bytes.NewReader(invalid), 3); err == nil {
+		t.Fatal("file without the ptau magic accepted")
+	}
+	// q of the header
+	invalid = append([]byte(nil), ptau...)
+	invalid[4+4+4+4+8+4]++
+	if _, err = ImportPhase1(bytes.NewReader(invalid), 3); err == nil {
+		t.Fatal("ptau of another curve accepted")
+	}
+	if _, err = ImportPhase1(bytes.NewReader(ptau[:len(ptau)/2]), 3); err == nil {
+		t.Fatal("truncated ptau accepted")
+	}
+}
+
+func TestPhase2(t *testing.T) {
+	var circuit cubicCircuit
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	phase1, err := NewPhase1(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = InitPhase2(ccs, phase1); err == nil {
+		t.Fatal("phase 1 too small accepted")
+	}
+	sparseCcs, err := frontend.Compile(ecc.BN254, scs.NewBuilder, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = InitPhase2(sparseCcs, phase1); err == nil {
+		t.Fatal("plonk circuit accepted")
+	}
+
+	phase1, err = NewPhase1(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = phase1.Contribute(); err != nil {
+		t.Fatal(err)
+	}
+	initial, evaluations, err := InitPhase2(ccs, phase1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	phase2 := new(Phase2)
+	reencode(t, initial, phase2)
+	for i := 0; i < 3; i++ {
+		if err = phase2.Contribute(); err != nil {
+			t.Fatal(err)
+		}
+		next := new(Phase2)
+		reencode(t, phase2, next)
+		if err = next.Verify(initial); err != nil {
+			t.Fatal(err)
+		}
+		phase2 = next
+	}
+
+	decoded := new(Phase2Evaluations)
+	reencode(t, evaluations, decoded)
+	pk, vk, err := phase2.ExtractKeys(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	witness, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 35}, ecc.BN254)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := groth16.Prove(ccs, pk, witness)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicWitness, err := witness.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = groth16.Verify(proof, vk, publicWitness); err != nil {
+		t.Fatal(err)
+	}
+	publicWitness, err = frontend.NewWitness(&cubicCircuit{Y: 36}, ecc.BN254, frontend.PublicOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = groth16.Verify(proof, vk, publicWitness); err == nil {
+		t.Fatal("proof accepted for another public input")
+	}
+
+	tampered := new(Phase2)
+	reencode(t, phase2, tampered)
+	tampered.Parameters.G1.K[0] = tampered.Parameters.G1.K[1]
+	if err = tampered.Verify(initial); err == nil {
+		t.Fatal("parameters not divided by δ accepted")
+	}
+	reencode(t, phase2, tampered)
+	tampered.Contributions = tampered.Contributions[1:]
+	if err = tampered.Verify(initial); err == nil {
+		t.Fatal("contribution dropped from the transcript accepted")
+	}
+
+	// the transcript starts from the initial parameters, so the contributions
+	// to the phase 2 of another circuit have other challenges
+	otherPhase1, err := NewPhase1(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = otherPhase1.Contribute(); err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := InitPhase2(ccs, otherPhase1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge, err := initial.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherChallenge, err := other.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(challenge, otherChallenge) {
+		t.Fatal("same first challenge for another circuit")
+	}
+	reencode(t, other, tampered)
+	tampered.Contributions = phase2.Contributions
+	tampered.Parameters = phase2.Parameters
+	if err = tampered.Verify(initial); err == nil {
+		t.Fatal("contributions replayed on the phase 2 of another circuit accepted")
+	}
+}
@@ -0,0 +1,342 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package mpcsetup
+
+import (
+	"errors"
+	"io"
+	"log"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+const (
+	// a domain of 2^28 is the largest with a root of unity in BN254 Fr
+	MaxPower = 28
+
+	dstTau   = 1
+	dstAlpha = 2
+	dstBeta  = 3
+)
+
+/*
+	Phase1: powers of tau of the phase 1 of the groth16 setup, universal for
+	all circuits with at most 2^power constraints
+*/
+type Phase1 struct {
+	Parameters struct {
+		G1 struct {
+			Tau      []curve.G1Affine // [τ⁰]₁, [τ¹]₁, ..., [τ²ⁿ⁻¹]₁
+			AlphaTau []curve.G1Affine // [ατ⁰]₁, ..., [ατⁿ⁻¹]₁
+			BetaTau  []curve.G1Affine // [βτ⁰]₁, ..., [βτⁿ⁻¹]₁
+		}
+		G2 struct {
+			Tau  []curve.G2Affine // [τ⁰]₂, ..., [τⁿ⁻¹]₂
+			Beta curve.G2Affine
+		}
+	}
+	// [τ]₁, [α]₁ and [β]₁ before the first contribution, the generators
+	// unless the phase 1 was imported from another ceremony
+	Initial struct {
+		TauG1, AlphaG1, BetaG1 curve.G1Affine
+	}
+	Contributions []Phase1Contribution
+}
+
+/*
+	Phase1Contribution: proofs of knowledge of the secrets of a contribution,
+	with [τ]₁, [α]₁ and [β]₁ after it
+*/
+type Phase1Contribution struct {
+	Tau, Alpha, Beta       PublicKey
+	TauG1, AlphaG1, BetaG1 curve.G1Affine
+}
+
+/*
+	NewPhase1: phase 1 before any contribution, τ = α = β = 1
+*/
+func NewPhase1(power int) (phase1 *Phase1, err error) {
+	if power < 1 || power > MaxPower {
+		log.Println("[NewPhase1] invalid power")
+		return nil, errors.New("[NewPhase1] invalid power")
+	}
+	n := 1 << power
+	_, _, g1, g2 := curve.Generators()
+	phase1 = new(Phase1)
+	params := &phase1.Parameters
+	params.G1.Tau = make([]curve.G1Affine, 2*n)
+	params.G1.AlphaTau = make([]curve.G1Affine, n)
+	params.G1.BetaTau = make([]curve.G1Affine, n)
+	params.G2.Tau = make([]curve.G2Affine, n)
+	for i := range params.G1.Tau {
+		params.G1.Tau[i] = g1
+	}
+	for i := 0; i < n; i++ {
+		params.G1.AlphaTau[i] = g1
+		params.G1.BetaTau[i] = g1
+		params.G2.Tau[i] = g2
+	}
+	params.G2.Beta = g2
+	phase1.Initial.TauG1, phase1.Initial.AlphaG1, phase1.Initial.BetaG1 = g1, g1, g1
+	return phase1, nil
+}
+
+/*
+	Size: number of constraints the phase 1 can be used for
+*/
+func (phase1 *Phase1) Size() int {
+	return len(phase1.Parameters.G2.Tau)
+}
+
+/*
+	Contribute: multiply the parameters with random τ, α and β, which are
+	dropped once the contribution is added
+*/
+func (phase1 *Phase1) Contribute() (err error) {
+	challenge, err := phase1.Hash()
+	if err != nil {
+		return err
+	}
+	tau, err := randomNonZero()
+	if err != nil {
+		return err
+	}
+	alpha, err := randomNonZero()
+	if err != nil {
+		return err
+	}
+	beta, err := randomNonZero()
+	if err != nil {
+		return err
+	}
+	var contribution Phase1Contribution
+	contribution.Tau, err = newPublicKey(&tau, challenge, dstTau)
+	if err != nil {
+		return err
+	}
+	contribution.Alpha, err = newPublicKey(&alpha, challenge, dstAlpha)
+	if err != nil {
+		return err
+	}
+	contribution.Beta, err = newPublicKey(&beta, challenge, dstBeta)
+	if err != nil {
+		return err
+	}
+
+	params := &phase1.Parameters
+	n := phase1.Size()
+	one := fr.One()
+	tauPowers := powers(one, tau, 2*n)
+	scaleG1(params.G1.Tau, tauPowers)
+	scaleG2(params.G2.Tau, tauPowers[:n])
+	alphaTauPowers := powers(alpha, tau, n)
+	scaleG1(params.G1.AlphaTau, alphaTauPowers)
+	betaTauPowers := powers(beta, tau, n)
+	scaleG1(params.G1.BetaTau, betaTauPowers)
+	params.G2.Beta.ScalarMultiplication(&params.G2.Beta, toBigInt(&beta))
+
+	contribution.TauG1 = params.G1.Tau[1]
+	contribution.AlphaG1 = params.G1.AlphaTau[0]
+	contribution.BetaG1 = params.G1.BetaTau[0]
+	phase1.Contributions = append(phase1.Contributions, contribution)
+	return nil
+}
+
+/*
+	Verify: every contribution proves the knowledge of its secrets and is
+	applied on top of the previous one, and the parameters are powers of the
+	resulting τ. initial is the phase 1 imported from the ceremony it
+	continues, see ImportPhase1, or nil if it starts from NewPhase1
+*/
+func (phase1 *Phase1) Verify(initial *Phase1) (err error) {
+	params := &phase1.Parameters
+	n := phase1.Size()
+	if n < 2 || n&(n-1) != 0 || len(params.G1.Tau) != 2*n ||
+		len(params.G1.AlphaTau) != n || len(params.G1.BetaTau) != n {
+		log.Println("[Phase1.Verify] invalid parameters size")
+		return errInvalidParameters
+	}
+	_, _, g1, g2 := curve.Generators()
+	tauG1, alphaG1, betaG1 := g1, g1, g1
+	if initial != nil {
+		tauG1, alphaG1, betaG1 = initial.Initial.TauG1, initial.Initial.AlphaG1, initial.Initial.BetaG1
+	}
+	if !phase1.Initial.TauG1.Equal(&tauG1) || !phase1.Initial.AlphaG1.Equal(&alphaG1) ||
+		!phase1.Initial.BetaG1.Equal(&betaG1) {
+		log.Println("[Phase1.Verify] phase 1 of another ceremony")
+		return errInvalidParameters
+	}
+	for i := range phase1.Contributions {
+		challenge, err := phase1.hash(i)
+		if err != nil {
+			return err
+		}
+		contribution := &phase1.Contributions[i]
+		if contribution.Tau.verify(&tauG1, &contribution.TauG1, challenge, dstTau) != nil ||
+			contribution.Alpha.verify(&alphaG1, &contribution.AlphaG1, challenge, dstAlpha) != nil ||
+			contribution.Beta.verify(&betaG1, &contribution.BetaG1, challenge, dstBeta) != nil {
+			log.Println("[Phase1.Verify] invalid contribution:", i)
+			return errInvalidContribution
+		}
+		tauG1, alphaG1, betaG1 = contribution.TauG1, contribution.AlphaG1, contribution.BetaG1
+	}
+	if !params.G1.Tau[0].Equal(&g1) || !params.G2.Tau[0].Equal(&g2) ||
+		!params.G1.Tau[1].Equal(&tauG1) || !params.G1.AlphaTau[0].Equal(&alphaG1) ||
+		!params.G1.BetaTau[0].Equal(&betaG1) {
+		log.Println("[Phase1.Verify] parameters don't match the last contribution")
+		return errInvalidParameters
+	}
+
+	// [τ]₂ and [β]₂ are the ones of G1
+	if !sameRatio(&g1, &params.G1.Tau[1], &g2, &params.G2.Tau[1]) ||
+		!sameRatio(&g1, &params.G1.BetaTau[0], &g2, &params.G2.Beta) {
+		log.Println("[Phase1.Verify] G2 parameters don't match G1")
+		return errInvalidParameters
+	}
+	return phase1.verifyPowers()
+}
+
+/*
+	verifyPowers: each point is the previous one multiplied by τ
+*/
+func (phase1 *Phase1) verifyPowers() (err error) {
+	params := &phase1.Parameters
+	_, _, g1, g2 := curve.Generators()
+	for _, points := range [][]curve.G1Affine{params.G1.Tau, params.G1.AlphaTau, params.G1.BetaTau} {
+		a, b, err := powersRatioG1(points)
+		if err != nil {
+			return err
+		}
+		if !sameRatio(&a, &b, &g2, &params.G2.Tau[1]) {
+			log.Println("[Phase1.verifyPowers] invalid powers of τ in G1")
+			return errInvalidParameters
+		}
+	}
+	a, b, err := powersRatioG2(params.G2.Tau)
+	if err != nil {
+		return err
+	}
+	if !sameRatio(&g1, &params.G1.Tau[1], &a, &b) {
+		log.Println("[Phase1.verifyPowers] invalid powers of τ in G2")
+		return errInvalidParameters
+	}
+	return nil
+}
+
+/*
+	Hash: hash of the initial parameters and of the contributions, a
+	participant finds its contribution in the transcript by the hash printed
+	when it contributed
+*/
+func (phase1 *Phase1) Hash() (hash []byte, err error) {
+	return phase1.hash(len(phase1.Contributions))
+}
+
+func (phase1 *Phase1) hash(contributions int) (hash []byte, err error) {
+	return transcriptHash(nil, contributions+1, func(i int, enc *curve.Encoder) error {
+		if i == 0 {
+			for _, v := range []interface{}{&phase1.Initial.TauG1, &phase1.Initial.AlphaG1, &phase1.Initial.BetaG1} {
+				err := enc.Encode(v)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return phase1.Contributions[i-1].writeTo(enc)
+	})
+}
+
+func (contribution *Phase1Contribution) writeTo(enc *curve.Encoder) (err error) {
+	for _, pk := range []*PublicKey{&contribution.Tau, &contribution.Alpha, &contribution.Beta} {
+		err = pk.writeTo(enc)
+		if err != nil {
+			return err
+		}
+	}
+	for _, v := range []interface{}{&contribution.TauG1, &contribution.AlphaG1, &contribution.BetaG1} {
+		err = enc.Encode(v)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (contribution *Phase1Contribution) readFrom(dec *curve.Decoder) (err error) {
+	for _, pk := range []*PublicKey{&contribution.Tau, &contribution.Alpha, &contribution.Beta} {
+		err = pk.readFrom(dec)
+		if err != nil {
+			return err
+		}
+	}
+	for _, v := range []interface{}{&contribution.TauG1, &contribution.AlphaG1, &contribution.BetaG1} {
+		err = dec.Decode(v)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (phase1 *Phase1) WriteTo(w io.Writer) (n int64, err error) {
+	enc := curve.NewEncoder(w)
+	params := &phase1.Parameters
+	for _, v := range []interface{}{
+		params.G1.Tau, params.G1.AlphaTau, params.G1.BetaTau, params.G2.Tau, &params.G2.Beta,
+		&phase1.Initial.TauG1, &phase1.Initial.AlphaG1, &phase1.Initial.BetaG1,
+		uint32(len(phase1.Contributions)),
+	} {
+		err = enc.Encode(v)
+		if err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+	for i := range phase1.Contributions {
+		err = phase1.Contributions[i].writeTo(enc)
+		if err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+	return enc.BytesWritten(), nil
+}
+
+func (phase1 *Phase1) ReadFrom(r io.Reader) (n int64, err error) {
+	dec := curve.NewDecoder(r)
+	params := &phase1.Parameters
+	var contributions uint32
+	for _, v := range []interface{}{
+		&params.G1.Tau, &params.G1.AlphaTau, &params.G1.BetaTau, &params.G2.Tau, &params.G2.Beta,
+		&phase1.Initial.TauG1, &phase1.Initial.AlphaG1, &phase1.Initial.BetaG1,
+		&contributions,
+	} {
+		err = dec.Decode(v)
+		if err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+	phase1.Contributions = make([]Phase1Contribution, contributions)
+	for i := range phase1.Contributions {
+		err = phase1.Contributions[i].readFrom(dec)
+		if err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+	return dec.BytesRead(), nil
+}
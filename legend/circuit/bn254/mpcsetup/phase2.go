@@ -0,0 +1,652 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package mpcsetup
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/compiled"
+)
+
+const dstDelta = 4
+
+/*
+	Phase2Evaluations: the part of the groth16 keys of a circuit which only
+	depends on the phase 1, it isn't changed by the contributions, γ is 1
+*/
+type Phase2Evaluations struct {
+	G1 struct {
+		Alpha, Beta curve.G1Affine
+		A, B        []curve.G1Affine // [Aᵢ(τ)]₁, [Bᵢ(τ)]₁ of all the wires
+		VKK         []curve.G1Affine // [βAᵢ(τ)+αBᵢ(τ)+Cᵢ(τ)]₁ of the public wires
+	}
+	G2 struct {
+		Beta curve.G2Affine
+		B    []curve.G2Affine // [Bᵢ(τ)]₂ of all the wires
+	}
+}
+
+/*
+	Phase2: the part of the groth16 keys of a circuit divided by δ, which is
+	multiplied by each contribution
+*/
+type Phase2 struct {
+	Parameters struct {
+		G1 struct {
+			Delta curve.G1Affine
+			K     []curve.G1Affine // [(βAᵢ(τ)+αBᵢ(τ)+Cᵢ(τ))/δ]₁ of the private wires
+			Z     []curve.G1Affine // [τⁱ(τⁿ-1)/δ]₁, bit reversed as in gnark
+		}
+		G2 struct {
+			Delta curve.G2Affine
+		}
+	}
+	// sha256 of the parameters of InitPhase2, the transcript starts from it
+	// so that a contribution can't be replayed on the phase 2 of another circuit
+	InitialHash   [sha256.Size]byte
+	Contributions []Phase2Contribution
+}
+
+/*
+	Phase2Contribution: proof of knowledge of the δ of a contribution, with [δ]₁ after it
+*/
+type Phase2Contribution struct {
+	Delta   PublicKey
+	DeltaG1 curve.G1Affine
+}
+
+/*
+	InitPhase2: phase 2 of the compiled circuit before any contribution, δ = 1.
+	The result only depends on the circuit and the phase 1, so anyone can
+	compute it again and compare it with the one the ceremony started from.
+*/
+func InitPhase2(ccs frontend.CompiledConstraintSystem, phase1 *Phase1) (phase2 *Phase2, evaluations *Phase2Evaluations, err error) {
+	r1cs, coefficients, err := readR1CS(ccs)
+	if err != nil {
+		return nil, nil, err
+	}
+	domain := fft.NewDomain(uint64(len(r1cs.Constraints)))
+	n := int(domain.Cardinality)
+	if phase1 == nil || n > phase1.Size() {
+		log.Println("[InitPhase2] phase 1 too small for the circuit, constraints:", len(r1cs.Constraints))
+		return nil, nil, errors.New("[InitPhase2] phase 1 too small for the circuit")
+	}
+	params1 := &phase1.Parameters
+	tauL1 := lagrangeG1(params1.G1.Tau[:n], domain)
+	alphaL1 := lagrangeG1(params1.G1.AlphaTau[:n], domain)
+	betaL1 := lagrangeG1(params1.G1.BetaTau[:n], domain)
+	tauL2 := lagrangeG2(params1.G2.Tau[:n], domain)
+
+	// Aᵢ(τ) = Σⱼ aᵢⱼLⱼ(τ) where aᵢⱼ is the coefficient of the wire i in L of
+	// the constraint j, and so on for B and C
+	nbWires := r1cs.NbInternalVariables + r1cs.NbPublicVariables + r1cs.NbSecretVariables
+	a := make([]curve.G1Jac, nbWires)
+	b1 := make([]curve.G1Jac, nbWires)
+	b2 := make([]curve.G2Jac, nbWires)
+	k := make([]curve.G1Jac, nbWires)
+	for j, c := range r1cs.Constraints {
+		for _, t := range c.L {
+			accumulateG1(&a[t.WireID()], &tauL1[j], t, coefficients)
+			accumulateG1(&k[t.WireID()], &betaL1[j], t, coefficients)
+		}
+		for _, t := range c.R {
+			accumulateG1(&b1[t.WireID()], &tauL1[j], t, coefficients)
+			accumulateG2(&b2[t.WireID()], &tauL2[j], t, coefficients)
+			accumulateG1(&k[t.WireID()], &alphaL1[j], t, coefficients)
+		}
+		for _, t := range c.O {
+			accumulateG1(&k[t.WireID()], &tauL1[j], t, coefficients)
+		}
+	}
+
+	evaluations = new(Phase2Evaluations)
+	evaluations.G1.Alpha = params1.G1.AlphaTau[0]
+	evaluations.G1.Beta = params1.G1.BetaTau[0]
+	evaluations.G2.Beta = params1.G2.Beta
+	evaluations.G1.A = toAffineG1(a)
+	evaluations.G1.B = toAffineG1(b1)
+	evaluations.G2.B = toAffineG2(b2)
+	kAffine := toAffineG1(k)
+	evaluations.G1.VKK = kAffine[:r1cs.NbPublicVariables]
+
+	phase2 = new(Phase2)
+	_, _, g1, g2 := curve.Generators()
+	params := &phase2.Parameters
+	params.G1.Delta = g1
+	params.G2.Delta = g2
+	params.G1.K = kAffine[r1cs.NbPublicVariables:]
+	// τⁱ(τⁿ-1) = τⁱ⁺ⁿ-τⁱ
+	z := make([]curve.G1Jac, n)
+	for i := range z {
+		var tau curve.G1Jac
+		tau.FromAffine(&params1.G1.Tau[i])
+		z[i].FromAffine(&params1.G1.Tau[i+n])
+		z[i].SubAssign(&tau)
+	}
+	params.G1.Z = toAffineG1(z)
+	bitReverseG1(params.G1.Z)
+	phase2.InitialHash, err = phase2.parametersHash()
+	if err != nil {
+		return nil, nil, err
+	}
+	return phase2, evaluations, nil
+}
+
+/*
+	readR1CS: constraints of a groth16 BN254 circuit, gnark v0.7.0 keeps the
+	compiled R1CS in an internal package so its fields are read by reflection
+*/
+func readR1CS(ccs frontend.CompiledConstraintSystem) (r1cs *compiled.R1CS, coefficients []fr.Element, err error) {
+	errInvalidCircuit := errors.New("[readR1CS] expected a groth16 BN254 circuit")
+	if ccs == nil || ccs.CurveID() != ecc.BN254 {
+		log.Println("[readR1CS] invalid circuit curve")
+		return nil, nil, errInvalidCircuit
+	}
+	v := reflect.ValueOf(ccs)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		log.Println("[readR1CS] invalid circuit type")
+		return nil, nil, errInvalidCircuit
+	}
+	r1csField := v.Elem().FieldByName("R1CS")
+	coefficientsField := v.Elem().FieldByName("Coefficients")
+	if !r1csField.IsValid() || !coefficientsField.IsValid() {
+		log.Println("[readR1CS] circuit isn't a R1CS")
+		return nil, nil, errInvalidCircuit
+	}
+	r1cs, isR1CS := r1csField.Addr().Interface().(*compiled.R1CS)
+	coefficients, isCoefficients := coefficientsField.Interface().([]fr.Element)
+	if !isR1CS || !isCoefficients {
+		log.Println("[readR1CS] circuit isn't a R1CS")
+		return nil, nil, errInvalidCircuit
+	}
+	return r1cs, coefficients, nil
+}
+
+func accumulateG1(res *curve.G1Jac, p *curve.G1Affine, t compiled.Term, coefficients []fr.Element) {
+	var q curve.G1Affine
+	switch t.CoeffID() {
+	case compiled.CoeffIdZero:
+		return
+	case compiled.CoeffIdOne:
+		q = *p
+	case compiled.CoeffIdMinusOne:
+		q.Neg(p)
+	default:
+		q.ScalarMultiplication(p, toBigInt(&coefficients[t.CoeffID()]))
+	}
+	res.AddMixed(&q)
+}
+
+func accumulateG2(res *curve.G2Jac, p *curve.G2Affine, t compiled.Term, coefficients []fr.Element) {
+	var q curve.G2Affine
+	switch t.CoeffID() {
+	case compiled.CoeffIdZero:
+		return
+	case compiled.CoeffIdOne:
+		q = *p
+	case compiled.CoeffIdMinusOne:
+		q.Neg(p)
+	default:
+		q.ScalarMultiplication(p, toBigInt(&coefficients[t.CoeffID()]))
+	}
+	res.AddMixed(&q)
+}
+
+/*
+	lagrangeG1, lagrangeG2: [Lᵢ(τ)] of the domain from [τʲ], j < n.
+	Lᵢ(τ) = 1/n Σⱼ ω⁻ⁱʲτʲ, so it's an inverse FFT on the points, done with
+	decimation in frequency and a bit reversal at the end.
+*/
+func lagrangeG1(tau []curve.G1Affine, domain *fft.Domain) []curve.G1Affine {
+	n := len(tau)
+	p := make([]curve.G1Jac, n)
+	for i := range tau {
+		p[i].FromAffine(&tau[i])
+	}
+	twiddles := twiddlesInv(domain, n)
+	for m := n / 2; m >= 1; m >>= 1 {
+		stride := n / (2 * m)
+		parallelize(n/2, func(start, end int) {
+			for t := start; t < end; t++ {
+				i := t/m*2*m + t%m
+				u, v := p[i], p[i+m]
+				p[i].AddAssign(&v)
+				p[i+m] = u
+				p[i+m].SubAssign(&v)
+				if t%m != 0 {
+					p[i+m].ScalarMultiplication(&p[i+m], toBigInt(&twiddles[t%m*stride]))
+				}
+			}
+		})
+	}
+	cardinalityInv := toBigInt(&domain.CardinalityInv)
+	parallelize(n, func(start, end int) {
+		for i := start; i < end; i++ {
+			p[i].ScalarMultiplication(&p[i], cardinalityInv)
+		}
+	})
+	res := toAffineG1(p)
+	bitReverseG1(res)
+	return res
+}
+
+func lagrangeG2(tau []curve.G2Affine, domain *fft.Domain) []curve.G2Affine {
+	n := len(tau)
+	p := make([]curve.G2Jac, n)
+	for i := range tau {
+		p[i].FromAffine(&tau[i])
+	}
+	twiddles := twiddlesInv(domain, n)
+	for m := n / 2; m >= 1; m >>= 1 {
+		stride := n / (2 * m)
+		parallelize(n/2, func(start, end int) {
+			for t := start; t < end; t++ {
+				i := t/m*2*m + t%m
+				u, v := p[i], p[i+m]
+				p[i].AddAssign(&v)
+				p[i+m] = u
+				p[i+m].SubAssign(&v)
+				if t%m != 0 {
+					p[i+m].ScalarMultiplication(&p[i+m], toBigInt(&twiddles[t%m*stride]))
+				}
+			}
+		})
+	}
+	cardinalityInv := toBigInt(&domain.CardinalityInv)
+	parallelize(n, func(start, end int) {
+		for i := start; i < end; i++ {
+			p[i].ScalarMultiplication(&p[i], cardinalityInv)
+		}
+	})
+	res := toAffineG2(p)
+	bitReverseG2(res)
+	return res
+}
+
+/*
+	twiddlesInv: ω⁻ⁱ, i < n/2
+*/
+func twiddlesInv(domain *fft.Domain, n int) []fr.Element {
+	return powers(fr.One(), domain.GeneratorInv, n/2)
+}
+
+func toAffineG1(points []curve.G1Jac) []curve.G1Affine {
+	res := make([]curve.G1Affine, len(points))
+	curve.BatchJacobianToAffineG1(points, res)
+	return res
+}
+
+func toAffineG2(points []curve.G2Jac) []curve.G2Affine {
+	res := make([]curve.G2Affine, len(points))
+	parallelize(len(points), func(start, end int) {
+		for i := start; i < end; i++ {
+			res[i].FromJacobian(&points[i])
+		}
+	})
+	return res
+}
+
+/*
+	Contribute: multiply δ with a random scalar and divide the parameters by
+	it, the scalar is dropped once the contribution is added
+*/
+func (phase2 *Phase2) Contribute() (err error) {
+	challenge, err := phase2.Hash()
+	if err != nil {
+		return err
+	}
+	delta, err := randomNonZero()
+	if err != nil {
+		return err
+	}
+	var contribution Phase2Contribution
+	contribution.Delta, err = newPublicKey(&delta, challenge, dstDelta)
+	if err != nil {
+		return err
+	}
+	params := &phase2.Parameters
+	params.G1.Delta.ScalarMultiplication(&params.G1.Delta, toBigInt(&delta))
+	params.G2.Delta.ScalarMultiplication(&params.G2.Delta, toBigInt(&delta))
+	var deltaInv fr.Element
+	deltaInv.Inverse(&delta)
+	mulG1(params.G1.K, &deltaInv)
+	mulG1(params.G1.Z, &deltaInv)
+
+	contribution.DeltaG1 = params.G1.Delta
+	phase2.Contributions = append(phase2.Contributions, contribution)
+	return nil
+}
+
+func mulG1(points []curve.G1Affine, x *fr.Element) {
+	s := toBigInt(x)
+	parallelize(len(points), func(start, end int) {
+		for i := start; i < end; i++ {
+			points[i].ScalarMultiplication(&points[i], s)
+		}
+	})
+}
+
+/*
+	Verify: the phase 2 is the initial one, with every contribution proving
+	the knowledge of its δ, applied on top of the previous one, and the
+	parameters divided by the resulting δ
+*/
+func (phase2 *Phase2) Verify(initial *Phase2) (err error) {
+	_, _, g1, g2 := curve.Generators()
+	params, initialParams := &phase2.Parameters, &initial.Parameters
+	if len(initial.Contributions) != 0 ||
+		!initialParams.G1.Delta.Equal(&g1) || !initialParams.G2.Delta.Equal(&g2) {
+		log.Println("[Phase2.Verify] invalid initial phase 2")
+		return errInvalidParameters
+	}
+	initialHash, err := initial.parametersHash()
+	if err != nil {
+		return err
+	}
+	if phase2.InitialHash != initialHash ||
+		len(params.G1.K) != len(initialParams.G1.K) || len(params.G1.Z) != len(initialParams.G1.Z) {
+		log.Println("[Phase2.Verify] phase 2 of another circuit")
+		return errInvalidParameters
+	}
+	deltaG1 := g1
+	for i := range phase2.Contributions {
+		challenge, err := phase2.hash(i)
+		if err != nil {
+			return err
+		}
+		contribution := &phase2.Contributions[i]
+		if contribution.Delta.verify(&deltaG1, &contribution.DeltaG1, challenge, dstDelta) != nil {
+			log.Println("[Phase2.Verify] invalid contribution:", i)
+			return errInvalidContribution
+		}
+		deltaG1 = contribution.DeltaG1
+	}
+	if !params.G1.Delta.Equal(&deltaG1) || !sameRatio(&g1, &deltaG1, &g2, &params.G2.Delta) {
+		log.Println("[Phase2.Verify] δ doesn't match the last contribution")
+		return errInvalidParameters
+	}
+	for _, points := range [][2][]curve.G1Affine{
+		{params.G1.K, initialParams.G1.K},
+		{params.G1.Z, initialParams.G1.Z},
+	} {
+		if len(points[0]) == 0 {
+			continue
+		}
+		a, b, err := scaleRatioG1(points[0], points[1])
+		if err != nil {
+			return err
+		}
+		if !sameRatio(&a, &b, &g2, &params.G2.Delta) {
+			log.Println("[Phase2.Verify] parameters aren't divided by δ")
+			return errInvalidParameters
+		}
+	}
+	return nil
+}
+
+/*
+	ExtractKeys: groth16 keys of the last contribution, the phase 2 must be
+	verified first. The evaluations aren't covered by the transcript, they must
+	be the ones InitPhase2 returns with the initial phase 2 of the verification
+*/
+func (phase2 *Phase2) ExtractKeys(evaluations *Phase2Evaluations) (pk groth16.ProvingKey, vk groth16.VerifyingKey, err error) {
+	if evaluations == nil || len(evaluations.G1.A) != len(evaluations.G1.B) ||
+		len(evaluations.G1.A) != len(evaluations.G2.B) ||
+		len(evaluations.G1.A) != len(evaluations.G1.VKK)+len(phase2.Parameters.G1.K) {
+		log.Println("[ExtractKeys] evaluations of another circuit")
+		return nil, nil, errors.New("[ExtractKeys] evaluations of another circuit")
+	}
+	// the keys are decoded by gnark from their encoding, their types are internal
+	pk = groth16.NewProvingKey(ecc.BN254)
+	err = decodeFrom(pk, func(w io.Writer) error {
+		return phase2.writeProvingKey(w, evaluations)
+	})
+	if err != nil {
+		log.Println("[ExtractKeys] unable to decode proving key:", err)
+		return nil, nil, err
+	}
+	vk = groth16.NewVerifyingKey(ecc.BN254)
+	err = decodeFrom(vk, func(w io.Writer) error {
+		return phase2.writeVerifyingKey(w, evaluations)
+	})
+	if err != nil {
+		log.Println("[ExtractKeys] unable to decode verifying key:", err)
+		return nil, nil, err
+	}
+	return pk, vk, nil
+}
+
+func decodeFrom(object io.ReaderFrom, encode func(w io.Writer) error) (err error) {
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(encode(w))
+	}()
+	_, err = object.ReadFrom(r)
+	r.CloseWithError(io.ErrClosedPipe)
+	return err
+}
+
+/*
+	writeProvingKey: encoding of gnark v0.7.0 groth16 proving keys, the wires
+	with [Aᵢ(τ)]₁ or [Bᵢ(τ)]₁ at infinity are flagged and left out
+*/
+func (phase2 *Phase2) writeProvingKey(w io.Writer, evaluations *Phase2Evaluations) (err error) {
+	params := &phase2.Parameters
+	domain := fft.NewDomain(uint64(len(params.G1.Z)))
+	_, err = domain.WriteTo(w)
+	if err != nil {
+		return err
+	}
+	nbWires := len(evaluations.G1.A)
+	infinityA, infinityB := make([]bool, nbWires), make([]bool, nbWires)
+	var (
+		a, b1                    []curve.G1Affine
+		b2                       []curve.G2Affine
+		nbInfinityA, nbInfinityB uint64
+	)
+	for i := 0; i < nbWires; i++ {
+		if evaluations.G1.A[i].IsInfinity() {
+			infinityA[i] = true
+			nbInfinityA++
+		} else {
+			a = append(a, evaluations.G1.A[i])
+		}
+		if evaluations.G1.B[i].IsInfinity() {
+			infinityB[i] = true
+			nbInfinityB++
+		} else {
+			b1 = append(b1, evaluations.G1.B[i])
+			b2 = append(b2, evaluations.G2.B[i])
+		}
+	}
+	enc := curve.NewEncoder(w)
+	for _, v := range []interface{}{
+		&evaluations.G1.Alpha, &evaluations.G1.Beta, &params.G1.Delta,
+		a, b1, params.G1.Z, params.G1.K,
+		&evaluations.G2.Beta, &params.G2.Delta, b2,
+		uint64(nbWires), nbInfinityA, nbInfinityB, infinityA, infinityB,
+	} {
+		err = enc.Encode(v)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+	writeVerifyingKey: encoding of gnark v0.7.0 groth16 verifying keys, [γ]₂ is the generator
+*/
+func (phase2 *Phase2) writeVerifyingKey(w io.Writer, evaluations *Phase2Evaluations) (err error) {
+	params := &phase2.Parameters
+	_, _, _, g2 := curve.Generators()
+	enc := curve.NewEncoder(w)
+	for _, v := range []interface{}{
+		&evaluations.G1.Alpha, &evaluations.G1.Beta, &evaluations.G2.Beta, &g2,
+		&params.G1.Delta, &params.G2.Delta, evaluations.G1.VKK,
+	} {
+		err = enc.Encode(v)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+	Hash: hash of the initial hash and of the contributions, see Phase1.Hash
+*/
+func (phase2 *Phase2) Hash() (hash []byte, err error) {
+	return phase2.hash(len(phase2.Contributions))
+}
+
+func (phase2 *Phase2) hash(contributions int) (hash []byte, err error) {
+	return transcriptHash(phase2.InitialHash[:], contributions, func(i int, enc *curve.Encoder) error {
+		return phase2.Contributions[i].writeTo(enc)
+	})
+}
+
+/*
+	parametersHash: sha256 of the encoded parameters, the initial hash when
+	there isn't any contribution
+*/
+func (phase2 *Phase2) parametersHash() (hash [sha256.Size]byte, err error) {
+	sum, err := transcriptHash(nil, 1, func(_ int, enc *curve.Encoder) error {
+		return phase2.writeParameters(enc)
+	})
+	if err != nil {
+		return hash, err
+	}
+	copy(hash[:], sum)
+	return hash, nil
+}
+
+func (phase2 *Phase2) writeParameters(enc *curve.Encoder) (err error) {
+	params := &phase2.Parameters
+	for _, v := range []interface{}{&params.G1.Delta, params.G1.K, params.G1.Z, &params.G2.Delta} {
+		err = enc.Encode(v)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (contribution *Phase2Contribution) writeTo(enc *curve.Encoder) (err error) {
+	err = contribution.Delta.writeTo(enc)
+	if err != nil {
+		return err
+	}
+	return enc.Encode(&contribution.DeltaG1)
+}
+
+func (contribution *Phase2Contribution) readFrom(dec *curve.Decoder) (err error) {
+	err = contribution.Delta.readFrom(dec)
+	if err != nil {
+		return err
+	}
+	return dec.Decode(&contribution.DeltaG1)
+}
+
+func (phase2 *Phase2) WriteTo(w io.Writer) (n int64, err error) {
+	written, err := w.Write(phase2.InitialHash[:])
+	if err != nil {
+		return int64(written), err
+	}
+	enc := curve.NewEncoder(w)
+	err = phase2.writeParameters(enc)
+	if err != nil {
+		return int64(written) + enc.BytesWritten(), err
+	}
+	err = enc.Encode(uint32(len(phase2.Contributions)))
+	if err != nil {
+		return int64(written) + enc.BytesWritten(), err
+	}
+	for i := range phase2.Contributions {
+		err = phase2.Contributions[i].writeTo(enc)
+		if err != nil {
+			return int64(written) + enc.BytesWritten(), err
+		}
+	}
+	return int64(written) + enc.BytesWritten(), nil
+}
+
+func (phase2 *Phase2) ReadFrom(r io.Reader) (n int64, err error) {
+	read, err := io.ReadFull(r, phase2.InitialHash[:])
+	if err != nil {
+		return int64(read), err
+	}
+	dec := curve.NewDecoder(r)
+	params := &phase2.Parameters
+	var contributions uint32
+	for _, v := range []interface{}{
+		&params.G1.Delta, &params.G1.K, &params.G1.Z, &params.G2.Delta,
+		&contributions,
+	} {
+		err = dec.Decode(v)
+		if err != nil {
+			return int64(read) + dec.BytesRead(), err
+		}
+	}
+	phase2.Contributions = make([]Phase2Contribution, contributions)
+	for i := range phase2.Contributions {
+		err = phase2.Contributions[i].readFrom(dec)
+		if err != nil {
+			return int64(read) + dec.BytesRead(), err
+		}
+	}
+	return int64(read) + dec.BytesRead(), nil
+}
+
+func (evaluations *Phase2Evaluations) WriteTo(w io.Writer) (n int64, err error) {
+	enc := curve.NewEncoder(w)
+	for _, v := range []interface{}{
+		&evaluations.G1.Alpha, &evaluations.G1.Beta, evaluations.G1.A, evaluations.G1.B, evaluations.G1.VKK,
+		&evaluations.G2.Beta, evaluations.G2.B,
+	} {
+		err = enc.Encode(v)
+		if err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+	return enc.BytesWritten(), nil
+}
+
+func (evaluations *Phase2Evaluations) ReadFrom(r io.Reader) (n int64, err error) {
+	dec := curve.NewDecoder(r)
+	for _, v := range []interface{}{
+		&evaluations.G1.Alpha, &evaluations.G1.Beta, &evaluations.G1.A, &evaluations.G1.B, &evaluations.G1.VKK,
+		&evaluations.G2.Beta, &evaluations.G2.B,
+	} {
+		err = dec.Decode(v)
+		if err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+	return dec.BytesRead(), nil
+}
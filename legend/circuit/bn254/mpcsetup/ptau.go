@@ -0,0 +1,303 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package mpcsetup
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+)
+
+// sections of a snarkjs ptau file, in the order they are written
+const (
+	ptauSectionHeader = iota + 1
+	ptauSectionTauG1
+	ptauSectionTauG2
+	ptauSectionAlphaTauG1
+	ptauSectionBetaTauG1
+	ptauSectionBetaG2
+
+	ptauFieldSize = fp.Bytes
+)
+
+var errInvalidPtau = errors.New("invalid ptau file")
+
+/*
+	ImportPhase1: phase 1 from the ptau file of a snarkjs powers of tau
+	ceremony on bn128, for circuits of at most 2^power constraints. A ptau of
+	power p has 2^(p+1)-1 powers of τ in G1, so power is at most p-1, e.g. the
+	phase 1 of power 20 is imported from powersOfTau28_hez_final_21.ptau.
+	The parameters are checked to be powers of the same τ, the contributions
+	of the ceremony are not, they are verified by snarkjs powersoftau verify
+*/
+func ImportPhase1(r io.Reader, power int) (phase1 *Phase1, err error) {
+	if power < 1 || power >= MaxPower {
+		log.Println("[ImportPhase1] invalid power")
+		return nil, errors.New("[ImportPhase1] invalid power")
+	}
+	n := 1 << power
+	reader := &ptauReader{r: bufio.NewReaderSize(r, 1<<20)}
+	var magic [4]byte
+	if _, err = io.ReadFull(reader.r, magic[:]); err != nil || string(magic[:]) != "ptau" {
+		log.Println("[ImportPhase1] not a ptau file")
+		return nil, errInvalidPtau
+	}
+	var version, sections uint32
+	if version, err = reader.uint32(); err != nil {
+		return nil, err
+	}
+	if sections, err = reader.uint32(); err != nil {
+		return nil, err
+	}
+	if version != 1 {
+		log.Println("[ImportPhase1] unsupported ptau version:", version)
+		return nil, errInvalidPtau
+	}
+
+	phase1 = new(Phase1)
+	params := &phase1.Parameters
+	params.G1.Tau = make([]curve.G1Affine, 2*n)
+	params.G1.AlphaTau = make([]curve.G1Affine, n)
+	params.G1.BetaTau = make([]curve.G1Affine, n)
+	params.G2.Tau = make([]curve.G2Affine, n)
+	betaG2 := make([]curve.G2Affine, 1)
+	ptauPower := -1
+	read := make(map[uint32]bool)
+	for i := uint32(0); i < sections; i++ {
+		id, err := reader.uint32()
+		if err != nil {
+			return nil, err
+		}
+		size, err := reader.uint64()
+		if err != nil {
+			return nil, err
+		}
+		if id == ptauSectionHeader {
+			ptauPower, err = reader.readHeader(size)
+			if err != nil {
+				return nil, err
+			}
+			// a phase 1 of power uses 2^(power+1) powers of τ in G1
+			if ptauPower <= power {
+				log.Println("[ImportPhase1] ptau too small for the power:", ptauPower)
+				return nil, errors.New("[ImportPhase1] ptau too small for the power")
+			}
+			read[id] = true
+			continue
+		}
+		if id < ptauSectionTauG1 || id > ptauSectionBetaG2 {
+			if err = reader.skip(size); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if ptauPower < 0 || read[id] {
+			log.Println("[ImportPhase1] unexpected ptau section:", id)
+			return nil, errInvalidPtau
+		}
+		m := uint64(1) << ptauPower
+		switch id {
+		case ptauSectionTauG1:
+			err = reader.readG1(params.G1.Tau, size, 2*m-1)
+		case ptauSectionTauG2:
+			err = reader.readG2(params.G2.Tau, size, m)
+		case ptauSectionAlphaTauG1:
+			err = reader.readG1(params.G1.AlphaTau, size, m)
+		case ptauSectionBetaTauG1:
+			err = reader.readG1(params.G1.BetaTau, size, m)
+		case ptauSectionBetaG2:
+			err = reader.readG2(betaG2, size, 1)
+		}
+		if err != nil {
+			return nil, err
+		}
+		read[id] = true
+	}
+	for id := uint32(ptauSectionHeader); id <= ptauSectionBetaG2; id++ {
+		if !read[id] {
+			log.Println("[ImportPhase1] missing ptau section:", id)
+			return nil, errInvalidPtau
+		}
+	}
+	params.G2.Beta = betaG2[0]
+	phase1.Initial.TauG1 = params.G1.Tau[1]
+	phase1.Initial.AlphaG1 = params.G1.AlphaTau[0]
+	phase1.Initial.BetaG1 = params.G1.BetaTau[0]
+	// without contribution, Verify checks the parameters are powers of the
+	// τ of the ceremony
+	if err = phase1.Verify(phase1); err != nil {
+		return nil, err
+	}
+	return phase1, nil
+}
+
+type ptauReader struct {
+	r *bufio.Reader
+}
+
+func (reader *ptauReader) uint32() (v uint32, err error) {
+	var buf [4]byte
+	if _, err = io.ReadFull(reader.r, buf[:]); err != nil {
+		log.Println("[ImportPhase1] unable to read ptau file:", err)
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(buf[:]), nil
+}
+
+func (reader *ptauReader) uint64() (v uint64, err error) {
+	var buf [8]byte
+	if _, err = io.ReadFull(reader.r, buf[:]); err != nil {
+		log.Println("[ImportPhase1] unable to read ptau file:", err)
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buf[:]), nil
+}
+
+func (reader *ptauReader) skip(size uint64) (err error) {
+	if _, err = io.CopyN(io.Discard, reader.r, int64(size)); err != nil {
+		log.Println("[ImportPhase1] unable to read ptau file:", err)
+		return err
+	}
+	return nil
+}
+
+/*
+	readHeader: the base field of the ceremony must be the one of BN254
+*/
+func (reader *ptauReader) readHeader(size uint64) (power int, err error) {
+	if size != 4+ptauFieldSize+4+4 {
+		log.Println("[ImportPhase1] invalid ptau header size")
+		return 0, errInvalidPtau
+	}
+	n8, err := reader.uint32()
+	if err != nil {
+		return 0, err
+	}
+	var q [ptauFieldSize]byte
+	if _, err = io.ReadFull(reader.r, q[:]); err != nil {
+		log.Println("[ImportPhase1] unable to read ptau file:", err)
+		return 0, err
+	}
+	reverse(q[:])
+	if n8 != ptauFieldSize || new(big.Int).SetBytes(q[:]).Cmp(fp.Modulus()) != 0 {
+		log.Println("[ImportPhase1] ptau of another curve")
+		return 0, errors.New("[ImportPhase1] ptau of another curve")
+	}
+	p, err := reader.uint32()
+	if err != nil {
+		return 0, err
+	}
+	// the ceremony power, which is the largest power of the truncated files
+	if _, err = reader.uint32(); err != nil {
+		return 0, err
+	}
+	if p < 1 || p > MaxPower {
+		log.Println("[ImportPhase1] invalid ptau power:", p)
+		return 0, errInvalidPtau
+	}
+	return int(p), nil
+}
+
+/*
+	readElement: coordinates are little endian in Montgomery form
+*/
+func (reader *ptauReader) readElement(e *fp.Element) (err error) {
+	var buf [ptauFieldSize]byte
+	if _, err = io.ReadFull(reader.r, buf[:]); err != nil {
+		log.Println("[ImportPhase1] unable to read ptau file:", err)
+		return err
+	}
+	for i := range e {
+		e[i] = binary.LittleEndian.Uint64(buf[8*i:])
+	}
+	reverse(buf[:])
+	if new(big.Int).SetBytes(buf[:]).Cmp(fp.Modulus()) >= 0 {
+		log.Println("[ImportPhase1] invalid ptau field element")
+		return errInvalidPtau
+	}
+	return nil
+}
+
+/*
+	readG1, readG2: the first len(points) of the count points of the section,
+	which have to be in the subgroup
+*/
+func (reader *ptauReader) readG1(points []curve.G1Affine, size, count uint64) (err error) {
+	if size != count*2*ptauFieldSize || uint64(len(points)) > count {
+		log.Println("[ImportPhase1] invalid ptau section size")
+		return errInvalidPtau
+	}
+	for i := range points {
+		if err = reader.readElement(&points[i].X); err != nil {
+			return err
+		}
+		if err = reader.readElement(&points[i].Y); err != nil {
+			return err
+		}
+	}
+	if !allInSubGroup(len(points), func(i int) bool { return !points[i].IsInfinity() && points[i].IsInSubGroup() }) {
+		log.Println("[ImportPhase1] invalid ptau G1 point")
+		return errInvalidPtau
+	}
+	return reader.skip((count - uint64(len(points))) * 2 * ptauFieldSize)
+}
+
+func (reader *ptauReader) readG2(points []curve.G2Affine, size, count uint64) (err error) {
+	if size != count*4*ptauFieldSize || uint64(len(points)) > count {
+		log.Println("[ImportPhase1] invalid ptau section size")
+		return errInvalidPtau
+	}
+	for i := range points {
+		for _, e := range []*fp.Element{&points[i].X.A0, &points[i].X.A1, &points[i].Y.A0, &points[i].Y.A1} {
+			if err = reader.readElement(e); err != nil {
+				return err
+			}
+		}
+	}
+	if !allInSubGroup(len(points), func(i int) bool { return !points[i].IsInfinity() && points[i].IsInSubGroup() }) {
+		log.Println("[ImportPhase1] invalid ptau G2 point")
+		return errInvalidPtau
+	}
+	return reader.skip((count - uint64(len(points))) * 4 * ptauFieldSize)
+}
+
+func allInSubGroup(n int, isInSubGroup func(i int) bool) bool {
+	valid := make(chan bool, n)
+	parallelize(n, func(start, end int) {
+		for i := start; i < end; i++ {
+			if !isInSubGroup(i) {
+				valid <- false
+				return
+			}
+		}
+	})
+	close(valid)
+	return len(valid) == 0
+}
+
+func reverse(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
@@ -0,0 +1,289 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package mpcsetup
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"math/bits"
+	"runtime"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+/*
+	PublicKey: proof of knowledge of the secret x of a contribution. S is a
+	random point, SX = x*S and XR = x*R, where R is hashed to G2 from the
+	transcript, S and SX, so the proof can't be replayed in another transcript.
+	The same R and XR prove a point was multiplied by x.
+*/
+type PublicKey struct {
+	S, SX curve.G1Affine
+	XR    curve.G2Affine
+}
+
+var (
+	errInvalidContribution = errors.New("invalid contribution")
+	errInvalidParameters   = errors.New("invalid parameters")
+)
+
+func newPublicKey(x *fr.Element, challenge []byte, dst byte) (pk PublicKey, err error) {
+	s, err := randomNonZero()
+	if err != nil {
+		return pk, err
+	}
+	_, _, g1, _ := curve.Generators()
+	pk.S.ScalarMultiplication(&g1, toBigInt(&s))
+	pk.SX.ScalarMultiplication(&pk.S, toBigInt(x))
+	r, err := pk.challengePoint(challenge, dst)
+	if err != nil {
+		return pk, err
+	}
+	pk.XR.ScalarMultiplication(&r, toBigInt(x))
+	return pk, nil
+}
+
+func (pk *PublicKey) challengePoint(challenge []byte, dst byte) (r curve.G2Affine, err error) {
+	s, sx := pk.S.Bytes(), pk.SX.Bytes()
+	msg := make([]byte, 0, len(challenge)+len(s)+len(sx))
+	msg = append(append(append(msg, challenge...), s[:]...), sx[:]...)
+	return curve.HashToCurveG2Svdw(msg, []byte{dst})
+}
+
+/*
+	verify: the proof of knowledge is valid and next = x * prev
+*/
+func (pk *PublicKey) verify(prev, next *curve.G1Affine, challenge []byte, dst byte) (err error) {
+	if pk.S.IsInfinity() || pk.SX.IsInfinity() || next.IsInfinity() {
+		return errInvalidContribution
+	}
+	r, err := pk.challengePoint(challenge, dst)
+	if err != nil {
+		return err
+	}
+	if !sameRatio(&pk.S, &pk.SX, &r, &pk.XR) || !sameRatio(prev, next, &r, &pk.XR) {
+		return errInvalidContribution
+	}
+	return nil
+}
+
+func (pk *PublicKey) writeTo(enc *curve.Encoder) (err error) {
+	for _, v := range []interface{}{&pk.S, &pk.SX, &pk.XR} {
+		err = enc.Encode(v)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (pk *PublicKey) readFrom(dec *curve.Decoder) (err error) {
+	for _, v := range []interface{}{&pk.S, &pk.SX, &pk.XR} {
+		err = dec.Decode(v)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+	sameRatio: b1/a1 == b2/a2, i.e. e(a1, b2) == e(b1, a2)
+*/
+func sameRatio(a1, b1 *curve.G1Affine, a2, b2 *curve.G2Affine) bool {
+	var negB1 curve.G1Affine
+	negB1.Neg(b1)
+	isOk, err := curve.PairingCheck([]curve.G1Affine{*a1, negB1}, []curve.G2Affine{*b2, *a2})
+	return err == nil && isOk
+}
+
+/*
+	powersRatioG1, powersRatioG2: random linear combinations of the points and
+	of the next points, so that points[i+1]/points[i] is the same ratio for all
+	i if the combinations have that ratio
+*/
+func powersRatioG1(points []curve.G1Affine) (a, b curve.G1Affine, err error) {
+	scalars, err := randomScalars(len(points) - 1)
+	if err != nil {
+		return a, b, err
+	}
+	_, err = a.MultiExp(points[:len(points)-1], scalars, ecc.MultiExpConfig{})
+	if err != nil {
+		return a, b, err
+	}
+	_, err = b.MultiExp(points[1:], scalars, ecc.MultiExpConfig{})
+	return a, b, err
+}
+
+func powersRatioG2(points []curve.G2Affine) (a, b curve.G2Affine, err error) {
+	scalars, err := randomScalars(len(points) - 1)
+	if err != nil {
+		return a, b, err
+	}
+	_, err = a.MultiExp(points[:len(points)-1], scalars, ecc.MultiExpConfig{})
+	if err != nil {
+		return a, b, err
+	}
+	_, err = b.MultiExp(points[1:], scalars, ecc.MultiExpConfig{})
+	return a, b, err
+}
+
+/*
+	scaleRatioG1: random linear combinations of the points before and after
+	they are all multiplied by the same scalar
+*/
+func scaleRatioG1(prev, next []curve.G1Affine) (a, b curve.G1Affine, err error) {
+	scalars, err := randomScalars(len(prev))
+	if err != nil {
+		return a, b, err
+	}
+	_, err = a.MultiExp(prev, scalars, ecc.MultiExpConfig{})
+	if err != nil {
+		return a, b, err
+	}
+	_, err = b.MultiExp(next, scalars, ecc.MultiExpConfig{})
+	return a, b, err
+}
+
+func randomScalars(n int) (scalars []fr.Element, err error) {
+	scalars = make([]fr.Element, n)
+	for i := range scalars {
+		_, err = scalars[i].SetRandom()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return scalars, nil
+}
+
+func randomNonZero() (x fr.Element, err error) {
+	for x.IsZero() {
+		_, err = x.SetRandom()
+		if err != nil {
+			return x, err
+		}
+	}
+	return x, nil
+}
+
+func toBigInt(x *fr.Element) *big.Int {
+	var b big.Int
+	return x.ToBigIntRegular(&b)
+}
+
+/*
+	powers: 1, x, x^2, ..., x^(n-1) multiplied by start
+*/
+func powers(start, x fr.Element, n int) []fr.Element {
+	res := make([]fr.Element, n)
+	if n == 0 {
+		return res
+	}
+	res[0] = start
+	for i := 1; i < n; i++ {
+		res[i].Mul(&res[i-1], &x)
+	}
+	return res
+}
+
+func scaleG1(points []curve.G1Affine, scalars []fr.Element) {
+	parallelize(len(points), func(start, end int) {
+		for i := start; i < end; i++ {
+			points[i].ScalarMultiplication(&points[i], toBigInt(&scalars[i]))
+		}
+	})
+}
+
+func scaleG2(points []curve.G2Affine, scalars []fr.Element) {
+	parallelize(len(points), func(start, end int) {
+		for i := start; i < end; i++ {
+			points[i].ScalarMultiplication(&points[i], toBigInt(&scalars[i]))
+		}
+	})
+}
+
+/*
+	parallelize: run work on [0, n) split in one range per cpu
+*/
+func parallelize(n int, work func(start, end int)) {
+	nbTasks := runtime.NumCPU()
+	if nbTasks > n {
+		nbTasks = n
+	}
+	if nbTasks <= 1 {
+		work(0, n)
+		return
+	}
+	var wg sync.WaitGroup
+	size := (n + nbTasks - 1) / nbTasks
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			work(start, end)
+		}(start, end)
+	}
+	wg.Wait()
+}
+
+func bitReverseG1(a []curve.G1Affine) {
+	n := uint(len(a))
+	nn := uint(bits.UintSize - bits.TrailingZeros(n))
+	for i := uint(0); i < n; i++ {
+		irev := bits.Reverse(i) >> nn
+		if irev > i {
+			a[i], a[irev] = a[irev], a[i]
+		}
+	}
+}
+
+func bitReverseG2(a []curve.G2Affine) {
+	n := uint(len(a))
+	nn := uint(bits.UintSize - bits.TrailingZeros(n))
+	for i := uint(0); i < n; i++ {
+		irev := bits.Reverse(i) >> nn
+		if irev > i {
+			a[i], a[irev] = a[irev], a[i]
+		}
+	}
+}
+
+/*
+	transcriptHash: sha256 of the seed and of the encoded contributions, the
+	challenge of the next contribution
+*/
+func transcriptHash(seed []byte, contributions int, writeContribution func(i int, enc *curve.Encoder) error) (hash []byte, err error) {
+	h := sha256.New()
+	h.Write(seed)
+	enc := curve.NewEncoder(h)
+	for i := 0; i < contributions; i++ {
+		err = writeContribution(i, enc)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,601 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prover
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	kzg_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+)
+
+/*
+	A bundle is a directory with the compiled block or exodus circuit, its keys and a
+	manifest of the parameters they were built with and the sha256 of the files.
+	Files are only read if the manifest matches the parameters of this build and
+	the file matches its hash, so a key can't be used with another circuit.
+*/
+
+const (
+	BundleVersion = 2
+
+	CircuitBlock     = "block"
+	CircuitExodus    = "exodus"
+	CircuitExodusNft = "exodusNft"
+
+	ManifestFile            = "manifest.json"
+	CompiledConstraintsFile = "block.r1cs"
+	ProvingKeyFile          = "block.pk"
+	VerifyingKeyFile        = "block.vk"
+	// KZG SRS of the plonk keys
+	SRSFile = "block.srs"
+
+	gnarkModulePath = "github.com/consensys/gnark"
+)
+
+type Manifest struct {
+	Version int
+	// block, exodus or exodusNft
+	Circuit string
+	// sha256 of the compiled constraint system
+	CircuitHash string
+	// block.CircuitVersion of the compiled constraint system
+	CircuitVersion        int
+	TxsCount              int
+	SlotTypes             []string
+	AccountMerkleLevels   int
+	AssetMerkleLevels     int
+	LiquidityMerkleLevels int
+	NftMerkleLevels       int
+	GnarkVersion          string
+	Curve                 string
+	Backend               string
+	// a single public input, see block.CompressedBlockConstraints
+	CompressedPublicInputs bool
+	// sha256 of the files of the bundle
+	Files map[string]string
+}
+
+/*
+	NewManifest: manifest of a block circuit with the slot types, compiled for
+	the backend with the tree depths and gnark version of this build
+*/
+func NewManifest(backendName string, slotTypes []int, compressed bool) (manifest *Manifest, err error) {
+	err = CheckBackend(backendName)
+	if err != nil {
+		return nil, err
+	}
+	manifest = &Manifest{
+		Version:                BundleVersion,
+		Circuit:                CircuitBlock,
+		CircuitVersion:         block.CircuitVersion,
+		TxsCount:               len(slotTypes),
+		AccountMerkleLevels:    block.AccountMerkleLevels,
+		AssetMerkleLevels:      block.AssetMerkleLevels,
+		LiquidityMerkleLevels:  block.LiquidityMerkleLevels,
+		NftMerkleLevels:        block.NftMerkleLevels,
+		GnarkVersion:           gnarkVersion(),
+		Curve:                  ecc.BN254.String(),
+		Backend:                backendName,
+		CompressedPublicInputs: compressed,
+		Files:                  make(map[string]string),
+	}
+	for _, slotType := range slotTypes {
+		name, isExist := block.TxSlotTypeNames[slotType]
+		if !isExist {
+			log.Println("[NewManifest] invalid slot type")
+			return nil, errors.New("[NewManifest] invalid slot type")
+		}
+		manifest.SlotTypes = append(manifest.SlotTypes, name)
+	}
+	return manifest, nil
+}
+
+/*
+	NewExodusManifest: manifest of an exodus circuit, which has no tx slots
+*/
+func NewExodusManifest(backendName string, circuit string) (manifest *Manifest, err error) {
+	err = CheckBackend(backendName)
+	if err != nil {
+		return nil, err
+	}
+	if circuit != CircuitExodus && circuit != CircuitExodusNft {
+		log.Println("[NewExodusManifest] invalid exodus circuit:", circuit)
+		return nil, errors.New("[NewExodusManifest] invalid exodus circuit")
+	}
+	return &Manifest{
+		Version:               BundleVersion,
+		Circuit:               circuit,
+		CircuitVersion:        block.CircuitVersion,
+		AccountMerkleLevels:   block.AccountMerkleLevels,
+		AssetMerkleLevels:     block.AssetMerkleLevels,
+		LiquidityMerkleLevels: block.LiquidityMerkleLevels,
+		NftMerkleLevels:       block.NftMerkleLevels,
+		GnarkVersion:          gnarkVersion(),
+		Curve:                 ecc.BN254.String(),
+		Backend:               backendName,
+		Files:                 make(map[string]string),
+	}, nil
+}
+
+/*
+	Check: the manifest is built with the same parameters as the expected one,
+	the hashes aren't compared
+*/
+func (manifest *Manifest) Check(expected *Manifest) (err error) {
+	mismatch := func(field string, value, expectedValue interface{}) error {
+		log.Printf("[Check] %s of the bundle is %v, expected %v\n", field, value, expectedValue)
+		return fmt.Errorf("[Check] mismatched %s: %v, expected %v", field, value, expectedValue)
+	}
+	switch {
+	case manifest.Version != expected.Version:
+		return mismatch("version", manifest.Version, expected.Version)
+	case manifest.Circuit != expected.Circuit:
+		return mismatch("circuit", manifest.Circuit, expected.Circuit)
+	case manifest.CircuitVersion != expected.CircuitVersion:
+		return mismatch("circuit version", manifest.CircuitVersion, expected.CircuitVersion)
+	case manifest.Curve != expected.Curve:
+		return mismatch("curve", manifest.Curve, expected.Curve)
+	case manifest.Backend != expected.Backend:
+		return mismatch("backend", manifest.Backend, expected.Backend)
+	case manifest.GnarkVersion != expected.GnarkVersion:
+		return mismatch("gnark version", manifest.GnarkVersion, expected.GnarkVersion)
+	case manifest.AccountMerkleLevels != expected.AccountMerkleLevels:
+		return mismatch("AccountMerkleLevels", manifest.AccountMerkleLevels, expected.AccountMerkleLevels)
+	case manifest.AssetMerkleLevels != expected.AssetMerkleLevels:
+		return mismatch("AssetMerkleLevels", manifest.AssetMerkleLevels, expected.AssetMerkleLevels)
+	case manifest.LiquidityMerkleLevels != expected.LiquidityMerkleLevels:
+		return mismatch("LiquidityMerkleLevels", manifest.LiquidityMerkleLevels, expected.LiquidityMerkleLevels)
+	case manifest.NftMerkleLevels != expected.NftMerkleLevels:
+		return mismatch("NftMerkleLevels", manifest.NftMerkleLevels, expected.NftMerkleLevels)
+	case manifest.CompressedPublicInputs != expected.CompressedPublicInputs:
+		return mismatch("CompressedPublicInputs", manifest.CompressedPublicInputs, expected.CompressedPublicInputs)
+	case manifest.TxsCount != expected.TxsCount || len(manifest.SlotTypes) != len(expected.SlotTypes):
+		return mismatch("TxsCount", manifest.TxsCount, expected.TxsCount)
+	}
+	for i := range manifest.SlotTypes {
+		if manifest.SlotTypes[i] != expected.SlotTypes[i] {
+			return mismatch("slot types", manifest.SlotTypes, expected.SlotTypes)
+		}
+	}
+	return nil
+}
+
+/*
+	gnarkVersion: version of the gnark module the binary is built with
+*/
+func gnarkVersion() string {
+	info, isOk := debug.ReadBuildInfo()
+	if !isOk {
+		return "unknown"
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == gnarkModulePath {
+			if dep.Replace != nil {
+				return dep.Replace.Path + "@" + dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return "unknown"
+}
+
+type Bundle struct {
+	Dir      string
+	Manifest *Manifest
+
+	// read once for both keys
+	srs kzg.SRS
+}
+
+/*
+	ReadBundle: read the manifest of the bundle, the bundle must be built with
+	the parameters of this build, the files are checked when they're read
+*/
+func ReadBundle(dir string) (bundle *Bundle, err error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		log.Println("[ReadBundle] unable to read manifest:", err)
+		return nil, err
+	}
+	var manifest *Manifest
+	err = json.Unmarshal(data, &manifest)
+	if err != nil || manifest == nil {
+		log.Println("[ReadBundle] invalid manifest:", err)
+		return nil, errors.New("[ReadBundle] invalid manifest")
+	}
+	bundle = &Bundle{Dir: dir, Manifest: manifest}
+	var expected *Manifest
+	if manifest.Circuit == CircuitBlock {
+		slotTypes, err := bundle.SlotTypes()
+		if err != nil {
+			return nil, err
+		}
+		expected, err = NewManifest(manifest.Backend, slotTypes, manifest.CompressedPublicInputs)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		expected, err = NewExodusManifest(manifest.Backend, manifest.Circuit)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = manifest.Check(expected)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.CircuitHash == "" || manifest.CircuitHash != manifest.Files[CompiledConstraintsFile] {
+		log.Println("[ReadBundle] invalid circuit hash")
+		return nil, errors.New("[ReadBundle] invalid circuit hash")
+	}
+	return bundle, nil
+}
+
+/*
+	LoadOrCompileBundle: read the bundle and its compiled circuit from the
+	directory, the circuit is compiled and the bundle created if there is no
+	manifest yet. An existing bundle of another backend, other slot types or
+	other public inputs isn't replaced.
+*/
+func LoadOrCompileBundle(
+	dir string, backendName string, slotTypes []int, compressed bool,
+) (bundle *Bundle, ccs frontend.CompiledConstraintSystem, err error) {
+	manifest, err := NewManifest(backendName, slotTypes, compressed)
+	if err != nil {
+		return nil, nil, err
+	}
+	return loadOrCompileBundle(dir, manifest, func() (frontend.CompiledConstraintSystem, error) {
+		return CompileBlockConstraints(backendName, slotTypes, compressed)
+	})
+}
+
+/*
+	LoadOrCompileExodusBundle: same as LoadOrCompileBundle for the exodus circuits
+*/
+func LoadOrCompileExodusBundle(
+	dir string, backendName string, circuit string,
+) (bundle *Bundle, ccs frontend.CompiledConstraintSystem, err error) {
+	manifest, err := NewExodusManifest(backendName, circuit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return loadOrCompileBundle(dir, manifest, func() (frontend.CompiledConstraintSystem, error) {
+		return CompileExodusConstraints(backendName, circuit)
+	})
+}
+
+func loadOrCompileBundle(
+	dir string, manifest *Manifest, compileCircuit func() (frontend.CompiledConstraintSystem, error),
+) (bundle *Bundle, ccs frontend.CompiledConstraintSystem, err error) {
+	_, err = os.Stat(filepath.Join(dir, ManifestFile))
+	if err == nil {
+		bundle, err = ReadBundle(dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		err = bundle.Manifest.Check(manifest)
+		if err != nil {
+			return nil, nil, err
+		}
+		ccs, err = bundle.ReadCompiledConstraints()
+		if err != nil {
+			return nil, nil, err
+		}
+		return bundle, ccs, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		log.Println("[loadOrCompileBundle] unable to read manifest:", err)
+		return nil, nil, err
+	}
+	ccs, err = compileCircuit()
+	if err != nil {
+		return nil, nil, err
+	}
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		log.Println("[loadOrCompileBundle] unable to create bundle:", err)
+		return nil, nil, err
+	}
+	bundle = &Bundle{Dir: dir, Manifest: manifest}
+	err = bundle.writeFile(CompiledConstraintsFile, ccs)
+	if err != nil {
+		return nil, nil, err
+	}
+	manifest.CircuitHash = manifest.Files[CompiledConstraintsFile]
+	err = bundle.writeManifest()
+	if err != nil {
+		return nil, nil, err
+	}
+	return bundle, ccs, nil
+}
+
+/*
+	CheckCircuit: the bundle is built for the circuit, block commands can't
+	use an exodus bundle and conversely
+*/
+func (bundle *Bundle) CheckCircuit(circuit string) (err error) {
+	if bundle.Manifest.Circuit != circuit {
+		log.Printf("[CheckCircuit] bundle is built for the %s circuit, expected %s\n", bundle.Manifest.Circuit, circuit)
+		return fmt.Errorf("[CheckCircuit] bundle is built for the %s circuit, expected %s", bundle.Manifest.Circuit, circuit)
+	}
+	return nil
+}
+
+/*
+	SlotTypes: slot types of the block circuit of the bundle
+*/
+func (bundle *Bundle) SlotTypes() (slotTypes []int, err error) {
+	for _, name := range bundle.Manifest.SlotTypes {
+		slotType, err := block.ParseTxSlotType(name)
+		if err != nil {
+			log.Println("[SlotTypes] invalid slot type:", err)
+			return nil, err
+		}
+		slotTypes = append(slotTypes, slotType)
+	}
+	return slotTypes, nil
+}
+
+/*
+	Check: the bundle is built for the backend, the slot types and the public inputs
+*/
+func (bundle *Bundle) Check(backendName string, slotTypes []int, compressed bool) (err error) {
+	expected, err := NewManifest(backendName, slotTypes, compressed)
+	if err != nil {
+		return err
+	}
+	return bundle.Manifest.Check(expected)
+}
+
+func (bundle *Bundle) ReadCompiledConstraints() (ccs frontend.CompiledConstraintSystem, err error) {
+	ccs, err = newCompiledConstraints(bundle.Manifest.Backend)
+	if err != nil {
+		return nil, err
+	}
+	err = bundle.readFile(CompiledConstraintsFile, ccs)
+	if err != nil {
+		return nil, err
+	}
+	return ccs, nil
+}
+
+/*
+	ReadProvingKey, ReadVerifyingKey: keys of the bundle, the plonk keys are
+	initialized with the SRS of the bundle
+*/
+func (bundle *Bundle) ReadProvingKey() (pk ProvingKey, err error) {
+	if bundle.Manifest.Backend == BackendPlonk {
+		return bundle.setupPlonkProvingKey()
+	}
+	pk, err = newProvingKey(bundle.Manifest.Backend)
+	if err != nil {
+		return nil, err
+	}
+	err = bundle.readFile(ProvingKeyFile, pk)
+	if err != nil {
+		return nil, err
+	}
+	return pk, nil
+}
+
+/*
+	setupPlonkProvingKey: a plonk proving key read by gnark v0.7.0 lacks the
+	permutation evaluations, which aren't serialized, and can't prove. The
+	setup is deterministic, so the key is derived again from the circuit and
+	the SRS of the bundle and must match the hash of the key written at setup.
+*/
+func (bundle *Bundle) setupPlonkProvingKey() (pk ProvingKey, err error) {
+	expectedHash, isExist := bundle.Manifest.Files[ProvingKeyFile]
+	if !isExist {
+		log.Println("[setupPlonkProvingKey] file not in bundle:", ProvingKeyFile)
+		return nil, fmt.Errorf("[setupPlonkProvingKey] file not in bundle: %s", ProvingKeyFile)
+	}
+	ccs, err := bundle.ReadCompiledConstraints()
+	if err != nil {
+		return nil, err
+	}
+	err = bundle.readSRS()
+	if err != nil {
+		return nil, err
+	}
+	pk, _, err = Setup(BackendPlonk, ccs, bundle.srs)
+	if err != nil {
+		return nil, err
+	}
+	keyHash := sha256.New()
+	_, err = pk.WriteTo(keyHash)
+	if err != nil {
+		log.Println("[setupPlonkProvingKey] unable to hash proving key:", err)
+		return nil, err
+	}
+	if hex.EncodeToString(keyHash.Sum(nil)) != expectedHash {
+		log.Println("[setupPlonkProvingKey] proving key doesn't match the bundle")
+		return nil, errors.New("[setupPlonkProvingKey] proving key doesn't match the bundle")
+	}
+	return pk, nil
+}
+
+func (bundle *Bundle) ReadVerifyingKey() (vk VerifyingKey, err error) {
+	vk, err = newVerifyingKey(bundle.Manifest.Backend)
+	if err != nil {
+		return nil, err
+	}
+	err = bundle.readFile(VerifyingKeyFile, vk)
+	if err != nil {
+		return nil, err
+	}
+	if plonkVk, isPlonk := vk.(plonk.VerifyingKey); isPlonk {
+		err = bundle.initKZG(plonkVk)
+		if err != nil {
+			return nil, err
+		}
+		err = setCosetShift(plonkVk)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return vk, nil
+}
+
+/*
+	setCosetShift: gnark v0.7.0 doesn't serialize the coset shift of plonk
+	verifying keys, which is the multiplicative generator of Fr set by the
+	setup. The key type is internal to gnark, so it's set by reflection.
+*/
+func setCosetShift(vk plonk.VerifyingKey) (err error) {
+	v := reflect.ValueOf(vk)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		log.Println("[setCosetShift] invalid verifying key")
+		return errors.New("[setCosetShift] invalid verifying key")
+	}
+	cosetShift := v.FieldByName("CosetShift")
+	if !cosetShift.IsValid() || !cosetShift.CanSet() || cosetShift.Type() != reflect.TypeOf(fr.Element{}) {
+		log.Println("[setCosetShift] invalid verifying key")
+		return errors.New("[setCosetShift] invalid verifying key")
+	}
+	cosetShift.Set(reflect.ValueOf(fft.NewDomain(1).FrMultiplicativeGen))
+	return nil
+}
+
+/*
+	initKZG: the KZG SRS isn't serialized with the plonk keys
+*/
+func (bundle *Bundle) initKZG(key interface{ InitKZG(srs kzg.SRS) error }) (err error) {
+	err = bundle.readSRS()
+	if err != nil {
+		return err
+	}
+	err = key.InitKZG(bundle.srs)
+	if err != nil {
+		log.Println("[initKZG] invalid srs:", err)
+		return err
+	}
+	return nil
+}
+
+func (bundle *Bundle) readSRS() (err error) {
+	if bundle.srs != nil {
+		return nil
+	}
+	srs := new(kzg_bn254.SRS)
+	err = bundle.readFile(SRSFile, srs)
+	if err != nil {
+		return err
+	}
+	bundle.srs = srs
+	return nil
+}
+
+/*
+	WriteKeys: add the keys of the compiled circuit to the bundle, keys of a
+	previous setup are replaced. The SRS the plonk keys are built from is
+	added too, it's not used by groth16 and may be nil.
+*/
+func (bundle *Bundle) WriteKeys(pk ProvingKey, vk VerifyingKey, srs kzg.SRS) (err error) {
+	if pk == nil || vk == nil || (bundle.Manifest.Backend == BackendPlonk && srs == nil) {
+		log.Println("[WriteKeys] invalid params")
+		return errors.New("[WriteKeys] invalid params")
+	}
+	// the old keys are unusable as soon as one of them is replaced
+	delete(bundle.Manifest.Files, ProvingKeyFile)
+	delete(bundle.Manifest.Files, VerifyingKeyFile)
+	delete(bundle.Manifest.Files, SRSFile)
+	bundle.srs = nil
+	err = bundle.writeManifest()
+	if err != nil {
+		return err
+	}
+	err = bundle.writeFile(ProvingKeyFile, pk)
+	if err != nil {
+		return err
+	}
+	err = bundle.writeFile(VerifyingKeyFile, vk)
+	if err != nil {
+		return err
+	}
+	if bundle.Manifest.Backend == BackendPlonk {
+		err = bundle.writeFile(SRSFile, srs)
+		if err != nil {
+			return err
+		}
+	}
+	return bundle.writeManifest()
+}
+
+func (bundle *Bundle) writeFile(name string, object io.WriterTo) (err error) {
+	fileHash, err := writeFile(filepath.Join(bundle.Dir, name), object)
+	if err != nil {
+		return err
+	}
+	bundle.Manifest.Files[name] = hex.EncodeToString(fileHash)
+	return nil
+}
+
+/*
+	readFile: read a file of the bundle, it must be in the manifest and match its hash
+*/
+func (bundle *Bundle) readFile(name string, object io.ReaderFrom) (err error) {
+	expectedHash, isExist := bundle.Manifest.Files[name]
+	if !isExist {
+		log.Println("[readFile] file not in bundle:", name)
+		return fmt.Errorf("[readFile] file not in bundle: %s", name)
+	}
+	fileHash, err := readFileHash(filepath.Join(bundle.Dir, name), object)
+	if err != nil {
+		return err
+	}
+	if hex.EncodeToString(fileHash) != expectedHash {
+		log.Println("[readFile] invalid file hash:", name)
+		return fmt.Errorf("[readFile] invalid file hash: %s", name)
+	}
+	return nil
+}
+
+func (bundle *Bundle) writeManifest() (err error) {
+	data, err := json.MarshalIndent(bundle.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(bundle.Dir, ManifestFile), data, 0644)
+	if err != nil {
+		log.Println("[writeManifest] unable to write manifest:", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prover
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+)
+
+func TestBundle(t *testing.T) {
+	dir := t.TempDir()
+	slotTypes := []int{block.TxSlotTypePriorityOp}
+	bundle, ccs, err := LoadOrCompileBundle(dir, BackendGroth16, slotTypes, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bundle.Manifest.TxsCount != 1 || bundle.Manifest.GnarkVersion == "unknown" ||
+		bundle.Manifest.CircuitHash != bundle.Manifest.Files[CompiledConstraintsFile] {
+		t.Fatal("invalid manifest")
+	}
+	// the compiled circuit is read from the bundle
+	r1csPath := filepath.Join(dir, CompiledConstraintsFile)
+	info, err := os.Stat(r1csPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, cachedCcs, err := LoadOrCompileBundle(dir, BackendGroth16, slotTypes, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cachedInfo, err := os.Stat(r1csPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cachedInfo.ModTime().Equal(info.ModTime()) || cachedCcs.GetNbConstraints() != ccs.GetNbConstraints() {
+		t.Fatal("circuit compiled again")
+	}
+	if _, _, err = LoadOrCompileBundle(dir, BackendGroth16, []int{block.TxSlotTypeL2Asset}, false); err == nil {
+		t.Fatal("bundle of another layout loaded")
+	}
+	if _, _, err = LoadOrCompileBundle(dir, BackendPlonk, slotTypes, false); err == nil {
+		t.Fatal("bundle of another backend loaded")
+	}
+	if _, _, err = LoadOrCompileBundle(dir, BackendGroth16, slotTypes, true); err == nil {
+		t.Fatal("bundle of other public inputs loaded")
+	}
+	if _, err = bundle.ReadProvingKey(); err == nil {
+		t.Fatal("proving key read before setup")
+	}
+
+	// a bundle built with other parameters isn't read
+	manifestPath := filepath.Join(dir, ManifestFile)
+	manifestData, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, update := range []func(manifest *Manifest){
+		func(manifest *Manifest) { manifest.GnarkVersion = "v0.6.4" },
+		func(manifest *Manifest) { manifest.CircuitVersion-- },
+		func(manifest *Manifest) { manifest.CircuitVersion-- },
+		func(manifest *Manifest) { manifest.AccountMerkleLevels = 24 },
+		func(manifest *Manifest) { manifest.Backend = "marlin" },
+		func(manifest *Manifest) { manifest.CircuitHash = manifest.Files[ManifestFile] },
+	} {
+		var manifest *Manifest
+		if err = json.Unmarshal(manifestData, &manifest); err != nil {
+			t.Fatal(err)
+		}
+		update(manifest)
+		data, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(manifestPath, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = ReadBundle(dir); err == nil {
+			t.Fatal("mismatched bundle read")
+		}
+	}
+	if err = os.WriteFile(manifestPath, manifestData, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// a file which doesn't match its hash isn't read
+	f, err := os.OpenFile(r1csPath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = f.Write([]byte{0}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if bundle, err = ReadBundle(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = bundle.ReadCompiledConstraints(); err == nil {
+		t.Fatal("modified circuit read")
+	}
+}
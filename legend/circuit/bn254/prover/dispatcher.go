@@ -0,0 +1,396 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prover
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"text/template"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark/backend/groth16"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+)
+
+/*
+	groth16VerifyingKey: points of a groth16 verifying key, the key type is
+	internal to gnark so they're decoded from its encoding
+*/
+type groth16VerifyingKey struct {
+	Size int
+	G1   struct {
+		Alpha, Beta, Delta curve.G1Affine
+		K                  []curve.G1Affine
+	}
+	G2 struct {
+		Beta, Gamma, Delta curve.G2Affine
+	}
+}
+
+func decodeGroth16VerifyingKey(size int, vk groth16.VerifyingKey) (key *groth16VerifyingKey, err error) {
+	var buf bytes.Buffer
+	_, err = vk.WriteRawTo(&buf)
+	if err != nil {
+		log.Println("[decodeGroth16VerifyingKey] unable to encode verifying key:", err)
+		return nil, err
+	}
+	key = &groth16VerifyingKey{Size: size}
+	dec := curve.NewDecoder(&buf)
+	for _, v := range []interface{}{
+		&key.G1.Alpha, &key.G1.Beta, &key.G2.Beta, &key.G2.Gamma, &key.G1.Delta, &key.G2.Delta, &key.G1.K,
+	} {
+		err = dec.Decode(v)
+		if err != nil {
+			log.Println("[decodeGroth16VerifyingKey] invalid verifying key:", err)
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+/*
+	ExportSolidity: one verifier contract for all the bundles of the registry,
+	verifyBlock(size, proof, inputs) checks the proof with the verifying key
+	of the bundle of TxsCount size, which are all embedded in the contract
+*/
+func (registry *Registry) ExportSolidity(w io.Writer) (err error) {
+	if registry.Backend != BackendGroth16 {
+		log.Println("[ExportSolidity] dispatcher contract is only supported for groth16")
+		return errors.New("[ExportSolidity] dispatcher contract is only supported for groth16")
+	}
+	keys := make([]*groth16VerifyingKey, len(registry.bundles))
+	for i, bundle := range registry.bundles {
+		vk, err := bundle.ReadVerifyingKey()
+		if err != nil {
+			return err
+		}
+		groth16Vk, isOk := vk.(groth16.VerifyingKey)
+		if !isOk {
+			return errors.New("[ExportSolidity] invalid groth16 verifying key")
+		}
+		keys[i], err = decodeGroth16VerifyingKey(bundle.Manifest.TxsCount, groth16Vk)
+		if err != nil {
+			return err
+		}
+	}
+	return exportDispatcherSolidity(keys, registry.Compressed, w)
+}
+
+/*
+	dispatcherParams: a compressed dispatcher also has verifyCompressedBlock,
+	which computes the public input hash from the block
+*/
+type dispatcherParams struct {
+	Keys                    []*groth16VerifyingKey
+	Compressed              bool
+	PublicInputHashBitsSize int
+}
+
+func exportDispatcherSolidity(keys []*groth16VerifyingKey, compressed bool, w io.Writer) (err error) {
+	tmpl, err := template.New("").Parse(dispatcherTemplate)
+	if err == nil {
+		_, err = tmpl.Parse(publicInputHashTemplate)
+	}
+	if err != nil {
+		return err
+	}
+	err = tmpl.Execute(w, dispatcherParams{
+		Keys:                    keys,
+		Compressed:              compressed,
+		PublicInputHashBitsSize: block.PublicInputHashBitsSize,
+	})
+	if err != nil {
+		log.Println("[exportDispatcherSolidity] unable to export dispatcher contract:", err)
+		return err
+	}
+	return nil
+}
+
+/*
+	dispatcherTemplate: the Pairing library and the checks of verifyProof are
+	the ones of the gnark groth16 verifier contract, the verifying key is
+	selected by the block size. The proof is a, b, c of verifyProof flattened:
+	[a0, a1, b00, b01, b10, b11, c0, c1].
+*/
+const dispatcherTemplate = `
+// SPDX-License-Identifier: AML
+//
+// Copyright 2017 Christian Reitwiessner
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+// 2019 OKIMS
+
+pragma solidity ^0.8.0;
+
+library Pairing {
+
+    uint256 constant PRIME_Q = 21888242871839275222246405745257275088696311157297823662689037894645226208583;
+
+    struct G1Point {
+        uint256 X;
+        uint256 Y;
+    }
+
+    // Encoding of field elements is: X[0] * z + X[1]
+    struct G2Point {
+        uint256[2] X;
+        uint256[2] Y;
+    }
+
+    /*
+     * @return The negation of p, i.e. p.plus(p.negate()) should be zero.
+     */
+    function negate(G1Point memory p) internal pure returns (G1Point memory) {
+
+        // The prime q in the base field F_q for G1
+        if (p.X == 0 && p.Y == 0) {
+            return G1Point(0, 0);
+        } else {
+            return G1Point(p.X, PRIME_Q - (p.Y % PRIME_Q));
+        }
+    }
+
+    /*
+     * @return The sum of two points of G1
+     */
+    function plus(
+        G1Point memory p1,
+        G1Point memory p2
+    ) internal view returns (G1Point memory r) {
+
+        uint256[4] memory input;
+        input[0] = p1.X;
+        input[1] = p1.Y;
+        input[2] = p2.X;
+        input[3] = p2.Y;
+        bool success;
+
+        // solium-disable-next-line security/no-inline-assembly
+        assembly {
+            success := staticcall(sub(gas(), 2000), 6, input, 0xc0, r, 0x60)
+            // Use "invalid" to make gas estimation work
+            switch success case 0 { invalid() }
+        }
+
+        require(success,"pairing-add-failed");
+    }
+
+    /*
+     * @return The product of a point on G1 and a scalar, i.e.
+     *         p == p.scalar_mul(1) and p.plus(p) == p.scalar_mul(2) for all
+     *         points p.
+     */
+    function scalar_mul(G1Point memory p, uint256 s) internal view returns (G1Point memory r) {
+
+        uint256[3] memory input;
+        input[0] = p.X;
+        input[1] = p.Y;
+        input[2] = s;
+        bool success;
+        // solium-disable-next-line security/no-inline-assembly
+        assembly {
+            success := staticcall(sub(gas(), 2000), 7, input, 0x80, r, 0x60)
+            // Use "invalid" to make gas estimation work
+            switch success case 0 { invalid() }
+        }
+        require (success,"pairing-mul-failed");
+    }
+
+    /* @return The result of computing the pairing check
+     *         e(p1[0], p2[0]) *  .... * e(p1[n], p2[n]) == 1
+     *         For example,
+     *         pairing([P1(), P1().negate()], [P2(), P2()]) should return true.
+     */
+    function pairing(
+        G1Point memory a1,
+        G2Point memory a2,
+        G1Point memory b1,
+        G2Point memory b2,
+        G1Point memory c1,
+        G2Point memory c2,
+        G1Point memory d1,
+        G2Point memory d2
+    ) internal view returns (bool) {
+
+        G1Point[4] memory p1 = [a1, b1, c1, d1];
+        G2Point[4] memory p2 = [a2, b2, c2, d2];
+        uint256 inputSize = 24;
+        uint256[] memory input = new uint256[](inputSize);
+
+        for (uint256 i = 0; i < 4; i++) {
+            uint256 j = i * 6;
+            input[j + 0] = p1[i].X;
+            input[j + 1] = p1[i].Y;
+            input[j + 2] = p2[i].X[0];
+            input[j + 3] = p2[i].X[1];
+            input[j + 4] = p2[i].Y[0];
+            input[j + 5] = p2[i].Y[1];
+        }
+
+        uint256[1] memory out;
+        bool success;
+
+        // solium-disable-next-line security/no-inline-assembly
+        assembly {
+            success := staticcall(sub(gas(), 2000), 8, add(input, 0x20), mul(inputSize, 0x20), out, 0x20)
+            // Use "invalid" to make gas estimation work
+            switch success case 0 { invalid() }
+        }
+
+        require(success,"pairing-opcode-failed");
+
+        return out[0] != 0;
+    }
+}
+
+contract ZkbasVerifier {
+
+    using Pairing for *;
+
+    uint256 constant SNARK_SCALAR_FIELD = 21888242871839275222246405745257275088548364400416034343698204186575808495617;
+    uint256 constant PRIME_Q = 21888242871839275222246405745257275088696311157297823662689037894645226208583;
+
+    struct VerifyingKey {
+        Pairing.G1Point alfa1;
+        Pairing.G2Point beta2;
+        Pairing.G2Point gamma2;
+        Pairing.G2Point delta2;
+        Pairing.G1Point[] IC;
+    }
+
+    struct Proof {
+        Pairing.G1Point A;
+        Pairing.G2Point B;
+        Pairing.G1Point C;
+    }
+
+    /*
+     * @returns The block sizes with a verifying key
+     */
+    function blockSizes() public pure returns (uint16[] memory sizes) {
+        sizes = new uint16[]({{len .Keys}});
+        {{- range $i, $key := .Keys}}
+        sizes[{{$i}}] = {{$key.Size}};
+        {{- end}}
+    }
+
+    function verifyingKey(uint16 size) internal pure returns (VerifyingKey memory vk) {
+        {{- range $i, $key := .Keys}}
+        {{if $i}}} else {{end}}if (size == {{$key.Size}}) {
+            vk.alfa1 = Pairing.G1Point(uint256({{$key.G1.Alpha.X.String}}), uint256({{$key.G1.Alpha.Y.String}}));
+            vk.beta2 = Pairing.G2Point([uint256({{$key.G2.Beta.X.A1.String}}), uint256({{$key.G2.Beta.X.A0.String}})], [uint256({{$key.G2.Beta.Y.A1.String}}), uint256({{$key.G2.Beta.Y.A0.String}})]);
+            vk.gamma2 = Pairing.G2Point([uint256({{$key.G2.Gamma.X.A1.String}}), uint256({{$key.G2.Gamma.X.A0.String}})], [uint256({{$key.G2.Gamma.Y.A1.String}}), uint256({{$key.G2.Gamma.Y.A0.String}})]);
+            vk.delta2 = Pairing.G2Point([uint256({{$key.G2.Delta.X.A1.String}}), uint256({{$key.G2.Delta.X.A0.String}})], [uint256({{$key.G2.Delta.Y.A1.String}}), uint256({{$key.G2.Delta.Y.A0.String}})]);
+            vk.IC = new Pairing.G1Point[]({{len $key.G1.K}});
+            {{- range $j, $kj := $key.G1.K}}
+            vk.IC[{{$j}}] = Pairing.G1Point(uint256({{$kj.X.String}}), uint256({{$kj.Y.String}}));
+            {{- end}}
+        {{- end}}
+        } else {
+            revert("verifier-invalid-block-size");
+        }
+    }
+
+    /*
+     * @returns Whether the proof is valid given the verifying key of the
+     *          block size and the public inputs
+     */
+    function verifyBlock(
+        uint16 size,
+        uint256[8] memory proof,
+        uint256[] memory inputs
+    ) public view returns (bool r) {
+
+        VerifyingKey memory vk = verifyingKey(size);
+        require(inputs.length + 1 == vk.IC.length, "verifier-invalid-inputs-length");
+
+        Proof memory p;
+        p.A = Pairing.G1Point(proof[0], proof[1]);
+        p.B = Pairing.G2Point([proof[2], proof[3]], [proof[4], proof[5]]);
+        p.C = Pairing.G1Point(proof[6], proof[7]);
+
+        // Make sure that every coordinate of the proof is less than the prime q
+        for (uint256 i = 0; i < 8; i++) {
+            require(proof[i] < PRIME_Q, "verifier-proof-gte-prime-q");
+        }
+
+        // Compute the linear combination vk_x
+        Pairing.G1Point memory vk_x = Pairing.G1Point(0, 0);
+
+        // Make sure that every input is less than the snark scalar field
+        for (uint256 i = 0; i < inputs.length; i++) {
+            require(inputs[i] < SNARK_SCALAR_FIELD, "verifier-gte-snark-scalar-field");
+            vk_x = Pairing.plus(vk_x, Pairing.scalar_mul(vk.IC[i + 1], inputs[i]));
+        }
+
+        vk_x = Pairing.plus(vk_x, vk.IC[0]);
+
+        return Pairing.pairing(
+            Pairing.negate(p.A),
+            p.B,
+            vk.alfa1,
+            vk.beta2,
+            vk_x,
+            vk.gamma2,
+            p.C,
+            vk.delta2
+        );
+    }
+    {{- if .Compressed}}
+{{template "publicInputHash" .PublicInputHashBitsSize}}
+    /*
+     * @returns Whether the proof is valid given the verifying key of the
+     *          block size and the block
+     */
+    function verifyCompressedBlock(
+        uint16 size,
+        uint256[8] memory proof,
+        uint256 oldStateRoot,
+        uint256 newStateRoot,
+        uint256 commitment,
+        uint256 blockNumber,
+        uint256 createdAt,
+        uint256 priorityOpsHashStart,
+        uint256 priorityOpsHashEnd
+    ) public view returns (bool r) {
+        uint256[] memory inputs = new uint256[](1);
+        inputs[0] = publicInputHash(
+            oldStateRoot, newStateRoot, commitment, blockNumber, createdAt, priorityOpsHashStart, priorityOpsHashEnd
+        );
+        return verifyBlock(size, proof, inputs);
+    }
+    {{- end}}
+}
+`
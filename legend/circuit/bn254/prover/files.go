@@ -0,0 +1,226 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prover
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	gnarkio "github.com/consensys/gnark/io"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/exodus"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/witness"
+)
+
+/*
+	WriteFile: write the object to the file, keys and proofs are written
+	uncompressed as they are read faster
+*/
+func WriteFile(path string, object io.WriterTo) (err error) {
+	_, err = writeFile(path, object)
+	return err
+}
+
+/*
+	writeFile: write the object to the file and return the sha256 of the file
+*/
+func writeFile(path string, object io.WriterTo) (fileHash []byte, err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Println("[writeFile] unable to create file:", err)
+		return nil, err
+	}
+	h := sha256.New()
+	w := bufio.NewWriter(io.MultiWriter(f, h))
+	if rawObject, isRaw := object.(gnarkio.WriterRawTo); isRaw {
+		_, err = rawObject.WriteRawTo(w)
+	} else {
+		_, err = object.WriteTo(w)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Println("[writeFile] unable to write file:", err)
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+/*
+	ReadFile: read the object from the file written by WriteFile
+*/
+func ReadFile(path string, object io.ReaderFrom) (err error) {
+	_, err = readFileHash(path, object)
+	return err
+}
+
+/*
+	readFileHash: read the object from the file and return the sha256 of the
+	whole file, including the bytes after the object
+*/
+func readFileHash(path string, object io.ReaderFrom) (fileHash []byte, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Println("[readFileHash] unable to open file:", err)
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	r := bufio.NewReader(io.TeeReader(f, h))
+	_, err = object.ReadFrom(r)
+	if err == nil {
+		_, err = io.Copy(io.Discard, r)
+	}
+	if err != nil {
+		log.Println("[readFileHash] unable to read file:", err)
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+func newCompiledConstraints(backendName string) (ccs frontend.CompiledConstraintSystem, err error) {
+	switch backendName {
+	case BackendGroth16:
+		return groth16.NewCS(ecc.BN254), nil
+	case BackendPlonk:
+		return plonk.NewCS(ecc.BN254), nil
+	}
+	return nil, errInvalidBackend
+}
+
+func newProvingKey(backendName string) (pk ProvingKey, err error) {
+	switch backendName {
+	case BackendGroth16:
+		return groth16.NewProvingKey(ecc.BN254), nil
+	case BackendPlonk:
+		return plonk.NewProvingKey(ecc.BN254), nil
+	}
+	return nil, errInvalidBackend
+}
+
+func newVerifyingKey(backendName string) (vk VerifyingKey, err error) {
+	switch backendName {
+	case BackendGroth16:
+		return groth16.NewVerifyingKey(ecc.BN254), nil
+	case BackendPlonk:
+		return plonk.NewVerifyingKey(ecc.BN254), nil
+	}
+	return nil, errInvalidBackend
+}
+
+func ReadProof(backendName string, path string) (proof Proof, err error) {
+	switch backendName {
+	case BackendGroth16:
+		proof = groth16.NewProof(ecc.BN254)
+	case BackendPlonk:
+		proof = plonk.NewProof(ecc.BN254)
+	default:
+		return nil, errInvalidBackend
+	}
+	err = ReadFile(path, proof)
+	if err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+/*
+	ReadBlock: block witness encoded as json, like the block test fixtures
+*/
+func ReadBlock(path string) (oBlock *block.Block, err error) {
+	err = readJSON(path, &oBlock)
+	if err != nil {
+		return nil, err
+	}
+	return oBlock, nil
+}
+
+/*
+	ReadSnapshot: leaves of the state encoded as json, see witness.Snapshot
+*/
+func ReadSnapshot(path string) (snapshot *witness.Snapshot, err error) {
+	err = readJSON(path, &snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+/*
+	ReadExodus, ReadExodusNft: exodus witness encoded as json, only the public
+	inputs are read to verify a proof
+*/
+func ReadExodus(path string) (oExodus *exodus.Exodus, err error) {
+	err = readJSON(path, &oExodus)
+	if err != nil {
+		return nil, err
+	}
+	return oExodus, nil
+}
+
+func ReadExodusNft(path string) (oExodus *exodus.ExodusNft, err error) {
+	err = readJSON(path, &oExodus)
+	if err != nil {
+		return nil, err
+	}
+	return oExodus, nil
+}
+
+/*
+	WriteJSON: write the object encoded as json, e.g. the exodus witness
+*/
+func WriteJSON(path string, object interface{}) (err error) {
+	data, err := json.Marshal(object)
+	if err != nil {
+		log.Println("[WriteJSON] unable to encode object:", err)
+		return err
+	}
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		log.Println("[WriteJSON] unable to write file:", err)
+		return err
+	}
+	return nil
+}
+
+func readJSON(path string, object interface{}) (err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Println("[readJSON] unable to read file:", err)
+		return err
+	}
+	err = json.Unmarshal(data, object)
+	if err != nil {
+		log.Println("[readJSON] unable to parse file:", err)
+		return err
+	}
+	return nil
+}
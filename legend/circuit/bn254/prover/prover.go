@@ -0,0 +1,473 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prover
+
+import (
+	"errors"
+	"io"
+	"log"
+	"text/template"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/exodus"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+const (
+	BackendGroth16 = "groth16"
+	BackendPlonk   = "plonk"
+)
+
+/*
+	ProvingKey, VerifyingKey, Proof: keys and proofs of the backend,
+	groth16.ProvingKey or plonk.ProvingKey and so on
+*/
+type ProvingKey interface {
+	io.WriterTo
+	io.ReaderFrom
+}
+
+type VerifyingKey interface {
+	io.WriterTo
+	io.ReaderFrom
+}
+
+type Proof interface {
+	io.WriterTo
+	io.ReaderFrom
+}
+
+var errInvalidBackend = errors.New("invalid backend, expected groth16 or plonk")
+
+func CheckBackend(backendName string) (err error) {
+	if backendName != BackendGroth16 && backendName != BackendPlonk {
+		log.Println("[CheckBackend] invalid backend:", backendName)
+		return errInvalidBackend
+	}
+	return nil
+}
+
+/*
+	CompileBlockConstraints: compile the block circuit with one tx slot per slot type,
+	to a R1CS for groth16 or to a sparse R1CS for plonk. A compressed circuit has
+	a single public input, see block.CompressedBlockConstraints.
+*/
+func CompileBlockConstraints(
+	backendName string, slotTypes []int, compressed bool,
+) (ccs frontend.CompiledConstraintSystem, err error) {
+	if len(slotTypes) == 0 {
+		log.Println("[CompileBlockConstraints] invalid params")
+		return nil, errors.New("[CompileBlockConstraints] invalid params")
+	}
+	var circuit frontend.Circuit
+	if compressed {
+		compressedCircuit := block.NewCompressedBlockConstraints(slotTypes)
+		circuit = &compressedCircuit
+	} else {
+		blockCircuit := block.NewBlockConstraints(slotTypes)
+		circuit = &blockCircuit
+	}
+	ccs, err = compile(backendName, circuit)
+	if err != nil {
+		log.Println("[CompileBlockConstraints] unable to compile block circuit:", err)
+		return nil, err
+	}
+	return ccs, nil
+}
+
+/*
+	CompileExodusConstraints: compile the exodus circuit of an asset or of an nft,
+	see exodus.ExodusConstraints and exodus.ExodusNftConstraints
+*/
+func CompileExodusConstraints(backendName string, circuit string) (ccs frontend.CompiledConstraintSystem, err error) {
+	var exodusCircuit frontend.Circuit
+	switch circuit {
+	case CircuitExodus:
+		exodusCircuit = new(exodus.ExodusConstraints)
+	case CircuitExodusNft:
+		exodusCircuit = new(exodus.ExodusNftConstraints)
+	default:
+		log.Println("[CompileExodusConstraints] invalid exodus circuit:", circuit)
+		return nil, errors.New("[CompileExodusConstraints] invalid exodus circuit")
+	}
+	ccs, err = compile(backendName, exodusCircuit)
+	if err != nil {
+		log.Println("[CompileExodusConstraints] unable to compile exodus circuit:", err)
+		return nil, err
+	}
+	return ccs, nil
+}
+
+/*
+	compile: R1CS for groth16 or sparse R1CS for plonk
+*/
+func compile(backendName string, circuit frontend.Circuit) (ccs frontend.CompiledConstraintSystem, err error) {
+	newBuilder := r1cs.NewBuilder
+	switch backendName {
+	case BackendGroth16:
+	case BackendPlonk:
+		newBuilder = scs.NewBuilder
+	default:
+		return nil, errInvalidBackend
+	}
+	return frontend.Compile(ecc.BN254, newBuilder, circuit, frontend.IgnoreUnconstrainedInputs())
+}
+
+/*
+	Setup: proving and verifying keys of the compiled block circuit. The groth16
+	setup randomness is not kept but it's still generated by a single party, so
+	the keys are only meant for tests. The plonk setup is deterministic from the
+	KZG SRS, which is only read by plonk.
+*/
+func Setup(backendName string, ccs frontend.CompiledConstraintSystem, srs kzg.SRS) (pk ProvingKey, vk VerifyingKey, err error) {
+	switch backendName {
+	case BackendGroth16:
+		pk, vk, err = groth16.Setup(ccs)
+	case BackendPlonk:
+		if srs == nil {
+			log.Println("[Setup] plonk setup needs a kzg srs")
+			return nil, nil, errors.New("[Setup] plonk setup needs a kzg srs")
+		}
+		pk, vk, err = plonk.Setup(ccs, srs)
+	default:
+		return nil, nil, errInvalidBackend
+	}
+	if err != nil {
+		log.Println("[Setup] unable to setup:", err)
+		return nil, nil, err
+	}
+	return pk, vk, nil
+}
+
+/*
+	ProveBlock: proof of the block, the block must fit the slot types
+	the circuit is compiled with
+*/
+func ProveBlock(
+	backendName string, ccs frontend.CompiledConstraintSystem, pk ProvingKey,
+	oBlock *block.Block, slotTypes []int, compressed bool,
+) (proof Proof, err error) {
+	if oBlock == nil || pk == nil {
+		log.Println("[ProveBlock] invalid params")
+		return nil, errors.New("[ProveBlock] invalid params")
+	}
+	err = block.CheckBlockLayout(oBlock, slotTypes)
+	if err != nil {
+		log.Println("[ProveBlock] invalid block layout:", err)
+		return nil, err
+	}
+	var witness frontend.Circuit
+	if compressed {
+		compressedWitness, err := block.SetCompressedBlockWitness(oBlock)
+		if err != nil {
+			log.Println("[ProveBlock] unable to set block witness:", err)
+			return nil, err
+		}
+		witness = &compressedWitness
+	} else {
+		blockWitness, err := block.SetBlockWitness(oBlock)
+		if err != nil {
+			log.Println("[ProveBlock] unable to set block witness:", err)
+			return nil, err
+		}
+		witness = &blockWitness
+	}
+	proof, err = prove(backendName, ccs, pk, witness)
+	if err != nil {
+		log.Println("[ProveBlock] unable to prove block:", err)
+		return nil, err
+	}
+	return proof, nil
+}
+
+/*
+	ProveExodus, ProveExodusNft: proof of the exodus witness built from the
+	state of the last verified block, see witness.State.BuildExodus
+*/
+func ProveExodus(
+	backendName string, ccs frontend.CompiledConstraintSystem, pk ProvingKey, oExodus *exodus.Exodus,
+) (proof Proof, err error) {
+	if pk == nil {
+		log.Println("[ProveExodus] invalid params")
+		return nil, errors.New("[ProveExodus] invalid params")
+	}
+	witness, err := exodus.SetExodusWitness(oExodus)
+	if err != nil {
+		log.Println("[ProveExodus] unable to set exodus witness:", err)
+		return nil, err
+	}
+	proof, err = prove(backendName, ccs, pk, &witness)
+	if err != nil {
+		log.Println("[ProveExodus] unable to prove exodus:", err)
+		return nil, err
+	}
+	return proof, nil
+}
+
+func ProveExodusNft(
+	backendName string, ccs frontend.CompiledConstraintSystem, pk ProvingKey, oExodus *exodus.ExodusNft,
+) (proof Proof, err error) {
+	if pk == nil {
+		log.Println("[ProveExodusNft] invalid params")
+		return nil, errors.New("[ProveExodusNft] invalid params")
+	}
+	witness, err := exodus.SetExodusNftWitness(oExodus)
+	if err != nil {
+		log.Println("[ProveExodusNft] unable to set exodus witness:", err)
+		return nil, err
+	}
+	proof, err = prove(backendName, ccs, pk, &witness)
+	if err != nil {
+		log.Println("[ProveExodusNft] unable to prove exodus:", err)
+		return nil, err
+	}
+	return proof, nil
+}
+
+func prove(
+	backendName string, ccs frontend.CompiledConstraintSystem, pk ProvingKey, witness frontend.Circuit,
+) (proof Proof, err error) {
+	fullWitness, err := frontend.NewWitness(witness, ecc.BN254)
+	if err != nil {
+		log.Println("[prove] unable to parse witness:", err)
+		return nil, err
+	}
+	hints := backend.WithHints(std.ComputeSLp, std.ComputeStableSwap, std.ComputePriceCumulative)
+	switch backendName {
+	case BackendGroth16:
+		groth16Pk, isOk := pk.(groth16.ProvingKey)
+		if !isOk {
+			return nil, errors.New("[prove] invalid groth16 proving key")
+		}
+		return groth16.Prove(ccs, groth16Pk, fullWitness, hints)
+	case BackendPlonk:
+		plonkPk, isOk := pk.(plonk.ProvingKey)
+		if !isOk {
+			return nil, errors.New("[prove] invalid plonk proving key")
+		}
+		return plonk.Prove(ccs, plonkPk, fullWitness, hints)
+	}
+	return nil, errInvalidBackend
+}
+
+/*
+	VerifyBlock: verify the proof against the public inputs of the block,
+	its old and new state roots, its commitment and the hashes of the priority
+	ops before and after it, or their hash with its number and timestamp if the
+	circuit is compressed
+*/
+func VerifyBlock(
+	backendName string, vk VerifyingKey, proof Proof, oBlock *block.Block, compressed bool,
+) (err error) {
+	if oBlock == nil || vk == nil || proof == nil {
+		log.Println("[VerifyBlock] invalid params")
+		return errors.New("[VerifyBlock] invalid params")
+	}
+	var publicInputs frontend.Circuit = &block.BlockConstraints{
+		OldStateRoot:         oBlock.OldStateRoot,
+		NewStateRoot:         oBlock.NewStateRoot,
+		BlockCommitment:      oBlock.BlockCommitment,
+		PriorityOpsHashStart: oBlock.PriorityOpsHashStart,
+		PriorityOpsHashEnd:   oBlock.PriorityOpsHashEnd,
+	}
+	if compressed {
+		publicInputHash, err := block.ComputePublicInputHash(oBlock)
+		if err != nil {
+			log.Println("[VerifyBlock] unable to compute public input hash:", err)
+			return err
+		}
+		publicInputs = &block.CompressedBlockConstraints{PublicInputHash: publicInputHash}
+	}
+	err = verify(backendName, vk, proof, publicInputs)
+	if err != nil {
+		log.Println("[VerifyBlock] invalid proof:", err)
+		return err
+	}
+	return nil
+}
+
+/*
+	VerifyExodus, VerifyExodusNft: verify the proof against the state root, the
+	account and the asset or nft of the exodus, the merkle proofs aren't read
+*/
+func VerifyExodus(backendName string, vk VerifyingKey, proof Proof, oExodus *exodus.Exodus) (err error) {
+	if oExodus == nil || oExodus.Account == nil || oExodus.Asset == nil || vk == nil || proof == nil {
+		log.Println("[VerifyExodus] invalid params")
+		return errors.New("[VerifyExodus] invalid params")
+	}
+	publicInputs, err := exodus.SetExodusWitness(oExodus)
+	if err != nil {
+		log.Println("[VerifyExodus] unable to set public inputs:", err)
+		return err
+	}
+	err = verify(backendName, vk, proof, &publicInputs)
+	if err != nil {
+		log.Println("[VerifyExodus] invalid proof:", err)
+		return err
+	}
+	return nil
+}
+
+func VerifyExodusNft(backendName string, vk VerifyingKey, proof Proof, oExodus *exodus.ExodusNft) (err error) {
+	if oExodus == nil || oExodus.Account == nil || oExodus.Nft == nil || vk == nil || proof == nil {
+		log.Println("[VerifyExodusNft] invalid params")
+		return errors.New("[VerifyExodusNft] invalid params")
+	}
+	publicInputs, err := exodus.SetExodusNftWitness(oExodus)
+	if err != nil {
+		log.Println("[VerifyExodusNft] unable to set public inputs:", err)
+		return err
+	}
+	err = verify(backendName, vk, proof, &publicInputs)
+	if err != nil {
+		log.Println("[VerifyExodusNft] invalid proof:", err)
+		return err
+	}
+	return nil
+}
+
+func verify(backendName string, vk VerifyingKey, proof Proof, publicInputs frontend.Circuit) (err error) {
+	publicWitness, err := frontend.NewWitness(publicInputs, ecc.BN254, frontend.PublicOnly())
+	if err != nil {
+		log.Println("[verify] unable to parse public witness:", err)
+		return err
+	}
+	switch backendName {
+	case BackendGroth16:
+		groth16Vk, isVk := vk.(groth16.VerifyingKey)
+		groth16Proof, isProof := proof.(groth16.Proof)
+		if !isVk || !isProof {
+			return errors.New("[verify] invalid groth16 verifying key or proof")
+		}
+		return groth16.Verify(groth16Proof, groth16Vk, publicWitness)
+	case BackendPlonk:
+		plonkVk, isVk := vk.(plonk.VerifyingKey)
+		plonkProof, isProof := proof.(plonk.Proof)
+		if !isVk || !isProof {
+			return errors.New("[verify] invalid plonk verifying key or proof")
+		}
+		return plonk.Verify(plonkProof, plonkVk, publicWitness)
+	}
+	return errInvalidBackend
+}
+
+/*
+	ExportSolidity: verifier contract of the verifying key, gnark v0.7.0 only
+	has a groth16 verifier contract, plonk proofs can't be verified on L1 yet.
+	The contract of a compressed circuit also has a BlockVerifier which computes
+	the public input hash from the block, see compressedVerifierTemplate.
+*/
+func ExportSolidity(backendName string, vk VerifyingKey, compressed bool, w io.Writer) (err error) {
+	switch backendName {
+	case BackendGroth16:
+		groth16Vk, isOk := vk.(groth16.VerifyingKey)
+		if !isOk {
+			return errors.New("[ExportSolidity] invalid groth16 verifying key")
+		}
+		err = groth16Vk.ExportSolidity(w)
+		if err == nil && compressed {
+			err = exportCompressedVerifierSolidity(w)
+		}
+	case BackendPlonk:
+		log.Println("[ExportSolidity] plonk verifier contract is not supported by gnark v0.7.0")
+		return errors.New("[ExportSolidity] plonk verifier contract is not supported by gnark v0.7.0")
+	default:
+		return errInvalidBackend
+	}
+	if err != nil {
+		log.Println("[ExportSolidity] unable to export verifier contract:", err)
+		return err
+	}
+	return nil
+}
+
+func exportCompressedVerifierSolidity(w io.Writer) (err error) {
+	tmpl, err := template.New("").Parse(compressedVerifierTemplate)
+	if err == nil {
+		_, err = tmpl.Parse(publicInputHashTemplate)
+	}
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, block.PublicInputHashBitsSize)
+}
+
+/*
+	compressedVerifierTemplate: appended to the gnark groth16 verifier contract,
+	verifyBlock computes the single public input of the proof from the block
+*/
+const compressedVerifierTemplate = `
+contract BlockVerifier is Verifier {
+{{template "publicInputHash" .}}
+    /*
+     * @returns Whether the proof is valid for the block
+     */
+    function verifyBlock(
+        uint256[2] memory a,
+        uint256[2][2] memory b,
+        uint256[2] memory c,
+        uint256 oldStateRoot,
+        uint256 newStateRoot,
+        uint256 commitment,
+        uint256 blockNumber,
+        uint256 createdAt,
+        uint256 priorityOpsHashStart,
+        uint256 priorityOpsHashEnd
+    ) public view returns (bool r) {
+        uint256[1] memory input;
+        input[0] = publicInputHash(
+            oldStateRoot, newStateRoot, commitment, blockNumber, createdAt, priorityOpsHashStart, priorityOpsHashEnd
+        );
+        return verifyProof(a, b, c, input);
+    }
+}
+`
+
+/*
+	publicInputHashTemplate: same as block.ComputePublicInputHash, executed with
+	block.PublicInputHashBitsSize
+*/
+const publicInputHashTemplate = `{{define "publicInputHash"}}
+    uint256 constant PUBLIC_INPUT_HASH_MASK = (1 << {{.}}) - 1;
+
+    /*
+     * @returns The single public input of the compressed block circuit
+     */
+    function publicInputHash(
+        uint256 oldStateRoot,
+        uint256 newStateRoot,
+        uint256 commitment,
+        uint256 blockNumber,
+        uint256 createdAt,
+        uint256 priorityOpsHashStart,
+        uint256 priorityOpsHashEnd
+    ) public pure returns (uint256) {
+        return uint256(keccak256(abi.encodePacked(
+            oldStateRoot, newStateRoot, commitment, blockNumber, createdAt, priorityOpsHashStart, priorityOpsHashEnd
+        ))) & PUBLIC_INPUT_HASH_MASK;
+    }
+{{end}}`
@@ -0,0 +1,284 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prover
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/kzg"
+
+	curve "github.com/bnb-chain/zkbas-crypto/ecc/ztwistededwards/tebn254"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/exodus"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/witness"
+	"github.com/bnb-chain/zkbas-crypto/wasm/legend/legendTxTypes"
+)
+
+func registerZnsBlock(t *testing.T) *block.Block {
+	s, err := witness.NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sk, err := curve.GenerateEddsaPrivateKey("sher.legend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stateRoot := s.StateRoot()
+	oTx, err := s.BuildTx(&legendTxTypes.RegisterZnsTxInfo{
+		TxType:          legendTxTypes.TxTypeRegisterZns,
+		AccountIndex:    0,
+		AccountName:     "sher.legend",
+		AccountNameHash: bytes.Repeat([]byte{1}, 32),
+		PubKey:          hex.EncodeToString(sk.PublicKey.Bytes()),
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oBlock := &block.Block{
+		BlockNumber:  1,
+		CreatedAt:    1655348736095,
+		OldStateRoot: stateRoot,
+		NewStateRoot: s.StateRoot(),
+		Txs:          []*block.Tx{oTx},
+	}
+	oBlock.BlockCommitment, err = block.ComputeBlockCommitment(oBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oBlock.PriorityOpsHashEnd, err = block.ComputePriorityOpsHash(oBlock.PriorityOpsHashStart, oBlock.Txs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return oBlock
+}
+
+func TestProveBlockGroth16(t *testing.T) {
+	testProveBlock(t, BackendGroth16, false)
+}
+
+func TestProveBlockPlonk(t *testing.T) {
+	testProveBlock(t, BackendPlonk, false)
+}
+
+func TestProveCompressedBlockGroth16(t *testing.T) {
+	testProveBlock(t, BackendGroth16, true)
+}
+
+func testProveBlock(t *testing.T, backendName string, compressed bool) {
+	if testing.Short() {
+		t.Skip("setup of the block circuit is slow")
+	}
+	dir := t.TempDir()
+	oBlock := registerZnsBlock(t)
+	slotTypes := []int{block.TxSlotTypePriorityOp}
+	bundle, ccs, err := LoadOrCompileBundle(dir, backendName, slotTypes, compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var srs kzg.SRS
+	if backendName == BackendPlonk {
+		if _, _, err = Setup(backendName, ccs, nil); err == nil {
+			t.Fatal("plonk setup without srs")
+		}
+		if srs, err = NewTestSRS(ccs); err != nil {
+			t.Fatal(err)
+		}
+		// the srs is read back from its file
+		srsPath := filepath.Join(dir, "test.srs")
+		if err = WriteFile(srsPath, srs); err != nil {
+			t.Fatal(err)
+		}
+		if srs, err = ReadSRS(srsPath); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pk, vk, err := Setup(backendName, ccs, srs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = bundle.WriteKeys(pk, vk, srs); err != nil {
+		t.Fatal(err)
+	}
+	// the files read back are the ones written
+	if bundle, err = ReadBundle(dir); err != nil {
+		t.Fatal(err)
+	}
+	if ccs, err = bundle.ReadCompiledConstraints(); err != nil {
+		t.Fatal(err)
+	}
+	if pk, err = bundle.ReadProvingKey(); err != nil {
+		t.Fatal(err)
+	}
+	if vk, err = bundle.ReadVerifyingKey(); err != nil {
+		t.Fatal(err)
+	}
+	blockData, err := json.Marshal(oBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockPath, proofPath := filepath.Join(dir, "block.json"), filepath.Join(dir, "block.proof")
+	if err = os.WriteFile(blockPath, blockData, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if oBlock, err = ReadBlock(blockPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = ProveBlock(backendName, ccs, pk, oBlock, []int{block.TxSlotTypeL2Asset}, compressed); err == nil {
+		t.Fatal("block proved with another layout")
+	}
+	proof, err := ProveBlock(backendName, ccs, pk, oBlock, slotTypes, compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = WriteFile(proofPath, proof); err != nil {
+		t.Fatal(err)
+	}
+	if proof, err = ReadProof(backendName, proofPath); err != nil {
+		t.Fatal(err)
+	}
+	if err = VerifyBlock(backendName, vk, proof, oBlock, compressed); err != nil {
+		t.Fatal(err)
+	}
+	if compressed {
+		if err = VerifyBlock(backendName, vk, proof, oBlock, false); err == nil {
+			t.Fatal("proof accepted for uncompressed public inputs")
+		}
+		// the block number is bound by the public input hash
+		oBlock.BlockNumber++
+		if err = VerifyBlock(backendName, vk, proof, oBlock, compressed); err == nil {
+			t.Fatal("proof accepted for another block number")
+		}
+		oBlock.BlockNumber--
+	}
+	oBlock.NewStateRoot = oBlock.OldStateRoot
+	if err = VerifyBlock(backendName, vk, proof, oBlock, compressed); err == nil {
+		t.Fatal("proof accepted for another new state root")
+	}
+
+	var sol bytes.Buffer
+	err = ExportSolidity(backendName, vk, compressed, &sol)
+	if backendName == BackendPlonk {
+		if err == nil {
+			t.Fatal("plonk verifier contract exported")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(sol.Bytes(), []byte("function verifyProof")) {
+		t.Fatal("invalid verifier contract")
+	}
+	if bytes.Contains(sol.Bytes(), []byte("contract BlockVerifier")) != compressed {
+		t.Fatal("invalid block verifier contract")
+	}
+}
+
+func TestProveExodusGroth16(t *testing.T) {
+	if testing.Short() {
+		t.Skip("setup of the exodus circuit is slow")
+	}
+	s, err := witness.NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sk, err := curve.GenerateEddsaPrivateKey("sher.legend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.SetAccount(&witness.Account{
+		AccountIndex:    1,
+		AccountNameHash: bytes.Repeat([]byte{1}, 32),
+		AccountPk:       &sk.PublicKey,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.SetAccountAsset(1, &std.AccountAsset{
+		AssetId:                  2,
+		Balance:                  big.NewInt(100),
+		LpAmount:                 big.NewInt(0),
+		OfferCanceledOrFinalized: big.NewInt(0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oExodus, err := s.BuildExodus(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	bundle, ccs, err := LoadOrCompileExodusBundle(dir, BackendGroth16, CircuitExodus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = LoadOrCompileBundle(dir, BackendGroth16, []int{block.TxSlotTypeAll}, false); err == nil {
+		t.Fatal("exodus bundle loaded as a block bundle")
+	}
+	pk, vk, err := Setup(BackendGroth16, ccs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = bundle.WriteKeys(pk, vk, nil); err != nil {
+		t.Fatal(err)
+	}
+	if bundle, err = ReadBundle(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err = bundle.CheckCircuit(CircuitBlock); err == nil {
+		t.Fatal("exodus bundle accepted by block commands")
+	}
+	if _, err = NewRegistry([]*Bundle{bundle}); err == nil {
+		t.Fatal("exodus bundle accepted by the registry")
+	}
+	proof, err := ProveExodus(BackendGroth16, ccs, pk, oExodus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only the public inputs are read back
+	exodusPath := filepath.Join(dir, "exodus.json")
+	if err = WriteJSON(exodusPath, &exodus.Exodus{
+		StateRoot: oExodus.StateRoot,
+		Account: &exodus.ExodusAccount{
+			AccountIndex:    1,
+			AccountNameHash: oExodus.Account.AccountNameHash,
+			AccountPk:       oExodus.Account.AccountPk,
+		},
+		Asset: oExodus.Asset,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if oExodus, err = ReadExodus(exodusPath); err != nil {
+		t.Fatal(err)
+	}
+	if err = VerifyExodus(BackendGroth16, vk, proof, oExodus); err != nil {
+		t.Fatal(err)
+	}
+	oExodus.Asset.Balance = big.NewInt(101)
+	if err = VerifyExodus(BackendGroth16, vk, proof, oExodus); err == nil {
+		t.Fatal("proof accepted for another balance")
+	}
+}
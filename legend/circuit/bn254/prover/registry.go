@@ -0,0 +1,130 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prover
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+)
+
+/*
+	Registry: bundles of the block circuit for several block sizes, one per
+	TxsCount, all of the same backend and public inputs so a single verifier
+	contract serves them
+*/
+type Registry struct {
+	Backend    string
+	Compressed bool
+	// sorted by TxsCount
+	bundles []*Bundle
+}
+
+func NewRegistry(bundles []*Bundle) (registry *Registry, err error) {
+	if len(bundles) == 0 {
+		log.Println("[NewRegistry] invalid params")
+		return nil, errors.New("[NewRegistry] invalid params")
+	}
+	registry = &Registry{
+		Backend:    bundles[0].Manifest.Backend,
+		Compressed: bundles[0].Manifest.CompressedPublicInputs,
+		bundles:    make([]*Bundle, len(bundles)),
+	}
+	copy(registry.bundles, bundles)
+	sort.Slice(registry.bundles, func(i, j int) bool {
+		return registry.bundles[i].Manifest.TxsCount < registry.bundles[j].Manifest.TxsCount
+	})
+	for i, bundle := range registry.bundles {
+		if err = bundle.CheckCircuit(CircuitBlock); err != nil {
+			return nil, err
+		}
+		if bundle.Manifest.Backend != registry.Backend {
+			log.Println("[NewRegistry] bundles of different backends")
+			return nil, errors.New("[NewRegistry] bundles of different backends")
+		}
+		if bundle.Manifest.CompressedPublicInputs != registry.Compressed {
+			log.Println("[NewRegistry] bundles of different public inputs")
+			return nil, errors.New("[NewRegistry] bundles of different public inputs")
+		}
+		if i > 0 && bundle.Manifest.TxsCount == registry.bundles[i-1].Manifest.TxsCount {
+			log.Println("[NewRegistry] several bundles of the same size:", bundle.Manifest.TxsCount)
+			return nil, fmt.Errorf("[NewRegistry] several bundles of the same size: %d", bundle.Manifest.TxsCount)
+		}
+	}
+	return registry, nil
+}
+
+/*
+	ReadRegistry: read the bundles of the directories, see ReadBundle
+*/
+func ReadRegistry(dirs []string) (registry *Registry, err error) {
+	bundles := make([]*Bundle, len(dirs))
+	for i, dir := range dirs {
+		bundles[i], err = ReadBundle(dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return NewRegistry(bundles)
+}
+
+/*
+	Sizes: TxsCount of the bundles in increasing order
+*/
+func (registry *Registry) Sizes() (sizes []int) {
+	sizes = make([]int, len(registry.bundles))
+	for i, bundle := range registry.bundles {
+		sizes[i] = bundle.Manifest.TxsCount
+	}
+	return sizes
+}
+
+func (registry *Registry) Bundle(txsCount int) (bundle *Bundle, err error) {
+	for _, bundle = range registry.bundles {
+		if bundle.Manifest.TxsCount == txsCount {
+			return bundle, nil
+		}
+	}
+	log.Println("[Bundle] no bundle of size:", txsCount)
+	return nil, fmt.Errorf("[Bundle] no bundle of size: %d", txsCount)
+}
+
+/*
+	Fit: smallest bundle the pending txs fit in, with the slot of each tx,
+	the other slots of the block are empty txs (see block.FitBlockLayout)
+*/
+func (registry *Registry) Fit(txTypes []int) (bundle *Bundle, slots []int, err error) {
+	for _, bundle = range registry.bundles {
+		if bundle.Manifest.TxsCount < len(txTypes) {
+			continue
+		}
+		slotTypes, err := bundle.SlotTypes()
+		if err != nil {
+			return nil, nil, err
+		}
+		slots, err = block.FitBlockLayout(txTypes, slotTypes)
+		if err == nil {
+			return bundle, slots, nil
+		}
+	}
+	log.Println("[Fit] txs don't fit any bundle")
+	return nil, nil, errors.New("[Fit] txs don't fit any bundle")
+}
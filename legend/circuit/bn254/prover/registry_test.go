@@ -0,0 +1,330 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prover
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/compiler"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+func newTestBundle(t *testing.T, backendName string, slotTypes []int, compressed bool) *Bundle {
+	manifest, err := NewManifest(backendName, slotTypes, compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Bundle{Manifest: manifest}
+}
+
+func TestRegistry(t *testing.T) {
+	bundles := []*Bundle{
+		newTestBundle(t, BackendGroth16, block.GetBlockLayout(2, 4, 2), false),
+		newTestBundle(t, BackendGroth16, block.GetBlockLayout(0, 1, 0), false),
+		newTestBundle(t, BackendGroth16, block.GetBlockLayout(2, 0, 0), false),
+	}
+	registry, err := NewRegistry(bundles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(registry.Sizes()) != "[1 2 8]" {
+		t.Fatal("invalid sizes:", registry.Sizes())
+	}
+	if _, err = registry.Bundle(4); err == nil {
+		t.Fatal("bundle of a missing size")
+	}
+	for _, test := range []struct {
+		txTypes []int
+		size    int
+		slots   string
+	}{
+		{[]int{std.TxTypeTransfer}, 1, "[0]"},
+		{[]int{std.TxTypeDeposit, std.TxTypeFullExit}, 2, "[0 1]"},
+		// the smallest bundle accepting the tx types, not only the count
+		{[]int{std.TxTypeDeposit, std.TxTypeTransfer}, 8, "[0 2]"},
+		{[]int{std.TxTypeMintNft}, 8, "[6]"},
+	} {
+		bundle, slots, err := registry.Fit(test.txTypes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bundle.Manifest.TxsCount != test.size || fmt.Sprint(slots) != test.slots {
+			t.Fatalf("tx types %v: size %d, slots %v", test.txTypes, bundle.Manifest.TxsCount, slots)
+		}
+	}
+	if _, _, err = registry.Fit([]int{std.TxTypeMintNft, std.TxTypeMintNft, std.TxTypeMintNft}); err == nil {
+		t.Fatal("txs fit no bundle")
+	}
+
+	if _, err = NewRegistry(append(bundles, newTestBundle(t, BackendGroth16, block.GetBlockLayout(0, 0, 1), false))); err == nil {
+		t.Fatal("two bundles of the same size")
+	}
+	if _, err = NewRegistry(append(bundles, newTestBundle(t, BackendPlonk, block.GetBlockLayout(0, 0, 4), false))); err == nil {
+		t.Fatal("bundles of different backends")
+	}
+	if _, err = NewRegistry(append(bundles, newTestBundle(t, BackendGroth16, block.GetBlockLayout(0, 0, 4), true))); err == nil {
+		t.Fatal("bundles of different public inputs")
+	}
+}
+
+type publicInputsCircuit struct {
+	X frontend.Variable
+	Y []frontend.Variable `gnark:",public"`
+}
+
+func (circuit *publicInputsCircuit) Define(api frontend.API) error {
+	for i := range circuit.Y {
+		api.AssertIsEqual(circuit.Y[i], api.Mul(circuit.X, i+1))
+	}
+	return nil
+}
+
+func TestExportDispatcherSolidity(t *testing.T) {
+	var keys []*groth16VerifyingKey
+	var verifiers []string
+	for _, size := range []int{1, 10} {
+		circuit := publicInputsCircuit{Y: make([]frontend.Variable, size)}
+		ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &circuit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, vk, err := groth16.Setup(ccs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := decodeGroth16VerifyingKey(size, vk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(key.G1.K) != size+1 {
+			t.Fatal("invalid verifying key")
+		}
+		keys = append(keys, key)
+		var sol bytes.Buffer
+		if err = vk.ExportSolidity(&sol); err != nil {
+			t.Fatal(err)
+		}
+		verifiers = append(verifiers, sol.String())
+	}
+	var sol bytes.Buffer
+	if err := exportDispatcherSolidity(keys, false, &sol); err != nil {
+		t.Fatal(err)
+	}
+	dispatcher := sol.String()
+	for _, expected := range []string{
+		"function verifyBlock(", "if (size == 1) {", "} else if (size == 10) {",
+		"sizes[1] = 10;", "vk.IC = new Pairing.G1Point[](11);",
+	} {
+		if !strings.Contains(dispatcher, expected) {
+			t.Fatal("missing in dispatcher contract:", expected)
+		}
+	}
+	if strings.Contains(dispatcher, "function verifyCompressedBlock(") {
+		t.Fatal("compressed block verifier in dispatcher contract")
+	}
+	var compressedSol bytes.Buffer
+	if err := exportDispatcherSolidity(keys, true, &compressedSol); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"function verifyCompressedBlock(", "function publicInputHash(",
+		"PUBLIC_INPUT_HASH_MASK = (1 << 253) - 1;",
+	} {
+		if !strings.Contains(compressedSol.String(), expected) {
+			t.Fatal("missing in compressed dispatcher contract:", expected)
+		}
+	}
+	// the keys embedded are the ones of the verifier contract of each size
+	for _, verifier := range verifiers {
+		for _, line := range strings.Split(verifier, "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "vk.") && !strings.Contains(dispatcher, line) {
+				t.Fatal("verifying key not in dispatcher contract:", line)
+			}
+		}
+	}
+}
+
+// proof coordinates in the order of verifyBlock: A, B (A1 before A0) and C
+func solidityProof(proof groth16.Proof) (words [8]*big.Int, err error) {
+	var buf bytes.Buffer
+	if _, err = proof.WriteRawTo(&buf); err != nil {
+		return words, err
+	}
+	var a, c curve.G1Affine
+	var b curve.G2Affine
+	dec := curve.NewDecoder(&buf)
+	for _, v := range []interface{}{&a, &b, &c} {
+		if err = dec.Decode(v); err != nil {
+			return words, err
+		}
+	}
+	for i, e := range []interface{ ToBigIntRegular(*big.Int) *big.Int }{
+		&a.X, &a.Y, &b.X.A1, &b.X.A0, &b.Y.A1, &b.Y.A0, &c.X, &c.Y,
+	} {
+		words[i] = e.ToBigIntRegular(new(big.Int))
+	}
+	return words, nil
+}
+
+func TestDispatcherVerifyProofs(t *testing.T) {
+	solc, err := exec.LookPath("solc")
+	if err != nil {
+		t.Skip("solc not found:", err)
+	}
+	// sizes 1 and 2 have the same public inputs count, so only the pairing
+	// tells their proofs apart
+	sizes := []int{1, 2, 10}
+	inputsCounts := map[int]int{1: 1, 2: 1, 10: 10}
+	var keys []*groth16VerifyingKey
+	proofs := make(map[int][8]*big.Int)
+	inputs := make(map[int][]*big.Int)
+	for _, size := range sizes {
+		circuit := publicInputsCircuit{Y: make([]frontend.Variable, inputsCounts[size])}
+		ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &circuit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pk, vk, err := groth16.Setup(ccs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := decodeGroth16VerifyingKey(size, vk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+		assignment := publicInputsCircuit{X: 7, Y: make([]frontend.Variable, inputsCounts[size])}
+		for i := range assignment.Y {
+			assignment.Y[i] = 7 * (i + 1)
+			inputs[size] = append(inputs[size], big.NewInt(int64(7*(i+1))))
+		}
+		witness, err := frontend.NewWitness(&assignment, ecc.BN254)
+		if err != nil {
+			t.Fatal(err)
+		}
+		proof, err := groth16.Prove(ccs, pk, witness)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if proofs[size], err = solidityProof(proof); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var sol bytes.Buffer
+	if err = exportDispatcherSolidity(keys, false, &sol); err != nil {
+		t.Fatal(err)
+	}
+	// the EVM of the simulated backend has no PUSH0, solc >= 0.8.20 emits it
+	// unless the evm version is older than shanghai
+	cmd := exec.Command(solc, "--evm-version", "london", "--optimize", "--combined-json", "abi,bin", "-")
+	cmd.Stdin = &sol
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err, stderr.String())
+	}
+	contracts, err := compiler.ParseCombinedJSON(out, "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var contract *compiler.Contract
+	for name, c := range contracts {
+		if strings.HasSuffix(name, ":ZkbasVerifier") {
+			contract = c
+		}
+	}
+	if contract == nil {
+		t.Fatal("no ZkbasVerifier contract")
+	}
+	abiJSON, err := json.Marshal(contract.Info.AbiDefinition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := abi.JSON(bytes.NewReader(abiJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: big.NewInt(1e18)}}, 30000000)
+	defer backend.Close()
+	_, _, verifier, err := bind.DeployContract(auth, parsed, common.FromHex(contract.Code), backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.Commit()
+
+	verifyBlock := func(size int, proof [8]*big.Int, inputs []*big.Int) (bool, error) {
+		var res []interface{}
+		err := verifier.Call(&bind.CallOpts{}, &res, "verifyBlock", uint16(size), proof, inputs)
+		if err != nil {
+			return false, err
+		}
+		return res[0].(bool), nil
+	}
+	for _, size := range sizes {
+		ok, err := verifyBlock(size, proofs[size], inputs[size])
+		if err != nil || !ok {
+			t.Fatalf("proof of size %d rejected: %v", size, err)
+		}
+		wrongInputs := append([]*big.Int{big.NewInt(8)}, inputs[size][1:]...)
+		if ok, err = verifyBlock(size, proofs[size], wrongInputs); err != nil || ok {
+			t.Fatalf("proof of size %d accepted with wrong inputs: %v", size, err)
+		}
+	}
+	if ok, err := verifyBlock(2, proofs[1], inputs[1]); err != nil || ok {
+		t.Fatal("proof of size 1 accepted under size 2:", err)
+	}
+	if ok, err := verifyBlock(1, proofs[2], inputs[2]); err != nil || ok {
+		t.Fatal("proof of size 2 accepted under size 1:", err)
+	}
+	if _, err := verifyBlock(10, proofs[1], inputs[1]); err == nil {
+		t.Fatal("proof of size 1 accepted under size 10")
+	}
+	if _, err := verifyBlock(4, proofs[1], inputs[1]); err == nil {
+		t.Fatal("proof of a missing size accepted")
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package prover
+
+import (
+	"crypto/rand"
+	"log"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	kzg_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/frontend"
+)
+
+/*
+	SRSSize: size of the KZG SRS needed by the plonk setup of the compiled circuit
+*/
+func SRSSize(ccs frontend.CompiledConstraintSystem) uint64 {
+	_, _, public := ccs.GetNbVariables()
+	return ecc.NextPowerOfTwo(uint64(ccs.GetNbConstraints()+public)) + 3
+}
+
+/*
+	NewTestSRS: KZG SRS for the compiled circuit from a random secret, the
+	secret isn't kept but it's known by a single party while the SRS is
+	generated, so the SRS is insecure and only meant for tests. SRS from an
+	MPC ceremony (e.g. the perpetual powers of tau) are read with ReadSRS.
+*/
+func NewTestSRS(ccs frontend.CompiledConstraintSystem) (srs kzg.SRS, err error) {
+	alpha, err := rand.Int(rand.Reader, fr.Modulus())
+	if err != nil {
+		log.Println("[NewTestSRS] unable to generate secret:", err)
+		return nil, err
+	}
+	srs, err = kzg_bn254.NewSRS(SRSSize(ccs), alpha)
+	if err != nil {
+		log.Println("[NewTestSRS] unable to generate srs:", err)
+		return nil, err
+	}
+	return srs, nil
+}
+
+/*
+	ReadSRS: BN254 KZG SRS encoded by gnark-crypto, it must have at least
+	SRSSize points in G1 for the plonk setup
+*/
+func ReadSRS(path string) (srs kzg.SRS, err error) {
+	srs = new(kzg_bn254.SRS)
+	err = ReadFile(path, srs)
+	if err != nil {
+		return nil, err
+	}
+	return srs, nil
+}
@@ -35,6 +35,10 @@ type Account struct {
 	CollectionNonce int64
 	AssetRoot       []byte
 	AssetsInfo      [NbAccountAssetsPerAccount]*AccountAsset
+	// root of the signer keys and signatures required of a multi-signature
+	// account, both are empty for an account of a single key
+	SignerRoot      []byte
+	SignerThreshold int64
 }
 
 func EmptyAccount(accountIndex int64, assetRoot []byte) *Account {
@@ -56,6 +60,8 @@ func EmptyAccount(accountIndex int64, assetRoot []byte) *Account {
 			EmptyAccountAsset(0),
 			EmptyAccountAsset(0),
 		},
+		SignerRoot:      []byte{},
+		SignerThreshold: 0,
 	}
 }
 
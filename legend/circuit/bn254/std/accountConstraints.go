@@ -31,7 +31,9 @@ type AccountConstraints struct {
 	CollectionNonce Variable
 	AssetRoot       Variable
 	// at most 4 assets changed in one transaction
-	AssetsInfo [NbAccountAssetsPerAccount]AccountAssetConstraints
+	AssetsInfo      [NbAccountAssetsPerAccount]AccountAssetConstraints
+	SignerRoot      Variable
+	SignerThreshold Variable
 }
 
 func CheckEmptyAccountNode(api API, flag Variable, account AccountConstraints) {
@@ -42,6 +44,51 @@ func CheckEmptyAccountNode(api API, flag Variable, account AccountConstraints) {
 	IsVariableEqual(api, flag, account.CollectionNonce, ZeroInt)
 	// empty asset
 	IsVariableEqual(api, flag, account.AssetRoot, EmptyAssetRoot)
+	IsVariableEqual(api, flag, account.SignerRoot, ZeroInt)
+	IsVariableEqual(api, flag, account.SignerThreshold, ZeroInt)
+}
+
+/*
+	CollectHashInputsFromAccount: fields of the account leaf in the order they are hashed,
+	the asset root is passed apart since it is updated before the leaf
+*/
+func CollectHashInputsFromAccount(account AccountConstraints, assetRoot Variable) (inputs []Variable) {
+	return []Variable{
+		account.AccountNameHash,
+		account.AccountPk.A.X,
+		account.AccountPk.A.Y,
+		account.Nonce,
+		account.CollectionNonce,
+		assetRoot,
+	}
+}
+
+/*
+	CollectHashInputsFromAccountSigners: the signer set of a multi-signature account
+	is hashed with the hash of the other fields of the leaf
+*/
+func CollectHashInputsFromAccountSigners(account AccountConstraints, fieldsHash Variable) (inputs []Variable) {
+	return []Variable{
+		fieldsHash,
+		account.SignerRoot,
+		account.SignerThreshold,
+	}
+}
+
+/*
+	ComputeHashFromAccount: hash of an account leaf with its asset root, an account
+	of a single key keeps the hash of its other fields
+*/
+func ComputeHashFromAccount(api API, account AccountConstraints, assetRoot Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(CollectHashInputsFromAccount(account, assetRoot)...)
+	fieldsHash := hFunc.Sum()
+	hFunc.Reset()
+	hFunc.Write(CollectHashInputsFromAccountSigners(account, fieldsHash)...)
+	signersHash := hFunc.Sum()
+	hFunc.Reset()
+	hasNoSigners := api.And(api.IsZero(account.SignerRoot), api.IsZero(account.SignerThreshold))
+	return api.Select(hasNoSigners, fieldsHash, signersHash)
 }
 
 type AccountAssetConstraints struct {
@@ -51,6 +98,14 @@ type AccountAssetConstraints struct {
 	OfferCanceledOrFinalized Variable
 }
 
+func CollectHashInputsFromAccountAsset(asset AccountAssetConstraints) (inputs []Variable) {
+	return []Variable{
+		asset.Balance,
+		asset.LpAmount,
+		asset.OfferCanceledOrFinalized,
+	}
+}
+
 func SetAccountAssetWitness(asset *AccountAsset) (witness AccountAssetConstraints, err error) {
 	if asset == nil {
 		log.Println("[SetAccountAssetWitness] invalid params")
@@ -81,6 +136,12 @@ func SetAccountWitness(account *Account) (witness AccountConstraints, err error)
 		Nonce:           account.Nonce,
 		CollectionNonce: account.CollectionNonce,
 		AssetRoot:       account.AssetRoot,
+		SignerRoot:      account.SignerRoot,
+		SignerThreshold: account.SignerThreshold,
+	}
+	// accounts serialized before the signer sets have none
+	if account.SignerRoot == nil {
+		witness.SignerRoot = ZeroBigInt
 	}
 	// set assets witness
 	for i := 0; i < NbAccountAssetsPerAccount; i++ {
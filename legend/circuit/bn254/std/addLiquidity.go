@@ -83,9 +83,8 @@ func SetAddLiquidityTxWitness(tx *AddLiquidityTx) (witness AddLiquidityTxConstra
 	return witness
 }
 
-func ComputeHashFromAddLiquidityTx(tx AddLiquidityTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
-	hFunc.Reset()
-	hFunc.Write(
+func CollectHashInputsFromAddLiquidityTx(tx AddLiquidityTxConstraints, nonce Variable, expiredAt Variable) (inputs []Variable) {
+	return []Variable{
 		tx.FromAccountIndex,
 		tx.PairIndex,
 		tx.AssetAAmount,
@@ -96,7 +95,12 @@ func ComputeHashFromAddLiquidityTx(tx AddLiquidityTxConstraints, nonce Variable,
 		expiredAt,
 		nonce,
 		ChainId,
-	)
+	}
+}
+
+func ComputeHashFromAddLiquidityTx(tx AddLiquidityTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(CollectHashInputsFromAddLiquidityTx(tx, nonce, expiredAt)...)
 	hashVal = hFunc.Sum()
 	return hashVal
 }
@@ -105,7 +109,8 @@ func VerifyAddLiquidityTx(
 	api API, flag Variable,
 	tx *AddLiquidityTxConstraints,
 	accountsBefore [NbAccountsPerTx]AccountConstraints, liquidityBefore LiquidityConstraints,
-) (pubData [PubDataSizePerTx]Variable, err error) {
+) (pubData []Variable, err error) {
+	defer ProfileScope(api, "VerifyAddLiquidityTx")()
 	pubData = CollectPubDataFromAddLiquidity(api, *tx)
 	// check params
 	// account index
@@ -126,19 +131,22 @@ func VerifyAddLiquidityTx(
 	tx.AssetAAmount = UnpackAmount(api, tx.AssetAAmount)
 	tx.AssetBAmount = UnpackAmount(api, tx.AssetBAmount)
 	tx.LpAmount = UnpackAmount(api, tx.LpAmount)
+	tx.TreasuryAmount = UnpackAmount(api, tx.TreasuryAmount)
 	tx.GasFeeAssetAmount = UnpackFee(api, tx.GasFeeAssetAmount)
 	IsVariableLessOrEqual(api, flag, tx.AssetAAmount, accountsBefore[0].AssetsInfo[0].Balance)
 	IsVariableLessOrEqual(api, flag, tx.AssetBAmount, accountsBefore[0].AssetsInfo[1].Balance)
 	IsVariableLessOrEqual(api, flag, tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[2].Balance)
-	// verify treasury amount
+	// verify treasury amount, the treasury lp of sqrt(k) only applies to constant
+	// product pairs, k of a StableSwap pair drops on swaps so no lp is minted
+	isStableSwap := api.IsZero(api.Sub(liquidityBefore.PairType, PairTypeStableSwap))
+	isConstantProduct := api.And(flag, api.IsZero(isStableSwap))
 	kCurrent := api.Mul(liquidityBefore.AssetA, liquidityBefore.AssetB)
-	IsVariableLessOrEqual(api, flag, liquidityBefore.KLast, kCurrent)
+	IsVariableLessOrEqual(api, isConstantProduct, liquidityBefore.KLast, kCurrent)
 	IsVariableLessOrEqual(api, flag, liquidityBefore.TreasuryRate, liquidityBefore.FeeRate)
-	sLps, err := api.Compiler().NewHint(ComputeSLp, 1, liquidityBefore.AssetA, liquidityBefore.AssetB, liquidityBefore.KLast, liquidityBefore.FeeRate, liquidityBefore.TreasuryRate)
+	sLp, err := VerifyTreasuryLpAmount(api, isConstantProduct, liquidityBefore)
 	if err != nil {
 		return pubData, err
 	}
-	sLp := sLps[0]
 	IsVariableEqual(api, flag, tx.TreasuryAmount, sLp)
 	// TODO verify ratio
 	l := api.Mul(liquidityBefore.AssetA, tx.AssetBAmount)
@@ -154,6 +162,12 @@ func VerifyAddLiquidityTx(
 	lpAmountSquare := api.Mul(tx.AssetAAmount, tx.AssetBAmount)
 	IsVariableLessOrEqual(api, isZero, api.Mul(tx.LpAmount, tx.LpAmount), lpAmountSquare)
 	notZero := api.IsZero(isZero)
-	IsVariableEqual(api, notZero, api.Mul(tx.LpAmount, liquidityBefore.AssetA), api.Mul(tx.AssetAAmount, liquidityBefore.LpAmount))
+	// treasury lp is minted before the new liquidity
+	poolLpVar := api.Add(liquidityBefore.LpAmount, sLp)
+	IsVariableEqual(api, notZero, api.Mul(tx.LpAmount, liquidityBefore.AssetA), api.Mul(tx.AssetAAmount, poolLpVar))
+	// the reserves of a StableSwap pair are capped so that the pair can still be swapped
+	isStableSwap = api.And(flag, isStableSwap)
+	api.ToBinary(api.Select(isStableSwap, api.Add(liquidityBefore.AssetA, tx.AssetAAmount), 0), StableSwapReserveBitsSize)
+	api.ToBinary(api.Select(isStableSwap, api.Add(liquidityBefore.AssetB, tx.AssetBAmount), 0), StableSwapReserveBitsSize)
 	return pubData, nil
 }
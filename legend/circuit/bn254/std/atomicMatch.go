@@ -52,9 +52,8 @@ func EmptyAtomicMatchTxWitness() (witness AtomicMatchTxConstraints) {
 	}
 }
 
-func ComputeHashFromOfferTx(tx OfferTxConstraints, hFunc MiMC) (hashVal Variable) {
-	hFunc.Reset()
-	hFunc.Write(
+func CollectHashInputsFromOfferTx(tx OfferTxConstraints) (inputs []Variable) {
+	return []Variable{
 		tx.Type,
 		tx.OfferId,
 		tx.AccountIndex,
@@ -64,7 +63,12 @@ func ComputeHashFromOfferTx(tx OfferTxConstraints, hFunc MiMC) (hashVal Variable
 		tx.ListedAt,
 		tx.ExpiredAt,
 		tx.TreasuryRate,
-	)
+	}
+}
+
+func ComputeHashFromOfferTx(tx OfferTxConstraints, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(CollectHashInputsFromOfferTx(tx)...)
 	hashVal = hFunc.Sum()
 	return hashVal
 }
@@ -83,9 +87,8 @@ func SetAtomicMatchTxWitness(tx *AtomicMatchTx) (witness AtomicMatchTxConstraint
 	return witness
 }
 
-func ComputeHashFromAtomicMatchTx(tx AtomicMatchTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
-	hFunc.Reset()
-	hFunc.Write(
+func CollectHashInputsFromAtomicMatchTx(tx AtomicMatchTxConstraints, nonce Variable, expiredAt Variable) (inputs []Variable) {
+	return []Variable{
 		tx.AccountIndex,
 		tx.BuyOffer.Type,
 		tx.BuyOffer.OfferId,
@@ -115,7 +118,12 @@ func ComputeHashFromAtomicMatchTx(tx AtomicMatchTxConstraints, nonce Variable, e
 		expiredAt,
 		nonce,
 		ChainId,
-	)
+	}
+}
+
+func ComputeHashFromAtomicMatchTx(tx AtomicMatchTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(CollectHashInputsFromAtomicMatchTx(tx, nonce, expiredAt)...)
 	hashVal = hFunc.Sum()
 	return hashVal
 }
@@ -127,7 +135,8 @@ func VerifyAtomicMatchTx(
 	nftBefore NftConstraints,
 	blockCreatedAt Variable,
 	hFunc MiMC,
-) (pubData [PubDataSizePerTx]Variable, err error) {
+) (pubData []Variable, err error) {
+	defer ProfileScope(api, "VerifyAtomicMatchTx")()
 	pubData = CollectPubDataFromAtomicMatch(api, *tx)
 	// verify params
 	IsVariableEqual(api, flag, tx.BuyOffer.Type, 0)
@@ -151,6 +160,8 @@ func VerifyAtomicMatchTx(
 	hFunc.Reset()
 	notBuyer := api.IsZero(api.IsZero(api.Sub(tx.AccountIndex, tx.BuyOffer.AccountIndex)))
 	notBuyer = api.And(flag, notBuyer)
+	// a multi-signature account has no key to sign its offers
+	IsVariableEqual(api, notBuyer, accountsBefore[1].SignerThreshold, 0)
 	err = VerifyEddsaSig(notBuyer, api, hFunc, buyOfferHash, accountsBefore[1].AccountPk, tx.BuyOffer.Sig)
 	if err != nil {
 		return pubData, err
@@ -160,6 +171,7 @@ func VerifyAtomicMatchTx(
 	hFunc.Reset()
 	notSeller := api.IsZero(api.IsZero(api.Sub(tx.AccountIndex, tx.SellOffer.AccountIndex)))
 	notSeller = api.And(flag, notSeller)
+	IsVariableEqual(api, notSeller, accountsBefore[2].SignerThreshold, 0)
 	err = VerifyEddsaSig(notSeller, api, hFunc, sellOfferHash, accountsBefore[2].AccountPk, tx.SellOffer.Sig)
 	if err != nil {
 		return pubData, err
@@ -179,7 +191,7 @@ func VerifyAtomicMatchTx(
 	buyOfferIdBits := api.ToBinary(tx.BuyOffer.OfferId, 24)
 	buyAssetId := api.FromBinary(buyOfferIdBits[7:]...)
 	buyOfferIndex := api.Sub(tx.BuyOffer.OfferId, api.Mul(buyAssetId, OfferSizePerAsset))
-	buyOfferIndexBits := api.ToBinary(accountsBefore[1].AssetsInfo[1].OfferCanceledOrFinalized, OfferSizePerAsset)
+	buyOfferIndexBits, _ := SplitOfferCanceledOrFinalized(api, accountsBefore[1].AssetsInfo[1].OfferCanceledOrFinalized)
 	for i := 0; i < OfferSizePerAsset; i++ {
 		isZero := api.IsZero(api.Sub(buyOfferIndex, i))
 		IsVariableEqual(api, isZero, buyOfferIndexBits[i], 0)
@@ -188,7 +200,7 @@ func VerifyAtomicMatchTx(
 	sellOfferIdBits := api.ToBinary(tx.SellOffer.OfferId, 24)
 	sellAssetId := api.FromBinary(sellOfferIdBits[7:]...)
 	sellOfferIndex := api.Sub(tx.SellOffer.OfferId, api.Mul(sellAssetId, OfferSizePerAsset))
-	sellOfferIndexBits := api.ToBinary(accountsBefore[2].AssetsInfo[1].OfferCanceledOrFinalized, OfferSizePerAsset)
+	sellOfferIndexBits, _ := SplitOfferCanceledOrFinalized(api, accountsBefore[2].AssetsInfo[1].OfferCanceledOrFinalized)
 	for i := 0; i < OfferSizePerAsset; i++ {
 		isZero := api.IsZero(api.Sub(sellOfferIndex, i))
 		IsVariableEqual(api, isZero, sellOfferIndexBits[i], 0)
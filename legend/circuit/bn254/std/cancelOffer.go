@@ -54,9 +54,8 @@ func SetCancelOfferTxWitness(tx *CancelOfferTx) (witness CancelOfferTxConstraint
 	return witness
 }
 
-func ComputeHashFromCancelOfferTx(tx CancelOfferTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
-	hFunc.Reset()
-	hFunc.Write(
+func CollectHashInputsFromCancelOfferTx(tx CancelOfferTxConstraints, nonce Variable, expiredAt Variable) (inputs []Variable) {
+	return []Variable{
 		tx.AccountIndex,
 		tx.OfferId,
 		tx.GasAccountIndex,
@@ -65,7 +64,12 @@ func ComputeHashFromCancelOfferTx(tx CancelOfferTxConstraints, nonce Variable, e
 		expiredAt,
 		nonce,
 		ChainId,
-	)
+	}
+}
+
+func ComputeHashFromCancelOfferTx(tx CancelOfferTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(CollectHashInputsFromCancelOfferTx(tx, nonce, expiredAt)...)
 	hashVal = hFunc.Sum()
 	return hashVal
 }
@@ -74,7 +78,8 @@ func VerifyCancelOfferTx(
 	api API, flag Variable,
 	tx *CancelOfferTxConstraints,
 	accountsBefore [NbAccountsPerTx]AccountConstraints,
-) (pubData [PubDataSizePerTx]Variable) {
+) (pubData []Variable) {
+	defer ProfileScope(api, "VerifyCancelOfferTx")()
 	pubData = CollectPubDataFromCancelOffer(api, *tx)
 	// verify params
 	IsVariableEqual(api, flag, tx.AccountIndex, accountsBefore[0].AccountIndex)
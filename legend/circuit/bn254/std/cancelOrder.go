@@ -0,0 +1,106 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package std
+
+import "math/big"
+
+/*
+	CancelOrderTx: the account fills its order slot up to CanceledOrderFilledAmount,
+	so that no order of the slot can be matched anymore
+*/
+type CancelOrderTx struct {
+	AccountIndex      int64
+	OrderId           int64
+	GasAccountIndex   int64
+	GasFeeAssetId     int64
+	GasFeeAssetAmount int64
+}
+
+type CancelOrderTxConstraints struct {
+	AccountIndex      Variable
+	OrderId           Variable
+	GasAccountIndex   Variable
+	GasFeeAssetId     Variable
+	GasFeeAssetAmount Variable
+}
+
+// filled amount of a canceled order slot, the amounts of the orders fit in
+// OrderFilledAmountBitsSize so that none of them can be filled over it
+var CanceledOrderFilledAmount = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), OrderFilledAmountBitsSize), big.NewInt(1))
+
+func EmptyCancelOrderTxWitness() (witness CancelOrderTxConstraints) {
+	return CancelOrderTxConstraints{
+		AccountIndex:      ZeroInt,
+		OrderId:           ZeroInt,
+		GasAccountIndex:   ZeroInt,
+		GasFeeAssetId:     ZeroInt,
+		GasFeeAssetAmount: ZeroInt,
+	}
+}
+
+func SetCancelOrderTxWitness(tx *CancelOrderTx) (witness CancelOrderTxConstraints) {
+	witness = CancelOrderTxConstraints{
+		AccountIndex:      tx.AccountIndex,
+		OrderId:           tx.OrderId,
+		GasAccountIndex:   tx.GasAccountIndex,
+		GasFeeAssetId:     tx.GasFeeAssetId,
+		GasFeeAssetAmount: tx.GasFeeAssetAmount,
+	}
+	return witness
+}
+
+func CollectHashInputsFromCancelOrderTx(tx CancelOrderTxConstraints, nonce Variable, expiredAt Variable) (inputs []Variable) {
+	return []Variable{
+		tx.AccountIndex,
+		tx.OrderId,
+		tx.GasAccountIndex,
+		tx.GasFeeAssetId,
+		tx.GasFeeAssetAmount,
+		expiredAt,
+		nonce,
+		ChainId,
+	}
+}
+
+func ComputeHashFromCancelOrderTx(tx CancelOrderTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(CollectHashInputsFromCancelOrderTx(tx, nonce, expiredAt)...)
+	hashVal = hFunc.Sum()
+	return hashVal
+}
+
+func VerifyCancelOrderTx(
+	api API, flag Variable,
+	tx *CancelOrderTxConstraints,
+	accountsBefore [NbAccountsPerTx]AccountConstraints,
+) (pubData []Variable) {
+	defer ProfileScope(api, "VerifyCancelOrderTx")()
+	pubData = CollectPubDataFromCancelOrder(api, *tx)
+	// verify params
+	IsVariableEqual(api, flag, tx.AccountIndex, accountsBefore[0].AccountIndex)
+	IsVariableEqual(api, flag, tx.GasAccountIndex, accountsBefore[1].AccountIndex)
+	IsVariableEqual(api, flag, tx.GasFeeAssetId, accountsBefore[0].AssetsInfo[0].AssetId)
+	IsVariableEqual(api, flag, tx.GasFeeAssetId, accountsBefore[1].AssetsInfo[0].AssetId)
+	// verify order slot, it can't be the leaf of the gas fee asset
+	IsVariableEqual(api, flag, tx.OrderId, accountsBefore[0].AssetsInfo[1].AssetId)
+	IsVariableEqual(api, flag, api.IsZero(api.Sub(tx.OrderId, tx.GasFeeAssetId)), 0)
+	// should have enough balance
+	tx.GasFeeAssetAmount = UnpackFee(api, tx.GasFeeAssetAmount)
+	IsVariableLessOrEqual(api, flag, tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[0].Balance)
+	return pubData
+}
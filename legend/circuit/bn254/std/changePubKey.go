@@ -0,0 +1,151 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package std
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+)
+
+/*
+	ChangePubKeyTx: the account gets a new key, or a signer set if SignerThreshold
+	isn't 0, in which case PubKey is empty
+*/
+type ChangePubKeyTx struct {
+	AccountIndex      int64
+	PubKey            *eddsa.PublicKey
+	GasAccountIndex   int64
+	GasFeeAssetId     int64
+	GasFeeAssetAmount int64
+	ExpiredAt         int64
+	Nonce             int64
+	SignerRoot        []byte
+	SignerThreshold   int64
+}
+
+type ChangePubKeyTxConstraints struct {
+	AccountIndex      Variable
+	PubKey            PublicKeyConstraints
+	GasAccountIndex   Variable
+	GasFeeAssetId     Variable
+	GasFeeAssetAmount Variable
+	ExpiredAt         Variable
+	Nonce             Variable
+	SignerRoot        Variable
+	SignerThreshold   Variable
+}
+
+func EmptyChangePubKeyTxWitness() (witness ChangePubKeyTxConstraints) {
+	return ChangePubKeyTxConstraints{
+		AccountIndex:      ZeroInt,
+		PubKey:            EmptyPublicKeyWitness(),
+		GasAccountIndex:   ZeroInt,
+		GasFeeAssetId:     ZeroInt,
+		GasFeeAssetAmount: ZeroInt,
+		ExpiredAt:         ZeroInt,
+		Nonce:             ZeroInt,
+		SignerRoot:        ZeroInt,
+		SignerThreshold:   ZeroInt,
+	}
+}
+
+func SetChangePubKeyTxWitness(tx *ChangePubKeyTx) (witness ChangePubKeyTxConstraints) {
+	witness = ChangePubKeyTxConstraints{
+		AccountIndex:      tx.AccountIndex,
+		PubKey:            SetPubKeyWitness(tx.PubKey),
+		GasAccountIndex:   tx.GasAccountIndex,
+		GasFeeAssetId:     tx.GasFeeAssetId,
+		GasFeeAssetAmount: tx.GasFeeAssetAmount,
+		ExpiredAt:         tx.ExpiredAt,
+		Nonce:             tx.Nonce,
+		SignerRoot:        tx.SignerRoot,
+		SignerThreshold:   tx.SignerThreshold,
+	}
+	if tx.SignerRoot == nil {
+		witness.SignerRoot = ZeroInt
+	}
+	return witness
+}
+
+func CollectHashInputsFromChangePubKeyTx(tx ChangePubKeyTxConstraints, nonce Variable, expiredAt Variable) (inputs []Variable) {
+	return []Variable{
+		tx.AccountIndex,
+		tx.PubKey.A.X,
+		tx.PubKey.A.Y,
+		tx.GasAccountIndex,
+		tx.GasFeeAssetId,
+		tx.GasFeeAssetAmount,
+		expiredAt,
+		nonce,
+		ChainId,
+	}
+}
+
+/*
+	CollectHashInputsFromChangePubKeySigners: the signer set is signed with the hash
+	of the other fields, so the changes to a single key keep their signatures
+*/
+func CollectHashInputsFromChangePubKeySigners(tx ChangePubKeyTxConstraints, fieldsHash Variable) (inputs []Variable) {
+	return []Variable{
+		fieldsHash,
+		tx.SignerRoot,
+		tx.SignerThreshold,
+	}
+}
+
+func ComputeHashFromChangePubKeyTx(api API, tx ChangePubKeyTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(CollectHashInputsFromChangePubKeyTx(tx, nonce, expiredAt)...)
+	fieldsHash := hFunc.Sum()
+	hFunc.Reset()
+	hFunc.Write(CollectHashInputsFromChangePubKeySigners(tx, fieldsHash)...)
+	signersHash := hFunc.Sum()
+	hasNoSigners := api.And(api.IsZero(tx.SignerRoot), api.IsZero(tx.SignerThreshold))
+	hashVal = api.Select(hasNoSigners, fieldsHash, signersHash)
+	return hashVal
+}
+
+/*
+	VerifyChangePubKeyTx: the new key of the account is signed by its current key
+	or signer set, the signature is verified with the other layer2 txs. A signer
+	set replaces the key with the empty key, and a key clears the signer set.
+*/
+func VerifyChangePubKeyTx(
+	api API, flag Variable,
+	tx *ChangePubKeyTxConstraints,
+	accountsBefore [NbAccountsPerTx]AccountConstraints,
+) (pubData []Variable) {
+	defer ProfileScope(api, "VerifyChangePubKeyTx")()
+	pubData = CollectPubDataFromChangePubKey(api, *tx)
+	// account index
+	IsVariableEqual(api, flag, tx.AccountIndex, accountsBefore[0].AccountIndex)
+	IsVariableEqual(api, flag, tx.GasAccountIndex, accountsBefore[1].AccountIndex)
+	// asset id
+	IsVariableEqual(api, flag, tx.GasFeeAssetId, accountsBefore[0].AssetsInfo[0].AssetId)
+	IsVariableEqual(api, flag, tx.GasFeeAssetId, accountsBefore[1].AssetsInfo[0].AssetId)
+	// should have enough assets
+	tx.GasFeeAssetAmount = UnpackFee(api, tx.GasFeeAssetAmount)
+	IsVariableLessOrEqual(api, flag, tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[0].Balance)
+	// signer set
+	IsVariableLessOrEqual(api, flag, tx.SignerThreshold, NbMultiSigsPerTx)
+	isSingleKey := api.And(flag, api.IsZero(tx.SignerThreshold))
+	isMultiSig := api.And(flag, api.Sub(1, api.IsZero(tx.SignerThreshold)))
+	IsVariableEqual(api, isSingleKey, tx.SignerRoot, ZeroInt)
+	IsVariableEqual(api, isMultiSig, tx.PubKey.A.X, ZeroInt)
+	IsVariableEqual(api, isMultiSig, tx.PubKey.A.Y, ZeroInt)
+	return pubData
+}
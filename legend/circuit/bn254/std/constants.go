@@ -40,10 +40,21 @@ const (
 
 	NbAccountAssetsPerAccount = 4
 	NbAccountsPerTx           = 5
+	// pairs of a route swap, a route goes through at least 2 of them
+	NbRoutePairsPerTx  = 3
+	MinRoutePairsCount = 2
+	// signatures verified for a multi-signature account, its threshold is at most
+	// NbMultiSigsPerTx and its signer set has at most 2^SignerMerkleLevels keys
+	NbMultiSigsPerTx   = 3
+	SignerMerkleLevels = 3
 
+	// max pubdata chunks of a tx, each chunk is a 32-byte field element
 	PubDataSizePerTx = 6
+	PubDataChunkSize = 32
 
 	OfferSizePerAsset = 128
+	// the filled amount of an order is kept above the offer bits of its order slot
+	OrderFilledAmountBitsSize = 125
 
 	ChainId = common.ChainId
 )
@@ -68,12 +79,87 @@ const (
 	TxTypeWithdrawNft
 	TxTypeFullExit
 	TxTypeFullExitNft
+	TxTypeChangePubKey
+	TxTypeFullChangePubKey
+	TxTypeMatchOrder
+	TxTypeRouteSwap
+	TxTypeCancelOrder
+)
+
+// pubdata chunks written by each tx type
+const (
+	RegisterZnsPubDataChunks      = 5
+	CreatePairPubDataChunks       = 1
+	UpdatePairRatePubDataChunks   = 1
+	DepositPubDataChunks          = 2
+	DepositNftPubDataChunks       = 5
+	TransferPubDataChunks         = 2
+	SwapPubDataChunks             = 1
+	AddLiquidityPubDataChunks     = 2
+	RemoveLiquidityPubDataChunks  = 2
+	WithdrawPubDataChunks         = 2
+	CreateCollectionPubDataChunks = 1
+	MintNftPubDataChunks          = 2
+	TransferNftPubDataChunks      = 2
+	AtomicMatchPubDataChunks      = 2
+	CancelOfferPubDataChunks      = 1
+	WithdrawNftPubDataChunks      = 6
+	FullExitPubDataChunks         = 2
+	FullExitNftPubDataChunks      = 6
+	ChangePubKeyPubDataChunks     = 4
+	FullChangePubKeyPubDataChunks = 4
+	MatchOrderPubDataChunks       = 2
+	RouteSwapPubDataChunks        = 2
+	CancelOrderPubDataChunks      = 1
 )
 
 const (
 	RateBase = 10000
 )
 
+// curves of the pairs
+const (
+	// x * y = k
+	PairTypeConstantProduct = iota
+	// StableSwap invariant with an amplification coefficient
+	PairTypeStableSwap
+)
+
+// modes of a swap
+const (
+	// the amount in is signed with a min amount out
+	SwapModeExactInput = iota
+	// the amount out is signed with a max amount in
+	SwapModeExactOutput
+)
+
 var (
+	PubDataChunksPerTxType = map[int]int{
+		TxTypeEmptyTx:          0,
+		TxTypeRegisterZns:      RegisterZnsPubDataChunks,
+		TxTypeCreatePair:       CreatePairPubDataChunks,
+		TxTypeUpdatePairRate:   UpdatePairRatePubDataChunks,
+		TxTypeDeposit:          DepositPubDataChunks,
+		TxTypeDepositNft:       DepositNftPubDataChunks,
+		TxTypeTransfer:         TransferPubDataChunks,
+		TxTypeSwap:             SwapPubDataChunks,
+		TxTypeAddLiquidity:     AddLiquidityPubDataChunks,
+		TxTypeRemoveLiquidity:  RemoveLiquidityPubDataChunks,
+		TxTypeWithdraw:         WithdrawPubDataChunks,
+		TxTypeCreateCollection: CreateCollectionPubDataChunks,
+		TxTypeMintNft:          MintNftPubDataChunks,
+		TxTypeTransferNft:      TransferNftPubDataChunks,
+		TxTypeAtomicMatch:      AtomicMatchPubDataChunks,
+		TxTypeCancelOffer:      CancelOfferPubDataChunks,
+		TxTypeWithdrawNft:      WithdrawNftPubDataChunks,
+		TxTypeFullExit:         FullExitPubDataChunks,
+		TxTypeFullExitNft:      FullExitNftPubDataChunks,
+		TxTypeChangePubKey:     ChangePubKeyPubDataChunks,
+		TxTypeFullChangePubKey: FullChangePubKeyPubDataChunks,
+		TxTypeMatchOrder:       MatchOrderPubDataChunks,
+		TxTypeRouteSwap:        RouteSwapPubDataChunks,
+		TxTypeCancelOrder:      CancelOrderPubDataChunks,
+	}
+
 	EmptyAssetRoot, _ = new(big.Int).SetString("20078765925047610631302921414746503738259000135611824775363050619361913896775", 10)
 )
@@ -62,9 +62,8 @@ func SetCreateCollectionTxWitness(tx *CreateCollectionTx) (witness CreateCollect
 	return witness
 }
 
-func ComputeHashFromCreateCollectionTx(tx CreateCollectionTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
-	hFunc.Reset()
-	hFunc.Write(
+func CollectHashInputsFromCreateCollectionTx(tx CreateCollectionTxConstraints, nonce Variable, expiredAt Variable) (inputs []Variable) {
+	return []Variable{
 		tx.AccountIndex,
 		tx.GasAccountIndex,
 		tx.GasFeeAssetId,
@@ -72,7 +71,12 @@ func ComputeHashFromCreateCollectionTx(tx CreateCollectionTxConstraints, nonce V
 		expiredAt,
 		nonce,
 		ChainId,
-	)
+	}
+}
+
+func ComputeHashFromCreateCollectionTx(tx CreateCollectionTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(CollectHashInputsFromCreateCollectionTx(tx, nonce, expiredAt)...)
 	hashVal = hFunc.Sum()
 	return hashVal
 }
@@ -81,7 +85,8 @@ func VerifyCreateCollectionTx(
 	api API, flag Variable,
 	tx *CreateCollectionTxConstraints,
 	accountsBefore [NbAccountsPerTx]AccountConstraints,
-) (pubData [PubDataSizePerTx]Variable) {
+) (pubData []Variable) {
+	defer ProfileScope(api, "VerifyCreateCollectionTx")()
 	pubData = CollectPubDataFromCreateCollection(api, *tx)
 	// verify params
 	IsVariableLessOrEqual(api, flag, tx.CollectionId, 65535)
@@ -24,6 +24,8 @@ type CreatePairTx struct {
 	FeeRate              int64
 	TreasuryAccountIndex int64
 	TreasuryRate         int64
+	PairType             int64
+	Amplification        int64
 }
 
 type CreatePairTxConstraints struct {
@@ -33,6 +35,8 @@ type CreatePairTxConstraints struct {
 	FeeRate              Variable
 	TreasuryAccountIndex Variable
 	TreasuryRate         Variable
+	PairType             Variable
+	Amplification        Variable
 }
 
 func EmptyCreatePairTxWitness() (witness CreatePairTxConstraints) {
@@ -43,6 +47,8 @@ func EmptyCreatePairTxWitness() (witness CreatePairTxConstraints) {
 		FeeRate:              ZeroInt,
 		TreasuryAccountIndex: ZeroInt,
 		TreasuryRate:         ZeroInt,
+		PairType:             ZeroInt,
+		Amplification:        ZeroInt,
 	}
 }
 
@@ -54,6 +60,8 @@ func SetCreatePairTxWitness(tx *CreatePairTx) (witness CreatePairTxConstraints)
 		FeeRate:              tx.FeeRate,
 		TreasuryAccountIndex: tx.TreasuryAccountIndex,
 		TreasuryRate:         tx.TreasuryRate,
+		PairType:             tx.PairType,
+		Amplification:        tx.Amplification,
 	}
 	return witness
 }
@@ -62,10 +70,17 @@ func VerifyCreatePairTx(
 	api API, flag Variable,
 	tx CreatePairTxConstraints,
 	liquidityBefore LiquidityConstraints,
-) (pubData [PubDataSizePerTx]Variable) {
+) (pubData []Variable) {
+	defer ProfileScope(api, "VerifyCreatePairTx")()
 	pubData = CollectPubDataFromCreatePair(api, tx)
 	// verify params
 	IsVariableEqual(api, flag, tx.PairIndex, liquidityBefore.PairIndex)
 	CheckEmptyLiquidityNode(api, flag, liquidityBefore)
+	// pair type, the StableSwap invariant needs an amplification of at least 1
+	isConstantProduct := api.IsZero(api.Sub(tx.PairType, PairTypeConstantProduct))
+	isStableSwap := api.IsZero(api.Sub(tx.PairType, PairTypeStableSwap))
+	IsVariableEqual(api, flag, api.Or(isConstantProduct, isStableSwap), 1)
+	IsVariableEqual(api, api.And(flag, isConstantProduct), tx.Amplification, 0)
+	IsVariableLessOrEqual(api, api.And(flag, isStableSwap), 1, tx.Amplification)
 	return pubData
 }
@@ -56,7 +56,8 @@ func VerifyDepositTx(
 	api API, flag Variable,
 	tx DepositTxConstraints,
 	accountsBefore [NbAccountsPerTx]AccountConstraints,
-) (pubData [PubDataSizePerTx]Variable) {
+) (pubData []Variable) {
+	defer ProfileScope(api, "VerifyDepositTx")()
 	pubData = CollectPubDataFromDeposit(api, tx)
 	// verify params
 	IsVariableEqual(api, flag, tx.AccountNameHash, accountsBefore[0].AccountNameHash)
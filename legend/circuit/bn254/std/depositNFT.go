@@ -78,7 +78,8 @@ func VerifyDepositNftTx(
 	tx DepositNftTxConstraints,
 	accountsBefore [NbAccountsPerTx]AccountConstraints,
 	nftBefore NftConstraints,
-) (pubData [PubDataSizePerTx]Variable) {
+) (pubData []Variable) {
+	defer ProfileScope(api, "VerifyDepositNftTx")()
 	pubData = CollectPubDataFromDepositNft(api, tx)
 	// verify params
 	// check empty nft
@@ -28,6 +28,7 @@ import (
 )
 
 func VerifyEddsaSig(flag Variable, api API, hFunc MiMC, hashVal Variable, pk PublicKeyConstraints, sig eddsa.Signature) error {
+	defer ProfileScope(api, "VerifyEddsaSig")()
 	curve, err := twistededwards.NewEdCurve(api, tedwards.BN254)
 	if err != nil {
 		return err
@@ -0,0 +1,68 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package std
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+)
+
+/*
+	FullChangePubKeyTx: key rotation requested on L1 by the owner of the account,
+	for a lost L2 key
+*/
+type FullChangePubKeyTx struct {
+	AccountIndex    int64
+	AccountNameHash []byte
+	PubKey          *eddsa.PublicKey
+}
+
+type FullChangePubKeyTxConstraints struct {
+	AccountIndex    Variable
+	AccountNameHash Variable
+	PubKey          PublicKeyConstraints
+}
+
+func EmptyFullChangePubKeyTxWitness() (witness FullChangePubKeyTxConstraints) {
+	return FullChangePubKeyTxConstraints{
+		AccountIndex:    ZeroInt,
+		AccountNameHash: ZeroInt,
+		PubKey:          EmptyPublicKeyWitness(),
+	}
+}
+
+func SetFullChangePubKeyTxWitness(tx *FullChangePubKeyTx) (witness FullChangePubKeyTxConstraints) {
+	witness = FullChangePubKeyTxConstraints{
+		AccountIndex:    tx.AccountIndex,
+		AccountNameHash: tx.AccountNameHash,
+		PubKey:          SetPubKeyWitness(tx.PubKey),
+	}
+	return witness
+}
+
+func VerifyFullChangePubKeyTx(
+	api API, flag Variable,
+	tx FullChangePubKeyTxConstraints,
+	accountsBefore [NbAccountsPerTx]AccountConstraints,
+) (pubData []Variable) {
+	defer ProfileScope(api, "VerifyFullChangePubKeyTx")()
+	pubData = CollectPubDataFromFullChangePubKey(api, tx)
+	// verify params
+	IsVariableEqual(api, flag, tx.AccountNameHash, accountsBefore[0].AccountNameHash)
+	IsVariableEqual(api, flag, tx.AccountIndex, accountsBefore[0].AccountIndex)
+	return pubData
+}
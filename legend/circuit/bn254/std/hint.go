@@ -42,29 +42,166 @@ func CleanPackedAmount(amount *big.Int) (nAmount *big.Int, err error) {
 	return nAmount, nil
 }
 
+/*
+	ComputeSLp: witnesses of the treasury lp amount, all of them are checked by VerifyTreasuryLpAmount.
+	outputs: sqrt(kLast), sqrt(kCurrent), feeRate / treasuryRate, feeRate % treasuryRate,
+	l / r, l % r, mantissa and exponent of the packed treasury lp amount
+*/
 func ComputeSLp(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
-	if len(inputs) != 5 {
+	if len(inputs) != 5 || len(outputs) != 8 {
+		log.Println("[ComputeSLp] invalid params")
 		return errors.New("[ComputeSLp] invalid params")
 	}
-	poolA := inputs[0]
-	poolB := inputs[1]
-	kLast := inputs[2]
-	feeRate := inputs[3]
-	treasuryRate := inputs[4]
+	witness, err := computeSLpWitness(inputs[0], inputs[1], inputs[2], inputs[3], inputs[4])
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(outputs); i++ {
+		outputs[i].Set(witness[i])
+	}
+	return nil
+}
+
+/*
+	ComputeSLpAmount: treasury lp amount minted before a liquidity change, same result as the circuit
+*/
+func ComputeSLpAmount(poolA, poolB, kLast, feeRate, treasuryRate *big.Int) (sLp *big.Int, err error) {
+	witness, err := computeSLpWitness(poolA, poolB, kLast, feeRate, treasuryRate)
+	if err != nil {
+		return nil, err
+	}
+	return ffmath.Multiply(witness[6], new(big.Int).Exp(big.NewInt(10), witness[7], nil)), nil
+}
+
+func computeSLpWitness(poolA, poolB, kLast, feeRate, treasuryRate *big.Int) (witness [8]*big.Int, err error) {
+	for i := 0; i < len(witness); i++ {
+		witness[i] = big.NewInt(0)
+	}
+	kCurrent := ffmath.Multiply(poolA, poolB)
+	sqrtKLast := new(big.Int).Sqrt(kLast)
+	sqrtKCurrent := new(big.Int).Sqrt(kCurrent)
+	witness[0], witness[1] = sqrtKLast, sqrtKCurrent
+	if treasuryRate.Cmp(ZeroBigInt) == 0 {
+		return witness, nil
+	}
+	feeRatio, feeRatioRem := new(big.Int).QuoRem(feeRate, treasuryRate, new(big.Int))
+	witness[2], witness[3] = feeRatio, feeRatioRem
 	if poolA.Cmp(ZeroBigInt) == 0 || poolB.Cmp(ZeroBigInt) == 0 {
-		outputs[0] = ZeroBigInt
+		return witness, nil
+	}
+	rateBase := big.NewInt(RateBase)
+	l := ffmath.Multiply(ffmath.Sub(sqrtKCurrent, sqrtKLast), rateBase)
+	r := ffmath.Multiply(ffmath.Sub(ffmath.Multiply(rateBase, feeRatio), rateBase), sqrtKCurrent)
+	r = ffmath.Add(r, ffmath.Multiply(rateBase, sqrtKLast))
+	if r.Cmp(ZeroBigInt) <= 0 || l.Cmp(ZeroBigInt) < 0 {
+		return witness, nil
+	}
+	sLpAmount, sLpRem := new(big.Int).QuoRem(l, r, new(big.Int))
+	witness[4], witness[5] = sLpAmount, sLpRem
+	if sLpAmount.Cmp(PackedAmountMaxAmount) > 0 {
+		log.Println("[computeSLpWitness] invalid treasury lp amount")
+		return witness, errors.New("[computeSLpWitness] invalid treasury lp amount")
+	}
+	mantissa := new(big.Int).Set(sLpAmount)
+	exponent := int64(0)
+	for mantissa.Cmp(PackedAmountMaxMantissa) > 0 {
+		mantissa = ffmath.Div(mantissa, big.NewInt(10))
+		exponent++
+	}
+	witness[6], witness[7] = mantissa, big.NewInt(exponent)
+	return witness, nil
+}
+
+/*
+	ComputeStableSwap: witnesses of a swap against a StableSwap pair, both are checked by VerifyStableSwap.
+	inputs: amplification, reserve in, reserve out, amount in, fee rate
+	outputs: invariant of the reserves, amount in after the fee
+*/
+func ComputeStableSwap(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	if len(inputs) != 5 || len(outputs) != 2 {
+		log.Println("[ComputeStableSwap] invalid params")
+		return errors.New("[ComputeStableSwap] invalid params")
+	}
+	outputs[0].Set(ComputeStableSwapInvariant(inputs[0], inputs[1], inputs[2]))
+	// the fee rate is checked by the swap
+	if inputs[4].Cmp(big.NewInt(RateBase)) > 0 {
+		outputs[1].SetInt64(0)
 		return nil
 	}
-	kCurrent := ffmath.Multiply(poolA, poolB)
-	kLast.Sqrt(kLast)
-	kCurrent.Sqrt(kCurrent)
-	l := ffmath.Multiply(ffmath.Sub(kCurrent, kLast), big.NewInt(RateBase))
-	r := ffmath.Multiply(ffmath.Sub(ffmath.Multiply(big.NewInt(RateBase), ffmath.Div(feeRate, treasuryRate)), big.NewInt(RateBase)), kCurrent)
-	r = ffmath.Add(r, ffmath.Multiply(big.NewInt(RateBase), kLast))
-	var err error
-	outputs[0], err = CleanPackedAmount(ffmath.Div(l, r))
-	if err != nil {
-		return err
+	amountInAfterFee := ffmath.Multiply(inputs[3], ffmath.Sub(big.NewInt(RateBase), inputs[4]))
+	outputs[1].Quo(amountInAfterFee, big.NewInt(RateBase))
+	return nil
+}
+
+/*
+	ComputePriceCumulative: witnesses of a price accumulator update, both are checked by accumulatePrice.
+	inputs: numerator, denominator, elapsed time, accumulator
+	outputs: price, number of times the accumulator wraps
+*/
+func ComputePriceCumulative(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	if len(inputs) != 4 || len(outputs) != 2 {
+		log.Println("[ComputePriceCumulative] invalid params")
+		return errors.New("[ComputePriceCumulative] invalid params")
+	}
+	if inputs[1].Sign() == 0 {
+		outputs[0].SetInt64(0)
+		outputs[1].SetInt64(0)
+		return nil
 	}
+	price := ComputeLiquidityPrice(inputs[0], inputs[1])
+	cumulative := new(big.Int).Add(inputs[3], new(big.Int).Mul(price, inputs[2]))
+	outputs[0].Set(price)
+	outputs[1].Rsh(cumulative, PriceCumulativeBitsSize)
 	return nil
 }
+
+/*
+	ComputeStableSwapInvariant: smallest D with f(D) <= 0,
+		f(D) = 16A * x * y * (x + y) + 4 * x * y * D - 16A * x * y * D - D^3
+	f decreases for A >= 1, the Newton iterations start from D = x + y, where f(D) <= 0,
+	and stay above the root up to the rounding, which is then adjusted
+*/
+func ComputeStableSwapInvariant(amplification, x, y *big.Int) (d *big.Int) {
+	xy := ffmath.Multiply(x, y)
+	if xy.Sign() <= 0 || amplification.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+	// D = (2 * D^3 + 16A * x * y * (x + y)) / (3 * D^2 + 16A * x * y - 4 * x * y)
+	axy := ffmath.Multiply(big.NewInt(16), ffmath.Multiply(amplification, xy))
+	c0 := ffmath.Multiply(axy, ffmath.Add(x, y))
+	c1 := ffmath.Sub(axy, ffmath.Multiply(big.NewInt(4), xy))
+	d = ffmath.Add(x, y)
+	for i := 0; i < 255; i++ {
+		d2 := ffmath.Multiply(d, d)
+		numerator := ffmath.Add(ffmath.Multiply(big.NewInt(2), ffmath.Multiply(d2, d)), c0)
+		denominator := ffmath.Add(ffmath.Multiply(big.NewInt(3), d2), c1)
+		next := new(big.Int).Quo(numerator, denominator)
+		if next.Cmp(d) >= 0 {
+			break
+		}
+		d = next
+	}
+	for !isStableSwapInvariantAbove(amplification, x, y, d) {
+		d = ffmath.Add(d, big.NewInt(1))
+	}
+	for d.Sign() > 0 && isStableSwapInvariantAbove(amplification, x, y, ffmath.Sub(d, big.NewInt(1))) {
+		d = ffmath.Sub(d, big.NewInt(1))
+	}
+	return d
+}
+
+/*
+	StableSwapInvariantSides: f(D) = l - r, both sides are non negative
+*/
+func StableSwapInvariantSides(amplification, x, y, d *big.Int) (l, r *big.Int) {
+	xy := ffmath.Multiply(x, y)
+	axy := ffmath.Multiply(big.NewInt(16), ffmath.Multiply(amplification, xy))
+	l = ffmath.Add(ffmath.Multiply(axy, ffmath.Add(x, y)), ffmath.Multiply(big.NewInt(4), ffmath.Multiply(xy, d)))
+	r = ffmath.Add(ffmath.Multiply(axy, d), ffmath.Multiply(d, ffmath.Multiply(d, d)))
+	return l, r
+}
+
+func isStableSwapInvariantAbove(amplification, x, y, d *big.Int) bool {
+	l, r := StableSwapInvariantSides(amplification, x, y, d)
+	return l.Cmp(r) <= 0
+}
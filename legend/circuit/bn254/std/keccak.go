@@ -0,0 +1,283 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package std
+
+import "math/big"
+
+const (
+	KeccakLaneBitsSize  = 64
+	KeccakStateLanes    = 25
+	KeccakRounds        = 24
+	Keccak256RateBytes  = 136
+	Keccak256OutputSize = 32
+)
+
+var (
+	keccakRoundConstants = [KeccakRounds]uint64{
+		0x0000000000000001, 0x0000000000008082, 0x800000000000808A, 0x8000000080008000,
+		0x000000000000808B, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+		0x000000000000008A, 0x0000000000000088, 0x0000000080008009, 0x000000008000000A,
+		0x000000008000808B, 0x800000000000008B, 0x8000000000008089, 0x8000000000008003,
+		0x8000000000008002, 0x8000000000000080, 0x000000000000800A, 0x800000008000000A,
+		0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+	}
+	// keccakRotationOffsets[x][y]
+	keccakRotationOffsets = [5][5]int{
+		{0, 36, 3, 41, 18},
+		{1, 44, 10, 45, 2},
+		{62, 6, 43, 15, 61},
+		{28, 55, 25, 21, 56},
+		{27, 20, 39, 8, 14},
+	}
+)
+
+// keccakBit keeps negations symbolic so that every xor works on single variables
+type keccakBit struct {
+	v   Variable
+	neg bool
+}
+
+type keccakLane [KeccakLaneBitsSize]keccakBit
+
+type keccakState [KeccakStateLanes]keccakLane
+
+/*
+	Keccak256Variables: in-circuit counterpart of the Keccak256 hint, every input is
+	serialized as a 32-byte big-endian word and the digest is returned as a field element
+*/
+func Keccak256Variables(api API, inputs ...Variable) Variable {
+	msgBits := make([]Variable, 0, len(inputs)*256)
+	for _, input := range inputs {
+		msgBits = append(msgBits, VariableToKeccakBytesBits(api, input)...)
+	}
+	digestBits := Keccak256Bits(api, msgBits)
+	return KeccakDigestToVariable(api, digestBits)
+}
+
+/*
+	TruncatedKeccak256Variables: Keccak256Variables keeping the low bitsSize bits of the
+	digest read as a big-endian integer, below the field order for bitsSize < 254, so the
+	L1 contract gets the same value by masking the digest instead of reducing it
+*/
+func TruncatedKeccak256Variables(api API, bitsSize int, inputs ...Variable) Variable {
+	msgBits := make([]Variable, 0, len(inputs)*256)
+	for _, input := range inputs {
+		msgBits = append(msgBits, VariableToKeccakBytesBits(api, input)...)
+	}
+	digestBits := Keccak256Bits(api, msgBits)
+	var bits [Keccak256OutputSize * 8]Variable
+	for i := 0; i < Keccak256OutputSize; i++ {
+		copy(bits[(31-i)*8:(32-i)*8], digestBits[i*8:(i+1)*8])
+	}
+	return api.FromBinary(bits[:bitsSize]...)
+}
+
+/*
+	VariableToKeccakBytesBits: decompose a field element into the bits of its canonical
+	32-byte big-endian encoding, ordered as keccak consumes them (byte by byte, lsb first)
+*/
+func VariableToKeccakBytesBits(api API, v Variable) (msgBits []Variable) {
+	bits := api.ToBinary(v, 256)
+	AssertBitsLessOrEqualConstant(api, bits, new(big.Int).Sub(api.Compiler().Curve().Info().Fr.Modulus(), big.NewInt(1)))
+	msgBits = make([]Variable, 256)
+	for i := 0; i < 32; i++ {
+		copy(msgBits[i*8:(i+1)*8], bits[(31-i)*8:(32-i)*8])
+	}
+	return msgBits
+}
+
+/*
+	KeccakDigestToVariable: interpret the 32-byte digest as a big-endian integer, the
+	same way the Keccak256 hint sets its output, which reduces it modulo the field order
+*/
+func KeccakDigestToVariable(api API, digestBits [Keccak256OutputSize * 8]Variable) Variable {
+	var bits [Keccak256OutputSize * 8]Variable
+	for i := 0; i < Keccak256OutputSize; i++ {
+		copy(bits[(31-i)*8:(32-i)*8], digestBits[i*8:(i+1)*8])
+	}
+	return api.FromBinary(bits[:]...)
+}
+
+/*
+	AssertBitsLessOrEqualConstant: check the little-endian bits are no larger than bound
+*/
+func AssertBitsLessOrEqualConstant(api API, bits []Variable, bound *big.Int) {
+	var isEqualSoFar Variable = 1
+	for i := len(bits) - 1; i >= 0; i-- {
+		if bound.Bit(i) == 1 {
+			isEqualSoFar = api.Mul(isEqualSoFar, bits[i])
+		} else {
+			api.AssertIsEqual(api.Mul(isEqualSoFar, bits[i]), 0)
+		}
+	}
+}
+
+/*
+	Keccak256Bits: keccak256 of a message whose length is known at compile time,
+	msgBits holds the message bytes in order, each byte lsb first.
+	The digest bits follow the same layout.
+*/
+func Keccak256Bits(api API, msgBits []Variable) (digestBits [Keccak256OutputSize * 8]Variable) {
+	if len(msgBits)%8 != 0 {
+		panic("keccak256: message should be byte aligned")
+	}
+	// pad10*1
+	msgLen := len(msgBits) / 8
+	paddedLen := (msgLen/Keccak256RateBytes + 1) * Keccak256RateBytes
+	padded := make([]Variable, paddedLen*8)
+	copy(padded, msgBits)
+	for i := len(msgBits); i < len(padded); i++ {
+		padded[i] = 0
+	}
+	padded[msgLen*8] = 1
+	padded[len(padded)-1] = 1
+
+	var state keccakState
+	for i := 0; i < KeccakStateLanes; i++ {
+		for j := 0; j < KeccakLaneBitsSize; j++ {
+			state[i][j] = keccakBit{v: 0}
+		}
+	}
+	// absorb
+	for offset := 0; offset < len(padded); offset += Keccak256RateBytes * 8 {
+		block := padded[offset : offset+Keccak256RateBytes*8]
+		for i := 0; i < Keccak256RateBytes/8; i++ {
+			for j := 0; j < KeccakLaneBitsSize; j++ {
+				state[i][j] = xorBits(api, state[i][j], keccakBit{v: block[i*KeccakLaneBitsSize+j]})
+			}
+		}
+		state = keccakF1600(api, state)
+	}
+	// squeeze, 256 bits fit in the first block
+	for i := 0; i < Keccak256OutputSize*8/KeccakLaneBitsSize; i++ {
+		for j := 0; j < KeccakLaneBitsSize; j++ {
+			digestBits[i*KeccakLaneBitsSize+j] = bitValue(api, state[i][j])
+		}
+	}
+	return digestBits
+}
+
+func keccakF1600(api API, a keccakState) keccakState {
+	for round := 0; round < KeccakRounds; round++ {
+		// theta
+		var c, d [5]keccakLane
+		for x := 0; x < 5; x++ {
+			for z := 0; z < KeccakLaneBitsSize; z++ {
+				c[x][z] = xorBits(api, a[x][z], a[x+5][z], a[x+10][z], a[x+15][z], a[x+20][z])
+			}
+		}
+		for x := 0; x < 5; x++ {
+			rotated := rotateLane(c[(x+1)%5], 1)
+			for z := 0; z < KeccakLaneBitsSize; z++ {
+				d[x][z] = xorBits(api, c[(x+4)%5][z], rotated[z])
+			}
+		}
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				for z := 0; z < KeccakLaneBitsSize; z++ {
+					a[x+5*y][z] = xorBits(api, a[x+5*y][z], d[x][z])
+				}
+			}
+		}
+		// rho and pi
+		var b keccakState
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				b[y+5*((2*x+3*y)%5)] = rotateLane(a[x+5*y], keccakRotationOffsets[x][y])
+			}
+		}
+		// chi
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				for z := 0; z < KeccakLaneBitsSize; z++ {
+					a[x+5*y][z] = xorBits(api, b[x+5*y][z], andBits(api, notBit(b[(x+1)%5+5*y][z]), b[(x+2)%5+5*y][z]))
+				}
+			}
+		}
+		// iota
+		for z := 0; z < KeccakLaneBitsSize; z++ {
+			if (keccakRoundConstants[round]>>z)&1 == 1 {
+				a[0][z] = notBit(a[0][z])
+			}
+		}
+	}
+	return a
+}
+
+// rotateLane rotates the lane left by n bits
+func rotateLane(lane keccakLane, n int) (res keccakLane) {
+	for z := 0; z < KeccakLaneBitsSize; z++ {
+		res[(z+n)%KeccakLaneBitsSize] = lane[z]
+	}
+	return res
+}
+
+func xorBits(api API, a keccakBit, others ...keccakBit) keccakBit {
+	res := a
+	for _, b := range others {
+		res = xorBit(api, res, b)
+	}
+	return res
+}
+
+func xorBit(api API, a, b keccakBit) keccakBit {
+	if c, ok := api.Compiler().ConstantValue(a.v); ok {
+		if c.Sign() == 0 {
+			return keccakBit{v: b.v, neg: b.neg != a.neg}
+		}
+		return keccakBit{v: b.v, neg: b.neg == a.neg}
+	}
+	if c, ok := api.Compiler().ConstantValue(b.v); ok {
+		if c.Sign() == 0 {
+			return keccakBit{v: a.v, neg: a.neg != b.neg}
+		}
+		return keccakBit{v: a.v, neg: a.neg == b.neg}
+	}
+	return keccakBit{v: api.Xor(a.v, b.v), neg: a.neg != b.neg}
+}
+
+func notBit(a keccakBit) keccakBit {
+	return keccakBit{v: a.v, neg: !a.neg}
+}
+
+func andBits(api API, a, b keccakBit) keccakBit {
+	if c, ok := api.Compiler().ConstantValue(a.v); ok {
+		if (c.Sign() == 0) != a.neg {
+			return keccakBit{v: 0}
+		}
+		return b
+	}
+	if c, ok := api.Compiler().ConstantValue(b.v); ok {
+		if (c.Sign() == 0) != b.neg {
+			return keccakBit{v: 0}
+		}
+		return a
+	}
+	res := api.Mul(bitValue(api, a), bitValue(api, b))
+	api.Compiler().MarkBoolean(res)
+	return keccakBit{v: res}
+}
+
+func bitValue(api API, a keccakBit) Variable {
+	if !a.neg {
+		return a.v
+	}
+	res := api.Sub(1, a.v)
+	api.Compiler().MarkBoolean(res)
+	return res
+}
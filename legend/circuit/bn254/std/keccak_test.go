@@ -0,0 +1,143 @@
+package std
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type KeccakBytesConstraints struct {
+	Msg    []Variable
+	Digest [Keccak256OutputSize]Variable
+}
+
+func (circuit KeccakBytesConstraints) Define(api API) error {
+	msgBits := make([]Variable, 0, len(circuit.Msg)*8)
+	for i := 0; i < len(circuit.Msg); i++ {
+		msgBits = append(msgBits, api.ToBinary(circuit.Msg[i], 8)...)
+	}
+	digestBits := Keccak256Bits(api, msgBits)
+	for i := 0; i < Keccak256OutputSize; i++ {
+		api.AssertIsEqual(api.FromBinary(digestBits[i*8:(i+1)*8]...), circuit.Digest[i])
+	}
+	return nil
+}
+
+type KeccakVariablesConstraints struct {
+	Inputs []Variable
+	Hash   Variable
+}
+
+func (circuit KeccakVariablesConstraints) Define(api API) error {
+	api.AssertIsEqual(Keccak256Variables(api, circuit.Inputs...), circuit.Hash)
+	return nil
+}
+
+func keccakBytesWitness(msg []byte) (circuit, witness KeccakBytesConstraints) {
+	circuit.Msg = make([]Variable, len(msg))
+	witness.Msg = make([]Variable, len(msg))
+	for i := 0; i < len(msg); i++ {
+		witness.Msg[i] = msg[i]
+	}
+	hashVal := crypto.Keccak256Hash(msg)
+	for i := 0; i < Keccak256OutputSize; i++ {
+		witness.Digest[i] = hashVal[i]
+	}
+	return circuit, witness
+}
+
+func TestKeccak256Bits(t *testing.T) {
+	// cover the empty message and the rate boundaries of the padding
+	for _, size := range []int{0, 1, 32, 135, 136, 137, 271, 272, 300} {
+		msg := make([]byte, size)
+		_, _ = rand.Read(msg)
+		circuit, witness := keccakBytesWitness(msg)
+		err := test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		// a wrong digest must not be accepted
+		witness.Digest[0] = (crypto.Keccak256Hash(msg)[0] + 1) % 255
+		err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16)
+		if err == nil {
+			t.Fatalf("size %d: wrong digest accepted", size)
+		}
+	}
+}
+
+func TestKeccak256Variables(t *testing.T) {
+	values := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		new(big.Int).Sub(ecc.BN254.Info().Fr.Modulus(), big.NewInt(1)),
+		new(big.Int).SetBytes(crypto.Keccak256([]byte("zkbas"))[:31]),
+	}
+	var buf bytes.Buffer
+	var circuit, witness KeccakVariablesConstraints
+	for i := 0; i < len(values); i++ {
+		buf.Write(values[i].FillBytes(make([]byte, 32)))
+		circuit.Inputs = append(circuit.Inputs, 0)
+		witness.Inputs = append(witness.Inputs, values[i])
+	}
+	hashVal := crypto.Keccak256Hash(buf.Bytes())
+	// same reduction as the Keccak256 hint
+	witness.Hash = new(big.Int).SetBytes(hashVal[:])
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(
+		&circuit, &witness, test.WithBackends(backend.GROTH16),
+		test.WithCurves(ecc.BN254),
+		test.WithCompileOpts(frontend.IgnoreUnconstrainedInputs()))
+
+	witness.Hash = new(big.Int).Add(new(big.Int).SetBytes(hashVal[:]), big.NewInt(1))
+	assert.SolvingFailed(
+		&circuit, &witness, test.WithBackends(backend.GROTH16),
+		test.WithCurves(ecc.BN254),
+		test.WithCompileOpts(frontend.IgnoreUnconstrainedInputs()))
+}
+
+type TruncatedKeccakVariablesConstraints struct {
+	Inputs []Variable
+	Hash   Variable
+}
+
+func (circuit TruncatedKeccakVariablesConstraints) Define(api API) error {
+	api.AssertIsEqual(TruncatedKeccak256Variables(api, 253, circuit.Inputs...), circuit.Hash)
+	return nil
+}
+
+func TestTruncatedKeccak256Variables(t *testing.T) {
+	values := []*big.Int{
+		big.NewInt(1),
+		new(big.Int).Sub(ecc.BN254.Info().Fr.Modulus(), big.NewInt(1)),
+	}
+	var buf bytes.Buffer
+	var circuit, witness TruncatedKeccakVariablesConstraints
+	for i := 0; i < len(values); i++ {
+		buf.Write(values[i].FillBytes(make([]byte, 32)))
+		circuit.Inputs = append(circuit.Inputs, 0)
+		witness.Inputs = append(witness.Inputs, values[i])
+	}
+	hashVal := crypto.Keccak256Hash(buf.Bytes())
+	// the digest is masked, not reduced
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 253), big.NewInt(1))
+	truncatedHash := new(big.Int).And(new(big.Int).SetBytes(hashVal[:]), mask)
+	witness.Hash = truncatedHash
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(
+		&circuit, &witness, test.WithBackends(backend.GROTH16),
+		test.WithCurves(ecc.BN254),
+		test.WithCompileOpts(frontend.IgnoreUnconstrainedInputs()))
+
+	witness.Hash = new(big.Int).Add(truncatedHash, new(big.Int).Lsh(big.NewInt(1), 253))
+	assert.SolvingFailed(
+		&circuit, &witness, test.WithBackends(backend.GROTH16),
+		test.WithCurves(ecc.BN254),
+		test.WithCompileOpts(frontend.IgnoreUnconstrainedInputs()))
+}
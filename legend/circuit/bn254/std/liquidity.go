@@ -30,6 +30,11 @@ type Liquidity struct {
 	FeeRate              int64
 	TreasuryAccountIndex int64
 	TreasuryRate         int64
+	PairType             int64
+	Amplification        int64
+	PriceACumulativeLast *big.Int
+	PriceBCumulativeLast *big.Int
+	BlockTimestampLast   int64
 }
 
 func EmptyLiquidity(pairIndex int64) *Liquidity {
@@ -45,5 +50,10 @@ func EmptyLiquidity(pairIndex int64) *Liquidity {
 		FeeRate:              0,
 		TreasuryAccountIndex: 0,
 		TreasuryRate:         0,
+		PairType:             PairTypeConstantProduct,
+		Amplification:        0,
+		PriceACumulativeLast: zero,
+		PriceBCumulativeLast: zero,
+		BlockTimestampLast:   0,
 	}
 }
@@ -20,6 +20,7 @@ package std
 import (
 	"errors"
 	"log"
+	"math/big"
 )
 
 type LiquidityConstraints struct {
@@ -33,6 +34,11 @@ type LiquidityConstraints struct {
 	FeeRate              Variable
 	TreasuryAccountIndex Variable
 	TreasuryRate         Variable
+	PairType             Variable
+	Amplification        Variable
+	PriceACumulativeLast Variable
+	PriceBCumulativeLast Variable
+	BlockTimestampLast   Variable
 }
 
 func CheckEmptyLiquidityNode(api API, flag Variable, liquidity LiquidityConstraints) {
@@ -45,6 +51,82 @@ func CheckEmptyLiquidityNode(api API, flag Variable, liquidity LiquidityConstrai
 	IsVariableEqual(api, flag, liquidity.FeeRate, ZeroInt)
 	IsVariableEqual(api, flag, liquidity.TreasuryAccountIndex, ZeroInt)
 	IsVariableEqual(api, flag, liquidity.TreasuryRate, ZeroInt)
+	IsVariableEqual(api, flag, liquidity.PairType, ZeroInt)
+	IsVariableEqual(api, flag, liquidity.Amplification, ZeroInt)
+	IsVariableEqual(api, flag, liquidity.PriceACumulativeLast, ZeroInt)
+	IsVariableEqual(api, flag, liquidity.PriceBCumulativeLast, ZeroInt)
+	IsVariableEqual(api, flag, liquidity.BlockTimestampLast, ZeroInt)
+}
+
+var (
+	pairTypeShift      = new(big.Int).Lsh(big.NewInt(1), FeeRateBitsSize)
+	amplificationShift = new(big.Int).Lsh(big.NewInt(1), FeeRateBitsSize+PairTypeBitsSize)
+)
+
+/*
+	PackPairFeeRate: the pair type and the amplification are hashed above the
+	FeeRateBitsSize bits of the fee rate, so a constant-product pair hashes its
+	fee rate alone
+*/
+func PackPairFeeRate(api API, liquidity LiquidityConstraints) Variable {
+	return api.Add(
+		liquidity.FeeRate,
+		api.Mul(liquidity.PairType, pairTypeShift),
+		api.Mul(liquidity.Amplification, amplificationShift),
+	)
+}
+
+/*
+	ComputePackedPairFeeRate: native PackPairFeeRate
+*/
+func ComputePackedPairFeeRate(feeRate, pairType, amplification int64) *big.Int {
+	packed := new(big.Int).Mul(big.NewInt(pairType), pairTypeShift)
+	packed.Add(packed, new(big.Int).Mul(big.NewInt(amplification), amplificationShift))
+	return packed.Add(packed, big.NewInt(feeRate))
+}
+
+func CollectHashInputsFromLiquidity(liquidity LiquidityConstraints, packedFeeRate Variable) (inputs []Variable) {
+	return []Variable{
+		liquidity.AssetAId,
+		liquidity.AssetA,
+		liquidity.AssetBId,
+		liquidity.AssetB,
+		liquidity.LpAmount,
+		liquidity.KLast,
+		packedFeeRate,
+		liquidity.TreasuryAccountIndex,
+		liquidity.TreasuryRate,
+	}
+}
+
+/*
+	CollectHashInputsFromLiquidityPrices: the price accumulators and their timestamp
+	are hashed with the hash of the other fields of the leaf
+*/
+func CollectHashInputsFromLiquidityPrices(liquidity LiquidityConstraints, fieldsHash Variable) (inputs []Variable) {
+	return []Variable{
+		fieldsHash,
+		liquidity.PriceACumulativeLast,
+		liquidity.PriceBCumulativeLast,
+		liquidity.BlockTimestampLast,
+	}
+}
+
+/*
+	ComputeHashFromLiquidity: hash of a liquidity leaf, a pair which never
+	accumulated prices keeps the hash of its other fields
+*/
+func ComputeHashFromLiquidity(api API, liquidity LiquidityConstraints, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(CollectHashInputsFromLiquidity(liquidity, PackPairFeeRate(api, liquidity))...)
+	fieldsHash := hFunc.Sum()
+	hFunc.Reset()
+	hFunc.Write(CollectHashInputsFromLiquidityPrices(liquidity, fieldsHash)...)
+	pricesHash := hFunc.Sum()
+	hFunc.Reset()
+	hasNoPrices := api.And(api.IsZero(liquidity.BlockTimestampLast),
+		api.And(api.IsZero(liquidity.PriceACumulativeLast), api.IsZero(liquidity.PriceBCumulativeLast)))
+	return api.Select(hasNoPrices, fieldsHash, pricesHash)
 }
 
 /*
@@ -55,6 +137,14 @@ func SetLiquidityWitness(liquidity *Liquidity) (witness LiquidityConstraints, er
 		log.Println("[SetLiquidityWitness] invalid params")
 		return witness, errors.New("[SetLiquidityWitness] invalid params")
 	}
+	// leaves serialized before the price accumulators have none
+	priceACumulativeLast, priceBCumulativeLast := liquidity.PriceACumulativeLast, liquidity.PriceBCumulativeLast
+	if priceACumulativeLast == nil {
+		priceACumulativeLast = ZeroBigInt
+	}
+	if priceBCumulativeLast == nil {
+		priceBCumulativeLast = ZeroBigInt
+	}
 	// set witness
 	witness = LiquidityConstraints{
 		PairIndex:            liquidity.PairIndex,
@@ -67,6 +157,11 @@ func SetLiquidityWitness(liquidity *Liquidity) (witness LiquidityConstraints, er
 		FeeRate:              liquidity.FeeRate,
 		TreasuryAccountIndex: liquidity.TreasuryAccountIndex,
 		TreasuryRate:         liquidity.TreasuryRate,
+		PairType:             liquidity.PairType,
+		Amplification:        liquidity.Amplification,
+		PriceACumulativeLast: priceACumulativeLast,
+		PriceBCumulativeLast: priceBCumulativeLast,
+		BlockTimestampLast:   liquidity.BlockTimestampLast,
 	}
 	return witness, nil
 }
@@ -0,0 +1,218 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package std
+
+/*
+	MatchOrderTx: settles AssetAFillAmount of asset A against AssetBFillAmount of asset B
+	between a buy order and a sell order, the orders can be partially filled and the filled
+	amount of asset A of each order is kept in its order slot
+*/
+type MatchOrderTx struct {
+	AccountIndex      int64
+	BuyOrder          *OrderTx
+	SellOrder         *OrderTx
+	AssetAFillAmount  int64
+	AssetBFillAmount  int64
+	GasAccountIndex   int64
+	GasFeeAssetId     int64
+	GasFeeAssetAmount int64
+}
+
+type MatchOrderTxConstraints struct {
+	AccountIndex      Variable
+	BuyOrder          OrderTxConstraints
+	SellOrder         OrderTxConstraints
+	AssetAFillAmount  Variable
+	AssetBFillAmount  Variable
+	GasAccountIndex   Variable
+	GasFeeAssetId     Variable
+	GasFeeAssetAmount Variable
+}
+
+func EmptyMatchOrderTxWitness() (witness MatchOrderTxConstraints) {
+	return MatchOrderTxConstraints{
+		AccountIndex:      ZeroInt,
+		BuyOrder:          EmptyOrderTxWitness(),
+		SellOrder:         EmptyOrderTxWitness(),
+		AssetAFillAmount:  ZeroInt,
+		AssetBFillAmount:  ZeroInt,
+		GasAccountIndex:   ZeroInt,
+		GasFeeAssetId:     ZeroInt,
+		GasFeeAssetAmount: ZeroInt,
+	}
+}
+
+func SetMatchOrderTxWitness(tx *MatchOrderTx) (witness MatchOrderTxConstraints) {
+	witness = MatchOrderTxConstraints{
+		AccountIndex:      tx.AccountIndex,
+		BuyOrder:          SetOrderTxWitness(tx.BuyOrder),
+		SellOrder:         SetOrderTxWitness(tx.SellOrder),
+		AssetAFillAmount:  tx.AssetAFillAmount,
+		AssetBFillAmount:  tx.AssetBFillAmount,
+		GasAccountIndex:   tx.GasAccountIndex,
+		GasFeeAssetId:     tx.GasFeeAssetId,
+		GasFeeAssetAmount: tx.GasFeeAssetAmount,
+	}
+	return witness
+}
+
+func CollectHashInputsFromMatchOrderTx(tx MatchOrderTxConstraints, nonce Variable, expiredAt Variable) (inputs []Variable) {
+	return []Variable{
+		tx.AccountIndex,
+		tx.BuyOrder.Type,
+		tx.BuyOrder.OrderId,
+		tx.BuyOrder.AccountIndex,
+		tx.BuyOrder.AssetAId,
+		tx.BuyOrder.AssetBId,
+		tx.BuyOrder.AssetAAmount,
+		tx.BuyOrder.AssetBAmount,
+		tx.BuyOrder.ExpiredAt,
+		tx.BuyOrder.Sig.R.X,
+		tx.BuyOrder.Sig.R.Y,
+		tx.BuyOrder.Sig.S,
+		tx.SellOrder.Type,
+		tx.SellOrder.OrderId,
+		tx.SellOrder.AccountIndex,
+		tx.SellOrder.AssetAId,
+		tx.SellOrder.AssetBId,
+		tx.SellOrder.AssetAAmount,
+		tx.SellOrder.AssetBAmount,
+		tx.SellOrder.ExpiredAt,
+		tx.SellOrder.Sig.R.X,
+		tx.SellOrder.Sig.R.Y,
+		tx.SellOrder.Sig.S,
+		tx.AssetAFillAmount,
+		tx.AssetBFillAmount,
+		tx.GasAccountIndex,
+		tx.GasFeeAssetId,
+		tx.GasFeeAssetAmount,
+		expiredAt,
+		nonce,
+		ChainId,
+	}
+}
+
+func ComputeHashFromMatchOrderTx(tx MatchOrderTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(CollectHashInputsFromMatchOrderTx(tx, nonce, expiredAt)...)
+	hashVal = hFunc.Sum()
+	return hashVal
+}
+
+/*
+	IsOrderAmountInRange: the amounts of an order fit in the filled amount of its order slot,
+	which also keeps the products of the price checks below the field size
+*/
+func IsOrderAmountInRange(api API, flag Variable, amount Variable) {
+	api.ToBinary(api.Select(flag, amount, 0), OrderFilledAmountBitsSize)
+}
+
+func VerifyMatchOrderTx(
+	api API, flag Variable,
+	tx *MatchOrderTxConstraints,
+	accountsBefore [NbAccountsPerTx]AccountConstraints,
+	blockCreatedAt Variable,
+	hFunc MiMC,
+) (pubData []Variable, err error) {
+	defer ProfileScope(api, "VerifyMatchOrderTx")()
+	pubData = CollectPubDataFromMatchOrder(api, *tx)
+	// verify params
+	IsVariableEqual(api, flag, tx.BuyOrder.Type, 0)
+	IsVariableEqual(api, flag, tx.SellOrder.Type, 1)
+	IsVariableEqual(api, flag, tx.BuyOrder.AssetAId, tx.SellOrder.AssetAId)
+	IsVariableEqual(api, flag, tx.BuyOrder.AssetBId, tx.SellOrder.AssetBId)
+	IsVariableEqual(api, flag, api.IsZero(api.Sub(tx.BuyOrder.AssetAId, tx.BuyOrder.AssetBId)), 0)
+	IsVariableLessOrEqual(api, flag, blockCreatedAt, tx.BuyOrder.ExpiredAt)
+	IsVariableLessOrEqual(api, flag, blockCreatedAt, tx.SellOrder.ExpiredAt)
+	// verify signature
+	hFunc.Reset()
+	buyOrderHash := ComputeHashFromOrderTx(tx.BuyOrder, hFunc)
+	hFunc.Reset()
+	notBuyer := api.IsZero(api.IsZero(api.Sub(tx.AccountIndex, tx.BuyOrder.AccountIndex)))
+	notBuyer = api.And(flag, notBuyer)
+	// a multi-signature account has no key to sign its orders
+	IsVariableEqual(api, notBuyer, accountsBefore[1].SignerThreshold, 0)
+	err = VerifyEddsaSig(notBuyer, api, hFunc, buyOrderHash, accountsBefore[1].AccountPk, tx.BuyOrder.Sig)
+	if err != nil {
+		return pubData, err
+	}
+	hFunc.Reset()
+	sellOrderHash := ComputeHashFromOrderTx(tx.SellOrder, hFunc)
+	hFunc.Reset()
+	notSeller := api.IsZero(api.IsZero(api.Sub(tx.AccountIndex, tx.SellOrder.AccountIndex)))
+	notSeller = api.And(flag, notSeller)
+	IsVariableEqual(api, notSeller, accountsBefore[2].SignerThreshold, 0)
+	err = VerifyEddsaSig(notSeller, api, hFunc, sellOrderHash, accountsBefore[2].AccountPk, tx.SellOrder.Sig)
+	if err != nil {
+		return pubData, err
+	}
+	// verify account index
+	// submitter
+	IsVariableEqual(api, flag, tx.AccountIndex, accountsBefore[0].AccountIndex)
+	// buyer
+	IsVariableEqual(api, flag, tx.BuyOrder.AccountIndex, accountsBefore[1].AccountIndex)
+	// seller
+	IsVariableEqual(api, flag, tx.SellOrder.AccountIndex, accountsBefore[2].AccountIndex)
+	// gas
+	IsVariableEqual(api, flag, tx.GasAccountIndex, accountsBefore[3].AccountIndex)
+	// verify asset id
+	IsVariableEqual(api, flag, tx.GasFeeAssetId, accountsBefore[0].AssetsInfo[0].AssetId)
+	IsVariableEqual(api, flag, tx.BuyOrder.AssetBId, accountsBefore[1].AssetsInfo[0].AssetId)
+	IsVariableEqual(api, flag, tx.BuyOrder.AssetAId, accountsBefore[1].AssetsInfo[1].AssetId)
+	IsVariableEqual(api, flag, tx.SellOrder.AssetAId, accountsBefore[2].AssetsInfo[0].AssetId)
+	IsVariableEqual(api, flag, tx.SellOrder.AssetBId, accountsBefore[2].AssetsInfo[1].AssetId)
+	IsVariableEqual(api, flag, tx.GasFeeAssetId, accountsBefore[3].AssetsInfo[0].AssetId)
+	// verify order slot, it can't be the leaf of a traded asset
+	IsVariableEqual(api, flag, tx.BuyOrder.OrderId, accountsBefore[1].AssetsInfo[2].AssetId)
+	IsVariableEqual(api, flag, tx.SellOrder.OrderId, accountsBefore[2].AssetsInfo[2].AssetId)
+	IsVariableEqual(api, flag, api.IsZero(api.Sub(tx.BuyOrder.OrderId, tx.BuyOrder.AssetAId)), 0)
+	IsVariableEqual(api, flag, api.IsZero(api.Sub(tx.BuyOrder.OrderId, tx.BuyOrder.AssetBId)), 0)
+	IsVariableEqual(api, flag, api.IsZero(api.Sub(tx.SellOrder.OrderId, tx.SellOrder.AssetAId)), 0)
+	IsVariableEqual(api, flag, api.IsZero(api.Sub(tx.SellOrder.OrderId, tx.SellOrder.AssetBId)), 0)
+	// verify amounts
+	tx.BuyOrder.AssetAAmount = UnpackAmount(api, tx.BuyOrder.AssetAAmount)
+	tx.BuyOrder.AssetBAmount = UnpackAmount(api, tx.BuyOrder.AssetBAmount)
+	tx.SellOrder.AssetAAmount = UnpackAmount(api, tx.SellOrder.AssetAAmount)
+	tx.SellOrder.AssetBAmount = UnpackAmount(api, tx.SellOrder.AssetBAmount)
+	tx.AssetAFillAmount = UnpackAmount(api, tx.AssetAFillAmount)
+	tx.AssetBFillAmount = UnpackAmount(api, tx.AssetBFillAmount)
+	IsOrderAmountInRange(api, flag, tx.BuyOrder.AssetAAmount)
+	IsOrderAmountInRange(api, flag, tx.BuyOrder.AssetBAmount)
+	IsOrderAmountInRange(api, flag, tx.SellOrder.AssetAAmount)
+	IsOrderAmountInRange(api, flag, tx.SellOrder.AssetBAmount)
+	IsOrderAmountInRange(api, flag, tx.AssetBFillAmount)
+	IsVariableLessOrEqual(api, flag, 1, tx.AssetAFillAmount)
+	// the buyer pays at most and the seller gets at least the price of its order
+	IsVariableLessOrEqual(api, flag,
+		api.Mul(tx.AssetBFillAmount, tx.BuyOrder.AssetAAmount),
+		api.Mul(tx.AssetAFillAmount, tx.BuyOrder.AssetBAmount))
+	IsVariableLessOrEqual(api, flag,
+		api.Mul(tx.AssetAFillAmount, tx.SellOrder.AssetBAmount),
+		api.Mul(tx.AssetBFillAmount, tx.SellOrder.AssetAAmount))
+	// orders can't be overfilled
+	_, buyOrderFilledAmount := SplitOfferCanceledOrFinalized(api, accountsBefore[1].AssetsInfo[2].OfferCanceledOrFinalized)
+	_, sellOrderFilledAmount := SplitOfferCanceledOrFinalized(api, accountsBefore[2].AssetsInfo[2].OfferCanceledOrFinalized)
+	IsVariableLessOrEqual(api, flag, api.Add(buyOrderFilledAmount, tx.AssetAFillAmount), tx.BuyOrder.AssetAAmount)
+	IsVariableLessOrEqual(api, flag, api.Add(sellOrderFilledAmount, tx.AssetAFillAmount), tx.SellOrder.AssetAAmount)
+	// should have enough balance
+	tx.GasFeeAssetAmount = UnpackFee(api, tx.GasFeeAssetAmount)
+	IsVariableLessOrEqual(api, flag, tx.AssetBFillAmount, accountsBefore[1].AssetsInfo[0].Balance)
+	IsVariableLessOrEqual(api, flag, tx.AssetAFillAmount, accountsBefore[2].AssetsInfo[0].Balance)
+	IsVariableLessOrEqual(api, flag, tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[0].Balance)
+	return pubData, nil
+}
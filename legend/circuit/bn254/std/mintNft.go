@@ -78,9 +78,8 @@ func SetMintNftTxWitness(tx *MintNftTx) (witness MintNftTxConstraints) {
 	return witness
 }
 
-func ComputeHashFromMintNftTx(api API, tx MintNftTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
-	hFunc.Reset()
-	hFunc.Write(
+func CollectHashInputsFromMintNftTx(tx MintNftTxConstraints, nonce Variable, expiredAt Variable) (inputs []Variable) {
+	return []Variable{
 		tx.CreatorAccountIndex,
 		tx.ToAccountIndex,
 		tx.ToAccountNameHash,
@@ -93,7 +92,12 @@ func ComputeHashFromMintNftTx(api API, tx MintNftTxConstraints, nonce Variable,
 		expiredAt,
 		nonce,
 		ChainId,
-	)
+	}
+}
+
+func ComputeHashFromMintNftTx(api API, tx MintNftTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(CollectHashInputsFromMintNftTx(tx, nonce, expiredAt)...)
 	hashVal = hFunc.Sum()
 	return hashVal
 }
@@ -102,7 +106,8 @@ func VerifyMintNftTx(
 	api API, flag Variable,
 	tx *MintNftTxConstraints,
 	accountsBefore [NbAccountsPerTx]AccountConstraints, nftBefore NftConstraints,
-) (pubData [PubDataSizePerTx]Variable) {
+) (pubData []Variable) {
+	defer ProfileScope(api, "VerifyMintNftTx")()
 	pubData = CollectPubDataFromMintNft(api, *tx)
 	// verify params
 	// check empty nft
@@ -0,0 +1,128 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package std
+
+import (
+	"errors"
+	"log"
+
+	oEddsa "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark/std/signature/eddsa"
+)
+
+/*
+	MultiSig: signature of a tx by a key of the signer set of a multi-signature
+	account, with the Merkle proof of the key in the signer tree
+*/
+type MultiSig struct {
+	SignerIndex int64
+	PubKey      *oEddsa.PublicKey
+	Sig         *oEddsa.Signature
+	MerkleProof [SignerMerkleLevels][]byte
+}
+
+type MultiSigConstraints struct {
+	SignerIndex Variable
+	PubKey      PublicKeyConstraints
+	Sig         eddsa.Signature
+	MerkleProof [SignerMerkleLevels]Variable
+}
+
+func EmptyMultiSigWitness() (witness MultiSigConstraints) {
+	witness = MultiSigConstraints{
+		SignerIndex: ZeroInt,
+		PubKey:      EmptyPublicKeyWitness(),
+		Sig:         SetSignatureWitness(EmptySignature()),
+	}
+	for i := 0; i < SignerMerkleLevels; i++ {
+		witness.MerkleProof[i] = ZeroInt
+	}
+	return witness
+}
+
+func SetMultiSigWitness(multiSig *MultiSig) (witness MultiSigConstraints, err error) {
+	if multiSig == nil || multiSig.PubKey == nil || multiSig.Sig == nil {
+		log.Println("[SetMultiSigWitness] invalid params")
+		return witness, errors.New("[SetMultiSigWitness] invalid params")
+	}
+	witness = MultiSigConstraints{
+		SignerIndex: multiSig.SignerIndex,
+		PubKey:      SetPubKeyWitness(multiSig.PubKey),
+		Sig:         SetSignatureWitness(multiSig.Sig),
+	}
+	for i := 0; i < SignerMerkleLevels; i++ {
+		witness.MerkleProof[i] = multiSig.MerkleProof[i]
+	}
+	return witness, nil
+}
+
+/*
+	CollectHashInputsFromSigner: a leaf of the signer tree is the hash of a key,
+	the leaves left are 0
+*/
+func CollectHashInputsFromSigner(pubKey PublicKeyConstraints) (inputs []Variable) {
+	return []Variable{
+		pubKey.A.X,
+		pubKey.A.Y,
+	}
+}
+
+/*
+	VerifyMultiSig: the tx is signed by SignerThreshold distinct keys of the signer
+	set of the account, in place of its single key. The first SignerThreshold
+	signatures are checked, their signer indexes are increasing so a key can't
+	sign twice, and each key is proved against the signer root of the account.
+*/
+func VerifyMultiSig(
+	api API, flag Variable, hFunc MiMC, hashVal Variable,
+	account AccountConstraints,
+	multiSigs [NbMultiSigsPerTx]MultiSigConstraints,
+) error {
+	defer ProfileScope(api, "VerifyMultiSig")()
+	isSigned := flag
+	for i := 0; i < NbMultiSigsPerTx; i++ {
+		// the threshold of the account is between 1 and NbMultiSigsPerTx
+		isSigned = api.And(isSigned, api.Sub(1, api.IsZero(api.Sub(account.SignerThreshold, i))))
+		if i > 0 {
+			IsVariableLess(api, isSigned, multiSigs[i-1].SignerIndex, multiSigs[i].SignerIndex)
+		}
+		signerIndexMerkleHelper := api.ToBinary(multiSigs[i].SignerIndex, SignerMerkleLevels)
+		hFunc.Reset()
+		hFunc.Write(CollectHashInputsFromSigner(multiSigs[i].PubKey)...)
+		signerNodeHash := hFunc.Sum()
+		hFunc.Reset()
+		endVerify := ProfileScope(api, "VerifyMerkleProof/signer")
+		VerifyMerkleProof(
+			api,
+			isSigned,
+			hFunc,
+			account.SignerRoot,
+			signerNodeHash,
+			multiSigs[i].MerkleProof[:],
+			signerIndexMerkleHelper,
+		)
+		endVerify()
+		hFunc.Reset()
+		err := VerifyEddsaSig(isSigned, api, hFunc, hashVal, multiSigs[i].PubKey, multiSigs[i].Sig)
+		if err != nil {
+			return err
+		}
+		hFunc.Reset()
+	}
+	return nil
+}
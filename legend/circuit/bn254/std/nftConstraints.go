@@ -43,6 +43,18 @@ func CheckEmptyNftNode(api API, flag Variable, nft NftConstraints) {
 	IsVariableEqual(api, flag, nft.CollectionId, ZeroInt)
 }
 
+func CollectHashInputsFromNft(nft NftConstraints) (inputs []Variable) {
+	return []Variable{
+		nft.CreatorAccountIndex,
+		nft.OwnerAccountIndex,
+		nft.NftContentHash,
+		nft.NftL1Address,
+		nft.NftL1TokenId,
+		nft.CreatorTreasuryRate,
+		nft.CollectionId,
+	}
+}
+
 /*
 	SetNftWitness: set liquidity witness
 */
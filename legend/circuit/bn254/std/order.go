@@ -0,0 +1,112 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package std
+
+import (
+	oEddsa "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark/std/signature/eddsa"
+)
+
+/*
+	OrderTx: signed limit order of asset A priced in asset B, the buy order (type 0) pays
+	at most AssetBAmount for AssetAAmount and the sell order (type 1) gets at least
+	AssetBAmount for AssetAAmount, both can be filled in several matches
+*/
+type OrderTx struct {
+	Type         int64
+	OrderId      int64
+	AccountIndex int64
+	AssetAId     int64
+	AssetBId     int64
+	AssetAAmount int64
+	AssetBAmount int64
+	ExpiredAt    int64
+	Sig          *oEddsa.Signature
+}
+
+type OrderTxConstraints struct {
+	Type         Variable
+	OrderId      Variable
+	AccountIndex Variable
+	AssetAId     Variable
+	AssetBId     Variable
+	AssetAAmount Variable
+	AssetBAmount Variable
+	ExpiredAt    Variable
+	Sig          eddsa.Signature
+}
+
+func EmptyOrderTxWitness() (witness OrderTxConstraints) {
+	return OrderTxConstraints{
+		Type:         ZeroInt,
+		OrderId:      ZeroInt,
+		AccountIndex: ZeroInt,
+		AssetAId:     ZeroInt,
+		AssetBId:     ZeroInt,
+		AssetAAmount: ZeroInt,
+		AssetBAmount: ZeroInt,
+		ExpiredAt:    ZeroInt,
+		Sig:          SetSignatureWitness(EmptySignature()),
+	}
+}
+
+func SetOrderTxWitness(tx *OrderTx) (witness OrderTxConstraints) {
+	witness = OrderTxConstraints{
+		Type:         tx.Type,
+		OrderId:      tx.OrderId,
+		AccountIndex: tx.AccountIndex,
+		AssetAId:     tx.AssetAId,
+		AssetBId:     tx.AssetBId,
+		AssetAAmount: tx.AssetAAmount,
+		AssetBAmount: tx.AssetBAmount,
+		ExpiredAt:    tx.ExpiredAt,
+		Sig:          SetSignatureWitness(tx.Sig),
+	}
+	return witness
+}
+
+func CollectHashInputsFromOrderTx(tx OrderTxConstraints) (inputs []Variable) {
+	return []Variable{
+		tx.Type,
+		tx.OrderId,
+		tx.AccountIndex,
+		tx.AssetAId,
+		tx.AssetBId,
+		tx.AssetAAmount,
+		tx.AssetBAmount,
+		tx.ExpiredAt,
+		ChainId,
+	}
+}
+
+func ComputeHashFromOrderTx(tx OrderTxConstraints, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(CollectHashInputsFromOrderTx(tx)...)
+	hashVal = hFunc.Sum()
+	return hashVal
+}
+
+/*
+	SplitOfferCanceledOrFinalized: the canceled or finalized bits of the offers of an asset leaf
+	and the filled amount of asset A of the order whose order slot is the leaf, which is kept
+	above the offer bits so that the leaves without order keep their hash
+*/
+func SplitOfferCanceledOrFinalized(api API, offerCanceledOrFinalized Variable) (offerBits []Variable, orderFilledAmount Variable) {
+	bits := api.ToBinary(offerCanceledOrFinalized)
+	return bits[:OfferSizePerAsset], api.FromBinary(bits[OfferSizePerAsset:]...)
+}
@@ -18,6 +18,7 @@
 package std
 
 func UnpackAmount(api API, packedAmount Variable) Variable {
+	defer ProfileScope(api, "UnpackAmount")()
 	amountBits := api.ToBinary(packedAmount, 40)
 	mantissa := api.FromBinary(amountBits[5:]...)
 	exponent := api.FromBinary(amountBits[:5]...)
@@ -30,6 +31,7 @@ func UnpackAmount(api API, packedAmount Variable) Variable {
 }
 
 func UnpackFee(api API, packedFee Variable) Variable {
+	defer ProfileScope(api, "UnpackFee")()
 	amountBits := api.ToBinary(packedFee, 16)
 	mantissa := api.FromBinary(amountBits[5:]...)
 	exponent := api.FromBinary(amountBits[:5]...)
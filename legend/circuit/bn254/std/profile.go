@@ -0,0 +1,92 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package std
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+/*
+	ProfileScope: count the constraints created between the call and the call of
+	the returned func, it adds no constraint and does nothing in the test engine:
+	defer ProfileScope(api, "VerifyTransferTx")()
+*/
+func ProfileScope(api API, name string) (end func()) {
+	from := api.Compiler().Tag(name)
+	return func() {
+		api.Compiler().AddCounter(from, api.Compiler().Tag(name))
+	}
+}
+
+type ComponentProfile struct {
+	Name          string `json:"name"`
+	Count         int    `json:"count"`
+	NbConstraints int    `json:"nbConstraints"`
+	NbVariables   int    `json:"nbVariables"`
+}
+
+type ConstraintProfile struct {
+	Circuit             string             `json:"circuit"`
+	NbConstraints       int                `json:"nbConstraints"`
+	NbInternalVariables int                `json:"nbInternalVariables"`
+	NbSecretVariables   int                `json:"nbSecretVariables"`
+	NbPublicVariables   int                `json:"nbPublicVariables"`
+	Components          []ComponentProfile `json:"components"`
+}
+
+/*
+	NewConstraintProfile: sum the counters of the compiled circuit by scope name,
+	nested scopes are counted in their parents too
+*/
+func NewConstraintProfile(circuit string, ccs frontend.CompiledConstraintSystem) (profile *ConstraintProfile) {
+	nbInternal, nbSecret, nbPublic := ccs.GetNbVariables()
+	profile = &ConstraintProfile{
+		Circuit:             circuit,
+		NbConstraints:       ccs.GetNbConstraints(),
+		NbInternalVariables: nbInternal,
+		NbSecretVariables:   nbSecret,
+		NbPublicVariables:   nbPublic,
+	}
+	components := make(map[string]*ComponentProfile)
+	for _, counter := range ccs.GetCounters() {
+		// tags are named "name[file:line]"
+		name := counter.From
+		if i := strings.LastIndex(name, "["); i >= 0 {
+			name = name[:i]
+		}
+		component, ok := components[name]
+		if !ok {
+			component = &ComponentProfile{Name: name}
+			components[name] = component
+		}
+		component.Count++
+		component.NbConstraints += counter.NbConstraints
+		component.NbVariables += counter.NbVariables
+	}
+	profile.Components = make([]ComponentProfile, 0, len(components))
+	for _, component := range components {
+		profile.Components = append(profile.Components, *component)
+	}
+	sort.Slice(profile.Components, func(i, j int) bool {
+		return profile.Components[i].Name < profile.Components[j].Name
+	})
+	return profile
+}
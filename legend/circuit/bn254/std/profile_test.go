@@ -0,0 +1,44 @@
+package std
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+type ProfileConstraints struct {
+	A Variable
+	B Variable
+}
+
+func (circuit ProfileConstraints) Define(api API) error {
+	defer ProfileScope(api, "outer")()
+	for i := 0; i < 2; i++ {
+		end := ProfileScope(api, "outer/mul")
+		api.AssertIsEqual(api.Mul(circuit.A, circuit.A), circuit.B)
+		end()
+	}
+	api.ToBinary(circuit.A, 8)
+	return nil
+}
+
+func TestNewConstraintProfile(t *testing.T) {
+	var circuit ProfileConstraints
+	oR1cs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile := NewConstraintProfile("profile", oR1cs)
+	if len(profile.Components) != 2 {
+		t.Fatal("invalid components")
+	}
+	outer, mul := profile.Components[0], profile.Components[1]
+	if outer.Name != "outer" || outer.Count != 1 || outer.NbConstraints != profile.NbConstraints {
+		t.Fatalf("invalid outer component: %+v", outer)
+	}
+	if mul.Name != "outer/mul" || mul.Count != 2 || mul.NbConstraints != 4 {
+		t.Fatalf("invalid mul component: %+v", mul)
+	}
+}
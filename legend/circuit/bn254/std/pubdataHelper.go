@@ -17,7 +17,9 @@
 
 package std
 
-func CollectPubDataFromRegisterZNS(api API, txInfo RegisterZnsTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+func CollectPubDataFromRegisterZNS(api API, txInfo RegisterZnsTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromRegisterZNS")()
+	pubData = make([]Variable, RegisterZnsPubDataChunks)
 	txTypeBits := api.ToBinary(TxTypeRegisterZns, TxTypeBitsSize)
 	accountIndexBits := api.ToBinary(txInfo.AccountIndex, AccountIndexBitsSize)
 	ABits := append(accountIndexBits, txTypeBits...)
@@ -31,13 +33,12 @@ func CollectPubDataFromRegisterZNS(api API, txInfo RegisterZnsTxConstraints) (pu
 	pubData[2] = txInfo.AccountNameHash
 	pubData[3] = txInfo.PubKey.A.X
 	pubData[4] = txInfo.PubKey.A.Y
-	for i := 5; i < PubDataSizePerTx; i++ {
-		pubData[i] = 0
-	}
 	return pubData
 }
 
-func CollectPubDataFromCreatePair(api API, txInfo CreatePairTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+func CollectPubDataFromCreatePair(api API, txInfo CreatePairTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromCreatePair")()
+	pubData = make([]Variable, CreatePairPubDataChunks)
 	txTypeBits := api.ToBinary(TxTypeCreatePair, TxTypeBitsSize)
 	pairIndexBits := api.ToBinary(txInfo.PairIndex, PairIndexBitsSize)
 	assetAIdBits := api.ToBinary(txInfo.AssetAId, AssetIdBitsSize)
@@ -45,25 +46,28 @@ func CollectPubDataFromCreatePair(api API, txInfo CreatePairTxConstraints) (pubD
 	FeeRateBits := api.ToBinary(txInfo.FeeRate, PackedFeeBitsSize)
 	TreasuryAccountIndexBits := api.ToBinary(txInfo.TreasuryAccountIndex, AccountIndexBitsSize)
 	TreasuryRateBits := api.ToBinary(txInfo.TreasuryRate, PackedFeeBitsSize)
+	pairTypeBits := api.ToBinary(txInfo.PairType, PairTypeBitsSize)
+	amplificationBits := api.ToBinary(txInfo.Amplification, AmplificationBitsSize)
 	ABits := append(pairIndexBits, txTypeBits...)
 	ABits = append(assetAIdBits, ABits...)
 	ABits = append(assetBIdBits, ABits...)
 	ABits = append(FeeRateBits, ABits...)
 	ABits = append(TreasuryAccountIndexBits, ABits...)
 	ABits = append(TreasuryRateBits, ABits...)
-	var paddingSize [136]Variable
-	for i := 0; i < 136; i++ {
+	ABits = append(pairTypeBits, ABits...)
+	ABits = append(amplificationBits, ABits...)
+	var paddingSize [112]Variable
+	for i := 0; i < 112; i++ {
 		paddingSize[i] = 0
 	}
 	ABits = append(paddingSize[:], ABits...)
 	pubData[0] = api.FromBinary(ABits...)
-	for i := 1; i < PubDataSizePerTx; i++ {
-		pubData[i] = 0
-	}
 	return pubData
 }
 
-func CollectPubDataFromUpdatePairRate(api API, txInfo UpdatePairRateTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+func CollectPubDataFromUpdatePairRate(api API, txInfo UpdatePairRateTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromUpdatePairRate")()
+	pubData = make([]Variable, UpdatePairRatePubDataChunks)
 	txTypeBits := api.ToBinary(TxTypeUpdatePairRate, TxTypeBitsSize)
 	pairIndexBits := api.ToBinary(txInfo.PairIndex, PairIndexBitsSize)
 	FeeRateBits := api.ToBinary(txInfo.FeeRate, PackedFeeBitsSize)
@@ -79,13 +83,12 @@ func CollectPubDataFromUpdatePairRate(api API, txInfo UpdatePairRateTxConstraint
 	}
 	ABits = append(paddingSize[:], ABits...)
 	pubData[0] = api.FromBinary(ABits...)
-	for i := 1; i < PubDataSizePerTx; i++ {
-		pubData[i] = 0
-	}
 	return pubData
 }
 
-func CollectPubDataFromDeposit(api API, txInfo DepositTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+func CollectPubDataFromDeposit(api API, txInfo DepositTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromDeposit")()
+	pubData = make([]Variable, DepositPubDataChunks)
 	txTypeBits := api.ToBinary(TxTypeDeposit, TxTypeBitsSize)
 	accountIndexBits := api.ToBinary(txInfo.AccountIndex, AccountIndexBitsSize)
 	assetIdBits := api.ToBinary(txInfo.AssetId, AssetIdBitsSize)
@@ -100,13 +103,12 @@ func CollectPubDataFromDeposit(api API, txInfo DepositTxConstraints) (pubData [P
 	ABits = append(paddingSize[:], ABits...)
 	pubData[0] = api.FromBinary(ABits...)
 	pubData[1] = txInfo.AccountNameHash
-	for i := 2; i < PubDataSizePerTx; i++ {
-		pubData[i] = 0
-	}
 	return pubData
 }
 
-func CollectPubDataFromDepositNft(api API, txInfo DepositNftTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+func CollectPubDataFromDepositNft(api API, txInfo DepositNftTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromDepositNft")()
+	pubData = make([]Variable, DepositNftPubDataChunks)
 	txTypeBits := api.ToBinary(TxTypeDepositNft, TxTypeBitsSize)
 	accountIndexBits := api.ToBinary(txInfo.AccountIndex, AccountIndexBitsSize)
 	nftIndexBits := api.ToBinary(txInfo.NftIndex, NftIndexBitsSize)
@@ -129,13 +131,12 @@ func CollectPubDataFromDepositNft(api API, txInfo DepositNftTxConstraints) (pubD
 	pubData[2] = txInfo.NftContentHash
 	pubData[3] = txInfo.NftL1TokenId
 	pubData[4] = txInfo.AccountNameHash
-	for i := 5; i < PubDataSizePerTx; i++ {
-		pubData[i] = 0
-	}
 	return pubData
 }
 
-func CollectPubDataFromTransfer(api API, txInfo TransferTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+func CollectPubDataFromTransfer(api API, txInfo TransferTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromTransfer")()
+	pubData = make([]Variable, TransferPubDataChunks)
 	txTypeBits := api.ToBinary(TxTypeTransfer, TxTypeBitsSize)
 	fromAccountIndexBits := api.ToBinary(txInfo.FromAccountIndex, AccountIndexBitsSize)
 	toAccountIndexBits := api.ToBinary(txInfo.ToAccountIndex, AccountIndexBitsSize)
@@ -158,13 +159,12 @@ func CollectPubDataFromTransfer(api API, txInfo TransferTxConstraints) (pubData
 	ABits = append(paddingSize[:], ABits...)
 	pubData[0] = api.FromBinary(ABits...)
 	pubData[1] = txInfo.CallDataHash
-	for i := 2; i < PubDataSizePerTx; i++ {
-		pubData[i] = 0
-	}
 	return pubData
 }
 
-func CollectPubDataFromSwap(api API, txInfo SwapTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+func CollectPubDataFromSwap(api API, txInfo SwapTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromSwap")()
+	pubData = make([]Variable, SwapPubDataChunks)
 	txTypeBits := api.ToBinary(TxTypeSwap, TxTypeBitsSize)
 	fromAccountIndexBits := api.ToBinary(txInfo.FromAccountIndex, AccountIndexBitsSize)
 	pairIndexBits := api.ToBinary(txInfo.PairIndex, PairIndexBitsSize)
@@ -175,6 +175,7 @@ func CollectPubDataFromSwap(api API, txInfo SwapTxConstraints) (pubData [PubData
 	gasAccountIndexBits := api.ToBinary(txInfo.GasAccountIndex, AccountIndexBitsSize)
 	gasFeeAssetIdBits := api.ToBinary(txInfo.GasFeeAssetId, AssetIdBitsSize)
 	gasFeeAssetAmountBits := api.ToBinary(txInfo.GasFeeAssetAmount, PackedFeeBitsSize)
+	swapModeBits := api.ToBinary(txInfo.SwapMode, SwapModeBitsSize)
 	ABits := append(fromAccountIndexBits, txTypeBits...)
 	ABits = append(pairIndexBits, ABits...)
 	ABits = append(assetAIdBits, ABits...)
@@ -184,19 +185,19 @@ func CollectPubDataFromSwap(api API, txInfo SwapTxConstraints) (pubData [PubData
 	ABits = append(gasAccountIndexBits, ABits...)
 	ABits = append(gasFeeAssetIdBits, ABits...)
 	ABits = append(gasFeeAssetAmountBits, ABits...)
-	var paddingSize [24]Variable
-	for i := 0; i < 24; i++ {
+	ABits = append(swapModeBits, ABits...)
+	var paddingSize [16]Variable
+	for i := 0; i < 16; i++ {
 		paddingSize[i] = 0
 	}
 	ABits = append(paddingSize[:], ABits...)
 	pubData[0] = api.FromBinary(ABits...)
-	for i := 1; i < PubDataSizePerTx; i++ {
-		pubData[i] = 0
-	}
 	return pubData
 }
 
-func CollectPubDataFromAddLiquidity(api API, txInfo AddLiquidityTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+func CollectPubDataFromAddLiquidity(api API, txInfo AddLiquidityTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromAddLiquidity")()
+	pubData = make([]Variable, AddLiquidityPubDataChunks)
 	txTypeBits := api.ToBinary(TxTypeAddLiquidity, TxTypeBitsSize)
 	fromAccountIndexBits := api.ToBinary(txInfo.FromAccountIndex, AccountIndexBitsSize)
 	pairIndexBits := api.ToBinary(txInfo.PairIndex, PairIndexBitsSize)
@@ -224,13 +225,12 @@ func CollectPubDataFromAddLiquidity(api API, txInfo AddLiquidityTxConstraints) (
 	BBits = append(gasFeeAssetIdBits, BBits...)
 	BBits = append(gasFeeAssetAmountBits, BBits...)
 	pubData[1] = api.FromBinary(BBits...)
-	for i := 2; i < PubDataSizePerTx; i++ {
-		pubData[i] = 0
-	}
 	return pubData
 }
 
-func CollectPubDataFromRemoveLiquidity(api API, txInfo RemoveLiquidityTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+func CollectPubDataFromRemoveLiquidity(api API, txInfo RemoveLiquidityTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromRemoveLiquidity")()
+	pubData = make([]Variable, RemoveLiquidityPubDataChunks)
 	txTypeBits := api.ToBinary(TxTypeRemoveLiquidity, TxTypeBitsSize)
 	fromAccountIndexBits := api.ToBinary(txInfo.FromAccountIndex, AccountIndexBitsSize)
 	pairIndexBits := api.ToBinary(txInfo.PairIndex, PairIndexBitsSize)
@@ -258,13 +258,12 @@ func CollectPubDataFromRemoveLiquidity(api API, txInfo RemoveLiquidityTxConstrai
 	BBits = append(gasFeeAssetAmountBits, BBits...)
 	pubData[0] = api.FromBinary(ABits...)
 	pubData[1] = api.FromBinary(BBits...)
-	for i := 2; i < PubDataSizePerTx; i++ {
-		pubData[i] = 0
-	}
 	return pubData
 }
 
-func CollectPubDataFromWithdraw(api API, txInfo WithdrawTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+func CollectPubDataFromWithdraw(api API, txInfo WithdrawTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromWithdraw")()
+	pubData = make([]Variable, WithdrawPubDataChunks)
 	txTypeBits := api.ToBinary(TxTypeWithdraw, TxTypeBitsSize)
 	fromAccountIndexBits := api.ToBinary(txInfo.FromAccountIndex, AccountIndexBitsSize)
 	toAddressBits := api.ToBinary(txInfo.ToAddress, AddressBitsSize)
@@ -286,13 +285,12 @@ func CollectPubDataFromWithdraw(api API, txInfo WithdrawTxConstraints) (pubData
 	ABits = append(paddingSize[:], ABits...)
 	pubData[0] = api.FromBinary(ABits...)
 	pubData[1] = api.FromBinary(BBits...)
-	for i := 2; i < PubDataSizePerTx; i++ {
-		pubData[i] = 0
-	}
 	return pubData
 }
 
-func CollectPubDataFromCreateCollection(api API, txInfo CreateCollectionTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+func CollectPubDataFromCreateCollection(api API, txInfo CreateCollectionTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromCreateCollection")()
+	pubData = make([]Variable, CreateCollectionPubDataChunks)
 	txTypeBits := api.ToBinary(TxTypeCreateCollection, TxTypeBitsSize)
 	accountIndexBits := api.ToBinary(txInfo.AccountIndex, AccountIndexBitsSize)
 	collectionIdBits := api.ToBinary(txInfo.CollectionId, CollectionIdBitsSize)
@@ -310,13 +308,12 @@ func CollectPubDataFromCreateCollection(api API, txInfo CreateCollectionTxConstr
 	}
 	ABits = append(paddingSize[:], ABits...)
 	pubData[0] = api.FromBinary(ABits...)
-	for i := 1; i < PubDataSizePerTx; i++ {
-		pubData[i] = 0
-	}
 	return pubData
 }
 
-func CollectPubDataFromMintNft(api API, txInfo MintNftTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+func CollectPubDataFromMintNft(api API, txInfo MintNftTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromMintNft")()
+	pubData = make([]Variable, MintNftPubDataChunks)
 	txTypeBits := api.ToBinary(TxTypeMintNft, TxTypeBitsSize)
 	fromAccountIndexBits := api.ToBinary(txInfo.CreatorAccountIndex, AccountIndexBitsSize)
 	toAccountIndexBits := api.ToBinary(txInfo.ToAccountIndex, AccountIndexBitsSize)
@@ -341,13 +338,12 @@ func CollectPubDataFromMintNft(api API, txInfo MintNftTxConstraints) (pubData [P
 	ABits = append(paddingSize[:], ABits...)
 	pubData[0] = api.FromBinary(ABits...)
 	pubData[1] = txInfo.NftContentHash
-	for i := 2; i < PubDataSizePerTx; i++ {
-		pubData[i] = 0
-	}
 	return pubData
 }
 
-func CollectPubDataFromTransferNft(api API, txInfo TransferNftTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+func CollectPubDataFromTransferNft(api API, txInfo TransferNftTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromTransferNft")()
+	pubData = make([]Variable, TransferNftPubDataChunks)
 	txTypeBits := api.ToBinary(TxTypeTransferNft, TxTypeBitsSize)
 	fromAccountIndexBits := api.ToBinary(txInfo.FromAccountIndex, AccountIndexBitsSize)
 	toAccountIndexBits := api.ToBinary(txInfo.ToAccountIndex, AccountIndexBitsSize)
@@ -368,13 +364,12 @@ func CollectPubDataFromTransferNft(api API, txInfo TransferNftTxConstraints) (pu
 	ABits = append(paddingSize[:], ABits...)
 	pubData[0] = api.FromBinary(ABits...)
 	pubData[1] = txInfo.CallDataHash
-	for i := 2; i < PubDataSizePerTx; i++ {
-		pubData[i] = 0
-	}
 	return pubData
 }
 
-func CollectPubDataFromAtomicMatch(api API, txInfo AtomicMatchTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+func CollectPubDataFromAtomicMatch(api API, txInfo AtomicMatchTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromAtomicMatch")()
+	pubData = make([]Variable, AtomicMatchPubDataChunks)
 	txTypeBits := api.ToBinary(TxTypeAtomicMatch, TxTypeBitsSize)
 	nftIndexBits := api.ToBinary(txInfo.BuyOffer.NftIndex, NftIndexBitsSize)
 	submitterAccountIndexBits := api.ToBinary(txInfo.AccountIndex, AccountIndexBitsSize)
@@ -408,13 +403,12 @@ func CollectPubDataFromAtomicMatch(api API, txInfo AtomicMatchTxConstraints) (pu
 	BBits = append(gasFeeAssetAmountBits, BBits...)
 	pubData[0] = api.FromBinary(ABits...)
 	pubData[1] = api.FromBinary(BBits...)
-	for i := 2; i < PubDataSizePerTx; i++ {
-		pubData[i] = 0
-	}
 	return pubData
 }
 
-func CollectPubDataFromCancelOffer(api API, txInfo CancelOfferTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+func CollectPubDataFromCancelOffer(api API, txInfo CancelOfferTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromCancelOffer")()
+	pubData = make([]Variable, CancelOfferPubDataChunks)
 	txTypeBits := api.ToBinary(TxTypeCancelOffer, TxTypeBitsSize)
 	accountIndexBits := api.ToBinary(txInfo.AccountIndex, AccountIndexBitsSize)
 	offerIdBits := api.ToBinary(txInfo.OfferId, OfferIdBitsSize)
@@ -432,13 +426,12 @@ func CollectPubDataFromCancelOffer(api API, txInfo CancelOfferTxConstraints) (pu
 	}
 	ABits = append(paddingSize[:], ABits...)
 	pubData[0] = api.FromBinary(ABits...)
-	for i := 1; i < PubDataSizePerTx; i++ {
-		pubData[i] = 0
-	}
 	return pubData
 }
 
-func CollectPubDataFromWithdrawNft(api API, txInfo WithdrawNftTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+func CollectPubDataFromWithdrawNft(api API, txInfo WithdrawNftTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromWithdrawNft")()
+	pubData = make([]Variable, WithdrawNftPubDataChunks)
 	txTypeBits := api.ToBinary(TxTypeWithdrawNft, TxTypeBitsSize)
 	accountIndexBits := api.ToBinary(txInfo.AccountIndex, AccountIndexBitsSize)
 	creatorAccountIndexBits := api.ToBinary(txInfo.CreatorAccountIndex, AccountIndexBitsSize)
@@ -471,7 +464,9 @@ func CollectPubDataFromWithdrawNft(api API, txInfo WithdrawNftTxConstraints) (pu
 	return pubData
 }
 
-func CollectPubDataFromFullExit(api API, txInfo FullExitTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+func CollectPubDataFromFullExit(api API, txInfo FullExitTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromFullExit")()
+	pubData = make([]Variable, FullExitPubDataChunks)
 	txTypeBits := api.ToBinary(TxTypeFullExit, TxTypeBitsSize)
 	accountIndexBits := api.ToBinary(txInfo.AccountIndex, AccountIndexBitsSize)
 	assetIdBits := api.ToBinary(txInfo.AssetId, AssetIdBitsSize)
@@ -486,13 +481,12 @@ func CollectPubDataFromFullExit(api API, txInfo FullExitTxConstraints) (pubData
 	ABits = append(paddingSize[:], ABits...)
 	pubData[0] = api.FromBinary(ABits...)
 	pubData[1] = txInfo.AccountNameHash
-	for i := 2; i < PubDataSizePerTx; i++ {
-		pubData[i] = 0
-	}
 	return pubData
 }
 
-func CollectPubDataFromFullExitNft(api API, txInfo FullExitNftTxConstraints) (pubData [PubDataSizePerTx]Variable) {
+func CollectPubDataFromFullExitNft(api API, txInfo FullExitNftTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromFullExitNft")()
+	pubData = make([]Variable, FullExitNftPubDataChunks)
 	txTypeBits := api.ToBinary(TxTypeFullExitNft, TxTypeBitsSize)
 	accountIndexBits := api.ToBinary(txInfo.AccountIndex, AccountIndexBitsSize)
 	creatorAccountIndexBits := api.ToBinary(txInfo.CreatorAccountIndex, AccountIndexBitsSize)
@@ -517,3 +511,146 @@ func CollectPubDataFromFullExitNft(api API, txInfo FullExitNftTxConstraints) (pu
 	pubData[5] = txInfo.NftL1TokenId
 	return pubData
 }
+
+func CollectPubDataFromChangePubKey(api API, txInfo ChangePubKeyTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromChangePubKey")()
+	pubData = make([]Variable, ChangePubKeyPubDataChunks)
+	txTypeBits := api.ToBinary(TxTypeChangePubKey, TxTypeBitsSize)
+	accountIndexBits := api.ToBinary(txInfo.AccountIndex, AccountIndexBitsSize)
+	gasAccountIndexBits := api.ToBinary(txInfo.GasAccountIndex, AccountIndexBitsSize)
+	gasFeeAssetIdBits := api.ToBinary(txInfo.GasFeeAssetId, AssetIdBitsSize)
+	gasFeeAssetAmountBits := api.ToBinary(txInfo.GasFeeAssetAmount, PackedFeeBitsSize)
+	signerThresholdBits := api.ToBinary(txInfo.SignerThreshold, SignerThresholdBitsSize)
+	ABits := append(accountIndexBits, txTypeBits...)
+	ABits = append(gasAccountIndexBits, ABits...)
+	ABits = append(gasFeeAssetIdBits, ABits...)
+	ABits = append(gasFeeAssetAmountBits, ABits...)
+	ABits = append(signerThresholdBits, ABits...)
+	var paddingSize [144]Variable
+	for i := 0; i < 144; i++ {
+		paddingSize[i] = 0
+	}
+	ABits = append(paddingSize[:], ABits...)
+	pubData[0] = api.FromBinary(ABits...)
+	pubData[1] = txInfo.PubKey.A.X
+	pubData[2] = txInfo.PubKey.A.Y
+	pubData[3] = txInfo.SignerRoot
+	return pubData
+}
+
+func CollectPubDataFromFullChangePubKey(api API, txInfo FullChangePubKeyTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromFullChangePubKey")()
+	pubData = make([]Variable, FullChangePubKeyPubDataChunks)
+	txTypeBits := api.ToBinary(TxTypeFullChangePubKey, TxTypeBitsSize)
+	accountIndexBits := api.ToBinary(txInfo.AccountIndex, AccountIndexBitsSize)
+	ABits := append(accountIndexBits, txTypeBits...)
+	var paddingSize [216]Variable
+	for i := 0; i < 216; i++ {
+		paddingSize[i] = 0
+	}
+	ABits = append(paddingSize[:], ABits...)
+	pubData[0] = api.FromBinary(ABits...)
+	pubData[1] = txInfo.AccountNameHash
+	pubData[2] = txInfo.PubKey.A.X
+	pubData[3] = txInfo.PubKey.A.Y
+	return pubData
+}
+
+func CollectPubDataFromMatchOrder(api API, txInfo MatchOrderTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromMatchOrder")()
+	pubData = make([]Variable, MatchOrderPubDataChunks)
+	txTypeBits := api.ToBinary(TxTypeMatchOrder, TxTypeBitsSize)
+	submitterAccountIndexBits := api.ToBinary(txInfo.AccountIndex, AccountIndexBitsSize)
+	buyerAccountIndexBits := api.ToBinary(txInfo.BuyOrder.AccountIndex, AccountIndexBitsSize)
+	buyerOrderIdBits := api.ToBinary(txInfo.BuyOrder.OrderId, OrderIdBitsSize)
+	sellerAccountIndexBits := api.ToBinary(txInfo.SellOrder.AccountIndex, AccountIndexBitsSize)
+	sellerOrderIdBits := api.ToBinary(txInfo.SellOrder.OrderId, OrderIdBitsSize)
+	assetAIdBits := api.ToBinary(txInfo.BuyOrder.AssetAId, AssetIdBitsSize)
+	assetBIdBits := api.ToBinary(txInfo.BuyOrder.AssetBId, AssetIdBitsSize)
+	assetAFillAmountBits := api.ToBinary(txInfo.AssetAFillAmount, PackedAmountBitsSize)
+	assetBFillAmountBits := api.ToBinary(txInfo.AssetBFillAmount, PackedAmountBitsSize)
+	gasAccountIndexBits := api.ToBinary(txInfo.GasAccountIndex, AccountIndexBitsSize)
+	gasFeeAssetIdBits := api.ToBinary(txInfo.GasFeeAssetId, AssetIdBitsSize)
+	gasFeeAssetAmountBits := api.ToBinary(txInfo.GasFeeAssetAmount, PackedFeeBitsSize)
+	ABits := append(submitterAccountIndexBits, txTypeBits...)
+	ABits = append(buyerAccountIndexBits, ABits...)
+	ABits = append(buyerOrderIdBits, ABits...)
+	ABits = append(sellerAccountIndexBits, ABits...)
+	ABits = append(sellerOrderIdBits, ABits...)
+	ABits = append(assetAIdBits, ABits...)
+	ABits = append(assetBIdBits, ABits...)
+	var paddingSize [88]Variable
+	for i := 0; i < 88; i++ {
+		paddingSize[i] = 0
+	}
+	ABits = append(paddingSize[:], ABits...)
+	BBits := append(assetBFillAmountBits, assetAFillAmountBits...)
+	BBits = append(gasAccountIndexBits, BBits...)
+	BBits = append(gasFeeAssetIdBits, BBits...)
+	BBits = append(gasFeeAssetAmountBits, BBits...)
+	pubData[0] = api.FromBinary(ABits...)
+	pubData[1] = api.FromBinary(BBits...)
+	return pubData
+}
+
+func CollectPubDataFromRouteSwap(api API, txInfo RouteSwapTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromRouteSwap")()
+	pubData = make([]Variable, RouteSwapPubDataChunks)
+	txTypeBits := api.ToBinary(TxTypeRouteSwap, TxTypeBitsSize)
+	fromAccountIndexBits := api.ToBinary(txInfo.FromAccountIndex, AccountIndexBitsSize)
+	pairsCountBits := api.ToBinary(txInfo.PairsCount, RoutePairsCountBitsSize)
+	assetAIdBits := api.ToBinary(txInfo.AssetAId, AssetIdBitsSize)
+	assetAAmountBits := api.ToBinary(txInfo.AssetAAmount, PackedAmountBitsSize)
+	assetBIdBits := api.ToBinary(txInfo.AssetBId, AssetIdBitsSize)
+	assetBAmountBits := api.ToBinary(txInfo.AssetBAmountDelta, PackedAmountBitsSize)
+	gasAccountIndexBits := api.ToBinary(txInfo.GasAccountIndex, AccountIndexBitsSize)
+	gasFeeAssetIdBits := api.ToBinary(txInfo.GasFeeAssetId, AssetIdBitsSize)
+	gasFeeAssetAmountBits := api.ToBinary(txInfo.GasFeeAssetAmount, PackedFeeBitsSize)
+	ABits := append(fromAccountIndexBits, txTypeBits...)
+	ABits = append(pairsCountBits, ABits...)
+	for i := 0; i < NbRoutePairsPerTx; i++ {
+		ABits = append(api.ToBinary(txInfo.PairIndexes[i], PairIndexBitsSize), ABits...)
+	}
+	ABits = append(assetAIdBits, ABits...)
+	ABits = append(assetAAmountBits, ABits...)
+	ABits = append(assetBIdBits, ABits...)
+	ABits = append(assetBAmountBits, ABits...)
+	var paddingSize [48]Variable
+	for i := 0; i < 48; i++ {
+		paddingSize[i] = 0
+	}
+	ABits = append(paddingSize[:], ABits...)
+	BBits := api.ToBinary(txInfo.HopAmounts[0], PackedAmountBitsSize)
+	for i := 1; i < NbRoutePairsPerTx-1; i++ {
+		BBits = append(api.ToBinary(txInfo.HopAmounts[i], PackedAmountBitsSize), BBits...)
+	}
+	BBits = append(gasAccountIndexBits, BBits...)
+	BBits = append(gasFeeAssetIdBits, BBits...)
+	BBits = append(gasFeeAssetAmountBits, BBits...)
+	pubData[0] = api.FromBinary(ABits...)
+	pubData[1] = api.FromBinary(BBits...)
+	return pubData
+}
+
+func CollectPubDataFromCancelOrder(api API, txInfo CancelOrderTxConstraints) (pubData []Variable) {
+	defer ProfileScope(api, "CollectPubDataFromCancelOrder")()
+	pubData = make([]Variable, CancelOrderPubDataChunks)
+	txTypeBits := api.ToBinary(TxTypeCancelOrder, TxTypeBitsSize)
+	accountIndexBits := api.ToBinary(txInfo.AccountIndex, AccountIndexBitsSize)
+	orderIdBits := api.ToBinary(txInfo.OrderId, OrderIdBitsSize)
+	gasAccountIndexBits := api.ToBinary(txInfo.GasAccountIndex, AccountIndexBitsSize)
+	gasFeeAssetIdBits := api.ToBinary(txInfo.GasFeeAssetId, AssetIdBitsSize)
+	gasFeeAssetAmountBits := api.ToBinary(txInfo.GasFeeAssetAmount, PackedFeeBitsSize)
+	ABits := append(accountIndexBits, txTypeBits...)
+	ABits = append(orderIdBits, ABits...)
+	ABits = append(gasAccountIndexBits, ABits...)
+	ABits = append(gasFeeAssetIdBits, ABits...)
+	ABits = append(gasFeeAssetAmountBits, ABits...)
+	var paddingSize [136]Variable
+	for i := 0; i < 136; i++ {
+		paddingSize[i] = 0
+	}
+	ABits = append(paddingSize[:], ABits...)
+	pubData[0] = api.FromBinary(ABits...)
+	return pubData
+}
@@ -58,7 +58,8 @@ func VerifyRegisterZNSTx(
 	api API, flag Variable,
 	tx RegisterZnsTxConstraints,
 	accountsBefore [NbAccountsPerTx]AccountConstraints,
-) (pubData [PubDataSizePerTx]Variable) {
+) (pubData []Variable) {
+	defer ProfileScope(api, "VerifyRegisterZNSTx")()
 	pubData = CollectPubDataFromRegisterZNS(api, tx)
 	CheckEmptyAccountNode(api, flag, accountsBefore[0])
 	return pubData
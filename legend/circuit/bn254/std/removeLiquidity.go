@@ -90,9 +90,8 @@ func SetRemoveLiquidityTxWitness(tx *RemoveLiquidityTx) (witness RemoveLiquidity
 	return witness
 }
 
-func ComputeHashFromRemoveLiquidityTx(tx RemoveLiquidityTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
-	hFunc.Reset()
-	hFunc.Write(
+func CollectHashInputsFromRemoveLiquidityTx(tx RemoveLiquidityTxConstraints, nonce Variable, expiredAt Variable) (inputs []Variable) {
+	return []Variable{
 		tx.FromAccountIndex,
 		tx.PairIndex,
 		tx.AssetAMinAmount,
@@ -104,7 +103,12 @@ func ComputeHashFromRemoveLiquidityTx(tx RemoveLiquidityTxConstraints, nonce Var
 		expiredAt,
 		nonce,
 		ChainId,
-	)
+	}
+}
+
+func ComputeHashFromRemoveLiquidityTx(tx RemoveLiquidityTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(CollectHashInputsFromRemoveLiquidityTx(tx, nonce, expiredAt)...)
 	hashVal = hFunc.Sum()
 	return hashVal
 }
@@ -113,7 +117,8 @@ func VerifyRemoveLiquidityTx(
 	api API, flag Variable,
 	tx *RemoveLiquidityTxConstraints,
 	accountsBefore [NbAccountsPerTx]AccountConstraints, liquidityBefore LiquidityConstraints,
-) (pubData [PubDataSizePerTx]Variable, err error) {
+) (pubData []Variable, err error) {
+	defer ProfileScope(api, "VerifyRemoveLiquidityTx")()
 	pubData = CollectPubDataFromRemoveLiquidity(api, *tx)
 	// verify params
 	// account index
@@ -138,17 +143,19 @@ func VerifyRemoveLiquidityTx(
 	tx.TreasuryAmount = UnpackAmount(api, tx.TreasuryAmount)
 	tx.GasFeeAssetAmount = UnpackFee(api, tx.GasFeeAssetAmount)
 	IsVariableLessOrEqual(api, flag, tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[2].Balance)
-	// TODO verify LP
+	// verify treasury amount, StableSwap pairs don't mint treasury lp, see VerifyAddLiquidityTx
+	isStableSwap := api.IsZero(api.Sub(liquidityBefore.PairType, PairTypeStableSwap))
+	isConstantProduct := api.And(flag, api.IsZero(isStableSwap))
 	kCurrent := api.Mul(liquidityBefore.AssetA, liquidityBefore.AssetB)
-	IsVariableLessOrEqual(api, flag, liquidityBefore.KLast, kCurrent)
+	IsVariableLessOrEqual(api, isConstantProduct, liquidityBefore.KLast, kCurrent)
 	IsVariableLessOrEqual(api, flag, liquidityBefore.TreasuryRate, liquidityBefore.FeeRate)
-	sLps, err := api.Compiler().NewHint(ComputeSLp, 1, liquidityBefore.AssetA, liquidityBefore.AssetB, liquidityBefore.KLast, liquidityBefore.FeeRate, liquidityBefore.TreasuryRate)
+	sLp, err := VerifyTreasuryLpAmount(api, isConstantProduct, liquidityBefore)
 	if err != nil {
 		return pubData, err
 	}
-	sLp := sLps[0]
 	IsVariableEqual(api, flag, tx.TreasuryAmount, sLp)
-	poolLpVar := api.Sub(liquidityBefore.LpAmount, sLp)
+	// treasury lp is minted before the liquidity is removed
+	poolLpVar := api.Add(liquidityBefore.LpAmount, sLp)
 	IsVariableLessOrEqual(api, flag, api.Mul(tx.AssetAAmountDelta, poolLpVar), api.Mul(tx.LpAmount, liquidityBefore.AssetA))
 	IsVariableLessOrEqual(api, flag, api.Mul(tx.AssetBAmountDelta, poolLpVar), api.Mul(tx.LpAmount, liquidityBefore.AssetB))
 	IsVariableLessOrEqual(api, flag, tx.AssetAMinAmount, tx.AssetAAmountDelta)
@@ -0,0 +1,249 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package std
+
+/*
+	RouteSwapTx: swaps AssetAAmount of asset A for asset B through PairsCount pairs,
+	the asset out of a pair is the asset in of the next one. HopAmounts are the amounts
+	out of the pairs before the last one, the amount out of the last pair is AssetBAmountDelta
+	and only AssetBMinAmount is checked against it.
+*/
+type RouteSwapTx struct {
+	FromAccountIndex  int64
+	PairsCount        int64
+	PairIndexes       [NbRoutePairsPerTx]int64
+	AssetAId          int64
+	AssetAAmount      int64
+	AssetBId          int64
+	AssetBMinAmount   int64
+	AssetBAmountDelta int64
+	HopAmounts        [NbRoutePairsPerTx - 1]int64
+	GasAccountIndex   int64
+	GasFeeAssetId     int64
+	GasFeeAssetAmount int64
+}
+
+type RouteSwapTxConstraints struct {
+	FromAccountIndex  Variable
+	PairsCount        Variable
+	PairIndexes       [NbRoutePairsPerTx]Variable
+	AssetAId          Variable
+	AssetAAmount      Variable
+	AssetBId          Variable
+	AssetBMinAmount   Variable
+	AssetBAmountDelta Variable
+	HopAmounts        [NbRoutePairsPerTx - 1]Variable
+	GasAccountIndex   Variable
+	GasFeeAssetId     Variable
+	GasFeeAssetAmount Variable
+}
+
+func EmptyRouteSwapTxWitness() (witness RouteSwapTxConstraints) {
+	witness = RouteSwapTxConstraints{
+		FromAccountIndex:  ZeroInt,
+		PairsCount:        ZeroInt,
+		AssetAId:          ZeroInt,
+		AssetAAmount:      ZeroInt,
+		AssetBId:          ZeroInt,
+		AssetBMinAmount:   ZeroInt,
+		AssetBAmountDelta: ZeroInt,
+		GasAccountIndex:   ZeroInt,
+		GasFeeAssetId:     ZeroInt,
+		GasFeeAssetAmount: ZeroInt,
+	}
+	for i := 0; i < NbRoutePairsPerTx; i++ {
+		witness.PairIndexes[i] = ZeroInt
+	}
+	for i := 0; i < NbRoutePairsPerTx-1; i++ {
+		witness.HopAmounts[i] = ZeroInt
+	}
+	return witness
+}
+
+func SetRouteSwapTxWitness(tx *RouteSwapTx) (witness RouteSwapTxConstraints) {
+	witness = RouteSwapTxConstraints{
+		FromAccountIndex:  tx.FromAccountIndex,
+		PairsCount:        tx.PairsCount,
+		AssetAId:          tx.AssetAId,
+		AssetAAmount:      tx.AssetAAmount,
+		AssetBId:          tx.AssetBId,
+		AssetBMinAmount:   tx.AssetBMinAmount,
+		AssetBAmountDelta: tx.AssetBAmountDelta,
+		GasAccountIndex:   tx.GasAccountIndex,
+		GasFeeAssetId:     tx.GasFeeAssetId,
+		GasFeeAssetAmount: tx.GasFeeAssetAmount,
+	}
+	for i := 0; i < NbRoutePairsPerTx; i++ {
+		witness.PairIndexes[i] = tx.PairIndexes[i]
+	}
+	for i := 0; i < NbRoutePairsPerTx-1; i++ {
+		witness.HopAmounts[i] = tx.HopAmounts[i]
+	}
+	return witness
+}
+
+func CollectHashInputsFromRouteSwapTx(tx RouteSwapTxConstraints, nonce Variable, expiredAt Variable) (inputs []Variable) {
+	inputs = []Variable{
+		tx.FromAccountIndex,
+		tx.PairsCount,
+	}
+	inputs = append(inputs, tx.PairIndexes[:]...)
+	inputs = append(inputs,
+		tx.AssetAId,
+		tx.AssetAAmount,
+		tx.AssetBId,
+		tx.AssetBMinAmount,
+		tx.GasAccountIndex,
+		tx.GasFeeAssetId,
+		tx.GasFeeAssetAmount,
+		expiredAt,
+		nonce,
+		ChainId,
+	)
+	return inputs
+}
+
+func ComputeHashFromRouteSwapTx(tx RouteSwapTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(CollectHashInputsFromRouteSwapTx(tx, nonce, expiredAt)...)
+	hashVal = hFunc.Sum()
+	return hashVal
+}
+
+/*
+	RouteSwapHopConstraints: a pair of the route, the amounts are unpacked
+*/
+type RouteSwapHopConstraints struct {
+	IsUsed     Variable
+	AssetInId  Variable
+	IsAssetAIn Variable
+	AmountIn   Variable
+	AmountOut  Variable
+}
+
+/*
+	GetRouteSwapPairFlags: pair i of the route is used if i < PairsCount, the pairs count
+	is checked by VerifyRouteSwapTx
+*/
+func GetRouteSwapPairFlags(api API, flag Variable, pairsCount Variable) (isUsed [NbRoutePairsPerTx]Variable) {
+	for i := 0; i < NbRoutePairsPerTx; i++ {
+		isUsed[i] = flag
+		if i >= MinRoutePairsCount {
+			isUsed[i] = api.And(isUsed[i-1], api.IsZero(api.IsZero(api.Sub(pairsCount, i))))
+		}
+	}
+	return isUsed
+}
+
+func GetRouteSwapHops(
+	api API, flag Variable,
+	tx RouteSwapTxConstraints,
+	liquiditiesBefore [NbRoutePairsPerTx]LiquidityConstraints,
+) (hops [NbRoutePairsPerTx]RouteSwapHopConstraints) {
+	isUsed := GetRouteSwapPairFlags(api, flag, tx.PairsCount)
+	assetInId := tx.AssetAId
+	for i := 0; i < NbRoutePairsPerTx; i++ {
+		hops[i].IsUsed = isUsed[i]
+		hops[i].AssetInId = assetInId
+		hops[i].IsAssetAIn = api.IsZero(api.Sub(assetInId, liquiditiesBefore[i].AssetAId))
+		hops[i].AmountIn = tx.AssetAAmount
+		if i > 0 {
+			hops[i].AmountIn = tx.HopAmounts[i-1]
+		}
+		hops[i].AmountOut = tx.AssetBAmountDelta
+		if i < NbRoutePairsPerTx-1 {
+			hops[i].AmountOut = api.Select(isUsed[i+1], tx.HopAmounts[i], tx.AssetBAmountDelta)
+		}
+		assetInId = api.Select(hops[i].IsAssetAIn, liquiditiesBefore[i].AssetBId, liquiditiesBefore[i].AssetAId)
+	}
+	return hops
+}
+
+func VerifyRouteSwapTx(
+	api API, flag Variable,
+	tx *RouteSwapTxConstraints,
+	accountsBefore [NbAccountsPerTx]AccountConstraints, liquiditiesBefore [NbRoutePairsPerTx]LiquidityConstraints,
+) (pubData []Variable, err error) {
+	defer ProfileScope(api, "VerifyRouteSwapTx")()
+	pubData = CollectPubDataFromRouteSwap(api, *tx)
+	// verify params
+	// account index
+	IsVariableEqual(api, flag, tx.FromAccountIndex, accountsBefore[0].AccountIndex)
+	IsVariableEqual(api, flag, tx.GasAccountIndex, accountsBefore[1].AccountIndex)
+	// asset id
+	IsVariableEqual(api, flag, tx.AssetAId, accountsBefore[0].AssetsInfo[0].AssetId)
+	IsVariableEqual(api, flag, tx.AssetBId, accountsBefore[0].AssetsInfo[1].AssetId)
+	IsVariableEqual(api, flag, tx.GasFeeAssetId, accountsBefore[0].AssetsInfo[2].AssetId)
+	IsVariableEqual(api, flag, tx.GasFeeAssetId, accountsBefore[1].AssetsInfo[0].AssetId)
+	// pairs count
+	IsVariableLessOrEqual(api, flag, MinRoutePairsCount, tx.PairsCount)
+	IsVariableLessOrEqual(api, flag, tx.PairsCount, NbRoutePairsPerTx)
+	// should have enough assets
+	tx.AssetAAmount = UnpackAmount(api, tx.AssetAAmount)
+	tx.AssetBMinAmount = UnpackAmount(api, tx.AssetBMinAmount)
+	tx.AssetBAmountDelta = UnpackAmount(api, tx.AssetBAmountDelta)
+	for i := 0; i < NbRoutePairsPerTx-1; i++ {
+		tx.HopAmounts[i] = UnpackAmount(api, tx.HopAmounts[i])
+	}
+	tx.GasFeeAssetAmount = UnpackFee(api, tx.GasFeeAssetAmount)
+	IsVariableLessOrEqual(api, flag, tx.AssetBMinAmount, tx.AssetBAmountDelta)
+	IsVariableLessOrEqual(api, flag, tx.AssetAAmount, accountsBefore[0].AssetsInfo[0].Balance)
+	IsVariableLessOrEqual(api, flag, tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[2].Balance)
+	// pairs of the route
+	hops := GetRouteSwapHops(api, flag, *tx, liquiditiesBefore)
+	var assetBId Variable = ZeroInt
+	for i := 0; i < NbRoutePairsPerTx; i++ {
+		hop := hops[i]
+		liquidity := liquiditiesBefore[i]
+		IsVariableEqual(api, hop.IsUsed, tx.PairIndexes[i], liquidity.PairIndex)
+		// the pubdata of the unused pairs is 0
+		isUnused := api.Sub(flag, hop.IsUsed)
+		IsVariableEqual(api, isUnused, tx.PairIndexes[i], 0)
+		if i > 0 {
+			IsVariableEqual(api, isUnused, tx.HopAmounts[i-1], 0)
+		}
+		isAssetBIn := api.IsZero(api.Sub(hop.AssetInId, liquidity.AssetBId))
+		IsVariableEqual(api, hop.IsUsed, api.Or(hop.IsAssetAIn, isAssetBIn), 1)
+		assetOutId := api.Select(hop.IsAssetAIn, liquidity.AssetBId, liquidity.AssetAId)
+		assetBId = api.Select(hop.IsUsed, assetOutId, assetBId)
+		// verify AMM, the fee is taken from the amount in:
+		// (RateBase * (reserveIn + amountIn) - feeRate * amountIn) * (reserveOut - amountOut) >= RateBase * reserveIn * reserveOut
+		reserveIn := api.Select(hop.IsAssetAIn, liquidity.AssetA, liquidity.AssetB)
+		reserveOut := api.Select(hop.IsAssetAIn, liquidity.AssetB, liquidity.AssetA)
+		IsVariableLessOrEqual(api, hop.IsUsed, liquidity.FeeRate, RateBase)
+		IsVariableLessOrEqual(api, hop.IsUsed, hop.AmountOut, reserveOut)
+		isStableSwap := api.IsZero(api.Sub(liquidity.PairType, PairTypeStableSwap))
+		l := api.Mul(
+			api.Sub(api.Mul(RateBase, api.Add(reserveIn, hop.AmountIn)), api.Mul(liquidity.FeeRate, hop.AmountIn)),
+			api.Sub(reserveOut, hop.AmountOut),
+		)
+		r := api.Mul(RateBase, reserveIn, reserveOut)
+		IsVariableLessOrEqual(api, api.And(hop.IsUsed, api.IsZero(isStableSwap)), r, l)
+		// verify StableSwap
+		err = VerifyStableSwap(
+			api, api.And(hop.IsUsed, isStableSwap),
+			liquidity.Amplification, liquidity.FeeRate,
+			reserveIn, reserveOut, hop.AmountIn, hop.AmountOut,
+		)
+		if err != nil {
+			return pubData, err
+		}
+	}
+	IsVariableEqual(api, flag, tx.AssetBId, assetBId)
+	return pubData, nil
+}
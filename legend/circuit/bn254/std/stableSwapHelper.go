@@ -0,0 +1,84 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package std
+
+const (
+	// reserves of StableSwap pairs are bounded so that the sides of the invariant,
+	// at most 16A * x * y * (x + y) + 4 * x * y * D < 2^250, can't wrap the field,
+	// AddLiquidity refuses to take a reserve above the bound
+	StableSwapReserveBitsSize   = 76
+	StableSwapInvariantBitsSize = 77
+)
+
+/*
+	VerifyStableSwap: check a swap against a StableSwap pair of amplification A,
+	the invariant D of reserves x and y is the smallest D with
+		16A * x * y * (x + y) + 4 * x * y * D <= 16A * x * y * D + D^3
+	D is computed by Newton iterations in the ComputeStableSwap hint, the circuit only
+	checks that it isn't below the invariant of the reserves before the swap, and that
+	the reserves after the swap, with the fee taken from the amount in, are on or
+	above the curve of D.
+*/
+func VerifyStableSwap(
+	api API, flag Variable,
+	amplification, feeRate Variable,
+	reserveIn, reserveOut, amountIn, amountOut Variable,
+) (err error) {
+	defer ProfileScope(api, "VerifyStableSwap")()
+	amplification = api.Select(flag, amplification, 0)
+	feeRate = api.Select(flag, feeRate, 0)
+	reserveIn = api.Select(flag, reserveIn, 0)
+	reserveOut = api.Select(flag, reserveOut, 0)
+	amountIn = api.Select(flag, amountIn, 0)
+	amountOut = api.Select(flag, amountOut, 0)
+	witness, err := api.Compiler().NewHint(ComputeStableSwap, 2, amplification, reserveIn, reserveOut, amountIn, feeRate)
+	if err != nil {
+		return err
+	}
+	invariant := api.Select(flag, witness[0], 0)
+	amountInAfterFee := api.Select(flag, witness[1], 0)
+	api.ToBinary(invariant, StableSwapInvariantBitsSize)
+	// the fee is rounded up
+	api.ToBinary(amountInAfterFee, StableSwapReserveBitsSize)
+	api.AssertIsLessOrEqual(api.Mul(amountInAfterFee, RateBase), api.Mul(amountIn, api.Sub(RateBase, feeRate)))
+	// reserves, the amount out can't be above the reserve out
+	reserveInAfter := api.Add(reserveIn, amountInAfterFee)
+	reserveOutAfter := api.Sub(reserveOut, amountOut)
+	api.ToBinary(reserveIn, StableSwapReserveBitsSize)
+	api.ToBinary(reserveOut, StableSwapReserveBitsSize)
+	api.ToBinary(reserveInAfter, StableSwapReserveBitsSize)
+	api.ToBinary(reserveOutAfter, StableSwapReserveBitsSize)
+	// invariant before
+	l, r := stableSwapInvariantSides(api, amplification, reserveIn, reserveOut, invariant)
+	api.AssertIsLessOrEqual(l, r)
+	// reserves after
+	l, r = stableSwapInvariantSides(api, amplification, reserveInAfter, reserveOutAfter, invariant)
+	api.AssertIsLessOrEqual(r, l)
+	return nil
+}
+
+/*
+	stableSwapInvariantSides: StableSwapInvariantSides in-circuit
+*/
+func stableSwapInvariantSides(api API, amplification, x, y, d Variable) (l, r Variable) {
+	xy := api.Mul(x, y)
+	axy := api.Mul(16, amplification, xy)
+	l = api.Add(api.Mul(axy, api.Add(x, y)), api.Mul(4, xy, d))
+	r = api.Add(api.Mul(axy, d), api.Mul(d, d, d))
+	return l, r
+}
@@ -0,0 +1,103 @@
+package std
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/test"
+)
+
+type StableSwapConstraints struct {
+	Flag          Variable
+	Amplification Variable
+	FeeRate       Variable
+	ReserveIn     Variable
+	ReserveOut    Variable
+	AmountIn      Variable
+	AmountOut     Variable
+}
+
+func (circuit StableSwapConstraints) Define(api API) error {
+	return VerifyStableSwap(api, circuit.Flag, circuit.Amplification, circuit.FeeRate,
+		circuit.ReserveIn, circuit.ReserveOut, circuit.AmountIn, circuit.AmountOut)
+}
+
+// largest amount out keeping the reserves after the swap on or above the curve
+func stableSwapAmountOut(amplification, reserveIn, reserveOut, amountIn *big.Int, feeRate int64) *big.Int {
+	d := ComputeStableSwapInvariant(amplification, reserveIn, reserveOut)
+	amountInAfterFee := new(big.Int).Mul(amountIn, big.NewInt(RateBase-feeRate))
+	x := new(big.Int).Add(reserveIn, amountInAfterFee.Quo(amountInAfterFee, big.NewInt(RateBase)))
+	low, high := big.NewInt(0), new(big.Int).Set(reserveOut)
+	for low.Cmp(high) < 0 {
+		mid := new(big.Int).Add(low, high)
+		mid.Rsh(mid, 1)
+		l, r := StableSwapInvariantSides(amplification, x, mid, d)
+		if l.Cmp(r) >= 0 {
+			high = mid
+		} else {
+			low = mid.Add(mid, big.NewInt(1))
+		}
+	}
+	return new(big.Int).Sub(reserveOut, low)
+}
+
+func TestVerifyStableSwap(t *testing.T) {
+	bigReserve := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 75), big.NewInt(1))
+	cases := []struct {
+		amplification                 int64
+		reserveIn, reserveOut, amount *big.Int
+		feeRate                       int64
+	}{
+		{100, big.NewInt(1000000), big.NewInt(1000000), big.NewInt(10000), 30},
+		{1, big.NewInt(1000000), big.NewInt(3000000), big.NewInt(10000), 30},
+		{2000, big.NewInt(1000000), big.NewInt(50000), big.NewInt(500000), 0},
+		{65535, bigReserve, bigReserve, big.NewInt(1000000000000), 30},
+	}
+	for i, c := range cases {
+		amplification := big.NewInt(c.amplification)
+		amountOut := stableSwapAmountOut(amplification, c.reserveIn, c.reserveOut, c.amount, c.feeRate)
+		witness := StableSwapConstraints{
+			Flag:          1,
+			Amplification: c.amplification,
+			FeeRate:       c.feeRate,
+			ReserveIn:     c.reserveIn,
+			ReserveOut:    c.reserveOut,
+			AmountIn:      c.amount,
+			AmountOut:     amountOut,
+		}
+		var circuit StableSwapConstraints
+		err := test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16, backend.WithHints(ComputeStableSwap))
+		if err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+		// one more out would be below the curve
+		witness.AmountOut = new(big.Int).Add(amountOut, big.NewInt(1))
+		err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16, backend.WithHints(ComputeStableSwap))
+		if err == nil {
+			t.Fatalf("case %d: wrong amount out accepted", i)
+		}
+	}
+	// reserves above the bound are rejected
+	witness := StableSwapConstraints{
+		Flag:          1,
+		Amplification: 100,
+		FeeRate:       30,
+		ReserveIn:     new(big.Int).Lsh(big.NewInt(1), StableSwapReserveBitsSize),
+		ReserveOut:    big.NewInt(1000000),
+		AmountIn:      big.NewInt(0),
+		AmountOut:     big.NewInt(0),
+	}
+	var circuit StableSwapConstraints
+	err := test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16, backend.WithHints(ComputeStableSwap))
+	if err == nil {
+		t.Fatal("reserves above the bound accepted")
+	}
+	// nothing is checked without the flag
+	witness.Flag = 0
+	err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16, backend.WithHints(ComputeStableSwap))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
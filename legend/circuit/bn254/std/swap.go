@@ -28,6 +28,8 @@ type SwapTx struct {
 	GasAccountIndex   int64
 	GasFeeAssetId     int64
 	GasFeeAssetAmount int64
+	SwapMode          int64
+	AssetAMaxAmount   int64
 }
 
 type SwapTxConstraints struct {
@@ -41,6 +43,8 @@ type SwapTxConstraints struct {
 	GasAccountIndex   Variable
 	GasFeeAssetId     Variable
 	GasFeeAssetAmount Variable
+	SwapMode          Variable
+	AssetAMaxAmount   Variable
 }
 
 func EmptySwapTxWitness() (witness SwapTxConstraints) {
@@ -55,6 +59,8 @@ func EmptySwapTxWitness() (witness SwapTxConstraints) {
 		GasAccountIndex:   ZeroInt,
 		GasFeeAssetId:     ZeroInt,
 		GasFeeAssetAmount: ZeroInt,
+		SwapMode:          SwapModeExactInput,
+		AssetAMaxAmount:   ZeroInt,
 	}
 }
 
@@ -70,26 +76,48 @@ func SetSwapTxWitness(tx *SwapTx) (witness SwapTxConstraints) {
 		GasAccountIndex:   tx.GasAccountIndex,
 		GasFeeAssetId:     tx.GasFeeAssetId,
 		GasFeeAssetAmount: tx.GasFeeAssetAmount,
+		SwapMode:          tx.SwapMode,
+		AssetAMaxAmount:   tx.AssetAMaxAmount,
 	}
 	return witness
 }
 
-func ComputeHashFromSwapTx(tx SwapTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
-	hFunc.Reset()
-	hFunc.Write(
+/*
+	SwapSignedFields: the swap mode is signed above the pair index, the amounts
+	signed are the amount in and the min amount out of an exact input swap, or the
+	max amount in and the amount out of an exact output swap
+*/
+func SwapSignedFields(api API, tx SwapTxConstraints) (packedPairIndex, assetAAmount, assetBAmount Variable) {
+	packedPairIndex = api.Add(tx.PairIndex, api.Mul(tx.SwapMode, 1<<PairIndexBitsSize))
+	assetAAmount = api.Select(tx.SwapMode, tx.AssetAMaxAmount, tx.AssetAAmount)
+	assetBAmount = api.Select(tx.SwapMode, tx.AssetBAmountDelta, tx.AssetBMinAmount)
+	return packedPairIndex, assetAAmount, assetBAmount
+}
+
+func CollectHashInputsFromSwapTx(
+	tx SwapTxConstraints, packedPairIndex, assetAAmount, assetBAmount Variable,
+	nonce Variable, expiredAt Variable,
+) (inputs []Variable) {
+	return []Variable{
 		tx.FromAccountIndex,
-		tx.PairIndex,
+		packedPairIndex,
 		tx.AssetAId,
-		tx.AssetAAmount,
+		assetAAmount,
 		tx.AssetBId,
-		tx.AssetBMinAmount,
+		assetBAmount,
 		tx.GasAccountIndex,
 		tx.GasFeeAssetId,
 		tx.GasFeeAssetAmount,
 		expiredAt,
 		nonce,
 		ChainId,
-	)
+	}
+}
+
+func ComputeHashFromSwapTx(api API, tx SwapTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	packedPairIndex, assetAAmount, assetBAmount := SwapSignedFields(api, tx)
+	hFunc.Write(CollectHashInputsFromSwapTx(tx, packedPairIndex, assetAAmount, assetBAmount, nonce, expiredAt)...)
 	hashVal = hFunc.Sum()
 	return hashVal
 }
@@ -98,7 +126,8 @@ func VerifySwapTx(
 	api API, flag Variable,
 	tx *SwapTxConstraints,
 	accountsBefore [NbAccountsPerTx]AccountConstraints, liquidityBefore LiquidityConstraints,
-) (pubData [PubDataSizePerTx]Variable) {
+) (pubData []Variable, err error) {
+	defer ProfileScope(api, "VerifySwapTx")()
 	pubData = CollectPubDataFromSwap(api, *tx)
 	// verify params
 	// account index
@@ -131,12 +160,18 @@ func VerifySwapTx(
 	)
 	IsVariableEqual(api, flag, tx.GasFeeAssetId, accountsBefore[0].AssetsInfo[2].AssetId)
 	IsVariableEqual(api, flag, tx.GasFeeAssetId, accountsBefore[1].AssetsInfo[0].AssetId)
+	// swap mode
+	isExactOutput := api.Select(flag, tx.SwapMode, SwapModeExactInput)
+	api.AssertIsBoolean(isExactOutput)
+	isExactInput := api.And(flag, api.IsZero(isExactOutput))
 	// should have enough assets
 	tx.AssetAAmount = UnpackAmount(api, tx.AssetAAmount)
 	tx.AssetBMinAmount = UnpackAmount(api, tx.AssetBMinAmount)
 	tx.AssetBAmountDelta = UnpackAmount(api, tx.AssetBAmountDelta)
+	tx.AssetAMaxAmount = UnpackAmount(api, tx.AssetAMaxAmount)
 	tx.GasFeeAssetAmount = UnpackFee(api, tx.GasFeeAssetAmount)
-	IsVariableLessOrEqual(api, flag, tx.AssetBMinAmount, tx.AssetBAmountDelta)
+	IsVariableLessOrEqual(api, isExactInput, tx.AssetBMinAmount, tx.AssetBAmountDelta)
+	IsVariableLessOrEqual(api, isExactOutput, tx.AssetAAmount, tx.AssetAMaxAmount)
 	IsVariableLessOrEqual(api, flag, tx.AssetAAmount, accountsBefore[0].AssetsInfo[0].Balance)
 	IsVariableLessOrEqual(api, flag, tx.GasFeeAssetAmount, accountsBefore[0].AssetsInfo[2].Balance)
 	// pool info
@@ -155,12 +190,24 @@ func VerifySwapTx(
 	assetBAmountAfter := api.Select(isSameAsset, api.Sub(liquidityBefore.AssetB, assetBAmount), api.Add(liquidityBefore.AssetB, assetBAmount))
 	assetBAmountAfter = api.Mul(RateBase, assetBAmountAfter)
 	assetBAmountAfterAdjusted := api.Select(isSameAsset, assetBAmountAfter, api.Sub(assetBAmountAfter, api.Mul(liquidityBefore.FeeRate, assetBAmount)))
+	isStableSwap := api.IsZero(api.Sub(liquidityBefore.PairType, PairTypeStableSwap))
 	// verify AMM
 	r := api.Mul(api.Mul(liquidityBefore.AssetA, liquidityBefore.AssetB), api.Mul(RateBase, RateBase))
 	l := api.Mul(
 		assetAAmountAfterAdjusted,
 		assetBAmountAfterAdjusted,
 	)
-	IsVariableLessOrEqual(api, flag, r, l)
-	return pubData
+	IsVariableLessOrEqual(api, api.And(flag, api.IsZero(isStableSwap)), r, l)
+	// verify StableSwap
+	err = VerifyStableSwap(
+		api, api.And(flag, isStableSwap),
+		liquidityBefore.Amplification, liquidityBefore.FeeRate,
+		api.Select(isSameAsset, liquidityBefore.AssetA, liquidityBefore.AssetB),
+		api.Select(isSameAsset, liquidityBefore.AssetB, liquidityBefore.AssetA),
+		tx.AssetAAmount, tx.AssetBAmountDelta,
+	)
+	if err != nil {
+		return pubData, err
+	}
+	return pubData, nil
 }
@@ -70,9 +70,8 @@ func SetTransferTxWitness(tx *TransferTx) (witness TransferTxConstraints) {
 	return witness
 }
 
-func ComputeHashFromTransferTx(tx TransferTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
-	hFunc.Reset()
-	hFunc.Write(
+func CollectHashInputsFromTransferTx(tx TransferTxConstraints, nonce Variable, expiredAt Variable) (inputs []Variable) {
+	return []Variable{
 		tx.FromAccountIndex,
 		tx.ToAccountIndex,
 		tx.ToAccountNameHash,
@@ -85,7 +84,12 @@ func ComputeHashFromTransferTx(tx TransferTxConstraints, nonce Variable, expired
 		expiredAt,
 		nonce,
 		ChainId,
-	)
+	}
+}
+
+func ComputeHashFromTransferTx(tx TransferTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(CollectHashInputsFromTransferTx(tx, nonce, expiredAt)...)
 	hashVal = hFunc.Sum()
 	return hashVal
 }
@@ -94,7 +98,8 @@ func VerifyTransferTx(
 	api API, flag Variable,
 	tx *TransferTxConstraints,
 	accountsBefore [NbAccountsPerTx]AccountConstraints,
-) (pubData [PubDataSizePerTx]Variable) {
+) (pubData []Variable) {
+	defer ProfileScope(api, "VerifyTransferTx")()
 	// collect pubdata
 	pubData = CollectPubDataFromTransfer(api, *tx)
 	// verify params
@@ -66,9 +66,8 @@ func SetTransferNftTxWitness(tx *TransferNftTx) (witness TransferNftTxConstraint
 	return witness
 }
 
-func ComputeHashFromTransferNftTx(tx TransferNftTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
-	hFunc.Reset()
-	hFunc.Write(
+func CollectHashInputsFromTransferNftTx(tx TransferNftTxConstraints, nonce Variable, expiredAt Variable) (inputs []Variable) {
+	return []Variable{
 		tx.FromAccountIndex,
 		tx.ToAccountIndex,
 		tx.ToAccountNameHash,
@@ -80,7 +79,12 @@ func ComputeHashFromTransferNftTx(tx TransferNftTxConstraints, nonce Variable, e
 		expiredAt,
 		nonce,
 		ChainId,
-	)
+	}
+}
+
+func ComputeHashFromTransferNftTx(tx TransferNftTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(CollectHashInputsFromTransferNftTx(tx, nonce, expiredAt)...)
 	hashVal = hFunc.Sum()
 	return hashVal
 }
@@ -91,7 +95,8 @@ func VerifyTransferNftTx(
 	tx *TransferNftTxConstraints,
 	accountsBefore [NbAccountsPerTx]AccountConstraints,
 	nftBefore NftConstraints,
-) (pubData [PubDataSizePerTx]Variable) {
+) (pubData []Variable) {
+	defer ProfileScope(api, "VerifyTransferNftTx")()
 	pubData = CollectPubDataFromTransferNft(api, *tx)
 	// verify params
 	// account index
@@ -0,0 +1,124 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package std
+
+import "math/big"
+
+const (
+	// pool reserves are bounded so that kCurrent = poolA * poolB can't wrap the field
+	PoolReserveBitsSize = 126
+	SqrtKBitsSize       = 126
+	// r = RateBase * (feeRate / treasuryRate - 1) * sqrt(kCurrent) + RateBase * sqrt(kLast)
+	SLpDenominatorBitsSize = 157
+	SLpAmountBitsSize      = 126
+	PackedMantissaBitsSize = 35
+	PackedExponentBitsSize = 5
+)
+
+/*
+	VerifyTreasuryLpAmount: compute the treasury lp amount of the pool in-circuit,
+	the ComputeSLp hint only provides the roots, quotients and the packed form,
+	and each of them is checked here.
+	sLp = CleanPackedAmount(l / r), l = (sqrt(kCurrent) - sqrt(kLast)) * RateBase
+	the amount is 0 if the pool is empty, the treasury rate is 0 or r is 0
+*/
+func VerifyTreasuryLpAmount(api API, flag Variable, liquidity LiquidityConstraints) (sLp Variable, err error) {
+	defer ProfileScope(api, "VerifyTreasuryLpAmount")()
+	// the leaf is only read when enabled, so that the hint doesn't fail on the
+	// pair of another tx of the slot
+	poolA := api.Select(flag, liquidity.AssetA, 0)
+	poolB := api.Select(flag, liquidity.AssetB, 0)
+	kLast := api.Select(flag, liquidity.KLast, 0)
+	feeRate := api.Select(flag, liquidity.FeeRate, 0)
+	treasuryRate := api.Select(flag, liquidity.TreasuryRate, 0)
+	witness, err := api.Compiler().NewHint(ComputeSLp, 8, poolA, poolB, kLast, feeRate, treasuryRate)
+	if err != nil {
+		return 0, err
+	}
+	// reserves
+	api.ToBinary(poolA, PoolReserveBitsSize)
+	api.ToBinary(poolB, PoolReserveBitsSize)
+	isPoolEmpty := api.Or(api.IsZero(poolA), api.IsZero(poolB))
+	// sqrt(kLast) and sqrt(kCurrent)
+	sqrtKLast := verifySqrt(api, kLast, api.Select(flag, witness[0], 0))
+	sqrtKCurrent := verifySqrt(api, api.Mul(poolA, poolB), api.Select(flag, witness[1], 0))
+	// feeRate / treasuryRate
+	hasTreasury := api.And(flag, api.IsZero(api.IsZero(treasuryRate)))
+	feeRatio := api.Select(hasTreasury, witness[2], 0)
+	feeRatioRem := api.Select(hasTreasury, witness[3], 0)
+	api.ToBinary(feeRatio, FeeRateBitsSize)
+	api.ToBinary(feeRatioRem, FeeRateBitsSize)
+	IsVariableEqual(api, hasTreasury, api.Add(api.Mul(feeRatio, treasuryRate), feeRatioRem), feeRate)
+	IsVariableLess(api, hasTreasury, feeRatioRem, treasuryRate)
+	// l / r
+	l := api.Mul(api.Sub(sqrtKCurrent, sqrtKLast), RateBase)
+	r := api.Add(
+		api.Mul(api.Sub(api.Mul(feeRatio, RateBase), RateBase), sqrtKCurrent),
+		api.Mul(sqrtKLast, RateBase),
+	)
+	isEnabled := api.And(hasTreasury, api.IsZero(isPoolEmpty))
+	isEnabled = api.And(isEnabled, api.IsZero(api.IsZero(r)))
+	l = api.Select(isEnabled, l, 0)
+	r = api.Select(isEnabled, r, 1)
+	sLpAmount := api.Select(isEnabled, witness[4], 0)
+	sLpRem := api.Select(isEnabled, witness[5], 0)
+	api.ToBinary(sLpAmount, SLpAmountBitsSize)
+	api.ToBinary(sLpRem, SLpDenominatorBitsSize)
+	IsVariableLess(api, isEnabled, sLpRem, r)
+	// sLpAmount * r is split at 2^126 so that no product wraps the field,
+	// l < 2^140 bounds the high part to less than 2^15
+	rBits := api.ToBinary(r, SLpDenominatorBitsSize)
+	rLow := api.FromBinary(rBits[:SLpAmountBitsSize]...)
+	rHigh := api.FromBinary(rBits[SLpAmountBitsSize:]...)
+	productHigh := api.Mul(sLpAmount, rHigh)
+	api.ToBinary(productHigh, 15)
+	shift := new(big.Int).Lsh(big.NewInt(1), SLpAmountBitsSize)
+	api.AssertIsEqual(
+		api.Add(api.Mul(productHigh, shift), api.Mul(sLpAmount, rLow), sLpRem),
+		l,
+	)
+	// packed form: mantissa * 10^exponent is the largest packable amount not above l / r
+	mantissa := api.Select(isEnabled, witness[6], 0)
+	exponent := api.Select(isEnabled, witness[7], 0)
+	api.ToBinary(mantissa, PackedMantissaBitsSize)
+	exponentBits := api.ToBinary(exponent, PackedExponentBitsSize)
+	var pow Variable = 1
+	for i := 0; i < PackedExponentBitsSize; i++ {
+		factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(1)<<i), nil)
+		pow = api.Mul(pow, api.Select(exponentBits[i], factor, 1))
+	}
+	sLp = api.Mul(mantissa, pow)
+	IsVariableLessOrEqual(api, isEnabled, sLp, sLpAmount)
+	IsVariableLess(api, isEnabled, api.Sub(sLpAmount, sLp), pow)
+	// exponent is minimal
+	maxMantissa := new(big.Int).Add(PackedAmountMaxMantissa, big.NewInt(1))
+	isExponentUsed := api.And(isEnabled, api.IsZero(api.IsZero(exponent)))
+	IsVariableLessOrEqual(api, isExponentUsed, api.Mul(pow, maxMantissa), api.Mul(sLpAmount, 10))
+	return sLp, nil
+}
+
+/*
+	verifySqrt: check root = floor(sqrt(k)), i.e. root^2 <= k < (root + 1)^2
+*/
+func verifySqrt(api API, k Variable, root Variable) Variable {
+	api.ToBinary(root, SqrtKBitsSize)
+	api.AssertIsLessOrEqual(api.Mul(root, root), k)
+	next := api.Add(root, 1)
+	api.AssertIsLessOrEqual(k, api.Sub(api.Mul(next, next), 1))
+	return root
+}
@@ -0,0 +1,130 @@
+package std
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/test"
+)
+
+type TreasuryLpConstraints struct {
+	Flag      Variable
+	Liquidity LiquidityConstraints
+	SLp       Variable
+}
+
+func (circuit TreasuryLpConstraints) Define(api API) error {
+	sLp, err := VerifyTreasuryLpAmount(api, circuit.Flag, circuit.Liquidity)
+	if err != nil {
+		return err
+	}
+	IsVariableEqual(api, circuit.Flag, sLp, circuit.SLp)
+	return nil
+}
+
+func treasuryLpWitness(poolA, poolB, kLast *big.Int, feeRate, treasuryRate int64) (witness TreasuryLpConstraints, sLp *big.Int, err error) {
+	sLp, err = ComputeSLpAmount(poolA, poolB, kLast, big.NewInt(feeRate), big.NewInt(treasuryRate))
+	if err != nil {
+		return witness, nil, err
+	}
+	witness = TreasuryLpConstraints{
+		Flag: 1,
+		Liquidity: LiquidityConstraints{
+			PairIndex:            1,
+			AssetAId:             0,
+			AssetA:               poolA,
+			AssetBId:             1,
+			AssetB:               poolB,
+			LpAmount:             0,
+			KLast:                kLast,
+			FeeRate:              feeRate,
+			TreasuryAccountIndex: 0,
+			TreasuryRate:         treasuryRate,
+			PairType:             PairTypeConstantProduct,
+			Amplification:        0,
+			PriceACumulativeLast: 0,
+			PriceBCumulativeLast: 0,
+			BlockTimestampLast:   0,
+		},
+		SLp: sLp,
+	}
+	return witness, sLp, nil
+}
+
+func TestVerifyTreasuryLpAmount(t *testing.T) {
+	bigPool, _ := new(big.Int).SetString("50000000000000000000000000000000000000", 10)
+	cases := []struct {
+		poolA, poolB, kLast       *big.Int
+		feeRate, treasuryRate     int64
+		expectZero, expectPacking bool
+	}{
+		{big.NewInt(1000000), big.NewInt(2000000), big.NewInt(1000000), 30, 30, false, false},
+		{big.NewInt(1000000), big.NewInt(2000000), big.NewInt(1000000), 30, 20, false, false},
+		{big.NewInt(1000000), big.NewInt(2000000), big.NewInt(1000000), 30, 5, true, false},
+		{big.NewInt(1000000), big.NewInt(2000000), big.NewInt(1000000), 30, 0, true, false},
+		{big.NewInt(0), big.NewInt(2000000), big.NewInt(0), 30, 5, true, false},
+		{big.NewInt(1000000), big.NewInt(2000000), big.NewInt(2000000000000), 30, 30, true, false},
+		{big.NewInt(1000000), big.NewInt(2000000), big.NewInt(0), 30, 30, true, false},
+		{bigPool, bigPool, big.NewInt(1), 30, 30, false, true},
+	}
+	for i, c := range cases {
+		witness, sLp, err := treasuryLpWitness(c.poolA, c.poolB, c.kLast, c.feeRate, c.treasuryRate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (sLp.Sign() == 0) != c.expectZero {
+			t.Fatalf("case %d: unexpected treasury lp amount %s", i, sLp.String())
+		}
+		if c.expectPacking && sLp.Cmp(PackedAmountMaxMantissa) <= 0 {
+			t.Fatalf("case %d: treasury lp amount %s is not packed", i, sLp.String())
+		}
+		var circuit TreasuryLpConstraints
+		err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16, backend.WithHints(ComputeSLp))
+		if err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+		// a different treasury amount must be rejected
+		witness.SLp = new(big.Int).Add(sLp, big.NewInt(1))
+		err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16, backend.WithHints(ComputeSLp))
+		if err == nil {
+			t.Fatalf("case %d: wrong treasury amount accepted", i)
+		}
+	}
+}
+
+func TestVerifyTreasuryLpAmountDisabled(t *testing.T) {
+	// the pending treasury lp amount of the pair can't be packed, which
+	// doesn't matter when the pair isn't changed
+	hugePool, _ := new(big.Int).SetString("1000000000000000000000000000000000000000000000", 10)
+	if _, err := ComputeSLpAmount(hugePool, hugePool, big.NewInt(1), big.NewInt(30), big.NewInt(30)); err == nil {
+		t.Fatal("unpackable treasury lp amount computed")
+	}
+	witness := TreasuryLpConstraints{
+		Flag: 0,
+		Liquidity: LiquidityConstraints{
+			PairIndex:            1,
+			AssetAId:             0,
+			AssetA:               hugePool,
+			AssetBId:             1,
+			AssetB:               hugePool,
+			LpAmount:             0,
+			KLast:                1,
+			FeeRate:              30,
+			TreasuryAccountIndex: 0,
+			TreasuryRate:         30,
+			PairType:             PairTypeConstantProduct,
+			Amplification:        0,
+			PriceACumulativeLast: 0,
+			PriceBCumulativeLast: 0,
+			BlockTimestampLast:   0,
+		},
+		SLp: 0,
+	}
+	var circuit TreasuryLpConstraints
+	err := test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16, backend.WithHints(ComputeSLp))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package std
+
+import (
+	"errors"
+	"log"
+	"math/big"
+)
+
+const (
+	// prices are fixed point numbers with PriceFractionBitsSize fractional bits,
+	// the price of a reserve over the other one fits PriceBitsSize bits
+	PriceFractionBitsSize = 64
+	PriceBitsSize         = StateAmountBitsSize + PriceFractionBitsSize
+	// accumulators wrap around 2^PriceCumulativeBitsSize, only their differences
+	// are meaningful
+	PriceCumulativeBitsSize = 224
+	// time elapsed between two updates of a pair, in the unit of the block CreatedAt
+	PriceElapsedBitsSize = 48
+	// an update wraps the accumulator less than 2^PriceCumulativeWrapsBitsSize times
+	PriceCumulativeWrapsBitsSize = PriceBitsSize + PriceElapsedBitsSize - PriceCumulativeBitsSize + 1
+)
+
+var (
+	priceFractionBase   = new(big.Int).Lsh(big.NewInt(1), PriceFractionBitsSize)
+	priceCumulativeBase = new(big.Int).Lsh(big.NewInt(1), PriceCumulativeBitsSize)
+)
+
+/*
+	UpdateLiquidityPrices: add the prices of the pair with its reserves before the tx,
+	times the time elapsed since its last update, to its price accumulators, and set
+	its last update to the block CreatedAt. PriceACumulativeLast accumulates the price
+	of asset A in asset B, AssetB / AssetA, PriceBCumulativeLast the inverse one.
+	A pair with an empty reserve doesn't accumulate, and the block CreatedAt can't
+	be before the last update.
+*/
+func UpdateLiquidityPrices(
+	api API, flag Variable,
+	liquidityBefore LiquidityConstraints, liquidityAfter LiquidityConstraints,
+	blockCreatedAt Variable,
+) (liquidityAfterPrices LiquidityConstraints, err error) {
+	defer ProfileScope(api, "UpdateLiquidityPrices")()
+	hasReserves := api.And(flag, api.And(
+		api.Sub(1, api.IsZero(liquidityBefore.AssetA)),
+		api.Sub(1, api.IsZero(liquidityBefore.AssetB)),
+	))
+	elapsed := api.Select(flag, api.Sub(blockCreatedAt, liquidityBefore.BlockTimestampLast), 0)
+	api.ToBinary(elapsed, PriceElapsedBitsSize)
+	elapsed = api.Select(hasReserves, elapsed, 0)
+	reserveA := api.Select(hasReserves, liquidityBefore.AssetA, 1)
+	reserveB := api.Select(hasReserves, liquidityBefore.AssetB, 1)
+	priceACumulative, err := accumulatePrice(api, reserveB, reserveA, elapsed,
+		api.Select(flag, liquidityBefore.PriceACumulativeLast, 0))
+	if err != nil {
+		return liquidityAfter, err
+	}
+	priceBCumulative, err := accumulatePrice(api, reserveA, reserveB, elapsed,
+		api.Select(flag, liquidityBefore.PriceBCumulativeLast, 0))
+	if err != nil {
+		return liquidityAfter, err
+	}
+	liquidityAfter.PriceACumulativeLast = api.Select(flag, priceACumulative, liquidityBefore.PriceACumulativeLast)
+	liquidityAfter.PriceBCumulativeLast = api.Select(flag, priceBCumulative, liquidityBefore.PriceBCumulativeLast)
+	liquidityAfter.BlockTimestampLast = api.Select(flag, blockCreatedAt, liquidityBefore.BlockTimestampLast)
+	return liquidityAfter, nil
+}
+
+/*
+	accumulatePrice: (cumulative + price * elapsed) mod 2^PriceCumulativeBitsSize with
+	price = floor(numerator * 2^PriceFractionBitsSize / denominator), the reserves
+	are below 2^StateAmountBitsSize and the denominator isn't zero
+*/
+func accumulatePrice(api API, numerator, denominator, elapsed, cumulative Variable) (cumulativeAfter Variable, err error) {
+	witness, err := api.Compiler().NewHint(ComputePriceCumulative, 2, numerator, denominator, elapsed, cumulative)
+	if err != nil {
+		return nil, err
+	}
+	price, wraps := witness[0], witness[1]
+	api.ToBinary(price, PriceBitsSize)
+	remainder := api.Sub(api.Mul(numerator, priceFractionBase), api.Mul(price, denominator))
+	api.ToBinary(remainder, StateAmountBitsSize)
+	api.AssertIsLessOrEqual(api.Add(remainder, 1), denominator)
+	cumulativeAfter = api.Sub(api.Add(cumulative, api.Mul(price, elapsed)), api.Mul(wraps, priceCumulativeBase))
+	api.ToBinary(cumulativeAfter, PriceCumulativeBitsSize)
+	api.ToBinary(wraps, PriceCumulativeWrapsBitsSize)
+	return cumulativeAfter, nil
+}
+
+/*
+	ComputeLiquidityPrice: price of a reserve in the other one, as accumulated by the
+	circuit, a fixed point number with PriceFractionBitsSize fractional bits
+*/
+func ComputeLiquidityPrice(reserveOther, reserve *big.Int) (price *big.Int) {
+	if reserve.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Quo(new(big.Int).Lsh(reserveOther, PriceFractionBitsSize), reserve)
+}
+
+/*
+	ComputeLiquidityPriceCumulatives: price accumulators the pair would have if it was
+	updated at the timestamp, the prices since its last update are the ones of its reserves
+*/
+func ComputeLiquidityPriceCumulatives(liquidity *Liquidity, timestamp int64) (priceACumulative, priceBCumulative *big.Int, err error) {
+	if liquidity == nil || timestamp < liquidity.BlockTimestampLast {
+		log.Println("[ComputeLiquidityPriceCumulatives] invalid params")
+		return nil, nil, errors.New("[ComputeLiquidityPriceCumulatives] invalid params")
+	}
+	priceACumulative, priceBCumulative = big.NewInt(0), big.NewInt(0)
+	if liquidity.PriceACumulativeLast != nil {
+		priceACumulative.Set(liquidity.PriceACumulativeLast)
+	}
+	if liquidity.PriceBCumulativeLast != nil {
+		priceBCumulative.Set(liquidity.PriceBCumulativeLast)
+	}
+	if liquidity.AssetA.Sign() == 0 || liquidity.AssetB.Sign() == 0 {
+		return priceACumulative, priceBCumulative, nil
+	}
+	elapsed := big.NewInt(timestamp - liquidity.BlockTimestampLast)
+	priceACumulative.Add(priceACumulative, new(big.Int).Mul(ComputeLiquidityPrice(liquidity.AssetB, liquidity.AssetA), elapsed))
+	priceBCumulative.Add(priceBCumulative, new(big.Int).Mul(ComputeLiquidityPrice(liquidity.AssetA, liquidity.AssetB), elapsed))
+	priceACumulative.Mod(priceACumulative, priceCumulativeBase)
+	priceBCumulative.Mod(priceBCumulative, priceCumulativeBase)
+	return priceACumulative, priceBCumulative, nil
+}
+
+/*
+	ComputeTwap: time-weighted average prices of a pair between two snapshots of its leaf,
+	taken at the timestamps from and to, with PriceFractionBitsSize fractional bits.
+	The accumulators wrap at most once between the snapshots.
+*/
+func ComputeTwap(liquidityFrom *Liquidity, from int64, liquidityTo *Liquidity, to int64) (priceA, priceB *big.Int, err error) {
+	if liquidityFrom == nil || liquidityTo == nil || liquidityFrom.PairIndex != liquidityTo.PairIndex || to <= from {
+		log.Println("[ComputeTwap] invalid params")
+		return nil, nil, errors.New("[ComputeTwap] invalid params")
+	}
+	priceACumulativeFrom, priceBCumulativeFrom, err := ComputeLiquidityPriceCumulatives(liquidityFrom, from)
+	if err != nil {
+		return nil, nil, err
+	}
+	priceACumulativeTo, priceBCumulativeTo, err := ComputeLiquidityPriceCumulatives(liquidityTo, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	elapsed := big.NewInt(to - from)
+	priceA = new(big.Int).Sub(priceACumulativeTo, priceACumulativeFrom)
+	priceA.Mod(priceA, priceCumulativeBase).Quo(priceA, elapsed)
+	priceB = new(big.Int).Sub(priceBCumulativeTo, priceBCumulativeFrom)
+	priceB.Mod(priceB, priceCumulativeBase).Quo(priceB, elapsed)
+	return priceA, priceB, nil
+}
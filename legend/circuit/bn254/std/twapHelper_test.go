@@ -0,0 +1,118 @@
+package std
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/test"
+)
+
+type LiquidityPricesConstraints struct {
+	Flag                 Variable
+	AssetA               Variable
+	AssetB               Variable
+	PriceACumulativeLast Variable
+	PriceBCumulativeLast Variable
+	BlockTimestampLast   Variable
+	BlockCreatedAt       Variable
+	PriceACumulative     Variable
+	PriceBCumulative     Variable
+	BlockTimestamp       Variable
+}
+
+func (circuit LiquidityPricesConstraints) Define(api API) error {
+	liquidity := LiquidityConstraints{
+		AssetA:               circuit.AssetA,
+		AssetB:               circuit.AssetB,
+		PriceACumulativeLast: circuit.PriceACumulativeLast,
+		PriceBCumulativeLast: circuit.PriceBCumulativeLast,
+		BlockTimestampLast:   circuit.BlockTimestampLast,
+	}
+	liquidityAfter, err := UpdateLiquidityPrices(api, circuit.Flag, liquidity, liquidity, circuit.BlockCreatedAt)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(liquidityAfter.PriceACumulativeLast, circuit.PriceACumulative)
+	api.AssertIsEqual(liquidityAfter.PriceBCumulativeLast, circuit.PriceBCumulative)
+	api.AssertIsEqual(liquidityAfter.BlockTimestampLast, circuit.BlockTimestamp)
+	return nil
+}
+
+func TestUpdateLiquidityPrices(t *testing.T) {
+	maxReserve := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), StateAmountBitsSize), big.NewInt(1))
+	nearWrap := new(big.Int).Sub(priceCumulativeBase, big.NewInt(1))
+	cases := []*Liquidity{
+		{AssetA: big.NewInt(100000), AssetB: big.NewInt(300000), PriceACumulativeLast: big.NewInt(0), PriceBCumulativeLast: big.NewInt(0)},
+		{AssetA: big.NewInt(7), AssetB: big.NewInt(3), PriceACumulativeLast: big.NewInt(12345), PriceBCumulativeLast: big.NewInt(678), BlockTimestampLast: 1654000000000},
+		{AssetA: big.NewInt(1), AssetB: maxReserve, PriceACumulativeLast: nearWrap, PriceBCumulativeLast: nearWrap, BlockTimestampLast: 1654000000000},
+		// empty reserves don't accumulate
+		{AssetA: big.NewInt(0), AssetB: big.NewInt(1000), PriceACumulativeLast: big.NewInt(5), PriceBCumulativeLast: big.NewInt(6), BlockTimestampLast: 1654000000000},
+	}
+	var circuit LiquidityPricesConstraints
+	for i, c := range cases {
+		blockCreatedAt := c.BlockTimestampLast + 3600000
+		priceACumulative, priceBCumulative, err := ComputeLiquidityPriceCumulatives(c, blockCreatedAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		witness := LiquidityPricesConstraints{
+			Flag:                 1,
+			AssetA:               c.AssetA,
+			AssetB:               c.AssetB,
+			PriceACumulativeLast: c.PriceACumulativeLast,
+			PriceBCumulativeLast: c.PriceBCumulativeLast,
+			BlockTimestampLast:   c.BlockTimestampLast,
+			BlockCreatedAt:       blockCreatedAt,
+			PriceACumulative:     priceACumulative,
+			PriceBCumulative:     priceBCumulative,
+			BlockTimestamp:       blockCreatedAt,
+		}
+		err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16, backend.WithHints(ComputePriceCumulative))
+		if err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+		// the block can't be created before the last update
+		witness.BlockCreatedAt = c.BlockTimestampLast - 1
+		witness.BlockTimestamp = c.BlockTimestampLast - 1
+		err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16, backend.WithHints(ComputePriceCumulative))
+		if err == nil {
+			t.Fatalf("case %d: block created before the last update accepted", i)
+		}
+		// nothing changes without the flag
+		witness.Flag = 0
+		witness.PriceACumulative = c.PriceACumulativeLast
+		witness.PriceBCumulative = c.PriceBCumulativeLast
+		witness.BlockTimestamp = c.BlockTimestampLast
+		err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16, backend.WithHints(ComputePriceCumulative))
+		if err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+	}
+}
+
+func TestComputeTwap(t *testing.T) {
+	// 2 B per A for 1000ms, then 4 B per A for 3000ms
+	from := &Liquidity{PairIndex: 1, AssetA: big.NewInt(1000), AssetB: big.NewInt(2000),
+		PriceACumulativeLast: new(big.Int).Sub(priceCumulativeBase, big.NewInt(1)), PriceBCumulativeLast: big.NewInt(0), BlockTimestampLast: 10000}
+	priceACumulative, priceBCumulative, err := ComputeLiquidityPriceCumulatives(from, 11000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := &Liquidity{PairIndex: 1, AssetA: big.NewInt(1000), AssetB: big.NewInt(4000),
+		PriceACumulativeLast: priceACumulative, PriceBCumulativeLast: priceBCumulative, BlockTimestampLast: 11000}
+	priceA, priceB, err := ComputeTwap(from, 10000, to, 14000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// (2 * 1000 + 4 * 3000) / 4000 = 3.5, (0.5 * 1000 + 0.25 * 3000) / 4000 = 0.3125
+	expectedA := new(big.Int).Rsh(new(big.Int).Mul(big.NewInt(7), priceFractionBase), 1)
+	expectedB := new(big.Int).Rsh(new(big.Int).Mul(big.NewInt(5), priceFractionBase), 4)
+	if priceA.Cmp(expectedA) != 0 || priceB.Cmp(expectedB) != 0 {
+		t.Fatalf("twap %s %s, expected %s %s", priceA, priceB, expectedA, expectedB)
+	}
+	if _, _, err = ComputeTwap(to, 14000, from, 10000); err == nil {
+		t.Fatal("snapshots out of order accepted")
+	}
+}
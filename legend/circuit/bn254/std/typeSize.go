@@ -21,9 +21,15 @@ const (
 	TxTypeBitsSize              = 8
 	CollectionIdBitsSize        = 16
 	OfferIdBitsSize             = 24
+	OrderIdBitsSize             = 16
 	FeeRateBitsSize             = 16
+	PairTypeBitsSize            = 8
+	AmplificationBitsSize       = 16
 	AccountIndexBitsSize        = 32
 	PairIndexBitsSize           = 16
+	RoutePairsCountBitsSize     = 8
+	SwapModeBitsSize            = 8
+	SignerThresholdBitsSize     = 8
 	AssetIdBitsSize             = 16
 	AccountNameBitsSize         = 256
 	AccountNameHashBitsSize     = 256
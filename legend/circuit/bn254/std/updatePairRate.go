@@ -54,7 +54,8 @@ func VerifyUpdatePairRateTx(
 	api API, flag Variable,
 	tx UpdatePairRateTxConstraints,
 	liquidityBefore LiquidityConstraints,
-) (pubData [PubDataSizePerTx]Variable) {
+) (pubData []Variable) {
+	defer ProfileScope(api, "VerifyUpdatePairRateTx")()
 	pubData = CollectPubDataFromUpdatePairRate(api, tx)
 	// verify params
 	IsVariableEqual(api, flag, tx.PairIndex, liquidityBefore.PairIndex)
@@ -64,9 +64,8 @@ func SetWithdrawTxWitness(tx *WithdrawTx) (witness WithdrawTxConstraints) {
 	return witness
 }
 
-func ComputeHashFromWithdrawTx(tx WithdrawTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
-	hFunc.Reset()
-	hFunc.Write(
+func CollectHashInputsFromWithdrawTx(tx WithdrawTxConstraints, nonce Variable, expiredAt Variable) (inputs []Variable) {
+	return []Variable{
 		tx.FromAccountIndex,
 		tx.AssetId,
 		tx.AssetAmount,
@@ -77,7 +76,12 @@ func ComputeHashFromWithdrawTx(tx WithdrawTxConstraints, nonce Variable, expired
 		expiredAt,
 		nonce,
 		ChainId,
-	)
+	}
+}
+
+func ComputeHashFromWithdrawTx(tx WithdrawTxConstraints, nonce Variable, expiredAt Variable, hFunc MiMC) (hashVal Variable) {
+	hFunc.Reset()
+	hFunc.Write(CollectHashInputsFromWithdrawTx(tx, nonce, expiredAt)...)
 	hashVal = hFunc.Sum()
 	return hashVal
 }
@@ -86,7 +90,8 @@ func VerifyWithdrawTx(
 	api API, flag Variable,
 	tx *WithdrawTxConstraints,
 	accountsBefore [NbAccountsPerTx]AccountConstraints,
-) (pubData [PubDataSizePerTx]Variable) {
+) (pubData []Variable) {
+	defer ProfileScope(api, "VerifyWithdrawTx")()
 	pubData = CollectPubDataFromWithdraw(api, *tx)
 	// verify params
 	// account index
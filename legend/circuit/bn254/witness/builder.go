@@ -0,0 +1,369 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package witness
+
+import (
+	"bytes"
+	"errors"
+	"log"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/executor"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+	"github.com/bnb-chain/zkbas-crypto/wasm/legend/legendTxTypes"
+)
+
+/*
+	txLayout: leaves of the tx slots in the order of the Verify*Tx functions,
+	unused slots are the last account, asset, pair and nft
+*/
+type txLayout struct {
+	AccountIndexes [block.NbAccountsPerTx]int64
+	AssetIds       [block.NbAccountsPerTx][block.NbAccountAssetsPerAccount]int64
+	PairIndex      int64
+	// pairs of a route swap after the first one
+	RoutePairIndexes [block.NbRoutePairsPerTx - 1]int64
+	NftIndex         int64
+}
+
+/*
+	BuildTx: block tx of the tx info with the leaves before and merkle proofs of
+	every slot read from the state, the state is updated to the state after the
+	tx if the tx is accepted by the executor and left unchanged otherwise
+*/
+func (s *State) BuildTx(txInfo legendTxTypes.TxInfo, blockCreatedAt int64) (oTx *block.Tx, err error) {
+	oTx, err = SetTxInfo(txInfo)
+	if err != nil {
+		log.Println("[BuildTx] invalid tx info:", err)
+		return nil, err
+	}
+	err = s.fillTx(oTx, blockCreatedAt)
+	if err == nil {
+		_, err = executor.ExecuteTransaction(oTx, block.TxSlotTypeAll, blockCreatedAt)
+	}
+	if err != nil {
+		s.rollback()
+		log.Println("[BuildTx] unable to build tx:", err)
+		return nil, err
+	}
+	s.commit()
+	return oTx, nil
+}
+
+/*
+	fillTx: the slots are filled one at a time with the leaves updated by the
+	previous slots, as the circuit chains the roots from slot to slot
+*/
+func (s *State) fillTx(oTx *block.Tx, blockCreatedAt int64) (err error) {
+	layout, err := s.txLayout(oTx)
+	if err != nil {
+		return err
+	}
+	oTx.AccountRootBefore = s.AccountTree.RootNode.Value
+	oTx.LiquidityRootBefore = s.LiquidityTree.RootNode.Value
+	oTx.NftRootBefore = s.NftTree.RootNode.Value
+	oTx.StateRootBefore = s.StateRoot()
+	for i := 0; i < block.NbAccountsPerTx; i++ {
+		oTx.AccountsInfoBefore[i] = s.slotAccount(layout.AccountIndexes[i], layout.AssetIds[i])
+	}
+	oTx.LiquidityBefore = s.liquidity(layout.PairIndex)
+	if oTx.TxType == std.TxTypeRouteSwap {
+		for i := 1; i < int(oTx.RouteSwapTxInfo.PairsCount); i++ {
+			oTx.RouteLiquiditiesBefore[i-1] = s.liquidity(layout.RoutePairIndexes[i-1])
+		}
+	}
+	oTx.NftBefore = s.nft(layout.NftIndex)
+
+	for i := 0; i < block.NbAccountsPerTx; i++ {
+		accountIndex := layout.AccountIndexes[i]
+		// the account node is proved with the asset root before the slot
+		oTx.AccountsInfoBefore[i] = s.slotAccount(accountIndex, layout.AssetIds[i])
+		accountProof, err := buildMerkleProofs(s.AccountTree, accountIndex)
+		if err != nil {
+			return err
+		}
+		copy(oTx.MerkleProofsAccountBefore[i][:], accountProof)
+		for j := 0; j < block.NbAccountAssetsPerAccount; j++ {
+			assetId := layout.AssetIds[i][j]
+			oTx.AccountsInfoBefore[i].AssetsInfo[j] = s.accountAsset(accountIndex, assetId)
+			assetProof, err := s.accountAssetProof(accountIndex, assetId)
+			if err != nil {
+				return err
+			}
+			copy(oTx.MerkleProofsAccountAssetsBefore[i][j][:], assetProof)
+			accountsAfter, _, _, _, err := executor.UpdateLeaves(oTx, blockCreatedAt)
+			if err != nil {
+				return err
+			}
+			err = s.updateAccountAsset(accountIndex, oTx.AccountsInfoBefore[i].AssetsInfo[j], accountsAfter[i].AssetsInfo[j])
+			if err != nil {
+				return err
+			}
+		}
+		accountsAfter, _, _, _, err := executor.UpdateLeaves(oTx, blockCreatedAt)
+		if err != nil {
+			return err
+		}
+		err = s.updateAccount(accountIndex, accountsAfter[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	liquidityProof, err := buildMerkleProofs(s.LiquidityTree, layout.PairIndex)
+	if err != nil {
+		return err
+	}
+	copy(oTx.MerkleProofsLiquidityBefore[:], liquidityProof)
+	nftProof, err := buildMerkleProofs(s.NftTree, layout.NftIndex)
+	if err != nil {
+		return err
+	}
+	copy(oTx.MerkleProofsNftBefore[:], nftProof)
+	_, liquidityAfter, _, nftAfter, err := executor.UpdateLeaves(oTx, blockCreatedAt)
+	if err != nil {
+		return err
+	}
+	err = s.updateLiquidity(oTx.LiquidityBefore, liquidityAfter)
+	if err != nil {
+		return err
+	}
+	// the pairs of a route swap are proved against the liquidity root after the
+	// pairs before them
+	for i := 0; i < block.NbRoutePairsPerTx-1; i++ {
+		pairIndex := layout.RoutePairIndexes[i]
+		if oTx.TxType != std.TxTypeRouteSwap || i+1 >= int(oTx.RouteSwapTxInfo.PairsCount) {
+			break
+		}
+		oTx.RouteLiquiditiesBefore[i] = s.liquidity(pairIndex)
+		routeLiquidityProof, err := buildMerkleProofs(s.LiquidityTree, pairIndex)
+		if err != nil {
+			return err
+		}
+		copy(oTx.MerkleProofsRouteLiquiditiesBefore[i][:], routeLiquidityProof)
+		_, _, routeLiquiditiesAfter, _, err := executor.UpdateLeaves(oTx, blockCreatedAt)
+		if err != nil {
+			return err
+		}
+		err = s.updateLiquidity(oTx.RouteLiquiditiesBefore[i], routeLiquiditiesAfter[i])
+		if err != nil {
+			return err
+		}
+	}
+	err = s.updateNft(oTx.NftBefore, nftAfter)
+	if err != nil {
+		return err
+	}
+	oTx.StateRootAfter = s.StateRoot()
+	return nil
+}
+
+/*
+	updateAccountAsset, updateAccount, updateLiquidity, updateNft: set the leaf
+	after the slot, leaves which are unchanged by the tx aren't written
+*/
+func (s *State) updateAccountAsset(accountIndex int64, before, after *std.AccountAsset) (err error) {
+	beforeHash, err := accountAssetNodeHash(before)
+	if err != nil {
+		return err
+	}
+	afterHash, err := accountAssetNodeHash(after)
+	if err != nil || bytes.Equal(beforeHash, afterHash) {
+		return err
+	}
+	return s.setAccountAsset(accountIndex, after)
+}
+
+func (s *State) updateAccount(accountIndex int64, after *std.Account) (err error) {
+	before := s.slotAccount(accountIndex, [block.NbAccountAssetsPerAccount]int64{})
+	// the asset root is kept up to date by the asset updates
+	after.AssetRoot = before.AssetRoot
+	beforeHash, err := accountNodeHash(before)
+	if err != nil {
+		return err
+	}
+	afterHash, err := accountNodeHash(after)
+	if err != nil || bytes.Equal(beforeHash, afterHash) {
+		return err
+	}
+	return s.setAccount(&Account{
+		AccountIndex:    accountIndex,
+		AccountNameHash: after.AccountNameHash,
+		AccountPk:       after.AccountPk,
+		Nonce:           after.Nonce,
+		CollectionNonce: after.CollectionNonce,
+		SignerRoot:      after.SignerRoot,
+		SignerThreshold: after.SignerThreshold,
+	})
+}
+
+func (s *State) updateLiquidity(before, after *std.Liquidity) (err error) {
+	beforeHash, err := liquidityNodeHash(before)
+	if err != nil {
+		return err
+	}
+	afterHash, err := liquidityNodeHash(after)
+	if err != nil || bytes.Equal(beforeHash, afterHash) {
+		return err
+	}
+	return s.setLiquidity(after)
+}
+
+func (s *State) updateNft(before, after *std.Nft) (err error) {
+	beforeHash, err := nftNodeHash(before)
+	if err != nil {
+		return err
+	}
+	afterHash, err := nftNodeHash(after)
+	if err != nil || bytes.Equal(beforeHash, afterHash) {
+		return err
+	}
+	return s.setNft(after)
+}
+
+/*
+	txLayout: accounts, assets, pair and nft of the tx slots, the treasury
+	account of a pair and the creator of an nft are read from the state
+*/
+func (s *State) txLayout(oTx *block.Tx) (layout *txLayout, err error) {
+	layout = &txLayout{
+		PairIndex: block.LastPairIndex,
+		NftIndex:  block.LastNftIndex,
+	}
+	for i := 0; i < block.NbAccountsPerTx; i++ {
+		layout.AccountIndexes[i] = block.LastAccountIndex
+		for j := 0; j < block.NbAccountAssetsPerAccount; j++ {
+			layout.AssetIds[i][j] = block.LastAccountAssetId
+		}
+	}
+	setAccount := func(i int, accountIndex int64, assetIds ...int64) {
+		layout.AccountIndexes[i] = accountIndex
+		copy(layout.AssetIds[i][:], assetIds)
+	}
+	switch oTx.TxType {
+	case std.TxTypeRegisterZns:
+		setAccount(0, oTx.RegisterZnsTxInfo.AccountIndex)
+	case std.TxTypeCreatePair:
+		layout.PairIndex = oTx.CreatePairTxInfo.PairIndex
+	case std.TxTypeUpdatePairRate:
+		layout.PairIndex = oTx.UpdatePairRateTxInfo.PairIndex
+	case std.TxTypeDeposit:
+		txInfo := oTx.DepositTxInfo
+		setAccount(0, txInfo.AccountIndex, txInfo.AssetId)
+	case std.TxTypeDepositNft:
+		setAccount(0, oTx.DepositNftTxInfo.AccountIndex)
+		layout.NftIndex = oTx.DepositNftTxInfo.NftIndex
+	case std.TxTypeTransfer:
+		txInfo := oTx.TransferTxInfo
+		setAccount(0, txInfo.FromAccountIndex, txInfo.AssetId, txInfo.GasFeeAssetId)
+		setAccount(1, txInfo.ToAccountIndex, txInfo.AssetId)
+		setAccount(2, txInfo.GasAccountIndex, txInfo.GasFeeAssetId)
+	case std.TxTypeSwap:
+		txInfo := oTx.SwapTxInfo
+		setAccount(0, txInfo.FromAccountIndex, txInfo.AssetAId, txInfo.AssetBId, txInfo.GasFeeAssetId)
+		setAccount(1, txInfo.GasAccountIndex, txInfo.GasFeeAssetId)
+		layout.PairIndex = txInfo.PairIndex
+	case std.TxTypeRouteSwap:
+		txInfo := oTx.RouteSwapTxInfo
+		setAccount(0, txInfo.FromAccountIndex, txInfo.AssetAId, txInfo.AssetBId, txInfo.GasFeeAssetId)
+		setAccount(1, txInfo.GasAccountIndex, txInfo.GasFeeAssetId)
+		layout.PairIndex = txInfo.PairIndexes[0]
+		copy(layout.RoutePairIndexes[:], txInfo.PairIndexes[1:])
+	case std.TxTypeAddLiquidity:
+		txInfo := oTx.AddLiquidityTxInfo
+		treasuryAccountIndex := s.liquidity(txInfo.PairIndex).TreasuryAccountIndex
+		setAccount(0, txInfo.FromAccountIndex, txInfo.AssetAId, txInfo.AssetBId, txInfo.GasFeeAssetId, txInfo.PairIndex)
+		setAccount(1, treasuryAccountIndex, txInfo.PairIndex)
+		setAccount(2, txInfo.GasAccountIndex, txInfo.GasFeeAssetId)
+		layout.PairIndex = txInfo.PairIndex
+	case std.TxTypeRemoveLiquidity:
+		txInfo := oTx.RemoveLiquidityTxInfo
+		treasuryAccountIndex := s.liquidity(txInfo.PairIndex).TreasuryAccountIndex
+		setAccount(0, txInfo.FromAccountIndex, txInfo.AssetAId, txInfo.AssetBId, txInfo.GasFeeAssetId, txInfo.PairIndex)
+		setAccount(1, treasuryAccountIndex, txInfo.PairIndex)
+		setAccount(2, txInfo.GasAccountIndex, txInfo.GasFeeAssetId)
+		layout.PairIndex = txInfo.PairIndex
+	case std.TxTypeWithdraw:
+		txInfo := oTx.WithdrawTxInfo
+		setAccount(0, txInfo.FromAccountIndex, txInfo.AssetId, txInfo.GasFeeAssetId)
+		setAccount(1, txInfo.GasAccountIndex, txInfo.GasFeeAssetId)
+	case std.TxTypeCreateCollection:
+		txInfo := oTx.CreateCollectionTxInfo
+		setAccount(0, txInfo.AccountIndex, txInfo.GasFeeAssetId)
+		setAccount(1, txInfo.GasAccountIndex, txInfo.GasFeeAssetId)
+	case std.TxTypeMintNft:
+		txInfo := oTx.MintNftTxInfo
+		setAccount(0, txInfo.CreatorAccountIndex, txInfo.GasFeeAssetId)
+		setAccount(1, txInfo.ToAccountIndex)
+		setAccount(2, txInfo.GasAccountIndex, txInfo.GasFeeAssetId)
+		layout.NftIndex = txInfo.NftIndex
+	case std.TxTypeTransferNft:
+		txInfo := oTx.TransferNftTxInfo
+		setAccount(0, txInfo.FromAccountIndex, txInfo.GasFeeAssetId)
+		setAccount(1, txInfo.ToAccountIndex)
+		setAccount(2, txInfo.GasAccountIndex, txInfo.GasFeeAssetId)
+		layout.NftIndex = txInfo.NftIndex
+	case std.TxTypeAtomicMatch:
+		txInfo := oTx.AtomicMatchTxInfo
+		buyOffer, sellOffer := txInfo.BuyOffer, txInfo.SellOffer
+		creatorAccountIndex := s.nft(sellOffer.NftIndex).CreatorAccountIndex
+		setAccount(0, txInfo.AccountIndex, txInfo.GasFeeAssetId)
+		setAccount(1, buyOffer.AccountIndex, buyOffer.AssetId, buyOffer.OfferId/std.OfferSizePerAsset)
+		setAccount(2, sellOffer.AccountIndex, sellOffer.AssetId, sellOffer.OfferId/std.OfferSizePerAsset)
+		setAccount(3, creatorAccountIndex, sellOffer.AssetId)
+		setAccount(4, txInfo.GasAccountIndex, sellOffer.AssetId, txInfo.GasFeeAssetId)
+		layout.NftIndex = sellOffer.NftIndex
+	case std.TxTypeCancelOffer:
+		txInfo := oTx.CancelOfferTxInfo
+		setAccount(0, txInfo.AccountIndex, txInfo.GasFeeAssetId, txInfo.OfferId/std.OfferSizePerAsset)
+		setAccount(1, txInfo.GasAccountIndex, txInfo.GasFeeAssetId)
+	case std.TxTypeWithdrawNft:
+		txInfo := oTx.WithdrawNftTxInfo
+		setAccount(0, txInfo.AccountIndex, txInfo.GasFeeAssetId)
+		setAccount(1, txInfo.CreatorAccountIndex)
+		setAccount(2, txInfo.GasAccountIndex, txInfo.GasFeeAssetId)
+		layout.NftIndex = txInfo.NftIndex
+	case std.TxTypeFullExit:
+		txInfo := oTx.FullExitTxInfo
+		setAccount(0, txInfo.AccountIndex, txInfo.AssetId)
+	case std.TxTypeFullExitNft:
+		setAccount(0, oTx.FullExitNftTxInfo.AccountIndex)
+		layout.NftIndex = oTx.FullExitNftTxInfo.NftIndex
+	case std.TxTypeChangePubKey:
+		txInfo := oTx.ChangePubKeyTxInfo
+		setAccount(0, txInfo.AccountIndex, txInfo.GasFeeAssetId)
+		setAccount(1, txInfo.GasAccountIndex, txInfo.GasFeeAssetId)
+	case std.TxTypeFullChangePubKey:
+		setAccount(0, oTx.FullChangePubKeyTxInfo.AccountIndex)
+	case std.TxTypeMatchOrder:
+		txInfo := oTx.MatchOrderTxInfo
+		buyOrder, sellOrder := txInfo.BuyOrder, txInfo.SellOrder
+		setAccount(0, txInfo.AccountIndex, txInfo.GasFeeAssetId)
+		setAccount(1, buyOrder.AccountIndex, buyOrder.AssetBId, buyOrder.AssetAId, buyOrder.OrderId)
+		setAccount(2, sellOrder.AccountIndex, sellOrder.AssetAId, sellOrder.AssetBId, sellOrder.OrderId)
+		setAccount(3, txInfo.GasAccountIndex, txInfo.GasFeeAssetId)
+	case std.TxTypeCancelOrder:
+		txInfo := oTx.CancelOrderTxInfo
+		setAccount(0, txInfo.AccountIndex, txInfo.GasFeeAssetId, txInfo.OrderId)
+		setAccount(1, txInfo.GasAccountIndex, txInfo.GasFeeAssetId)
+	default:
+		log.Println("[txLayout] invalid tx type")
+		return nil, errors.New("[txLayout] invalid tx type")
+	}
+	return layout, nil
+}
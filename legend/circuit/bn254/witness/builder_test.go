@@ -0,0 +1,1469 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package witness
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/test"
+
+	curve "github.com/bnb-chain/zkbas-crypto/ecc/ztwistededwards/tebn254"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/executor"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+	"github.com/bnb-chain/zkbas-crypto/wasm/legend/legendTxTypes"
+)
+
+func accountNameHash(accountName string) []byte {
+	hFunc := mimc.NewMiMC()
+	hFunc.Write(legendTxTypes.PaddingStringToBytes32(accountName))
+	return hFunc.Sum(nil)
+}
+
+func registerAccount(t *testing.T, s *State, accountIndex int64, accountName string) *curve.PrivateKey {
+	sk, err := curve.GenerateEddsaPrivateKey(accountName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buildTx(t, s, &legendTxTypes.RegisterZnsTxInfo{
+		TxType:          legendTxTypes.TxTypeRegisterZns,
+		AccountIndex:    accountIndex,
+		AccountName:     accountName,
+		AccountNameHash: accountNameHash(accountName),
+		PubKey:          hex.EncodeToString(sk.PublicKey.Bytes()),
+	})
+	return sk
+}
+
+/*
+	buildTx: the built tx is accepted by the executor and by the circuit in its
+	typed slot, from the state root before to the state root after
+*/
+func buildTx(t *testing.T, s *State, txInfo legendTxTypes.TxInfo) *block.Tx {
+	stateRootBefore := s.StateRoot()
+	oTx, err := s.BuildTx(txInfo, block.TxConstraintsBlockCreatedAt)
+	if err != nil {
+		t.Fatalf("tx type %d: %v", txInfo.GetTxType(), err)
+	}
+	if !bytes.Equal(oTx.StateRootBefore, stateRootBefore) || !bytes.Equal(oTx.StateRootAfter, s.StateRoot()) {
+		t.Fatalf("tx type %d: invalid state roots", txInfo.GetTxType())
+	}
+	slotType, err := block.GetTxSlotType(int(oTx.TxType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = executor.ExecuteTransaction(oTx, slotType, block.TxConstraintsBlockCreatedAt); err != nil {
+		t.Fatalf("tx type %d: %v", txInfo.GetTxType(), err)
+	}
+	witness, err := block.SetTxWitness(oTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var circuit block.TxConstraints
+	circuit.SlotType = slotType
+	err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16, backend.WithHints(std.ComputeSLp, std.ComputeStableSwap, std.ComputePriceCumulative))
+	if err != nil {
+		t.Fatalf("tx type %d: %v", txInfo.GetTxType(), err)
+	}
+	return oTx
+}
+
+func TestBuildTx(t *testing.T) {
+	s, err := NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if std.EmptyAssetRoot.Cmp(new(big.Int).SetBytes(s.slotAccount(0, [block.NbAccountAssetsPerAccount]int64{}).AssetRoot)) != 0 {
+		t.Fatal("invalid empty asset root")
+	}
+	registerAccount(t, s, 0, "treasury.legend")
+	sk := registerAccount(t, s, 1, "sher.legend")
+	registerAccount(t, s, 2, "gavin.legend")
+	buildTx(t, s, &legendTxTypes.DepositTxInfo{
+		TxType:          legendTxTypes.TxTypeDeposit,
+		AccountIndex:    1,
+		AccountNameHash: accountNameHash("sher.legend"),
+		AssetId:         0,
+		AssetAmount:     big.NewInt(100000000),
+	})
+	// the asset and the gas fee asset are the same asset of the sender
+	segment := fmt.Sprintf(`{"from_account_index":1,"to_account_index":2,"to_account_name":"%x",`+
+		`"asset_id":0,"asset_amount":"10000","gas_account_index":0,"gas_fee_asset_id":0,`+
+		`"gas_fee_asset_amount":"100","memo":"","call_data":"","expired_at":1654656781000,"nonce":0}`,
+		accountNameHash("gavin.legend"))
+	transferTxInfo, err := legendTxTypes.ConstructTransferTxInfo(sk, segment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oTx := buildTx(t, s, transferTxInfo)
+	if oTx.AccountsInfoBefore[0].AssetsInfo[1].Balance.Int64() != 100000000-10000 {
+		t.Fatal("gas fee asset before not updated by the transfer")
+	}
+	for accountIndex, balance := range map[int64]int64{0: 100, 1: 100000000 - 10000 - 100, 2: 10000} {
+		if s.accountAsset(accountIndex, 0).Balance.Int64() != balance {
+			t.Fatalf("invalid balance of account %d", accountIndex)
+		}
+	}
+	if s.Accounts[1].Nonce != 1 {
+		t.Fatal("nonce not updated")
+	}
+	// the replayed transfer is rejected and the state is left unchanged
+	stateRoot := s.StateRoot()
+	if _, err = s.BuildTx(transferTxInfo, 0); err == nil {
+		t.Fatal("replayed transfer accepted")
+	}
+	if !bytes.Equal(s.StateRoot(), stateRoot) || s.accountAsset(2, 0).Balance.Int64() != 10000 {
+		t.Fatal("state updated by a rejected tx")
+	}
+}
+
+func TestChangePubKey(t *testing.T) {
+	s, err := NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerAccount(t, s, 0, "treasury.legend")
+	sk := registerAccount(t, s, 1, "sher.legend")
+	buildTx(t, s, &legendTxTypes.DepositTxInfo{
+		TxType:          legendTxTypes.TxTypeDeposit,
+		AccountIndex:    1,
+		AccountNameHash: accountNameHash("sher.legend"),
+		AssetId:         0,
+		AssetAmount:     big.NewInt(100000000),
+	})
+	newSk, err := curve.GenerateEddsaPrivateKey("sher.legend.new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newPubKey := hex.EncodeToString(newSk.PublicKey.Bytes())
+	segment := fmt.Sprintf(`{"account_index":1,"pub_key":"%s","gas_account_index":0,"gas_fee_asset_id":0,`+
+		`"gas_fee_asset_amount":"100","expired_at":1654656781000,"nonce":0}`, newPubKey)
+	// signed by the new key, the current key is required
+	changePubKeyTxInfo, err := legendTxTypes.ConstructChangePubKeyTxInfo(newSk, segment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.BuildTx(changePubKeyTxInfo, 0); err == nil {
+		t.Fatal("change pub key signed by the new key accepted")
+	}
+	if changePubKeyTxInfo, err = legendTxTypes.ConstructChangePubKeyTxInfo(sk, segment); err != nil {
+		t.Fatal(err)
+	}
+	buildTx(t, s, changePubKeyTxInfo)
+	if !s.Accounts[1].AccountPk.Equal(&newSk.PublicKey) || s.accountAsset(1, 0).Balance.Int64() != 100000000-100 {
+		t.Fatal("account not updated by the change pub key")
+	}
+	// txs are signed by the new key only
+	transferSegment := func(nonce int64) string {
+		return fmt.Sprintf(`{"from_account_index":1,"to_account_index":0,"to_account_name":"%x",`+
+			`"asset_id":0,"asset_amount":"10000","gas_account_index":0,"gas_fee_asset_id":0,`+
+			`"gas_fee_asset_amount":"100","memo":"","call_data":"","expired_at":1654656781000,"nonce":%d}`,
+			accountNameHash("treasury.legend"), nonce)
+	}
+	transferTxInfo, err := legendTxTypes.ConstructTransferTxInfo(sk, transferSegment(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.BuildTx(transferTxInfo, 0); err == nil {
+		t.Fatal("transfer signed by the old key accepted")
+	}
+	if transferTxInfo, err = legendTxTypes.ConstructTransferTxInfo(newSk, transferSegment(1)); err != nil {
+		t.Fatal(err)
+	}
+	buildTx(t, s, transferTxInfo)
+	// the owner sets the old key again from L1
+	fullChangePubKeyTxInfo := &legendTxTypes.FullChangePubKeyTxInfo{
+		TxType:          legendTxTypes.TxTypeFullChangePubKey,
+		AccountIndex:    1,
+		AccountNameHash: accountNameHash("treasury.legend"),
+		PubKey:          hex.EncodeToString(sk.PublicKey.Bytes()),
+	}
+	if _, err = s.BuildTx(fullChangePubKeyTxInfo, 0); err == nil {
+		t.Fatal("full change pub key of another account name accepted")
+	}
+	fullChangePubKeyTxInfo.AccountNameHash = accountNameHash("sher.legend")
+	oTx := buildTx(t, s, fullChangePubKeyTxInfo)
+	if !s.Accounts[1].AccountPk.Equal(&sk.PublicKey) || s.Accounts[1].Nonce != 2 {
+		t.Fatal("account not updated by the full change pub key")
+	}
+	if !block.IsOnChainOp(oTx.TxType) || !block.IsPriorityOp(oTx.TxType) {
+		t.Fatal("full change pub key is not an L1 op")
+	}
+}
+
+func TestMultiSig(t *testing.T) {
+	s, err := NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerAccount(t, s, 0, "treasury.legend")
+	sk := registerAccount(t, s, 1, "sher.legend")
+	buildTx(t, s, &legendTxTypes.DepositTxInfo{
+		TxType:          legendTxTypes.TxTypeDeposit,
+		AccountIndex:    1,
+		AccountNameHash: accountNameHash("sher.legend"),
+		AssetId:         0,
+		AssetAmount:     big.NewInt(100000000),
+	})
+	var (
+		signerSks     []*curve.PrivateKey
+		signerPubKeys []string
+	)
+	for i := 0; i < 3; i++ {
+		signerSk, err := curve.GenerateEddsaPrivateKey(fmt.Sprintf("signer%d.legend", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		signerSks = append(signerSks, signerSk)
+		signerPubKeys = append(signerPubKeys, hex.EncodeToString(signerSk.PublicKey.Bytes()))
+	}
+	// the key of the account is replaced by a 2-of-3 signer set
+	signerPubKeysBytes, err := json.Marshal(signerPubKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changePubKeyTxInfo, err := legendTxTypes.ConstructChangePubKeyTxInfo(sk, fmt.Sprintf(
+		`{"account_index":1,"pub_key":"","gas_account_index":0,"gas_fee_asset_id":0,`+
+			`"gas_fee_asset_amount":"100","expired_at":1654656781000,"nonce":0,`+
+			`"signer_pub_keys":%s,"signer_threshold":2}`, signerPubKeysBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buildTx(t, s, changePubKeyTxInfo)
+	if s.Accounts[1].SignerThreshold != 2 || !bytes.Equal(s.Accounts[1].SignerRoot, changePubKeyTxInfo.SignerRoot) {
+		t.Fatal("account not updated by the change pub key")
+	}
+	transferTxInfo, err := legendTxTypes.ConstructTransferTxInfo(sk, fmt.Sprintf(
+		`{"from_account_index":1,"to_account_index":0,"to_account_name":"%x",`+
+			`"asset_id":0,"asset_amount":"10000","gas_account_index":0,"gas_fee_asset_id":0,`+
+			`"gas_fee_asset_amount":"100","memo":"","call_data":"","expired_at":1654656781000,"nonce":1}`,
+		accountNameHash("treasury.legend")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the old key doesn't sign for the account anymore
+	if _, err = s.BuildTx(transferTxInfo, 0); err == nil {
+		t.Fatal("transfer signed by the old key accepted")
+	}
+	var partialSigs []*legendTxTypes.PartialSignature
+	for _, signerIndex := range []int64{2, 1} {
+		partialSig, err := legendTxTypes.SignPartialSignature(signerSks[signerIndex], signerIndex, transferTxInfo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		partialSigs = append(partialSigs, partialSig)
+	}
+	// a signer can't count twice
+	duplicateTxInfo := &legendTxTypes.MultiSigTxInfo{
+		TxInfo:        transferTxInfo,
+		SignerPubKeys: signerPubKeys,
+		Sigs:          []*legendTxTypes.PartialSignature{partialSigs[0], partialSigs[0]},
+	}
+	if _, err = s.BuildTx(duplicateTxInfo, 0); err == nil {
+		t.Fatal("transfer signed twice by a signer accepted")
+	}
+	if _, err = legendTxTypes.GatherPartialSignatures(transferTxInfo, signerPubKeys, 2, partialSigs[:1]); err == nil {
+		t.Fatal("transfer signed below the threshold gathered")
+	}
+	multiSigTxInfo, err := legendTxTypes.GatherPartialSignatures(transferTxInfo, signerPubKeys, 2, partialSigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oTx := buildTx(t, s, multiSigTxInfo)
+	if s.accountAsset(1, 0).Balance.Int64() != 100000000-100-10000-100 || s.Accounts[1].Nonce != 2 {
+		t.Fatal("account not updated by the multi-signature transfer")
+	}
+	// the circuit rejects the same signer twice too
+	oTx.MultiSigs[1] = oTx.MultiSigs[0]
+	witness, err := block.SetTxWitness(oTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slotType, err := block.GetTxSlotType(int(oTx.TxType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	circuit := block.TxConstraints{SlotType: slotType}
+	err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16, backend.WithHints(std.ComputeSLp, std.ComputeStableSwap, std.ComputePriceCumulative))
+	if err == nil {
+		t.Fatal("transfer signed twice by a signer solved")
+	}
+	// the owner sets a single key again from L1
+	buildTx(t, s, &legendTxTypes.FullChangePubKeyTxInfo{
+		TxType:          legendTxTypes.TxTypeFullChangePubKey,
+		AccountIndex:    1,
+		AccountNameHash: accountNameHash("sher.legend"),
+		PubKey:          hex.EncodeToString(sk.PublicKey.Bytes()),
+	})
+	if s.Accounts[1].SignerThreshold != 0 || new(big.Int).SetBytes(s.Accounts[1].SignerRoot).Sign() != 0 || !s.Accounts[1].AccountPk.Equal(&sk.PublicKey) {
+		t.Fatal("signer set not cleared by the full change pub key")
+	}
+}
+
+func TestMatchOrder(t *testing.T) {
+	s, err := NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerAccount(t, s, 0, "treasury.legend")
+	buyerSk := registerAccount(t, s, 1, "sher.legend")
+	sellerSk := registerAccount(t, s, 2, "gavin.legend")
+	deposit := func(accountIndex int64, accountName string, assetId int64, assetAmount int64) {
+		buildTx(t, s, &legendTxTypes.DepositTxInfo{
+			TxType:          legendTxTypes.TxTypeDeposit,
+			AccountIndex:    accountIndex,
+			AccountNameHash: accountNameHash(accountName),
+			AssetId:         assetId,
+			AssetAmount:     big.NewInt(assetAmount),
+		})
+	}
+	deposit(1, "sher.legend", 0, 1000)
+	deposit(1, "sher.legend", 2, 10000)
+	deposit(2, "gavin.legend", 1, 1000)
+	// buy 1000 of asset 1 for at most 2000 of asset 2, sell them for at least 1500
+	buyOrder, err := legendTxTypes.ConstructOrderTxInfo(buyerSk, `{"type":0,"order_id":100,"account_index":1,`+
+		`"asset_a_id":1,"asset_b_id":2,"asset_a_amount":"1000","asset_b_amount":"2000","expired_at":1654656781000}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sellOrder, err := legendTxTypes.ConstructOrderTxInfo(sellerSk, `{"type":1,"order_id":101,"account_index":2,`+
+		`"asset_a_id":1,"asset_b_id":2,"asset_a_amount":"1000","asset_b_amount":"1500","expired_at":1654656781000}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matchOrder := func(assetAFillAmount, assetBFillAmount int64, nonce int64) *legendTxTypes.MatchOrderTxInfo {
+		buyOrderBytes, err := json.Marshal(buyOrder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sellOrderBytes, err := json.Marshal(sellOrder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		segment, err := json.Marshal(&legendTxTypes.MatchOrderSegmentFormat{
+			AccountIndex:      1,
+			BuyOrder:          string(buyOrderBytes),
+			SellOrder:         string(sellOrderBytes),
+			AssetAFillAmount:  fmt.Sprint(assetAFillAmount),
+			AssetBFillAmount:  fmt.Sprint(assetBFillAmount),
+			GasAccountIndex:   0,
+			GasFeeAssetId:     0,
+			GasFeeAssetAmount: "10",
+			Nonce:             nonce,
+			ExpiredAt:         1654656781000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		txInfo, err := legendTxTypes.ConstructMatchOrderTxInfo(buyerSk, string(segment))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return txInfo
+	}
+	// below the price of the sell order
+	if _, err = s.BuildTx(matchOrder(400, 500, 0), 0); err == nil {
+		t.Fatal("match below the sell price accepted")
+	}
+	// above the price of the buy order
+	if _, err = s.BuildTx(matchOrder(400, 900, 0), 0); err == nil {
+		t.Fatal("match above the buy price accepted")
+	}
+	// both orders are filled in two matches
+	buildTx(t, s, matchOrder(400, 700, 0))
+	oTx := buildTx(t, s, matchOrder(600, 1050, 1))
+	if block.IsOnChainOp(oTx.TxType) {
+		t.Fatal("match order is an on-chain op")
+	}
+	if s.accountAsset(1, 1).Balance.Int64() != 1000 || s.accountAsset(1, 2).Balance.Int64() != 10000-1750 ||
+		s.accountAsset(2, 1).Balance.Int64() != 0 || s.accountAsset(2, 2).Balance.Int64() != 1750 ||
+		s.accountAsset(1, 0).Balance.Int64() != 1000-20 {
+		t.Fatal("balances not updated by the match orders")
+	}
+	for accountIndex, orderId := range map[int64]int64{1: 100, 2: 101} {
+		filledAmount := new(big.Int).Rsh(s.accountAsset(accountIndex, orderId).OfferCanceledOrFinalized, std.OfferSizePerAsset)
+		if filledAmount.Int64() != 1000 {
+			t.Fatal("invalid filled amount:", filledAmount)
+		}
+	}
+	// the orders can't be overfilled
+	if _, err = s.BuildTx(matchOrder(1, 2, 2), 0); err == nil {
+		t.Fatal("overfilled order accepted")
+	}
+}
+
+func TestCancelOrder(t *testing.T) {
+	s, err := NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerAccount(t, s, 0, "treasury.legend")
+	buyerSk := registerAccount(t, s, 1, "sher.legend")
+	sellerSk := registerAccount(t, s, 2, "gavin.legend")
+	deposit := func(accountIndex int64, accountName string, assetId int64, assetAmount int64) {
+		buildTx(t, s, &legendTxTypes.DepositTxInfo{
+			TxType:          legendTxTypes.TxTypeDeposit,
+			AccountIndex:    accountIndex,
+			AccountNameHash: accountNameHash(accountName),
+			AssetId:         assetId,
+			AssetAmount:     big.NewInt(assetAmount),
+		})
+	}
+	deposit(1, "sher.legend", 0, 1000)
+	deposit(1, "sher.legend", 2, 10000)
+	deposit(2, "gavin.legend", 0, 1000)
+	deposit(2, "gavin.legend", 1, 1000)
+	// the order slots are above the fungible asset ids
+	buyOrder, err := legendTxTypes.ConstructOrderTxInfo(buyerSk, `{"type":0,"order_id":32768,"account_index":1,`+
+		`"asset_a_id":1,"asset_b_id":2,"asset_a_amount":"1000","asset_b_amount":"2000","expired_at":1654656781000}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sellOrder, err := legendTxTypes.ConstructOrderTxInfo(sellerSk, `{"type":1,"order_id":32769,"account_index":2,`+
+		`"asset_a_id":1,"asset_b_id":2,"asset_a_amount":"1000","asset_b_amount":"1500","expired_at":1654656781000}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matchOrder := func(nonce int64) *legendTxTypes.MatchOrderTxInfo {
+		buyOrderBytes, err := json.Marshal(buyOrder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sellOrderBytes, err := json.Marshal(sellOrder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		segment, err := json.Marshal(&legendTxTypes.MatchOrderSegmentFormat{
+			AccountIndex:      1,
+			BuyOrder:          string(buyOrderBytes),
+			SellOrder:         string(sellOrderBytes),
+			AssetAFillAmount:  "400",
+			AssetBFillAmount:  "700",
+			GasAccountIndex:   0,
+			GasFeeAssetId:     0,
+			GasFeeAssetAmount: "10",
+			Nonce:             nonce,
+			ExpiredAt:         1654656781000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		txInfo, err := legendTxTypes.ConstructMatchOrderTxInfo(buyerSk, string(segment))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return txInfo
+	}
+	buildTx(t, s, matchOrder(0))
+	// the seller cancels the rest of its order
+	cancelOrder, err := legendTxTypes.ConstructCancelOrderTxInfo(sellerSk, `{"account_index":2,"order_id":32769,`+
+		`"gas_account_index":0,"gas_fee_asset_id":0,"gas_fee_asset_amount":"10","expired_at":1654656781000,"nonce":0}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = cancelOrder.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	oTx := buildTx(t, s, cancelOrder)
+	if block.IsOnChainOp(oTx.TxType) {
+		t.Fatal("cancel order is an on-chain op")
+	}
+	filledAmount := new(big.Int).Rsh(s.accountAsset(2, 32769).OfferCanceledOrFinalized, std.OfferSizePerAsset)
+	if filledAmount.Cmp(std.CanceledOrderFilledAmount) != 0 || s.accountAsset(2, 0).Balance.Int64() != 1000-10 {
+		t.Fatal("order slot not filled by the cancel order:", filledAmount)
+	}
+	// the canceled order can't be matched with the rest of the buy order anymore
+	if _, err = s.BuildTx(matchOrder(1), 0); err == nil {
+		t.Fatal("canceled order matched")
+	}
+	// the order slot can't be the leaf of the gas fee asset
+	cancelOrder, err = legendTxTypes.ConstructCancelOrderTxInfo(buyerSk, `{"account_index":1,"order_id":0,`+
+		`"gas_account_index":0,"gas_fee_asset_id":0,"gas_fee_asset_amount":"10","expired_at":1654656781000,"nonce":1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.BuildTx(cancelOrder, 0); err == nil {
+		t.Fatal("order slot of the gas fee asset accepted")
+	}
+}
+
+func TestMultiSigOrders(t *testing.T) {
+	s, err := NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerAccount(t, s, 0, "treasury.legend")
+	buyerSk := registerAccount(t, s, 1, "sher.legend")
+	sellerSk := registerAccount(t, s, 2, "gavin.legend")
+	deposit := func(accountIndex int64, accountName string, assetId int64, assetAmount int64) {
+		buildTx(t, s, &legendTxTypes.DepositTxInfo{
+			TxType:          legendTxTypes.TxTypeDeposit,
+			AccountIndex:    accountIndex,
+			AccountNameHash: accountNameHash(accountName),
+			AssetId:         assetId,
+			AssetAmount:     big.NewInt(assetAmount),
+		})
+	}
+	deposit(1, "sher.legend", 0, 1000)
+	deposit(1, "sher.legend", 2, 10000)
+	deposit(2, "gavin.legend", 0, 1000)
+	deposit(2, "gavin.legend", 1, 1000)
+	buyOrder, err := legendTxTypes.ConstructOrderTxInfo(buyerSk, `{"type":0,"order_id":100,"account_index":1,`+
+		`"asset_a_id":1,"asset_b_id":2,"asset_a_amount":"1000","asset_b_amount":"2000","expired_at":1654656781000}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the sell order is signed before the seller gets a 2-of-3 signer set
+	sellOrder, err := legendTxTypes.ConstructOrderTxInfo(sellerSk, `{"type":1,"order_id":101,"account_index":2,`+
+		`"asset_a_id":1,"asset_b_id":2,"asset_a_amount":"1000","asset_b_amount":"1500","expired_at":1654656781000}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		signerSks     []*curve.PrivateKey
+		signerPubKeys []string
+	)
+	for i := 0; i < 3; i++ {
+		signerSk, err := curve.GenerateEddsaPrivateKey(fmt.Sprintf("signer%d.legend", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		signerSks = append(signerSks, signerSk)
+		signerPubKeys = append(signerPubKeys, hex.EncodeToString(signerSk.PublicKey.Bytes()))
+	}
+	signerPubKeysBytes, err := json.Marshal(signerPubKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changePubKeyTxInfo, err := legendTxTypes.ConstructChangePubKeyTxInfo(sellerSk, fmt.Sprintf(
+		`{"account_index":2,"pub_key":"","gas_account_index":0,"gas_fee_asset_id":0,`+
+			`"gas_fee_asset_amount":"100","expired_at":1654656781000,"nonce":0,`+
+			`"signer_pub_keys":%s,"signer_threshold":2}`, signerPubKeysBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buildTx(t, s, changePubKeyTxInfo)
+	matchOrder := func(sk *curve.PrivateKey, accountIndex int64, nonce int64) *legendTxTypes.MatchOrderTxInfo {
+		buyOrderBytes, err := json.Marshal(buyOrder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sellOrderBytes, err := json.Marshal(sellOrder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		segment, err := json.Marshal(&legendTxTypes.MatchOrderSegmentFormat{
+			AccountIndex:      accountIndex,
+			BuyOrder:          string(buyOrderBytes),
+			SellOrder:         string(sellOrderBytes),
+			AssetAFillAmount:  "400",
+			AssetBFillAmount:  "700",
+			GasAccountIndex:   0,
+			GasFeeAssetId:     0,
+			GasFeeAssetAmount: "10",
+			Nonce:             nonce,
+			ExpiredAt:         1654656781000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		txInfo, err := legendTxTypes.ConstructMatchOrderTxInfo(sk, string(segment))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return txInfo
+	}
+	// a multi-signature account has no key to sign the order of a counterparty
+	txInfo := matchOrder(buyerSk, 1, 0)
+	if _, err = s.BuildTx(txInfo, block.TxConstraintsBlockCreatedAt); err == nil {
+		t.Fatal("order of a multi-signature counterparty accepted")
+	}
+	oTx, err := SetTxInfo(txInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.fillTx(oTx, block.TxConstraintsBlockCreatedAt)
+	s.rollback()
+	if err != nil {
+		t.Fatal(err)
+	}
+	witness, err := block.SetTxWitness(oTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slotType, err := block.GetTxSlotType(int(oTx.TxType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	circuit := block.TxConstraints{SlotType: slotType}
+	err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16, backend.WithHints(std.ComputeSLp, std.ComputeStableSwap, std.ComputePriceCumulative))
+	if err == nil {
+		t.Fatal("order of a multi-signature counterparty solved")
+	}
+	// its own orders are signed by the signer set with the match
+	txInfo = matchOrder(sellerSk, 2, 1)
+	var partialSigs []*legendTxTypes.PartialSignature
+	for _, signerIndex := range []int64{0, 1} {
+		partialSig, err := legendTxTypes.SignPartialSignature(signerSks[signerIndex], signerIndex, txInfo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		partialSigs = append(partialSigs, partialSig)
+	}
+	multiSigTxInfo, err := legendTxTypes.GatherPartialSignatures(txInfo, signerPubKeys, 2, partialSigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buildTx(t, s, multiSigTxInfo)
+	if s.accountAsset(1, 1).Balance.Int64() != 400 || s.accountAsset(2, 2).Balance.Int64() != 700 {
+		t.Fatal("balances not updated by the match of the multi-signature account")
+	}
+}
+
+func TestRouteSwap(t *testing.T) {
+	s, err := NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerAccount(t, s, 0, "treasury.legend")
+	sk := registerAccount(t, s, 1, "sher.legend")
+	for assetId, assetAmount := range map[int64]int64{0: 1000, 1: 10000} {
+		buildTx(t, s, &legendTxTypes.DepositTxInfo{
+			TxType:          legendTxTypes.TxTypeDeposit,
+			AccountIndex:    1,
+			AccountNameHash: accountNameHash("sher.legend"),
+			AssetId:         assetId,
+			AssetAmount:     big.NewInt(assetAmount),
+		})
+	}
+	// 1 -> 2 -> 3 -> 4, the second pair is traded from its asset b
+	pairs := []*legendTxTypes.RouteSwapPairInfo{
+		{PairIndex: 0, AssetAId: 1, AssetA: big.NewInt(100000), AssetBId: 2, AssetB: big.NewInt(100000), FeeRate: 30},
+		{PairIndex: 1, AssetAId: 3, AssetA: big.NewInt(200000), AssetBId: 2, AssetB: big.NewInt(100000), FeeRate: 30},
+		{PairIndex: 2, AssetAId: 3, AssetA: big.NewInt(1000000), AssetBId: 4, AssetB: big.NewInt(500000), FeeRate: 50},
+	}
+	for _, pair := range pairs {
+		err = s.SetLiquidity(&std.Liquidity{
+			PairIndex: pair.PairIndex, AssetAId: pair.AssetAId, AssetA: pair.AssetA, AssetBId: pair.AssetBId,
+			AssetB: pair.AssetB, LpAmount: big.NewInt(0), KLast: big.NewInt(0), FeeRate: pair.FeeRate,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	routeSwap := func(pairs []*legendTxTypes.RouteSwapPairInfo, assetBMinAmount int64, nonce int64) *legendTxTypes.RouteSwapTxInfo {
+		quote, err := legendTxTypes.QuoteRouteSwap(1, big.NewInt(1000), pairs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		segment := &legendTxTypes.RouteSwapSegmentFormat{
+			FromAccountIndex:  1,
+			PairIndexes:       quote.PairIndexes,
+			AssetAId:          1,
+			AssetAAmount:      "1000",
+			AssetBId:          quote.AssetBId,
+			AssetBMinAmount:   fmt.Sprint(assetBMinAmount),
+			AssetBAmountDelta: quote.AssetBAmountDelta.String(),
+			GasAccountIndex:   0,
+			GasFeeAssetId:     0,
+			GasFeeAssetAmount: "10",
+			ExpiredAt:         1654656781000,
+			Nonce:             nonce,
+		}
+		for _, hopAmount := range quote.HopAmounts {
+			segment.HopAmounts = append(segment.HopAmounts, hopAmount.String())
+		}
+		segmentBytes, err := json.Marshal(segment)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txInfo, err := legendTxTypes.ConstructRouteSwapTxInfo(sk, string(segmentBytes))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = txInfo.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		return txInfo
+	}
+	// the amount out is below the min amount
+	if _, err = s.BuildTx(routeSwap(pairs, 10000, 0), 0); err == nil {
+		t.Fatal("route swap below the min amount accepted")
+	}
+	// a hop amount above the quote breaks the amm check of its pair
+	txInfo := routeSwap(pairs, 1, 0)
+	txInfo.HopAmounts[0] = new(big.Int).Add(txInfo.HopAmounts[0], big.NewInt(1))
+	if _, err = s.BuildTx(txInfo, 0); err == nil {
+		t.Fatal("route swap above the quote accepted")
+	}
+	txInfo = routeSwap(pairs, 400, 0)
+	buildTx(t, s, txInfo)
+	reserves := map[int64][2]*big.Int{
+		0: {big.NewInt(100000 + 1000), new(big.Int).Sub(big.NewInt(100000), txInfo.HopAmounts[0])},
+		1: {new(big.Int).Sub(big.NewInt(200000), txInfo.HopAmounts[1]), new(big.Int).Add(big.NewInt(100000), txInfo.HopAmounts[0])},
+		2: {new(big.Int).Add(big.NewInt(1000000), txInfo.HopAmounts[1]), new(big.Int).Sub(big.NewInt(500000), txInfo.AssetBAmountDelta)},
+	}
+	for pairIndex, reserve := range reserves {
+		liquidity := s.liquidity(pairIndex)
+		if liquidity.AssetA.Cmp(reserve[0]) != 0 || liquidity.AssetB.Cmp(reserve[1]) != 0 {
+			t.Fatalf("reserves of pair %d not updated by the route swap", pairIndex)
+		}
+	}
+	if s.accountAsset(1, 1).Balance.Int64() != 10000-1000 || s.accountAsset(1, 2).Balance.Int64() != 0 ||
+		s.accountAsset(1, 3).Balance.Int64() != 0 || s.accountAsset(1, 4).Balance.Cmp(txInfo.AssetBAmountDelta) != 0 {
+		t.Fatal("balances not updated by the route swap")
+	}
+	// a route through 2 pairs, quoted on the updated reserves
+	for i, pair := range pairs[:2] {
+		liquidity := s.liquidity(pair.PairIndex)
+		pairs[i].AssetA, pairs[i].AssetB = liquidity.AssetA, liquidity.AssetB
+	}
+	txInfo = routeSwap(pairs[:2], 1, 1)
+	buildTx(t, s, txInfo)
+	if s.accountAsset(1, 3).Balance.Cmp(txInfo.AssetBAmountDelta) != 0 {
+		t.Fatal("balances not updated by the route swap")
+	}
+}
+
+func TestStableSwap(t *testing.T) {
+	s, err := NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerAccount(t, s, 0, "treasury.legend")
+	sk := registerAccount(t, s, 1, "sher.legend")
+	for assetId, assetAmount := range map[int64]int64{0: 1000, 1: 100000} {
+		buildTx(t, s, &legendTxTypes.DepositTxInfo{
+			TxType:          legendTxTypes.TxTypeDeposit,
+			AccountIndex:    1,
+			AccountNameHash: accountNameHash("sher.legend"),
+			AssetId:         assetId,
+			AssetAmount:     big.NewInt(assetAmount),
+		})
+	}
+	createPair := &legendTxTypes.CreatePairTxInfo{
+		TxType:               legendTxTypes.TxTypeCreatePair,
+		PairIndex:            0,
+		AssetAId:             1,
+		AssetBId:             2,
+		FeeRate:              30,
+		TreasuryAccountIndex: 0,
+		TreasuryRate:         10,
+		PairType:             legendTxTypes.PairTypeStableSwap,
+		Amplification:        0,
+	}
+	// a StableSwap pair needs an amplification
+	if _, err = s.BuildTx(createPair, 0); err == nil {
+		t.Fatal("stable pair without amplification accepted")
+	}
+	createPair.Amplification = 100
+	buildTx(t, s, createPair)
+	liquidity := s.liquidity(0)
+	if liquidity.PairType != std.PairTypeStableSwap || liquidity.Amplification != 100 {
+		t.Fatal("pair type not set by create pair")
+	}
+	liquidity.AssetA, liquidity.AssetB = big.NewInt(1000000), big.NewInt(1000000)
+	if err = s.SetLiquidity(liquidity); err != nil {
+		t.Fatal(err)
+	}
+	amountOut, err := legendTxTypes.ComputeStableSwapAmountOut(big.NewInt(1000000), big.NewInt(1000000), big.NewInt(10000), 30, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	swap := func(assetBAmountDelta *big.Int) *legendTxTypes.SwapTxInfo {
+		segmentBytes, err := json.Marshal(&legendTxTypes.SwapSegmentFormat{
+			FromAccountIndex:  1,
+			PairIndex:         0,
+			AssetAId:          1,
+			AssetAAmount:      "10000",
+			AssetBId:          2,
+			AssetBMinAmount:   "1",
+			AssetBAmountDelta: assetBAmountDelta.String(),
+			GasAccountIndex:   0,
+			GasFeeAssetId:     0,
+			GasFeeAssetAmount: "10",
+			ExpiredAt:         1654656781000,
+			Nonce:             0,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		txInfo, err := legendTxTypes.ConstructSwapTxInfo(sk, string(segmentBytes))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return txInfo
+	}
+	// one more out than the quote is below the curve
+	if _, err = s.BuildTx(swap(new(big.Int).Add(amountOut, big.NewInt(1))), 0); err == nil {
+		t.Fatal("swap above the quote accepted")
+	}
+	buildTx(t, s, swap(amountOut))
+	liquidity = s.liquidity(0)
+	if liquidity.AssetA.Int64() != 1000000+10000 || liquidity.AssetB.Cmp(new(big.Int).Sub(big.NewInt(1000000), amountOut)) != 0 {
+		t.Fatal("reserves not updated by the swap")
+	}
+	if s.accountAsset(1, 2).Balance.Cmp(amountOut) != 0 {
+		t.Fatal("balances not updated by the swap")
+	}
+}
+
+func TestStableLiquidity(t *testing.T) {
+	s, err := NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerAccount(t, s, 0, "treasury.legend")
+	sk := registerAccount(t, s, 1, "sher.legend")
+	for assetId, assetAmount := range map[int64]int64{0: 1000, 1: 1000000000, 2: 1000000000} {
+		buildTx(t, s, &legendTxTypes.DepositTxInfo{
+			TxType:          legendTxTypes.TxTypeDeposit,
+			AccountIndex:    1,
+			AccountNameHash: accountNameHash("sher.legend"),
+			AssetId:         assetId,
+			AssetAmount:     big.NewInt(assetAmount),
+		})
+	}
+	buildTx(t, s, &legendTxTypes.CreatePairTxInfo{
+		TxType:               legendTxTypes.TxTypeCreatePair,
+		PairIndex:            5,
+		AssetAId:             1,
+		AssetBId:             2,
+		FeeRate:              30,
+		TreasuryAccountIndex: 0,
+		TreasuryRate:         10,
+		PairType:             legendTxTypes.PairTypeStableSwap,
+		Amplification:        100,
+	})
+	// StableSwap pairs don't mint treasury lp
+	addLiquidity := func(assetAAmount, assetBAmount, lpAmount, treasuryAmount int64, nonce int64) *legendTxTypes.AddLiquidityTxInfo {
+		txInfo, err := legendTxTypes.ConstructAddLiquidityTxInfo(sk, fmt.Sprintf(
+			`{"from_account_index":1,"pair_index":5,"asset_a_id":1,"asset_a_amount":"%d","asset_b_id":2,`+
+				`"asset_b_amount":"%d","gas_account_index":0,"gas_fee_asset_id":0,"gas_fee_asset_amount":"10",`+
+				`"expired_at":1654656781000,"nonce":%d}`, assetAAmount, assetBAmount, nonce))
+		if err != nil {
+			t.Fatal(err)
+		}
+		liquidity := s.liquidity(5)
+		txInfo.LpAmount = big.NewInt(lpAmount)
+		txInfo.TreasuryAmount = big.NewInt(treasuryAmount)
+		txInfo.KLast = new(big.Int).Mul(
+			new(big.Int).Add(liquidity.AssetA, big.NewInt(assetAAmount)),
+			new(big.Int).Add(liquidity.AssetB, big.NewInt(assetBAmount)),
+		)
+		return txInfo
+	}
+	buildTx(t, s, addLiquidity(1000000, 1000000, 1000000, 0, 0))
+	amountOut, err := legendTxTypes.ComputeStableSwapAmountOut(big.NewInt(1000000), big.NewInt(1000000), big.NewInt(10000), 30, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txInfo, err := legendTxTypes.ConstructSwapTxInfo(sk, fmt.Sprintf(
+		`{"from_account_index":1,"pair_index":5,"asset_a_id":1,"asset_a_amount":"10000","asset_b_id":2,`+
+			`"asset_b_min_amount":"1","asset_b_amount_delta":"%s","gas_account_index":0,"gas_fee_asset_id":0,`+
+			`"gas_fee_asset_amount":"10","expired_at":1654656781000,"nonce":1}`, amountOut.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buildTx(t, s, txInfo)
+	// the swap lowers x * y below k last, which only constant product pairs keep
+	liquidity := s.liquidity(5)
+	if new(big.Int).Mul(liquidity.AssetA, liquidity.AssetB).Cmp(liquidity.KLast) >= 0 {
+		t.Fatal("k not lowered by the stable swap")
+	}
+	assetB := liquidity.AssetB.Int64()
+	if _, err = s.BuildTx(addLiquidity(10100, assetB/100, 10000, 1, 2), 0); err == nil {
+		t.Fatal("treasury lp of a stable pair accepted")
+	}
+	buildTx(t, s, addLiquidity(10100, assetB/100, 10000, 0, 2))
+	liquidity = s.liquidity(5)
+	if liquidity.LpAmount.Int64() != 1010000 || s.accountAsset(1, 5).LpAmount.Int64() != 1010000 || s.accountAsset(0, 5).LpAmount.Sign() != 0 {
+		t.Fatal("pair not updated by the add liquidity")
+	}
+	// the share of 5000 lp
+	assetAAmountDelta := liquidity.AssetA.Int64() * 5000 / 1010000
+	assetBAmountDelta := liquidity.AssetB.Int64() * 5000 / 1010000
+	removeLiquidity, err := legendTxTypes.ConstructRemoveLiquidityTxInfo(sk, fmt.Sprintf(
+		`{"from_account_index":1,"pair_index":5,"asset_a_id":1,"asset_a_min_amount":"%d","asset_b_id":2,`+
+			`"asset_b_min_amount":"%d","lp_amount":"5000","asset_a_amount_delta":"%d","asset_b_amount_delta":"%d",`+
+			`"gas_account_index":0,"gas_fee_asset_id":0,"gas_fee_asset_amount":"10","expired_at":1654656781000,"nonce":3}`,
+		assetAAmountDelta, assetBAmountDelta, assetAAmountDelta, assetBAmountDelta))
+	if err != nil {
+		t.Fatal(err)
+	}
+	removeLiquidity.TreasuryAmount = big.NewInt(0)
+	removeLiquidity.KLast = new(big.Int).Mul(
+		new(big.Int).Sub(liquidity.AssetA, big.NewInt(assetAAmountDelta)),
+		new(big.Int).Sub(liquidity.AssetB, big.NewInt(assetBAmountDelta)),
+	)
+	buildTx(t, s, removeLiquidity)
+	if s.accountAsset(1, 5).LpAmount.Int64() != 1010000-5000 ||
+		s.accountAsset(1, 1).Balance.Int64() != 1000000000-1000000-10000-10100+assetAAmountDelta {
+		t.Fatal("balances not updated by the remove liquidity")
+	}
+	// the reserves can't be added above 2^76, where the pair couldn't be swapped
+	buildTx(t, s, &legendTxTypes.CreatePairTxInfo{
+		TxType:               legendTxTypes.TxTypeCreatePair,
+		PairIndex:            6,
+		AssetAId:             1,
+		AssetBId:             2,
+		FeeRate:              30,
+		TreasuryAccountIndex: 0,
+		TreasuryRate:         10,
+		PairType:             legendTxTypes.PairTypeStableSwap,
+		Amplification:        100,
+	})
+	reserveAmount, _ := new(big.Int).SetString("80000000000000000000000", 10)
+	for _, assetId := range []int64{1, 2} {
+		buildTx(t, s, &legendTxTypes.DepositTxInfo{
+			TxType:          legendTxTypes.TxTypeDeposit,
+			AccountIndex:    1,
+			AccountNameHash: accountNameHash("sher.legend"),
+			AssetId:         assetId,
+			AssetAmount:     reserveAmount,
+		})
+	}
+	addReserves, err := legendTxTypes.ConstructAddLiquidityTxInfo(sk, fmt.Sprintf(
+		`{"from_account_index":1,"pair_index":6,"asset_a_id":1,"asset_a_amount":"%s","asset_b_id":2,`+
+			`"asset_b_amount":"%s","gas_account_index":0,"gas_fee_asset_id":0,"gas_fee_asset_amount":"10",`+
+			`"expired_at":1654656781000,"nonce":4}`, reserveAmount, reserveAmount))
+	if err != nil {
+		t.Fatal(err)
+	}
+	addReserves.LpAmount = reserveAmount
+	addReserves.TreasuryAmount = big.NewInt(0)
+	// the product doesn't fit in a packed amount, stable pairs don't use k last
+	addReserves.KLast = big.NewInt(0)
+	if _, err = s.BuildTx(addReserves, 0); err == nil {
+		t.Fatal("stable pair reserve above 2^76 accepted")
+	}
+}
+
+func TestExactOutputSwap(t *testing.T) {
+	s, err := NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerAccount(t, s, 0, "treasury.legend")
+	sk := registerAccount(t, s, 1, "sher.legend")
+	for assetId, assetAmount := range map[int64]int64{0: 1000, 1: 10000} {
+		buildTx(t, s, &legendTxTypes.DepositTxInfo{
+			TxType:          legendTxTypes.TxTypeDeposit,
+			AccountIndex:    1,
+			AccountNameHash: accountNameHash("sher.legend"),
+			AssetId:         assetId,
+			AssetAmount:     big.NewInt(assetAmount),
+		})
+	}
+	err = s.SetLiquidity(&std.Liquidity{
+		PairIndex: 0, AssetAId: 1, AssetA: big.NewInt(100000), AssetBId: 2, AssetB: big.NewInt(100000),
+		LpAmount: big.NewInt(0), KLast: big.NewInt(0), FeeRate: 30,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	amountIn, err := legendTxTypes.ComputeSwapAmountIn(big.NewInt(100000), big.NewInt(100000), big.NewInt(987), 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	swap := func(assetAAmount, assetAMaxAmount int64) *legendTxTypes.SwapTxInfo {
+		segmentBytes, err := json.Marshal(&legendTxTypes.SwapSegmentFormat{
+			FromAccountIndex:  1,
+			PairIndex:         0,
+			AssetAId:          1,
+			AssetAAmount:      fmt.Sprint(assetAAmount),
+			AssetBId:          2,
+			AssetBAmountDelta: "987",
+			GasAccountIndex:   0,
+			GasFeeAssetId:     0,
+			GasFeeAssetAmount: "10",
+			ExpiredAt:         1654656781000,
+			Nonce:             0,
+			SwapMode:          legendTxTypes.SwapModeExactOutput,
+			AssetAMaxAmount:   fmt.Sprint(assetAMaxAmount),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		txInfo, err := legendTxTypes.ConstructSwapTxInfo(sk, string(segmentBytes))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return txInfo
+	}
+	// the amount in is above the max amount
+	if _, err = s.BuildTx(swap(amountIn.Int64(), amountIn.Int64()-1), 0); err == nil {
+		t.Fatal("swap above the max amount in accepted")
+	}
+	// one less in breaks the amm check
+	if _, err = s.BuildTx(swap(amountIn.Int64()-1, 1100), 0); err == nil {
+		t.Fatal("swap below the quote accepted")
+	}
+	buildTx(t, s, swap(amountIn.Int64(), 1100))
+	if s.accountAsset(1, 1).Balance.Cmp(new(big.Int).Sub(big.NewInt(10000), amountIn)) != 0 ||
+		s.accountAsset(1, 2).Balance.Int64() != 987 {
+		t.Fatal("balances not updated by the swap")
+	}
+}
+
+func TestLiquidityPrices(t *testing.T) {
+	s, err := NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerAccount(t, s, 0, "treasury.legend")
+	sk := registerAccount(t, s, 1, "sher.legend")
+	for assetId, assetAmount := range map[int64]int64{0: 1000, 1: 10000} {
+		buildTx(t, s, &legendTxTypes.DepositTxInfo{
+			TxType:          legendTxTypes.TxTypeDeposit,
+			AccountIndex:    1,
+			AccountNameHash: accountNameHash("sher.legend"),
+			AssetId:         assetId,
+			AssetAmount:     big.NewInt(assetAmount),
+		})
+	}
+	createdAt := int64(block.TxConstraintsBlockCreatedAt)
+	err = s.SetLiquidity(&std.Liquidity{
+		PairIndex: 0, AssetAId: 1, AssetA: big.NewInt(100000), AssetBId: 2, AssetB: big.NewInt(300000),
+		LpAmount: big.NewInt(0), KLast: big.NewInt(0), FeeRate: 30,
+		PriceACumulativeLast: big.NewInt(0), PriceBCumulativeLast: big.NewInt(0), BlockTimestampLast: createdAt - 60000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	swap := func(nonce int64) *legendTxTypes.SwapTxInfo {
+		// the swap circuit adds the amount in of a pair ordered like the tx to reserve b
+		liquidity := s.liquidity(0)
+		amountOut, err := legendTxTypes.ComputeSwapAmountOut(liquidity.AssetB, liquidity.AssetA, big.NewInt(1000), 30)
+		if err != nil {
+			t.Fatal(err)
+		}
+		segmentBytes, err := json.Marshal(&legendTxTypes.SwapSegmentFormat{
+			FromAccountIndex:  1,
+			PairIndex:         0,
+			AssetAId:          1,
+			AssetAAmount:      "1000",
+			AssetBId:          2,
+			AssetBMinAmount:   "1",
+			AssetBAmountDelta: amountOut.String(),
+			GasAccountIndex:   0,
+			GasFeeAssetId:     0,
+			GasFeeAssetAmount: "10",
+			ExpiredAt:         1654656781000,
+			Nonce:             nonce,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		txInfo, err := legendTxTypes.ConstructSwapTxInfo(sk, string(segmentBytes))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return txInfo
+	}
+	// the prices of the reserves before the swap are accumulated up to the block
+	liquidityBefore := s.liquidity(0)
+	priceACumulative, priceBCumulative, err := std.ComputeLiquidityPriceCumulatives(liquidityBefore, createdAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buildTx(t, s, swap(0))
+	snapshot := s.liquidity(0)
+	if snapshot.PriceACumulativeLast.Cmp(priceACumulative) != 0 || snapshot.PriceBCumulativeLast.Cmp(priceBCumulative) != 0 ||
+		snapshot.BlockTimestampLast != createdAt {
+		t.Fatal("price accumulators not updated by the swap")
+	}
+	// the twap over the next minute is the price after the swap
+	if _, err = s.BuildTx(swap(1), createdAt+60000); err != nil {
+		t.Fatal(err)
+	}
+	priceA, priceB, err := std.ComputeTwap(snapshot, createdAt, s.liquidity(0), createdAt+60000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priceA.Cmp(std.ComputeLiquidityPrice(snapshot.AssetB, snapshot.AssetA)) != 0 ||
+		priceB.Cmp(std.ComputeLiquidityPrice(snapshot.AssetA, snapshot.AssetB)) != 0 {
+		t.Fatal("invalid twap")
+	}
+	// blocks can't be created before the last update of the pair
+	if _, err = s.BuildTx(swap(2), createdAt); err == nil {
+		t.Fatal("swap before the last update of the pair accepted")
+	}
+}
+
+func TestLiquidity(t *testing.T) {
+	s, err := NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerAccount(t, s, 0, "treasury.legend")
+	sk := registerAccount(t, s, 1, "sher.legend")
+	for assetId, assetAmount := range map[int64]int64{0: 1000, 1: 1000000, 2: 1000000} {
+		buildTx(t, s, &legendTxTypes.DepositTxInfo{
+			TxType:          legendTxTypes.TxTypeDeposit,
+			AccountIndex:    1,
+			AccountNameHash: accountNameHash("sher.legend"),
+			AssetId:         assetId,
+			AssetAmount:     big.NewInt(assetAmount),
+		})
+	}
+	// the lp of the pair is held as asset 5, apart from the gas fee asset
+	buildTx(t, s, &legendTxTypes.CreatePairTxInfo{
+		TxType:               legendTxTypes.TxTypeCreatePair,
+		PairIndex:            5,
+		AssetAId:             1,
+		AssetBId:             2,
+		FeeRate:              30,
+		TreasuryAccountIndex: 0,
+		TreasuryRate:         10,
+	})
+	treasuryAmount := func() *big.Int {
+		liquidity := s.liquidity(5)
+		sLp, err := std.ComputeSLpAmount(liquidity.AssetA, liquidity.AssetB, liquidity.KLast,
+			big.NewInt(liquidity.FeeRate), big.NewInt(liquidity.TreasuryRate))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sLp
+	}
+	addLiquidity := func(assetAAmount, assetBAmount, lpAmount int64, nonce int64) *legendTxTypes.AddLiquidityTxInfo {
+		txInfo, err := legendTxTypes.ConstructAddLiquidityTxInfo(sk, fmt.Sprintf(
+			`{"from_account_index":1,"pair_index":5,"asset_a_id":1,"asset_a_amount":"%d","asset_b_id":2,`+
+				`"asset_b_amount":"%d","gas_account_index":0,"gas_fee_asset_id":0,"gas_fee_asset_amount":"10",`+
+				`"expired_at":1654656781000,"nonce":%d}`, assetAAmount, assetBAmount, nonce))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// set by L2 from the pair before the tx
+		liquidity := s.liquidity(5)
+		txInfo.LpAmount = big.NewInt(lpAmount)
+		txInfo.TreasuryAmount = treasuryAmount()
+		txInfo.KLast = new(big.Int).Mul(
+			new(big.Int).Add(liquidity.AssetA, big.NewInt(assetAAmount)),
+			new(big.Int).Add(liquidity.AssetB, big.NewInt(assetBAmount)),
+		)
+		return txInfo
+	}
+	// the first lp is at most the square root of the product of the amounts
+	if _, err = s.BuildTx(addLiquidity(100000, 400000, 200001, 0), 0); err == nil {
+		t.Fatal("lp amount above the amounts accepted")
+	}
+	buildTx(t, s, addLiquidity(100000, 400000, 200000, 0))
+	// then it is proportional to the reserves
+	if _, err = s.BuildTx(addLiquidity(10000, 40000, 20001, 1), 0); err == nil {
+		t.Fatal("lp amount above the share of the pair accepted")
+	}
+	buildTx(t, s, addLiquidity(10000, 40000, 20000, 1))
+	liquidity := s.liquidity(5)
+	if liquidity.AssetA.Int64() != 110000 || liquidity.AssetB.Int64() != 440000 || liquidity.LpAmount.Int64() != 220000 ||
+		s.accountAsset(1, 5).LpAmount.Int64() != 220000 {
+		t.Fatal("pair not updated by the add liquidity")
+	}
+	removeLiquidity := func(assetAAmountDelta, assetBAmountDelta int64) *legendTxTypes.RemoveLiquidityTxInfo {
+		txInfo, err := legendTxTypes.ConstructRemoveLiquidityTxInfo(sk, fmt.Sprintf(
+			`{"from_account_index":1,"pair_index":5,"asset_a_id":1,"asset_a_min_amount":"2000","asset_b_id":2,`+
+				`"asset_b_min_amount":"9000","lp_amount":"5000","asset_a_amount_delta":"%d","asset_b_amount_delta":"%d",`+
+				`"gas_account_index":0,"gas_fee_asset_id":0,"gas_fee_asset_amount":"10","expired_at":1654656781000,"nonce":2}`,
+			assetAAmountDelta, assetBAmountDelta))
+		if err != nil {
+			t.Fatal(err)
+		}
+		liquidity := s.liquidity(5)
+		txInfo.TreasuryAmount = treasuryAmount()
+		txInfo.KLast = new(big.Int).Mul(
+			new(big.Int).Sub(liquidity.AssetA, big.NewInt(assetAAmountDelta)),
+			new(big.Int).Sub(liquidity.AssetB, big.NewInt(assetBAmountDelta)),
+		)
+		return txInfo
+	}
+	// the lp balance is checked against the packed lp amount like the circuit,
+	// the share of the reserves against the lp amount
+	if _, err = s.BuildTx(removeLiquidity(2501, 10000), 0); err == nil {
+		t.Fatal("asset a above the share of the lp accepted")
+	}
+	if _, err = s.BuildTx(removeLiquidity(2500, 8999), 0); err == nil {
+		t.Fatal("asset b below the min amount accepted")
+	}
+	buildTx(t, s, removeLiquidity(2500, 10000))
+	if s.accountAsset(1, 1).Balance.Int64() != 1000000-110000+2500 || s.accountAsset(1, 2).Balance.Int64() != 1000000-440000+10000 ||
+		s.accountAsset(1, 5).LpAmount.Int64() != 220000-5000 || s.accountAsset(1, 0).Balance.Int64() != 1000-30 {
+		t.Fatal("balances not updated by the remove liquidity")
+	}
+}
+
+func TestWithdraw(t *testing.T) {
+	s, err := NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerAccount(t, s, 0, "treasury.legend")
+	sk := registerAccount(t, s, 1, "sher.legend")
+	for assetId, assetAmount := range map[int64]int64{0: 1000, 1: 10000} {
+		buildTx(t, s, &legendTxTypes.DepositTxInfo{
+			TxType:          legendTxTypes.TxTypeDeposit,
+			AccountIndex:    1,
+			AccountNameHash: accountNameHash("sher.legend"),
+			AssetId:         assetId,
+			AssetAmount:     big.NewInt(assetAmount),
+		})
+	}
+	withdraw := func(assetAmount int64) *legendTxTypes.WithdrawTxInfo {
+		txInfo, err := legendTxTypes.ConstructWithdrawTxInfo(sk, fmt.Sprintf(
+			`{"from_account_index":1,"asset_id":1,"asset_amount":"%d","gas_account_index":0,"gas_fee_asset_id":0,`+
+				`"gas_fee_asset_amount":"10","to_address":"0xd5Aa3B56a2E2139DB315CdFE3b34149c8ed09171",`+
+				`"expired_at":1654656781000,"nonce":0}`, assetAmount))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return txInfo
+	}
+	if _, err = s.BuildTx(withdraw(10001), 0); err == nil {
+		t.Fatal("withdraw above the balance accepted")
+	}
+	oTx := buildTx(t, s, withdraw(4000))
+	if !block.IsOnChainOp(oTx.TxType) || block.IsPriorityOp(oTx.TxType) {
+		t.Fatal("withdraw is not an on-chain op")
+	}
+	if s.accountAsset(1, 1).Balance.Int64() != 10000-4000 || s.accountAsset(0, 0).Balance.Int64() != 10 {
+		t.Fatal("balances not updated by the withdraw")
+	}
+	// the full exit withdraws the whole balance
+	fullExitTxInfo := &legendTxTypes.FullExitTxInfo{
+		TxType:          legendTxTypes.TxTypeFullExit,
+		AccountIndex:    1,
+		AccountNameHash: accountNameHash("sher.legend"),
+		AssetId:         1,
+		AssetAmount:     big.NewInt(5000),
+	}
+	if _, err = s.BuildTx(fullExitTxInfo, 0); err == nil {
+		t.Fatal("full exit of a part of the balance accepted")
+	}
+	fullExitTxInfo.AssetAmount = big.NewInt(6000)
+	buildTx(t, s, fullExitTxInfo)
+	if s.accountAsset(1, 1).Balance.Sign() != 0 {
+		t.Fatal("balance not updated by the full exit")
+	}
+}
+
+func TestNft(t *testing.T) {
+	s, err := NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerAccount(t, s, 0, "treasury.legend")
+	buyerSk := registerAccount(t, s, 1, "sher.legend")
+	sellerSk := registerAccount(t, s, 2, "gavin.legend")
+	creatorSk := registerAccount(t, s, 3, "carl.legend")
+	for accountIndex, accountName := range map[int64]string{1: "sher.legend", 2: "gavin.legend", 3: "carl.legend"} {
+		buildTx(t, s, &legendTxTypes.DepositTxInfo{
+			TxType:          legendTxTypes.TxTypeDeposit,
+			AccountIndex:    accountIndex,
+			AccountNameHash: accountNameHash(accountName),
+			AssetId:         0,
+			AssetAmount:     big.NewInt(100000),
+		})
+	}
+	createCollectionTxInfo, err := legendTxTypes.ConstructCreateCollectionTxInfo(creatorSk,
+		`{"account_index":3,"name":"legend","introduction":"","gas_account_index":0,"gas_fee_asset_id":0,`+
+			`"gas_fee_asset_amount":"10","expired_at":1654656781000,"nonce":0}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the collection id is the collection nonce of the account
+	createCollectionTxInfo.CollectionId = 1
+	if _, err = s.BuildTx(createCollectionTxInfo, 0); err == nil {
+		t.Fatal("collection id above the collection nonce accepted")
+	}
+	createCollectionTxInfo.CollectionId = 0
+	buildTx(t, s, createCollectionTxInfo)
+	if s.Accounts[3].CollectionNonce != 1 {
+		t.Fatal("collection nonce not updated by the create collection")
+	}
+	nftContentHash := hex.EncodeToString(accountNameHash("legend nft"))
+	mintNft := func(nonce int64) *legendTxTypes.MintNftTxInfo {
+		txInfo, err := legendTxTypes.ConstructMintNftTxInfo(creatorSk, fmt.Sprintf(
+			`{"creator_account_index":3,"to_account_index":3,"to_account_name_hash":"%x","nft_content_hash":"%s",`+
+				`"nft_collection_id":0,"creator_treasury_rate":100,"gas_account_index":0,"gas_fee_asset_id":0,`+
+				`"gas_fee_asset_amount":"10","expired_at":1654656781000,"nonce":%d}`,
+			accountNameHash("carl.legend"), nftContentHash, nonce))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// set by L2
+		txInfo.NftIndex = 0
+		return txInfo
+	}
+	buildTx(t, s, mintNft(1))
+	// an nft can't be minted twice
+	if _, err = s.BuildTx(mintNft(2), 0); err == nil {
+		t.Fatal("nft minted twice")
+	}
+	transferNftTxInfo, err := legendTxTypes.ConstructTransferNftTxInfo(creatorSk, fmt.Sprintf(
+		`{"from_account_index":3,"to_account_index":2,"to_account_name":"%x","nft_index":0,"gas_account_index":0,`+
+			`"gas_fee_asset_id":0,"gas_fee_asset_amount":"10","call_data":"","expired_at":1654656781000,"nonce":2}`,
+		accountNameHash("gavin.legend")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buildTx(t, s, transferNftTxInfo)
+	if s.nft(0).OwnerAccountIndex != 2 || s.nft(0).CreatorAccountIndex != 3 {
+		t.Fatal("nft not updated by the transfer nft")
+	}
+	// offer ids from 128 are the bits of asset 1, apart from the gas fee asset
+	offer := func(sk *curve.PrivateKey, offerType, offerId, accountIndex int64) *legendTxTypes.OfferTxInfo {
+		txInfo, err := legendTxTypes.ConstructOfferTxInfo(sk, fmt.Sprintf(
+			`{"type":%d,"offer_id":%d,"account_index":%d,"nft_index":0,"asset_id":0,"asset_amount":"10000",`+
+				`"listed_at":1654656761000,"expired_at":1654656781000,"treasury_rate":200}`, offerType, offerId, accountIndex))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return txInfo
+	}
+	cancelOfferTxInfo, err := legendTxTypes.ConstructCancelOfferTxInfo(buyerSk,
+		`{"account_index":1,"offer_id":129,"gas_account_index":0,"gas_fee_asset_id":0,`+
+			`"gas_fee_asset_amount":"10","expired_at":1654656781000,"nonce":0}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buildTx(t, s, cancelOfferTxInfo)
+	if s.accountAsset(1, 1).OfferCanceledOrFinalized.Int64() != 1<<1 {
+		t.Fatal("offer not canceled")
+	}
+	atomicMatch := func(buyOffer *legendTxTypes.OfferTxInfo, nonce int64) *legendTxTypes.AtomicMatchTxInfo {
+		buyOfferBytes, err := json.Marshal(buyOffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sellOfferBytes, err := json.Marshal(offer(sellerSk, 1, 128, 2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		segment, err := json.Marshal(&legendTxTypes.AtomicMatchSegmentFormat{
+			AccountIndex:      1,
+			BuyOffer:          string(buyOfferBytes),
+			SellOffer:         string(sellOfferBytes),
+			GasAccountIndex:   0,
+			GasFeeAssetId:     0,
+			GasFeeAssetAmount: "10",
+			Nonce:             nonce,
+			ExpiredAt:         1654656781000,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		txInfo, err := legendTxTypes.ConstructAtomicMatchTxInfo(buyerSk, string(segment))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// set by L2 from the creator treasury rate of the nft and the offers
+		txInfo.CreatorAmount = big.NewInt(10000 * 100 / std.RateBase)
+		txInfo.TreasuryAmount = big.NewInt(10000 * 200 / std.RateBase)
+		return txInfo
+	}
+	// the canceled offer can't be matched
+	if _, err = s.BuildTx(atomicMatch(offer(buyerSk, 0, 129, 1), 1), 0); err == nil {
+		t.Fatal("canceled offer matched")
+	}
+	buildTx(t, s, atomicMatch(offer(buyerSk, 0, 130, 1), 1))
+	if s.nft(0).OwnerAccountIndex != 1 {
+		t.Fatal("nft not updated by the atomic match")
+	}
+	for accountIndex, balance := range map[int64]int64{
+		0: 10*5 + 200, 1: 100000 - 10*2 - 10000, 2: 100000 + 10000 - 100 - 200, 3: 100000 - 10*3 + 100,
+	} {
+		if s.accountAsset(accountIndex, 0).Balance.Int64() != balance {
+			t.Fatalf("invalid balance of account %d", accountIndex)
+		}
+	}
+	if _, err = s.BuildTx(atomicMatch(offer(buyerSk, 0, 131, 1), 2), 0); err == nil {
+		t.Fatal("finalized sell offer matched")
+	}
+	withdrawNftTxInfo, err := legendTxTypes.ConstructWithdrawNftTxInfo(buyerSk,
+		`{"account_index":1,"nft_index":0,"to_address":"0xd5Aa3B56a2E2139DB315CdFE3b34149c8ed09171",`+
+			`"gas_account_index":0,"gas_fee_asset_id":0,"gas_fee_asset_amount":"10","expired_at":1654656781000,"nonce":2}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// set by L2 from the nft
+	withdrawNftTxInfo.CreatorAccountIndex = 3
+	withdrawNftTxInfo.CreatorAccountNameHash = accountNameHash("carl.legend")
+	withdrawNftTxInfo.CreatorTreasuryRate = 100
+	withdrawNftTxInfo.NftContentHash = s.nft(0).NftContentHash
+	withdrawNftTxInfo.NftL1Address = "0"
+	withdrawNftTxInfo.NftL1TokenId = big.NewInt(0)
+	withdrawNftTxInfo.CollectionId = 0
+	oTx := buildTx(t, s, withdrawNftTxInfo)
+	if !block.IsOnChainOp(oTx.TxType) || new(big.Int).SetBytes(s.nft(0).NftContentHash).Sign() != 0 {
+		t.Fatal("nft not withdrawn")
+	}
+	// the nft is deposited back from L1 and exits again
+	depositNftTxInfo := &legendTxTypes.DepositNftTxInfo{
+		TxType:              legendTxTypes.TxTypeDepositNft,
+		AccountIndex:        2,
+		AccountNameHash:     accountNameHash("gavin.legend"),
+		NftIndex:            1,
+		NftL1Address:        "0x78C34ad5641aE34eDEc94dd463C61298070Ff7BE",
+		NftL1TokenId:        big.NewInt(7),
+		NftContentHash:      accountNameHash("legend nft"),
+		CreatorAccountIndex: 3,
+		CreatorTreasuryRate: 100,
+		CollectionId:        0,
+	}
+	buildTx(t, s, depositNftTxInfo)
+	if _, err = s.BuildTx(depositNftTxInfo, 0); err == nil {
+		t.Fatal("nft deposited twice")
+	}
+	fullExitNftTxInfo := &legendTxTypes.FullExitNftTxInfo{
+		TxType:                 legendTxTypes.TxTypeFullExitNft,
+		AccountIndex:           2,
+		AccountNameHash:        accountNameHash("gavin.legend"),
+		CreatorAccountIndex:    3,
+		CreatorAccountNameHash: accountNameHash("carl.legend"),
+		CreatorTreasuryRate:    100,
+		NftIndex:               1,
+		CollectionId:           0,
+		NftContentHash:         accountNameHash("legend nft"),
+		NftL1Address:           "0x78C34ad5641aE34eDEc94dd463C61298070Ff7BE",
+		NftL1TokenId:           big.NewInt(8),
+	}
+	if _, err = s.BuildTx(fullExitNftTxInfo, 0); err == nil {
+		t.Fatal("full exit of another l1 token accepted")
+	}
+	fullExitNftTxInfo.NftL1TokenId = big.NewInt(7)
+	buildTx(t, s, fullExitNftTxInfo)
+	if new(big.Int).SetBytes(s.nft(1).NftContentHash).Sign() != 0 {
+		t.Fatal("nft not updated by the full exit nft")
+	}
+}
+
+func TestSetTxInfo(t *testing.T) {
+	if _, err := SetTxInfo(&legendTxTypes.DepositTxInfo{TxType: legendTxTypes.TxTypeDeposit}); err == nil {
+		t.Fatal("nil amount accepted")
+	}
+	if _, err := SetTxInfo(&legendTxTypes.RegisterZnsTxInfo{TxType: legendTxTypes.TxTypeRegisterZns, PubKey: "00"}); err == nil {
+		t.Fatal("invalid public key accepted")
+	}
+}
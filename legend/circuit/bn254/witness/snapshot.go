@@ -0,0 +1,191 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package witness
+
+import (
+	"errors"
+	"log"
+	"sort"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/exodus"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+/*
+	Snapshot: leaves of the state, encoded as json to be read by the exodus
+	prover, the merkle trees are rebuilt from the leaves
+*/
+type Snapshot struct {
+	Accounts      []*Account
+	AccountAssets map[int64][]*std.AccountAsset
+	Liquidities   []*std.Liquidity
+	Nfts          []*std.Nft
+}
+
+/*
+	NewStateFromSnapshot: state with the leaves of the snapshot
+*/
+func NewStateFromSnapshot(snapshot *Snapshot) (s *State, err error) {
+	if snapshot == nil {
+		log.Println("[NewStateFromSnapshot] invalid params")
+		return nil, errors.New("[NewStateFromSnapshot] invalid params")
+	}
+	s, err = NewState()
+	if err != nil {
+		return nil, err
+	}
+	for _, account := range snapshot.Accounts {
+		if err = s.SetAccount(account); err != nil {
+			return nil, err
+		}
+	}
+	for accountIndex, assets := range snapshot.AccountAssets {
+		for _, asset := range assets {
+			if err = s.SetAccountAsset(accountIndex, asset); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, liquidity := range snapshot.Liquidities {
+		if err = s.SetLiquidity(liquidity); err != nil {
+			return nil, err
+		}
+	}
+	for _, nft := range snapshot.Nfts {
+		if err = s.SetNft(nft); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+/*
+	Snapshot: leaves of the state sorted by index
+*/
+func (s *State) Snapshot() *Snapshot {
+	snapshot := &Snapshot{AccountAssets: make(map[int64][]*std.AccountAsset)}
+	for _, account := range s.Accounts {
+		snapshot.Accounts = append(snapshot.Accounts, account)
+	}
+	sort.Slice(snapshot.Accounts, func(i, j int) bool {
+		return snapshot.Accounts[i].AccountIndex < snapshot.Accounts[j].AccountIndex
+	})
+	for accountIndex, assets := range s.AccountAssets {
+		var accountAssets []*std.AccountAsset
+		for _, asset := range assets {
+			accountAssets = append(accountAssets, asset)
+		}
+		sort.Slice(accountAssets, func(i, j int) bool {
+			return accountAssets[i].AssetId < accountAssets[j].AssetId
+		})
+		snapshot.AccountAssets[accountIndex] = accountAssets
+	}
+	for _, liquidity := range s.Liquidities {
+		snapshot.Liquidities = append(snapshot.Liquidities, liquidity)
+	}
+	sort.Slice(snapshot.Liquidities, func(i, j int) bool {
+		return snapshot.Liquidities[i].PairIndex < snapshot.Liquidities[j].PairIndex
+	})
+	for _, nft := range s.Nfts {
+		snapshot.Nfts = append(snapshot.Nfts, nft)
+	}
+	sort.Slice(snapshot.Nfts, func(i, j int) bool {
+		return snapshot.Nfts[i].NftIndex < snapshot.Nfts[j].NftIndex
+	})
+	return snapshot
+}
+
+/*
+	BuildExodus: witness of the exodus circuit for the asset of the account,
+	the account must be registered but the asset may be empty
+*/
+func (s *State) BuildExodus(accountIndex int64, assetId int64) (oExodus *exodus.Exodus, err error) {
+	account, err := s.exodusAccount(accountIndex)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := s.accountAssetProof(accountIndex, assetId)
+	if err != nil {
+		log.Println("[BuildExodus] unable to build asset merkle proof:", err)
+		return nil, err
+	}
+	oExodus = &exodus.Exodus{
+		StateRoot:     s.StateRoot(),
+		Account:       account,
+		Asset:         s.accountAsset(accountIndex, assetId),
+		LiquidityRoot: s.LiquidityTree.RootNode.Value,
+		NftRoot:       s.NftTree.RootNode.Value,
+	}
+	copy(oExodus.MerkleProofsAccountAsset[:], proof)
+	return oExodus, nil
+}
+
+/*
+	BuildExodusNft: witness of the exodus circuit for the nft, the nft must
+	exist and its owner must be registered
+*/
+func (s *State) BuildExodusNft(nftIndex int64) (oExodus *exodus.ExodusNft, err error) {
+	nft, isExist := s.Nfts[nftIndex]
+	if !isExist {
+		log.Println("[BuildExodusNft] nft doesn't exist:", nftIndex)
+		return nil, errors.New("[BuildExodusNft] nft doesn't exist")
+	}
+	account, err := s.exodusAccount(nft.OwnerAccountIndex)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := buildMerkleProofs(s.NftTree, nftIndex)
+	if err != nil {
+		log.Println("[BuildExodusNft] unable to build nft merkle proof:", err)
+		return nil, err
+	}
+	oExodus = &exodus.ExodusNft{
+		StateRoot:     s.StateRoot(),
+		Account:       account,
+		Nft:           s.nft(nftIndex),
+		LiquidityRoot: s.LiquidityTree.RootNode.Value,
+	}
+	copy(oExodus.MerkleProofsNft[:], proof)
+	return oExodus, nil
+}
+
+func (s *State) exodusAccount(accountIndex int64) (account *exodus.ExodusAccount, err error) {
+	info, isExist := s.Accounts[accountIndex]
+	if !isExist {
+		log.Println("[exodusAccount] account doesn't exist:", accountIndex)
+		return nil, errors.New("[exodusAccount] account doesn't exist")
+	}
+	proof, err := buildMerkleProofs(s.AccountTree, accountIndex)
+	if err != nil {
+		log.Println("[exodusAccount] unable to build account merkle proof:", err)
+		return nil, err
+	}
+	account = &exodus.ExodusAccount{
+		AccountIndex:    info.AccountIndex,
+		AccountNameHash: info.AccountNameHash,
+		AccountPk:       info.AccountPk,
+		Nonce:           info.Nonce,
+		CollectionNonce: info.CollectionNonce,
+		AssetRoot:       s.slotAccount(accountIndex, [block.NbAccountAssetsPerAccount]int64{}).AssetRoot,
+		SignerRoot:      info.SignerRoot,
+		SignerThreshold: info.SignerThreshold,
+	}
+	copy(account.MerkleProofsAccount[:], proof)
+	return account, nil
+}
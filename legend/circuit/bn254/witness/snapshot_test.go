@@ -0,0 +1,128 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package witness
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/test"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/exodus"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+	"github.com/bnb-chain/zkbas-crypto/wasm/legend/legendTxTypes"
+)
+
+func TestExodus(t *testing.T) {
+	s, err := NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registerAccount(t, s, 0, "treasury.legend")
+	registerAccount(t, s, 1, "sher.legend")
+	buildTx(t, s, &legendTxTypes.DepositTxInfo{
+		TxType:          legendTxTypes.TxTypeDeposit,
+		AccountIndex:    1,
+		AccountNameHash: accountNameHash("sher.legend"),
+		AssetId:         2,
+		AssetAmount:     big.NewInt(100000000),
+	})
+	err = s.SetNft(&std.Nft{
+		NftIndex:            3,
+		NftContentHash:      accountNameHash("content"),
+		CreatorAccountIndex: 0,
+		OwnerAccountIndex:   1,
+		NftL1Address:        big.NewInt(0),
+		NftL1TokenId:        big.NewInt(0),
+		CreatorTreasuryRate: 30,
+		CollectionId:        1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the state read from its snapshot has the same root
+	stateRoot := s.StateRoot()
+	data, err := json.Marshal(s.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snapshot *Snapshot
+	if err = json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	s, err = NewStateFromSnapshot(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(s.StateRoot(), stateRoot) {
+		t.Fatal("invalid state root of the snapshot")
+	}
+
+	oExodus, err := s.BuildExodus(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	witness, err := exodus.SetExodusWitness(oExodus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var circuit exodus.ExodusConstraints
+	if err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16); err != nil {
+		t.Fatal(err)
+	}
+	witness.Balance = 100000001
+	if err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16); err == nil {
+		t.Fatal("exodus of a wrong balance accepted")
+	}
+	// an empty asset can be proven too
+	oExodus, err = s.BuildExodus(0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	witness, err = exodus.SetExodusWitness(oExodus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = test.IsSolved(&circuit, &witness, ecc.BN254, backend.GROTH16); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.BuildExodus(5, 2); err == nil {
+		t.Fatal("exodus of an unregistered account accepted")
+	}
+
+	oExodusNft, err := s.BuildExodusNft(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nftWitness, err := exodus.SetExodusNftWitness(oExodusNft)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nftCircuit exodus.ExodusNftConstraints
+	if err = test.IsSolved(&nftCircuit, &nftWitness, ecc.BN254, backend.GROTH16); err != nil {
+		t.Fatal(err)
+	}
+	nftWitness.Nft.OwnerAccountIndex = 0
+	if err = test.IsSolved(&nftCircuit, &nftWitness, ecc.BN254, backend.GROTH16); err == nil {
+		t.Fatal("exodus of an nft of another account accepted")
+	}
+}
@@ -0,0 +1,448 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package witness
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+
+	"github.com/bnb-chain/zkbas-crypto/accumulators/merkleTree"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+/*
+	Account: account leaf without its assets
+*/
+type Account struct {
+	AccountIndex    int64
+	AccountNameHash []byte
+	AccountPk       *eddsa.PublicKey
+	Nonce           int64
+	CollectionNonce int64
+	SignerRoot      []byte
+	SignerThreshold int64
+}
+
+/*
+	State: leaves of the state and the merkle trees of their hashes, the leaves
+	which aren't set are empty and their node is the nil node of the tree
+*/
+type State struct {
+	AccountTree       *merkleTree.Tree
+	AccountAssetTrees map[int64]*merkleTree.Tree
+	LiquidityTree     *merkleTree.Tree
+	NftTree           *merkleTree.Tree
+
+	Accounts      map[int64]*Account
+	AccountAssets map[int64]map[int64]*std.AccountAsset
+	Liquidities   map[int64]*std.Liquidity
+	Nfts          map[int64]*std.Nft
+
+	// undo the updates of the tx being built
+	journal []func()
+}
+
+func NewState() (s *State, err error) {
+	s = &State{
+		AccountAssetTrees: make(map[int64]*merkleTree.Tree),
+		Accounts:          make(map[int64]*Account),
+		AccountAssets:     make(map[int64]map[int64]*std.AccountAsset),
+		Liquidities:       make(map[int64]*std.Liquidity),
+		Nfts:              make(map[int64]*std.Nft),
+	}
+	accountNilHash, err := accountNodeHash(s.slotAccount(0, [block.NbAccountAssetsPerAccount]int64{}))
+	if err != nil {
+		return nil, err
+	}
+	s.AccountTree, err = merkleTree.NewEmptyTree(block.AccountMerkleLevels, accountNilHash, mimc.NewMiMC())
+	if err != nil {
+		return nil, err
+	}
+	liquidityNilHash, err := liquidityNodeHash(std.EmptyLiquidity(0))
+	if err != nil {
+		return nil, err
+	}
+	s.LiquidityTree, err = merkleTree.NewEmptyTree(block.LiquidityMerkleLevels, liquidityNilHash, mimc.NewMiMC())
+	if err != nil {
+		return nil, err
+	}
+	nftNilHash, err := nftNodeHash(std.EmptyNft(0))
+	if err != nil {
+		return nil, err
+	}
+	s.NftTree, err = merkleTree.NewEmptyTree(block.NftMerkleLevels, nftNilHash, mimc.NewMiMC())
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+/*
+	StateRoot: hash of the account, liquidity and nft roots
+*/
+func (s *State) StateRoot() []byte {
+	return hashNodes(s.AccountTree.RootNode.Value, s.LiquidityTree.RootNode.Value, s.NftTree.RootNode.Value)
+}
+
+/*
+	SetAccount, SetAccountAsset, SetLiquidity, SetNft: load a leaf of the state,
+	the assets of an account are kept when the account is set
+*/
+func (s *State) SetAccount(account *Account) (err error) {
+	return s.apply(s.setAccount(account))
+}
+
+func (s *State) SetAccountAsset(accountIndex int64, asset *std.AccountAsset) (err error) {
+	return s.apply(s.setAccountAsset(accountIndex, asset))
+}
+
+func (s *State) SetLiquidity(liquidity *std.Liquidity) (err error) {
+	return s.apply(s.setLiquidity(liquidity))
+}
+
+func (s *State) SetNft(nft *std.Nft) (err error) {
+	return s.apply(s.setNft(nft))
+}
+
+func (s *State) setAccount(account *Account) (err error) {
+	if account == nil || account.AccountPk == nil {
+		log.Println("[setAccount] invalid params")
+		return errors.New("[setAccount] invalid params")
+	}
+	old, isExist := s.Accounts[account.AccountIndex]
+	s.Accounts[account.AccountIndex] = account
+	s.record(func() {
+		if isExist {
+			s.Accounts[account.AccountIndex] = old
+		} else {
+			delete(s.Accounts, account.AccountIndex)
+		}
+	})
+	return s.updateAccountNode(account.AccountIndex)
+}
+
+func (s *State) setAccountAsset(accountIndex int64, asset *std.AccountAsset) (err error) {
+	if asset == nil || asset.Balance == nil || asset.LpAmount == nil || asset.OfferCanceledOrFinalized == nil {
+		log.Println("[setAccountAsset] invalid params")
+		return errors.New("[setAccountAsset] invalid params")
+	}
+	assets, isExist := s.AccountAssets[accountIndex]
+	if !isExist {
+		assets = make(map[int64]*std.AccountAsset)
+		s.AccountAssets[accountIndex] = assets
+		s.record(func() { delete(s.AccountAssets, accountIndex) })
+	}
+	old, isAssetExist := assets[asset.AssetId]
+	assets[asset.AssetId] = asset
+	s.record(func() {
+		if isAssetExist {
+			assets[asset.AssetId] = old
+		} else {
+			delete(assets, asset.AssetId)
+		}
+	})
+	nodeHash, err := accountAssetNodeHash(asset)
+	if err != nil {
+		return err
+	}
+	tree, isExist := s.AccountAssetTrees[accountIndex]
+	if !isExist {
+		tree, err = newAccountAssetTree()
+		if err != nil {
+			return err
+		}
+		s.AccountAssetTrees[accountIndex] = tree
+		s.record(func() { delete(s.AccountAssetTrees, accountIndex) })
+	}
+	err = s.updateTree(tree, asset.AssetId, nodeHash)
+	if err != nil {
+		return err
+	}
+	return s.updateAccountNode(accountIndex)
+}
+
+func (s *State) setLiquidity(liquidity *std.Liquidity) (err error) {
+	if liquidity == nil {
+		log.Println("[setLiquidity] invalid params")
+		return errors.New("[setLiquidity] invalid params")
+	}
+	old, isExist := s.Liquidities[liquidity.PairIndex]
+	s.Liquidities[liquidity.PairIndex] = liquidity
+	s.record(func() {
+		if isExist {
+			s.Liquidities[liquidity.PairIndex] = old
+		} else {
+			delete(s.Liquidities, liquidity.PairIndex)
+		}
+	})
+	nodeHash, err := liquidityNodeHash(liquidity)
+	if err != nil {
+		return err
+	}
+	return s.updateTree(s.LiquidityTree, liquidity.PairIndex, nodeHash)
+}
+
+func (s *State) setNft(nft *std.Nft) (err error) {
+	if nft == nil {
+		log.Println("[setNft] invalid params")
+		return errors.New("[setNft] invalid params")
+	}
+	old, isExist := s.Nfts[nft.NftIndex]
+	s.Nfts[nft.NftIndex] = nft
+	s.record(func() {
+		if isExist {
+			s.Nfts[nft.NftIndex] = old
+		} else {
+			delete(s.Nfts, nft.NftIndex)
+		}
+	})
+	nodeHash, err := nftNodeHash(nft)
+	if err != nil {
+		return err
+	}
+	return s.updateTree(s.NftTree, nft.NftIndex, nodeHash)
+}
+
+/*
+	slotAccount: account with the assets of a tx slot
+*/
+func (s *State) slotAccount(accountIndex int64, assetIds [block.NbAccountAssetsPerAccount]int64) *std.Account {
+	account := std.EmptyAccount(accountIndex, std.EmptyAssetRoot.FillBytes(make([]byte, 32)))
+	if info, isExist := s.Accounts[accountIndex]; isExist {
+		account.AccountNameHash = info.AccountNameHash
+		account.AccountPk = info.AccountPk
+		account.Nonce = info.Nonce
+		account.CollectionNonce = info.CollectionNonce
+		account.SignerRoot = info.SignerRoot
+		account.SignerThreshold = info.SignerThreshold
+	}
+	if tree, isExist := s.AccountAssetTrees[accountIndex]; isExist {
+		account.AssetRoot = tree.RootNode.Value
+	}
+	for j, assetId := range assetIds {
+		account.AssetsInfo[j] = s.accountAsset(accountIndex, assetId)
+	}
+	return account
+}
+
+func (s *State) accountAsset(accountIndex int64, assetId int64) *std.AccountAsset {
+	asset, isExist := s.AccountAssets[accountIndex][assetId]
+	if !isExist {
+		return std.EmptyAccountAsset(assetId)
+	}
+	return &std.AccountAsset{
+		AssetId:                  asset.AssetId,
+		Balance:                  new(big.Int).Set(asset.Balance),
+		LpAmount:                 new(big.Int).Set(asset.LpAmount),
+		OfferCanceledOrFinalized: new(big.Int).Set(asset.OfferCanceledOrFinalized),
+	}
+}
+
+func (s *State) liquidity(pairIndex int64) *std.Liquidity {
+	liquidity, isExist := s.Liquidities[pairIndex]
+	if !isExist {
+		return std.EmptyLiquidity(pairIndex)
+	}
+	res := *liquidity
+	return &res
+}
+
+func (s *State) nft(nftIndex int64) *std.Nft {
+	nft, isExist := s.Nfts[nftIndex]
+	if !isExist {
+		return std.EmptyNft(nftIndex)
+	}
+	res := *nft
+	return &res
+}
+
+func (s *State) accountAssetProof(accountIndex int64, assetId int64) (proof [][]byte, err error) {
+	tree, isExist := s.AccountAssetTrees[accountIndex]
+	if !isExist {
+		tree, err = newAccountAssetTree()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buildMerkleProofs(tree, assetId)
+}
+
+func (s *State) updateAccountNode(accountIndex int64) (err error) {
+	nodeHash, err := accountNodeHash(s.slotAccount(accountIndex, [block.NbAccountAssetsPerAccount]int64{}))
+	if err != nil {
+		return err
+	}
+	return s.updateTree(s.AccountTree, accountIndex, nodeHash)
+}
+
+/*
+	updateTree: set the node of the leaf, unchanged nodes aren't written so that
+	the tree doesn't grow up to the index of an unused slot
+*/
+func (s *State) updateTree(tree *merkleTree.Tree, index int64, nodeHash []byte) (err error) {
+	oldNodeHash := tree.NilHashValueConst[0]
+	if index < int64(len(tree.Leaves)) {
+		oldNodeHash = tree.Leaves[index].Value
+	}
+	if bytes.Equal(oldNodeHash, nodeHash) {
+		return nil
+	}
+	err = tree.Update(index, nodeHash)
+	if err != nil {
+		log.Println("[updateTree] unable to update tree:", err)
+		return err
+	}
+	s.record(func() { _ = tree.Update(index, oldNodeHash) })
+	return nil
+}
+
+func (s *State) record(undo func()) {
+	s.journal = append(s.journal, undo)
+}
+
+/*
+	rollback: undo the updates since the last commit
+*/
+func (s *State) rollback() {
+	for i := len(s.journal) - 1; i >= 0; i-- {
+		s.journal[i]()
+	}
+	s.journal = nil
+}
+
+func (s *State) commit() {
+	s.journal = nil
+}
+
+/*
+	apply: keep the updates if err is nil, undo them otherwise
+*/
+func (s *State) apply(err error) error {
+	if err != nil {
+		s.rollback()
+		return err
+	}
+	s.commit()
+	return nil
+}
+
+func newAccountAssetTree() (*merkleTree.Tree, error) {
+	nilHash, err := accountAssetNodeHash(std.EmptyAccountAsset(0))
+	if err != nil {
+		return nil, err
+	}
+	return merkleTree.NewEmptyTree(block.AssetMerkleLevels, nilHash, mimc.NewMiMC())
+}
+
+/*
+	buildMerkleProofs: proof of the leaf from the leaf level to the root
+*/
+func buildMerkleProofs(tree *merkleTree.Tree, index int64) (proof [][]byte, err error) {
+	proof, _, err = tree.BuildMerkleProofs(index)
+	if err != nil {
+		return nil, err
+	}
+	if len(proof) != tree.MaxHeight {
+		log.Println("[buildMerkleProofs] invalid merkle proof")
+		return nil, errors.New("[buildMerkleProofs] invalid merkle proof")
+	}
+	return merkleTree.CopyMerkleProofs(proof), nil
+}
+
+func accountAssetNodeHash(asset *std.AccountAsset) ([]byte, error) {
+	witness, err := std.SetAccountAssetWitness(asset)
+	if err != nil {
+		return nil, err
+	}
+	return hashInputs(std.CollectHashInputsFromAccountAsset(witness))
+}
+
+func accountNodeHash(account *std.Account) ([]byte, error) {
+	witness, err := std.SetAccountWitness(account)
+	if err != nil {
+		return nil, err
+	}
+	fieldsHash, err := hashInputs(std.CollectHashInputsFromAccount(witness, witness.AssetRoot))
+	if err != nil {
+		return nil, err
+	}
+	// an account of a single key keeps the hash of its other fields
+	if new(big.Int).SetBytes(account.SignerRoot).Sign() == 0 && account.SignerThreshold == 0 {
+		return fieldsHash, nil
+	}
+	return hashInputs(std.CollectHashInputsFromAccountSigners(witness, new(big.Int).SetBytes(fieldsHash)))
+}
+
+func liquidityNodeHash(liquidity *std.Liquidity) ([]byte, error) {
+	witness, err := std.SetLiquidityWitness(liquidity)
+	if err != nil {
+		return nil, err
+	}
+	packedFeeRate := std.ComputePackedPairFeeRate(liquidity.FeeRate, liquidity.PairType, liquidity.Amplification)
+	fieldsHash, err := hashInputs(std.CollectHashInputsFromLiquidity(witness, packedFeeRate))
+	if err != nil {
+		return nil, err
+	}
+	// a pair which never accumulated prices keeps the hash of its other fields
+	priceACumulativeLast, priceBCumulativeLast := witness.PriceACumulativeLast.(*big.Int), witness.PriceBCumulativeLast.(*big.Int)
+	if priceACumulativeLast.Sign() == 0 && priceBCumulativeLast.Sign() == 0 && liquidity.BlockTimestampLast == 0 {
+		return fieldsHash, nil
+	}
+	return hashInputs(std.CollectHashInputsFromLiquidityPrices(witness, new(big.Int).SetBytes(fieldsHash)))
+}
+
+func nftNodeHash(nft *std.Nft) ([]byte, error) {
+	witness, err := std.SetNftWitness(nft)
+	if err != nil {
+		return nil, err
+	}
+	return hashInputs(std.CollectHashInputsFromNft(witness))
+}
+
+/*
+	hashInputs: MiMC of the witness values, like the leaf hashes of the circuit
+*/
+func hashInputs(inputs []std.Variable) ([]byte, error) {
+	hFunc := mimc.NewMiMC()
+	for _, input := range inputs {
+		var x fr.Element
+		_, err := x.SetInterface(input)
+		if err != nil {
+			log.Println("[hashInputs] invalid input:", err)
+			return nil, err
+		}
+		b := x.Bytes()
+		hFunc.Write(b[:])
+	}
+	return hFunc.Sum(nil), nil
+}
+
+func hashNodes(nodes ...[]byte) []byte {
+	hFunc := mimc.NewMiMC()
+	for _, node := range nodes {
+		hFunc.Write(node)
+	}
+	return hFunc.Sum(nil)
+}
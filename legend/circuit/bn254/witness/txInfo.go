@@ -0,0 +1,493 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package witness
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+	"github.com/bnb-chain/zkbas-crypto/util"
+	"github.com/bnb-chain/zkbas-crypto/wasm/legend/legendTxTypes"
+)
+
+/*
+	SetTxInfo: block tx with the tx info, nonce, expiry and signature of the tx,
+	amounts are packed like in the signed message
+*/
+func SetTxInfo(txInfo legendTxTypes.TxInfo) (oTx *block.Tx, err error) {
+	if txInfo == nil {
+		log.Println("[SetTxInfo] invalid params")
+		return nil, errors.New("[SetTxInfo] invalid params")
+	}
+	// the partial signatures of a multi-signature account replace the signature
+	if multiSigTxInfo, isMultiSig := txInfo.(*legendTxTypes.MultiSigTxInfo); isMultiSig {
+		return setMultiSigTxInfo(multiSigTxInfo)
+	}
+	var c converter
+	oTx = &block.Tx{
+		TxType:    uint8(txInfo.GetTxType()),
+		Nonce:     txInfo.GetNonce(),
+		ExpiredAt: txInfo.GetExpiredAt(),
+		Signature: std.EmptySignature(),
+	}
+	switch txInfo := txInfo.(type) {
+	case *legendTxTypes.RegisterZnsTxInfo:
+		pubKey, err := legendTxTypes.ParsePublicKey(txInfo.PubKey)
+		if err != nil {
+			log.Println("[SetTxInfo] invalid public key:", err)
+			return nil, err
+		}
+		oTx.RegisterZnsTxInfo = &block.RegisterZnsTx{
+			AccountIndex:    txInfo.AccountIndex,
+			AccountName:     legendTxTypes.PaddingStringToBytes32(txInfo.AccountName),
+			AccountNameHash: txInfo.AccountNameHash,
+			PubKey:          pubKey,
+		}
+	case *legendTxTypes.CreatePairTxInfo:
+		oTx.CreatePairTxInfo = &block.CreatePairTx{
+			PairIndex:            txInfo.PairIndex,
+			AssetAId:             txInfo.AssetAId,
+			AssetBId:             txInfo.AssetBId,
+			FeeRate:              txInfo.FeeRate,
+			TreasuryAccountIndex: txInfo.TreasuryAccountIndex,
+			TreasuryRate:         txInfo.TreasuryRate,
+			PairType:             txInfo.PairType,
+			Amplification:        txInfo.Amplification,
+		}
+	case *legendTxTypes.UpdatePairRateTxInfo:
+		oTx.UpdatePairRateTxInfo = &block.UpdatePairRateTx{
+			PairIndex:            txInfo.PairIndex,
+			FeeRate:              txInfo.FeeRate,
+			TreasuryAccountIndex: txInfo.TreasuryAccountIndex,
+			TreasuryRate:         txInfo.TreasuryRate,
+		}
+	case *legendTxTypes.DepositTxInfo:
+		oTx.DepositTxInfo = &block.DepositTx{
+			AccountIndex:    txInfo.AccountIndex,
+			AccountNameHash: txInfo.AccountNameHash,
+			AssetId:         txInfo.AssetId,
+			AssetAmount:     c.bigInt("AssetAmount", txInfo.AssetAmount),
+		}
+	case *legendTxTypes.DepositNftTxInfo:
+		oTx.DepositNftTxInfo = &block.DepositNftTx{
+			AccountIndex:        txInfo.AccountIndex,
+			NftIndex:            txInfo.NftIndex,
+			NftL1Address:        txInfo.NftL1Address,
+			AccountNameHash:     txInfo.AccountNameHash,
+			NftContentHash:      txInfo.NftContentHash,
+			NftL1TokenId:        c.bigInt("NftL1TokenId", txInfo.NftL1TokenId),
+			CreatorAccountIndex: txInfo.CreatorAccountIndex,
+			CreatorTreasuryRate: txInfo.CreatorTreasuryRate,
+			CollectionId:        txInfo.CollectionId,
+		}
+	case *legendTxTypes.TransferTxInfo:
+		oTx.TransferTxInfo = &block.TransferTx{
+			FromAccountIndex:  txInfo.FromAccountIndex,
+			ToAccountIndex:    txInfo.ToAccountIndex,
+			ToAccountNameHash: common.FromHex(txInfo.ToAccountNameHash),
+			AssetId:           txInfo.AssetId,
+			AssetAmount:       c.packedAmount("AssetAmount", txInfo.AssetAmount),
+			GasAccountIndex:   txInfo.GasAccountIndex,
+			GasFeeAssetId:     txInfo.GasFeeAssetId,
+			GasFeeAssetAmount: c.packedFee("GasFeeAssetAmount", txInfo.GasFeeAssetAmount),
+			CallDataHash:      txInfo.CallDataHash,
+		}
+		oTx.Signature = c.signature(txInfo.Sig)
+	case *legendTxTypes.SwapTxInfo:
+		// the min amount out is only signed by exact input swaps, the max amount in
+		// by exact output swaps
+		var assetBMinAmount, assetAMaxAmount int64
+		if txInfo.SwapMode == legendTxTypes.SwapModeExactOutput {
+			assetAMaxAmount = c.packedAmount("AssetAMaxAmount", txInfo.AssetAMaxAmount)
+		} else {
+			assetBMinAmount = c.packedAmount("AssetBMinAmount", txInfo.AssetBMinAmount)
+		}
+		oTx.SwapTxInfo = &block.SwapTx{
+			FromAccountIndex:  txInfo.FromAccountIndex,
+			PairIndex:         txInfo.PairIndex,
+			AssetAId:          txInfo.AssetAId,
+			AssetAAmount:      c.packedAmount("AssetAAmount", txInfo.AssetAAmount),
+			AssetBId:          txInfo.AssetBId,
+			AssetBMinAmount:   assetBMinAmount,
+			AssetBAmountDelta: c.packedAmount("AssetBAmountDelta", txInfo.AssetBAmountDelta),
+			GasAccountIndex:   txInfo.GasAccountIndex,
+			GasFeeAssetId:     txInfo.GasFeeAssetId,
+			GasFeeAssetAmount: c.packedFee("GasFeeAssetAmount", txInfo.GasFeeAssetAmount),
+			SwapMode:          txInfo.SwapMode,
+			AssetAMaxAmount:   assetAMaxAmount,
+		}
+		oTx.Signature = c.signature(txInfo.Sig)
+	case *legendTxTypes.RouteSwapTxInfo:
+		if len(txInfo.PairIndexes) > block.NbRoutePairsPerTx || len(txInfo.HopAmounts) != len(txInfo.PairIndexes)-1 {
+			log.Println("[SetTxInfo] invalid route")
+			return nil, errors.New("[SetTxInfo] invalid route")
+		}
+		oTx.RouteSwapTxInfo = &block.RouteSwapTx{
+			FromAccountIndex:  txInfo.FromAccountIndex,
+			PairsCount:        int64(len(txInfo.PairIndexes)),
+			AssetAId:          txInfo.AssetAId,
+			AssetAAmount:      c.packedAmount("AssetAAmount", txInfo.AssetAAmount),
+			AssetBId:          txInfo.AssetBId,
+			AssetBMinAmount:   c.packedAmount("AssetBMinAmount", txInfo.AssetBMinAmount),
+			AssetBAmountDelta: c.packedAmount("AssetBAmountDelta", txInfo.AssetBAmountDelta),
+			GasAccountIndex:   txInfo.GasAccountIndex,
+			GasFeeAssetId:     txInfo.GasFeeAssetId,
+			GasFeeAssetAmount: c.packedFee("GasFeeAssetAmount", txInfo.GasFeeAssetAmount),
+		}
+		// the unused pairs and hop amounts are 0
+		copy(oTx.RouteSwapTxInfo.PairIndexes[:], txInfo.PairIndexes)
+		for i, hopAmount := range txInfo.HopAmounts {
+			oTx.RouteSwapTxInfo.HopAmounts[i] = c.packedAmount("HopAmounts", hopAmount)
+		}
+		oTx.Signature = c.signature(txInfo.Sig)
+	case *legendTxTypes.AddLiquidityTxInfo:
+		oTx.AddLiquidityTxInfo = &block.AddLiquidityTx{
+			FromAccountIndex:  txInfo.FromAccountIndex,
+			PairIndex:         txInfo.PairIndex,
+			AssetAId:          txInfo.AssetAId,
+			AssetAAmount:      c.packedAmount("AssetAAmount", txInfo.AssetAAmount),
+			AssetBId:          txInfo.AssetBId,
+			AssetBAmount:      c.packedAmount("AssetBAmount", txInfo.AssetBAmount),
+			LpAmount:          c.packedAmount("LpAmount", txInfo.LpAmount),
+			KLast:             c.packedAmount("KLast", txInfo.KLast),
+			TreasuryAmount:    c.packedAmount("TreasuryAmount", txInfo.TreasuryAmount),
+			GasAccountIndex:   txInfo.GasAccountIndex,
+			GasFeeAssetId:     txInfo.GasFeeAssetId,
+			GasFeeAssetAmount: c.packedFee("GasFeeAssetAmount", txInfo.GasFeeAssetAmount),
+		}
+		oTx.Signature = c.signature(txInfo.Sig)
+	case *legendTxTypes.RemoveLiquidityTxInfo:
+		oTx.RemoveLiquidityTxInfo = &block.RemoveLiquidityTx{
+			FromAccountIndex:  txInfo.FromAccountIndex,
+			PairIndex:         txInfo.PairIndex,
+			AssetAId:          txInfo.AssetAId,
+			AssetAMinAmount:   c.packedAmount("AssetAMinAmount", txInfo.AssetAMinAmount),
+			AssetBId:          txInfo.AssetBId,
+			AssetBMinAmount:   c.packedAmount("AssetBMinAmount", txInfo.AssetBMinAmount),
+			LpAmount:          c.packedAmount("LpAmount", txInfo.LpAmount),
+			KLast:             c.packedAmount("KLast", txInfo.KLast),
+			TreasuryAmount:    c.packedAmount("TreasuryAmount", txInfo.TreasuryAmount),
+			AssetAAmountDelta: c.packedAmount("AssetAAmountDelta", txInfo.AssetAAmountDelta),
+			AssetBAmountDelta: c.packedAmount("AssetBAmountDelta", txInfo.AssetBAmountDelta),
+			GasAccountIndex:   txInfo.GasAccountIndex,
+			GasFeeAssetId:     txInfo.GasFeeAssetId,
+			GasFeeAssetAmount: c.packedFee("GasFeeAssetAmount", txInfo.GasFeeAssetAmount),
+		}
+		oTx.Signature = c.signature(txInfo.Sig)
+	case *legendTxTypes.WithdrawTxInfo:
+		oTx.WithdrawTxInfo = &block.WithdrawTx{
+			FromAccountIndex:  txInfo.FromAccountIndex,
+			AssetId:           txInfo.AssetId,
+			AssetAmount:       c.bigInt("AssetAmount", txInfo.AssetAmount),
+			GasAccountIndex:   txInfo.GasAccountIndex,
+			GasFeeAssetId:     txInfo.GasFeeAssetId,
+			GasFeeAssetAmount: c.packedFee("GasFeeAssetAmount", txInfo.GasFeeAssetAmount),
+			ToAddress:         new(big.Int).SetBytes(common.FromHex(txInfo.ToAddress)),
+		}
+		oTx.Signature = c.signature(txInfo.Sig)
+	case *legendTxTypes.CreateCollectionTxInfo:
+		oTx.CreateCollectionTxInfo = &block.CreateCollectionTx{
+			AccountIndex:      txInfo.AccountIndex,
+			CollectionId:      txInfo.CollectionId,
+			GasAccountIndex:   txInfo.GasAccountIndex,
+			GasFeeAssetId:     txInfo.GasFeeAssetId,
+			GasFeeAssetAmount: c.packedFee("GasFeeAssetAmount", txInfo.GasFeeAssetAmount),
+			ExpiredAt:         txInfo.ExpiredAt,
+			Nonce:             txInfo.Nonce,
+		}
+		oTx.Signature = c.signature(txInfo.Sig)
+	case *legendTxTypes.MintNftTxInfo:
+		oTx.MintNftTxInfo = &block.MintNftTx{
+			CreatorAccountIndex: txInfo.CreatorAccountIndex,
+			ToAccountIndex:      txInfo.ToAccountIndex,
+			ToAccountNameHash:   common.FromHex(txInfo.ToAccountNameHash),
+			NftIndex:            txInfo.NftIndex,
+			NftContentHash:      common.FromHex(txInfo.NftContentHash),
+			CreatorTreasuryRate: txInfo.CreatorTreasuryRate,
+			GasAccountIndex:     txInfo.GasAccountIndex,
+			GasFeeAssetId:       txInfo.GasFeeAssetId,
+			GasFeeAssetAmount:   c.packedFee("GasFeeAssetAmount", txInfo.GasFeeAssetAmount),
+			CollectionId:        txInfo.NftCollectionId,
+			ExpiredAt:           txInfo.ExpiredAt,
+		}
+		oTx.Signature = c.signature(txInfo.Sig)
+	case *legendTxTypes.TransferNftTxInfo:
+		oTx.TransferNftTxInfo = &block.TransferNftTx{
+			FromAccountIndex:  txInfo.FromAccountIndex,
+			ToAccountIndex:    txInfo.ToAccountIndex,
+			ToAccountNameHash: common.FromHex(txInfo.ToAccountNameHash),
+			NftIndex:          txInfo.NftIndex,
+			GasAccountIndex:   txInfo.GasAccountIndex,
+			GasFeeAssetId:     txInfo.GasFeeAssetId,
+			GasFeeAssetAmount: c.packedFee("GasFeeAssetAmount", txInfo.GasFeeAssetAmount),
+			CallDataHash:      txInfo.CallDataHash,
+		}
+		oTx.Signature = c.signature(txInfo.Sig)
+	case *legendTxTypes.AtomicMatchTxInfo:
+		if txInfo.BuyOffer == nil || txInfo.SellOffer == nil {
+			log.Println("[SetTxInfo] invalid offers")
+			return nil, errors.New("[SetTxInfo] invalid offers")
+		}
+		oTx.AtomicMatchTxInfo = &block.AtomicMatchTx{
+			AccountIndex:      txInfo.AccountIndex,
+			BuyOffer:          c.offer(txInfo.BuyOffer),
+			SellOffer:         c.offer(txInfo.SellOffer),
+			CreatorAmount:     c.packedAmount("CreatorAmount", txInfo.CreatorAmount),
+			TreasuryAmount:    c.packedAmount("TreasuryAmount", txInfo.TreasuryAmount),
+			GasAccountIndex:   txInfo.GasAccountIndex,
+			GasFeeAssetId:     txInfo.GasFeeAssetId,
+			GasFeeAssetAmount: c.packedFee("GasFeeAssetAmount", txInfo.GasFeeAssetAmount),
+		}
+		oTx.Signature = c.signature(txInfo.Sig)
+	case *legendTxTypes.MatchOrderTxInfo:
+		if txInfo.BuyOrder == nil || txInfo.SellOrder == nil {
+			log.Println("[SetTxInfo] invalid orders")
+			return nil, errors.New("[SetTxInfo] invalid orders")
+		}
+		oTx.MatchOrderTxInfo = &block.MatchOrderTx{
+			AccountIndex:      txInfo.AccountIndex,
+			BuyOrder:          c.order(txInfo.BuyOrder),
+			SellOrder:         c.order(txInfo.SellOrder),
+			AssetAFillAmount:  c.packedAmount("AssetAFillAmount", txInfo.AssetAFillAmount),
+			AssetBFillAmount:  c.packedAmount("AssetBFillAmount", txInfo.AssetBFillAmount),
+			GasAccountIndex:   txInfo.GasAccountIndex,
+			GasFeeAssetId:     txInfo.GasFeeAssetId,
+			GasFeeAssetAmount: c.packedFee("GasFeeAssetAmount", txInfo.GasFeeAssetAmount),
+		}
+		oTx.Signature = c.signature(txInfo.Sig)
+	case *legendTxTypes.CancelOfferTxInfo:
+		oTx.CancelOfferTxInfo = &block.CancelOfferTx{
+			AccountIndex:      txInfo.AccountIndex,
+			OfferId:           txInfo.OfferId,
+			GasAccountIndex:   txInfo.GasAccountIndex,
+			GasFeeAssetId:     txInfo.GasFeeAssetId,
+			GasFeeAssetAmount: c.packedFee("GasFeeAssetAmount", txInfo.GasFeeAssetAmount),
+		}
+		oTx.Signature = c.signature(txInfo.Sig)
+	case *legendTxTypes.CancelOrderTxInfo:
+		oTx.CancelOrderTxInfo = &block.CancelOrderTx{
+			AccountIndex:      txInfo.AccountIndex,
+			OrderId:           txInfo.OrderId,
+			GasAccountIndex:   txInfo.GasAccountIndex,
+			GasFeeAssetId:     txInfo.GasFeeAssetId,
+			GasFeeAssetAmount: c.packedFee("GasFeeAssetAmount", txInfo.GasFeeAssetAmount),
+		}
+		oTx.Signature = c.signature(txInfo.Sig)
+	case *legendTxTypes.WithdrawNftTxInfo:
+		oTx.WithdrawNftTxInfo = &block.WithdrawNftTx{
+			AccountIndex:           txInfo.AccountIndex,
+			CreatorAccountIndex:    txInfo.CreatorAccountIndex,
+			CreatorAccountNameHash: txInfo.CreatorAccountNameHash,
+			CreatorTreasuryRate:    txInfo.CreatorTreasuryRate,
+			NftIndex:               txInfo.NftIndex,
+			NftContentHash:         txInfo.NftContentHash,
+			NftL1Address:           txInfo.NftL1Address,
+			NftL1TokenId:           c.bigInt("NftL1TokenId", txInfo.NftL1TokenId),
+			ToAddress:              txInfo.ToAddress,
+			GasAccountIndex:        txInfo.GasAccountIndex,
+			GasFeeAssetId:          txInfo.GasFeeAssetId,
+			GasFeeAssetAmount:      c.packedFee("GasFeeAssetAmount", txInfo.GasFeeAssetAmount),
+			CollectionId:           txInfo.CollectionId,
+		}
+		oTx.Signature = c.signature(txInfo.Sig)
+	case *legendTxTypes.FullExitTxInfo:
+		oTx.FullExitTxInfo = &block.FullExitTx{
+			AccountIndex:    txInfo.AccountIndex,
+			AccountNameHash: txInfo.AccountNameHash,
+			AssetId:         txInfo.AssetId,
+			AssetAmount:     c.bigInt("AssetAmount", txInfo.AssetAmount),
+		}
+	case *legendTxTypes.FullExitNftTxInfo:
+		oTx.FullExitNftTxInfo = &block.FullExitNftTx{
+			AccountIndex:           txInfo.AccountIndex,
+			AccountNameHash:        txInfo.AccountNameHash,
+			CreatorAccountIndex:    txInfo.CreatorAccountIndex,
+			CreatorAccountNameHash: txInfo.CreatorAccountNameHash,
+			CreatorTreasuryRate:    txInfo.CreatorTreasuryRate,
+			NftIndex:               txInfo.NftIndex,
+			CollectionId:           txInfo.CollectionId,
+			NftContentHash:         txInfo.NftContentHash,
+			NftL1Address:           txInfo.NftL1Address,
+			NftL1TokenId:           c.bigInt("NftL1TokenId", txInfo.NftL1TokenId),
+		}
+	case *legendTxTypes.ChangePubKeyTxInfo:
+		// the key of a signer set is empty
+		pubKey := new(eddsa.PublicKey)
+		if txInfo.SignerThreshold == 0 {
+			pubKey, err = legendTxTypes.ParsePublicKey(txInfo.PubKey)
+			if err != nil {
+				log.Println("[SetTxInfo] invalid public key:", err)
+				return nil, err
+			}
+		}
+		oTx.ChangePubKeyTxInfo = &block.ChangePubKeyTx{
+			AccountIndex:      txInfo.AccountIndex,
+			PubKey:            pubKey,
+			GasAccountIndex:   txInfo.GasAccountIndex,
+			GasFeeAssetId:     txInfo.GasFeeAssetId,
+			GasFeeAssetAmount: c.packedFee("GasFeeAssetAmount", txInfo.GasFeeAssetAmount),
+			ExpiredAt:         txInfo.ExpiredAt,
+			Nonce:             txInfo.Nonce,
+			SignerRoot:        txInfo.SignerRoot,
+			SignerThreshold:   txInfo.SignerThreshold,
+		}
+		oTx.Signature = c.signature(txInfo.Sig)
+	case *legendTxTypes.FullChangePubKeyTxInfo:
+		pubKey, err := legendTxTypes.ParsePublicKey(txInfo.PubKey)
+		if err != nil {
+			log.Println("[SetTxInfo] invalid public key:", err)
+			return nil, err
+		}
+		oTx.FullChangePubKeyTxInfo = &block.FullChangePubKeyTx{
+			AccountIndex:    txInfo.AccountIndex,
+			AccountNameHash: txInfo.AccountNameHash,
+			PubKey:          pubKey,
+		}
+	default:
+		log.Println("[SetTxInfo] invalid tx type")
+		return nil, errors.New("[SetTxInfo] invalid tx type")
+	}
+	if c.err != nil {
+		log.Println("[SetTxInfo] invalid tx info:", c.err)
+		return nil, c.err
+	}
+	return oTx, nil
+}
+
+/*
+	setMultiSigTxInfo: block tx of the wrapped tx info, signed by the partial
+	signatures with the merkle proofs of their keys in the signer tree
+*/
+func setMultiSigTxInfo(txInfo *legendTxTypes.MultiSigTxInfo) (oTx *block.Tx, err error) {
+	if len(txInfo.Sigs) > block.NbMultiSigsPerTx {
+		log.Println("[SetTxInfo] too many partial signatures")
+		return nil, errors.New("[SetTxInfo] too many partial signatures")
+	}
+	oTx, err = SetTxInfo(txInfo.TxInfo)
+	if err != nil {
+		return nil, err
+	}
+	oTx.Signature = std.EmptySignature()
+	var c converter
+	for i, partialSig := range txInfo.Sigs {
+		pubKey, err := legendTxTypes.ParsePublicKey(partialSig.PubKey)
+		if err != nil {
+			log.Println("[SetTxInfo] invalid signer public key:", err)
+			return nil, err
+		}
+		proof, err := legendTxTypes.ComputeSignerMerkleProof(txInfo.SignerPubKeys, partialSig.SignerIndex)
+		if err != nil {
+			log.Println("[SetTxInfo] unable to compute signer merkle proof:", err)
+			return nil, err
+		}
+		oTx.MultiSigs[i] = &std.MultiSig{
+			SignerIndex: partialSig.SignerIndex,
+			PubKey:      pubKey,
+			Sig:         c.signature(partialSig.Sig),
+		}
+		copy(oTx.MultiSigs[i].MerkleProof[:], proof)
+	}
+	if c.err != nil {
+		log.Println("[SetTxInfo] invalid partial signature:", c.err)
+		return nil, c.err
+	}
+	return oTx, nil
+}
+
+/*
+	converter: keeps the first invalid field of the tx info
+*/
+type converter struct {
+	err error
+}
+
+func (c *converter) fail(field string, err error) {
+	if c.err == nil {
+		c.err = fmt.Errorf("invalid %s: %v", field, err)
+	}
+}
+
+func (c *converter) bigInt(field string, amount *big.Int) *big.Int {
+	if amount == nil {
+		c.fail(field, errors.New("nil amount"))
+		return big.NewInt(0)
+	}
+	return amount
+}
+
+func (c *converter) packedAmount(field string, amount *big.Int) int64 {
+	packedAmount, err := util.ToPackedAmount(c.bigInt(field, amount))
+	if err != nil {
+		c.fail(field, err)
+	}
+	return packedAmount
+}
+
+func (c *converter) packedFee(field string, amount *big.Int) int64 {
+	packedFee, err := util.ToPackedFee(c.bigInt(field, amount))
+	if err != nil {
+		c.fail(field, err)
+	}
+	return packedFee
+}
+
+func (c *converter) signature(sigBytes []byte) *block.Signature {
+	sig := new(eddsa.Signature)
+	_, err := sig.SetBytes(sigBytes)
+	if err != nil {
+		c.fail("Sig", err)
+		return std.EmptySignature()
+	}
+	return sig
+}
+
+func (c *converter) offer(offer *legendTxTypes.OfferTxInfo) *std.OfferTx {
+	return &std.OfferTx{
+		Type:         offer.Type,
+		OfferId:      offer.OfferId,
+		AccountIndex: offer.AccountIndex,
+		NftIndex:     offer.NftIndex,
+		AssetId:      offer.AssetId,
+		AssetAmount:  c.packedAmount("AssetAmount", offer.AssetAmount),
+		ListedAt:     offer.ListedAt,
+		ExpiredAt:    offer.ExpiredAt,
+		TreasuryRate: offer.TreasuryRate,
+		Sig:          c.signature(offer.Sig),
+	}
+}
+
+func (c *converter) order(order *legendTxTypes.OrderTxInfo) *std.OrderTx {
+	return &std.OrderTx{
+		Type:         order.Type,
+		OrderId:      order.OrderId,
+		AccountIndex: order.AccountIndex,
+		AssetAId:     order.AssetAId,
+		AssetBId:     order.AssetBId,
+		AssetAAmount: c.packedAmount("AssetAAmount", order.AssetAAmount),
+		AssetBAmount: c.packedAmount("AssetBAmount", order.AssetBAmount),
+		ExpiredAt:    order.ExpiredAt,
+		Sig:          c.signature(order.Sig),
+	}
+}
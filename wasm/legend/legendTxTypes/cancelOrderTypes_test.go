@@ -0,0 +1,93 @@
+package legendTxTypes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	curve "github.com/bnb-chain/zkbas-crypto/ecc/ztwistededwards/tebn254"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCancelOrderTxInfo(t *testing.T) {
+	testCases := []struct {
+		err      error
+		testCase *CancelOrderTxInfo
+	}{
+		// AccountIndex
+		{
+			fmt.Errorf("AccountIndex should not be less than %d", minAccountIndex),
+			&CancelOrderTxInfo{
+				AccountIndex: minAccountIndex - 1,
+			},
+		},
+		// OrderId, the fungible asset ids aren't order slots
+		{
+			fmt.Errorf("OrderId should not be less than %d", minOrderId),
+			&CancelOrderTxInfo{
+				AccountIndex: 1,
+				OrderId:      maxAssetId,
+			},
+		},
+		{
+			fmt.Errorf("OrderId should not be larger than %d", maxOrderId),
+			&CancelOrderTxInfo{
+				AccountIndex: 1,
+				OrderId:      maxOrderId + 1,
+			},
+		},
+		// GasFeeAssetId
+		{
+			fmt.Errorf("GasFeeAssetId should not be larger than %d", maxAssetId),
+			&CancelOrderTxInfo{
+				AccountIndex:    1,
+				OrderId:         minOrderId,
+				GasAccountIndex: 0,
+				GasFeeAssetId:   minOrderId,
+			},
+		},
+		// GasFeeAssetAmount
+		{
+			fmt.Errorf("GasFeeAssetAmount should not be nil"),
+			&CancelOrderTxInfo{
+				AccountIndex:    1,
+				OrderId:         minOrderId,
+				GasAccountIndex: 0,
+				GasFeeAssetId:   3,
+			},
+		},
+		// true
+		{
+			nil,
+			&CancelOrderTxInfo{
+				AccountIndex:      1,
+				OrderId:           minOrderId,
+				GasAccountIndex:   0,
+				GasFeeAssetId:     3,
+				GasFeeAssetAmount: big.NewInt(100),
+				ExpiredAt:         time.Now().Add(time.Hour).UnixMilli(),
+				Nonce:             1,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := testCase.testCase.Validate()
+		require.Equalf(t, err, testCase.err, "err should be the same")
+	}
+}
+
+func TestCancelOrderSignature(t *testing.T) {
+	sk, err := curve.GenerateEddsaPrivateKey("sher.legend")
+	require.NoError(t, err)
+	otherSk, err := curve.GenerateEddsaPrivateKey("gavin.legend")
+	require.NoError(t, err)
+	txInfo, err := ConstructCancelOrderTxInfo(sk, fmt.Sprintf(`{"account_index":2,"order_id":%d,"gas_account_index":1,`+
+		`"gas_fee_asset_id":0,"gas_fee_asset_amount":"10","expired_at":1654656781000,"nonce":1}`, minOrderId))
+	require.NoError(t, err)
+	require.NoError(t, txInfo.Validate())
+	require.NoError(t, txInfo.VerifySignature(hex.EncodeToString(sk.PublicKey.Bytes())))
+	require.Error(t, txInfo.VerifySignature(hex.EncodeToString(otherSk.PublicKey.Bytes())))
+}
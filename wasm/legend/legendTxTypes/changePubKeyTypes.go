@@ -0,0 +1,266 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package legendTxTypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+)
+
+type ChangePubKeySegmentFormat struct {
+	AccountIndex      int64  `json:"account_index"`
+	PubKey            string `json:"pub_key"`
+	GasAccountIndex   int64  `json:"gas_account_index"`
+	GasFeeAssetId     int64  `json:"gas_fee_asset_id"`
+	GasFeeAssetAmount string `json:"gas_fee_asset_amount"`
+	ExpiredAt         int64  `json:"expired_at"`
+	Nonce             int64  `json:"nonce"`
+	// keys of a multi-signature account and the signatures it requires, the
+	// pub key is empty for a signer set
+	SignerPubKeys   []string `json:"signer_pub_keys"`
+	SignerThreshold int64    `json:"signer_threshold"`
+}
+
+/*
+	ConstructChangePubKeyTxInfo: construct change pub key tx, sign txInfo with the current key
+*/
+func ConstructChangePubKeyTxInfo(sk *PrivateKey, segmentStr string) (txInfo *ChangePubKeyTxInfo, err error) {
+	var segmentFormat *ChangePubKeySegmentFormat
+	err = json.Unmarshal([]byte(segmentStr), &segmentFormat)
+	if err != nil {
+		log.Println("[ConstructChangePubKeyTxInfo] err info:", err)
+		return nil, err
+	}
+	gasFeeAmount, err := StringToBigInt(segmentFormat.GasFeeAssetAmount)
+	if err != nil {
+		log.Println("[ConstructChangePubKeyTxInfo] unable to convert string to big int:", err)
+		return nil, err
+	}
+	gasFeeAmount, _ = CleanPackedFee(gasFeeAmount)
+	var signerRoot []byte
+	if segmentFormat.SignerThreshold != 0 {
+		signerRoot, err = ComputeSignerRoot(segmentFormat.SignerPubKeys)
+		if err != nil {
+			log.Println("[ConstructChangePubKeyTxInfo] unable to compute signer root:", err)
+			return nil, err
+		}
+	}
+	txInfo = &ChangePubKeyTxInfo{
+		AccountIndex:      segmentFormat.AccountIndex,
+		PubKey:            segmentFormat.PubKey,
+		GasAccountIndex:   segmentFormat.GasAccountIndex,
+		GasFeeAssetId:     segmentFormat.GasFeeAssetId,
+		GasFeeAssetAmount: gasFeeAmount,
+		ExpiredAt:         segmentFormat.ExpiredAt,
+		Nonce:             segmentFormat.Nonce,
+		SignerRoot:        signerRoot,
+		SignerThreshold:   segmentFormat.SignerThreshold,
+		Sig:               nil,
+	}
+	// compute msg hash
+	hFunc := mimc.NewMiMC()
+	msgHash, err := ComputeChangePubKeyMsgHash(txInfo, hFunc)
+	if err != nil {
+		log.Println("[ConstructChangePubKeyTxInfo] unable to compute hash:", err)
+		return nil, err
+	}
+	// compute signature
+	hFunc.Reset()
+	sigBytes, err := sk.Sign(msgHash, hFunc)
+	if err != nil {
+		log.Println("[ConstructChangePubKeyTxInfo] unable to sign:", err)
+		return nil, err
+	}
+	txInfo.Sig = sigBytes
+	return txInfo, nil
+}
+
+type ChangePubKeyTxInfo struct {
+	AccountIndex      int64
+	PubKey            string
+	GasAccountIndex   int64
+	GasFeeAssetId     int64
+	GasFeeAssetAmount *big.Int
+	ExpiredAt         int64
+	Nonce             int64
+	SignerRoot        []byte
+	SignerThreshold   int64
+	Sig               []byte
+}
+
+func (txInfo *ChangePubKeyTxInfo) Validate() error {
+	// AccountIndex
+	if txInfo.AccountIndex < minAccountIndex {
+		return fmt.Errorf("AccountIndex should not be less than %d", minAccountIndex)
+	}
+	if txInfo.AccountIndex > maxAccountIndex {
+		return fmt.Errorf("AccountIndex should not be larger than %d", maxAccountIndex)
+	}
+
+	// SignerThreshold
+	if txInfo.SignerThreshold < 0 {
+		return fmt.Errorf("SignerThreshold should not be less than 0")
+	}
+	if txInfo.SignerThreshold > maxSignerThreshold {
+		return fmt.Errorf("SignerThreshold should not be larger than %d", maxSignerThreshold)
+	}
+
+	// PubKey, a signer set replaces the key of the account
+	if txInfo.SignerThreshold == 0 {
+		if _, err := ParsePublicKey(txInfo.PubKey); err != nil {
+			return fmt.Errorf("PubKey is invalid")
+		}
+		if len(txInfo.SignerRoot) != 0 {
+			return fmt.Errorf("SignerRoot should be empty for a single key")
+		}
+	} else {
+		if txInfo.PubKey != "" {
+			return fmt.Errorf("PubKey should be empty for a signer set")
+		}
+		if !IsValidHashBytes(txInfo.SignerRoot) {
+			return fmt.Errorf("SignerRoot is invalid")
+		}
+	}
+
+	// GasAccountIndex
+	if txInfo.GasAccountIndex < minAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be less than %d", minAccountIndex)
+	}
+	if txInfo.GasAccountIndex > maxAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be larger than %d", maxAccountIndex)
+	}
+
+	// GasFeeAssetId
+	if txInfo.GasFeeAssetId < minAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be less than %d", minAssetId)
+	}
+	if txInfo.GasFeeAssetId > maxAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be larger than %d", maxAssetId)
+	}
+
+	// GasFeeAssetAmount
+	if txInfo.GasFeeAssetAmount == nil {
+		return fmt.Errorf("GasFeeAssetAmount should not be nil")
+	}
+	if txInfo.GasFeeAssetAmount.Cmp(minPackedFeeAmount) < 0 {
+		return fmt.Errorf("GasFeeAssetAmount should not be less than %s", minPackedFeeAmount.String())
+	}
+	if txInfo.GasFeeAssetAmount.Cmp(maxPackedFeeAmount) > 0 {
+		return fmt.Errorf("GasFeeAssetAmount should not be larger than %s", maxPackedFeeAmount.String())
+	}
+
+	// Nonce
+	if txInfo.Nonce < minNonce {
+		return fmt.Errorf("Nonce should not be less than %d", minNonce)
+	}
+
+	return nil
+}
+
+/*
+	VerifySignature: the signature is checked with the current key of the account, not the new one
+*/
+func (txInfo *ChangePubKeyTxInfo) VerifySignature(pubKey string) error {
+	// compute hash
+	hFunc := mimc.NewMiMC()
+	msgHash, err := ComputeChangePubKeyMsgHash(txInfo, hFunc)
+	if err != nil {
+		return err
+	}
+	// verify signature
+	hFunc.Reset()
+	pk, err := ParsePublicKey(pubKey)
+	if err != nil {
+		return err
+	}
+	isValid, err := pk.Verify(txInfo.Sig, msgHash, hFunc)
+	if err != nil {
+		return err
+	}
+
+	if !isValid {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func (txInfo *ChangePubKeyTxInfo) GetTxType() int {
+	return TxTypeChangePubKey
+}
+
+func (txInfo *ChangePubKeyTxInfo) GetFromAccountIndex() int64 {
+	return txInfo.AccountIndex
+}
+
+func (txInfo *ChangePubKeyTxInfo) GetNonce() int64 {
+	return txInfo.Nonce
+}
+
+func (txInfo *ChangePubKeyTxInfo) GetExpiredAt() int64 {
+	return txInfo.ExpiredAt
+}
+
+func ComputeChangePubKeyMsgHash(txInfo *ChangePubKeyTxInfo, hFunc hash.Hash) (msgHash []byte, err error) {
+	hFunc.Reset()
+	var buf bytes.Buffer
+	// the key of a signer set is empty
+	pk := new(eddsa.PublicKey)
+	if txInfo.SignerThreshold == 0 {
+		pk, err = ParsePublicKey(txInfo.PubKey)
+		if err != nil {
+			log.Println("[ComputeChangePubKeyMsgHash] invalid public key", err.Error())
+			return nil, err
+		}
+	}
+	packedFee, err := ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		log.Println("[ComputeChangePubKeyMsgHash] unable to packed amount", err.Error())
+		return nil, err
+	}
+	WriteInt64IntoBuf(&buf, txInfo.AccountIndex)
+	WriteBigIntIntoBuf(&buf, pk.A.X.ToBigIntRegular(new(big.Int)))
+	WriteBigIntIntoBuf(&buf, pk.A.Y.ToBigIntRegular(new(big.Int)))
+	WriteInt64IntoBuf(&buf, txInfo.GasAccountIndex)
+	WriteInt64IntoBuf(&buf, txInfo.GasFeeAssetId)
+	WriteInt64IntoBuf(&buf, packedFee)
+	WriteInt64IntoBuf(&buf, txInfo.ExpiredAt)
+	WriteInt64IntoBuf(&buf, txInfo.Nonce)
+	WriteInt64IntoBuf(&buf, ChainId)
+	hFunc.Write(buf.Bytes())
+	msgHash = hFunc.Sum(nil)
+	if len(txInfo.SignerRoot) == 0 && txInfo.SignerThreshold == 0 {
+		return msgHash, nil
+	}
+	// the signer set is signed with the hash of the other fields
+	hFunc.Reset()
+	buf.Reset()
+	buf.Write(msgHash)
+	WriteBigIntIntoBuf(&buf, new(big.Int).SetBytes(txInfo.SignerRoot))
+	WriteInt64IntoBuf(&buf, txInfo.SignerThreshold)
+	hFunc.Write(buf.Bytes())
+	msgHash = hFunc.Sum(nil)
+	return msgHash, nil
+}
@@ -0,0 +1,80 @@
+package legendTxTypes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	curve "github.com/bnb-chain/zkbas-crypto/ecc/ztwistededwards/tebn254"
+)
+
+func TestValidateChangePubKeyTxInfo(t *testing.T) {
+	sk, err := curve.GenerateEddsaPrivateKey("sher.legend")
+	require.NoError(t, err)
+	pubKey := hex.EncodeToString(sk.PublicKey.Bytes())
+	testCases := []struct {
+		err      error
+		testCase *ChangePubKeyTxInfo
+	}{
+		// AccountIndex
+		{
+			fmt.Errorf("AccountIndex should not be less than %d", minAccountIndex),
+			&ChangePubKeyTxInfo{
+				AccountIndex: minAccountIndex - 1,
+			},
+		},
+		// PubKey
+		{
+			fmt.Errorf("PubKey is invalid"),
+			&ChangePubKeyTxInfo{
+				AccountIndex: 1,
+				PubKey:       "00",
+			},
+		},
+		// GasFeeAssetAmount
+		{
+			fmt.Errorf("GasFeeAssetAmount should not be nil"),
+			&ChangePubKeyTxInfo{
+				AccountIndex:    1,
+				PubKey:          pubKey,
+				GasAccountIndex: 0,
+				GasFeeAssetId:   3,
+			},
+		},
+		// true
+		{
+			nil,
+			&ChangePubKeyTxInfo{
+				AccountIndex:      1,
+				PubKey:            pubKey,
+				GasAccountIndex:   0,
+				GasFeeAssetId:     3,
+				GasFeeAssetAmount: big.NewInt(100),
+				Nonce:             1,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := testCase.testCase.Validate()
+		require.Equalf(t, err, testCase.err, "err should be the same")
+	}
+}
+
+func TestChangePubKeySignature(t *testing.T) {
+	sk, err := curve.GenerateEddsaPrivateKey("sher.legend")
+	require.NoError(t, err)
+	newSk, err := curve.GenerateEddsaPrivateKey("sher.legend.new")
+	require.NoError(t, err)
+	segment := fmt.Sprintf(`{"account_index":1,"pub_key":"%x","gas_account_index":0,"gas_fee_asset_id":0,`+
+		`"gas_fee_asset_amount":"100","expired_at":1654656781000,"nonce":1}`, newSk.PublicKey.Bytes())
+	txInfo, err := ConstructChangePubKeyTxInfo(sk, segment)
+	require.NoError(t, err)
+	require.NoError(t, txInfo.Validate())
+	// signed by the current key, not by the new one
+	require.NoError(t, txInfo.VerifySignature(hex.EncodeToString(sk.PublicKey.Bytes())))
+	require.Error(t, txInfo.VerifySignature(txInfo.PubKey))
+}
@@ -61,7 +61,13 @@ const (
 	TxTypeWithdrawNft
 	TxTypeFullExit
 	TxTypeFullExitNft
+	TxTypeChangePubKey
+	TxTypeFullChangePubKey
+	TxTypeMatchOrder
+	TxTypeRouteSwap
+	TxTypeCancelOrder
 	TxTypeOffer
+	TxTypeOrder
 )
 
 const (
@@ -70,8 +76,10 @@ const (
 	minAccountIndex int64 = 0
 	maxAccountIndex int64 = (1 << 32) - 1
 
+	// fungible assets are listed from 0, the asset leaves above them are the
+	// order slots so that an order is never filled in the leaf of a live asset
 	minAssetId int64 = 0
-	maxAssetId int64 = (1 << 16) - 1
+	maxAssetId int64 = (1 << 15) - 1
 
 	minNftIndex int64 = 0
 	maxNftIndex int64 = (1 << 40) - 1
@@ -91,6 +99,40 @@ const (
 
 	minPairIndex = 0
 	maxPairIndex = (1 << 16) - 1
+
+	minOrderId int64 = maxAssetId + 1
+	maxOrderId int64 = (1 << 16) - 1
+
+	// a route swap goes through 2 or 3 pairs
+	minRoutePairsCount = 2
+	maxRoutePairsCount = 3
+
+	// signatures required of a multi-signature account, its signer set has at
+	// most 2^SignerMerkleLevels keys
+	maxSignerThreshold = 3
+	SignerMerkleLevels = 3
+
+	// fee rates of the pairs are in basis points
+	RateBase int64 = 10000
+
+	// reserves of StableSwap pairs are kept in 76 bits by the circuit
+	stableSwapReserveBitsSize = 76
+)
+
+// curves of the pairs
+const (
+	// x * y = k
+	PairTypeConstantProduct = iota
+	// StableSwap invariant with an amplification coefficient
+	PairTypeStableSwap
+)
+
+// modes of a swap
+const (
+	// the amount in is signed with a min amount out
+	SwapModeExactInput = iota
+	// the amount out is signed with a max amount in
+	SwapModeExactOutput
 )
 
 var (
@@ -99,4 +141,7 @@ var (
 
 	minAssetAmount = big.NewInt(0)
 	maxAssetAmount = util.PackedAmountMaxAmount
+
+	// the filled amount of an order is kept in 125 bits of its order slot
+	maxOrderAmount = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 125), big.NewInt(1))
 )
@@ -10,6 +10,10 @@ type CreatePairTxInfo struct {
 	FeeRate              int64
 	TreasuryAccountIndex int64
 	TreasuryRate         int64
+	// PairTypeConstantProduct or PairTypeStableSwap, the amplification is only set
+	// for StableSwap pairs
+	PairType      int64
+	Amplification int64
 }
 
 func (txInfo *CreatePairTxInfo) GetTxType() int {
@@ -0,0 +1,34 @@
+package legendTxTypes
+
+type FullChangePubKeyTxInfo struct {
+	TxType uint8
+
+	// Get from layer1 events.
+	AccountIndex    int64
+	AccountNameHash []byte
+	PubKey          string
+}
+
+func (txInfo *FullChangePubKeyTxInfo) GetTxType() int {
+	return TxTypeFullChangePubKey
+}
+
+func (txInfo *FullChangePubKeyTxInfo) Validate() error {
+	return nil
+}
+
+func (txInfo *FullChangePubKeyTxInfo) VerifySignature(pubKey string) error {
+	return nil
+}
+
+func (txInfo *FullChangePubKeyTxInfo) GetFromAccountIndex() int64 {
+	return NilTxAccountIndex
+}
+
+func (txInfo *FullChangePubKeyTxInfo) GetNonce() int64 {
+	return NilNonce
+}
+
+func (txInfo *FullChangePubKeyTxInfo) GetExpiredAt() int64 {
+	return NilExpiredAt
+}
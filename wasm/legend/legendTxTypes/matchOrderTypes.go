@@ -0,0 +1,343 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package legendTxTypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+)
+
+type MatchOrderSegmentFormat struct {
+	AccountIndex int64 `json:"account_index"`
+	// OrderTxInfo Type
+	BuyOrder string `json:"buy_order"`
+	// OrderTxInfo Type
+	SellOrder         string `json:"sell_order"`
+	AssetAFillAmount  string `json:"asset_a_fill_amount"`
+	AssetBFillAmount  string `json:"asset_b_fill_amount"`
+	GasAccountIndex   int64  `json:"gas_account_index"`
+	GasFeeAssetId     int64  `json:"gas_fee_asset_id"`
+	GasFeeAssetAmount string `json:"gas_fee_asset_amount"`
+	Nonce             int64  `json:"nonce"`
+	// transaction amount +1 for fromAccountIndex
+	ExpiredAt int64 `json:"expired_at"`
+	// transaction expire time in milli-second type
+	// eg. current timestamp + 1 week
+}
+
+/*
+	ConstructMatchOrderTxInfo: construct match order tx, sign txInfo
+*/
+func ConstructMatchOrderTxInfo(sk *PrivateKey, segmentStr string) (txInfo *MatchOrderTxInfo, err error) {
+	var segmentFormat *MatchOrderSegmentFormat
+	err = json.Unmarshal([]byte(segmentStr), &segmentFormat)
+	if err != nil {
+		log.Println("[ConstructMatchOrderTxInfo] err info:", err)
+		return nil, err
+	}
+	assetAFillAmount, err := StringToBigInt(segmentFormat.AssetAFillAmount)
+	if err != nil {
+		log.Println("[ConstructMatchOrderTxInfo] unable to convert string to big int:", err)
+		return nil, err
+	}
+	assetAFillAmount, _ = CleanPackedAmount(assetAFillAmount)
+	assetBFillAmount, err := StringToBigInt(segmentFormat.AssetBFillAmount)
+	if err != nil {
+		log.Println("[ConstructMatchOrderTxInfo] unable to convert string to big int:", err)
+		return nil, err
+	}
+	assetBFillAmount, _ = CleanPackedAmount(assetBFillAmount)
+	gasFeeAmount, err := StringToBigInt(segmentFormat.GasFeeAssetAmount)
+	if err != nil {
+		log.Println("[ConstructMatchOrderTxInfo] unable to convert string to big int:", err)
+		return nil, err
+	}
+	gasFeeAmount, _ = CleanPackedFee(gasFeeAmount)
+	var (
+		buyOrder, sellOrder *OrderTxInfo
+	)
+	err = json.Unmarshal([]byte(segmentFormat.BuyOrder), &buyOrder)
+	if err != nil {
+		log.Println("[ConstructMatchOrderTxInfo] unable to unmarshal order", err.Error())
+		return nil, err
+	}
+	err = json.Unmarshal([]byte(segmentFormat.SellOrder), &sellOrder)
+	if err != nil {
+		log.Println("[ConstructMatchOrderTxInfo] unable to unmarshal order", err.Error())
+		return nil, err
+	}
+	txInfo = &MatchOrderTxInfo{
+		AccountIndex:      segmentFormat.AccountIndex,
+		BuyOrder:          buyOrder,
+		SellOrder:         sellOrder,
+		AssetAFillAmount:  assetAFillAmount,
+		AssetBFillAmount:  assetBFillAmount,
+		GasAccountIndex:   segmentFormat.GasAccountIndex,
+		GasFeeAssetId:     segmentFormat.GasFeeAssetId,
+		GasFeeAssetAmount: gasFeeAmount,
+		Nonce:             segmentFormat.Nonce,
+		ExpiredAt:         segmentFormat.ExpiredAt,
+		Sig:               nil,
+	}
+	// compute call data hash
+	hFunc := mimc.NewMiMC()
+	// compute msg hash
+	msgHash, err := ComputeMatchOrderMsgHash(txInfo, hFunc)
+	if err != nil {
+		log.Println("[ConstructMatchOrderTxInfo] unable to compute hash: ", err.Error())
+		return nil, err
+	}
+	// compute signature
+	hFunc.Reset()
+	sigBytes, err := sk.Sign(msgHash, hFunc)
+	if err != nil {
+		log.Println("[ConstructMatchOrderTxInfo] unable to sign:", err)
+		return nil, err
+	}
+	txInfo.Sig = sigBytes
+	return txInfo, nil
+}
+
+/*
+	MatchOrderTxInfo: settles AssetAFillAmount of asset A for AssetBFillAmount of asset B
+	between two orders, what is left of the orders can be filled by later matches
+*/
+type MatchOrderTxInfo struct {
+	AccountIndex      int64
+	BuyOrder          *OrderTxInfo
+	SellOrder         *OrderTxInfo
+	AssetAFillAmount  *big.Int
+	AssetBFillAmount  *big.Int
+	GasAccountIndex   int64
+	GasFeeAssetId     int64
+	GasFeeAssetAmount *big.Int
+	Nonce             int64
+	ExpiredAt         int64
+	Sig               []byte
+}
+
+func (txInfo *MatchOrderTxInfo) Validate() error {
+	// AccountIndex
+	if txInfo.AccountIndex < minAccountIndex {
+		return fmt.Errorf("AccountIndex should not be less than %d", minAccountIndex)
+	}
+	if txInfo.AccountIndex > maxAccountIndex {
+		return fmt.Errorf("AccountIndex should not be larger than %d", maxAccountIndex)
+	}
+
+	// BuyOrder
+	if txInfo.BuyOrder == nil {
+		return fmt.Errorf("BuyOrder should not be nil")
+	}
+	if err := txInfo.BuyOrder.Validate(); err != nil {
+		return fmt.Errorf("BuyOrder is invalid, %s", err.Error())
+	}
+	if txInfo.BuyOrder.Type != BuyOrderType {
+		return fmt.Errorf("BuyOrder should be a buy order")
+	}
+
+	// SellOrder
+	if txInfo.SellOrder == nil {
+		return fmt.Errorf("SellOrder should not be nil")
+	}
+	if err := txInfo.SellOrder.Validate(); err != nil {
+		return fmt.Errorf("SellOrder is invalid, %s", err.Error())
+	}
+	if txInfo.SellOrder.Type != SellOrderType {
+		return fmt.Errorf("SellOrder should be a sell order")
+	}
+	if txInfo.BuyOrder.AssetAId != txInfo.SellOrder.AssetAId || txInfo.BuyOrder.AssetBId != txInfo.SellOrder.AssetBId {
+		return fmt.Errorf("BuyOrder and SellOrder should trade the same assets")
+	}
+
+	// AssetAFillAmount
+	if txInfo.AssetAFillAmount == nil {
+		return fmt.Errorf("AssetAFillAmount should not be nil")
+	}
+	if txInfo.AssetAFillAmount.Cmp(big.NewInt(0)) <= 0 {
+		return fmt.Errorf("AssetAFillAmount should be larger than 0")
+	}
+	if txInfo.AssetAFillAmount.Cmp(maxOrderAmount) > 0 {
+		return fmt.Errorf("AssetAFillAmount should not be larger than %s", maxOrderAmount.String())
+	}
+
+	// AssetBFillAmount
+	if txInfo.AssetBFillAmount == nil {
+		return fmt.Errorf("AssetBFillAmount should not be nil")
+	}
+	if txInfo.AssetBFillAmount.Cmp(minAssetAmount) < 0 {
+		return fmt.Errorf("AssetBFillAmount should not be less than %s", minAssetAmount.String())
+	}
+	if txInfo.AssetBFillAmount.Cmp(maxOrderAmount) > 0 {
+		return fmt.Errorf("AssetBFillAmount should not be larger than %s", maxOrderAmount.String())
+	}
+
+	// GasAccountIndex
+	if txInfo.GasAccountIndex < minAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be less than %d", minAccountIndex)
+	}
+	if txInfo.GasAccountIndex > maxAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be larger than %d", maxAccountIndex)
+	}
+
+	// GasFeeAssetId
+	if txInfo.GasFeeAssetId < minAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be less than %d", minAssetId)
+	}
+	if txInfo.GasFeeAssetId > maxAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be larger than %d", maxAssetId)
+	}
+
+	// GasFeeAssetAmount
+	if txInfo.GasFeeAssetAmount == nil {
+		return fmt.Errorf("GasFeeAssetAmount should not be nil")
+	}
+	if txInfo.GasFeeAssetAmount.Cmp(minPackedFeeAmount) < 0 {
+		return fmt.Errorf("GasFeeAssetAmount should not be less than %s", minPackedFeeAmount.String())
+	}
+	if txInfo.GasFeeAssetAmount.Cmp(maxPackedFeeAmount) > 0 {
+		return fmt.Errorf("GasFeeAssetAmount should not be larger than %s", maxPackedFeeAmount.String())
+	}
+
+	// Nonce
+	if txInfo.Nonce < minNonce {
+		return fmt.Errorf("Nonce should not be less than %d", minNonce)
+	}
+
+	return nil
+}
+
+func (txInfo *MatchOrderTxInfo) VerifySignature(pubKey string) error {
+	// compute hash
+	hFunc := mimc.NewMiMC()
+	msgHash, err := ComputeMatchOrderMsgHash(txInfo, hFunc)
+	if err != nil {
+		return err
+	}
+	// verify signature
+	hFunc.Reset()
+	pk, err := ParsePublicKey(pubKey)
+	if err != nil {
+		return err
+	}
+	isValid, err := pk.Verify(txInfo.Sig, msgHash, hFunc)
+	if err != nil {
+		return err
+	}
+
+	if !isValid {
+		return errors.New("invalid signature")
+	}
+
+	return nil
+}
+
+func (txInfo *MatchOrderTxInfo) GetTxType() int {
+	return TxTypeMatchOrder
+}
+
+func (txInfo *MatchOrderTxInfo) GetFromAccountIndex() int64 {
+	return txInfo.AccountIndex
+}
+
+func (txInfo *MatchOrderTxInfo) GetNonce() int64 {
+	return txInfo.Nonce
+}
+
+func (txInfo *MatchOrderTxInfo) GetExpiredAt() int64 {
+	return txInfo.ExpiredAt
+}
+
+func writeOrderIntoBuf(buf *bytes.Buffer, order *OrderTxInfo) (err error) {
+	packedAssetAAmount, err := ToPackedAmount(order.AssetAAmount)
+	if err != nil {
+		log.Println("[ComputeMatchOrderMsgHash] unable to packed amount:", err.Error())
+		return err
+	}
+	packedAssetBAmount, err := ToPackedAmount(order.AssetBAmount)
+	if err != nil {
+		log.Println("[ComputeMatchOrderMsgHash] unable to packed amount:", err.Error())
+		return err
+	}
+	WriteInt64IntoBuf(buf, order.Type)
+	WriteInt64IntoBuf(buf, order.OrderId)
+	WriteInt64IntoBuf(buf, order.AccountIndex)
+	WriteInt64IntoBuf(buf, order.AssetAId)
+	WriteInt64IntoBuf(buf, order.AssetBId)
+	WriteInt64IntoBuf(buf, packedAssetAAmount)
+	WriteInt64IntoBuf(buf, packedAssetBAmount)
+	WriteInt64IntoBuf(buf, order.ExpiredAt)
+	sig := new(eddsa.Signature)
+	_, err = sig.SetBytes(order.Sig)
+	if err != nil {
+		log.Println("[ComputeMatchOrderMsgHash] unable to convert to sig: ", err.Error())
+		return err
+	}
+	buf.Write(sig.R.X.Marshal())
+	buf.Write(sig.R.Y.Marshal())
+	buf.Write(sig.S[:])
+	return nil
+}
+
+func ComputeMatchOrderMsgHash(txInfo *MatchOrderTxInfo, hFunc hash.Hash) (msgHash []byte, err error) {
+	hFunc.Reset()
+	var buf bytes.Buffer
+	packedAssetAFillAmount, err := ToPackedAmount(txInfo.AssetAFillAmount)
+	if err != nil {
+		log.Println("[ComputeMatchOrderMsgHash] unable to packed amount:", err.Error())
+		return nil, err
+	}
+	packedAssetBFillAmount, err := ToPackedAmount(txInfo.AssetBFillAmount)
+	if err != nil {
+		log.Println("[ComputeMatchOrderMsgHash] unable to packed amount:", err.Error())
+		return nil, err
+	}
+	packedFee, err := ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		log.Println("[ComputeMatchOrderMsgHash] unable to packed amount:", err.Error())
+		return nil, err
+	}
+	WriteInt64IntoBuf(&buf, txInfo.AccountIndex)
+	err = writeOrderIntoBuf(&buf, txInfo.BuyOrder)
+	if err != nil {
+		return nil, err
+	}
+	err = writeOrderIntoBuf(&buf, txInfo.SellOrder)
+	if err != nil {
+		return nil, err
+	}
+	WriteInt64IntoBuf(&buf, packedAssetAFillAmount)
+	WriteInt64IntoBuf(&buf, packedAssetBFillAmount)
+	WriteInt64IntoBuf(&buf, txInfo.GasAccountIndex)
+	WriteInt64IntoBuf(&buf, txInfo.GasFeeAssetId)
+	WriteInt64IntoBuf(&buf, packedFee)
+	WriteInt64IntoBuf(&buf, txInfo.ExpiredAt)
+	WriteInt64IntoBuf(&buf, txInfo.Nonce)
+	WriteInt64IntoBuf(&buf, ChainId)
+	hFunc.Write(buf.Bytes())
+	msgHash = hFunc.Sum(nil)
+	return msgHash, nil
+}
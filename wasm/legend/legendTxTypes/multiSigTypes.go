@@ -0,0 +1,291 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package legendTxTypes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+)
+
+/*
+	PartialSignature: signature of a tx by the key at SignerIndex of the signer set
+	of a multi-signature account
+*/
+type PartialSignature struct {
+	SignerIndex int64
+	PubKey      string
+	Sig         []byte
+}
+
+/*
+	MultiSigTxInfo: tx of a multi-signature account, the signature of the tx info
+	is replaced by the partial signatures of SignerThreshold distinct signers,
+	sorted by signer index
+*/
+type MultiSigTxInfo struct {
+	TxInfo
+	SignerPubKeys []string
+	Sigs          []*PartialSignature
+}
+
+/*
+	ComputeSignerRoot: root of the signer tree, a leaf is the MiMC of the key and
+	the leaves left are empty
+*/
+func ComputeSignerRoot(pubKeys []string) (root []byte, err error) {
+	nodes, err := signerLeaves(pubKeys)
+	if err != nil {
+		return nil, err
+	}
+	hFunc := mimc.NewMiMC()
+	for len(nodes) > 1 {
+		for i := 0; i < len(nodes)/2; i++ {
+			hFunc.Reset()
+			hFunc.Write(nodes[2*i])
+			hFunc.Write(nodes[2*i+1])
+			nodes[i] = hFunc.Sum(nil)
+		}
+		nodes = nodes[:len(nodes)/2]
+	}
+	return nodes[0], nil
+}
+
+/*
+	ComputeSignerMerkleProof: siblings of the leaf of the key at signerIndex, from
+	the leaf to the root
+*/
+func ComputeSignerMerkleProof(pubKeys []string, signerIndex int64) (proof [][]byte, err error) {
+	if signerIndex < 0 || signerIndex >= int64(len(pubKeys)) {
+		log.Println("[ComputeSignerMerkleProof] invalid signer index")
+		return nil, errors.New("[ComputeSignerMerkleProof] invalid signer index")
+	}
+	nodes, err := signerLeaves(pubKeys)
+	if err != nil {
+		return nil, err
+	}
+	hFunc := mimc.NewMiMC()
+	index := signerIndex
+	for len(nodes) > 1 {
+		proof = append(proof, nodes[index^1])
+		for i := 0; i < len(nodes)/2; i++ {
+			hFunc.Reset()
+			hFunc.Write(nodes[2*i])
+			hFunc.Write(nodes[2*i+1])
+			nodes[i] = hFunc.Sum(nil)
+		}
+		nodes = nodes[:len(nodes)/2]
+		index /= 2
+	}
+	return proof, nil
+}
+
+func signerLeaves(pubKeys []string) (leaves [][]byte, err error) {
+	if len(pubKeys) == 0 || len(pubKeys) > 1<<SignerMerkleLevels {
+		log.Println("[signerLeaves] invalid signer set size")
+		return nil, errors.New("[signerLeaves] invalid signer set size")
+	}
+	leaves = make([][]byte, 1<<SignerMerkleLevels)
+	hFunc := mimc.NewMiMC()
+	for i := range leaves {
+		leaves[i] = make([]byte, HashLength)
+		if i >= len(pubKeys) {
+			continue
+		}
+		pk, err := ParsePublicKey(pubKeys[i])
+		if err != nil {
+			log.Println("[signerLeaves] invalid public key:", err)
+			return nil, err
+		}
+		var buf bytes.Buffer
+		WriteBigIntIntoBuf(&buf, pk.A.X.ToBigIntRegular(new(big.Int)))
+		WriteBigIntIntoBuf(&buf, pk.A.Y.ToBigIntRegular(new(big.Int)))
+		hFunc.Reset()
+		hFunc.Write(buf.Bytes())
+		leaves[i] = hFunc.Sum(nil)
+	}
+	return leaves, nil
+}
+
+/*
+	ComputeTxMsgHash: message signed by the account of a layer2 tx
+*/
+func ComputeTxMsgHash(txInfo TxInfo) (msgHash []byte, err error) {
+	hFunc := mimc.NewMiMC()
+	switch txInfo := txInfo.(type) {
+	case *TransferTxInfo:
+		return ComputeTransferMsgHash(txInfo, hFunc)
+	case *SwapTxInfo:
+		return ComputeSwapMsgHash(txInfo, hFunc)
+	case *AddLiquidityTxInfo:
+		return ComputeAddLiquidityMsgHash(txInfo, hFunc)
+	case *RemoveLiquidityTxInfo:
+		return ComputeRemoveLiquidityMsgHash(txInfo, hFunc)
+	case *WithdrawTxInfo:
+		return ComputeWithdrawMsgHash(txInfo, hFunc)
+	case *CreateCollectionTxInfo:
+		return ComputeCreateCollectionMsgHash(txInfo, hFunc)
+	case *MintNftTxInfo:
+		return ComputeMintNftMsgHash(txInfo, hFunc)
+	case *TransferNftTxInfo:
+		return ComputeTransferNftMsgHash(txInfo, hFunc)
+	case *AtomicMatchTxInfo:
+		return ComputeAtomicMatchMsgHash(txInfo, hFunc)
+	case *CancelOfferTxInfo:
+		return ComputeCancelOfferMsgHash(txInfo, hFunc)
+	case *WithdrawNftTxInfo:
+		return ComputeWithdrawNftMsgHash(txInfo, hFunc)
+	case *ChangePubKeyTxInfo:
+		return ComputeChangePubKeyMsgHash(txInfo, hFunc)
+	case *MatchOrderTxInfo:
+		return ComputeMatchOrderMsgHash(txInfo, hFunc)
+	case *RouteSwapTxInfo:
+		return ComputeRouteSwapMsgHash(txInfo, hFunc)
+	case *CancelOrderTxInfo:
+		return ComputeCancelOrderMsgHash(txInfo, hFunc)
+	}
+	log.Println("[ComputeTxMsgHash] invalid tx type")
+	return nil, errors.New("[ComputeTxMsgHash] invalid tx type")
+}
+
+/*
+	SignPartialSignature: sign the tx with the key at signerIndex of the signer set
+*/
+func SignPartialSignature(sk *PrivateKey, signerIndex int64, txInfo TxInfo) (partialSig *PartialSignature, err error) {
+	msgHash, err := ComputeTxMsgHash(txInfo)
+	if err != nil {
+		log.Println("[SignPartialSignature] unable to compute hash:", err)
+		return nil, err
+	}
+	sigBytes, err := sk.Sign(msgHash, mimc.NewMiMC())
+	if err != nil {
+		log.Println("[SignPartialSignature] unable to sign:", err)
+		return nil, err
+	}
+	return &PartialSignature{
+		SignerIndex: signerIndex,
+		PubKey:      fmt.Sprintf("%x", sk.PublicKey.Bytes()),
+		Sig:         sigBytes,
+	}, nil
+}
+
+/*
+	GatherPartialSignatures: keep the valid partial signatures of distinct signers
+	of the set, the tx is signed once threshold of them are gathered
+*/
+func GatherPartialSignatures(
+	txInfo TxInfo, signerPubKeys []string, threshold int64, partialSigs []*PartialSignature,
+) (multiSigTxInfo *MultiSigTxInfo, err error) {
+	if threshold <= 0 || threshold > maxSignerThreshold {
+		log.Println("[GatherPartialSignatures] invalid threshold")
+		return nil, errors.New("[GatherPartialSignatures] invalid threshold")
+	}
+	multiSigTxInfo = &MultiSigTxInfo{
+		TxInfo:        txInfo,
+		SignerPubKeys: signerPubKeys,
+	}
+	isSigned := make(map[int64]bool)
+	for _, partialSig := range partialSigs {
+		if partialSig == nil || isSigned[partialSig.SignerIndex] {
+			continue
+		}
+		if multiSigTxInfo.verifyPartialSignature(partialSig) != nil {
+			continue
+		}
+		isSigned[partialSig.SignerIndex] = true
+		multiSigTxInfo.Sigs = append(multiSigTxInfo.Sigs, partialSig)
+	}
+	if int64(len(multiSigTxInfo.Sigs)) < threshold {
+		log.Println("[GatherPartialSignatures] not enough partial signatures")
+		return nil, errors.New("[GatherPartialSignatures] not enough partial signatures")
+	}
+	sort.Slice(multiSigTxInfo.Sigs, func(i, j int) bool {
+		return multiSigTxInfo.Sigs[i].SignerIndex < multiSigTxInfo.Sigs[j].SignerIndex
+	})
+	multiSigTxInfo.Sigs = multiSigTxInfo.Sigs[:threshold]
+	return multiSigTxInfo, nil
+}
+
+func (txInfo *MultiSigTxInfo) Validate() error {
+	if err := txInfo.TxInfo.Validate(); err != nil {
+		return err
+	}
+
+	// Sigs
+	if len(txInfo.Sigs) == 0 {
+		return fmt.Errorf("Sigs should not be empty")
+	}
+	if len(txInfo.Sigs) > maxSignerThreshold {
+		return fmt.Errorf("Sigs should not be more than %d", maxSignerThreshold)
+	}
+	for i := 1; i < len(txInfo.Sigs); i++ {
+		if txInfo.Sigs[i-1].SignerIndex >= txInfo.Sigs[i].SignerIndex {
+			return fmt.Errorf("Sigs should be sorted by distinct signer indexes")
+		}
+	}
+
+	return nil
+}
+
+/*
+	VerifySignature: the signer set of the account is checked against its signer
+	root, in place of the key of a single key account
+*/
+func (txInfo *MultiSigTxInfo) VerifySignature(signerRoot string) error {
+	root, err := ComputeSignerRoot(txInfo.SignerPubKeys)
+	if err != nil {
+		return err
+	}
+	if fmt.Sprintf("%x", root) != signerRoot {
+		return errors.New("invalid signer set")
+	}
+	for _, partialSig := range txInfo.Sigs {
+		if err = txInfo.verifyPartialSignature(partialSig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (txInfo *MultiSigTxInfo) verifyPartialSignature(partialSig *PartialSignature) error {
+	if partialSig.SignerIndex < 0 || partialSig.SignerIndex >= int64(len(txInfo.SignerPubKeys)) ||
+		txInfo.SignerPubKeys[partialSig.SignerIndex] != partialSig.PubKey {
+		return errors.New("invalid signer")
+	}
+	msgHash, err := ComputeTxMsgHash(txInfo.TxInfo)
+	if err != nil {
+		return err
+	}
+	pk, err := ParsePublicKey(partialSig.PubKey)
+	if err != nil {
+		return err
+	}
+	isValid, err := pk.Verify(partialSig.Sig, msgHash, mimc.NewMiMC())
+	if err != nil {
+		return err
+	}
+	if !isValid {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
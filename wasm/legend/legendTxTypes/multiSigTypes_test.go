@@ -0,0 +1,122 @@
+package legendTxTypes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/stretchr/testify/require"
+
+	curve "github.com/bnb-chain/zkbas-crypto/ecc/ztwistededwards/tebn254"
+)
+
+func TestSignerMerkleProof(t *testing.T) {
+	var pubKeys []string
+	for i := 0; i < 3; i++ {
+		sk, err := curve.GenerateEddsaPrivateKey(fmt.Sprintf("signer%d.legend", i))
+		require.NoError(t, err)
+		pubKeys = append(pubKeys, hex.EncodeToString(sk.PublicKey.Bytes()))
+	}
+	root, err := ComputeSignerRoot(pubKeys)
+	require.NoError(t, err)
+	leaves, err := signerLeaves(pubKeys)
+	require.NoError(t, err)
+	hFunc := mimc.NewMiMC()
+	for signerIndex := range pubKeys {
+		proof, err := ComputeSignerMerkleProof(pubKeys, int64(signerIndex))
+		require.NoError(t, err)
+		require.Equal(t, SignerMerkleLevels, len(proof))
+		node := leaves[signerIndex]
+		for i, sibling := range proof {
+			hFunc.Reset()
+			if signerIndex>>i&1 == 1 {
+				hFunc.Write(sibling)
+				hFunc.Write(node)
+			} else {
+				hFunc.Write(node)
+				hFunc.Write(sibling)
+			}
+			node = hFunc.Sum(nil)
+		}
+		require.Equal(t, root, node)
+	}
+	_, err = ComputeSignerMerkleProof(pubKeys, 3)
+	require.Error(t, err)
+}
+
+func TestGatherPartialSignatures(t *testing.T) {
+	var (
+		sks     []*PrivateKey
+		pubKeys []string
+	)
+	for i := 0; i < 3; i++ {
+		sk, err := curve.GenerateEddsaPrivateKey(fmt.Sprintf("signer%d.legend", i))
+		require.NoError(t, err)
+		sks = append(sks, sk)
+		pubKeys = append(pubKeys, hex.EncodeToString(sk.PublicKey.Bytes()))
+	}
+	signerRoot, err := ComputeSignerRoot(pubKeys)
+	require.NoError(t, err)
+	segment := fmt.Sprintf(`{"from_account_index":1,"to_account_index":2,"to_account_name":"%x",`+
+		`"asset_id":0,"asset_amount":"10000","gas_account_index":0,"gas_fee_asset_id":0,`+
+		`"gas_fee_asset_amount":"100","memo":"","call_data":"","expired_at":1654656781000,"nonce":1}`,
+		PaddingStringToBytes32("gavin.legend"))
+	txInfo, err := ConstructTransferTxInfo(sks[0], segment)
+	require.NoError(t, err)
+
+	var partialSigs []*PartialSignature
+	for _, signerIndex := range []int64{2, 0, 2} {
+		partialSig, err := SignPartialSignature(sks[signerIndex], signerIndex, txInfo)
+		require.NoError(t, err)
+		partialSigs = append(partialSigs, partialSig)
+	}
+	// a key signing at the index of another signer is dropped
+	invalidSig, err := SignPartialSignature(sks[0], 1, txInfo)
+	require.NoError(t, err)
+	partialSigs = append(partialSigs, invalidSig)
+
+	multiSigTxInfo, err := GatherPartialSignatures(txInfo, pubKeys, 2, partialSigs)
+	require.NoError(t, err)
+	require.NoError(t, multiSigTxInfo.Validate())
+	require.Equal(t, int64(0), multiSigTxInfo.Sigs[0].SignerIndex)
+	require.Equal(t, int64(2), multiSigTxInfo.Sigs[1].SignerIndex)
+	require.NoError(t, multiSigTxInfo.VerifySignature(hex.EncodeToString(signerRoot)))
+	require.Error(t, multiSigTxInfo.VerifySignature(pubKeys[0]))
+
+	// the same signer twice doesn't reach a threshold of 3
+	_, err = GatherPartialSignatures(txInfo, pubKeys, 3, partialSigs)
+	require.Error(t, err)
+}
+
+func TestChangePubKeySignerSet(t *testing.T) {
+	sk, err := curve.GenerateEddsaPrivateKey("sher.legend")
+	require.NoError(t, err)
+	var pubKeys []string
+	for i := 0; i < 3; i++ {
+		signerSk, err := curve.GenerateEddsaPrivateKey(fmt.Sprintf("signer%d.legend", i))
+		require.NoError(t, err)
+		pubKeys = append(pubKeys, fmt.Sprintf(`"%x"`, signerSk.PublicKey.Bytes()))
+	}
+	segment := fmt.Sprintf(`{"account_index":1,"pub_key":"","gas_account_index":0,"gas_fee_asset_id":0,`+
+		`"gas_fee_asset_amount":"100","expired_at":1654656781000,"nonce":1,`+
+		`"signer_pub_keys":[%s,%s,%s],"signer_threshold":2}`, pubKeys[0], pubKeys[1], pubKeys[2])
+	txInfo, err := ConstructChangePubKeyTxInfo(sk, segment)
+	require.NoError(t, err)
+	require.NoError(t, txInfo.Validate())
+	require.NoError(t, txInfo.VerifySignature(hex.EncodeToString(sk.PublicKey.Bytes())))
+
+	// the signer set is signed
+	msgHash, err := ComputeTxMsgHash(txInfo)
+	require.NoError(t, err)
+	txInfo.SignerThreshold = 3
+	otherMsgHash, err := ComputeTxMsgHash(txInfo)
+	require.NoError(t, err)
+	require.NotEqual(t, msgHash, otherMsgHash)
+
+	txInfo.SignerThreshold = 4
+	require.Equal(t, fmt.Errorf("SignerThreshold should not be larger than %d", maxSignerThreshold), txInfo.Validate())
+	txInfo.SignerThreshold = 2
+	txInfo.PubKey = hex.EncodeToString(sk.PublicKey.Bytes())
+	require.Equal(t, fmt.Errorf("PubKey should be empty for a signer set"), txInfo.Validate())
+}
@@ -0,0 +1,245 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package legendTxTypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+)
+
+const (
+	BuyOrderType  = 0
+	SellOrderType = 1
+)
+
+type OrderSegmentFormat struct {
+	Type         int64  `json:"type"`
+	OrderId      int64  `json:"order_id"`
+	AccountIndex int64  `json:"account_index"`
+	AssetAId     int64  `json:"asset_a_id"`
+	AssetBId     int64  `json:"asset_b_id"`
+	AssetAAmount string `json:"asset_a_amount"`
+	AssetBAmount string `json:"asset_b_amount"`
+	ExpiredAt    int64  `json:"expired_at"`
+}
+
+/*
+	ConstructOrderTxInfo: construct limit order, sign txInfo
+*/
+func ConstructOrderTxInfo(sk *PrivateKey, segmentStr string) (txInfo *OrderTxInfo, err error) {
+	var segmentFormat *OrderSegmentFormat
+	err = json.Unmarshal([]byte(segmentStr), &segmentFormat)
+	if err != nil {
+		log.Println("[ConstructOrderTxInfo] err info:", err)
+		return nil, err
+	}
+	assetAAmount, err := StringToBigInt(segmentFormat.AssetAAmount)
+	if err != nil {
+		log.Println("[ConstructOrderTxInfo] unable to convert string to big int:", err)
+		return nil, err
+	}
+	assetAAmount, _ = CleanPackedAmount(assetAAmount)
+	assetBAmount, err := StringToBigInt(segmentFormat.AssetBAmount)
+	if err != nil {
+		log.Println("[ConstructOrderTxInfo] unable to convert string to big int:", err)
+		return nil, err
+	}
+	assetBAmount, _ = CleanPackedAmount(assetBAmount)
+	txInfo = &OrderTxInfo{
+		Type:         segmentFormat.Type,
+		OrderId:      segmentFormat.OrderId,
+		AccountIndex: segmentFormat.AccountIndex,
+		AssetAId:     segmentFormat.AssetAId,
+		AssetBId:     segmentFormat.AssetBId,
+		AssetAAmount: assetAAmount,
+		AssetBAmount: assetBAmount,
+		ExpiredAt:    segmentFormat.ExpiredAt,
+		Sig:          nil,
+	}
+	// compute call data hash
+	hFunc := mimc.NewMiMC()
+	// compute msg hash
+	msgHash, err := ComputeOrderMsgHash(txInfo, hFunc)
+	if err != nil {
+		return nil, err
+	}
+	// compute signature
+	hFunc.Reset()
+	sigBytes, err := sk.Sign(msgHash, hFunc)
+	if err != nil {
+		log.Println("[ConstructOrderTxInfo] unable to sign:", err)
+		return nil, err
+	}
+	txInfo.Sig = sigBytes
+	return txInfo, nil
+}
+
+/*
+	OrderTxInfo: limit order of AssetAAmount of asset A for AssetBAmount of asset B,
+	the buy order pays at most and the sell order gets at least this price
+*/
+type OrderTxInfo struct {
+	Type         int64
+	OrderId      int64
+	AccountIndex int64
+	AssetAId     int64
+	AssetBId     int64
+	AssetAAmount *big.Int
+	AssetBAmount *big.Int
+	ExpiredAt    int64
+	Sig          []byte
+}
+
+func (txInfo *OrderTxInfo) Validate() error {
+	// Type
+	if txInfo.Type != BuyOrderType && txInfo.Type != SellOrderType {
+		return fmt.Errorf("Type should only be buy(%d) and sell(%d)", BuyOrderType, SellOrderType)
+	}
+
+	// OrderId
+	if txInfo.OrderId < minOrderId {
+		return fmt.Errorf("OrderId should not be less than %d", minOrderId)
+	}
+	if txInfo.OrderId > maxOrderId {
+		return fmt.Errorf("OrderId should not be larger than %d", maxOrderId)
+	}
+
+	// AccountIndex
+	if txInfo.AccountIndex < minAccountIndex {
+		return fmt.Errorf("AccountIndex should not be less than %d", minAccountIndex)
+	}
+	if txInfo.AccountIndex > maxAccountIndex {
+		return fmt.Errorf("AccountIndex should not be larger than %d", maxAccountIndex)
+	}
+
+	// AssetAId
+	if txInfo.AssetAId < minAssetId {
+		return fmt.Errorf("AssetAId should not be less than %d", minAssetId)
+	}
+	if txInfo.AssetAId > maxAssetId {
+		return fmt.Errorf("AssetAId should not be larger than %d", maxAssetId)
+	}
+
+	// AssetBId
+	if txInfo.AssetBId < minAssetId {
+		return fmt.Errorf("AssetBId should not be less than %d", minAssetId)
+	}
+	if txInfo.AssetBId > maxAssetId {
+		return fmt.Errorf("AssetBId should not be larger than %d", maxAssetId)
+	}
+	if txInfo.AssetAId == txInfo.AssetBId {
+		return fmt.Errorf("AssetAId and AssetBId should be different")
+	}
+
+	// AssetAAmount
+	if txInfo.AssetAAmount == nil {
+		return fmt.Errorf("AssetAAmount should not be nil")
+	}
+	if txInfo.AssetAAmount.Cmp(big.NewInt(0)) <= 0 {
+		return fmt.Errorf("AssetAAmount should be larger than 0")
+	}
+	if txInfo.AssetAAmount.Cmp(maxOrderAmount) > 0 {
+		return fmt.Errorf("AssetAAmount should not be larger than %s", maxOrderAmount.String())
+	}
+
+	// AssetBAmount
+	if txInfo.AssetBAmount == nil {
+		return fmt.Errorf("AssetBAmount should not be nil")
+	}
+	if txInfo.AssetBAmount.Cmp(minAssetAmount) < 0 {
+		return fmt.Errorf("AssetBAmount should not be less than %s", minAssetAmount.String())
+	}
+	if txInfo.AssetBAmount.Cmp(maxOrderAmount) > 0 {
+		return fmt.Errorf("AssetBAmount should not be larger than %s", maxOrderAmount.String())
+	}
+	return nil
+}
+
+func (txInfo *OrderTxInfo) VerifySignature(pubKey string) error {
+	// compute hash
+	hFunc := mimc.NewMiMC()
+	msgHash, err := ComputeOrderMsgHash(txInfo, hFunc)
+	if err != nil {
+		return err
+	}
+	// verify signature
+	hFunc.Reset()
+	pk, err := ParsePublicKey(pubKey)
+	if err != nil {
+		return err
+	}
+	isValid, err := pk.Verify(txInfo.Sig, msgHash, hFunc)
+	if err != nil {
+		return err
+	}
+
+	if !isValid {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func (txInfo *OrderTxInfo) GetTxType() int {
+	return TxTypeOrder
+}
+
+func (txInfo *OrderTxInfo) GetFromAccountIndex() int64 {
+	return txInfo.AccountIndex
+}
+
+func (txInfo *OrderTxInfo) GetNonce() int64 {
+	return NilNonce
+}
+
+func (txInfo *OrderTxInfo) GetExpiredAt() int64 {
+	return txInfo.ExpiredAt
+}
+
+func ComputeOrderMsgHash(txInfo *OrderTxInfo, hFunc hash.Hash) (msgHash []byte, err error) {
+	hFunc.Reset()
+	var buf bytes.Buffer
+	packedAssetAAmount, err := ToPackedAmount(txInfo.AssetAAmount)
+	if err != nil {
+		log.Println("[ComputeOrderMsgHash] unable to packed amount:", err.Error())
+		return nil, err
+	}
+	packedAssetBAmount, err := ToPackedAmount(txInfo.AssetBAmount)
+	if err != nil {
+		log.Println("[ComputeOrderMsgHash] unable to packed amount:", err.Error())
+		return nil, err
+	}
+	WriteInt64IntoBuf(&buf, txInfo.Type)
+	WriteInt64IntoBuf(&buf, txInfo.OrderId)
+	WriteInt64IntoBuf(&buf, txInfo.AccountIndex)
+	WriteInt64IntoBuf(&buf, txInfo.AssetAId)
+	WriteInt64IntoBuf(&buf, txInfo.AssetBId)
+	WriteInt64IntoBuf(&buf, packedAssetAAmount)
+	WriteInt64IntoBuf(&buf, packedAssetBAmount)
+	WriteInt64IntoBuf(&buf, txInfo.ExpiredAt)
+	WriteInt64IntoBuf(&buf, ChainId)
+	hFunc.Write(buf.Bytes())
+	msgHash = hFunc.Sum(nil)
+	return msgHash, nil
+}
@@ -0,0 +1,134 @@
+package legendTxTypes
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	curve "github.com/bnb-chain/zkbas-crypto/ecc/ztwistededwards/tebn254"
+)
+
+func TestValidateOrderTxInfo(t *testing.T) {
+	testCases := []struct {
+		err      error
+		testCase *OrderTxInfo
+	}{
+		// Type
+		{
+			fmt.Errorf("Type should only be buy(%d) and sell(%d)", BuyOrderType, SellOrderType),
+			&OrderTxInfo{
+				Type: 3,
+			},
+		},
+		// OrderId, the fungible asset ids aren't order slots
+		{
+			fmt.Errorf("OrderId should not be less than %d", minOrderId),
+			&OrderTxInfo{
+				Type:    BuyOrderType,
+				OrderId: 2,
+			},
+		},
+		{
+			fmt.Errorf("OrderId should not be larger than %d", maxOrderId),
+			&OrderTxInfo{
+				Type:    BuyOrderType,
+				OrderId: maxOrderId + 1,
+			},
+		},
+		// AssetBId
+		{
+			fmt.Errorf("AssetAId and AssetBId should be different"),
+			&OrderTxInfo{
+				Type:         BuyOrderType,
+				OrderId:      minOrderId,
+				AccountIndex: 2,
+				AssetAId:     1,
+				AssetBId:     1,
+			},
+		},
+		// AssetAAmount
+		{
+			fmt.Errorf("AssetAAmount should be larger than 0"),
+			&OrderTxInfo{
+				Type:         BuyOrderType,
+				OrderId:      minOrderId,
+				AccountIndex: 2,
+				AssetAId:     1,
+				AssetBId:     2,
+				AssetAAmount: big.NewInt(0),
+			},
+		},
+		{
+			fmt.Errorf("AssetAAmount should not be larger than %s", maxOrderAmount.String()),
+			&OrderTxInfo{
+				Type:         BuyOrderType,
+				OrderId:      minOrderId,
+				AccountIndex: 2,
+				AssetAId:     1,
+				AssetBId:     2,
+				AssetAAmount: new(big.Int).Add(maxOrderAmount, big.NewInt(1)),
+			},
+		},
+		// true
+		{
+			nil,
+			&OrderTxInfo{
+				Type:         SellOrderType,
+				OrderId:      minOrderId,
+				AccountIndex: 2,
+				AssetAId:     1,
+				AssetBId:     2,
+				AssetAAmount: big.NewInt(1000),
+				AssetBAmount: big.NewInt(2000),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := testCase.testCase.Validate()
+		require.Equalf(t, err, testCase.err, "err should be the same")
+	}
+}
+
+func TestMatchOrderSignature(t *testing.T) {
+	buyerSk, err := curve.GenerateEddsaPrivateKey("sher.legend")
+	require.NoError(t, err)
+	sellerSk, err := curve.GenerateEddsaPrivateKey("gavin.legend")
+	require.NoError(t, err)
+	buyOrder, err := ConstructOrderTxInfo(buyerSk, `{"type":0,"order_id":32768,"account_index":2,"asset_a_id":1,`+
+		`"asset_b_id":2,"asset_a_amount":"1000","asset_b_amount":"2000","expired_at":1654656781000}`)
+	require.NoError(t, err)
+	require.NoError(t, buyOrder.VerifySignature(hex.EncodeToString(buyerSk.PublicKey.Bytes())))
+	require.Error(t, buyOrder.VerifySignature(hex.EncodeToString(sellerSk.PublicKey.Bytes())))
+	sellOrder, err := ConstructOrderTxInfo(sellerSk, `{"type":1,"order_id":32769,"account_index":3,"asset_a_id":1,`+
+		`"asset_b_id":2,"asset_a_amount":"500","asset_b_amount":"900","expired_at":1654656781000}`)
+	require.NoError(t, err)
+	buyOrderBytes, err := json.Marshal(buyOrder)
+	require.NoError(t, err)
+	sellOrderBytes, err := json.Marshal(sellOrder)
+	require.NoError(t, err)
+	segment, err := json.Marshal(&MatchOrderSegmentFormat{
+		AccountIndex:      2,
+		BuyOrder:          string(buyOrderBytes),
+		SellOrder:         string(sellOrderBytes),
+		AssetAFillAmount:  "400",
+		AssetBFillAmount:  "780",
+		GasAccountIndex:   1,
+		GasFeeAssetId:     0,
+		GasFeeAssetAmount: "10",
+		Nonce:             1,
+		ExpiredAt:         1654656781000,
+	})
+	require.NoError(t, err)
+	txInfo, err := ConstructMatchOrderTxInfo(buyerSk, string(segment))
+	require.NoError(t, err)
+	require.NoError(t, txInfo.Validate())
+	require.NoError(t, txInfo.VerifySignature(hex.EncodeToString(buyerSk.PublicKey.Bytes())))
+	// the sell order can't be matched as a buy order
+	txInfo.BuyOrder, txInfo.SellOrder = txInfo.SellOrder, txInfo.BuyOrder
+	require.Error(t, txInfo.Validate())
+}
@@ -0,0 +1,439 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package legendTxTypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+)
+
+type RouteSwapSegmentFormat struct {
+	FromAccountIndex int64 `json:"from_account_index"`
+	// pairs of the route, from asset a to asset b
+	PairIndexes       []int64 `json:"pair_indexes"`
+	AssetAId          int64   `json:"asset_a_id"`
+	AssetAAmount      string  `json:"asset_a_amount"`
+	AssetBId          int64   `json:"asset_b_id"`
+	AssetBMinAmount   string  `json:"asset_b_min_amount"`
+	AssetBAmountDelta string  `json:"asset_b_amount_delta"`
+	// amounts out of the pairs before the last one
+	HopAmounts        []string `json:"hop_amounts"`
+	GasAccountIndex   int64    `json:"gas_account_index"`
+	GasFeeAssetId     int64    `json:"gas_fee_asset_id"`
+	GasFeeAssetAmount string   `json:"gas_fee_asset_amount"`
+	ExpiredAt         int64    `json:"expired_at"`
+	Nonce             int64    `json:"nonce"`
+}
+
+/*
+	ConstructRouteSwapTxInfo: construct route swap tx, sign txInfo
+*/
+func ConstructRouteSwapTxInfo(sk *PrivateKey, segmentStr string) (txInfo *RouteSwapTxInfo, err error) {
+	var segmentFormat *RouteSwapSegmentFormat
+	err = json.Unmarshal([]byte(segmentStr), &segmentFormat)
+	if err != nil {
+		log.Println("[ConstructRouteSwapTxInfo] err info:", err)
+		return nil, err
+	}
+	assetAAmount, err := StringToBigInt(segmentFormat.AssetAAmount)
+	if err != nil {
+		log.Println("[ConstructRouteSwapTxInfo] unable to convert string to big int:", err)
+		return nil, err
+	}
+	assetAAmount, _ = CleanPackedAmount(assetAAmount)
+	assetBMinAmount, err := StringToBigInt(segmentFormat.AssetBMinAmount)
+	if err != nil {
+		log.Println("[ConstructRouteSwapTxInfo] unable to convert string to big int:", err)
+		return nil, err
+	}
+	assetBMinAmount, _ = CleanPackedAmount(assetBMinAmount)
+	assetBAmountDelta, err := StringToBigInt(segmentFormat.AssetBAmountDelta)
+	if err != nil {
+		log.Println("[ConstructRouteSwapTxInfo] unable to convert string to big int:", err)
+		return nil, err
+	}
+	assetBAmountDelta, _ = CleanPackedAmount(assetBAmountDelta)
+	hopAmounts := make([]*big.Int, len(segmentFormat.HopAmounts))
+	for i, hopAmountStr := range segmentFormat.HopAmounts {
+		hopAmount, err := StringToBigInt(hopAmountStr)
+		if err != nil {
+			log.Println("[ConstructRouteSwapTxInfo] unable to convert string to big int:", err)
+			return nil, err
+		}
+		hopAmounts[i], _ = CleanPackedAmount(hopAmount)
+	}
+	gasFeeAmount, err := StringToBigInt(segmentFormat.GasFeeAssetAmount)
+	if err != nil {
+		log.Println("[ConstructRouteSwapTxInfo] unable to convert string to big int:", err)
+		return nil, err
+	}
+	gasFeeAmount, _ = CleanPackedFee(gasFeeAmount)
+	txInfo = &RouteSwapTxInfo{
+		FromAccountIndex:  segmentFormat.FromAccountIndex,
+		PairIndexes:       segmentFormat.PairIndexes,
+		AssetAId:          segmentFormat.AssetAId,
+		AssetAAmount:      assetAAmount,
+		AssetBId:          segmentFormat.AssetBId,
+		AssetBMinAmount:   assetBMinAmount,
+		AssetBAmountDelta: assetBAmountDelta,
+		HopAmounts:        hopAmounts,
+		GasAccountIndex:   segmentFormat.GasAccountIndex,
+		GasFeeAssetId:     segmentFormat.GasFeeAssetId,
+		GasFeeAssetAmount: gasFeeAmount,
+		Nonce:             segmentFormat.Nonce,
+		ExpiredAt:         segmentFormat.ExpiredAt,
+		Sig:               nil,
+	}
+	hFunc := mimc.NewMiMC()
+	msgHash, err := ComputeRouteSwapMsgHash(txInfo, hFunc)
+	if err != nil {
+		log.Println("[ConstructRouteSwapTxInfo] unable to compute hash:", err)
+		return nil, err
+	}
+	hFunc.Reset()
+	sigBytes, err := sk.Sign(msgHash, hFunc)
+	if err != nil {
+		log.Println("[ConstructRouteSwapTxInfo] unable to sign:", err)
+		return nil, err
+	}
+	txInfo.Sig = sigBytes
+	return txInfo, nil
+}
+
+/*
+	RouteSwapTxInfo: swap of asset a for asset b through 2 or 3 pairs, the asset
+	out of a pair is the asset in of the next one. Only the min amount of asset b
+	is signed, the hop amounts and the amount delta are set by the sequencer.
+*/
+type RouteSwapTxInfo struct {
+	FromAccountIndex  int64
+	PairIndexes       []int64
+	AssetAId          int64
+	AssetAAmount      *big.Int
+	AssetBId          int64
+	AssetBMinAmount   *big.Int
+	AssetBAmountDelta *big.Int
+	HopAmounts        []*big.Int
+	GasAccountIndex   int64
+	GasFeeAssetId     int64
+	GasFeeAssetAmount *big.Int
+	ExpiredAt         int64
+	Nonce             int64
+	Sig               []byte
+}
+
+func (txInfo *RouteSwapTxInfo) Validate() error {
+	if txInfo.FromAccountIndex < minAccountIndex {
+		return fmt.Errorf("FromAccountIndex should not be less than %d", minAccountIndex)
+	}
+	if txInfo.FromAccountIndex > maxAccountIndex {
+		return fmt.Errorf("FromAccountIndex should not be larger than %d", maxAccountIndex)
+	}
+
+	if len(txInfo.PairIndexes) < minRoutePairsCount {
+		return fmt.Errorf("PairIndexes should have at least %d pairs", minRoutePairsCount)
+	}
+	if len(txInfo.PairIndexes) > maxRoutePairsCount {
+		return fmt.Errorf("PairIndexes should have at most %d pairs", maxRoutePairsCount)
+	}
+	for i, pairIndex := range txInfo.PairIndexes {
+		if pairIndex < minPairIndex {
+			return fmt.Errorf("PairIndexes should not be less than %d", minPairIndex)
+		}
+		if pairIndex > maxPairIndex {
+			return fmt.Errorf("PairIndexes should not be larger than %d", maxPairIndex)
+		}
+		for _, prevPairIndex := range txInfo.PairIndexes[:i] {
+			if pairIndex == prevPairIndex {
+				return fmt.Errorf("PairIndexes should be different")
+			}
+		}
+	}
+
+	if txInfo.AssetAId < minAssetId {
+		return fmt.Errorf("AssetAId should not be less than %d", minAssetId)
+	}
+	if txInfo.AssetAId > maxAssetId {
+		return fmt.Errorf("AssetAId should not be larger than %d", maxAssetId)
+	}
+
+	if txInfo.AssetAAmount == nil {
+		return fmt.Errorf("AssetAAmount should not be nil")
+	}
+	if txInfo.AssetAAmount.Cmp(minAssetAmount) < 0 {
+		return fmt.Errorf("AssetAAmount should not be less than %s", minAssetAmount.String())
+	}
+	if txInfo.AssetAAmount.Cmp(maxAssetAmount) > 0 {
+		return fmt.Errorf("AssetAAmount should not be larger than %s", maxAssetAmount.String())
+	}
+
+	if txInfo.AssetBId < minAssetId {
+		return fmt.Errorf("AssetBId should not be less than %d", minAssetId)
+	}
+	if txInfo.AssetBId > maxAssetId {
+		return fmt.Errorf("AssetBId should not be larger than %d", maxAssetId)
+	}
+	if txInfo.AssetBId == txInfo.AssetAId {
+		return fmt.Errorf("AssetAId and AssetBId should be different")
+	}
+
+	if txInfo.AssetBMinAmount == nil {
+		return fmt.Errorf("AssetBMinAmount should not be nil")
+	}
+	if txInfo.AssetBMinAmount.Cmp(minAssetAmount) < 0 {
+		return fmt.Errorf("AssetBMinAmount should not be less than %s", minAssetAmount.String())
+	}
+	if txInfo.AssetBMinAmount.Cmp(maxAssetAmount) > 0 {
+		return fmt.Errorf("AssetBMinAmount should not be larger than %s", maxAssetAmount.String())
+	}
+
+	if len(txInfo.HopAmounts) != len(txInfo.PairIndexes)-1 {
+		return fmt.Errorf("HopAmounts should have an amount for every pair but the last one")
+	}
+	for _, hopAmount := range txInfo.HopAmounts {
+		if hopAmount == nil {
+			return fmt.Errorf("HopAmounts should not be nil")
+		}
+		if hopAmount.Cmp(minAssetAmount) < 0 {
+			return fmt.Errorf("HopAmounts should not be less than %s", minAssetAmount.String())
+		}
+		if hopAmount.Cmp(maxAssetAmount) > 0 {
+			return fmt.Errorf("HopAmounts should not be larger than %s", maxAssetAmount.String())
+		}
+	}
+
+	if txInfo.GasAccountIndex < minAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be less than %d", minAccountIndex)
+	}
+	if txInfo.GasAccountIndex > maxAccountIndex {
+		return fmt.Errorf("GasAccountIndex should not be larger than %d", maxAccountIndex)
+	}
+
+	if txInfo.GasFeeAssetId < minAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be less than %d", minAssetId)
+	}
+	if txInfo.GasFeeAssetId > maxAssetId {
+		return fmt.Errorf("GasFeeAssetId should not be larger than %d", maxAssetId)
+	}
+
+	if txInfo.GasFeeAssetAmount == nil {
+		return fmt.Errorf("GasFeeAssetAmount should not be nil")
+	}
+	if txInfo.GasFeeAssetAmount.Cmp(minPackedFeeAmount) < 0 {
+		return fmt.Errorf("GasFeeAssetAmount should not be less than %s", minPackedFeeAmount.String())
+	}
+	if txInfo.GasFeeAssetAmount.Cmp(maxPackedFeeAmount) > 0 {
+		return fmt.Errorf("GasFeeAssetAmount should not be larger than %s", maxPackedFeeAmount.String())
+	}
+
+	if txInfo.Nonce < minNonce {
+		return fmt.Errorf("Nonce should not be less than %d", minNonce)
+	}
+	return nil
+}
+
+func (txInfo *RouteSwapTxInfo) VerifySignature(pubKey string) error {
+	// compute hash
+	hFunc := mimc.NewMiMC()
+	msgHash, err := ComputeRouteSwapMsgHash(txInfo, hFunc)
+	if err != nil {
+		return err
+	}
+	// verify signature
+	hFunc.Reset()
+	pk, err := ParsePublicKey(pubKey)
+	if err != nil {
+		return err
+	}
+	isValid, err := pk.Verify(txInfo.Sig, msgHash, hFunc)
+	if err != nil {
+		return err
+	}
+
+	if !isValid {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func (txInfo *RouteSwapTxInfo) GetTxType() int {
+	return TxTypeRouteSwap
+}
+
+func (txInfo *RouteSwapTxInfo) GetFromAccountIndex() int64 {
+	return txInfo.FromAccountIndex
+}
+
+func (txInfo *RouteSwapTxInfo) GetNonce() int64 {
+	return txInfo.Nonce
+}
+
+func (txInfo *RouteSwapTxInfo) GetExpiredAt() int64 {
+	return txInfo.ExpiredAt
+}
+
+/*
+	ComputeRouteSwapMsgHash: the pair indexes are padded with 0 to the max pairs
+	count, like in the circuit
+*/
+func ComputeRouteSwapMsgHash(txInfo *RouteSwapTxInfo, hFunc hash.Hash) (msgHash []byte, err error) {
+	hFunc.Reset()
+	if len(txInfo.PairIndexes) > maxRoutePairsCount {
+		log.Println("[ComputeRouteSwapMsgHash] too many pairs")
+		return nil, errors.New("[ComputeRouteSwapMsgHash] too many pairs")
+	}
+	var buf bytes.Buffer
+	packedAAmount, err := ToPackedAmount(txInfo.AssetAAmount)
+	if err != nil {
+		log.Println("[ComputeRouteSwapMsgHash] unable to packed amount:", err.Error())
+		return nil, err
+	}
+	packedBAmount, err := ToPackedAmount(txInfo.AssetBMinAmount)
+	if err != nil {
+		log.Println("[ComputeRouteSwapMsgHash] unable to packed amount:", err.Error())
+		return nil, err
+	}
+	packedFee, err := ToPackedFee(txInfo.GasFeeAssetAmount)
+	if err != nil {
+		log.Println("[ComputeRouteSwapMsgHash] unable to packed amount:", err.Error())
+		return nil, err
+	}
+	WriteInt64IntoBuf(&buf, txInfo.FromAccountIndex)
+	WriteInt64IntoBuf(&buf, int64(len(txInfo.PairIndexes)))
+	for i := 0; i < maxRoutePairsCount; i++ {
+		var pairIndex int64
+		if i < len(txInfo.PairIndexes) {
+			pairIndex = txInfo.PairIndexes[i]
+		}
+		WriteInt64IntoBuf(&buf, pairIndex)
+	}
+	WriteInt64IntoBuf(&buf, txInfo.AssetAId)
+	WriteInt64IntoBuf(&buf, packedAAmount)
+	WriteInt64IntoBuf(&buf, txInfo.AssetBId)
+	WriteInt64IntoBuf(&buf, packedBAmount)
+	WriteInt64IntoBuf(&buf, txInfo.GasAccountIndex)
+	WriteInt64IntoBuf(&buf, txInfo.GasFeeAssetId)
+	WriteInt64IntoBuf(&buf, int64(packedFee))
+	WriteInt64IntoBuf(&buf, txInfo.ExpiredAt)
+	WriteInt64IntoBuf(&buf, txInfo.Nonce)
+	WriteInt64IntoBuf(&buf, ChainId)
+	hFunc.Write(buf.Bytes())
+	msgHash = hFunc.Sum(nil)
+	return msgHash, nil
+}
+
+/*
+	RouteSwapPairInfo: reserves, fee rate and curve of a pair of the route
+*/
+type RouteSwapPairInfo struct {
+	PairIndex     int64
+	AssetAId      int64
+	AssetA        *big.Int
+	AssetBId      int64
+	AssetB        *big.Int
+	FeeRate       int64
+	PairType      int64
+	Amplification int64
+}
+
+/*
+	ComputeSwapAmountOut: amount out of a pair for amountIn, the fee is taken from
+	the amount in like in the circuit:
+		amountOut = amountIn * (RateBase - feeRate) * reserveOut / (reserveIn * RateBase + amountIn * (RateBase - feeRate))
+	the amount out is rounded down to a packed amount
+*/
+func ComputeSwapAmountOut(reserveIn, reserveOut, amountIn *big.Int, feeRate int64) (amountOut *big.Int, err error) {
+	if reserveIn == nil || reserveOut == nil || amountIn == nil ||
+		reserveIn.Sign() <= 0 || reserveOut.Sign() <= 0 || amountIn.Sign() < 0 ||
+		feeRate < 0 || feeRate > RateBase {
+		log.Println("[ComputeSwapAmountOut] invalid params")
+		return nil, errors.New("[ComputeSwapAmountOut] invalid params")
+	}
+	amountInWithFee := new(big.Int).Mul(amountIn, big.NewInt(RateBase-feeRate))
+	numerator := new(big.Int).Mul(amountInWithFee, reserveOut)
+	denominator := new(big.Int).Add(new(big.Int).Mul(reserveIn, big.NewInt(RateBase)), amountInWithFee)
+	amountOut = new(big.Int).Quo(numerator, denominator)
+	return CleanPackedAmount(amountOut)
+}
+
+/*
+	RouteSwapQuote: amounts of a route swap for an amount of asset a
+*/
+type RouteSwapQuote struct {
+	PairIndexes       []int64
+	AssetBId          int64
+	HopAmounts        []*big.Int
+	AssetBAmountDelta *big.Int
+}
+
+/*
+	QuoteRouteSwap: amounts out of the pairs of the route for assetAAmount, each
+	amount out is the amount in of the next pair
+*/
+func QuoteRouteSwap(assetAId int64, assetAAmount *big.Int, pairs []*RouteSwapPairInfo) (quote *RouteSwapQuote, err error) {
+	if len(pairs) < minRoutePairsCount || len(pairs) > maxRoutePairsCount {
+		log.Println("[QuoteRouteSwap] invalid pairs count")
+		return nil, errors.New("[QuoteRouteSwap] invalid pairs count")
+	}
+	amountIn, err := CleanPackedAmount(assetAAmount)
+	if err != nil {
+		log.Println("[QuoteRouteSwap] invalid asset a amount:", err)
+		return nil, err
+	}
+	quote = &RouteSwapQuote{}
+	assetInId := assetAId
+	for i, pair := range pairs {
+		if pair == nil {
+			log.Println("[QuoteRouteSwap] invalid pair")
+			return nil, errors.New("[QuoteRouteSwap] invalid pair")
+		}
+		var (
+			reserveIn, reserveOut *big.Int
+			assetOutId            int64
+		)
+		switch assetInId {
+		case pair.AssetAId:
+			reserveIn, reserveOut, assetOutId = pair.AssetA, pair.AssetB, pair.AssetBId
+		case pair.AssetBId:
+			reserveIn, reserveOut, assetOutId = pair.AssetB, pair.AssetA, pair.AssetAId
+		default:
+			log.Println("[QuoteRouteSwap] pair doesn't hold the asset in")
+			return nil, errors.New("[QuoteRouteSwap] pair doesn't hold the asset in")
+		}
+		amountOut, err := ComputePairAmountOut(pair.PairType, reserveIn, reserveOut, amountIn, pair.FeeRate, pair.Amplification)
+		if err != nil {
+			log.Println("[QuoteRouteSwap] unable to compute amount out:", err)
+			return nil, err
+		}
+		quote.PairIndexes = append(quote.PairIndexes, pair.PairIndex)
+		if i < len(pairs)-1 {
+			quote.HopAmounts = append(quote.HopAmounts, amountOut)
+		}
+		assetInId, amountIn = assetOutId, amountOut
+	}
+	quote.AssetBId = assetInId
+	quote.AssetBAmountDelta = amountIn
+	return quote, nil
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package legendTxTypes
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRouteSwapTxInfo(t *testing.T) {
+	testCases := []struct {
+		err      error
+		testCase *RouteSwapTxInfo
+	}{
+		// PairIndexes
+		{
+			fmt.Errorf("PairIndexes should have at least %d pairs", minRoutePairsCount),
+			&RouteSwapTxInfo{
+				FromAccountIndex: 1,
+				PairIndexes:      []int64{1},
+			},
+		},
+		{
+			fmt.Errorf("PairIndexes should have at most %d pairs", maxRoutePairsCount),
+			&RouteSwapTxInfo{
+				FromAccountIndex: 1,
+				PairIndexes:      []int64{1, 2, 3, 4},
+			},
+		},
+		{
+			fmt.Errorf("PairIndexes should not be larger than %d", maxPairIndex),
+			&RouteSwapTxInfo{
+				FromAccountIndex: 1,
+				PairIndexes:      []int64{1, maxPairIndex + 1},
+			},
+		},
+		{
+			fmt.Errorf("PairIndexes should be different"),
+			&RouteSwapTxInfo{
+				FromAccountIndex: 1,
+				PairIndexes:      []int64{1, 2, 1},
+			},
+		},
+		// AssetBId
+		{
+			fmt.Errorf("AssetAId and AssetBId should be different"),
+			&RouteSwapTxInfo{
+				FromAccountIndex: 1,
+				PairIndexes:      []int64{1, 2},
+				AssetAId:         1,
+				AssetAAmount:     big.NewInt(1),
+				AssetBId:         1,
+			},
+		},
+		// HopAmounts
+		{
+			fmt.Errorf("HopAmounts should have an amount for every pair but the last one"),
+			&RouteSwapTxInfo{
+				FromAccountIndex: 1,
+				PairIndexes:      []int64{1, 2, 3},
+				AssetAId:         1,
+				AssetAAmount:     big.NewInt(1),
+				AssetBId:         2,
+				AssetBMinAmount:  big.NewInt(1),
+				HopAmounts:       []*big.Int{big.NewInt(1)},
+			},
+		},
+		{
+			fmt.Errorf("HopAmounts should not be nil"),
+			&RouteSwapTxInfo{
+				FromAccountIndex: 1,
+				PairIndexes:      []int64{1, 2},
+				AssetAId:         1,
+				AssetAAmount:     big.NewInt(1),
+				AssetBId:         2,
+				AssetBMinAmount:  big.NewInt(1),
+				HopAmounts:       []*big.Int{nil},
+			},
+		},
+		// true
+		{
+			nil,
+			&RouteSwapTxInfo{
+				FromAccountIndex:  1,
+				PairIndexes:       []int64{1, 2},
+				AssetAId:          1,
+				AssetAAmount:      big.NewInt(1),
+				AssetBId:          2,
+				AssetBMinAmount:   big.NewInt(1),
+				AssetBAmountDelta: big.NewInt(1),
+				HopAmounts:        []*big.Int{big.NewInt(1)},
+				GasAccountIndex:   1,
+				GasFeeAssetId:     3,
+				GasFeeAssetAmount: big.NewInt(1),
+				Nonce:             1,
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		err := testCase.testCase.Validate()
+		require.Equalf(t, testCase.err, err, "err should be the same")
+	}
+}
+
+func TestQuoteRouteSwap(t *testing.T) {
+	// 1000 * 9970 * 100000 / (100000 * 10000 + 1000 * 9970)
+	amountOut, err := ComputeSwapAmountOut(big.NewInt(100000), big.NewInt(100000), big.NewInt(1000), 30)
+	require.NoError(t, err)
+	require.Equal(t, int64(987), amountOut.Int64())
+	// the amount out is rounded down to a packed amount
+	amountOut, err = ComputeSwapAmountOut(big.NewInt(1e18), big.NewInt(1e18), big.NewInt(1e12), 0)
+	require.NoError(t, err)
+	cleanAmountOut, err := CleanPackedAmount(amountOut)
+	require.NoError(t, err)
+	require.Equal(t, cleanAmountOut, amountOut)
+	_, err = ComputeSwapAmountOut(big.NewInt(100000), big.NewInt(100000), big.NewInt(1000), RateBase+1)
+	require.Error(t, err)
+
+	pairs := []*RouteSwapPairInfo{
+		{PairIndex: 5, AssetAId: 1, AssetA: big.NewInt(100000), AssetBId: 2, AssetB: big.NewInt(100000), FeeRate: 30},
+		{PairIndex: 7, AssetAId: 3, AssetA: big.NewInt(200000), AssetBId: 2, AssetB: big.NewInt(100000), FeeRate: 30},
+	}
+	quote, err := QuoteRouteSwap(1, big.NewInt(1000), pairs)
+	require.NoError(t, err)
+	require.Equal(t, []int64{5, 7}, quote.PairIndexes)
+	require.Equal(t, int64(3), quote.AssetBId)
+	require.Equal(t, []*big.Int{big.NewInt(987)}, quote.HopAmounts)
+	hopAmountOut, err := ComputeSwapAmountOut(big.NewInt(100000), big.NewInt(200000), big.NewInt(987), 30)
+	require.NoError(t, err)
+	require.Equal(t, hopAmountOut, quote.AssetBAmountDelta)
+	// the asset in isn't in the pair
+	_, err = QuoteRouteSwap(4, big.NewInt(1000), pairs)
+	require.Error(t, err)
+	_, err = QuoteRouteSwap(1, big.NewInt(1000), pairs[:1])
+	require.Error(t, err)
+}
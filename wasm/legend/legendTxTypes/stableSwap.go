@@ -0,0 +1,198 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package legendTxTypes
+
+import (
+	"errors"
+	"log"
+	"math/big"
+)
+
+/*
+	ComputeStableSwapInvariant: invariant D of the reserves of a StableSwap pair,
+	the smallest D with
+		16A * x * y * (x + y) + 4 * x * y * D <= 16A * x * y * D + D^3
+	it matches the invariant witnessed in the circuit
+*/
+func ComputeStableSwapInvariant(amplification, x, y *big.Int) (d *big.Int) {
+	xy := new(big.Int).Mul(x, y)
+	if xy.Sign() <= 0 || amplification.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+	// D = (2 * D^3 + 16A * x * y * (x + y)) / (3 * D^2 + 16A * x * y - 4 * x * y)
+	axy := new(big.Int).Mul(big.NewInt(16), new(big.Int).Mul(amplification, xy))
+	c0 := new(big.Int).Mul(axy, new(big.Int).Add(x, y))
+	c1 := new(big.Int).Sub(axy, new(big.Int).Mul(big.NewInt(4), xy))
+	d = new(big.Int).Add(x, y)
+	for i := 0; i < 255; i++ {
+		d2 := new(big.Int).Mul(d, d)
+		numerator := new(big.Int).Add(new(big.Int).Mul(big.NewInt(2), new(big.Int).Mul(d2, d)), c0)
+		denominator := new(big.Int).Add(new(big.Int).Mul(big.NewInt(3), d2), c1)
+		next := new(big.Int).Quo(numerator, denominator)
+		if next.Cmp(d) >= 0 {
+			break
+		}
+		d = next
+	}
+	for stableSwapCurve(amplification, x, y, d).Sign() > 0 {
+		d.Add(d, big.NewInt(1))
+	}
+	for d.Sign() > 0 && stableSwapCurve(amplification, x, y, new(big.Int).Sub(d, big.NewInt(1))).Sign() <= 0 {
+		d.Sub(d, big.NewInt(1))
+	}
+	return d
+}
+
+/*
+	ComputeStableSwapAmountOut: amount out of a StableSwap pair for amountIn, the fee
+	is taken from the amount in like in the circuit, the reserve out after the swap is
+	the smallest one keeping the reserves on or above the curve of the invariant.
+	the amount out is rounded down to a packed amount
+*/
+func ComputeStableSwapAmountOut(
+	reserveIn, reserveOut, amountIn *big.Int, feeRate int64, amplification int64,
+) (amountOut *big.Int, err error) {
+	if reserveIn == nil || reserveOut == nil || amountIn == nil ||
+		reserveIn.Sign() <= 0 || reserveOut.Sign() <= 0 || amountIn.Sign() < 0 ||
+		feeRate < 0 || feeRate > RateBase || amplification <= 0 {
+		log.Println("[ComputeStableSwapAmountOut] invalid params")
+		return nil, errors.New("[ComputeStableSwapAmountOut] invalid params")
+	}
+	amountInAfterFee := new(big.Int).Mul(amountIn, big.NewInt(RateBase-feeRate))
+	amountInAfterFee.Quo(amountInAfterFee, big.NewInt(RateBase))
+	x := new(big.Int).Add(reserveIn, amountInAfterFee)
+	if reserveIn.BitLen() > stableSwapReserveBitsSize || reserveOut.BitLen() > stableSwapReserveBitsSize ||
+		x.BitLen() > stableSwapReserveBitsSize {
+		log.Println("[ComputeStableSwapAmountOut] reserves are too large")
+		return nil, errors.New("[ComputeStableSwapAmountOut] reserves are too large")
+	}
+	d := ComputeStableSwapInvariant(big.NewInt(amplification), reserveIn, reserveOut)
+	y := stableSwapReserve(big.NewInt(amplification), x, d)
+	amountOut = new(big.Int).Sub(reserveOut, y)
+	if amountOut.Sign() < 0 {
+		amountOut.SetInt64(0)
+	}
+	return CleanPackedAmount(amountOut)
+}
+
+/*
+	ComputePairAmountOut: amount out of a pair of any type for amountIn
+*/
+func ComputePairAmountOut(
+	pairType int64, reserveIn, reserveOut, amountIn *big.Int, feeRate int64, amplification int64,
+) (amountOut *big.Int, err error) {
+	switch pairType {
+	case PairTypeConstantProduct:
+		return ComputeSwapAmountOut(reserveIn, reserveOut, amountIn, feeRate)
+	case PairTypeStableSwap:
+		return ComputeStableSwapAmountOut(reserveIn, reserveOut, amountIn, feeRate, amplification)
+	default:
+		log.Println("[ComputePairAmountOut] invalid pair type")
+		return nil, errors.New("[ComputePairAmountOut] invalid pair type")
+	}
+}
+
+/*
+	ComputeStableSwapAmountIn: smallest amount in of a StableSwap pair for amountOut,
+	with the fee taken from the amount in like in the circuit. the amount in is
+	rounded up to a packed amount
+*/
+func ComputeStableSwapAmountIn(
+	reserveIn, reserveOut, amountOut *big.Int, feeRate int64, amplification int64,
+) (amountIn *big.Int, err error) {
+	if reserveIn == nil || reserveOut == nil || amountOut == nil ||
+		reserveIn.Sign() <= 0 || reserveOut.Sign() <= 0 || amountOut.Sign() < 0 || amountOut.Cmp(reserveOut) >= 0 ||
+		feeRate < 0 || feeRate >= RateBase || amplification <= 0 {
+		log.Println("[ComputeStableSwapAmountIn] invalid params")
+		return nil, errors.New("[ComputeStableSwapAmountIn] invalid params")
+	}
+	if reserveIn.BitLen() > stableSwapReserveBitsSize || reserveOut.BitLen() > stableSwapReserveBitsSize {
+		log.Println("[ComputeStableSwapAmountIn] reserves are too large")
+		return nil, errors.New("[ComputeStableSwapAmountIn] reserves are too large")
+	}
+	d := ComputeStableSwapInvariant(big.NewInt(amplification), reserveIn, reserveOut)
+	// the curve is symmetric in x and y
+	x := stableSwapReserve(big.NewInt(amplification), new(big.Int).Sub(reserveOut, amountOut), d)
+	if x.BitLen() > stableSwapReserveBitsSize {
+		log.Println("[ComputeStableSwapAmountIn] reserves are too large")
+		return nil, errors.New("[ComputeStableSwapAmountIn] reserves are too large")
+	}
+	amountInAfterFee := new(big.Int).Sub(x, reserveIn)
+	if amountInAfterFee.Sign() < 0 {
+		amountInAfterFee.SetInt64(0)
+	}
+	// smallest amount in with amountIn * (RateBase - feeRate) / RateBase >= amountInAfterFee
+	amountIn = ceilDiv(new(big.Int).Mul(amountInAfterFee, big.NewInt(RateBase)), big.NewInt(RateBase-feeRate))
+	return CleanPackedAmountUp(amountIn)
+}
+
+/*
+	ComputePairAmountIn: amount in of a pair of any type for amountOut
+*/
+func ComputePairAmountIn(
+	pairType int64, reserveIn, reserveOut, amountOut *big.Int, feeRate int64, amplification int64,
+) (amountIn *big.Int, err error) {
+	switch pairType {
+	case PairTypeConstantProduct:
+		return ComputeSwapAmountIn(reserveIn, reserveOut, amountOut, feeRate)
+	case PairTypeStableSwap:
+		return ComputeStableSwapAmountIn(reserveIn, reserveOut, amountOut, feeRate, amplification)
+	default:
+		log.Println("[ComputePairAmountIn] invalid pair type")
+		return nil, errors.New("[ComputePairAmountIn] invalid pair type")
+	}
+}
+
+/*
+	stableSwapReserve: smallest y with the reserves x and y on or above the curve of d,
+	16A * x * y^2 + (16A * x^2 + 4 * x * D - 16A * x * D) * y - D^3 = 0 has a single
+	positive root
+*/
+func stableSwapReserve(amplification, x, d *big.Int) (y *big.Int) {
+	a := new(big.Int).Mul(big.NewInt(16), new(big.Int).Mul(amplification, x))
+	b := new(big.Int).Mul(a, new(big.Int).Sub(x, d))
+	b.Add(b, new(big.Int).Mul(big.NewInt(4), new(big.Int).Mul(x, d)))
+	c := new(big.Int).Mul(d, new(big.Int).Mul(d, d))
+	if a.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	// y = (sqrt(b^2 + 4 * a * D^3) - b) / 2a
+	delta := new(big.Int).Add(new(big.Int).Mul(b, b), new(big.Int).Mul(big.NewInt(4), new(big.Int).Mul(a, c)))
+	y = new(big.Int).Sub(new(big.Int).Sqrt(delta), b)
+	y.Quo(y, new(big.Int).Mul(big.NewInt(2), a))
+	if y.Sign() < 0 {
+		y.SetInt64(0)
+	}
+	for stableSwapCurve(amplification, x, y, d).Sign() < 0 {
+		y.Add(y, big.NewInt(1))
+	}
+	for y.Sign() > 0 && stableSwapCurve(amplification, x, new(big.Int).Sub(y, big.NewInt(1)), d).Sign() >= 0 {
+		y.Sub(y, big.NewInt(1))
+	}
+	return y
+}
+
+// 16A * x * y * (x + y) + 4 * x * y * D - 16A * x * y * D - D^3
+func stableSwapCurve(amplification, x, y, d *big.Int) *big.Int {
+	xy := new(big.Int).Mul(x, y)
+	axy := new(big.Int).Mul(big.NewInt(16), new(big.Int).Mul(amplification, xy))
+	f := new(big.Int).Mul(axy, new(big.Int).Add(x, y))
+	f.Add(f, new(big.Int).Mul(big.NewInt(4), new(big.Int).Mul(xy, d)))
+	f.Sub(f, new(big.Int).Mul(axy, d))
+	return f.Sub(f, new(big.Int).Mul(d, new(big.Int).Mul(d, d)))
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright © 2021 Zecrey Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package legendTxTypes
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeStableSwapAmountOut(t *testing.T) {
+	// balanced reserves: D = x + y
+	d := ComputeStableSwapInvariant(big.NewInt(100), big.NewInt(1000000), big.NewInt(1000000))
+	require.Equal(t, int64(2000000), d.Int64())
+	// the invariant is the smallest D with the reserves on or below the curve
+	d = ComputeStableSwapInvariant(big.NewInt(100), big.NewInt(1000000), big.NewInt(3000000))
+	require.True(t, stableSwapCurve(big.NewInt(100), big.NewInt(1000000), big.NewInt(3000000), d).Sign() <= 0)
+	require.True(t, stableSwapCurve(big.NewInt(100), big.NewInt(1000000), big.NewInt(3000000), new(big.Int).Sub(d, big.NewInt(1))).Sign() > 0)
+
+	// a StableSwap pair gives more out than x * y = k for balanced reserves
+	reserve := big.NewInt(1000000)
+	amountIn := big.NewInt(10000)
+	stableAmountOut, err := ComputeStableSwapAmountOut(reserve, reserve, amountIn, 30, 100)
+	require.NoError(t, err)
+	amountOut, err := ComputeSwapAmountOut(reserve, reserve, amountIn, 30)
+	require.NoError(t, err)
+	require.True(t, stableAmountOut.Cmp(amountOut) > 0)
+	require.True(t, stableAmountOut.Cmp(big.NewInt(9970)) <= 0)
+	// the reserves after the swap are on or above the curve, one more out would be below
+	x := big.NewInt(1000000 + 9970)
+	d = ComputeStableSwapInvariant(big.NewInt(100), reserve, reserve)
+	y := new(big.Int).Sub(reserve, stableAmountOut)
+	require.True(t, stableSwapCurve(big.NewInt(100), x, y, d).Sign() >= 0)
+	require.True(t, stableSwapCurve(big.NewInt(100), x, new(big.Int).Sub(y, big.NewInt(1)), d).Sign() < 0)
+
+	// the quote of a route dispatches on the pair type
+	pairs := []*RouteSwapPairInfo{
+		{PairIndex: 5, AssetAId: 1, AssetA: reserve, AssetBId: 2, AssetB: reserve, FeeRate: 30,
+			PairType: PairTypeStableSwap, Amplification: 100},
+		{PairIndex: 7, AssetAId: 2, AssetA: big.NewInt(100000), AssetBId: 3, AssetB: big.NewInt(200000), FeeRate: 30},
+	}
+	quote, err := QuoteRouteSwap(1, amountIn, pairs)
+	require.NoError(t, err)
+	require.Equal(t, []*big.Int{stableAmountOut}, quote.HopAmounts)
+
+	// invalid params
+	_, err = ComputeStableSwapAmountOut(reserve, reserve, amountIn, 30, 0)
+	require.Error(t, err)
+	_, err = ComputeStableSwapAmountOut(new(big.Int).Lsh(big.NewInt(1), 76), reserve, amountIn, 30, 100)
+	require.Error(t, err)
+	_, err = ComputePairAmountOut(2, reserve, reserve, amountIn, 30, 100)
+	require.Error(t, err)
+}
@@ -42,6 +42,9 @@ type SwapSegmentFormat struct {
 	GasFeeAssetAmount string `json:"gas_fee_asset_amount"`
 	ExpiredAt         int64  `json:"expired_at"`
 	Nonce             int64  `json:"nonce"`
+	// SwapModeExactOutput swaps sign the asset b amount delta and a max asset a amount
+	SwapMode        int64  `json:"swap_mode"`
+	AssetAMaxAmount string `json:"asset_a_max_amount"`
 }
 
 func ConstructSwapTxInfo(sk *PrivateKey, segmentStr string) (txInfo *SwapTxInfo, err error) {
@@ -75,6 +78,15 @@ func ConstructSwapTxInfo(sk *PrivateKey, segmentStr string) (txInfo *SwapTxInfo,
 		return nil, err
 	}
 	gasFeeAmount, _ = CleanPackedFee(gasFeeAmount)
+	var assetAMaxAmount *big.Int
+	if segmentFormat.SwapMode == SwapModeExactOutput {
+		assetAMaxAmount, err = StringToBigInt(segmentFormat.AssetAMaxAmount)
+		if err != nil {
+			log.Println("[ConstructSwapTxInfo] unable to convert string to big int:", err)
+			return nil, err
+		}
+		assetAMaxAmount, _ = CleanPackedAmount(assetAMaxAmount)
+	}
 	txInfo = &SwapTxInfo{
 		FromAccountIndex:  segmentFormat.FromAccountIndex,
 		PairIndex:         segmentFormat.PairIndex,
@@ -86,6 +98,8 @@ func ConstructSwapTxInfo(sk *PrivateKey, segmentStr string) (txInfo *SwapTxInfo,
 		GasAccountIndex:   segmentFormat.GasAccountIndex,
 		GasFeeAssetId:     segmentFormat.GasFeeAssetId,
 		GasFeeAssetAmount: gasFeeAmount,
+		SwapMode:          segmentFormat.SwapMode,
+		AssetAMaxAmount:   assetAMaxAmount,
 		Nonce:             segmentFormat.Nonce,
 		ExpiredAt:         segmentFormat.ExpiredAt,
 		Sig:               nil,
@@ -117,9 +131,13 @@ type SwapTxInfo struct {
 	GasAccountIndex   int64
 	GasFeeAssetId     int64
 	GasFeeAssetAmount *big.Int
-	ExpiredAt         int64
-	Nonce             int64
-	Sig               []byte
+	// the asset a amount of an exact output swap is set by the sequencer, up to
+	// AssetAMaxAmount
+	SwapMode        int64
+	AssetAMaxAmount *big.Int
+	ExpiredAt       int64
+	Nonce           int64
+	Sig             []byte
 }
 
 func (txInfo *SwapTxInfo) Validate() error {
@@ -161,14 +179,44 @@ func (txInfo *SwapTxInfo) Validate() error {
 		return fmt.Errorf("AssetBId should not be larger than %d", maxAssetId)
 	}
 
-	if txInfo.AssetBMinAmount == nil {
-		return fmt.Errorf("AssetBMinAmount should not be nil")
+	if txInfo.SwapMode != SwapModeExactInput && txInfo.SwapMode != SwapModeExactOutput {
+		return fmt.Errorf("SwapMode should be %d or %d", SwapModeExactInput, SwapModeExactOutput)
 	}
-	if txInfo.AssetBMinAmount.Cmp(minAssetAmount) < 0 {
-		return fmt.Errorf("AssetBMinAmount should not be less than %s", minAssetAmount.String())
+
+	if txInfo.SwapMode == SwapModeExactInput {
+		if txInfo.AssetBMinAmount == nil {
+			return fmt.Errorf("AssetBMinAmount should not be nil")
+		}
+		if txInfo.AssetBMinAmount.Cmp(minAssetAmount) < 0 {
+			return fmt.Errorf("AssetBMinAmount should not be less than %s", minAssetAmount.String())
+		}
+		if txInfo.AssetBMinAmount.Cmp(maxAssetAmount) > 0 {
+			return fmt.Errorf("AssetBMinAmount should not be larger than %s", maxAssetAmount.String())
+		}
 	}
-	if txInfo.AssetBMinAmount.Cmp(maxAssetAmount) > 0 {
-		return fmt.Errorf("AssetBMinAmount should not be larger than %s", maxAssetAmount.String())
+
+	if txInfo.SwapMode == SwapModeExactOutput {
+		if txInfo.AssetBAmountDelta == nil {
+			return fmt.Errorf("AssetBAmountDelta should not be nil")
+		}
+		if txInfo.AssetBAmountDelta.Cmp(minAssetAmount) < 0 {
+			return fmt.Errorf("AssetBAmountDelta should not be less than %s", minAssetAmount.String())
+		}
+		if txInfo.AssetBAmountDelta.Cmp(maxAssetAmount) > 0 {
+			return fmt.Errorf("AssetBAmountDelta should not be larger than %s", maxAssetAmount.String())
+		}
+		if txInfo.AssetAMaxAmount == nil {
+			return fmt.Errorf("AssetAMaxAmount should not be nil")
+		}
+		if txInfo.AssetAMaxAmount.Cmp(minAssetAmount) < 0 {
+			return fmt.Errorf("AssetAMaxAmount should not be less than %s", minAssetAmount.String())
+		}
+		if txInfo.AssetAMaxAmount.Cmp(maxAssetAmount) > 0 {
+			return fmt.Errorf("AssetAMaxAmount should not be larger than %s", maxAssetAmount.String())
+		}
+		if txInfo.AssetAAmount.Cmp(txInfo.AssetAMaxAmount) > 0 {
+			return fmt.Errorf("AssetAAmount should not be larger than AssetAMaxAmount")
+		}
 	}
 
 	if txInfo.GasAccountIndex < minAccountIndex {
@@ -244,12 +292,21 @@ func (txInfo *SwapTxInfo) GetExpiredAt() int64 {
 func ComputeSwapMsgHash(txInfo *SwapTxInfo, hFunc hash.Hash) (msgHash []byte, err error) {
 	hFunc.Reset()
 	var buf bytes.Buffer
-	packedAAmount, err := ToPackedAmount(txInfo.AssetAAmount)
+	// the amount in and the min amount out, or the max amount in and the amount out
+	assetAAmount, assetBAmount := txInfo.AssetAAmount, txInfo.AssetBMinAmount
+	if txInfo.SwapMode == SwapModeExactOutput {
+		assetAAmount, assetBAmount = txInfo.AssetAMaxAmount, txInfo.AssetBAmountDelta
+	}
+	if assetAAmount == nil || assetBAmount == nil {
+		log.Println("[ComputeSwapMsgHash] invalid amounts")
+		return nil, errors.New("[ComputeSwapMsgHash] invalid amounts")
+	}
+	packedAAmount, err := ToPackedAmount(assetAAmount)
 	if err != nil {
 		log.Println("[ComputeTransferMsgHash] unable to packed amount:", err.Error())
 		return nil, err
 	}
-	packedBAmount, err := ToPackedAmount(txInfo.AssetBMinAmount)
+	packedBAmount, err := ToPackedAmount(assetBAmount)
 	if err != nil {
 		log.Println("[ComputeTransferMsgHash] unable to packed amount:", err.Error())
 		return nil, err
@@ -260,7 +317,8 @@ func ComputeSwapMsgHash(txInfo *SwapTxInfo, hFunc hash.Hash) (msgHash []byte, er
 		return nil, err
 	}
 	WriteInt64IntoBuf(&buf, txInfo.FromAccountIndex)
-	WriteInt64IntoBuf(&buf, txInfo.PairIndex)
+	// the swap mode is signed above the pair index
+	WriteInt64IntoBuf(&buf, txInfo.PairIndex+txInfo.SwapMode<<16)
 	WriteInt64IntoBuf(&buf, txInfo.AssetAId)
 	WriteInt64IntoBuf(&buf, packedAAmount)
 	WriteInt64IntoBuf(&buf, txInfo.AssetBId)
@@ -275,3 +333,22 @@ func ComputeSwapMsgHash(txInfo *SwapTxInfo, hFunc hash.Hash) (msgHash []byte, er
 	msgHash = hFunc.Sum(nil)
 	return msgHash, nil
 }
+
+/*
+	ComputeSwapAmountIn: smallest amount in of a pair for amountOut, the fee is taken
+	from the amount in like in the circuit:
+		amountIn * (RateBase - feeRate) >= reserveIn * RateBase * amountOut / (reserveOut - amountOut)
+	the amount in is rounded up to a packed amount
+*/
+func ComputeSwapAmountIn(reserveIn, reserveOut, amountOut *big.Int, feeRate int64) (amountIn *big.Int, err error) {
+	if reserveIn == nil || reserveOut == nil || amountOut == nil ||
+		reserveIn.Sign() <= 0 || reserveOut.Sign() <= 0 || amountOut.Sign() < 0 || amountOut.Cmp(reserveOut) >= 0 ||
+		feeRate < 0 || feeRate >= RateBase {
+		log.Println("[ComputeSwapAmountIn] invalid params")
+		return nil, errors.New("[ComputeSwapAmountIn] invalid params")
+	}
+	numerator := new(big.Int).Mul(new(big.Int).Mul(reserveIn, big.NewInt(RateBase)), amountOut)
+	amountInWithFee := ceilDiv(numerator, new(big.Int).Sub(reserveOut, amountOut))
+	amountIn = ceilDiv(amountInWithFee, big.NewInt(RateBase-feeRate))
+	return CleanPackedAmountUp(amountIn)
+}
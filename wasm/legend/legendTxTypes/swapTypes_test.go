@@ -18,12 +18,16 @@
 package legendTxTypes
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	curve "github.com/bnb-chain/zkbas-crypto/ecc/ztwistededwards/tebn254"
 )
 
 func TestValidateSwapTxInfo(t *testing.T) {
@@ -335,3 +339,98 @@ func TestValidateSwapTxInfo(t *testing.T) {
 		require.Equalf(t, testCase.err, err, "err should be the same")
 	}
 }
+
+func TestExactOutputSwap(t *testing.T) {
+	sk, err := curve.GenerateEddsaPrivateKey("sher.legend")
+	require.NoError(t, err)
+	pubKey := hex.EncodeToString(sk.PublicKey.Bytes())
+	segment := &SwapSegmentFormat{
+		FromAccountIndex:  1,
+		PairIndex:         1,
+		AssetAId:          1,
+		AssetAAmount:      "1000",
+		AssetBId:          2,
+		AssetBAmountDelta: "987",
+		GasAccountIndex:   0,
+		GasFeeAssetId:     0,
+		GasFeeAssetAmount: "10",
+		ExpiredAt:         time.Now().Add(time.Hour).UnixMilli(),
+		Nonce:             1,
+		SwapMode:          SwapModeExactOutput,
+		AssetAMaxAmount:   "1010",
+	}
+	segmentBytes, err := json.Marshal(segment)
+	require.NoError(t, err)
+	txInfo, err := ConstructSwapTxInfo(sk, string(segmentBytes))
+	require.NoError(t, err)
+	require.NoError(t, txInfo.Validate())
+	require.NoError(t, txInfo.VerifySignature(pubKey))
+	// the amount in is set by the sequencer up to the max amount
+	txInfo.AssetAAmount = big.NewInt(1005)
+	require.NoError(t, txInfo.VerifySignature(pubKey))
+	txInfo.AssetAAmount = big.NewInt(1011)
+	require.Error(t, txInfo.Validate())
+	// the amount out and the swap mode are signed
+	txInfo.AssetAAmount = big.NewInt(1000)
+	txInfo.AssetBAmountDelta = big.NewInt(988)
+	require.Error(t, txInfo.VerifySignature(pubKey))
+	txInfo.AssetBAmountDelta = big.NewInt(987)
+	txInfo.SwapMode = SwapModeExactInput
+	txInfo.AssetBMinAmount = big.NewInt(987)
+	txInfo.AssetAAmount = big.NewInt(1010)
+	require.Error(t, txInfo.VerifySignature(pubKey))
+	txInfo.SwapMode = 2
+	require.Error(t, txInfo.Validate())
+}
+
+func TestComputeSwapAmountIn(t *testing.T) {
+	// 100000 * 10000 * 987 / (100000 - 987) = 9968390.9..., 9968391 / 9970 = 999.8...
+	amountIn, err := ComputeSwapAmountIn(big.NewInt(100000), big.NewInt(100000), big.NewInt(987), 30)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), amountIn.Int64())
+	// the amount in is the smallest one giving the amount out
+	amountOut, err := ComputeSwapAmountOut(big.NewInt(100000), big.NewInt(100000), amountIn, 30)
+	require.NoError(t, err)
+	require.Equal(t, int64(987), amountOut.Int64())
+	amountOut, err = ComputeSwapAmountOut(big.NewInt(100000), big.NewInt(100000), big.NewInt(999), 30)
+	require.NoError(t, err)
+	require.True(t, amountOut.Int64() < 987)
+	// the amount in is rounded up to a packed amount
+	amountIn, err = ComputeSwapAmountIn(big.NewInt(1e18), big.NewInt(1e18), big.NewInt(1e12), 30)
+	require.NoError(t, err)
+	cleanAmountIn, err := CleanPackedAmount(amountIn)
+	require.NoError(t, err)
+	require.Equal(t, cleanAmountIn, amountIn)
+	amountOut, err = ComputeSwapAmountOut(big.NewInt(1e18), big.NewInt(1e18), amountIn, 30)
+	require.NoError(t, err)
+	require.True(t, amountOut.Cmp(big.NewInt(1e12)) >= 0)
+	// the whole reserve can't be bought
+	_, err = ComputeSwapAmountIn(big.NewInt(100000), big.NewInt(100000), big.NewInt(100000), 30)
+	require.Error(t, err)
+
+	// StableSwap pairs
+	reserve := big.NewInt(1000000)
+	amountIn, err = ComputeStableSwapAmountIn(reserve, reserve, big.NewInt(9900), 30, 100)
+	require.NoError(t, err)
+	amountOut, err = ComputeStableSwapAmountOut(reserve, reserve, amountIn, 30, 100)
+	require.NoError(t, err)
+	require.True(t, amountOut.Cmp(big.NewInt(9900)) >= 0)
+	amountOut, err = ComputeStableSwapAmountOut(reserve, reserve, new(big.Int).Sub(amountIn, big.NewInt(1)), 30, 100)
+	require.NoError(t, err)
+	require.True(t, amountOut.Cmp(big.NewInt(9900)) < 0)
+	_, err = ComputePairAmountIn(2, reserve, reserve, big.NewInt(9900), 30, 100)
+	require.Error(t, err)
+}
+
+func TestCleanPackedAmountUp(t *testing.T) {
+	amount, err := CleanPackedAmountUp(big.NewInt(34359738367))
+	require.NoError(t, err)
+	require.Equal(t, int64(34359738367), amount.Int64())
+	amount, err = CleanPackedAmountUp(big.NewInt(34359738368))
+	require.NoError(t, err)
+	require.Equal(t, int64(34359738370), amount.Int64())
+	amount, err = CleanPackedAmountUp(big.NewInt(343597383671))
+	require.NoError(t, err)
+	// 34359738367 * 10 is the largest amount with an exponent of 1
+	require.Equal(t, int64(343597383700), amount.Int64())
+}
@@ -75,6 +75,36 @@ func CleanPackedAmount(amount *big.Int) (nAmount *big.Int, err error) {
 	return util.CleanPackedAmount(amount)
 }
 
+/*
+	CleanPackedAmountUp: smallest packed amount not below amount
+*/
+func CleanPackedAmountUp(amount *big.Int) (nAmount *big.Int, err error) {
+	nAmount, err = CleanPackedAmount(amount)
+	if err != nil {
+		return nil, err
+	}
+	for nAmount.Cmp(amount) < 0 {
+		// next packed amount, the mantissa can't overflow
+		unit := big.NewInt(1)
+		for new(big.Int).Quo(nAmount, unit).Cmp(util.PackedAmountMaxMantissa) >= 0 {
+			unit.Mul(unit, big.NewInt(10))
+		}
+		nAmount, err = CleanPackedAmount(new(big.Int).Add(nAmount, unit))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nAmount, nil
+}
+
+func ceilDiv(a, b *big.Int) *big.Int {
+	res, m := new(big.Int).QuoRem(a, b, new(big.Int))
+	if m.Sign() > 0 {
+		res.Add(res, big.NewInt(1))
+	}
+	return res
+}
+
 /*
 	ToPackedFee: convert big int to 16 bit, 5 bits for 10^x, 11 bits for a * 10^x
 */
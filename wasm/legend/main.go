@@ -48,6 +48,11 @@ func main() {
 	js.Global().Set("signSwap", src.SwapTx())
 	js.Global().Set("signTransfer", src.TransferTx())
 	js.Global().Set("signWithdraw", src.WithdrawTx())
+	js.Global().Set("signChangePubKey", src.ChangePubKeyTx())
+	js.Global().Set("signOrder", src.OrderTx())
+	js.Global().Set("signMatchOrder", src.MatchOrderTx())
+	js.Global().Set("signCancelOrder", src.CancelOrderTx())
+	js.Global().Set("signRouteSwap", src.RouteSwapTx())
 
 	// nft
 	js.Global().Set("signAtomicMatch", src.AtomicMatchTx())